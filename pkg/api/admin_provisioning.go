@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -85,3 +86,50 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route GET /admin/provisioning/drift admin_provisioning adminProvisioningGetDriftReport
+//
+// Get the report of the last drift scan.
+//
+// Returns how the dashboards, datasources, plugins and alert rules provisioned from files drifted from their files, as found by the last scan. Resources are reported as missing when they are only defined in a file, modified when they differ from their file, and orphaned when they were provisioned from a file that no longer defines them.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningDriftReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (hs *HTTPServer) AdminProvisioningGetDriftReport(c *contextmodel.ReqContext) response.Response {
+	report := hs.ProvisioningService.GetDriftReport()
+	if report == nil {
+		return response.Error(http.StatusNotFound, "No drift scan has run yet", nil)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route POST /admin/provisioning/drift/scan admin_provisioning adminProvisioningScanDrift
+//
+// Scan the provisioned resources for drift.
+//
+// Compares the resources provisioned from files with the database now, instead of waiting for the next periodic scan. When `drift_auto_revert` is enabled, the provisioning files of the drifted resources are applied again.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningDriftReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminProvisioningScanDrift(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.ProvisioningService.ScanDrift(c.Req.Context()))
+}
+
+// swagger:response adminProvisioningDriftReportResponse
+type AdminProvisioningDriftReportResponse struct {
+	// in:body
+	Body drift.Report `json:"body"`
+}
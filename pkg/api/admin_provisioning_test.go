@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"testing"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -165,3 +167,60 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningDrift(t *testing.T) {
+	permissions := []accesscontrol.Permission{{Action: ActionProvisioningReload, Scope: ScopeProvisionersAll}}
+	report := &drift.Report{
+		Items: []drift.Item{{Kind: drift.KindDashboard, OrgID: 1, Name: "dash", State: drift.StateModified, Fields: []string{"title"}}},
+	}
+
+	setup := func(t *testing.T) (*webtest.Server, *provisioning.ProvisioningServiceMock) {
+		pService := provisioning.NewProvisioningServiceMock(context.Background())
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.ProvisioningService = pService
+		})
+		return server, pService
+	}
+
+	t.Run("should return 404 before the first scan", func(t *testing.T) {
+		server, _ := setup(t)
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/drift"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should return the last report", func(t *testing.T) {
+		server, pService := setup(t)
+		pService.GetDriftReportFunc = func() *drift.Report { return report }
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/drift"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var body drift.Report
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, report.Items, body.Items)
+	})
+
+	t.Run("should scan", func(t *testing.T) {
+		server, pService := setup(t)
+		pService.ScanDriftFunc = func(ctx context.Context) *drift.Report { return report }
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/admin/provisioning/drift/scan", nil), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+		assert.Len(t, pService.Calls.ScanDrift, 1)
+	})
+
+	t.Run("should require the permission on all provisioners", func(t *testing.T) {
+		server, pService := setup(t)
+		dashboardsOnly := []accesscontrol.Permission{{Action: ActionProvisioningReload, Scope: ScopeProvisionersDashboards}}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/admin/provisioning/drift/scan", nil), userWithPermissions(1, dashboardsOnly)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+		assert.Empty(t, pService.Calls.ScanDrift)
+	})
+}
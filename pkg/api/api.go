@@ -579,6 +579,8 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/plugins/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/alerting/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules)), routing.Wrap(hs.AdminProvisioningReloadAlerting))
+		adminRoute.Get("/provisioning/drift", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAll)), routing.Wrap(hs.AdminProvisioningGetDriftReport))
+		adminRoute.Post("/provisioning/drift/scan", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAll)), routing.Wrap(hs.AdminProvisioningScanDrift))
 	}, reqSignedIn)
 
 	// Administering users
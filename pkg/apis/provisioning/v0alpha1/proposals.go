@@ -0,0 +1,108 @@
+package v0alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A change to the files of a repository that is reviewed before it is merged (e.g. a pull request on GitHub)
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ChangeProposal struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ChangeProposalSpec   `json:"spec,omitempty"`
+	Status ChangeProposalStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ChangeProposalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// +listType=atomic
+	Items []ChangeProposal `json:"items,omitempty"`
+}
+
+type ChangeProposalSpec struct {
+	// The name of the repository the change is proposed to
+	Repository string `json:"repository"`
+
+	// The branch with the proposed changes (e.g. written with the branch workflow)
+	Branch string `json:"branch"`
+
+	// The branch the changes are merged into.
+	// When empty, the branch of the repository is used.
+	Target string `json:"target,omitempty"`
+
+	// The title of the change request
+	Title string `json:"title"`
+
+	// The description of the change request
+	Description string `json:"description,omitempty"`
+
+	// Merge the changes into the target branch once the change request is approved
+	Merge bool `json:"merge,omitempty"`
+}
+
+// +enum
+type ChangeProposalState string
+
+const (
+	// The change request has not been opened yet
+	ChangeProposalStatePending ChangeProposalState = "pending"
+
+	// The change request is waiting for a review
+	ChangeProposalStateOpen ChangeProposalState = "open"
+
+	// The changes were merged into the target branch
+	ChangeProposalStateMerged ChangeProposalState = "merged"
+
+	// The change request was closed without merging the changes
+	ChangeProposalStateClosed ChangeProposalState = "closed"
+
+	// The change request could not be opened or merged
+	ChangeProposalStateError ChangeProposalState = "error"
+)
+
+func (s ChangeProposalState) Finished() bool {
+	return s == ChangeProposalStateMerged || s == ChangeProposalStateClosed
+}
+
+type ChangeProposalStatus struct {
+	// The generation of the spec last time the proposal was reconciled
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	State ChangeProposalState `json:"state,omitempty"`
+
+	// The number of the change request (e.g. the pull request number)
+	Number int64 `json:"number,omitempty"`
+
+	// URL to the change request
+	URL string `json:"url,omitempty"`
+
+	// The change request has been approved by a reviewer
+	Approved bool `json:"approved,omitempty"`
+
+	// The files changed by the proposal, compared to the target branch
+	// +listType=atomic
+	Files []ChangeProposalFile `json:"files,omitempty"`
+
+	// Summary messages (will be shown to users)
+	// +listType=atomic
+	Message []string `json:"message,omitempty"`
+}
+
+type ChangeProposalFile struct {
+	// The path of the file in the repository
+	Path string `json:"path"`
+
+	// created, updated, deleted or renamed
+	Action string `json:"action"`
+
+	// The path of the file before it was renamed
+	PreviousPath string `json:"previousPath,omitempty"`
+
+	// The changes of the file in the unified diff format.
+	// It is empty when the file is too large to be compared.
+	Diff string `json:"diff,omitempty"`
+}
@@ -41,6 +41,10 @@ var RepositoryResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 				target = m.Spec.Local.Path
 			case GitHubRepositoryType:
 				target = m.Spec.GitHub.URL
+			case GitRepositoryType:
+				target = m.Spec.Git.URL
+			case BucketRepositoryType:
+				target = m.Spec.Bucket.URL
 			}
 
 			return []interface{}{
@@ -81,6 +85,36 @@ var JobResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 		},
 	})
 
+var ChangeProposalResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
+	"changeproposals", "changeproposal", "ChangeProposal",
+	func() runtime.Object { return &ChangeProposal{} },     // newObj
+	func() runtime.Object { return &ChangeProposalList{} }, // newList
+	utils.TableColumns{ // Returned by `kubectl get`. Doesn't affect disk storage.
+		Definition: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Created At", Type: "date"},
+			{Name: "Repository", Type: "string"},
+			{Name: "Title", Type: "string"},
+			{Name: "State", Type: "string"},
+			{Name: "URL", Type: "string"},
+		},
+		Reader: func(obj any) ([]interface{}, error) {
+			m, ok := obj.(*ChangeProposal)
+			if !ok {
+				return nil, errors.New("expected ChangeProposal")
+			}
+
+			return []interface{}{
+				m.Name,
+				m.CreationTimestamp.UTC().Format(time.RFC3339),
+				m.Spec.Repository,
+				m.Spec.Title,
+				m.Status.State,
+				m.Status.URL,
+			}, nil
+		},
+	})
+
 var (
 	// SchemeGroupVersion is group version used to register these objects
 	SchemeGroupVersion   = schema.GroupVersion{Group: GROUP, Version: VERSION}
@@ -117,6 +151,8 @@ func AddKnownTypes(gv schema.GroupVersion, scheme *runtime.Scheme) error {
 		&ResourceStats{},
 		&Job{},
 		&JobList{},
+		&ChangeProposal{},
+		&ChangeProposalList{},
 	)
 	return nil
 }
@@ -51,6 +51,51 @@ type GitHubRepositoryConfig struct {
 	GenerateDashboardPreviews bool `json:"generateDashboardPreviews,omitempty"`
 }
 
+type GitRepositoryConfig struct {
+	// The repository URL, over HTTPS (e.g. `https://gitea.example.com/example/test.git`)
+	// or SSH (e.g. `ssh://git@gitlab.example.com/example/test.git` or `git@gitlab.example.com:example/test.git`).
+	URL string `json:"url,omitempty"`
+
+	// The branch to use in the repository.
+	Branch string `json:"branch"`
+
+	// The username sent with the token over HTTPS. Defaults to `git`.
+	Username string `json:"username,omitempty"`
+	// Token for accessing the repository over HTTPS. If set, it will be encrypted into encryptedToken, then set to an empty string again.
+	Token string `json:"token,omitempty"`
+	// Token for accessing the repository, but encrypted. This is not possible to read back to a user decrypted.
+	// +listType=atomic
+	EncryptedToken []byte `json:"encryptedToken,omitempty"`
+
+	// Private key (e.g. a deploy key) for accessing the repository over SSH. If set, it will be encrypted into encryptedSSHKey, then set to an empty string again.
+	SSHKey string `json:"sshKey,omitempty"`
+	// Private SSH key, but encrypted. This is not possible to read back to a user decrypted.
+	// +listType=atomic
+	EncryptedSSHKey []byte `json:"encryptedSSHKey,omitempty"`
+	// Known hosts entries used to verify the SSH server.
+	// Required when the url uses SSH.
+	KnownHosts string `json:"knownHosts,omitempty"`
+}
+
+type BucketRepositoryConfig struct {
+	// The bucket URL, in the format of the Go CDK (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `azblob://my-container`).
+	// S3 URLs also accept an `endpoint` and `use_path_style` for S3 compatible storages.
+	URL string `json:"url"`
+
+	// Only objects with this prefix are used (e.g. `dashboards/`).
+	Prefix string `json:"prefix,omitempty"`
+
+	// The access key ID for S3, or the storage account name for Azure. Not used for GCS.
+	// The credentials of the server are never used, so that repositories can only access the buckets of their credentials.
+	AccessKey string `json:"accessKey,omitempty"`
+	// The secret access key for S3, the storage account key for Azure, or the JSON key of a service account for GCS.
+	// If set, it will be encrypted into encryptedSecretKey, then set to an empty string again.
+	SecretKey string `json:"secretKey,omitempty"`
+	// The secret key, but encrypted. This is not possible to read back to a user decrypted.
+	// +listType=atomic
+	EncryptedSecretKey []byte `json:"encryptedSecretKey,omitempty"`
+}
+
 // RepositoryType defines the types of Repository
 // +enum
 type RepositoryType string
@@ -59,6 +104,8 @@ type RepositoryType string
 const (
 	LocalRepositoryType  RepositoryType = "local"
 	GitHubRepositoryType RepositoryType = "github"
+	GitRepositoryType    RepositoryType = "git"
+	BucketRepositoryType RepositoryType = "bucket"
 )
 
 type RepositorySpec struct {
@@ -80,13 +127,20 @@ type RepositorySpec struct {
 	Type RepositoryType `json:"type"`
 
 	// The repository on the local file system.
-	// Mutually exclusive with local | github.
+	// Mutually exclusive with local | github | git | bucket.
 	Local *LocalRepositoryConfig `json:"local,omitempty"`
 
 	// The repository on GitHub.
-	// Mutually exclusive with local | github.
-	// TODO: github or just 'git'??
+	// Mutually exclusive with local | github | git | bucket.
 	GitHub *GitHubRepositoryConfig `json:"github,omitempty"`
+
+	// The repository on any Git server (e.g. Gitea, GitLab or a plain Git server).
+	// Mutually exclusive with local | github | git | bucket.
+	Git *GitRepositoryConfig `json:"git,omitempty"`
+
+	// The repository in an object storage bucket (e.g. S3, GCS or Azure Blob Storage).
+	// Mutually exclusive with local | github | git | bucket.
+	Bucket *BucketRepositoryConfig `json:"bucket,omitempty"`
 }
 
 // SyncTargetType defines where we want all values to resolve
@@ -27,6 +27,146 @@ func (in *Author) DeepCopy() *Author {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketRepositoryConfig) DeepCopyInto(out *BucketRepositoryConfig) {
+	*out = *in
+	if in.EncryptedSecretKey != nil {
+		in, out := &in.EncryptedSecretKey, &out.EncryptedSecretKey
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketRepositoryConfig.
+func (in *BucketRepositoryConfig) DeepCopy() *BucketRepositoryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketRepositoryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeProposal) DeepCopyInto(out *ChangeProposal) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeProposal.
+func (in *ChangeProposal) DeepCopy() *ChangeProposal {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeProposal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChangeProposal) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeProposalFile) DeepCopyInto(out *ChangeProposalFile) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeProposalFile.
+func (in *ChangeProposalFile) DeepCopy() *ChangeProposalFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeProposalFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeProposalList) DeepCopyInto(out *ChangeProposalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ChangeProposal, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeProposalList.
+func (in *ChangeProposalList) DeepCopy() *ChangeProposalList {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeProposalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChangeProposalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeProposalSpec) DeepCopyInto(out *ChangeProposalSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeProposalSpec.
+func (in *ChangeProposalSpec) DeepCopy() *ChangeProposalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeProposalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeProposalStatus) DeepCopyInto(out *ChangeProposalStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]ChangeProposalFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.Message != nil {
+		in, out := &in.Message, &out.Message
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeProposalStatus.
+func (in *ChangeProposalStatus) DeepCopy() *ChangeProposalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeProposalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportJobOptions) DeepCopyInto(out *ExportJobOptions) {
 	*out = *in
@@ -111,6 +251,32 @@ func (in *GitHubRepositoryConfig) DeepCopy() *GitHubRepositoryConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepositoryConfig) DeepCopyInto(out *GitRepositoryConfig) {
+	*out = *in
+	if in.EncryptedToken != nil {
+		in, out := &in.EncryptedToken, &out.EncryptedToken
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.EncryptedSSHKey != nil {
+		in, out := &in.EncryptedSSHKey, &out.EncryptedSSHKey
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositoryConfig.
+func (in *GitRepositoryConfig) DeepCopy() *GitRepositoryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepositoryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthStatus) DeepCopyInto(out *HealthStatus) {
 	*out = *in
@@ -459,6 +625,16 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 		*out = new(GitHubRepositoryConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitRepositoryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bucket != nil {
+		in, out := &in.Bucket, &out.Bucket
+		*out = new(BucketRepositoryConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
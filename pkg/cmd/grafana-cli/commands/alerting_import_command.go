@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const importRulerPath = "/api/convert/prometheus/import"
+
+var (
+	errMissingGrafanaToken = errors.New("missing Grafana token, use --token or the GRAFANA_TOKEN environment variable")
+	errMissingRulerURL     = errors.New("missing ruler URL, use --ruler-url")
+	errMissingDatasource   = errors.New("missing datasource UID, use --datasource-uid")
+)
+
+// importRulerOptions contains the settings of a run of the import-ruler command.
+type importRulerOptions struct {
+	grafanaURL string
+	token      string
+	orgID      int
+	tenants    []string
+	request    apimodels.ConvertPrometheusImportRulerRequest
+}
+
+// importRulerCommand imports the rules of a Prometheus-compatible ruler into a running Grafana
+// through the convert API. Rules of every tenant are imported one after another.
+func importRulerCommand(c utils.CommandLine) error {
+	opts := importRulerOptions{
+		grafanaURL: c.String("grafana-url"),
+		token:      c.String("token"),
+		orgID:      c.Int("org-id"),
+		tenants:    c.StringSlice("tenant"),
+		request: apimodels.ConvertPrometheusImportRulerRequest{
+			RulerURL:             c.String("ruler-url"),
+			RulesPath:            c.String("rules-path"),
+			BasicAuthUser:        c.String("ruler-user"),
+			BasicAuthPassword:    c.String("ruler-password"),
+			DatasourceUID:        c.String("datasource-uid"),
+			Namespaces:           c.StringSlice("namespace"),
+			RecordingRulesPaused: c.Bool("pause-recording-rules"),
+			AlertRulesPaused:     c.Bool("pause-alert-rules"),
+			Apply:                c.Bool("apply"),
+			DeleteRemoved:        c.Bool("delete-removed"),
+		},
+	}
+	return importRuler(opts, &http.Client{Timeout: 5 * time.Minute})
+}
+
+func importRuler(opts importRulerOptions, client *http.Client) error {
+	if opts.token == "" {
+		return errMissingGrafanaToken
+	}
+	if opts.request.RulerURL == "" {
+		return errMissingRulerURL
+	}
+	if opts.request.DatasourceUID == "" {
+		return errMissingDatasource
+	}
+	endpoint, err := url.JoinPath(opts.grafanaURL, importRulerPath)
+	if err != nil {
+		return fmt.Errorf("invalid Grafana URL: %w", err)
+	}
+
+	tenants := opts.tenants
+	if len(tenants) == 0 {
+		tenants = []string{""}
+	}
+	failed := 0
+	for _, tenant := range tenants {
+		req := opts.request
+		req.TenantID = tenant
+		if tenant != "" {
+			logger.Infof("Tenant %s\n", tenant)
+		}
+
+		result, err := postImportRuler(client, endpoint, opts, req)
+		if err != nil {
+			return err
+		}
+		printImportRulerResult(result)
+		failed += result.Summary.FailedGroups
+	}
+
+	if !opts.request.Apply {
+		logger.Info("Dry run, no rules were changed. Use --apply to import the rules.\n")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d rule groups could not be imported", failed)
+	}
+	return nil
+}
+
+func postImportRuler(client *http.Client, endpoint string, opts importRulerOptions, body apimodels.ConvertPrometheusImportRulerRequest) (apimodels.ConvertPrometheusImportRulerResponse, error) {
+	var result apimodels.ConvertPrometheusImportRulerResponse
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return result, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+opts.token)
+	if opts.orgID > 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.Itoa(opts.orgID))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("failed to call Grafana: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("grafana responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return result, fmt.Errorf("failed to parse the response of Grafana: %w", err)
+	}
+	return result, nil
+}
+
+func printImportRulerResult(result apimodels.ConvertPrometheusImportRulerResponse) {
+	for _, rule := range result.Rules {
+		line := fmt.Sprintf("%-16s %s/%s: %s", rule.Action, rule.Namespace, rule.Group, rule.Title)
+		if len(rule.Diff) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(rule.Diff, ", "))
+		}
+		logger.Info(line + "\n")
+	}
+	for _, w := range result.Warnings {
+		if w.Group != "" {
+			logger.Warnf("warning: %s/%s: %s\n", w.Namespace, w.Group, w.Message)
+			continue
+		}
+		logger.Warnf("warning: %s: %s\n", w.Namespace, w.Message)
+	}
+	s := result.Summary
+	logger.Infof("create: %d, update: %d, unchanged: %d, delete: %d, removed upstream: %d, failed groups: %d\n",
+		s.Create, s.Update, s.Unchanged, s.Delete, s.RemovedUpstream, s.FailedGroups)
+}
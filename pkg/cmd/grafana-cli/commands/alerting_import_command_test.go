@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestImportRuler(t *testing.T) {
+	opts := importRulerOptions{
+		token: "token",
+		orgID: 2,
+		request: apimodels.ConvertPrometheusImportRulerRequest{
+			RulerURL:      "http://mimir:8080",
+			DatasourceUID: "mimir",
+		},
+	}
+
+	t.Run("should import the rules of every tenant", func(t *testing.T) {
+		var requests []apimodels.ConvertPrometheusImportRulerRequest
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, importRulerPath, r.URL.Path)
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			require.Equal(t, "2", r.Header.Get("X-Grafana-Org-Id"))
+			var body apimodels.ConvertPrometheusImportRulerRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			requests = append(requests, body)
+			_ = json.NewEncoder(w).Encode(apimodels.ConvertPrometheusImportRulerResponse{
+				DryRun: true,
+				Rules:  []apimodels.ConvertPrometheusImportRuleChange{{Namespace: "ns", Group: "group", Title: "rule", Action: "create"}},
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		o := opts
+		o.grafanaURL = srv.URL
+		o.tenants = []string{"tenant-1", "tenant-2"}
+		require.NoError(t, importRuler(o, srv.Client()))
+
+		require.Len(t, requests, 2)
+		require.Equal(t, "tenant-1", requests[0].TenantID)
+		require.Equal(t, "tenant-2", requests[1].TenantID)
+		require.Equal(t, "http://mimir:8080", requests[1].RulerURL)
+	})
+
+	t.Run("should return error if groups failed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(apimodels.ConvertPrometheusImportRulerResponse{
+				Summary: apimodels.ConvertPrometheusImportSummary{FailedGroups: 1},
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		o := opts
+		o.grafanaURL = srv.URL
+		require.ErrorContains(t, importRuler(o, srv.Client()), "1 rule groups could not be imported")
+	})
+
+	t.Run("should return error if Grafana fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"Failed to read rules from the ruler"}`, http.StatusBadGateway)
+		}))
+		t.Cleanup(srv.Close)
+
+		o := opts
+		o.grafanaURL = srv.URL
+		err := importRuler(o, srv.Client())
+		require.ErrorContains(t, err, "502")
+		require.ErrorContains(t, err, "Failed to read rules from the ruler")
+	})
+
+	t.Run("should validate options", func(t *testing.T) {
+		o := opts
+		o.token = ""
+		require.ErrorIs(t, importRuler(o, http.DefaultClient), errMissingGrafanaToken)
+
+		o = opts
+		o.request.RulerURL = ""
+		require.ErrorIs(t, importRuler(o, http.DefaultClient), errMissingRulerURL)
+
+		o = opts
+		o.request.DatasourceUID = ""
+		require.ErrorIs(t, importRuler(o, http.DefaultClient), errMissingDatasource)
+	})
+}
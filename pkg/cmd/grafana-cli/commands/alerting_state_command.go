@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	exportStatePath  = "/api/v1/ngalert/state/export"
+	restoreStatePath = "/api/v1/ngalert/state/restore"
+)
+
+var (
+	errMissingOutput = errors.New("missing output file, use --output")
+	errMissingInput  = errors.New("missing input file, use --input")
+)
+
+// alertStateOptions contains the settings of a run of the export-state and restore-state commands.
+type alertStateOptions struct {
+	grafanaURL string
+	token      string
+	orgID      int
+	file       string
+	ruleUIDs   map[string]string
+	dryRun     bool
+}
+
+// exportStateCommand writes the state of the alert instances, the notification log and the silences
+// of an organization of a running Grafana to a file.
+func exportStateCommand(c utils.CommandLine) error {
+	opts := alertStateOptions{
+		grafanaURL: c.String("grafana-url"),
+		token:      c.String("token"),
+		orgID:      c.Int("org-id"),
+		file:       c.String("output"),
+	}
+	return exportState(opts, &http.Client{Timeout: 5 * time.Minute})
+}
+
+// restoreStateCommand restores a file written by export-state into an organization of a running Grafana.
+func restoreStateCommand(c utils.CommandLine) error {
+	ruleUIDs, err := parseRuleUIDMap(c.StringSlice("rule-uid-map"))
+	if err != nil {
+		return err
+	}
+	opts := alertStateOptions{
+		grafanaURL: c.String("grafana-url"),
+		token:      c.String("token"),
+		orgID:      c.Int("org-id"),
+		file:       c.String("input"),
+		ruleUIDs:   ruleUIDs,
+		dryRun:     c.Bool("dry-run"),
+	}
+	return restoreState(opts, &http.Client{Timeout: 5 * time.Minute})
+}
+
+// parseRuleUIDMap parses pairs of rule UIDs in the form old=new.
+func parseRuleUIDMap(pairs []string) (map[string]string, error) {
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid rule UID mapping %q, expected <old UID>=<new UID>", pair)
+		}
+		result[from] = to
+	}
+	return result, nil
+}
+
+func exportState(opts alertStateOptions, client *http.Client) error {
+	if opts.token == "" {
+		return errMissingGrafanaToken
+	}
+	if opts.file == "" {
+		return errMissingOutput
+	}
+
+	var snapshot apimodels.AlertStateSnapshot
+	if err := callAlertStateAPI(client, http.MethodGet, exportStatePath, opts, nil, &snapshot); err != nil {
+		return err
+	}
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(opts.file, b, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.file, err)
+	}
+	logger.Infof("Exported %d alert instances of org %d to %s\n", len(snapshot.Instances), snapshot.OrgID, opts.file)
+	return nil
+}
+
+func restoreState(opts alertStateOptions, client *http.Client) error {
+	if opts.token == "" {
+		return errMissingGrafanaToken
+	}
+	if opts.file == "" {
+		return errMissingInput
+	}
+
+	b, err := os.ReadFile(opts.file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.file, err)
+	}
+	req := apimodels.AlertStateRestoreRequest{RuleUIDs: opts.ruleUIDs, DryRun: opts.dryRun}
+	if err := json.Unmarshal(b, &req.Snapshot); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", opts.file, err)
+	}
+
+	var result apimodels.AlertStateRestoreResult
+	if err := callAlertStateAPI(client, http.MethodPost, restoreStatePath, opts, req, &result); err != nil {
+		return err
+	}
+	for _, uid := range result.MissingRules {
+		logger.Warnf("warning: alert rule %s does not exist, its alert instances were skipped\n", uid)
+	}
+	logger.Infof("alert instances: %d, skipped: %d, silences: %d, notification log entries: %d\n",
+		result.Instances, result.SkippedInstances, result.Silences, result.NotificationLogEntries)
+	if result.DryRun {
+		logger.Info("Dry run, no state was restored.\n")
+	}
+	return nil
+}
+
+func callAlertStateAPI(client *http.Client, method string, path string, opts alertStateOptions, body any, result any) error {
+	endpoint, err := url.JoinPath(opts.grafanaURL, path)
+	if err != nil {
+		return fmt.Errorf("invalid Grafana URL: %w", err)
+	}
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+opts.token)
+	if opts.orgID > 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.Itoa(opts.orgID))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Grafana: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("grafana responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to parse the response of Grafana: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestExportAndRestoreState(t *testing.T) {
+	snapshot := apimodels.AlertStateSnapshot{
+		Version: apimodels.AlertStateSnapshotVersion,
+		OrgID:   2,
+		Instances: []apimodels.AlertInstanceSnapshot{
+			{RuleUID: "old-uid", Labels: map[string]string{"alertname": "rule"}, State: "Alerting"},
+		},
+		Silences: []byte{0x01, 0x02},
+	}
+	var restoreRequest apimodels.AlertStateRestoreRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.Equal(t, "2", r.Header.Get("X-Grafana-Org-Id"))
+		switch r.URL.Path {
+		case exportStatePath:
+			_ = json.NewEncoder(w).Encode(snapshot)
+		case restoreStatePath:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&restoreRequest))
+			_ = json.NewEncoder(w).Encode(apimodels.AlertStateRestoreResult{Instances: 1, Silences: 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	opts := alertStateOptions{
+		grafanaURL: srv.URL,
+		token:      "token",
+		orgID:      2,
+		file:       filepath.Join(t.TempDir(), "state.json"),
+		ruleUIDs:   map[string]string{"old-uid": "new-uid"},
+	}
+
+	require.NoError(t, exportState(opts, srv.Client()))
+	b, err := os.ReadFile(opts.file)
+	require.NoError(t, err)
+	var exported apimodels.AlertStateSnapshot
+	require.NoError(t, json.Unmarshal(b, &exported))
+	require.Equal(t, snapshot, exported)
+
+	require.NoError(t, restoreState(opts, srv.Client()))
+	require.Equal(t, snapshot, restoreRequest.Snapshot)
+	require.Equal(t, map[string]string{"old-uid": "new-uid"}, restoreRequest.RuleUIDs)
+
+	t.Run("should return error if token is missing", func(t *testing.T) {
+		o := opts
+		o.token = ""
+		require.ErrorIs(t, exportState(o, srv.Client()), errMissingGrafanaToken)
+		require.ErrorIs(t, restoreState(o, srv.Client()), errMissingGrafanaToken)
+	})
+}
+
+func TestParseRuleUIDMap(t *testing.T) {
+	result, err := parseRuleUIDMap([]string{"a=b", "c=d"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "b", "c": "d"}, result)
+
+	_, err = parseRuleUIDMap([]string{"a"})
+	require.Error(t, err)
+	_, err = parseRuleUIDMap([]string{"=b"})
+	require.Error(t, err)
+}
@@ -172,6 +172,137 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Alerting migration commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "import-ruler",
+				Usage:  "Imports the rules of a Prometheus-compatible ruler (Cortex, Mimir or Loki) into a running Grafana. Dry run unless --apply is set. Safe to execute multiple times.",
+				Action: runPluginCommand(importRulerCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "grafana-url",
+						Usage: "URL of the Grafana instance to import the rules to",
+						Value: "http://localhost:3000",
+					},
+					&cli.StringFlag{
+						Name:    "token",
+						Usage:   "Service account token used to call Grafana",
+						EnvVars: []string{"GRAFANA_TOKEN"},
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization to import the rules to",
+					},
+					&cli.StringFlag{
+						Name:  "ruler-url",
+						Usage: "Base URL of the ruler, for example http://mimir:8080/prometheus",
+					},
+					&cli.StringFlag{
+						Name:  "rules-path",
+						Usage: "Path of the rules endpoint of the ruler, use /loki/api/v1/rules for Loki",
+						Value: "/api/v1/rules",
+					},
+					&cli.StringSliceFlag{
+						Name:  "tenant",
+						Usage: "Tenant whose rules are imported. Can be repeated to import the rules of several tenants",
+					},
+					&cli.StringFlag{
+						Name:  "ruler-user",
+						Usage: "Basic authentication user of the ruler",
+					},
+					&cli.StringFlag{
+						Name:    "ruler-password",
+						Usage:   "Basic authentication password of the ruler",
+						EnvVars: []string{"GRAFANA_RULER_PASSWORD"},
+					},
+					&cli.StringFlag{
+						Name:  "datasource-uid",
+						Usage: "UID of the data source that the imported rules query",
+					},
+					&cli.StringSliceFlag{
+						Name:  "namespace",
+						Usage: "Namespace to import. Can be repeated. All namespaces are imported if it is not set",
+					},
+					&cli.BoolFlag{
+						Name:  "pause-alert-rules",
+						Usage: "Import alert rules paused",
+					},
+					&cli.BoolFlag{
+						Name:  "pause-recording-rules",
+						Usage: "Import recording rules paused",
+					},
+					&cli.BoolFlag{
+						Name:  "apply",
+						Usage: "Save the imported rules. Without it, only the changes are printed",
+					},
+					&cli.BoolFlag{
+						Name:  "delete-removed",
+						Usage: "Delete imported rule groups that do not exist in the ruler anymore",
+					},
+				},
+			},
+			{
+				Name:   "export-state",
+				Usage:  "Exports the state of the alert instances, the notification log and the silences of an organization to a file, so it can be restored in another Grafana instance.",
+				Action: runPluginCommand(exportStateCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "grafana-url",
+						Usage: "URL of the Grafana instance",
+						Value: "http://localhost:3000",
+					},
+					&cli.StringFlag{
+						Name:    "token",
+						Usage:   "Service account token of an organization admin used to call Grafana",
+						EnvVars: []string{"GRAFANA_TOKEN"},
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization",
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "File to write the alert state to",
+					},
+				},
+			},
+			{
+				Name:   "restore-state",
+				Usage:  "Restores the alert state exported by export-state into an organization. Restored state takes precedence over existing state.",
+				Action: runPluginCommand(restoreStateCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "grafana-url",
+						Usage: "URL of the Grafana instance",
+						Value: "http://localhost:3000",
+					},
+					&cli.StringFlag{
+						Name:    "token",
+						Usage:   "Service account token of an organization admin used to call Grafana",
+						EnvVars: []string{"GRAFANA_TOKEN"},
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization",
+					},
+					&cli.StringFlag{
+						Name:  "input",
+						Usage: "File written by export-state",
+					},
+					&cli.StringSliceFlag{
+						Name:  "rule-uid-map",
+						Usage: "UID of an alert rule in the exported instance and its UID in this instance, as <old UID>=<new UID>. Can be repeated",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only validate the file and print what would be restored",
+					},
+				},
+			},
+		},
+	},
 }
 
 var Commands = []*cli.Command{
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
 )
 
 var (
@@ -59,6 +60,16 @@ func (s *stubProvisioning) ProvisionPlugins(ctx context.Context) error {
 	panic("unimplemented")
 }
 
+// ScanDrift implements provisioning.ProvisioningService.
+func (s *stubProvisioning) ScanDrift(ctx context.Context) *drift.Report {
+	panic("unimplemented")
+}
+
+// GetDriftReport implements provisioning.ProvisioningService.
+func (s *stubProvisioning) GetDriftReport() *drift.Report {
+	return nil
+}
+
 // Run implements provisioning.ProvisioningService.
 func (s *stubProvisioning) Run(ctx context.Context) error {
 	panic("unimplemented")
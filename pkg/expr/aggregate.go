@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AggregateCommand is an expression command that groups series or numbers by their labels
+// and reduces each group to a single series or number, like `sum by (cluster)` in PromQL.
+type AggregateCommand struct {
+	ReferenceVar   string
+	Reducer        mathexp.ReducerID
+	ReducerOptions mathexp.ReducerOptions
+	Grouping       mathexp.Grouping
+	refID          string
+	seriesMapper   mathexp.ReduceMapper
+}
+
+// NewAggregateCommand creates a new AggregateCommand. Only one of by and without can be set,
+// when neither is set all values are aggregated into a single group.
+func NewAggregateCommand(refID, referenceVar string, reducer mathexp.ReducerID, opts mathexp.ReducerOptions, by, without []string, mapper mathexp.ReduceMapper) (*AggregateCommand, error) {
+	if _, err := mathexp.GetReduceFuncWithOptions(reducer, opts); err != nil {
+		return nil, err
+	}
+	if len(by) > 0 && len(without) > 0 {
+		return nil, errors.New("only one of by and without can be specified for aggregate")
+	}
+	grouping := mathexp.Grouping{Labels: by}
+	if len(without) > 0 {
+		grouping = mathexp.Grouping{Labels: without, Without: true}
+	}
+	for _, l := range grouping.Labels {
+		if l == "" {
+			return nil, errors.New("aggregate labels must not be empty")
+		}
+	}
+	return &AggregateCommand{
+		ReferenceVar:   referenceVar,
+		Reducer:        reducer,
+		ReducerOptions: opts,
+		Grouping:       grouping,
+		refID:          refID,
+		seriesMapper:   mapper,
+	}, nil
+}
+
+// UnmarshalAggregateCommand creates an AggregateCommand from Grafana's frontend query.
+func UnmarshalAggregateCommand(rn *rawNode) (*AggregateCommand, error) {
+	q := AggregateQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the aggregate command: %w", err)
+	}
+	return newAggregateCommandFromQuery(rn.RefID, q)
+}
+
+func newAggregateCommandFromQuery(refID string, q AggregateQuery) (*AggregateCommand, error) {
+	referenceVar, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	var opts mathexp.ReducerOptions
+	var mapper mathexp.ReduceMapper
+	if q.Settings != nil {
+		opts.Threshold = q.Settings.Threshold
+		switch q.Settings.Mode {
+		case ReduceModeStrict:
+		case ReduceModeDrop:
+			mapper = mathexp.DropNonNumber{}
+		case ReduceModeReplace:
+			if q.Settings.ReplaceWithValue == nil {
+				return nil, fmt.Errorf("setting replaceWithValue must be specified when mode is '%s'", q.Settings.Mode)
+			}
+			mapper = mathexp.ReplaceNonNumberWithValue{Value: *q.Settings.ReplaceWithValue}
+		default:
+			return nil, fmt.Errorf("reducer mode '%s' is not supported. Supported only: [%s,%s]", q.Settings.Mode, ReduceModeDrop, ReduceModeReplace)
+		}
+	}
+	reducer := mathexp.ReducerID(strings.ToLower(string(q.Reducer)))
+	return NewAggregateCommand(refID, referenceVar, reducer, opts, q.By, q.Without, mapper)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AggregateCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AggregateCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAggregate")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("reducer", string(ac.Reducer)),
+		attribute.StringSlice("labels", ac.Grouping.Labels),
+		attribute.Bool("without", ac.Grouping.Without),
+	)
+
+	values, err := mathexp.Aggregate(ac.refID, vars[ac.ReferenceVar].Values, ac.Grouping, ac.Reducer, ac.ReducerOptions, ac.seriesMapper)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to aggregate %s: %w", ac.ReferenceVar, err)
+	}
+	return mathexp.Results{Values: values}, nil
+}
+
+func (ac *AggregateCommand) Type() string {
+	return TypeAggregate.String()
+}
@@ -0,0 +1,61 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestAggregateCommand(t *testing.T) {
+	t.Run("parses the query", func(t *testing.T) {
+		cmd, err := UnmarshalAggregateCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","reducer":"Sum","without":["pod"],"settings":{"mode":"dropNN"}}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A"}, cmd.NeedsVars())
+		assert.Equal(t, mathexp.ReducerSum, cmd.Reducer)
+		assert.Equal(t, mathexp.Grouping{Labels: []string{"pod"}, Without: true}, cmd.Grouping)
+		assert.Equal(t, mathexp.DropNonNumber{}, cmd.seriesMapper)
+	})
+
+	for name, raw := range map[string]string{
+		"by and without":  `{"expression":"$A","reducer":"sum","by":["cluster"],"without":["pod"]}`,
+		"unknown reducer": `{"expression":"$A","reducer":"foo"}`,
+		"no expression":   `{"reducer":"sum"}`,
+		"empty label":     `{"expression":"$A","reducer":"sum","by":[""]}`,
+		"unknown mode":    `{"expression":"$A","reducer":"sum","settings":{"mode":"foo"}}`,
+	} {
+		t.Run("fails on "+name, func(t *testing.T) {
+			_, err := UnmarshalAggregateCommand(&rawNode{RefID: "B", QueryRaw: []byte(raw)})
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("aggregates numbers by label", func(t *testing.T) {
+		cmd, err := NewAggregateCommand("B", "A", mathexp.ReducerMax, mathexp.ReducerOptions{}, []string{"cluster"}, nil, nil)
+		require.NoError(t, err)
+
+		vars := mathexp.Vars{"A": mathexp.Results{}}
+		for i, v := range []float64{1, 5, 3} {
+			n := mathexp.NewNumber("A", data.Labels{"cluster": "eu", "pod": string(rune('a' + i))})
+			n.SetValue(util.Pointer(v))
+			vars["A"] = mathexp.Results{Values: append(vars["A"].Values, n)}
+		}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		n := res.Values[0].(mathexp.Number)
+		assert.Equal(t, 5.0, *n.GetFloat64Value())
+		assert.Equal(t, data.Labels{"cluster": "eu"}, n.GetLabels())
+	})
+}
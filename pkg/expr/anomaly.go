@@ -0,0 +1,314 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	defaultOutliersThreshold = 3.0
+	defaultSeasonalAlpha     = 0.5
+	defaultSeasonalBeta      = 0.1
+	defaultSeasonalGamma     = 0.3
+	defaultSeasonalDeviation = 3.0
+)
+
+// OutliersCommand is an expression command that detects outliers with the z-score or the median absolute deviation.
+// Series are scored point by point against their own values, numbers are scored against each other.
+type OutliersCommand struct {
+	ReferenceVar string
+	Algorithm    mathexp.OutlierAlgorithm
+	Threshold    float64
+	Output       OutliersOutput
+	refID        string
+}
+
+// NewOutliersCommand creates a new OutliersCommand.
+func NewOutliersCommand(refID, referenceVar string, algorithm mathexp.OutlierAlgorithm, threshold *float64, output OutliersOutput) (*OutliersCommand, error) {
+	switch algorithm {
+	case mathexp.OutlierZScore, mathexp.OutlierMAD:
+	default:
+		return nil, fmt.Errorf("expected outlier algorithm to be one of [%s, %s], got %q", mathexp.OutlierZScore, mathexp.OutlierMAD, algorithm)
+	}
+	switch output {
+	case "":
+		output = OutliersOutputFlag
+	case OutliersOutputFlag, OutliersOutputScore:
+	default:
+		return nil, fmt.Errorf("expected outliers output to be one of [%s, %s], got %q", OutliersOutputFlag, OutliersOutputScore, output)
+	}
+	t := defaultOutliersThreshold
+	if threshold != nil {
+		if *threshold <= 0 {
+			return nil, fmt.Errorf("outliers threshold must be positive, got %v", *threshold)
+		}
+		t = *threshold
+	}
+	return &OutliersCommand{
+		ReferenceVar: referenceVar,
+		Algorithm:    algorithm,
+		Threshold:    t,
+		Output:       output,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalOutliersCommand creates an OutliersCommand from Grafana's frontend query.
+func UnmarshalOutliersCommand(rn *rawNode) (*OutliersCommand, error) {
+	q := OutliersQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the outliers command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewOutliersCommand(rn.RefID, referenceVar, q.Algorithm, q.Threshold, q.Output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (oc *OutliersCommand) NeedsVars() []string {
+	return []string{oc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (oc *OutliersCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteOutliers")
+	defer span.End()
+	span.SetAttributes(attribute.String("algorithm", string(oc.Algorithm)))
+
+	var threshold *float64
+	if oc.Output == OutliersOutputFlag {
+		threshold = &oc.Threshold
+	}
+
+	newRes := mathexp.Results{}
+	var numbers []mathexp.Number
+	for _, val := range vars[oc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			s, err := v.Outliers(oc.refID, oc.Algorithm, threshold)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.Number:
+			numbers = append(numbers, v)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect outliers in series or numbers, got type %v", val.Type())
+		}
+	}
+
+	if len(numbers) > 0 {
+		values := make([]*float64, 0, len(numbers))
+		for _, n := range numbers {
+			values = append(values, n.GetFloat64Value())
+		}
+		scores, err := mathexp.OutlierScores(values, oc.Algorithm)
+		if err != nil {
+			return newRes, err
+		}
+		for i, n := range numbers {
+			copyV := mathexp.NewNumber(oc.refID, n.GetLabels())
+			value := scores[i]
+			if value != nil && threshold != nil {
+				flag := 0.0
+				if math.Abs(*value) > *threshold {
+					flag = 1
+				}
+				value = &flag
+			}
+			copyV.SetValue(value)
+			newRes.Values = append(newRes.Values, copyV)
+		}
+	}
+	return newRes, nil
+}
+
+func (oc *OutliersCommand) Type() string {
+	return TypeOutliers.String()
+}
+
+// SeasonalCommand is an expression command that fits a Holt-Winters model to every series
+// and returns its baseline, the bounds of the expected values, or whether points are anomalous.
+type SeasonalCommand struct {
+	ReferenceVar string
+	Params       mathexp.HoltWintersParams
+	Output       mathexp.SeasonalOutput
+	refID        string
+}
+
+// NewSeasonalCommand creates a new SeasonalCommand from the query. Unset parameters get their default value.
+func NewSeasonalCommand(refID, referenceVar string, q SeasonalQuery) (*SeasonalCommand, error) {
+	season, err := gtime.ParseDuration(q.Season)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse seasonal "season" duration field %q: %w`, q.Season, err)
+	}
+	orDefault := func(v *float64, def float64) float64 {
+		if v == nil {
+			return def
+		}
+		return *v
+	}
+	output := q.Output
+	if output == "" {
+		output = mathexp.SeasonalAnomaly
+	}
+	switch output {
+	case mathexp.SeasonalBaseline, mathexp.SeasonalUpper, mathexp.SeasonalLower, mathexp.SeasonalAnomaly:
+	default:
+		return nil, fmt.Errorf("expected seasonal output to be one of [%s, %s, %s, %s], got %q", mathexp.SeasonalBaseline, mathexp.SeasonalUpper, mathexp.SeasonalLower, mathexp.SeasonalAnomaly, output)
+	}
+	params := mathexp.HoltWintersParams{
+		Season:     season,
+		Alpha:      orDefault(q.Alpha, defaultSeasonalAlpha),
+		Beta:       orDefault(q.Beta, defaultSeasonalBeta),
+		Gamma:      orDefault(q.Gamma, defaultSeasonalGamma),
+		Deviations: orDefault(q.Deviations, defaultSeasonalDeviation),
+	}
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid seasonal parameters: %w", err)
+	}
+	return &SeasonalCommand{
+		ReferenceVar: referenceVar,
+		Params:       params,
+		Output:       output,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalSeasonalCommand creates a SeasonalCommand from Grafana's frontend query.
+func UnmarshalSeasonalCommand(rn *rawNode) (*SeasonalCommand, error) {
+	q := SeasonalQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the seasonal command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewSeasonalCommand(rn.RefID, referenceVar, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (sc *SeasonalCommand) NeedsVars() []string {
+	return []string{sc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. Series that do not cover two seasons get no values and a warning.
+func (sc *SeasonalCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteSeasonal")
+	defer span.End()
+	span.SetAttributes(attribute.String("season", sc.Params.Season.String()), attribute.String("output", string(sc.Output)))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[sc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			s, err := v.HoltWinters(sc.refID, sc.Params, sc.Output)
+			if errors.Is(err, mathexp.ErrNotEnoughData) {
+				s.AddNotice(data.Notice{
+					Severity: data.NoticeSeverityWarning,
+					Text:     fmt.Sprintf("Seasonal model needs at least two seasons (%v) of data for every series of %s.", 2*sc.Params.Season, sc.ReferenceVar),
+				})
+			} else if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only fit a seasonal model to type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (sc *SeasonalCommand) Type() string {
+	return TypeSeasonal.String()
+}
+
+// ForecastCommand is an expression command that predicts the value of every series at a time
+// after the evaluation time, using a linear regression like predict_linear in PromQL.
+type ForecastCommand struct {
+	ReferenceVar string
+	Horizon      time.Duration
+	refID        string
+}
+
+// NewForecastCommand creates a new ForecastCommand.
+func NewForecastCommand(refID, referenceVar, rawHorizon string) (*ForecastCommand, error) {
+	horizon, err := gtime.ParseDuration(rawHorizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("forecast horizon must not be negative, got %v", horizon)
+	}
+	return &ForecastCommand{
+		ReferenceVar: referenceVar,
+		Horizon:      horizon,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	q := ForecastQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewForecastCommand(rn.RefID, referenceVar, q.Horizon)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	span.SetAttributes(attribute.String("horizon", fc.Horizon.String()))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			newRes.Values = append(newRes.Values, v.PredictLinear(fc.refID, now.Add(fc.Horizon)))
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
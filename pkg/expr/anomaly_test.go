@@ -0,0 +1,160 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func newMinutelySeries(start time.Time, labels data.Labels, values ...float64) mathexp.Series {
+	s := mathexp.NewSeries("A", labels, len(values))
+	for i, v := range values {
+		s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), util.Pointer(v))
+	}
+	return s
+}
+
+func TestOutliersCommand(t *testing.T) {
+	t.Run("parses the query with defaults", func(t *testing.T) {
+		cmd, err := UnmarshalOutliersCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","algorithm":"zscore"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A"}, cmd.NeedsVars())
+		assert.Equal(t, defaultOutliersThreshold, cmd.Threshold)
+		assert.Equal(t, OutliersOutputFlag, cmd.Output)
+	})
+
+	t.Run("fails on unknown algorithm", func(t *testing.T) {
+		_, err := UnmarshalOutliersCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","algorithm":"foo"}`),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("scores numbers against each other", func(t *testing.T) {
+		cmd, err := NewOutliersCommand("B", "A", mathexp.OutlierMAD, util.Pointer(3.5), OutliersOutputFlag)
+		require.NoError(t, err)
+
+		vars := mathexp.Vars{"A": mathexp.Results{}}
+		for i, v := range []float64{10, 11, 9, 10, 50} {
+			n := mathexp.NewNumber("A", data.Labels{"host": string(rune('a' + i))})
+			n.SetValue(util.Pointer(v))
+			vars["A"] = mathexp.Results{Values: append(vars["A"].Values, n)}
+		}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 5)
+		for i, v := range res.Values {
+			n := v.(mathexp.Number)
+			expected := 0.0
+			if i == 4 {
+				expected = 1
+			}
+			assert.Equal(t, expected, *n.GetFloat64Value())
+			assert.Equal(t, data.Labels{"host": string(rune('a' + i))}, n.GetLabels())
+		}
+	})
+
+	t.Run("returns scores of series", func(t *testing.T) {
+		cmd, err := NewOutliersCommand("B", "A", mathexp.OutlierZScore, nil, OutliersOutputScore)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{newMinutelySeries(time.Unix(0, 0), nil, 1, 2, 3)}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s := res.Values[0].(mathexp.Series)
+		assert.InDelta(t, 1.22, *s.GetValue(2), 0.01)
+	})
+}
+
+func TestSeasonalCommand(t *testing.T) {
+	t.Run("parses the query with defaults", func(t *testing.T) {
+		cmd, err := UnmarshalSeasonalCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","season":"1d"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, mathexp.HoltWintersParams{
+			Season:     24 * time.Hour,
+			Alpha:      defaultSeasonalAlpha,
+			Beta:       defaultSeasonalBeta,
+			Gamma:      defaultSeasonalGamma,
+			Deviations: defaultSeasonalDeviation,
+		}, cmd.Params)
+		assert.Equal(t, mathexp.SeasonalAnomaly, cmd.Output)
+	})
+
+	t.Run("fails on invalid parameters", func(t *testing.T) {
+		_, err := UnmarshalSeasonalCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","season":"1d","alpha":1.5}`),
+		})
+		require.Error(t, err)
+		_, err = UnmarshalSeasonalCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","season":"1d","output":"foo"}`),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("warns when there is not enough data", func(t *testing.T) {
+		cmd, err := NewSeasonalCommand("B", "A", SeasonalQuery{Season: "10m"})
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{newMinutelySeries(time.Unix(0, 0), nil, 1, 2, 3)}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s := res.Values[0].(mathexp.Series)
+		assert.Nil(t, s.GetValue(2))
+		require.Len(t, s.AsDataFrame().Meta.Notices, 1)
+	})
+
+	t.Run("passes no data through", func(t *testing.T) {
+		cmd, err := NewSeasonalCommand("B", "A", SeasonalQuery{Season: "10m"})
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		assert.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+}
+
+func TestForecastCommand(t *testing.T) {
+	t.Run("fails on invalid horizon", func(t *testing.T) {
+		_, err := UnmarshalForecastCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"expression":"$A","horizon":"soon"}`),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("predicts the value after the evaluation time", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", "1h")
+		require.NoError(t, err)
+
+		now := time.Unix(0, 0).Add(10 * time.Minute)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+			newMinutelySeries(time.Unix(0, 0), data.Labels{"disk": "sda"}, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+		}}}
+		res, err := cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		n := res.Values[0].(mathexp.Number)
+		assert.InDelta(t, 70, *n.GetFloat64Value(), 0.0001)
+		assert.Equal(t, data.Labels{"disk": "sda"}, n.GetLabels())
+	})
+}
@@ -82,24 +82,26 @@ func (gm *MathCommand) Type() string {
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
-	Reducer      mathexp.ReducerID
-	VarToReduce  string
-	refID        string
-	seriesMapper mathexp.ReduceMapper
+	Reducer        mathexp.ReducerID
+	ReducerOptions mathexp.ReducerOptions
+	VarToReduce    string
+	refID          string
+	seriesMapper   mathexp.ReduceMapper
 }
 
 // NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+func NewReduceCommand(refID string, reducer mathexp.ReducerID, opts mathexp.ReducerOptions, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	_, err := mathexp.GetReduceFuncWithOptions(reducer, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
-		Reducer:      reducer,
-		VarToReduce:  varToReduce,
-		refID:        refID,
-		seriesMapper: mapper,
+		Reducer:        reducer,
+		ReducerOptions: opts,
+		VarToReduce:    varToReduce,
+		refID:          refID,
+		seriesMapper:   mapper,
 	}, nil
 }
 
@@ -126,10 +128,18 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	redFunc := mathexp.ReducerID(strings.ToLower(redString))
 
 	var mapper mathexp.ReduceMapper = nil
+	var opts mathexp.ReducerOptions
 	settings, ok := rn.Query["settings"]
 	if ok {
 		switch s := settings.(type) {
 		case map[string]any:
+			if rawThreshold, ok := s["threshold"]; ok && rawThreshold != nil {
+				threshold, ok := rawThreshold.(float64)
+				if !ok {
+					return nil, fmt.Errorf("setting threshold must be a number, got %T", rawThreshold)
+				}
+				opts.Threshold = &threshold
+			}
 			mode, ok := s["mode"]
 			if ok && mode != "" {
 				switch mode {
@@ -154,7 +164,7 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	return NewReduceCommand(rn.RefID, redFunc, opts, varToReduce, mapper)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -175,7 +185,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.ReduceWithOptions(gr.refID, gr.Reducer, gr.ReducerOptions, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -212,28 +222,30 @@ func (gr *ReduceCommand) Type() string {
 
 // ResampleCommand is an expression command for resampling of a timeseries.
 type ResampleCommand struct {
-	Window        time.Duration
-	VarToResample string
-	Downsampler   mathexp.ReducerID
-	Upsampler     mathexp.Upsampler
-	TimeRange     TimeRange
-	refID         string
+	Window             time.Duration
+	VarToResample      string
+	Downsampler        mathexp.ReducerID
+	DownsamplerOptions mathexp.ReducerOptions
+	Upsampler          mathexp.Upsampler
+	TimeRange          TimeRange
+	refID              string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, downsamplerOpts mathexp.ReducerOptions, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
 	return &ResampleCommand{
-		Window:        window,
-		VarToResample: varToResample,
-		Downsampler:   downsampler,
-		Upsampler:     upsampler,
-		TimeRange:     tr,
-		refID:         refID,
+		Window:             window,
+		VarToResample:      varToResample,
+		Downsampler:        downsampler,
+		DownsamplerOptions: downsamplerOpts,
+		Upsampler:          upsampler,
+		TimeRange:          tr,
+		refID:              refID,
 	}, nil
 }
 
@@ -280,9 +292,19 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
+	var downsamplerOpts mathexp.ReducerOptions
+	if rawThreshold, ok := rn.Query["threshold"]; ok && rawThreshold != nil {
+		threshold, ok := rawThreshold.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected resample threshold to be a number, got type %T", rawThreshold)
+		}
+		downsamplerOpts.Threshold = &threshold
+	}
+
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
+		downsamplerOpts,
 		mathexp.Upsampler(upsampler),
 		rn.TimeRange)
 }
@@ -306,7 +328,7 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.ResampleWithOptions(gr.refID, gr.Window, gr.Downsampler, gr.DownsamplerOptions, gr.Upsampler, timeRange.From, timeRange.To)
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +365,14 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeOutliers is the CMDType for detecting outliers with z-score or MAD
+	TypeOutliers
+	// TypeSeasonal is the CMDType for a seasonal baseline and bands
+	TypeSeasonal
+	// TypeForecast is the CMDType for a linear forecast
+	TypeForecast
+	// TypeAggregate is the CMDType for aggregating by labels
+	TypeAggregate
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +389,14 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeOutliers:
+		return "outliers"
+	case TypeSeasonal:
+		return "seasonal"
+	case TypeForecast:
+		return "forecast"
+	case TypeAggregate:
+		return "aggregate"
 	default:
 		return "unknown"
 	}
@@ -379,6 +417,14 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "outliers":
+		return TypeOutliers, nil
+	case "seasonal":
+		return TypeSeasonal, nil
+	case "forecast":
+		return TypeForecast, nil
+	case "aggregate":
+		return TypeAggregate, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
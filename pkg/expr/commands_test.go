@@ -99,11 +99,28 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_Threshold(t *testing.T) {
+	unmarshal := func(q string) (*ReduceCommand, error) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+		return UnmarshalReduceCommand(&rawNode{RefID: "B", Query: qmap})
+	}
+
+	cmd, err := unmarshal(`{ "expression" : "$A", "reducer": "percent_above", "settings": { "mode": "dropNN", "threshold": 90 } }`)
+	require.NoError(t, err)
+	require.Equal(t, mathexp.ReducerPercentAbove, cmd.Reducer)
+	require.Equal(t, util.Pointer(90.0), cmd.ReducerOptions.Threshold)
+	require.Equal(t, mathexp.DropNonNumber{}, cmd.seriesMapper)
+
+	_, err = unmarshal(`{ "expression" : "$A", "reducer": "percent_above", "settings": { "threshold": "90" } }`)
+	require.Error(t, err)
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
 	t.Run("when mapper is nil", func(t *testing.T) {
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerOptions{}, varToReduce, nil)
 		require.NoError(t, err)
 
 		t.Run("should noop if Number", func(t *testing.T) {
@@ -161,7 +178,7 @@ func TestReduceExecute(t *testing.T) {
 		}
 
 		t.Run("drop all non numbers if mapper is DropNonNumber", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.DropNonNumber{})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerOptions{}, varToReduce, &mathexp.DropNonNumber{})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -169,7 +186,7 @@ func TestReduceExecute(t *testing.T) {
 		})
 
 		t.Run("replace all non numbers if mapper is ReplaceNonNumberWithValue", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerOptions{}, varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -192,7 +209,7 @@ func TestReduceExecute(t *testing.T) {
 				Values: noData,
 			},
 		}
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerOptions{}, varToReduce, nil)
 		require.NoError(t, err)
 		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 		require.NoError(t, err)
@@ -221,7 +238,7 @@ func TestResampleCommand_Execute(t *testing.T) {
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", mathexp.ReducerOptions{}, "pad", tr)
 	require.NoError(t, err)
 
 	var tests = []struct {
@@ -0,0 +1,137 @@
+package mathexp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// Grouping selects the labels that values are grouped by when they are aggregated.
+type Grouping struct {
+	// Labels are the labels that identify a group, or the labels that are removed
+	// to identify a group when Without is true.
+	Labels  []string
+	Without bool
+}
+
+// GroupLabels returns the labels of the group that a value with the given labels belongs to.
+func (g Grouping) GroupLabels(labels data.Labels) data.Labels {
+	group := data.Labels{}
+	if !g.Without {
+		for _, name := range g.Labels {
+			if v, ok := labels[name]; ok {
+				group[name] = v
+			}
+		}
+		return group
+	}
+	for name, v := range labels {
+		group[name] = v
+	}
+	for _, name := range g.Labels {
+		delete(group, name)
+	}
+	return group
+}
+
+// Aggregate groups the values by their labels and reduces the values of each group with the reducer.
+// Numbers of a group are reduced to a single Number. Series of a group are reduced to a single Series
+// point by point: the values of the series at the same time are reduced to the value of the point
+// at that time. Series and numbers cannot be aggregated together.
+//
+// The groups are returned in the order in which they first appear. NoData values are ignored,
+// and NoData is returned when there is nothing else to aggregate.
+func Aggregate(refID string, values Values, grouping Grouping, rFunc ReducerID, opts ReducerOptions, mapper ReduceMapper) (Values, error) {
+	reduceFunc, err := GetReduceFuncWithOptions(rFunc, opts)
+	if err != nil {
+		return nil, err
+	}
+	reduce := func(group []*float64) *float64 {
+		mapped := make([]*float64, 0, len(group))
+		for _, v := range group {
+			if mapper != nil {
+				v = mapper.MapInput(v)
+				if v == nil {
+					continue
+				}
+			}
+			mapped = append(mapped, v)
+		}
+		field := Float64Field(*data.NewField("", nil, mapped))
+		f := reduceFunc(&field)
+		if f != nil && mapper != nil {
+			f = mapper.MapOutput(f)
+		}
+		return f
+	}
+
+	var groups []data.Labels
+	members := map[string][]Value{}
+	valueType := parse.TypeNoData
+	for _, v := range values {
+		switch v.(type) {
+		case Series, Number:
+		case NoData:
+			continue
+		default:
+			return nil, fmt.Errorf("can only aggregate series or numbers, got type %v", v.Type())
+		}
+		if valueType == parse.TypeNoData {
+			valueType = v.Type()
+		} else if valueType != v.Type() {
+			return nil, fmt.Errorf("can not aggregate series and numbers together")
+		}
+		labels := grouping.GroupLabels(v.GetLabels())
+		key := labels.String()
+		if _, ok := members[key]; !ok {
+			groups = append(groups, labels)
+		}
+		members[key] = append(members[key], v)
+	}
+
+	if len(groups) == 0 {
+		return Values{NewNoData()}, nil
+	}
+
+	newValues := make(Values, 0, len(groups))
+	for _, labels := range groups {
+		group := members[labels.String()]
+		if _, ok := group[0].(Number); ok {
+			numbers := make([]*float64, 0, len(group))
+			for _, v := range group {
+				numbers = append(numbers, v.(Number).GetFloat64Value())
+			}
+			n := NewNumber(refID, labels)
+			n.SetValue(reduce(numbers))
+			newValues = append(newValues, n)
+			continue
+		}
+
+		// Points are grouped by their Unix time, so that equal times in different locations match.
+		byTime := map[int64][]*float64{}
+		var times []time.Time
+		for _, v := range group {
+			s := v.(Series)
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				if _, ok := byTime[t.UnixNano()]; !ok {
+					times = append(times, t)
+				}
+				byTime[t.UnixNano()] = append(byTime[t.UnixNano()], f)
+			}
+		}
+		sort.Slice(times, func(i, j int) bool {
+			return times[i].Before(times[j])
+		})
+		s := NewSeries(refID, labels, len(times))
+		for i, t := range times {
+			s.SetPoint(i, t, reduce(byTime[t.UnixNano()]))
+		}
+		newValues = append(newValues, s)
+	}
+	return newValues, nil
+}
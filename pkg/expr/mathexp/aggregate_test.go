@@ -0,0 +1,101 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+func TestGroupingGroupLabels(t *testing.T) {
+	labels := data.Labels{"cluster": "eu", "pod": "a", "instance": "1"}
+	assert.Equal(t, data.Labels{"cluster": "eu"}, Grouping{Labels: []string{"cluster", "missing"}}.GroupLabels(labels))
+	assert.Equal(t, data.Labels{"cluster": "eu", "pod": "a"}, Grouping{Labels: []string{"instance"}, Without: true}.GroupLabels(labels))
+	assert.Equal(t, data.Labels{}, Grouping{}.GroupLabels(labels))
+}
+
+func TestAggregateNumbers(t *testing.T) {
+	values := Values{
+		makeNumber("", data.Labels{"cluster": "eu", "pod": "a"}, float64Pointer(1)),
+		makeNumber("", data.Labels{"cluster": "us", "pod": "b"}, float64Pointer(10)),
+		makeNumber("", data.Labels{"cluster": "eu", "pod": "c"}, float64Pointer(3)),
+		makeNumber("", data.Labels{"cluster": "us", "pod": "d"}, nil),
+		NewNoData(),
+	}
+
+	t.Run("by", func(t *testing.T) {
+		res, err := Aggregate("B", values, Grouping{Labels: []string{"cluster"}}, ReducerSum, ReducerOptions{}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		assert.Equal(t, data.Labels{"cluster": "eu"}, res[0].GetLabels())
+		assert.Equal(t, 4.0, *res[0].(Number).GetFloat64Value())
+		assert.Equal(t, data.Labels{"cluster": "us"}, res[1].GetLabels())
+		assert.True(t, math.IsNaN(*res[1].(Number).GetFloat64Value()), "null values are not numbers in strict mode")
+	})
+
+	t.Run("without with a mapper", func(t *testing.T) {
+		res, err := Aggregate("B", values, Grouping{Labels: []string{"pod"}, Without: true}, ReducerMax, ReducerOptions{}, DropNonNumber{})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		assert.Equal(t, 3.0, *res[0].(Number).GetFloat64Value())
+		assert.Equal(t, 10.0, *res[1].(Number).GetFloat64Value())
+	})
+
+	t.Run("all values in one group", func(t *testing.T) {
+		res, err := Aggregate("B", values[:3], Grouping{}, ReducerCount, ReducerOptions{}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, data.Labels{}, res[0].GetLabels())
+		assert.Equal(t, 3.0, *res[0].(Number).GetFloat64Value())
+	})
+}
+
+func TestAggregateSeries(t *testing.T) {
+	values := Values{
+		makeSeries("", data.Labels{"cluster": "eu", "pod": "a"},
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(60, 0), float64Pointer(2)},
+		),
+		makeSeries("", data.Labels{"cluster": "eu", "pod": "b"},
+			tp{time.Unix(60, 0), float64Pointer(20)},
+			tp{time.Unix(120, 0), float64Pointer(30)},
+		),
+	}
+	res, err := Aggregate("B", values, Grouping{Labels: []string{"cluster"}}, ReducerMean, ReducerOptions{}, nil)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	s := res[0].(Series)
+	assert.Equal(t, data.Labels{"cluster": "eu"}, s.GetLabels())
+	require.Equal(t, 3, s.Len())
+	assert.Equal(t, time.Unix(0, 0), s.GetTime(0))
+	assert.Equal(t, 1.0, *s.GetValue(0))
+	assert.Equal(t, 11.0, *s.GetValue(1))
+	assert.Equal(t, 30.0, *s.GetValue(2))
+}
+
+func TestAggregateErrors(t *testing.T) {
+	t.Run("mixed types", func(t *testing.T) {
+		_, err := Aggregate("B", Values{
+			makeNumber("", nil, float64Pointer(1)),
+			makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}),
+		}, Grouping{}, ReducerSum, ReducerOptions{}, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown reducer", func(t *testing.T) {
+		_, err := Aggregate("B", Values{makeNumber("", nil, float64Pointer(1))}, Grouping{}, "foo", ReducerOptions{}, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("only no data", func(t *testing.T) {
+		res, err := Aggregate("B", Values{NewNoData()}, Grouping{}, ReducerSum, ReducerOptions{}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, parse.TypeNoData, res[0].Type())
+	})
+}
@@ -0,0 +1,360 @@
+package mathexp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ErrNotEnoughData is returned when a series has too few points for the requested computation.
+var ErrNotEnoughData = errors.New("not enough data")
+
+// The outlier detection algorithm
+// +enum
+type OutlierAlgorithm string
+
+const (
+	// Distance from the mean in standard deviations
+	OutlierZScore OutlierAlgorithm = "zscore"
+
+	// Modified z-score based on the median absolute deviation, which is robust to the outliers themselves
+	OutlierMAD OutlierAlgorithm = "mad"
+)
+
+// madScale makes the median absolute deviation consistent with the standard deviation of normally distributed data.
+const madScale = 0.6745
+
+// OutlierScores returns the score of every value with the given algorithm. Null and NaN values
+// get a nil score and are ignored when computing the center and the spread of the values.
+func OutlierScores(values []*float64, algorithm OutlierAlgorithm) ([]*float64, error) {
+	valid := make([]float64, 0, len(values))
+	for _, v := range values {
+		if v != nil && !math.IsNaN(*v) {
+			valid = append(valid, *v)
+		}
+	}
+
+	var center, spread float64
+	switch algorithm {
+	case OutlierZScore:
+		center, spread = meanStdDev(valid)
+	case OutlierMAD:
+		center = median(valid)
+		deviations := make([]float64, 0, len(valid))
+		for _, v := range valid {
+			deviations = append(deviations, math.Abs(v-center))
+		}
+		spread = median(deviations) / madScale
+	default:
+		return nil, fmt.Errorf("outlier algorithm %q not implemented, expected one of [%s, %s]", algorithm, OutlierZScore, OutlierMAD)
+	}
+
+	scores := make([]*float64, len(values))
+	if len(valid) == 0 {
+		return scores, nil
+	}
+	for i, v := range values {
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		score := 0.0
+		switch {
+		case spread > 0:
+			score = (*v - center) / spread
+		case *v > center:
+			score = math.Inf(1)
+		case *v < center:
+			score = math.Inf(-1)
+		}
+		scores[i] = &score
+	}
+	return scores, nil
+}
+
+// Outliers returns a series with the outlier score of every point of the series, or, if threshold is set,
+// 1 for points whose absolute score is above the threshold and 0 otherwise.
+func (s Series) Outliers(refID string, algorithm OutlierAlgorithm, threshold *float64) (Series, error) {
+	values := make([]*float64, s.Len())
+	for i := range values {
+		values[i] = s.GetValue(i)
+	}
+	scores, err := OutlierScores(values, algorithm)
+	if err != nil {
+		return s, err
+	}
+
+	out := NewSeries(refID, copyLabels(s.GetLabels()), s.Len())
+	for i, score := range scores {
+		out.SetPoint(i, s.GetTime(i), flagOrScore(score, threshold))
+	}
+	return out, nil
+}
+
+func flagOrScore(score *float64, threshold *float64) *float64 {
+	if score == nil || threshold == nil {
+		return score
+	}
+	flag := 0.0
+	if math.Abs(*score) > *threshold {
+		flag = 1
+	}
+	return &flag
+}
+
+// The output of the seasonal model
+// +enum
+type SeasonalOutput string
+
+const (
+	// The value predicted by the model
+	SeasonalBaseline SeasonalOutput = "baseline"
+
+	// The upper bound of the expected values
+	SeasonalUpper SeasonalOutput = "upper"
+
+	// The lower bound of the expected values
+	SeasonalLower SeasonalOutput = "lower"
+
+	// 1 when the value is outside of the bounds, 0 otherwise
+	SeasonalAnomaly SeasonalOutput = "anomaly"
+)
+
+// HoltWintersParams configures the additive Holt-Winters model.
+type HoltWintersParams struct {
+	// Season is the length of the seasonal cycle, e.g. one day.
+	Season time.Duration
+	// Alpha, Beta and Gamma are the smoothing factors of the level, trend and seasonal components, between 0 and 1.
+	Alpha, Beta, Gamma float64
+	// Deviations is the width of the band around the baseline, in standard deviations of the prediction error.
+	Deviations float64
+}
+
+// Validate returns an error if the parameters are out of range.
+func (p HoltWintersParams) Validate() error {
+	if p.Season <= 0 {
+		return fmt.Errorf("season must be positive")
+	}
+	for name, v := range map[string]float64{"alpha": p.Alpha, "beta": p.Beta, "gamma": p.Gamma} {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("%s must be between 0 and 1, got %v", name, v)
+		}
+	}
+	if p.Deviations < 0 {
+		return fmt.Errorf("deviations must not be negative, got %v", p.Deviations)
+	}
+	return nil
+}
+
+// HoltWinters fits an additive Holt-Winters model to the series and returns the requested output for every point.
+// The series is expected to have regularly spaced points; the step is taken from the median distance between points.
+// The first season is used to initialize the model, so its points have no output. Null and NaN values are replaced
+// with the prediction of the model. ErrNotEnoughData is returned if the series does not cover at least two seasons.
+func (s Series) HoltWinters(refID string, p HoltWintersParams, output SeasonalOutput) (Series, error) {
+	if err := p.Validate(); err != nil {
+		return s, err
+	}
+	switch output {
+	case SeasonalBaseline, SeasonalUpper, SeasonalLower, SeasonalAnomaly:
+	default:
+		return s, fmt.Errorf("seasonal output %q not implemented, expected one of [%s, %s, %s, %s]", output, SeasonalBaseline, SeasonalUpper, SeasonalLower, SeasonalAnomaly)
+	}
+
+	out := NewSeries(refID, copyLabels(s.GetLabels()), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		out.SetPoint(i, s.GetTime(i), nil)
+	}
+
+	step := s.medianStep()
+	if step <= 0 {
+		return out, ErrNotEnoughData
+	}
+	m := int(math.Round(float64(p.Season) / float64(step)))
+	if m < 2 {
+		return s, fmt.Errorf("season %v must be at least two times the step of the series %v", p.Season, step)
+	}
+	n := s.Len()
+	if n < 2*m {
+		return out, ErrNotEnoughData
+	}
+
+	x := make([]*float64, n)
+	for i := range x {
+		if v := s.GetValue(i); v != nil && !math.IsNaN(*v) {
+			x[i] = v
+		}
+	}
+
+	first, second := meanOf(x[:m]), meanOf(x[m:2*m])
+	if first == nil || second == nil {
+		return out, ErrNotEnoughData
+	}
+	level := *first
+	trend := (*second - *first) / float64(m)
+	seasonal := make([]float64, n)
+	for i := 0; i < m; i++ {
+		if x[i] != nil {
+			seasonal[i] = *x[i] - level
+		}
+	}
+
+	predicted := make([]float64, n)
+	var residuals []float64
+	for t := m; t < n; t++ {
+		predicted[t] = level + trend + seasonal[t-m]
+		obs := predicted[t]
+		if x[t] != nil {
+			obs = *x[t]
+			residuals = append(residuals, obs-predicted[t])
+		}
+		prevLevel := level
+		level = p.Alpha*(obs-seasonal[t-m]) + (1-p.Alpha)*(level+trend)
+		trend = p.Beta*(level-prevLevel) + (1-p.Beta)*trend
+		seasonal[t] = p.Gamma*(obs-level) + (1-p.Gamma)*seasonal[t-m]
+	}
+
+	_, sigma := meanStdDev(residuals)
+	band := p.Deviations * sigma
+	for t := m; t < n; t++ {
+		var v float64
+		switch output {
+		case SeasonalBaseline:
+			v = predicted[t]
+		case SeasonalUpper:
+			v = predicted[t] + band
+		case SeasonalLower:
+			v = predicted[t] - band
+		case SeasonalAnomaly:
+			if x[t] == nil {
+				continue
+			}
+			if math.Abs(*x[t]-predicted[t]) > band {
+				v = 1
+			}
+		}
+		out.SetPoint(t, s.GetTime(t), &v)
+	}
+	return out, nil
+}
+
+// PredictLinear returns the value the series is predicted to have at the given time, using a simple linear
+// regression over its points. Null and NaN values are ignored. The value is nil if there are less than two points.
+func (s Series) PredictLinear(refID string, at time.Time) Number {
+	n := NewNumber(refID, copyLabels(s.GetLabels()))
+	_, intercept, ok := s.linearRegression(at)
+	if !ok {
+		n.SetValue(nil)
+		return n
+	}
+	n.SetValue(&intercept)
+	return n
+}
+
+// linearRegression returns the slope per second and the intercept at the given time of the least squares fit of the series.
+func (s Series) linearRegression(at time.Time) (slope, intercept float64, ok bool) {
+	xs := make([]float64, 0, s.Len())
+	ys := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		xs = append(xs, t.Sub(at).Seconds())
+		ys = append(ys, *v)
+	}
+	return leastSquares(xs, ys)
+}
+
+// leastSquares returns the slope and the intercept of the least squares fit of the points.
+// It is not ok if there are less than two points or all points have the same x.
+func leastSquares(xs, ys []float64) (slope, intercept float64, ok bool) {
+	if len(xs) < 2 {
+		return 0, 0, false
+	}
+	var sumX, sumY, sumXY, sumX2 float64
+	for i, x := range xs {
+		sumX += x
+		sumY += ys[i]
+		sumXY += x * ys[i]
+		sumX2 += x * x
+	}
+	count := float64(len(xs))
+	covXY := sumXY - sumX*sumY/count
+	varX := sumX2 - sumX*sumX/count
+	if varX == 0 {
+		return 0, 0, false
+	}
+	slope = covXY / varX
+	intercept = sumY/count - slope*sumX/count
+	return slope, intercept, true
+}
+
+// medianStep returns the median distance between consecutive points of the series.
+func (s Series) medianStep() time.Duration {
+	if s.Len() < 2 {
+		return 0
+	}
+	steps := make([]float64, 0, s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		steps = append(steps, float64(s.GetTime(i).Sub(s.GetTime(i-1))))
+	}
+	return time.Duration(median(steps))
+}
+
+func meanOf(values []*float64) *float64 {
+	var sum float64
+	count := 0
+	for _, v := range values {
+		if v != nil {
+			sum += *v
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	mean := sum / float64(count)
+	return &mean
+}
+
+// meanStdDev returns the mean and the population standard deviation of the values.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+// median returns the median of the values without modifying them.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func copyLabels(l data.Labels) data.Labels {
+	if l == nil {
+		return nil
+	}
+	return l.Copy()
+}
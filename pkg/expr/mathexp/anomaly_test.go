@@ -0,0 +1,154 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seriesOf(start time.Time, step time.Duration, values ...*float64) Series {
+	points := make([]tp, 0, len(values))
+	for i, v := range values {
+		points = append(points, tp{start.Add(time.Duration(i) * step), v})
+	}
+	return makeSeries("", data.Labels{"host": "a"}, points...)
+}
+
+func seriesValues(s Series) []*float64 {
+	values := make([]*float64, s.Len())
+	for i := range values {
+		values[i] = s.GetValue(i)
+	}
+	return values
+}
+
+func TestOutlierScores(t *testing.T) {
+	values := []*float64{float64Pointer(1), float64Pointer(2), float64Pointer(3), nil, float64Pointer(math.NaN()), float64Pointer(100)}
+
+	t.Run("zscore", func(t *testing.T) {
+		scores, err := OutlierScores(values, OutlierZScore)
+		require.NoError(t, err)
+		require.Len(t, scores, len(values))
+		assert.Nil(t, scores[3])
+		assert.Nil(t, scores[4])
+		assert.InDelta(t, 1.73, *scores[5], 0.01)
+	})
+
+	t.Run("mad is not skewed by the outlier", func(t *testing.T) {
+		scores, err := OutlierScores(values, OutlierMAD)
+		require.NoError(t, err)
+		assert.InDelta(t, -1.01, *scores[0], 0.01)
+		assert.InDelta(t, 65.76, *scores[5], 0.01)
+	})
+
+	t.Run("values different from a constant have an infinite score", func(t *testing.T) {
+		scores, err := OutlierScores([]*float64{float64Pointer(1), float64Pointer(1), float64Pointer(1), float64Pointer(2)}, OutlierMAD)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, *scores[0])
+		assert.True(t, math.IsInf(*scores[3], 1))
+	})
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, err := OutlierScores(values, "foo")
+		require.Error(t, err)
+	})
+}
+
+func TestSeriesOutliers(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := seriesOf(start, time.Minute, float64Pointer(10), float64Pointer(11), float64Pointer(9), float64Pointer(10), float64Pointer(50), nil)
+
+	flags, err := s.Outliers("B", OutlierMAD, float64Pointer(3.5))
+	require.NoError(t, err)
+	assert.Equal(t, []*float64{float64Pointer(0), float64Pointer(0), float64Pointer(0), float64Pointer(0), float64Pointer(1), nil}, seriesValues(flags))
+	assert.Equal(t, data.Labels{"host": "a"}, flags.GetLabels())
+	assert.Equal(t, start.Add(4*time.Minute), flags.GetTime(4))
+
+	scores, err := s.Outliers("B", OutlierMAD, nil)
+	require.NoError(t, err)
+	assert.Greater(t, *scores.GetValue(4), 3.5)
+}
+
+func TestSeriesHoltWinters(t *testing.T) {
+	start := time.Unix(0, 0)
+	params := HoltWintersParams{Season: 4 * time.Minute, Alpha: 0.5, Beta: 0.1, Gamma: 0.3, Deviations: 3}
+
+	// A repeating pattern with a spike in the last season.
+	pattern := []float64{10, 20, 30, 20}
+	var values []*float64
+	for i := 0; i < 16; i++ {
+		values = append(values, float64Pointer(pattern[i%4]))
+	}
+	values[14] = float64Pointer(90)
+	s := seriesOf(start, time.Minute, values...)
+
+	t.Run("baseline follows the seasonal pattern", func(t *testing.T) {
+		baseline, err := s.HoltWinters("B", params, SeasonalBaseline)
+		require.NoError(t, err)
+		require.Equal(t, s.Len(), baseline.Len())
+		for i := 0; i < 4; i++ {
+			assert.Nil(t, baseline.GetValue(i), "the first season has no prediction")
+		}
+		for i := 4; i < 14; i++ {
+			assert.InDelta(t, pattern[i%4], *baseline.GetValue(i), 0.001)
+		}
+	})
+
+	t.Run("anomaly flags the spike", func(t *testing.T) {
+		anomaly, err := s.HoltWinters("B", params, SeasonalAnomaly)
+		require.NoError(t, err)
+		for i := 4; i < s.Len(); i++ {
+			expected := 0.0
+			if i == 14 {
+				expected = 1
+			}
+			assert.Equal(t, expected, *anomaly.GetValue(i), "point %d", i)
+		}
+	})
+
+	t.Run("bands surround the baseline", func(t *testing.T) {
+		upper, err := s.HoltWinters("B", params, SeasonalUpper)
+		require.NoError(t, err)
+		lower, err := s.HoltWinters("B", params, SeasonalLower)
+		require.NoError(t, err)
+		assert.Greater(t, *upper.GetValue(8), *lower.GetValue(8))
+	})
+
+	t.Run("less than two seasons", func(t *testing.T) {
+		out, err := seriesOf(start, time.Minute, values[:7]...).HoltWinters("B", params, SeasonalBaseline)
+		require.ErrorIs(t, err, ErrNotEnoughData)
+		require.Equal(t, 7, out.Len())
+		assert.Nil(t, out.GetValue(6))
+	})
+
+	t.Run("season shorter than two steps", func(t *testing.T) {
+		p := params
+		p.Season = time.Minute
+		_, err := s.HoltWinters("B", p, SeasonalBaseline)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid smoothing factor", func(t *testing.T) {
+		p := params
+		p.Alpha = 2
+		_, err := s.HoltWinters("B", p, SeasonalBaseline)
+		require.Error(t, err)
+	})
+}
+
+func TestSeriesPredictLinear(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := seriesOf(start, time.Minute, float64Pointer(0), nil, float64Pointer(2), float64Pointer(3))
+
+	n := s.PredictLinear("B", start.Add(10*time.Minute))
+	require.NotNil(t, n.GetFloat64Value())
+	assert.InDelta(t, 10, *n.GetFloat64Value(), 0.0001)
+	assert.Equal(t, data.Labels{"host": "a"}, n.GetLabels())
+
+	n = seriesOf(start, time.Minute, float64Pointer(1)).PredictLinear("B", start)
+	assert.Nil(t, n.GetFloat64Value())
+}
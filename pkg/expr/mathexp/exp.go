@@ -214,28 +214,8 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
-		check := func(v string, matchArray []bool, r *Results) {
-			for i, b := range matchArray {
-				if b {
-					continue
-				}
-				if e.Drops == nil {
-					e.Drops = make(map[string]map[string][]data.Labels)
-				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
-				}
-
-				if r.Values[i].Type() == parse.TypeNoData {
-					continue
-				}
-
-				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
-			}
-		}
-		check(aVar, aMatched, &aResults)
-		check(bVar, bMatched, &bResults)
+		e.collectDrops(biNode, aVar, aMatched, &aResults)
+		e.collectDrops(biNode, bVar, bMatched, &bResults)
 	}
 
 	aValueLen := len(aResults.Values)
@@ -306,6 +286,151 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	return unions
 }
 
+// collectDrops records the values of one side of a binary operation that were not matched
+// with any value of the other side.
+func (e *State) collectDrops(biNode *parse.BinaryNode, v string, matchArray []bool, r *Results) {
+	for i, b := range matchArray {
+		if b {
+			continue
+		}
+		if e.Drops == nil {
+			e.Drops = make(map[string]map[string][]data.Labels)
+		}
+		if e.Drops[biNode.String()] == nil {
+			e.Drops[biNode.String()] = make(map[string][]data.Labels)
+		}
+
+		if r.Values[i].Type() == parse.TypeNoData {
+			continue
+		}
+
+		e.DropCount++
+		e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
+	}
+}
+
+// matchingSignature returns the labels that values are matched on according to the
+// on or ignoring modifier.
+func matchingSignature(m *parse.VectorMatching, labels data.Labels) data.Labels {
+	sig := data.Labels{}
+	if m.On {
+		for _, name := range m.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				sig[name] = v
+			}
+		}
+		return sig
+	}
+	for name, v := range labels {
+		sig[name] = v
+	}
+	for _, name := range m.MatchingLabels {
+		delete(sig, name)
+	}
+	return sig
+}
+
+// matchUnions creates Union objects for a binary operation with vector matching modifiers.
+// Values are matched when the labels selected by on or ignoring are equal, like in PromQL.
+// Unlike union, matches that are ambiguous are reported as errors rather than dropped.
+//
+// The labels of a one-to-one Union are the matching labels. The labels of a many-to-one
+// or one-to-many Union are the labels of the value on the "many" side, plus the included
+// labels of the value on the "one" side.
+func (e *State) matchUnions(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	unions := []*Union{}
+	m := biNode.Matching
+
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+
+	isNoData := func(r Results) bool {
+		return len(r.Values) == 1 && r.Values[0].Type() == parse.TypeNoData
+	}
+	if isNoData(aResults) || isNoData(bResults) {
+		return append(unions, &Union{A: aResults.Values[0], B: bResults.Values[0]}), nil
+	}
+
+	// The "one" side must have a single value for each signature, which is the right
+	// side unless the operation is one-to-many.
+	one, many := bResults, aResults
+	oneSide, manySide := "right", "left"
+	if m.Card == parse.CardOneToMany {
+		one, many = aResults, bResults
+		oneSide, manySide = "left", "right"
+	}
+	oneMatched := make([]bool, len(one.Values))
+	manyMatched := make([]bool, len(many.Values))
+
+	oneBySignature := make(map[string][]int, len(one.Values))
+	for i, v := range one.Values {
+		if v.Type() == parse.TypeNoData {
+			continue
+		}
+		key := matchingSignature(m, v.GetLabels()).String()
+		oneBySignature[key] = append(oneBySignature[key], i)
+	}
+
+	resultLabels := make(map[string]struct{}, len(many.Values))
+	for iMany, manyValue := range many.Values {
+		if manyValue.Type() == parse.TypeNoData {
+			continue
+		}
+		sig := matchingSignature(m, manyValue.GetLabels())
+		matches := oneBySignature[sig.String()]
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+		default:
+			return nil, fmt.Errorf("found duplicate values for the match group %s on the %s hand side of %s: many-to-many matching is not allowed, matching labels must be unique on one side",
+				sig, oneSide, biNode)
+		}
+		iOne := matches[0]
+		oneValue := one.Values[iOne]
+
+		labels := sig
+		if m.Card != parse.CardOneToOne {
+			labels = manyValue.GetLabels().Copy()
+			for _, name := range m.Include {
+				if v, ok := oneValue.GetLabels()[name]; ok {
+					labels[name] = v
+				} else {
+					delete(labels, name)
+				}
+			}
+		}
+		key := labels.String()
+		if _, ok := resultLabels[key]; ok {
+			if m.Card == parse.CardOneToOne {
+				return nil, fmt.Errorf("found multiple matches for the match group %s on the %s hand side of %s: many-to-one matching must be explicit (group_left/group_right)",
+					sig, manySide, biNode)
+			}
+			return nil, fmt.Errorf("found multiple matches for the labels %s in %s: grouping labels must ensure unique matches",
+				labels, biNode)
+		}
+		resultLabels[key] = struct{}{}
+
+		u := &Union{Labels: labels, A: manyValue, B: oneValue}
+		if m.Card == parse.CardOneToMany {
+			u.A, u.B = oneValue, manyValue
+		}
+		unions = append(unions, u)
+		oneMatched[iOne] = true
+		manyMatched[iMany] = true
+	}
+
+	if m.Card == parse.CardOneToMany {
+		e.collectDrops(biNode, biNode.Args[0].String(), oneMatched, &one)
+		e.collectDrops(biNode, biNode.Args[1].String(), manyMatched, &many)
+	} else {
+		e.collectDrops(biNode, biNode.Args[0].String(), manyMatched, &many)
+		e.collectDrops(biNode, biNode.Args[1].String(), oneMatched, &one)
+	}
+	return unions, nil
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values: Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -316,7 +441,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.matchUnions(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = e.union(ar, br, node)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -68,6 +68,56 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      windowFunction("rate", rateWindow),
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      windowFunction("delta", deltaWindow),
+	},
+	"deriv": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      windowFunction("deriv", derivWindow),
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      windowFunction("moving_avg", avgWindow),
+	},
+	"moving_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      windowFunction("moving_sum", sumWindow),
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"offset": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"timestamp": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      timestamp,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
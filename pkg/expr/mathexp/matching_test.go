@@ -0,0 +1,160 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorMatching(t *testing.T) {
+	point := func(v float64) tp {
+		return tp{time.Unix(5, 0), float64Pointer(v)}
+	}
+	requests := Results{Values: Values{
+		makeSeries("a", data.Labels{"service": "api", "instance": "1"}, point(10)),
+		makeSeries("a", data.Labels{"service": "api", "instance": "2"}, point(20)),
+		makeSeries("a", data.Labels{"service": "web", "instance": "1"}, point(30)),
+	}}
+	limits := Results{Values: Values{
+		makeSeries("b", data.Labels{"service": "api", "team": "core"}, point(100)),
+		makeSeries("b", data.Labels{"service": "web", "team": "ui"}, point(300)),
+	}}
+
+	type result struct {
+		labels data.Labels
+		value  float64
+	}
+	tests := []struct {
+		name     string
+		expr     string
+		vars     Vars
+		expected []result
+		err      string
+	}{
+		{
+			name: "on matches the listed labels",
+			expr: `$A + on(service) $B`,
+			vars: Vars{"A": Results{Values: requests.Values[1:]}, "B": limits},
+			expected: []result{
+				{labels: data.Labels{"service": "api"}, value: 120},
+				{labels: data.Labels{"service": "web"}, value: 330},
+			},
+		},
+		{
+			name: "ignoring matches all other labels",
+			expr: `$A + ignoring(team) $B`,
+			vars: Vars{"A": Results{Values: Values{
+				makeSeries("a", data.Labels{"service": "api"}, point(1)),
+			}}, "B": limits},
+			expected: []result{
+				{labels: data.Labels{"service": "api"}, value: 101},
+			},
+		},
+		{
+			name: "group_left keeps the labels of the many side",
+			expr: `$A / on(service) group_left(team) $B`,
+			vars: Vars{"A": requests, "B": limits},
+			expected: []result{
+				{labels: data.Labels{"service": "api", "instance": "1", "team": "core"}, value: 0.1},
+				{labels: data.Labels{"service": "api", "instance": "2", "team": "core"}, value: 0.2},
+				{labels: data.Labels{"service": "web", "instance": "1", "team": "ui"}, value: 0.1},
+			},
+		},
+		{
+			name: "group_right keeps the labels of the many side",
+			expr: `$B - on(service) group_right $A`,
+			vars: Vars{"A": requests, "B": limits},
+			expected: []result{
+				{labels: data.Labels{"service": "api", "instance": "1"}, value: 90},
+				{labels: data.Labels{"service": "api", "instance": "2"}, value: 80},
+				{labels: data.Labels{"service": "web", "instance": "1"}, value: 270},
+			},
+		},
+		{
+			name: "numbers are matched too",
+			expr: `$A > on(service) $B`,
+			vars: Vars{
+				"A": Results{Values: Values{makeNumber("a", data.Labels{"service": "api", "instance": "1"}, float64Pointer(200))}},
+				"B": Results{Values: Values{makeNumber("b", data.Labels{"service": "api"}, float64Pointer(100))}},
+			},
+			expected: []result{
+				{labels: data.Labels{"service": "api"}, value: 1},
+			},
+		},
+		{
+			name: "one-to-one with many values on one side",
+			expr: `$A + on(service) $B`,
+			vars: Vars{"A": requests, "B": limits},
+			err:  "many-to-one matching must be explicit",
+		},
+		{
+			name: "many-to-many",
+			expr: `$B + on(service) group_left $A`,
+			vars: Vars{"A": requests, "B": limits},
+			err:  `found duplicate values for the match group service=api on the right hand side of $B + on(service) group_left() $A: many-to-many matching is not allowed`,
+		},
+		{
+			name: "grouping labels that are not unique",
+			expr: `$A + ignoring(instance) group_left(instance) $B`,
+			vars: Vars{"A": requests, "B": Results{Values: Values{
+				makeSeries("b", data.Labels{"service": "api"}, point(1)),
+			}}},
+			err: "grouping labels must ensure unique matches",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := executeExpr(t, tt.expr, tt.vars)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tt.expected))
+			for i, expected := range tt.expected {
+				assert.Equal(t, expected.labels, res.Values[i].GetLabels())
+				var value *float64
+				switch v := res.Values[i].(type) {
+				case Series:
+					value = v.GetValue(0)
+				case Number:
+					value = v.GetFloat64Value()
+				}
+				require.NotNil(t, value)
+				assert.InDelta(t, expected.value, *value, 0.0001)
+			}
+		})
+	}
+}
+
+func TestVectorMatchingDrops(t *testing.T) {
+	vars := Vars{
+		"A": Results{Values: Values{
+			makeNumber("a", data.Labels{"service": "api"}, float64Pointer(1)),
+			makeNumber("a", data.Labels{"service": "db"}, float64Pointer(2)),
+		}},
+		"B": Results{Values: Values{makeNumber("b", data.Labels{"service": "api"}, float64Pointer(3))}},
+	}
+	res, err := executeExpr(t, `$A + on(service) $B`, vars)
+	require.NoError(t, err)
+	require.Len(t, res.Values, 1)
+	n := res.Values[0].(Number)
+	assert.Equal(t, 4.0, *n.GetFloat64Value())
+	notices := n.Frame.Meta.Notices
+	require.Len(t, notices, 1)
+	assert.Contains(t, notices[0].Text, "1 items dropped from union(s)")
+	assert.Contains(t, notices[0].Text, "service=db")
+}
+
+func TestVectorMatchingNoData(t *testing.T) {
+	res, err := executeExpr(t, `$A + on(service) $B`, Vars{
+		"A": Results{Values: Values{NewNoData()}},
+		"B": Results{Values: Values{makeNumber("b", data.Labels{"service": "api"}, float64Pointer(3))}},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Values, 1)
+	assert.Equal(t, NewNoData(), res.Values[0])
+}
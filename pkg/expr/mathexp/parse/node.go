@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -300,24 +301,42 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching holds the vector matching modifiers of the operation, it is nil when
+	// the values of the arguments are matched by their labels alone.
+	Matching *VectorMatching
 }
 
-func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
-	return &BinaryNode{NodeType: NodeBinary, Pos: operator.pos, Args: [2]Node{arg1, arg2}, Operator: operator, OpStr: operator.val}
+func newBinary(operator item, matching *VectorMatching, arg1, arg2 Node) *BinaryNode {
+	return &BinaryNode{NodeType: NodeBinary, Pos: operator.pos, Args: [2]Node{arg1, arg2}, Operator: operator, OpStr: operator.val, Matching: matching}
 }
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
-	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
+	return fmt.Sprintf("%s %s%s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
-	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
+	return fmt.Sprintf("%s%s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	if b.Matching != nil {
+		for _, arg := range b.Args {
+			if arg.Return() == TypeScalar {
+				return fmt.Errorf("parse: vector matching is only allowed between sets, got %v in %s", TypeScalar, b)
+			}
+		}
+		if err := b.Matching.Check(); err != nil {
+			return err
+		}
+	}
+	for _, arg := range b.Args {
+		if err := arg.Check(t); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -331,6 +350,67 @@ func (b *BinaryNode) Return() ReturnType {
 	return t0
 }
 
+// VectorMatchCardinality describes how many values on each side of a binary operation
+// may be matched with each other.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne requires every value to match at most one value on the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne allows many values on the left side to match one value on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany allows one value on the left side to match many values on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching holds the on/ignoring and group_left/group_right modifiers of a binary operation.
+type VectorMatching struct {
+	// On is true when the values are matched on MatchingLabels only (on), and false when
+	// they are matched on all labels but MatchingLabels (ignoring).
+	On             bool
+	MatchingLabels []string
+	Card           VectorMatchCardinality
+	// Include holds the labels that are copied from the "one" side to the result of a
+	// many-to-one or one-to-many operation.
+	Include []string
+}
+
+// String returns the string representation of the modifiers, with a leading space.
+func (m *VectorMatching) String() string {
+	if m == nil {
+		return ""
+	}
+	keyword := "ignoring"
+	if m.On {
+		keyword = "on"
+	}
+	s := fmt.Sprintf(" %s(%s)", keyword, strings.Join(m.MatchingLabels, ", "))
+	switch m.Card {
+	case CardManyToOne:
+		s += fmt.Sprintf(" group_left(%s)", strings.Join(m.Include, ", "))
+	case CardOneToMany:
+		s += fmt.Sprintf(" group_right(%s)", strings.Join(m.Include, ", "))
+	}
+	return s
+}
+
+// Check verifies that the labels of the modifiers do not contradict each other.
+func (m *VectorMatching) Check() error {
+	seen := make(map[string]struct{}, len(m.MatchingLabels))
+	for _, l := range m.MatchingLabels {
+		if _, ok := seen[l]; ok {
+			return fmt.Errorf("parse: label %q is repeated in %s", l, m)
+		}
+		seen[l] = struct{}{}
+	}
+	for _, l := range m.Include {
+		if _, ok := seen[l]; ok && m.On {
+			return fmt.Errorf("parse: label %q must not occur in on and group clauses at once", l)
+		}
+	}
+	return nil
+}
+
 // UnaryNode holds one argument and an operator.
 type UnaryNode struct {
 	NodeType
@@ -165,16 +165,18 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [match] A}
+A -> C {"&&" [match] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [match] P}
+P -> M {( "+" | "-" ) [match] M}
+M -> E {( "*" | "/" ) [match] F}
+E -> F {( "**" ) [match] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+match -> ("on" | "ignoring") labels [("group_left" | "group_right") [labels]]
+labels -> "(" [name {"," name}] ")"
 */
 
 // expr:
@@ -185,7 +187,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = newBinary(t.next(), t.vectorMatching(), n, t.A())
 		default:
 			return n
 		}
@@ -198,7 +200,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = newBinary(t.next(), t.vectorMatching(), n, t.C())
 		default:
 			return n
 		}
@@ -211,7 +213,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = newBinary(t.next(), t.vectorMatching(), n, t.P())
 		default:
 			return n
 		}
@@ -224,7 +226,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = newBinary(t.next(), t.vectorMatching(), n, t.M())
 		default:
 			return n
 		}
@@ -237,7 +239,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = newBinary(t.next(), t.vectorMatching(), n, t.E())
 		default:
 			return n
 		}
@@ -250,7 +252,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = newBinary(t.next(), t.vectorMatching(), n, t.F())
 		default:
 			return n
 		}
@@ -275,6 +277,56 @@ func (t *Tree) F() Node {
 	return nil
 }
 
+// vectorMatching parses the optional match modifiers that follow a binary operator.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{On: token.val == "on", MatchingLabels: t.labelList(token.val)}
+	if token = t.peek(); token.typ == itemFunc && (token.val == "group_left" || token.val == "group_right") {
+		t.next()
+		m.Card = CardManyToOne
+		if token.val == "group_right" {
+			m.Card = CardOneToMany
+		}
+		if t.peek().typ == itemLeftParen {
+			m.Include = t.labelList(token.val)
+		}
+	}
+	return m
+}
+
+// labelList parses a parenthesized, comma separated list of label names.
+func (t *Tree) labelList(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	expectLabel := true
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			if !expectLabel {
+				t.unexpected(token, context)
+			}
+			labels = append(labels, token.val)
+			expectLabel = false
+		case itemComma:
+			if expectLabel {
+				t.unexpected(token, context)
+			}
+			expectLabel = true
+		case itemRightParen:
+			if expectLabel && len(labels) > 0 {
+				t.unexpected(token, context)
+			}
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // V is number | func(..) | queryVar in the grammar.
 func (t *Tree) v() Node {
 	switch token := t.next(); token.typ {
@@ -314,22 +366,40 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	// Arguments after the first one must be preceded by a comma.
+	expectArg := true
 	for {
 		switch token = t.next(); token.typ {
 		default:
+			if !expectArg {
+				t.unexpected(token, "func")
+			}
 			t.backup()
 			node := t.O()
 			f.append(node)
 			if len(f.Args) == 1 && f.F.VariantReturn {
 				f.F.Return = node.Return()
 			}
+			expectArg = false
 		case itemString:
+			if !expectArg {
+				t.unexpected(token, "func")
+			}
 			s, err := strconv.Unquote(token.val)
 			if err != nil {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+			expectArg = false
+		case itemComma:
+			if expectArg {
+				t.unexpected(token, "func")
+			}
+			expectArg = true
 		case itemRightParen:
+			if expectArg && len(f.Args) > 0 {
+				t.unexpected(token, "func")
+			}
 			return
 		}
 	}
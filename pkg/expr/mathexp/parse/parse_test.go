@@ -0,0 +1,98 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFuncArgs(t *testing.T) {
+	funcs := map[string]Func{
+		"window": {
+			Args:   []ReturnType{TypeSeriesSet, TypeString},
+			Return: TypeSeriesSet,
+		},
+		"clamp": {
+			Args:          []ReturnType{TypeVariantSet, TypeScalar, TypeScalar},
+			VariantReturn: true,
+		},
+	}
+
+	tests := []struct {
+		expr string
+		ast  string
+		err  bool
+	}{
+		{expr: `window($A, "5m")`, ast: `window($A, "5m")`},
+		{expr: `clamp($A, -1, 1 + 1)`, ast: `clamp($A, -1, 1 + 1)`},
+		{expr: `clamp(window($A, "5m"), 0, 100)`, ast: `clamp(window($A, "5m"), 0, 100)`},
+		{expr: `window($A "5m")`, err: true},
+		{expr: `window($A, , "5m")`, err: true},
+		{expr: `window(, $A, "5m")`, err: true},
+		{expr: `window($A, "5m",)`, err: true},
+		{expr: `window($A, 5)`, err: true},
+		{expr: `window($A)`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			tree, err := Parse(tt.expr, funcs)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.ast, tree.Root.String())
+		})
+	}
+}
+
+func TestParseVectorMatching(t *testing.T) {
+	tests := []struct {
+		expr     string
+		ast      string
+		matching *VectorMatching
+		err      bool
+	}{
+		{
+			expr:     `$A / on(host, k8s_ns) $B`,
+			ast:      `$A / on(host, k8s_ns) $B`,
+			matching: &VectorMatching{On: true, MatchingLabels: []string{"host", "k8s_ns"}},
+		},
+		{
+			expr:     `$A > ignoring(instance) $B`,
+			ast:      `$A > ignoring(instance) $B`,
+			matching: &VectorMatching{MatchingLabels: []string{"instance"}},
+		},
+		{
+			expr:     `$A * on(service) group_left(team) $B`,
+			ast:      `$A * on(service) group_left(team) $B`,
+			matching: &VectorMatching{On: true, MatchingLabels: []string{"service"}, Card: CardManyToOne, Include: []string{"team"}},
+		},
+		{
+			expr:     `$A - ignoring() group_right $B`,
+			ast:      `$A - ignoring() group_right() $B`,
+			matching: &VectorMatching{MatchingLabels: []string{}, Card: CardOneToMany},
+		},
+		{expr: `$A + group_left $B`, err: true},
+		{expr: `$A + on $B`, err: true},
+		{expr: `$A + on(host,) $B`, err: true},
+		{expr: `$A + on(host host) $B`, err: true},
+		{expr: `$A + on(host, host) $B`, err: true},
+		{expr: `$A + on(host) group_left(host) $B`, err: true},
+		{expr: `$A + on(host) 1`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			tree, err := Parse(tt.expr)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.ast, tree.Root.String())
+			require.IsType(t, &BinaryNode{}, tree.Root)
+			assert.Equal(t, tt.matching, tree.Root.(*BinaryNode).Matching)
+		})
+	}
+}
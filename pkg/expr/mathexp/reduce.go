@@ -15,18 +15,34 @@ type ReducerFunc = func(fv *Float64Field) *float64
 type ReducerID string
 
 const (
-	ReducerSum    ReducerID = "sum"
-	ReducerMean   ReducerID = "mean"
-	ReducerMin    ReducerID = "min"
-	ReducerMax    ReducerID = "max"
-	ReducerCount  ReducerID = "count"
-	ReducerLast   ReducerID = "last"
-	ReducerMedian ReducerID = "median"
+	ReducerSum           ReducerID = "sum"
+	ReducerMean          ReducerID = "mean"
+	ReducerMin           ReducerID = "min"
+	ReducerMax           ReducerID = "max"
+	ReducerCount         ReducerID = "count"
+	ReducerLast          ReducerID = "last"
+	ReducerMedian        ReducerID = "median"
+	ReducerP95           ReducerID = "p95"
+	ReducerP99           ReducerID = "p99"
+	ReducerStdDev        ReducerID = "stddev"
+	ReducerFirst         ReducerID = "first"
+	ReducerRange         ReducerID = "range"
+	ReducerCountDistinct ReducerID = "count_distinct"
+	ReducerPercentAbove  ReducerID = "percent_above"
 )
 
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian,
+		ReducerP95, ReducerP99, ReducerStdDev, ReducerFirst, ReducerRange, ReducerCountDistinct, ReducerPercentAbove,
+	}
+}
+
+// ReducerOptions holds the arguments of the reducers that take one.
+type ReducerOptions struct {
+	// Threshold is the value that points are compared to by the percent_above reducer. Defaults to 0.
+	Threshold *float64
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -126,7 +142,114 @@ func Median(fv *Float64Field) *float64 {
 	}
 }
 
+// numbers returns the values of the field, or false if any of them is null or NaN.
+func numbers(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+// Percentile returns a reducer that computes the p-th percentile, with p between 0 and 1,
+// by linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := numbers(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		sort.Float64s(values)
+		rank := p * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	values, ok := numbers(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	f := math.Sqrt(sq / float64(len(values)))
+	return &f
+}
+
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Range returns the difference between the maximum and the minimum value.
+func Range(fv *Float64Field) *float64 {
+	lowest, highest := Min(fv), Max(fv)
+	f := *highest - *lowest
+	return &f
+}
+
+// CountDistinct returns the number of different values.
+func CountDistinct(fv *Float64Field) *float64 {
+	values, ok := numbers(fv)
+	if !ok {
+		nan := math.NaN()
+		return &nan
+	}
+	distinct := make(map[float64]struct{}, len(values))
+	for _, v := range values {
+		distinct[v] = struct{}{}
+	}
+	f := float64(len(distinct))
+	return &f
+}
+
+// PercentAbove returns a reducer that computes the percentage, between 0 and 100,
+// of the values that are greater than the threshold.
+func PercentAbove(threshold float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := numbers(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		above := 0
+		for _, v := range values {
+			if v > threshold {
+				above++
+			}
+		}
+		f := float64(above) / float64(len(values)) * 100
+		return &f
+	}
+}
+
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
+	return GetReduceFuncWithOptions(rFunc, ReducerOptions{})
+}
+
+// GetReduceFuncWithOptions returns the reducer function with the given arguments.
+func GetReduceFuncWithOptions(rFunc ReducerID, opts ReducerOptions) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
 		return Sum, nil
@@ -142,6 +265,24 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Last, nil
 	case ReducerMedian:
 		return Median, nil
+	case ReducerP95:
+		return Percentile(0.95), nil
+	case ReducerP99:
+		return Percentile(0.99), nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerRange:
+		return Range, nil
+	case ReducerCountDistinct:
+		return CountDistinct, nil
+	case ReducerPercentAbove:
+		threshold := 0.0
+		if opts.Threshold != nil {
+			threshold = *opts.Threshold
+		}
+		return PercentAbove(threshold), nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -151,6 +292,11 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
 func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Number, error) {
+	return s.ReduceWithOptions(refID, rFunc, ReducerOptions{}, mapper)
+}
+
+// ReduceWithOptions is like Reduce, for reducers that take arguments.
+func (s Series) ReduceWithOptions(refID string, rFunc ReducerID, opts ReducerOptions, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -163,7 +309,7 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	}
 	fVec := series.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetReduceFuncWithOptions(rFunc, opts)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
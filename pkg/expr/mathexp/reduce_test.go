@@ -543,3 +543,57 @@ func sortedFloat64(f []float64) []float64 {
 	sort.Float64s(f)
 	return f
 }
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	values := makeSeries("", nil,
+		tp{time.Unix(1, 0), float64Pointer(4)},
+		tp{time.Unix(2, 0), float64Pointer(1)},
+		tp{time.Unix(3, 0), float64Pointer(3)},
+		tp{time.Unix(4, 0), float64Pointer(1)},
+		tp{time.Unix(5, 0), float64Pointer(6)},
+	)
+	withNaN := makeSeries("", nil,
+		tp{time.Unix(1, 0), float64Pointer(4)},
+		tp{time.Unix(2, 0), float64Pointer(math.NaN())},
+		tp{time.Unix(3, 0), nil},
+		tp{time.Unix(4, 0), float64Pointer(2)},
+	)
+
+	tests := []struct {
+		red      ReducerID
+		opts     ReducerOptions
+		expected float64
+		dropNN   float64
+	}{
+		{red: ReducerP95, expected: 5.6, dropNN: 3.9},
+		{red: ReducerP99, expected: 5.92, dropNN: 3.98},
+		{red: ReducerStdDev, expected: math.Sqrt(3.6), dropNN: 1},
+		{red: ReducerFirst, expected: 4, dropNN: 4},
+		{red: ReducerRange, expected: 5, dropNN: 2},
+		{red: ReducerCountDistinct, expected: 4, dropNN: 2},
+		{red: ReducerPercentAbove, opts: ReducerOptions{Threshold: float64Pointer(2)}, expected: 60, dropNN: 50},
+		{red: ReducerPercentAbove, expected: 100, dropNN: 100},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.red), func(t *testing.T) {
+			n, err := values.ReduceWithOptions("", tt.red, tt.opts, nil)
+			require.NoError(t, err)
+			require.InDelta(t, tt.expected, *n.GetFloat64Value(), 0.0001)
+
+			n, err = withNaN.ReduceWithOptions("", tt.red, tt.opts, DropNonNumber{})
+			require.NoError(t, err)
+			require.InDelta(t, tt.dropNN, *n.GetFloat64Value(), 0.0001)
+
+			n, err = withNaN.ReduceWithOptions("", tt.red, tt.opts, ReplaceNonNumberWithValue{Value: 4})
+			require.NoError(t, err)
+			require.NotNil(t, n.GetFloat64Value())
+			require.False(t, math.IsNaN(*n.GetFloat64Value()))
+
+			if tt.red != ReducerFirst {
+				n, err = withNaN.ReduceWithOptions("", tt.red, tt.opts, nil)
+				require.NoError(t, err)
+				require.True(t, math.IsNaN(*n.GetFloat64Value()), "strict mode returns NaN for non-numbers")
+			}
+		})
+	}
+}
@@ -24,6 +24,11 @@ const (
 
 // Resample turns the Series into a Number based on the given reduction function
 func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+	return s.ResampleWithOptions(refID, interval, downsampler, ReducerOptions{}, upsampler, from, to)
+}
+
+// ResampleWithOptions is like Resample, for downsamplers that take arguments.
+func (s Series) ResampleWithOptions(refID string, interval time.Duration, downsampler ReducerID, opts ReducerOptions, upsampler Upsampler, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
@@ -74,22 +79,11 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
+			reduceFunc, err := GetReduceFuncWithOptions(downsampler, opts)
+			if err != nil {
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
-			value = tmp
+			value = reduceFunc(&ff)
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
@@ -277,6 +277,34 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: downsampling (p95 / pad )",
+			interval:    time.Second * 5,
+			downsampler: "p95",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(1, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2.95),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(4.8),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,264 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// windowPoint is a point of a series with a non-null value.
+type windowPoint struct {
+	t time.Time
+	v float64
+}
+
+// windowFunc computes the value of a point from the non-null points in the window ending at that point,
+// which are in time order and include the point itself. A nil result means that there is no value.
+type windowFunc func(points []windowPoint) *float64
+
+// parseWindow parses the duration of a window function argument, which must be positive.
+func parseWindow(name, rawWindow string) (time.Duration, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s window %q: %w", name, rawWindow, err)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("%s window must be positive, got %q", name, rawWindow)
+	}
+	return window, nil
+}
+
+// sortedByTime returns the indices of the points of the series ordered by time, from oldest to newest.
+func (s Series) sortedByTime() []int {
+	idx := make([]int, s.Len())
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return s.GetTime(idx[i]).Before(s.GetTime(idx[j]))
+	})
+	return idx
+}
+
+// Window returns a series with the result of f over the window (t - window, t] of every point at time t.
+// Points with a null value are excluded from the windows and have a null result. NaN values are
+// included, so they propagate to the results of the windows that contain them.
+// The returned series is sorted by time.
+func (s Series) Window(refID string, window time.Duration, f windowFunc) Series {
+	out := NewSeries(refID, s.GetLabels(), s.Len())
+	var points []windowPoint
+	for i, idx := range s.sortedByTime() {
+		t, v := s.GetPoint(idx)
+		if v == nil {
+			out.SetPoint(i, t, nil)
+			continue
+		}
+		points = append(points, windowPoint{t: t, v: *v})
+		start := 0
+		for start < len(points) && !points[start].t.After(t.Add(-window)) {
+			start++
+		}
+		points = points[start:]
+		out.SetPoint(i, t, f(points))
+	}
+	return out
+}
+
+// rateWindow is the per-second average rate of increase of a counter in the window. A decrease of
+// the value is treated as a counter reset, so the value after the reset is counted as increase.
+func rateWindow(points []windowPoint) *float64 {
+	if len(points) < 2 {
+		return nil
+	}
+	first, last := points[0], points[len(points)-1]
+	seconds := last.t.Sub(first.t).Seconds()
+	if seconds <= 0 {
+		return nil
+	}
+	var increase float64
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1].v, points[i].v
+		if cur < prev {
+			increase += cur
+		} else {
+			increase += cur - prev
+		}
+	}
+	rate := increase / seconds
+	return &rate
+}
+
+// deltaWindow is the difference between the last and the first value in the window.
+func deltaWindow(points []windowPoint) *float64 {
+	if len(points) < 2 {
+		return nil
+	}
+	delta := points[len(points)-1].v - points[0].v
+	return &delta
+}
+
+// derivWindow is the per-second derivative of the values in the window, using a simple linear regression.
+func derivWindow(points []windowPoint) *float64 {
+	xs := make([]float64, 0, len(points))
+	ys := make([]float64, 0, len(points))
+	for _, p := range points {
+		xs = append(xs, p.t.Sub(points[0].t).Seconds())
+		ys = append(ys, p.v)
+	}
+	slope, _, ok := leastSquares(xs, ys)
+	if !ok {
+		return nil
+	}
+	return &slope
+}
+
+// sumWindow is the sum of the values in the window.
+func sumWindow(points []windowPoint) *float64 {
+	var sum float64
+	for _, p := range points {
+		sum += p.v
+	}
+	return &sum
+}
+
+// avgWindow is the average of the values in the window.
+func avgWindow(points []windowPoint) *float64 {
+	avg := *sumWindow(points) / float64(len(points))
+	return &avg
+}
+
+// perSeries applies seriesF to every Series of varSet. NoData is passed through,
+// other types are not supported because they have no time.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			s, err := seriesF(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, s)
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s can only be applied to series, got type %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// windowFunction returns the implementation of a function that applies f over a window to every series.
+func windowFunction(name string, f windowFunc) func(e *State, varSet Results, rawWindow string) (Results, error) {
+	return func(e *State, varSet Results, rawWindow string) (Results, error) {
+		window, err := parseWindow(name, rawWindow)
+		if err != nil {
+			return Results{}, err
+		}
+		return perSeries(e, name, varSet, func(s Series) (Series, error) {
+			return s.Window(e.RefID, window, f), nil
+		})
+	}
+}
+
+// shift moves every point of each series later in time by the duration, so the value of a point is the
+// value of the series the duration before. A negative duration moves the points earlier.
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("failed to parse shift duration %q: %w", rawDuration, err)
+	}
+	return perSeries(e, "shift", varSet, func(s Series) (Series, error) {
+		out := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			out.SetPoint(i, t.Add(d), v)
+		}
+		return out, nil
+	})
+}
+
+// cumsum returns the running sum of each series. Null values are not added and have a null result.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) (Series, error) {
+		out := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i, idx := range s.sortedByTime() {
+			t, v := s.GetPoint(idx)
+			if v == nil {
+				out.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *v
+			value := sum
+			out.SetPoint(i, t, &value)
+		}
+		return out, nil
+	})
+}
+
+// timestamp returns the time of every point of each series as the number of seconds since the Unix epoch.
+// Points with a null value have a null result.
+func timestamp(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "timestamp", varSet, func(s Series) (Series, error) {
+		out := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			if v == nil {
+				out.SetPoint(i, t, nil)
+				continue
+			}
+			ts := float64(t.UnixNano()) / float64(time.Second)
+			out.SetPoint(i, t, &ts)
+		}
+		return out, nil
+	})
+}
+
+// clamp limits each value of a NumberSet, SeriesSet, or Scalar to the range [min, max].
+// Null and NaN values are returned as they are.
+func clamp(e *State, varSet Results, minSet Results, maxSet Results) (Results, error) {
+	lower, err := scalarArg("clamp", "min", minSet)
+	if err != nil {
+		return Results{}, err
+	}
+	upper, err := scalarArg("clamp", "max", maxSet)
+	if err != nil {
+		return Results{}, err
+	}
+	if lower > upper {
+		return Results{}, fmt.Errorf("clamp min %v must not be greater than max %v", lower, upper)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, func(f *float64) *float64 {
+			if f == nil || math.IsNaN(*f) {
+				return f
+			}
+			v := math.Max(lower, math.Min(upper, *f))
+			return &v
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar argument of a function.
+func scalarArg(funcName, argName string, r Results) (float64, error) {
+	if len(r.Values) != 1 || r.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s %s must be a scalar", funcName, argName)
+	}
+	f := r.Values[0].(Scalar).GetFloat64Value()
+	if f == nil || math.IsNaN(*f) {
+		return 0, fmt.Errorf("%s %s must be a number", funcName, argName)
+	}
+	return *f, nil
+}
@@ -0,0 +1,178 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func executeExpr(t *testing.T, expr string, vars Vars) (Results, error) {
+	t.Helper()
+	e, err := New(expr)
+	require.NoError(t, err)
+	return e.Execute("", vars, tracing.InitializeTracerForTest())
+}
+
+func TestWindowFuncs(t *testing.T) {
+	start := time.Unix(0, 0)
+	// A counter scraped every minute with a reset and a missing scrape.
+	counter := Vars{"A": resultValuesNoErr(seriesOf(start, time.Minute,
+		float64Pointer(0), float64Pointer(60), nil, float64Pointer(180), float64Pointer(30), float64Pointer(90),
+	))}
+
+	tests := []struct {
+		name     string
+		expr     string
+		vars     Vars
+		expected []*float64
+	}{
+		{
+			name:     "rate handles counter resets",
+			expr:     `rate($A, "2m")`,
+			vars:     counter,
+			expected: []*float64{nil, float64Pointer(1), nil, nil, float64Pointer(0.5), float64Pointer(1)},
+		},
+		{
+			name:     "delta",
+			expr:     `delta($A, "2m")`,
+			vars:     counter,
+			expected: []*float64{nil, float64Pointer(60), nil, nil, float64Pointer(-150), float64Pointer(60)},
+		},
+		{
+			name:     "deriv",
+			expr:     `deriv($A, "3m")`,
+			vars:     counter,
+			expected: []*float64{nil, float64Pointer(1), nil, float64Pointer(1), float64Pointer(-2.5), float64Pointer(-0.75)},
+		},
+		{
+			name:     "moving_avg",
+			expr:     `moving_avg($A, "2m")`,
+			vars:     counter,
+			expected: []*float64{float64Pointer(0), float64Pointer(30), nil, float64Pointer(180), float64Pointer(105), float64Pointer(60)},
+		},
+		{
+			name:     "moving_sum",
+			expr:     `moving_sum($A, "2m")`,
+			vars:     counter,
+			expected: []*float64{float64Pointer(0), float64Pointer(60), nil, float64Pointer(180), float64Pointer(210), float64Pointer(120)},
+		},
+		{
+			name:     "cumsum skips nulls",
+			expr:     `cumsum($A)`,
+			vars:     counter,
+			expected: []*float64{float64Pointer(0), float64Pointer(60), nil, float64Pointer(240), float64Pointer(270), float64Pointer(360)},
+		},
+		{
+			name:     "timestamp",
+			expr:     `timestamp($A)`,
+			vars:     counter,
+			expected: []*float64{float64Pointer(0), float64Pointer(60), nil, float64Pointer(180), float64Pointer(240), float64Pointer(300)},
+		},
+		{
+			name:     "clamp keeps nulls",
+			expr:     `clamp($A, 50, 100)`,
+			vars:     counter,
+			expected: []*float64{float64Pointer(50), float64Pointer(60), nil, float64Pointer(100), float64Pointer(50), float64Pointer(90)},
+		},
+		{
+			name: "window functions can be combined with math",
+			expr: `rate($A, "2m") * 60`,
+			vars: Vars{"A": resultValuesNoErr(seriesOf(start, time.Minute,
+				float64Pointer(0), float64Pointer(60), float64Pointer(120),
+			))},
+			expected: []*float64{nil, float64Pointer(60), float64Pointer(60)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := executeExpr(t, tt.expr, tt.vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			s := res.Values[0].(Series)
+			require.Equal(t, len(tt.expected), s.Len())
+			for i, expected := range tt.expected {
+				if expected == nil {
+					assert.Nil(t, s.GetValue(i), "point %d", i)
+					continue
+				}
+				require.NotNil(t, s.GetValue(i), "point %d", i)
+				assert.InDelta(t, *expected, *s.GetValue(i), 0.0001, "point %d", i)
+			}
+			assert.Equal(t, data.Labels{"host": "a"}, s.GetLabels())
+		})
+	}
+}
+
+func TestWindowFuncsNaN(t *testing.T) {
+	start := time.Unix(0, 0)
+	vars := Vars{"A": resultValuesNoErr(seriesOf(start, time.Minute,
+		float64Pointer(1), float64Pointer(math.NaN()), float64Pointer(3), float64Pointer(4),
+	))}
+	res, err := executeExpr(t, `moving_sum($A, "2m")`, vars)
+	require.NoError(t, err)
+	s := res.Values[0].(Series)
+	assert.Equal(t, 1.0, *s.GetValue(0))
+	assert.True(t, math.IsNaN(*s.GetValue(1)))
+	assert.True(t, math.IsNaN(*s.GetValue(2)))
+	assert.Equal(t, 7.0, *s.GetValue(3))
+}
+
+func TestWindowFuncsUnsortedSeries(t *testing.T) {
+	s := makeSeries("", nil,
+		tp{time.Unix(120, 0), float64Pointer(3)},
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(60, 0), float64Pointer(2)},
+	)
+	res, err := executeExpr(t, `cumsum($A)`, Vars{"A": resultValuesNoErr(s)})
+	require.NoError(t, err)
+	out := res.Values[0].(Series)
+	assert.Equal(t, time.Unix(0, 0), out.GetTime(0))
+	assert.Equal(t, 6.0, *out.GetValue(2))
+}
+
+func TestShiftFunc(t *testing.T) {
+	start := time.Unix(0, 0)
+	vars := Vars{"A": resultValuesNoErr(seriesOf(start, time.Minute, float64Pointer(1), nil))}
+	for _, expr := range []string{`shift($A, "1h")`, `offset($A, "1h")`} {
+		res, err := executeExpr(t, expr, vars)
+		require.NoError(t, err)
+		s := res.Values[0].(Series)
+		assert.Equal(t, start.Add(time.Hour), s.GetTime(0))
+		assert.Equal(t, 1.0, *s.GetValue(0))
+		assert.Nil(t, s.GetValue(1))
+	}
+}
+
+func TestWindowFuncsErrors(t *testing.T) {
+	series := Vars{"A": resultValuesNoErr(seriesOf(time.Unix(0, 0), time.Minute, float64Pointer(1)))}
+	numbers := Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1)))}
+
+	for _, tt := range []struct {
+		expr string
+		vars Vars
+	}{
+		{expr: `rate($A, "soon")`, vars: series},
+		{expr: `rate($A, "-5m")`, vars: series},
+		{expr: `rate($A, "5m")`, vars: numbers},
+		{expr: `timestamp($A)`, vars: numbers},
+		{expr: `clamp($A, 10, 1)`, vars: series},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := executeExpr(t, tt.expr, tt.vars)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("no data is passed through", func(t *testing.T) {
+		res, err := executeExpr(t, `rate($A, "5m")`, Vars{"A": resultValuesNoErr(NewNoData())})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		assert.Equal(t, NewNoData(), res.Values[0])
+	})
+}
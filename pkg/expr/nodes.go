@@ -164,6 +164,14 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeOutliers:
+		node.Command, err = UnmarshalOutliersCommand(rn)
+	case TypeSeasonal:
+		node.Command, err = UnmarshalSeasonalCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
+	case TypeAggregate:
+		node.Command, err = UnmarshalAggregateCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -323,7 +331,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), firstNode.datasource.Type).Inc()
 			}
 
-			resp, err := s.dataService.QueryData(ctx, req)
+			resp, err := s.queryData(ctx, req)
 			if err != nil {
 				for _, dn := range nodeGroup {
 					vars[dn.refID] = mathexp.Results{Error: MakeQueryError(firstNode.refID, firstNode.datasource.UID, err)}
@@ -398,7 +406,7 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 		s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), dn.datasource.Type).Inc()
 	}()
 
-	resp, err := s.dataService.QueryData(ctx, req)
+	resp, err := s.queryData(ctx, req)
 	if err != nil {
 		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
 	}
@@ -29,6 +29,18 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect outliers with z-score or MAD
+	QueryTypeOutliers QueryType = "outliers"
+
+	// Seasonal baseline and bands (Holt-Winters)
+	QueryTypeSeasonal QueryType = "seasonal"
+
+	// Linear forecast of query results
+	QueryTypeForecast QueryType = "forecast"
+
+	// Aggregate query results by label
+	QueryTypeAggregate QueryType = "aggregate"
 )
 
 type MathQuery struct {
@@ -58,6 +70,9 @@ type ResampleQuery struct {
 	// The downsample function
 	Downsampler mathexp.ReducerID `json:"downsampler"`
 
+	// The threshold of the percent_above downsampler. Defaults to 0
+	Threshold *float64 `json:"threshold,omitempty"`
+
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
 }
@@ -70,6 +85,69 @@ type ThresholdQuery struct {
 	Conditions []ThresholdConditionJSON `json:"conditions"`
 }
 
+type OutliersQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The outlier detection algorithm
+	Algorithm mathexp.OutlierAlgorithm `json:"algorithm"`
+
+	// Points with an absolute score above the threshold are outliers. Defaults to 3
+	Threshold *float64 `json:"threshold,omitempty"`
+
+	// Return the outlier flag (1 or 0) or the score of every point
+	Output OutliersOutput `json:"output,omitempty"`
+}
+
+type SeasonalQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The length of the seasonal cycle
+	Season string `json:"season" jsonschema:"minLength=1,example=1d,example=1w"`
+
+	// Smoothing factor of the level, between 0 and 1. Defaults to 0.5
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// Smoothing factor of the trend, between 0 and 1. Defaults to 0.1
+	Beta *float64 `json:"beta,omitempty"`
+
+	// Smoothing factor of the seasonal component, between 0 and 1. Defaults to 0.3
+	Gamma *float64 `json:"gamma,omitempty"`
+
+	// Width of the band around the baseline in standard deviations. Defaults to 3
+	Deviations *float64 `json:"deviations,omitempty"`
+
+	// The output of the model
+	Output mathexp.SeasonalOutput `json:"output,omitempty"`
+}
+
+type ForecastQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// How far after the evaluation time the value is predicted
+	Horizon string `json:"horizon" jsonschema:"minLength=1,example=1h,example=4h"`
+}
+
+// QueryType = aggregate
+type AggregateQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The reducer applied to the values of each group
+	Reducer mathexp.ReducerID `json:"reducer"`
+
+	// The labels that values are grouped by
+	By []string `json:"by,omitempty"`
+
+	// The labels that are removed to group values, the other labels are kept. Can not be used with by
+	Without []string `json:"without,omitempty"`
+
+	// Reducer Options
+	Settings *ReduceSettings `json:"settings,omitempty"`
+}
+
 type ClassicQuery struct {
 	Conditions []classic.ConditionJSON `json:"conditions"`
 }
@@ -89,6 +167,9 @@ type ReduceSettings struct {
 
 	// Only valid when mode is replace
 	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
+
+	// The threshold of the percent_above reducer. Defaults to 0
+	Threshold *float64 `json:"threshold,omitempty"`
 }
 
 // Non-Number behavior mode
@@ -106,6 +187,18 @@ const (
 	ReduceModeReplace ReduceMode = "replaceNN"
 )
 
+// The output of the outliers expression
+// +enum
+type OutliersOutput string
+
+const (
+	// 1 for outliers and 0 otherwise
+	OutliersOutputFlag OutliersOutput = "flag"
+
+	// The outlier score of every point
+	OutliersOutputScore OutliersOutput = "score"
+)
+
 //go:embed query.types.json
 var f embed.FS
 
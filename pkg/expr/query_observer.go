@@ -0,0 +1,30 @@
+package expr
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// DatasourceQueryObserver is called after every request a pipeline sends to a data source,
+// with the response of the data source, or the error if the request failed, and the time the request took.
+// It is not called for expressions, because they do not query a data source.
+type DatasourceQueryObserver func(resp *backend.QueryDataResponse, err error, dur time.Duration)
+
+type datasourceQueryObserverKey struct{}
+
+// WithDatasourceQueryObserver returns a context that makes the pipelines executed with it report their data source requests to the observer.
+func WithDatasourceQueryObserver(ctx context.Context, observer DatasourceQueryObserver) context.Context {
+	return context.WithValue(ctx, datasourceQueryObserverKey{}, observer)
+}
+
+// queryData sends the request to the data source and reports it to the observer of the context, if any.
+func (s *Service) queryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	start := time.Now()
+	resp, err := s.dataService.QueryData(ctx, req)
+	if observer, ok := ctx.Value(datasourceQueryObserverKey{}).(DatasourceQueryObserver); ok {
+		observer(resp, err, time.Since(start))
+	}
+	return resp, err
+}
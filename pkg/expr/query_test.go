@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestQueryTypeDefinitions(t *testing.T) {
@@ -27,6 +28,9 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(mathexp.OutlierZScore),
+				reflect.TypeOf(mathexp.SeasonalBaseline),
+				reflect.TypeOf(OutliersOutputFlag),
 			},
 		})
 	require.NoError(t, err)
@@ -136,6 +140,22 @@ func TestQueryTypeDefinitions(t *testing.T) {
 						}},
 					}),
 				},
+				{
+					Name: "With warning and critical levels",
+					SaveModel: data.AsUnstructured(ThresholdQuery{
+						Expression: "A",
+						Conditions: []ThresholdConditionJSON{
+							{
+								Level:     "warning",
+								Evaluator: ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{80}},
+							},
+							{
+								Level:     "critical",
+								Evaluator: ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{90}},
+							},
+						},
+					}),
+				},
 				{
 					Name: "With loaded+unloaded evaluators",
 					SaveModel: toUnstructured(`{
@@ -161,6 +181,70 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeOutliers),
+			GoType:         reflect.TypeOf(&OutliersQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Flag points of A more than 3.5 MADs from the median",
+					SaveModel: data.AsUnstructured(OutliersQuery{
+						Expression: "$A",
+						Algorithm:  mathexp.OutlierMAD,
+						Threshold:  util.Pointer(3.5),
+						Output:     OutliersOutputFlag,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeSeasonal),
+			GoType:         reflect.TypeOf(&SeasonalQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Daily seasonal anomalies of A",
+					SaveModel: data.AsUnstructured(SeasonalQuery{
+						Expression: "$A",
+						Season:     "1d",
+						Output:     mathexp.SeasonalAnomaly,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Value of A in 4 hours",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Horizon:    "4h",
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAggregate),
+			GoType:         reflect.TypeOf(&AggregateQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Sum of A by cluster",
+					SaveModel: data.AsUnstructured(AggregateQuery{
+						Expression: "$A",
+						Reducer:    mathexp.ReducerSum,
+						By:         []string{"cluster"},
+					}),
+				},
+				{
+					Name: "Max of A without the instance label",
+					SaveModel: data.AsUnstructured(AggregateQuery{
+						Expression: "$A",
+						Reducer:    mathexp.ReducerMax,
+						Without:    []string{"instance"},
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
@@ -66,6 +66,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 
 	case QueryTypeReduce:
 		var mapper mathexp.ReduceMapper = nil
+		var opts mathexp.ReducerOptions
 		q := &ReduceQuery{}
 		err = iter.ReadVal(q)
 		if err == nil {
@@ -73,6 +74,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Properties = q
 		}
 		if err == nil && q.Settings != nil {
+			opts.Threshold = q.Settings.Threshold
 			switch q.Settings.Mode {
 			case ReduceModeStrict:
 				mapper = nil
@@ -90,7 +92,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			eq.Properties = q
 			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+				q.Reducer, opts, referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -109,6 +111,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 				q.Window,
 				referenceVar,
 				q.Downsampler,
+				mathexp.ReducerOptions{Threshold: q.Threshold},
 				q.Upsampler,
 				AbsoluteTimeRange{
 					From: tr.GetFromAsTimeUTC(),
@@ -143,10 +146,15 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 		}
+		if err == nil && hasThresholdLevels(q.Conditions) {
+			eq.Properties = q
+			eq.Command, err = NewLevelsThresholdCommand(common.RefID, referenceVar, q.Conditions, h.features.IsEnabledGlobally(featuremgmt.FlagRecoveryThreshold))
+			return eq, err
+		}
 		if err == nil {
-			// we only support one condition for now, we might want to turn this in to "OR" expressions later
+			// we only support one condition without levels for now, we might want to turn this in to "OR" expressions later
 			if len(q.Conditions) != 1 {
-				return eq, fmt.Errorf("threshold expression requires exactly one condition")
+				return eq, fmt.Errorf("threshold expression requires exactly one condition, or one condition per level")
 			}
 			firstCondition := q.Conditions[0]
 
@@ -177,6 +185,47 @@ func (h *ExpressionQueryReader) ReadQuery(
 			}
 		}
 
+	case QueryTypeOutliers:
+		q := &OutliersQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewOutliersCommand(common.RefID, referenceVar, q.Algorithm, q.Threshold, q.Output)
+		}
+
+	case QueryTypeSeasonal:
+		q := &SeasonalQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewSeasonalCommand(common.RefID, referenceVar, *q)
+		}
+
+	case QueryTypeForecast:
+		q := &ForecastQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewForecastCommand(common.RefID, referenceVar, q.Horizon)
+		}
+
+	case QueryTypeAggregate:
+		q := &AggregateQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = newAggregateCommandFromQuery(common.RefID, *q)
+		}
+
 	default:
 		err = fmt.Errorf("unknown query type (%s)", common.QueryType)
 	}
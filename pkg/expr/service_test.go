@@ -146,6 +146,48 @@ func TestDSQueryError(t *testing.T) {
 	require.Equal(t, fp(42), res.Responses["C"].Frames[0].Fields[0].At(0))
 }
 
+func TestDatasourceQueryObserver(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(2)}),
+	)
+	resp := map[string]backend.DataResponse{
+		"A": {Frames: data.Frames{dsDF}},
+	}
+	queries := []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+		},
+	}
+
+	s, req := newMockQueryService(resp, queries)
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+
+	var observed []*backend.QueryDataResponse
+	ctx := WithDatasourceQueryObserver(context.Background(), func(resp *backend.QueryDataResponse, err error, _ time.Duration) {
+		require.NoError(t, err)
+		observed = append(observed, resp)
+	})
+	_, err = s.ExecutePipeline(ctx, time.Now(), pl)
+	require.NoError(t, err)
+
+	require.Len(t, observed, 1, "only the data source query is observed")
+	require.Equal(t, data.Frames{dsDF}, observed[0].Responses["A"].Frames)
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
@@ -140,9 +140,13 @@ func UnmarshalThresholdCommand(rn *rawNode, features featuremgmt.FeatureToggles)
 	}
 	referenceVar := cmdConfig.Expression
 
-	// we only support one condition for now, we might want to turn this in to "OR" expressions later
+	if hasThresholdLevels(cmdConfig.Conditions) {
+		return NewLevelsThresholdCommand(rn.RefID, referenceVar, cmdConfig.Conditions, features.IsEnabledGlobally(featuremgmt.FlagRecoveryThreshold))
+	}
+
+	// we only support one condition without levels for now, we might want to turn this in to "OR" expressions later
 	if len(cmdConfig.Conditions) != 1 {
-		return nil, fmt.Errorf("threshold expression requires exactly one condition")
+		return nil, fmt.Errorf("threshold expression requires exactly one condition, or one condition per level")
 	}
 	firstCondition := cmdConfig.Conditions[0]
 
@@ -237,6 +241,9 @@ type ThresholdCommandConfig struct {
 }
 
 type ThresholdConditionJSON struct {
+	// The name of the severity level of the condition, for example "warning" or "critical".
+	// Conditions with levels are ordered from the least to the most severe level.
+	Level            string             `json:"level,omitempty"`
 	Evaluator        ConditionEvalJSON  `json:"evaluator"`
 	UnloadEvaluator  *ConditionEvalJSON `json:"unloadEvaluator,omitempty"`
 	LoadedDimensions *data.Frame        `json:"loadedDimensions,omitempty"`
@@ -300,6 +307,10 @@ func getConditionForHysteresisCommand(query map[string]any) (map[string]any, err
 	if !ok {
 		return nil, nil
 	}
+	// conditions with levels are handled by the LevelsThresholdCommand
+	if _, ok = condition["level"]; ok {
+		return nil, nil
+	}
 	return condition, nil
 }
 
@@ -0,0 +1,265 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ThresholdLevel is a severity level of a LevelsThresholdCommand.
+type ThresholdLevel struct {
+	Name string
+	// Condition is the ThresholdCommand of the level, or a HysteresisCommand when the level has a recovery threshold.
+	Condition Command
+}
+
+// LevelsThresholdCommand is a threshold command with ordered severity levels, for example warning and critical,
+// where each level has its own threshold and optional recovery threshold.
+// The result for each value is the position of the most severe level that is met, starting at 1 for the first level,
+// or 0 when no level is met. Level names can be looked up with the result using ThresholdLevelName.
+type LevelsThresholdCommand struct {
+	RefID        string
+	ReferenceVar string
+	Levels       []ThresholdLevel
+}
+
+// NewLevelsThresholdCommand creates a LevelsThresholdCommand from conditions ordered from the least to the most severe level.
+// Recovery thresholds are only used when recovery is true.
+func NewLevelsThresholdCommand(refID, referenceVar string, conditions []ThresholdConditionJSON, recovery bool) (*LevelsThresholdCommand, error) {
+	if len(conditions) == 0 {
+		return nil, errors.New("threshold expression with levels requires at least one condition")
+	}
+	names := make(map[string]struct{}, len(conditions))
+	levels := make([]ThresholdLevel, 0, len(conditions))
+	for _, c := range conditions {
+		if c.Level == "" {
+			return nil, errors.New("all conditions of a threshold expression with levels must have a level")
+		}
+		if _, ok := names[c.Level]; ok {
+			return nil, fmt.Errorf("level %q is used by more than one condition", c.Level)
+		}
+		names[c.Level] = struct{}{}
+
+		threshold, err := NewThresholdCommand(refID, referenceVar, c.Evaluator.Type, c.Evaluator.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition of level %q: %w", c.Level, err)
+		}
+		level := ThresholdLevel{Name: c.Level, Condition: threshold}
+		if c.UnloadEvaluator != nil && recovery {
+			unloading, err := NewThresholdCommand(refID, referenceVar, c.UnloadEvaluator.Type, c.UnloadEvaluator.Params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid unloadCondition of level %q: %w", c.Level, err)
+			}
+			unloading.Invert = true
+			var d Fingerprints
+			if c.LoadedDimensions != nil {
+				d, err = FingerprintsFromFrame(c.LoadedDimensions)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse loaded dimensions of level %q: %w", c.Level, err)
+				}
+			}
+			level.Condition, err = NewHysteresisCommand(refID, referenceVar, *threshold, *unloading, d)
+			if err != nil {
+				return nil, err
+			}
+		}
+		levels = append(levels, level)
+	}
+	return &LevelsThresholdCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Levels:       levels,
+	}, nil
+}
+
+// hasThresholdLevels returns true if any of the conditions has a level.
+func hasThresholdLevels(conditions []ThresholdConditionJSON) bool {
+	for _, c := range conditions {
+		if c.Level != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (lc *LevelsThresholdCommand) NeedsVars() []string {
+	return []string{lc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (lc *LevelsThresholdCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	traceCtx, span := tracer.Start(ctx, "SSE.ExecuteThresholdLevels")
+	defer span.End()
+	span.SetAttributes(attribute.Int("levels", len(lc.Levels)))
+
+	// The results of every level by the fingerprint of the labels of the value.
+	levelResults := make([]map[data.Fingerprint]mathexp.Value, 0, len(lc.Levels))
+	for _, level := range lc.Levels {
+		res, err := level.Condition.Execute(traceCtx, now, vars, tracer)
+		if err != nil {
+			return mathexp.Results{}, fmt.Errorf("failed to execute the condition of level %q: %w", level.Name, err)
+		}
+		byFingerprint := make(map[data.Fingerprint]mathexp.Value, len(res.Values))
+		for _, v := range res.Values {
+			byFingerprint[v.GetLabels().Fingerprint()] = v
+		}
+		levelResults = append(levelResults, byFingerprint)
+	}
+
+	// highestLevel returns the position of the most severe level of the flags that is met, or nil if no level has a result.
+	highestLevel := func(flags []*float64) *float64 {
+		var level *float64
+		for i, f := range flags {
+			if f == nil {
+				continue
+			}
+			if level == nil {
+				level = util.Pointer(float64(0))
+			}
+			if *f == 1 {
+				level = util.Pointer(float64(i + 1))
+			}
+		}
+		return level
+	}
+
+	refVarResult := vars[lc.ReferenceVar]
+	newRes := mathexp.Results{Values: make(mathexp.Values, 0, len(refVarResult.Values))}
+	for _, val := range refVarResult.Values {
+		fp := val.GetLabels().Fingerprint()
+		flagsAt := func(get func(v mathexp.Value) *float64) []*float64 {
+			flags := make([]*float64, len(levelResults))
+			for i, results := range levelResults {
+				if v, ok := results[fp]; ok {
+					flags[i] = get(v)
+				}
+			}
+			return flags
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			s := mathexp.NewSeries(lc.RefID, v.GetLabels(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				s.SetPoint(i, v.GetTime(i), highestLevel(flagsAt(func(r mathexp.Value) *float64 {
+					if rs, ok := r.(mathexp.Series); ok && i < rs.Len() {
+						return rs.GetValue(i)
+					}
+					return nil
+				})))
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.Number:
+			n := mathexp.NewNumber(lc.RefID, v.GetLabels())
+			n.SetValue(highestLevel(flagsAt(func(r mathexp.Value) *float64 {
+				if rn, ok := r.(mathexp.Number); ok {
+					return rn.GetFloat64Value()
+				}
+				return nil
+			})))
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.Scalar:
+			newRes.Values = append(newRes.Values, mathexp.NewScalar(lc.RefID, highestLevel(flagsAt(func(r mathexp.Value) *float64 {
+				if rs, ok := r.(mathexp.Scalar); ok {
+					return rs.GetFloat64Value()
+				}
+				return nil
+			}))))
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, mathexp.NewNoData())
+		default:
+			return newRes, fmt.Errorf("unsupported format of the input data, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (lc *LevelsThresholdCommand) Type() string {
+	return TypeThreshold.String()
+}
+
+// ThresholdLevelName returns the name of the level for the result of a LevelsThresholdCommand.
+// It returns false when no level is met.
+func ThresholdLevelName(levels []string, result float64) (string, bool) {
+	i := int(result)
+	if float64(i) != result || i < 1 || i > len(levels) {
+		return "", false
+	}
+	return levels[i-1], true
+}
+
+// GetThresholdLevels returns the names of the levels of a threshold command with levels described by the raw model,
+// ordered from the least to the most severe level. It returns nil if the model does not describe such a command.
+func GetThresholdLevels(query map[string]any) []string {
+	conditions, err := getConditionsForLevelsThresholdCommand(query)
+	if err != nil {
+		return nil
+	}
+	levels := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		levels = append(levels, c["level"].(string))
+	}
+	return levels
+}
+
+// SetLoadedLevelsToThresholdLevelsCommand mutates the input map and sets field "loadedDimensions" of every condition
+// that has a recovery threshold with the fingerprints that were at that level, or a more severe one, in the previous evaluation.
+// Levels are positions of the levels starting at 1, like the results of the command.
+func SetLoadedLevelsToThresholdLevelsCommand(query map[string]any, loaded map[data.Fingerprint]int) error {
+	conditions, err := getConditionsForLevelsThresholdCommand(query)
+	if err != nil {
+		return err
+	}
+	for i, c := range conditions {
+		if _, ok := c["unloadEvaluator"]; !ok {
+			continue
+		}
+		fingerprints := Fingerprints{}
+		for fp, level := range loaded {
+			if level > i {
+				fingerprints[fp] = struct{}{}
+			}
+		}
+		c["loadedDimensions"] = FingerprintsToFrame(fingerprints)
+	}
+	return nil
+}
+
+func getConditionsForLevelsThresholdCommand(query map[string]any) ([]map[string]any, error) {
+	t, err := GetExpressionCommandType(query)
+	if err != nil {
+		return nil, err
+	}
+	if t != TypeThreshold {
+		return nil, errors.New("not a threshold command")
+	}
+	arr, ok := query["conditions"].([]any)
+	if !ok {
+		return nil, errors.New("invalid threshold command: field \"conditions\" expected to be an array of objects")
+	}
+	conditions := make([]map[string]any, 0, len(arr))
+	for _, c := range arr {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			return nil, errors.New("invalid threshold command: elements of field \"conditions\" expected to be objects")
+		}
+		if level, ok := condition["level"].(string); !ok || level == "" {
+			return nil, errors.New("not a threshold command with levels")
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 0 {
+		return nil, errors.New("not a threshold command with levels")
+	}
+	return conditions, nil
+}
@@ -0,0 +1,148 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const levelsThresholdQuery = `{
+	"expression": "A",
+	"type": "threshold",
+	"conditions": [
+		{
+			"level": "warning",
+			"evaluator": { "type": "gt", "params": [80] },
+			"unloadEvaluator": { "type": "lt", "params": [75] }
+		},
+		{
+			"level": "critical",
+			"evaluator": { "type": "gt", "params": [90] },
+			"unloadEvaluator": { "type": "lt", "params": [85] }
+		}
+	]
+}`
+
+func TestUnmarshalLevelsThresholdCommand(t *testing.T) {
+	unmarshal := func(t *testing.T, query string) (Command, error) {
+		t.Helper()
+		return UnmarshalThresholdCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(query),
+		}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryThreshold))
+	}
+
+	t.Run("unmarshal levels with recovery thresholds", func(t *testing.T) {
+		cmd, err := unmarshal(t, levelsThresholdQuery)
+		require.NoError(t, err)
+		require.IsType(t, &LevelsThresholdCommand{}, cmd)
+		levels := cmd.(*LevelsThresholdCommand)
+		assert.Equal(t, []string{"A"}, levels.NeedsVars())
+		require.Len(t, levels.Levels, 2)
+		assert.Equal(t, "warning", levels.Levels[0].Name)
+		require.IsType(t, &HysteresisCommand{}, levels.Levels[0].Condition)
+		assert.Equal(t, greaterThanPredicate{80}, levels.Levels[0].Condition.(*HysteresisCommand).LoadingThresholdFunc.predicate)
+		assert.Equal(t, "critical", levels.Levels[1].Name)
+		assert.Equal(t, greaterThanPredicate{90}, levels.Levels[1].Condition.(*HysteresisCommand).LoadingThresholdFunc.predicate)
+	})
+
+	t.Run("recovery thresholds are ignored without the feature flag", func(t *testing.T) {
+		cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", QueryRaw: []byte(levelsThresholdQuery)}, featuremgmt.WithFeatures())
+		require.NoError(t, err)
+		for _, level := range cmd.(*LevelsThresholdCommand).Levels {
+			require.IsType(t, &ThresholdCommand{}, level.Condition)
+		}
+	})
+
+	for name, query := range map[string]string{
+		"missing level":   `{"expression":"A","conditions":[{"level":"warning","evaluator":{"type":"gt","params":[1]}},{"evaluator":{"type":"gt","params":[2]}}]}`,
+		"duplicate level": `{"expression":"A","conditions":[{"level":"warning","evaluator":{"type":"gt","params":[1]}},{"level":"warning","evaluator":{"type":"gt","params":[2]}}]}`,
+		"invalid level":   `{"expression":"A","conditions":[{"level":"warning","evaluator":{"type":"foo","params":[1]}}]}`,
+	} {
+		t.Run("fails on "+name, func(t *testing.T) {
+			_, err := unmarshal(t, query)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLevelsThresholdExecute(t *testing.T) {
+	number := func(label string, value *float64) mathexp.Number {
+		n := mathexp.NewNumber("B", data.Labels{"host": label})
+		n.SetValue(value)
+		return n
+	}
+	fingerprint := func(label string) data.Fingerprint {
+		return data.Labels{"host": label}.Fingerprint()
+	}
+
+	query := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(levelsThresholdQuery), &query))
+	// "a" and "b" were critical, "c" was warning and "d" was normal in the previous evaluation.
+	require.NoError(t, SetLoadedLevelsToThresholdLevelsCommand(query, map[data.Fingerprint]int{
+		fingerprint("a"): 2,
+		fingerprint("b"): 2,
+		fingerprint("c"): 1,
+	}))
+	raw, err := json.Marshal(query)
+	require.NoError(t, err)
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", QueryRaw: raw}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryThreshold))
+	require.NoError(t, err)
+
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+		number("a", util.Pointer(88.0)), // below critical, above its recovery threshold
+		number("b", util.Pointer(80.0)), // recovered from critical but still warning
+		number("c", util.Pointer(78.0)), // below warning, above its recovery threshold
+		number("d", util.Pointer(88.0)), // warning, but not critical
+		number("e", util.Pointer(95.0)),
+		number("f", nil),
+	}}}
+	res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	assert.Equal(t, mathexp.Values{
+		number("a", util.Pointer(2.0)),
+		number("b", util.Pointer(1.0)),
+		number("c", util.Pointer(1.0)),
+		number("d", util.Pointer(1.0)),
+		number("e", util.Pointer(2.0)),
+		number("f", nil),
+	}, res.Values)
+
+	level, ok := ThresholdLevelName(GetThresholdLevels(query), 2)
+	assert.True(t, ok)
+	assert.Equal(t, "critical", level)
+	_, ok = ThresholdLevelName(GetThresholdLevels(query), 0)
+	assert.False(t, ok)
+}
+
+func TestLevelsThresholdExpression(t *testing.T) {
+	query := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(levelsThresholdQuery), &query))
+	assert.Equal(t, []string{"warning", "critical"}, GetThresholdLevels(query))
+	assert.False(t, IsHysteresisExpression(map[string]any{
+		"type":       "threshold",
+		"conditions": []any{map[string]any{"level": "warning", "unloadEvaluator": map[string]any{}}},
+	}), "conditions with levels are not handled as hysteresis")
+
+	for _, input := range []string{
+		`{}`,
+		`{ "type": "reduce" }`,
+		`{ "type": "threshold", "conditions": [] }`,
+		`{ "type": "threshold", "conditions": [{ "unloadEvaluator": {} }] }`,
+	} {
+		query := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(input), &query))
+		assert.Nil(t, GetThresholdLevels(query), input)
+		assert.Error(t, SetLoadedLevelsToThresholdLevelsCommand(query, nil), input)
+	}
+}
@@ -18,6 +18,8 @@ type RepositorySpecApplyConfiguration struct {
 	Type        *provisioningv0alpha1.RepositoryType      `json:"type,omitempty"`
 	Local       *LocalRepositoryConfigApplyConfiguration  `json:"local,omitempty"`
 	GitHub      *GitHubRepositoryConfigApplyConfiguration `json:"github,omitempty"`
+	Git         *GitRepositoryConfigApplyConfiguration    `json:"git,omitempty"`
+	Bucket      *BucketRepositoryConfigApplyConfiguration `json:"bucket,omitempty"`
 }
 
 // RepositorySpecApplyConfiguration constructs a declarative configuration of the RepositorySpec type for use with
@@ -83,3 +85,19 @@ func (b *RepositorySpecApplyConfiguration) WithGitHub(value *GitHubRepositoryCon
 	b.GitHub = value
 	return b
 }
+
+// WithGit sets the Git field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Git field is set to the value of the last call.
+func (b *RepositorySpecApplyConfiguration) WithGit(value *GitRepositoryConfigApplyConfiguration) *RepositorySpecApplyConfiguration {
+	b.Git = value
+	return b
+}
+
+// WithBucket sets the Bucket field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Bucket field is set to the value of the last call.
+func (b *RepositorySpecApplyConfiguration) WithBucket(value *BucketRepositoryConfigApplyConfiguration) *RepositorySpecApplyConfiguration {
+	b.Bucket = value
+	return b
+}
@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+type RepoLister interface {
+	// ListRepositories returns the repositories of all namespaces
+	ListRepositories(ctx context.Context) ([]provisioning.Repository, error)
+}
+
+// refReader is implemented by repositories that can tell whether they changed without reading all files,
+// e.g. versioned repositories or buckets.
+type refReader interface {
+	LatestRef(ctx context.Context) (string, error)
+}
+
+// syncTimeout is how long a sync can run before the poller considers that it was interrupted
+const syncTimeout = time.Hour
+
+// SyncPoller queues sync jobs for repositories with a sync interval.
+// Repositories with a latest ref are only synced when it differs from the one of the last sync,
+// so that repositories without webhooks (e.g. plain git servers or buckets) pick up changes.
+type SyncPoller struct {
+	lister RepoLister
+	getter RepoGetter
+	queue  JobQueue
+
+	// How often the poller checks which repositories are due
+	tick time.Duration
+	now  func() time.Time
+
+	// When the repository was last checked, by namespace/name
+	checked map[string]time.Time
+}
+
+func NewSyncPoller(lister RepoLister, getter RepoGetter, queue JobQueue) *SyncPoller {
+	return &SyncPoller{
+		lister:  lister,
+		getter:  getter,
+		queue:   queue,
+		tick:    10 * time.Second,
+		now:     time.Now,
+		checked: make(map[string]time.Time),
+	}
+}
+
+// Run polls the repositories until the context is cancelled
+func (p *SyncPoller) Run(ctx context.Context) {
+	logger := logging.DefaultLogger.With("logger", "sync-poller")
+	ctx = logging.Context(ctx, logger)
+
+	ticker := time.NewTicker(p.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+func (p *SyncPoller) poll(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
+	repos, err := p.lister.ListRepositories(ctx)
+	if err != nil {
+		logger.Warn("error listing repositories", "err", err)
+		return
+	}
+
+	now := p.now()
+	seen := make(map[string]time.Time, len(repos))
+	for _, cfg := range repos {
+		sync := cfg.Spec.Sync
+		if !sync.Enabled || sync.IntervalSeconds <= 0 {
+			continue
+		}
+
+		key := cfg.Namespace + "/" + cfg.Name
+		last, ok := p.checked[key]
+		if ok && now.Sub(last) < time.Duration(sync.IntervalSeconds)*time.Second {
+			seen[key] = last
+			continue
+		}
+		seen[key] = now
+
+		// A sync is already queued or running, unless it was interrupted (e.g. by a restart)
+		status := cfg.Status.Sync
+		running := status.State == provisioning.JobStatePending || status.State == provisioning.JobStateWorking
+		if running && now.Sub(time.UnixMilli(status.Started)) < syncTimeout {
+			continue
+		}
+
+		logger := logger.With("repository", cfg.Name, "namespace", cfg.Namespace)
+		if err := p.check(ctx, cfg); err != nil {
+			logger.Warn("error polling repository", "err", err)
+		}
+	}
+
+	// Forget about deleted repositories
+	p.checked = seen
+}
+
+// check queues a sync job if the repository changed since the last sync
+func (p *SyncPoller) check(ctx context.Context, cfg provisioning.Repository) error {
+	ctx = request.WithNamespace(ctx, cfg.Namespace)
+	ctx, _, err := identity.WithProvisioningIdentitiy(ctx, cfg.Namespace)
+	if err != nil {
+		return fmt.Errorf("get poller identity: %w", err)
+	}
+
+	repo, err := p.getter.GetRepository(ctx, cfg.Name)
+	if err != nil {
+		return fmt.Errorf("get repository: %w", err)
+	}
+
+	lastRef := cfg.Status.Sync.LastRef
+	if reader, ok := repo.(refReader); ok {
+		latest, err := reader.LatestRef(ctx)
+		if err != nil {
+			return fmt.Errorf("get latest ref: %w", err)
+		}
+		if latest == lastRef {
+			return nil // nothing changed
+		}
+	}
+
+	// Only versioned repositories can compare the changes since the last ref
+	_, versioned := repo.(repository.Versioned)
+	_, err = p.queue.Add(ctx, &provisioning.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cfg.Namespace,
+		},
+		Spec: provisioning.JobSpec{
+			Action:     provisioning.JobActionSync,
+			Repository: cfg.Name,
+			Sync: &provisioning.SyncJobOptions{
+				Incremental: versioned && lastRef != "",
+			},
+		},
+	})
+	return err
+}
@@ -0,0 +1,148 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+type fakeRepoLister []provisioning.Repository
+
+func (f fakeRepoLister) ListRepositories(ctx context.Context) ([]provisioning.Repository, error) {
+	return f, nil
+}
+
+type fakeRepoGetter map[string]repository.Repository
+
+func (f fakeRepoGetter) GetRepository(ctx context.Context, name string) (repository.Repository, error) {
+	return f[name], nil
+}
+
+type fakeVersionedRepo struct {
+	repository.Repository
+	ref string
+}
+
+func (f *fakeVersionedRepo) History(ctx context.Context, path, ref string) ([]provisioning.HistoryItem, error) {
+	return nil, nil
+}
+
+func (f *fakeVersionedRepo) LatestRef(ctx context.Context) (string, error) {
+	return f.ref, nil
+}
+
+func (f *fakeVersionedRepo) CompareFiles(ctx context.Context, base, ref string) ([]repository.VersionedFileChange, error) {
+	return nil, nil
+}
+
+// fakeRefRepo has a latest ref, but is not versioned (e.g. a bucket)
+type fakeRefRepo struct {
+	repository.Repository
+	ref string
+}
+
+func (f *fakeRefRepo) LatestRef(ctx context.Context) (string, error) {
+	return f.ref, nil
+}
+
+type fakeQueue struct {
+	JobQueue
+	jobs []provisioning.Job
+}
+
+func (f *fakeQueue) Add(ctx context.Context, job *provisioning.Job) (*provisioning.Job, error) {
+	f.jobs = append(f.jobs, *job)
+	return job, nil
+}
+
+func TestSyncPoller(t *testing.T) {
+	newRepo := func(name string, interval int64, lastRef string) provisioning.Repository {
+		return provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: provisioning.RepositorySpec{
+				Sync: provisioning.SyncOptions{Enabled: true, IntervalSeconds: interval},
+			},
+			Status: provisioning.RepositoryStatus{
+				Sync: provisioning.SyncStatus{LastRef: lastRef},
+			},
+		}
+	}
+
+	repos := fakeRepoLister{
+		newRepo("changed", 60, "abc"),
+		newRepo("unchanged", 60, "abc"),
+		newRepo("never-synced", 60, ""),
+		newRepo("no-interval", 0, ""),
+		newRepo("bucket", 60, "abc"),
+	}
+	getter := fakeRepoGetter{
+		"changed":      &fakeVersionedRepo{ref: "def"},
+		"unchanged":    &fakeVersionedRepo{ref: "abc"},
+		"never-synced": &fakeVersionedRepo{ref: "abc"},
+		"no-interval":  &fakeVersionedRepo{ref: "abc"},
+		"bucket":       &fakeRefRepo{ref: "def"},
+	}
+	queue := &fakeQueue{}
+
+	now := time.Now()
+	poller := NewSyncPoller(repos, getter, queue)
+	poller.now = func() time.Time { return now }
+
+	poller.poll(context.Background())
+	require.Len(t, queue.jobs, 3)
+	require.Equal(t, "changed", queue.jobs[0].Spec.Repository)
+	require.Equal(t, provisioning.JobActionSync, queue.jobs[0].Spec.Action)
+	require.Equal(t, "default", queue.jobs[0].Namespace)
+	require.True(t, queue.jobs[0].Spec.Sync.Incremental)
+	require.Equal(t, "never-synced", queue.jobs[1].Spec.Repository)
+	require.False(t, queue.jobs[1].Spec.Sync.Incremental)
+	require.Equal(t, "bucket", queue.jobs[2].Spec.Repository)
+	require.False(t, queue.jobs[2].Spec.Sync.Incremental, "only versioned repositories can sync incrementally")
+
+	// The interval has not elapsed yet
+	now = now.Add(30 * time.Second)
+	poller.poll(context.Background())
+	require.Len(t, queue.jobs, 3)
+
+	now = now.Add(31 * time.Second)
+	poller.poll(context.Background())
+	require.Len(t, queue.jobs, 6)
+}
+
+func TestSyncPoller_RunningSync(t *testing.T) {
+	now := time.Now()
+	newRepo := func(name string, started time.Time) provisioning.Repository {
+		return provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: provisioning.RepositorySpec{
+				Sync: provisioning.SyncOptions{Enabled: true, IntervalSeconds: 60},
+			},
+			Status: provisioning.RepositoryStatus{
+				Sync: provisioning.SyncStatus{State: provisioning.JobStateWorking, Started: started.UnixMilli(), LastRef: "abc"},
+			},
+		}
+	}
+
+	repos := fakeRepoLister{
+		newRepo("running", now.Add(-time.Minute)),
+		newRepo("interrupted", now.Add(-2*time.Hour)),
+	}
+	getter := fakeRepoGetter{
+		"running":     &fakeVersionedRepo{ref: "def"},
+		"interrupted": &fakeVersionedRepo{ref: "def"},
+	}
+	queue := &fakeQueue{}
+
+	poller := NewSyncPoller(repos, getter, queue)
+	poller.now = func() time.Time { return now }
+	poller.poll(context.Background())
+
+	require.Len(t, queue.jobs, 1)
+	require.Equal(t, "interrupted", queue.jobs[0].Spec.Repository)
+}
@@ -0,0 +1,483 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard/v1alpha1"
+	folders "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+// errNotResource is returned for files which are not resources, e.g. a README.
+var errNotResource = errors.New("the file is not a resource")
+
+var (
+	dashboardResource = dashboard.DashboardResourceInfo.GroupVersionResource()
+	folderResource    = folders.FolderResourceInfo.GroupVersionResource()
+)
+
+type RepoStatusUpdater interface {
+	// UpdateSyncStatus saves the sync status of a repository
+	UpdateSyncStatus(ctx context.Context, namespace, name string, status provisioning.SyncStatus) error
+}
+
+// SyncWorker imports the files of a repository into Grafana.
+// Dashboards are written with the repository information, and the directories of the repository become folders.
+// Resources of the repository are updated by later syncs, and deleted when their file is deleted.
+type SyncWorker struct {
+	client dynamic.Interface
+	status RepoStatusUpdater
+}
+
+func NewSyncWorker(client dynamic.Interface, status RepoStatusUpdater) *SyncWorker {
+	return &SyncWorker{
+		client: client,
+		status: status,
+	}
+}
+
+func (w *SyncWorker) IsSupported(ctx context.Context, job provisioning.Job) bool {
+	return job.Spec.Action == provisioning.JobActionSync
+}
+
+// Process syncs the repository and records the ref of the successful syncs in the repository status
+func (w *SyncWorker) Process(ctx context.Context, repo repository.Repository, job provisioning.Job, progress JobProgressRecorder) error {
+	cfg := repo.Config()
+	logger := logging.FromContext(ctx)
+
+	status := provisioning.SyncStatus{
+		State:       provisioning.JobStateWorking,
+		JobID:       job.Name,
+		Started:     time.Now().UnixMilli(),
+		Message:     []string{},
+		LastRef:     cfg.Status.Sync.LastRef,
+		Incremental: job.Spec.Sync != nil && job.Spec.Sync.Incremental,
+	}
+	if err := w.status.UpdateSyncStatus(ctx, cfg.Namespace, cfg.Name, status); err != nil {
+		logger.Warn("error updating sync status", "err", err)
+	}
+
+	ref, syncErr := w.sync(ctx, repo, status.Incremental, progress)
+
+	status.Finished = time.Now().UnixMilli()
+	if syncErr != nil {
+		status.State = provisioning.JobStateError
+		status.Message = []string{syncErr.Error()}
+	} else {
+		status.State = provisioning.JobStateSuccess
+		status.LastRef = ref
+	}
+	if err := w.status.UpdateSyncStatus(ctx, cfg.Namespace, cfg.Name, status); err != nil {
+		logger.Warn("error updating sync status", "err", err)
+	}
+	return syncErr
+}
+
+// managedResource is a resource written by a previous sync
+type managedResource struct {
+	resource schema.GroupVersionResource
+	name     string
+}
+
+// syncer holds the state of a single sync
+type syncer struct {
+	client dynamic.Interface
+	cfg    *provisioning.Repository
+	reader repository.Reader
+	ref    string
+
+	// The dashboards and folders of the repository, by path
+	files   map[string]managedResource
+	folders map[string]managedResource
+	// The folders created or updated by this sync, by path
+	synced map[string]string
+}
+
+// sync imports the changes of the repository, and returns the ref which has been imported.
+// Versioned repositories only import the changes since the last sync when incremental is set.
+// The last sync is not advanced when some files could not be imported, so that they are imported again.
+func (w *SyncWorker) sync(ctx context.Context, repo repository.Repository, incremental bool, progress JobProgressRecorder) (string, error) {
+	cfg := repo.Config()
+	reader, ok := repo.(repository.Reader)
+	if !ok {
+		return "", errors.New("the repository can not be read")
+	}
+
+	// Versioned repositories are read at the latest commit, so that all files are read at the same version
+	s := &syncer{client: w.client, cfg: cfg, reader: reader, synced: map[string]string{}}
+	var latest string
+	versioned, isVersioned := repo.(repository.Versioned)
+	if r, ok := repo.(refReader); ok {
+		var err error
+		if latest, err = r.LatestRef(ctx); err != nil {
+			return "", fmt.Errorf("get latest ref: %w", err)
+		}
+		if isVersioned {
+			s.ref = latest
+		}
+	}
+	progress.SetRef(latest)
+
+	var err error
+	if s.files, err = s.list(ctx, dashboardResource); err != nil {
+		return "", err
+	}
+	if s.folders, err = s.list(ctx, folderResource); err != nil {
+		return "", err
+	}
+
+	var changes []repository.VersionedFileChange
+	lastRef := cfg.Status.Sync.LastRef
+	incremental = incremental && isVersioned && lastRef != ""
+	if incremental {
+		if lastRef == latest {
+			progress.SetMessage("the repository did not change")
+			return latest, nil
+		}
+		if changes, err = versioned.CompareFiles(ctx, lastRef, latest); err != nil {
+			return "", fmt.Errorf("compare files: %w", err)
+		}
+	} else if changes, err = s.changes(ctx); err != nil {
+		return "", err
+	}
+
+	progress.SetTotal(len(changes))
+	failed := false
+	for _, change := range changes {
+		result := s.apply(ctx, change)
+		failed = failed || result.Error != nil
+		progress.Record(ctx, result)
+		if err := progress.TooManyErrors(); err != nil {
+			return "", err
+		}
+	}
+
+	// Folders are only removed by full syncs, once their dashboards have been removed
+	if !incremental {
+		for _, result := range s.deleteFolders(ctx) {
+			failed = failed || result.Error != nil
+			progress.Record(ctx, result)
+		}
+	}
+
+	if failed {
+		return "", errors.New("some files could not be imported")
+	}
+	return latest, nil
+}
+
+// list returns the resources of the repository, by path
+func (s *syncer) list(ctx context.Context, resource schema.GroupVersionResource) (map[string]managedResource, error) {
+	list, err := s.client.Resource(resource).Namespace(s.cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", resource.Resource, err)
+	}
+
+	result := make(map[string]managedResource)
+	for i := range list.Items {
+		obj, err := utils.MetaAccessor(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		repo, err := obj.GetRepositoryInfo()
+		if err != nil || repo == nil || repo.Name != s.cfg.Name {
+			continue
+		}
+		result[repo.Path] = managedResource{resource: resource, name: obj.GetName()}
+	}
+	return result, nil
+}
+
+// changes compares the files of the repository with the resources of the previous syncs
+func (s *syncer) changes(ctx context.Context) ([]repository.VersionedFileChange, error) {
+	tree, err := s.reader.ReadTree(ctx, s.ref)
+	if err != nil {
+		return nil, fmt.Errorf("read tree: %w", err)
+	}
+
+	var changes []repository.VersionedFileChange
+	seen := make(map[string]bool, len(tree))
+	for _, entry := range tree {
+		if !entry.Blob {
+			continue
+		}
+		seen[entry.Path] = true
+		action := repository.FileActionCreated
+		if _, ok := s.files[entry.Path]; ok {
+			action = repository.FileActionUpdated
+		}
+		changes = append(changes, repository.VersionedFileChange{Action: action, Path: entry.Path, Ref: s.ref})
+	}
+
+	var deleted []string
+	for p := range s.files {
+		if !seen[p] {
+			deleted = append(deleted, p)
+		}
+	}
+	sort.Strings(deleted)
+	for _, p := range deleted {
+		changes = append(changes, repository.VersionedFileChange{Action: repository.FileActionDeleted, Path: p})
+	}
+	return changes, nil
+}
+
+func (s *syncer) apply(ctx context.Context, change repository.VersionedFileChange) JobResourceResult {
+	result := JobResourceResult{
+		Path:     change.Path,
+		Action:   change.Action,
+		Group:    dashboardResource.Group,
+		Resource: dashboardResource.Resource,
+	}
+
+	switch change.Action {
+	case repository.FileActionDeleted:
+		result.Name, result.Error = s.delete(ctx, change.Path)
+		return result
+	case repository.FileActionRenamed:
+		if _, err := s.delete(ctx, change.PreviousPath); err != nil {
+			result.Error = err
+			return result
+		}
+	case repository.FileActionCreated, repository.FileActionUpdated:
+	default:
+		return result
+	}
+
+	info, err := s.reader.Read(ctx, change.Path, s.ref)
+	if err != nil {
+		result.Error = fmt.Errorf("read file: %w", err)
+		return result
+	}
+	obj, err := parseResource(info.Data)
+	if errors.Is(err, errNotResource) {
+		result.Action = repository.FileActionIgnored
+		return result
+	}
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	if obj.GetName() == "" {
+		obj.SetName(s.nameFor(change.Path))
+	}
+	result.Name = obj.GetName()
+	folder, err := s.ensureFolder(ctx, path.Dir(change.Path))
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	meta.SetFolder(folder)
+	var modified *time.Time
+	if info.Modified != nil {
+		modified = &info.Modified.Time
+	}
+	meta.SetRepositoryInfo(&utils.ResourceRepositoryInfo{
+		Name:      s.cfg.Name,
+		Path:      change.Path,
+		Hash:      info.Hash,
+		Timestamp: modified,
+	})
+
+	action, err := s.write(ctx, dashboardResource, obj)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if change.Action != repository.FileActionRenamed {
+		result.Action = action
+	}
+	s.files[change.Path] = managedResource{resource: dashboardResource, name: obj.GetName()}
+	return result
+}
+
+// write creates or updates a resource. Resources which do not belong to the repository are not overwritten.
+func (s *syncer) write(ctx context.Context, resource schema.GroupVersionResource, obj *unstructured.Unstructured) (repository.FileAction, error) {
+	client := s.client.Resource(resource).Namespace(s.cfg.Namespace)
+	obj.SetNamespace(s.cfg.Namespace)
+
+	existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("create %s: %w", obj.GetName(), err)
+		}
+		return repository.FileActionCreated, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get %s: %w", obj.GetName(), err)
+	}
+
+	meta, err := utils.MetaAccessor(existing)
+	if err != nil {
+		return "", err
+	}
+	repo, err := meta.GetRepositoryInfo()
+	if err != nil {
+		return "", err
+	}
+	if repo == nil || repo.Name != s.cfg.Name {
+		return "", fmt.Errorf("%s %s already exists and is not managed by the repository", resource.Resource, obj.GetName())
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if _, err := client.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("update %s: %w", obj.GetName(), err)
+	}
+	return repository.FileActionUpdated, nil
+}
+
+// delete removes the resource of a file, and returns its name
+func (s *syncer) delete(ctx context.Context, p string) (string, error) {
+	existing, ok := s.files[p]
+	if !ok {
+		return "", nil
+	}
+	err := s.client.Resource(existing.resource).Namespace(s.cfg.Namespace).Delete(ctx, existing.name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existing.name, fmt.Errorf("delete %s: %w", existing.name, err)
+	}
+	delete(s.files, p)
+	return existing.name, nil
+}
+
+// ensureFolder writes the folder of a directory and its parents, and returns its name.
+// The root of the repository has no folder.
+func (s *syncer) ensureFolder(ctx context.Context, dir string) (string, error) {
+	if dir == "." || dir == "" {
+		return "", nil
+	}
+	if name, ok := s.synced[dir]; ok {
+		return name, nil
+	}
+
+	parent, err := s.ensureFolder(ctx, path.Dir(dir))
+	if err != nil {
+		return "", err
+	}
+
+	name := s.nameFor(dir)
+	if existing, ok := s.folders[dir]; ok {
+		name = existing.name
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": folders.APIVERSION,
+		"kind":       "Folder",
+		"metadata":   map[string]any{"name": name},
+		"spec":       map[string]any{"title": path.Base(dir)},
+	}}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return "", err
+	}
+	meta.SetFolder(parent)
+	meta.SetRepositoryInfo(&utils.ResourceRepositoryInfo{Name: s.cfg.Name, Path: dir})
+
+	if _, err := s.write(ctx, folderResource, obj); err != nil {
+		return "", err
+	}
+	s.synced[dir] = name
+	return name, nil
+}
+
+// deleteFolders removes the folders of the directories without dashboards, the deepest first
+func (s *syncer) deleteFolders(ctx context.Context) []JobResourceResult {
+	var dirs []string
+	for dir := range s.folders {
+		if _, ok := s.synced[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+
+	results := make([]JobResourceResult, 0, len(dirs))
+	for _, dir := range dirs {
+		result := JobResourceResult{
+			Name:     s.folders[dir].name,
+			Path:     dir,
+			Action:   repository.FileActionDeleted,
+			Group:    folderResource.Group,
+			Resource: folderResource.Resource,
+		}
+		err := s.client.Resource(folderResource).Namespace(s.cfg.Namespace).Delete(ctx, result.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			result.Error = fmt.Errorf("delete folder %s: %w", result.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// nameFor returns a stable name for the resource of a path without a name or uid
+func (s *syncer) nameFor(p string) string {
+	hash := sha256.Sum256([]byte(s.cfg.Name + "/" + p))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+// parseResource reads a dashboard from a JSON or YAML file.
+// The file is either a dashboard resource, or a dashboard JSON model as exported from the UI.
+func parseResource(data []byte) (*unstructured.Unstructured, error) {
+	value := map[string]any{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 1024).Decode(&value); err != nil {
+		return nil, errNotResource
+	}
+
+	obj := &unstructured.Unstructured{Object: value}
+	if obj.GetAPIVersion() != "" || obj.GetKind() != "" {
+		gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+		if err != nil {
+			return nil, fmt.Errorf("invalid apiVersion: %w", err)
+		}
+		if gv.Group != dashboardResource.Group || obj.GetKind() != "Dashboard" {
+			return nil, fmt.Errorf("unsupported resource %s %s", obj.GetAPIVersion(), obj.GetKind())
+		}
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": obj.GetAPIVersion(),
+			"kind":       obj.GetKind(),
+			"metadata":   map[string]any{"name": obj.GetName()},
+			"spec":       value["spec"],
+		}}, nil
+	}
+
+	// A dashboard JSON model
+	_, hasPanels := value["panels"]
+	_, hasSchemaVersion := value["schemaVersion"]
+	if !hasPanels && !hasSchemaVersion {
+		return nil, errNotResource
+	}
+	name, _ := value["uid"].(string)
+	delete(value, "id")
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": dashboard.APIVERSION,
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": name},
+		"spec":       value,
+	}}, nil
+}
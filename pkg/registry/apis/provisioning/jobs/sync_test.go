@@ -0,0 +1,239 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+// fakeFileRepo is a versioned repository with the files in memory
+type fakeFileRepo struct {
+	repository.Repository
+	cfg     *provisioning.Repository
+	ref     string
+	files   map[string]string
+	changes []repository.VersionedFileChange
+}
+
+func (f *fakeFileRepo) Config() *provisioning.Repository {
+	return f.cfg
+}
+
+func (f *fakeFileRepo) Read(ctx context.Context, path, ref string) (*repository.FileInfo, error) {
+	data, ok := f.files[path]
+	if !ok {
+		return nil, repository.ErrFileNotFound
+	}
+	return &repository.FileInfo{Path: path, Ref: ref, Data: []byte(data), Hash: hash(data)}, nil
+}
+
+func (f *fakeFileRepo) ReadTree(ctx context.Context, ref string) ([]repository.FileTreeEntry, error) {
+	entries := make([]repository.FileTreeEntry, 0, len(f.files))
+	for path, data := range f.files {
+		entries = append(entries, repository.FileTreeEntry{Path: path, Hash: hash(data), Blob: true})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+func (f *fakeFileRepo) History(ctx context.Context, path, ref string) ([]provisioning.HistoryItem, error) {
+	return nil, nil
+}
+
+func (f *fakeFileRepo) LatestRef(ctx context.Context) (string, error) {
+	return f.ref, nil
+}
+
+func (f *fakeFileRepo) CompareFiles(ctx context.Context, base, ref string) ([]repository.VersionedFileChange, error) {
+	return f.changes, nil
+}
+
+type fakeStatusUpdater struct {
+	statuses []provisioning.SyncStatus
+}
+
+func (f *fakeStatusUpdater) UpdateSyncStatus(ctx context.Context, namespace, name string, status provisioning.SyncStatus) error {
+	f.statuses = append(f.statuses, status)
+	return nil
+}
+
+func (f *fakeStatusUpdater) last() provisioning.SyncStatus {
+	return f.statuses[len(f.statuses)-1]
+}
+
+func hash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func newFakeResourceClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		dashboardResource: "DashboardList",
+		folderResource:    "FolderList",
+	}, objects...)
+}
+
+func listResources(t *testing.T, client *dynamicfake.FakeDynamicClient, resource schema.GroupVersionResource) map[string]utils.GrafanaMetaAccessor {
+	t.Helper()
+	list, err := client.Resource(resource).Namespace("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	result := make(map[string]utils.GrafanaMetaAccessor, len(list.Items))
+	for i := range list.Items {
+		obj, err := utils.MetaAccessor(&list.Items[i])
+		require.NoError(t, err)
+		result[obj.GetName()] = obj
+	}
+	return result
+}
+
+func TestSyncWorker(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func(files map[string]string) *fakeFileRepo {
+		return &fakeFileRepo{
+			cfg: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"},
+			},
+			ref:   "v1",
+			files: files,
+		}
+	}
+	syncJob := provisioning.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo:sync:1"},
+		Spec:       provisioning.JobSpec{Action: provisioning.JobActionSync, Repository: "repo", Sync: &provisioning.SyncJobOptions{}},
+	}
+	process := func(worker *SyncWorker, repo *fakeFileRepo, job provisioning.Job) (provisioning.JobStatus, error) {
+		require.True(t, worker.IsSupported(ctx, job))
+		progress := newJobProgressRecorder(func(ctx context.Context, status provisioning.JobStatus) error { return nil })
+		err := worker.Process(ctx, repo, job, progress)
+		return progress.Complete(ctx, err), err
+	}
+
+	client := newFakeResourceClient()
+	status := &fakeStatusUpdater{}
+	worker := NewSyncWorker(client, status)
+	repo := newRepo(map[string]string{
+		"README.md":                      "# Dashboards",
+		"overview.json":                  `{"title":"Overview","panels":[]}`,
+		"dashboards/a.json":              `{"id":12,"uid":"a","title":"A","panels":[]}`,
+		"dashboards/team/b.yaml":         "apiVersion: dashboard.grafana.app/v1alpha1\nkind: Dashboard\nmetadata:\n  name: b\nspec:\n  title: B\n",
+		"dashboards/team/notes/todo.txt": "not a dashboard",
+	})
+	s := &syncer{cfg: repo.cfg}
+
+	t.Run("should import the files", func(t *testing.T) {
+		jobStatus, err := process(worker, repo, syncJob)
+		require.NoError(t, err)
+		require.Equal(t, provisioning.JobStateSuccess, jobStatus.State)
+
+		require.Equal(t, provisioning.JobStateWorking, status.statuses[0].State)
+		require.Equal(t, provisioning.JobStateSuccess, status.last().State)
+		require.Equal(t, "v1", status.last().LastRef)
+		require.Equal(t, "repo:sync:1", status.last().JobID)
+
+		dashboards := listResources(t, client, dashboardResource)
+		require.Len(t, dashboards, 3)
+		require.Equal(t, s.nameFor("dashboards"), dashboards["a"].GetFolder())
+		require.Equal(t, s.nameFor("dashboards/team"), dashboards["b"].GetFolder())
+		overview := dashboards[s.nameFor("overview.json")]
+		require.NotNil(t, overview)
+		require.Empty(t, overview.GetFolder())
+
+		info, err := dashboards["a"].GetRepositoryInfo()
+		require.NoError(t, err)
+		require.Equal(t, &utils.ResourceRepositoryInfo{Name: "repo", Path: "dashboards/a.json", Hash: hash(repo.files["dashboards/a.json"])}, info)
+		spec, err := dashboards["a"].GetSpec()
+		require.NoError(t, err)
+		require.Equal(t, "A", spec.(map[string]any)["title"])
+		require.NotContains(t, spec, "id")
+
+		folders := listResources(t, client, folderResource)
+		require.Len(t, folders, 2)
+		require.Empty(t, folders[s.nameFor("dashboards")].GetFolder())
+		require.Equal(t, s.nameFor("dashboards"), folders[s.nameFor("dashboards/team")].GetFolder())
+	})
+
+	t.Run("should remove the resources of deleted files", func(t *testing.T) {
+		delete(repo.files, "dashboards/team/b.yaml")
+		repo.files["dashboards/a.json"] = `{"uid":"a","title":"A v2","panels":[]}`
+		repo.ref = "v2"
+
+		_, err := process(worker, repo, syncJob)
+		require.NoError(t, err)
+		require.Equal(t, "v2", status.last().LastRef)
+
+		dashboards := listResources(t, client, dashboardResource)
+		require.Len(t, dashboards, 2)
+		require.Contains(t, dashboards, "a")
+		require.NotContains(t, dashboards, "b")
+		spec, err := dashboards["a"].GetSpec()
+		require.NoError(t, err)
+		require.Equal(t, "A v2", spec.(map[string]any)["title"])
+
+		folders := listResources(t, client, folderResource)
+		require.Len(t, folders, 1)
+		require.Contains(t, folders, s.nameFor("dashboards"))
+	})
+
+	t.Run("should only import the changes of incremental syncs", func(t *testing.T) {
+		repo.cfg.Status.Sync.LastRef = "v2"
+		repo.ref = "v3"
+		repo.files["dashboards/a.json"] = `{"uid":"a","title":"A v3","panels":[]}`
+		repo.files["overview.json"] = `{"title":"Changed but not compared","panels":[]}`
+		repo.changes = []repository.VersionedFileChange{{Action: repository.FileActionUpdated, Path: "dashboards/a.json", Ref: "v3"}}
+		job := *syncJob.DeepCopy()
+		job.Spec.Sync.Incremental = true
+
+		jobStatus, err := process(worker, repo, job)
+		require.NoError(t, err)
+		require.Equal(t, "v3", status.last().LastRef)
+		require.True(t, status.last().Incremental)
+		require.Len(t, jobStatus.Summary, 1)
+		require.Equal(t, int64(1), jobStatus.Summary[0].Update)
+
+		dashboards := listResources(t, client, dashboardResource)
+		spec, err := dashboards["a"].GetSpec()
+		require.NoError(t, err)
+		require.Equal(t, "A v3", spec.(map[string]any)["title"])
+		spec, err = dashboards[s.nameFor("overview.json")].GetSpec()
+		require.NoError(t, err)
+		require.Equal(t, "Overview", spec.(map[string]any)["title"])
+	})
+
+	t.Run("should not overwrite resources of other sources", func(t *testing.T) {
+		existing := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": dashboardResource.GroupVersion().String(),
+			"kind":       "Dashboard",
+			"metadata":   map[string]any{"name": "a", "namespace": "default"},
+			"spec":       map[string]any{"title": "Created in the UI"},
+		}}
+		client := newFakeResourceClient(existing)
+		status := &fakeStatusUpdater{}
+		repo := newRepo(map[string]string{"a.json": `{"uid":"a","title":"A","panels":[]}`})
+
+		jobStatus, err := process(NewSyncWorker(client, status), repo, syncJob)
+		require.Error(t, err)
+		require.Equal(t, provisioning.JobStateError, jobStatus.State)
+		require.Contains(t, jobStatus.Errors[0], "not managed by the repository")
+		require.Equal(t, provisioning.JobStateError, status.last().State)
+		require.Empty(t, status.last().LastRef, "the ref is only recorded once all files are imported")
+
+		spec, err := listResources(t, client, dashboardResource)["a"].GetSpec()
+		require.NoError(t, err)
+		require.Equal(t, "Created in the UI", spec.(map[string]any)["title"])
+	})
+}
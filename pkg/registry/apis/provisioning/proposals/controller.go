@@ -0,0 +1,239 @@
+package proposals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+// maxDiffSize is the size of a file above which its changes are not compared.
+const maxDiffSize = 64 * 1024
+
+type ProposalStore interface {
+	// ListProposals returns the change proposals of all namespaces
+	ListProposals(ctx context.Context) ([]provisioning.ChangeProposal, error)
+
+	// UpdateProposalStatus saves the status of the proposal
+	UpdateProposalStatus(ctx context.Context, proposal *provisioning.ChangeProposal) error
+}
+
+// Controller opens a change request for each change proposal, and keeps the status of the proposal up to date.
+// The changes are only merged into the target branch when the proposal asks for it and a reviewer approved them.
+type Controller struct {
+	store  ProposalStore
+	getter jobs.RepoGetter
+
+	// How often the proposals are reconciled.
+	// Each open proposal calls the API of the server, so this should not be too often.
+	tick time.Duration
+}
+
+func NewController(store ProposalStore, getter jobs.RepoGetter) *Controller {
+	return &Controller{
+		store:  store,
+		getter: getter,
+		tick:   30 * time.Second,
+	}
+}
+
+// Run reconciles the proposals until the context is cancelled
+func (c *Controller) Run(ctx context.Context) {
+	logger := logging.DefaultLogger.With("logger", "change-proposals")
+	ctx = logging.Context(ctx, logger)
+
+	ticker := time.NewTicker(c.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.poll(ctx)
+		}
+	}
+}
+
+func (c *Controller) poll(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
+	proposals, err := c.store.ListProposals(ctx)
+	if err != nil {
+		logger.Warn("error listing change proposals", "err", err)
+		return
+	}
+
+	for i := range proposals {
+		proposal := &proposals[i]
+		if proposal.Status.State.Finished() {
+			continue
+		}
+
+		logger := logger.With("proposal", proposal.Name, "namespace", proposal.Namespace)
+		status, err := c.reconcile(ctx, proposal)
+		if err != nil {
+			logger.Warn("error reconciling change proposal", "err", err)
+			status.State = provisioning.ChangeProposalStateError
+			status.Message = []string{err.Error()}
+		}
+
+		if equality.Semantic.DeepEqual(proposal.Status, status) {
+			continue
+		}
+		proposal.Status = status
+		if err := c.store.UpdateProposalStatus(ctx, proposal); err != nil {
+			logger.Warn("error updating change proposal status", "err", err)
+		}
+	}
+}
+
+// reconcile returns the new status of the proposal.
+// On errors, the status holds what is known so far (e.g. the change request that was opened).
+func (c *Controller) reconcile(ctx context.Context, proposal *provisioning.ChangeProposal) (provisioning.ChangeProposalStatus, error) {
+	status := *proposal.Status.DeepCopy()
+	status.ObservedGeneration = proposal.Generation
+	status.Message = nil
+
+	ctx = request.WithNamespace(ctx, proposal.Namespace)
+	ctx, _, err := identity.WithProvisioningIdentitiy(ctx, proposal.Namespace)
+	if err != nil {
+		return status, fmt.Errorf("get proposal identity: %w", err)
+	}
+
+	repo, err := c.getter.GetRepository(ctx, proposal.Spec.Repository)
+	if err != nil {
+		return status, fmt.Errorf("get repository: %w", err)
+	}
+	requester, ok := repo.(repository.ChangeRequester)
+	if !ok {
+		return status, errors.New("the repository does not support change requests")
+	}
+
+	var cr *repository.ChangeRequest
+	if status.Number == 0 {
+		cr, err = requester.OpenChangeRequest(ctx, repository.ChangeRequestOptions{
+			Branch:      proposal.Spec.Branch,
+			Target:      proposal.Spec.Target,
+			Title:       proposal.Spec.Title,
+			Description: proposal.Spec.Description,
+		})
+	} else {
+		cr, err = requester.GetChangeRequest(ctx, status.Number)
+	}
+	if err != nil {
+		return status, err
+	}
+	status.Number = cr.Number
+	status.URL = cr.URL
+	status.Approved = cr.Approved
+	status.State = provisioning.ChangeProposalState(cr.State)
+
+	// The files are kept as they were once the change request is done, as the branch may be deleted.
+	if cr.State != repository.ChangeRequestStateOpen {
+		return status, nil
+	}
+
+	changes, err := requester.CompareFiles(ctx, cr.Target, proposal.Spec.Branch)
+	if err != nil {
+		return status, fmt.Errorf("compare files: %w", err)
+	}
+	status.Files = make([]provisioning.ChangeProposalFile, 0, len(changes))
+	for _, change := range changes {
+		if change.Action == repository.FileActionIgnored {
+			continue
+		}
+		file := provisioning.ChangeProposalFile{
+			Path:         change.Path,
+			Action:       string(change.Action),
+			PreviousPath: change.PreviousPath,
+		}
+		if reader, ok := repo.(repository.Reader); ok {
+			file.Diff, err = diffFile(ctx, reader, change)
+			if err != nil {
+				return status, fmt.Errorf("compare %s: %w", change.Path, err)
+			}
+		}
+		status.Files = append(status.Files, file)
+	}
+
+	if !proposal.Spec.Merge {
+		return status, nil
+	}
+	if !cr.Approved {
+		status.Message = []string{"waiting for approval"}
+		return status, nil
+	}
+	// Only the approved commit is merged, so that changes pushed after the review are reviewed again.
+	err = requester.MergeChangeRequest(ctx, cr.Number, cr.Head, proposal.Spec.Title)
+	if errors.Is(err, repository.ErrChangeRequestStale) {
+		status.Approved = false
+		status.Message = []string{"the branch changed after it was approved, waiting for a new approval"}
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	status.State = provisioning.ChangeProposalStateMerged
+	return status, nil
+}
+
+// diffFile returns the changes of a file in the unified diff format.
+// It returns an empty diff when either version of the file is larger than maxDiffSize.
+func diffFile(ctx context.Context, reader repository.Reader, change repository.VersionedFileChange) (string, error) {
+	previousPath := change.Path
+	if change.PreviousPath != "" {
+		previousPath = change.PreviousPath
+	}
+
+	var before, after []byte
+	if change.Action != repository.FileActionCreated {
+		info, err := reader.Read(ctx, previousPath, change.PreviousRef)
+		if err != nil {
+			return "", err
+		}
+		before = info.Data
+	}
+	if change.Action != repository.FileActionDeleted {
+		info, err := reader.Read(ctx, change.Path, change.Ref)
+		if err != nil {
+			return "", err
+		}
+		after = info.Data
+	}
+	if len(before) > maxDiffSize || len(after) > maxDiffSize {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(before),
+		B:        splitLines(after),
+		FromFile: "a/" + previousPath,
+		ToFile:   "b/" + change.Path,
+		Context:  3,
+	})
+}
+
+// splitLines splits the data into lines that all end with a newline, as the unified diff format expects.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return lines
+}
@@ -0,0 +1,200 @@
+package proposals
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+type fakeProposalStore struct {
+	proposals []provisioning.ChangeProposal
+	updates   int
+}
+
+func (f *fakeProposalStore) ListProposals(ctx context.Context) ([]provisioning.ChangeProposal, error) {
+	list := make([]provisioning.ChangeProposal, len(f.proposals))
+	for i := range f.proposals {
+		f.proposals[i].DeepCopyInto(&list[i])
+	}
+	return list, nil
+}
+
+func (f *fakeProposalStore) UpdateProposalStatus(ctx context.Context, proposal *provisioning.ChangeProposal) error {
+	f.updates++
+	for i := range f.proposals {
+		if f.proposals[i].Name == proposal.Name {
+			f.proposals[i].Status = proposal.Status
+		}
+	}
+	return nil
+}
+
+type fakeRepoGetter map[string]repository.Repository
+
+func (f fakeRepoGetter) GetRepository(ctx context.Context, name string) (repository.Repository, error) {
+	return f[name], nil
+}
+
+type fakeRequester struct {
+	repository.Repository
+	repository.Versioned
+
+	opened   []repository.ChangeRequestOptions
+	requests map[int64]*repository.ChangeRequest
+	merged   []int64
+	changes  []repository.VersionedFileChange
+	// The content of the files by ref and path
+	files map[string]string
+	// Set when the branch changed after it was approved
+	stale bool
+}
+
+func (f *fakeRequester) Read(ctx context.Context, path, ref string) (*repository.FileInfo, error) {
+	data, ok := f.files[ref+":"+path]
+	if !ok {
+		return nil, repository.ErrFileNotFound
+	}
+	return &repository.FileInfo{Path: path, Ref: ref, Data: []byte(data)}, nil
+}
+
+func (f *fakeRequester) ReadTree(ctx context.Context, ref string) ([]repository.FileTreeEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeRequester) OpenChangeRequest(ctx context.Context, opts repository.ChangeRequestOptions) (*repository.ChangeRequest, error) {
+	f.opened = append(f.opened, opts)
+	cr := &repository.ChangeRequest{
+		Number: int64(len(f.opened)),
+		URL:    "https://example.com/pull",
+		Target: "main",
+		State:  repository.ChangeRequestStateOpen,
+	}
+	f.requests[cr.Number] = cr
+	return cr, nil
+}
+
+func (f *fakeRequester) GetChangeRequest(ctx context.Context, number int64) (*repository.ChangeRequest, error) {
+	cr := *f.requests[number]
+	return &cr, nil
+}
+
+func (f *fakeRequester) MergeChangeRequest(ctx context.Context, number int64, head, message string) error {
+	if f.stale {
+		return repository.ErrChangeRequestStale
+	}
+	f.merged = append(f.merged, number)
+	f.requests[number].State = repository.ChangeRequestStateMerged
+	return nil
+}
+
+func (f *fakeRequester) CompareFiles(ctx context.Context, base, ref string) ([]repository.VersionedFileChange, error) {
+	return f.changes, nil
+}
+
+func TestController(t *testing.T) {
+	ctx := context.Background()
+	newProposal := func(name, repo string, merge bool) provisioning.ChangeProposal {
+		return provisioning.ChangeProposal{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: 1},
+			Spec: provisioning.ChangeProposalSpec{
+				Repository: repo,
+				Branch:     "changes-" + name,
+				Title:      "Update " + name,
+				Merge:      merge,
+			},
+		}
+	}
+
+	requester := &fakeRequester{
+		requests: map[int64]*repository.ChangeRequest{},
+		changes: []repository.VersionedFileChange{
+			{Action: repository.FileActionUpdated, Path: "dashboards/a.json", Ref: "head", PreviousRef: "base"},
+			{Action: repository.FileActionRenamed, Path: "dashboards/c.json", PreviousPath: "dashboards/b.json", Ref: "head", PreviousRef: "base"},
+			{Action: repository.FileActionIgnored, Path: "README.md"},
+		},
+		files: map[string]string{
+			"base:dashboards/a.json": "{\n  \"title\": \"A\"\n}\n",
+			"head:dashboards/a.json": "{\n  \"title\": \"A2\"\n}\n",
+			"base:dashboards/b.json": "{}\n",
+			"head:dashboards/c.json": "{}\n",
+		},
+	}
+	store := &fakeProposalStore{proposals: []provisioning.ChangeProposal{
+		newProposal("review", "github", false),
+		newProposal("merge", "github", true),
+		newProposal("local", "local", false),
+	}}
+	controller := NewController(store, fakeRepoGetter{
+		"github": requester,
+		"local":  &struct{ repository.Repository }{},
+	})
+
+	controller.poll(ctx)
+	require.Len(t, requester.opened, 2)
+	require.Equal(t, repository.ChangeRequestOptions{Branch: "changes-review", Title: "Update review"}, requester.opened[0])
+
+	review := store.proposals[0].Status
+	require.Equal(t, provisioning.ChangeProposalStateOpen, review.State)
+	require.Equal(t, int64(1), review.Number)
+	require.Equal(t, int64(1), review.ObservedGeneration)
+	require.Equal(t, []provisioning.ChangeProposalFile{
+		{Path: "dashboards/a.json", Action: "updated", Diff: "--- a/dashboards/a.json\n+++ b/dashboards/a.json\n@@ -1,3 +1,3 @@\n {\n-  \"title\": \"A\"\n+  \"title\": \"A2\"\n }\n"},
+		{Path: "dashboards/c.json", Action: "renamed", PreviousPath: "dashboards/b.json"},
+	}, review.Files)
+
+	merge := store.proposals[1].Status
+	require.Equal(t, provisioning.ChangeProposalStateOpen, merge.State)
+	require.Equal(t, []string{"waiting for approval"}, merge.Message)
+
+	local := store.proposals[2].Status
+	require.Equal(t, provisioning.ChangeProposalStateError, local.State)
+	require.Equal(t, []string{"the repository does not support change requests"}, local.Message)
+
+	t.Run("should not update an unchanged status", func(t *testing.T) {
+		updates := store.updates
+		controller.poll(ctx)
+		require.Len(t, requester.opened, 2, "the change requests are opened once")
+		require.Equal(t, updates, store.updates)
+	})
+
+	t.Run("should not merge changes pushed after the approval", func(t *testing.T) {
+		requester.requests[2].Approved = true
+		requester.stale = true
+		controller.poll(ctx)
+		require.Empty(t, requester.merged)
+		require.False(t, store.proposals[1].Status.Approved)
+		require.Equal(t, provisioning.ChangeProposalStateOpen, store.proposals[1].Status.State)
+		require.Equal(t, []string{"the branch changed after it was approved, waiting for a new approval"}, store.proposals[1].Status.Message)
+		requester.stale = false
+	})
+
+	t.Run("should merge approved changes", func(t *testing.T) {
+		requester.requests[1].Approved = true
+		requester.requests[2].Approved = true
+		controller.poll(ctx)
+		require.Equal(t, []int64{2}, requester.merged, "only proposals that ask for it are merged")
+		require.True(t, store.proposals[0].Status.Approved)
+		require.Equal(t, provisioning.ChangeProposalStateOpen, store.proposals[0].Status.State)
+		require.Equal(t, provisioning.ChangeProposalStateMerged, store.proposals[1].Status.State)
+		require.Empty(t, store.proposals[1].Status.Message)
+	})
+
+	t.Run("should follow changes made on the server", func(t *testing.T) {
+		requester.requests[1].State = repository.ChangeRequestStateClosed
+		controller.poll(ctx)
+		require.Equal(t, provisioning.ChangeProposalStateClosed, store.proposals[0].Status.State)
+		require.Len(t, store.proposals[0].Status.Files, 2, "the files of a closed proposal are kept")
+
+		// Finished proposals are not reconciled anymore
+		updates := store.updates
+		requester.requests[1].State = repository.ChangeRequestStateOpen
+		controller.poll(ctx)
+		require.Equal(t, provisioning.ChangeProposalStateClosed, store.proposals[0].Status.State)
+		require.Equal(t, updates, store.updates)
+	})
+}
@@ -3,24 +3,32 @@ package provisioning
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
 	grafanaregistry "github.com/grafana/grafana/pkg/apiserver/registry/generic"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/proposals"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/secrets"
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	grafanasecrets "github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/spec3"
 )
@@ -35,8 +43,16 @@ var (
 
 type APIBuilder struct {
 	secrets secrets.Service
-	jobs    jobs.JobQueue
-	getter  rest.Getter
+	// Where git repositories are cloned to
+	clonesDir string
+
+	jobs             jobs.JobQueue
+	getter           rest.Getter
+	lister           rest.Lister
+	repositoryStatus rest.Updater
+
+	proposalLister rest.Lister
+	proposalStatus rest.Updater
 }
 
 // NewAPIBuilder creates an API builder.
@@ -44,9 +60,11 @@ type APIBuilder struct {
 // This means there are no hidden dependencies, and no use of e.g. *settings.Cfg.
 func NewAPIBuilder(
 	secrets secrets.Service,
+	clonesDir string,
 ) *APIBuilder {
 	return &APIBuilder{
-		secrets: secrets,
+		secrets:   secrets,
+		clonesDir: clonesDir,
 	}
 }
 
@@ -55,6 +73,7 @@ func NewAPIBuilder(
 func RegisterAPIService(
 	features featuremgmt.FeatureToggles,
 	apiregistration builder.APIRegistrar,
+	cfg *setting.Cfg,
 	secretsSvc grafanasecrets.Service,
 ) (*APIBuilder, error) {
 	if !features.IsEnabledGlobally(featuremgmt.FlagProvisioning) &&
@@ -62,7 +81,7 @@ func RegisterAPIService(
 		return nil, nil // skip registration unless opting into experimental apis OR the feature specifically
 	}
 
-	builder := NewAPIBuilder(secrets.NewSingleTenant(secretsSvc))
+	builder := NewAPIBuilder(secrets.NewSingleTenant(secretsSvc), filepath.Join(cfg.DataPath, "provisioning", "git"))
 	apiregistration.RegisterAPI(builder)
 	return builder, nil
 }
@@ -107,13 +126,27 @@ func (b *APIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupI
 	// FIXME: Make job queue store the jobs somewhere persistent.
 	jobStore := jobs.NewJobStore(50, b) // in memory, for now...
 	b.jobs = jobStore
+	b.getter = repositoryStorage
+	b.lister = repositoryStorage
 
 	repositoryStatusStorage := grafanaregistry.NewRegistryStatusStore(opts.Scheme, repositoryStorage)
+	b.repositoryStatus = repositoryStatusStorage
+
+	proposalStorage, err := grafanaregistry.NewRegistryStore(opts.Scheme, provisioning.ChangeProposalResourceInfo, opts.OptsGetter)
+	if err != nil {
+		return fmt.Errorf("failed to create change proposal storage: %w", err)
+	}
+	proposalStatusStorage := grafanaregistry.NewRegistryStatusStore(opts.Scheme, proposalStorage)
+	b.proposalLister = proposalStorage
+	b.proposalStatus = proposalStatusStorage
 
 	storage := map[string]rest.Storage{}
 	storage[provisioning.JobResourceInfo.StoragePath()] = jobStore
 	storage[provisioning.RepositoryResourceInfo.StoragePath()] = repositoryStorage
 	storage[provisioning.RepositoryResourceInfo.StoragePath("status")] = repositoryStatusStorage
+	storage[provisioning.RepositoryResourceInfo.StoragePath("propose")] = &subProposeREST{builder: b, proposals: proposalStorage}
+	storage[provisioning.ChangeProposalResourceInfo.StoragePath()] = proposalStorage
+	storage[provisioning.ChangeProposalResourceInfo.StoragePath("status")] = proposalStatusStorage
 	apiGroupInfo.VersionedResourcesStorageMap[provisioning.VERSION] = storage
 	return nil
 }
@@ -125,13 +158,51 @@ func (b *APIBuilder) Mutate(ctx context.Context, a admission.Attributes, o admis
 		return nil // This is normal for sub-resource
 	}
 
+	// Change proposals have nothing to mutate
+	if _, ok := obj.(*provisioning.ChangeProposal); ok {
+		return nil
+	}
+
 	r, ok := obj.(*provisioning.Repository)
 	if !ok {
 		return fmt.Errorf("expected repository configuration")
 	}
 
-	// TODO: Do something based on the resource we got.
-	_ = r
+	// Secrets are only stored encrypted
+	if r.Spec.GitHub != nil && r.Spec.GitHub.Token != "" {
+		encrypted, err := b.secrets.Encrypt(ctx, []byte(r.Spec.GitHub.Token))
+		if err != nil {
+			return fmt.Errorf("encrypt github token: %w", err)
+		}
+		r.Spec.GitHub.EncryptedToken = encrypted
+		r.Spec.GitHub.Token = ""
+	}
+	if r.Spec.Git != nil {
+		if r.Spec.Git.Token != "" {
+			encrypted, err := b.secrets.Encrypt(ctx, []byte(r.Spec.Git.Token))
+			if err != nil {
+				return fmt.Errorf("encrypt git token: %w", err)
+			}
+			r.Spec.Git.EncryptedToken = encrypted
+			r.Spec.Git.Token = ""
+		}
+		if r.Spec.Git.SSHKey != "" {
+			encrypted, err := b.secrets.Encrypt(ctx, []byte(r.Spec.Git.SSHKey))
+			if err != nil {
+				return fmt.Errorf("encrypt git ssh key: %w", err)
+			}
+			r.Spec.Git.EncryptedSSHKey = encrypted
+			r.Spec.Git.SSHKey = ""
+		}
+	}
+	if r.Spec.Bucket != nil && r.Spec.Bucket.SecretKey != "" {
+		encrypted, err := b.secrets.Encrypt(ctx, []byte(r.Spec.Bucket.SecretKey))
+		if err != nil {
+			return fmt.Errorf("encrypt bucket secret key: %w", err)
+		}
+		r.Spec.Bucket.EncryptedSecretKey = encrypted
+		r.Spec.Bucket.SecretKey = ""
+	}
 
 	return nil
 }
@@ -142,8 +213,26 @@ func (b *APIBuilder) Validate(ctx context.Context, a admission.Attributes, o adm
 		return nil // This is normal for sub-resource
 	}
 
+	if p, ok := obj.(*provisioning.ChangeProposal); ok {
+		return b.validateProposal(ctx, p)
+	}
+
+	r, ok := obj.(*provisioning.Repository)
+	if !ok {
+		return fmt.Errorf("expected repository configuration")
+	}
+
 	var list field.ErrorList
-	// TODO: Fill the list with validation errors.
+	switch r.Spec.Type {
+	case provisioning.GitHubRepositoryType, provisioning.GitRepositoryType, provisioning.BucketRepositoryType:
+		repo, err := b.AsRepository(ctx, r)
+		if err != nil {
+			return err
+		}
+		list = repo.Validate()
+	default:
+		// TODO: Validate the other repository types once they are implemented.
+	}
 
 	if len(list) > 0 {
 		return apierrors.NewInvalid(
@@ -153,6 +242,45 @@ func (b *APIBuilder) Validate(ctx context.Context, a admission.Attributes, o adm
 	return nil
 }
 
+// validateProposal checks that the changes can be proposed to the repository
+func (b *APIBuilder) validateProposal(ctx context.Context, p *provisioning.ChangeProposal) error {
+	var list field.ErrorList
+	if p.Spec.Branch == "" {
+		list = append(list, field.Required(field.NewPath("spec", "branch"), "a branch is required"))
+	} else if p.Spec.Branch == p.Spec.Target {
+		list = append(list, field.Invalid(field.NewPath("spec", "target"), p.Spec.Target, "the target must be another branch"))
+	}
+	if p.Spec.Title == "" {
+		list = append(list, field.Required(field.NewPath("spec", "title"), "a title is required"))
+	}
+
+	if p.Spec.Repository == "" {
+		list = append(list, field.Required(field.NewPath("spec", "repository"), "a repository is required"))
+	} else {
+		repo, err := b.GetRepository(ctx, p.Spec.Repository)
+		switch {
+		case apierrors.IsNotFound(err):
+			list = append(list, field.NotFound(field.NewPath("spec", "repository"), p.Spec.Repository))
+		case err != nil:
+			list = append(list, field.Invalid(field.NewPath("spec", "repository"), p.Spec.Repository, err.Error()))
+		default:
+			if _, ok := repo.(repository.ChangeRequester); !ok {
+				list = append(list, field.Invalid(field.NewPath("spec", "repository"), p.Spec.Repository, "the repository does not support change requests"))
+			}
+			if !slices.Contains(repo.Config().Spec.Workflows, provisioning.BranchWorkflow) {
+				list = append(list, field.Forbidden(field.NewPath("spec", "repository"), "the repository does not allow the branch workflow"))
+			}
+		}
+	}
+
+	if len(list) > 0 {
+		return apierrors.NewInvalid(
+			provisioning.ChangeProposalResourceInfo.GroupVersionKind().GroupKind(),
+			p.Name, list)
+	}
+	return nil
+}
+
 func (b *APIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
 	return provisioning.GetOpenAPIDefinitions
 }
@@ -161,6 +289,14 @@ func (b *APIBuilder) GetPostStartHooks() (map[string]genericapiserver.PostStartH
 	postStartHooks := map[string]genericapiserver.PostStartHookFunc{
 		"grafana-provisioning": func(postStartHookCtx genericapiserver.PostStartHookContext) error {
 			// TODO: Set up a shared informer for a controller and a watcher with workers.
+			client, err := dynamic.NewForConfig(postStartHookCtx.LoopbackClientConfig)
+			if err != nil {
+				return fmt.Errorf("create resource client: %w", err)
+			}
+			b.jobs.Register(jobs.NewSyncWorker(client, b))
+
+			go jobs.NewSyncPoller(b, b, b.jobs).Run(postStartHookCtx.Context)
+			go proposals.NewController(b, b).Run(postStartHookCtx.Context)
 			return nil
 		},
 	}
@@ -189,7 +325,85 @@ func (b *APIBuilder) GetRepository(ctx context.Context, name string) (repository
 		return nil, err
 	}
 
-	_ = obj
-	// FIXME: Return a valid Repository object with the correct underlying storage.
-	panic("FIXME")
+	r, ok := obj.(*provisioning.Repository)
+	if !ok {
+		return nil, fmt.Errorf("expected repository configuration")
+	}
+
+	return b.AsRepository(ctx, r)
+}
+
+// AsRepository returns the repository implementation for the type of the configuration.
+func (b *APIBuilder) AsRepository(ctx context.Context, r *provisioning.Repository) (repository.Repository, error) {
+	switch r.Spec.Type {
+	case provisioning.GitHubRepositoryType:
+		return repository.NewGitHub(r, b.secrets, b.clonesDir), nil
+	case provisioning.GitRepositoryType:
+		return repository.NewGit(r, b.secrets, b.clonesDir), nil
+	case provisioning.BucketRepositoryType:
+		return repository.NewBucket(r, b.secrets), nil
+	default:
+		return nil, fmt.Errorf("unsupported repository type: %s", r.Spec.Type)
+	}
+}
+
+// ListRepositories returns the repositories of all namespaces.
+// It runs as the service itself, as the repositories are not limited to one org.
+func (b *APIBuilder) ListRepositories(ctx context.Context) ([]provisioning.Repository, error) {
+	ctx = identity.WithServiceIdentityContext(ctx, 0)
+	obj, err := b.lister.List(ctx, &internalversion.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := obj.(*provisioning.RepositoryList)
+	if !ok {
+		return nil, fmt.Errorf("expected repository list")
+	}
+
+	return list.Items, nil
+}
+
+// ListProposals returns the change proposals of all namespaces.
+func (b *APIBuilder) ListProposals(ctx context.Context) ([]provisioning.ChangeProposal, error) {
+	ctx = identity.WithServiceIdentityContext(ctx, 0)
+	obj, err := b.proposalLister.List(ctx, &internalversion.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := obj.(*provisioning.ChangeProposalList)
+	if !ok {
+		return nil, fmt.Errorf("expected change proposal list")
+	}
+
+	return list.Items, nil
+}
+
+// UpdateSyncStatus saves the sync status of a repository.
+// The status is set on the latest version of the repository, so that it does not conflict with spec changes.
+func (b *APIBuilder) UpdateSyncStatus(ctx context.Context, namespace, name string, status provisioning.SyncStatus) error {
+	ctx = identity.WithServiceIdentityContext(ctx, 0)
+	ctx = request.WithNamespace(ctx, namespace)
+	updated := rest.DefaultUpdatedObjectInfo(nil, func(ctx context.Context, newObj, oldObj runtime.Object) (runtime.Object, error) {
+		r, ok := oldObj.(*provisioning.Repository)
+		if !ok {
+			return nil, fmt.Errorf("expected repository configuration")
+		}
+		r = r.DeepCopy()
+		r.Status.Sync = status
+		return r, nil
+	})
+	_, _, err := b.repositoryStatus.Update(ctx, name, updated,
+		rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
+	return err
+}
+
+// UpdateProposalStatus saves the status of a change proposal.
+func (b *APIBuilder) UpdateProposalStatus(ctx context.Context, p *provisioning.ChangeProposal) error {
+	ctx = identity.WithServiceIdentityContext(ctx, 0)
+	ctx = request.WithNamespace(ctx, p.Namespace)
+	_, _, err := b.proposalStatus.Update(ctx, p.Name, rest.DefaultUpdatedObjectInfo(p),
+		rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
+	return err
 }
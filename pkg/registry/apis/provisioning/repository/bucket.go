@@ -0,0 +1,566 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcerrors"
+	"gocloud.dev/gcp"
+	"golang.org/x/oauth2/google"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/secrets"
+)
+
+var (
+	_ Repository = (*bucketRepository)(nil)
+	_ Reader     = (*bucketRepository)(nil)
+	_ Writer     = (*bucketRepository)(nil)
+)
+
+var errBucketRef = errors.New("bucket repositories do not support refs")
+
+const defaultS3Region = "us-east-1"
+
+// bucketSchemes are the URL schemes of the supported bucket drivers.
+// Local directories are not supported, as they would expose the files of the server.
+var bucketSchemes = []string{"s3", "gs", "azblob"}
+
+// s3Params are the query parameters of S3 URLs. The others (e.g. `profile`) would read the configuration of the server.
+var s3Params = []string{"region", "endpoint", "use_path_style"}
+
+// azureAccountName matches the names of storage accounts, which are used in the host of the account.
+var azureAccountName = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+// bucketRepository is a repository in an object storage bucket. The files are the objects with the configured prefix.
+// Buckets are not versioned, so changes are detected with the hashes of the objects instead of commits.
+type bucketRepository struct {
+	config  *provisioning.Repository
+	secrets secrets.Service
+
+	// openBucket opens the bucket with the credentials of the repository. Tests replace it with a local directory.
+	openBucket func(ctx context.Context) (*blob.Bucket, error)
+}
+
+// NewBucket creates a repository backed by an object storage bucket.
+// The bucket is opened for every operation, with the credentials of the repository.
+func NewBucket(config *provisioning.Repository, secrets secrets.Service) *bucketRepository {
+	r := &bucketRepository{
+		config:  config,
+		secrets: secrets,
+	}
+	r.openBucket = r.openWithCredentials
+	return r
+}
+
+func (r *bucketRepository) Config() *provisioning.Repository {
+	return r.config
+}
+
+// Validate implements Repository.
+func (r *bucketRepository) Validate() (list field.ErrorList) {
+	cfg := r.config.Spec.Bucket
+	if cfg == nil {
+		list = append(list, field.Required(field.NewPath("spec", "bucket"), "a bucket config is required"))
+		return list
+	}
+
+	scheme := ""
+	if cfg.URL == "" {
+		list = append(list, field.Required(field.NewPath("spec", "bucket", "url"), "a bucket url is required"))
+	} else {
+		u, err := url.Parse(cfg.URL)
+		switch {
+		case err != nil:
+			list = append(list, field.Invalid(field.NewPath("spec", "bucket", "url"), cfg.URL, "unable to parse the url"))
+		case !isBucketScheme(u.Scheme):
+			list = append(list, field.Invalid(field.NewPath("spec", "bucket", "url"), cfg.URL, "the url must use one of "+strings.Join(bucketSchemes, ", ")))
+		case u.Host == "":
+			list = append(list, field.Invalid(field.NewPath("spec", "bucket", "url"), cfg.URL, "the url must contain the bucket name"))
+		default:
+			scheme = u.Scheme
+			for param := range u.Query() {
+				if u.Scheme != "s3" || !isS3Param(param) {
+					list = append(list, field.Invalid(field.NewPath("spec", "bucket", "url"), cfg.URL, "unsupported url parameter: "+param))
+				}
+			}
+		}
+	}
+
+	if cfg.Prefix != "" {
+		p, err := cleanPath(cfg.Prefix)
+		if err != nil || p != strings.Trim(cfg.Prefix, "/") {
+			list = append(list, field.Invalid(field.NewPath("spec", "bucket", "prefix"), cfg.Prefix, "the prefix must be a clean path"))
+		}
+	}
+
+	hasSecretKey := cfg.SecretKey != "" || len(cfg.EncryptedSecretKey) > 0
+	if scheme != "" && !hasSecretKey {
+		list = append(list, field.Required(field.NewPath("spec", "bucket", "secretKey"), "the credentials of the bucket are required"))
+	}
+	switch scheme {
+	case "s3":
+		if cfg.AccessKey == "" {
+			list = append(list, field.Required(field.NewPath("spec", "bucket", "accessKey"), "an access key ID is required"))
+		}
+	case "azblob":
+		if !azureAccountName.MatchString(cfg.AccessKey) {
+			list = append(list, field.Invalid(field.NewPath("spec", "bucket", "accessKey"), cfg.AccessKey, "a storage account name is required"))
+		}
+	case "gs":
+		if cfg.AccessKey != "" {
+			list = append(list, field.Forbidden(field.NewPath("spec", "bucket", "accessKey"), "GCS buckets use a service account key"))
+		}
+	}
+
+	return list
+}
+
+// Test implements Repository.
+func (r *bucketRepository) Test(ctx context.Context) (*provisioning.TestResults, error) {
+	b, err := r.open(ctx)
+	if err == nil {
+		defer func() { _ = b.Close() }()
+		_, _, err = b.ListPage(ctx, blob.FirstPageToken, 1, &blob.ListOptions{Prefix: r.prefix()})
+	}
+	if err != nil {
+		return &provisioning.TestResults{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Errors:  []string{err.Error()},
+		}, nil
+	}
+	return &provisioning.TestResults{
+		Code:    http.StatusOK,
+		Success: true,
+	}, nil
+}
+
+// Read implements Reader.
+// Reading a directory returns its path without data.
+func (r *bucketRepository) Read(ctx context.Context, filePath, ref string) (*FileInfo, error) {
+	if ref != "" {
+		return nil, errBucketRef
+	}
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = b.Close() }()
+
+	info := &FileInfo{Path: p}
+	if p == "" {
+		return info, nil
+	}
+
+	key := r.key(p)
+	attrs, err := b.Attributes(ctx, key)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		isDir, err := r.hasObjects(ctx, b, key+"/")
+		if err != nil {
+			return nil, err
+		}
+		if !isDir {
+			return nil, ErrFileNotFound
+		}
+		return info, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read attributes: %w", err)
+	}
+
+	info.Data, err = b.ReadAll(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	info.Hash = objectHash(attrs.MD5, attrs.ETag)
+	if !attrs.ModTime.IsZero() {
+		modified := metav1.NewTime(attrs.ModTime)
+		info.Modified = &modified
+	}
+	return info, nil
+}
+
+// ReadTree implements Reader.
+// The hash of an entry changes whenever the object is written, so it can be compared with the hash of a previous sync.
+func (r *bucketRepository) ReadTree(ctx context.Context, ref string) ([]FileTreeEntry, error) {
+	if ref != "" {
+		return nil, errBucketRef
+	}
+
+	b, err := r.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = b.Close() }()
+
+	prefix := r.prefix()
+	dirs := make(map[string]bool)
+	var entries []FileTreeEntry
+	addDirs := func(p string) {
+		for dir := p; ; {
+			idx := strings.LastIndex(dir, "/")
+			if idx < 0 {
+				return
+			}
+			dir = dir[:idx]
+			if dirs[dir] {
+				return
+			}
+			dirs[dir] = true
+			entries = append(entries, FileTreeEntry{Path: dir})
+		}
+	}
+
+	iter := b.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list objects: %w", err)
+		}
+
+		rel := strings.TrimPrefix(obj.Key, prefix)
+		p, err := cleanPath(rel)
+		if err != nil || p == "" || p != strings.TrimSuffix(rel, "/") {
+			continue // not addressable as a path
+		}
+		if strings.HasSuffix(rel, "/") {
+			// A directory marker, as created by some consoles
+			addDirs(p + "/")
+			continue
+		}
+
+		hash := objectHash(obj.MD5, "")
+		if hash == "" {
+			// Not all drivers list the MD5, but all of them return an ETag
+			attrs, err := b.Attributes(ctx, obj.Key)
+			if err != nil {
+				return nil, fmt.Errorf("read attributes: %w", err)
+			}
+			hash = objectHash(attrs.MD5, attrs.ETag)
+		}
+
+		addDirs(p)
+		entries = append(entries, FileTreeEntry{
+			Path: p,
+			Hash: hash,
+			Size: obj.Size,
+			Blob: true,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+// LatestRef returns a hash of the paths and hashes of all files.
+// It changes whenever a file is created, updated or deleted, which is used to poll the bucket for changes.
+func (r *bucketRepository) LatestRef(ctx context.Context) (string, error) {
+	entries, err := r.ReadTree(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, entry := range entries {
+		if entry.Blob {
+			_, _ = fmt.Fprintf(h, "%s\x00%s\n", entry.Path, entry.Hash)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Create implements Writer.
+// Buckets do not keep a history, so the message is not stored.
+func (r *bucketRepository) Create(ctx context.Context, filePath, ref string, data []byte, message string) error {
+	return r.write(ctx, filePath, ref, data, func(exists bool) error {
+		if exists {
+			return ErrFileAlreadyExists
+		}
+		return nil
+	})
+}
+
+// Update implements Writer.
+func (r *bucketRepository) Update(ctx context.Context, filePath, ref string, data []byte, message string) error {
+	if strings.HasSuffix(filePath, "/") {
+		return fmt.Errorf("cannot update a directory")
+	}
+	return r.write(ctx, filePath, ref, data, func(exists bool) error {
+		if !exists {
+			return ErrFileNotFound
+		}
+		return nil
+	})
+}
+
+// Write implements Writer.
+func (r *bucketRepository) Write(ctx context.Context, filePath, ref string, data []byte, message string) error {
+	return r.write(ctx, filePath, ref, data, func(bool) error { return nil })
+}
+
+func (r *bucketRepository) write(ctx context.Context, filePath, ref string, data []byte, check func(exists bool) error) error {
+	if ref != "" {
+		return errBucketRef
+	}
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	if p == "" {
+		return fmt.Errorf("a path is required")
+	}
+	if strings.HasSuffix(filePath, "/") {
+		// Buckets do not have directories, so a placeholder object is created instead.
+		p += "/" + keepFile
+		data = nil
+	}
+
+	b, err := r.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = b.Close() }()
+
+	key := r.key(p)
+	exists, err := b.Exists(ctx, key)
+	if err != nil {
+		return fmt.Errorf("check file: %w", err)
+	}
+	if err := check(exists); err != nil {
+		return err
+	}
+	if err := b.WriteAll(ctx, key, data, nil); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+// Delete implements Writer.
+// Deleting a directory deletes all the objects in it.
+func (r *bucketRepository) Delete(ctx context.Context, filePath, ref, message string) error {
+	if ref != "" {
+		return errBucketRef
+	}
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	if p == "" {
+		return fmt.Errorf("a path is required")
+	}
+
+	b, err := r.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = b.Close() }()
+
+	key := r.key(p)
+	if !strings.HasSuffix(filePath, "/") {
+		exists, err := b.Exists(ctx, key)
+		if err != nil {
+			return fmt.Errorf("check file: %w", err)
+		}
+		if exists {
+			return b.Delete(ctx, key)
+		}
+	}
+
+	// The keys are listed first, as deleting while listing is not supported by all drivers.
+	var keys []string
+	iter := b.List(&blob.ListOptions{Prefix: key + "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("list objects: %w", err)
+		}
+		keys = append(keys, obj.Key)
+	}
+	if len(keys) == 0 {
+		return ErrFileNotFound
+	}
+	for _, k := range keys {
+		if err := b.Delete(ctx, k); err != nil {
+			return fmt.Errorf("delete %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func (r *bucketRepository) open(ctx context.Context) (*blob.Bucket, error) {
+	b, err := r.openBucket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open bucket: %w", err)
+	}
+	return b, nil
+}
+
+// openWithCredentials opens the bucket with the driver of the URL scheme.
+// The clients are created with the credentials of the repository, instead of the default credentials of the server.
+func (r *bucketRepository) openWithCredentials(ctx context.Context) (*blob.Bucket, error) {
+	cfg := r.config.Spec.Bucket
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey := []byte(cfg.SecretKey)
+	if len(secretKey) == 0 && len(cfg.EncryptedSecretKey) > 0 {
+		secretKey, err = r.secrets.Decrypt(ctx, cfg.EncryptedSecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt secret key: %w", err)
+		}
+	}
+	if len(secretKey) == 0 {
+		return nil, errors.New("the credentials of the bucket are required")
+	}
+
+	switch u.Scheme {
+	case "s3":
+		query := u.Query()
+		region := query.Get("region")
+		if region == "" {
+			region = defaultS3Region
+		}
+		config := &aws.Config{
+			Credentials: credentials.NewStaticCredentials(cfg.AccessKey, string(secretKey), ""),
+			Region:      aws.String(region),
+		}
+		if endpoint := query.Get("endpoint"); endpoint != "" {
+			config.Endpoint = aws.String(endpoint)
+		}
+		if pathStyle, err := strconv.ParseBool(query.Get("use_path_style")); err == nil {
+			config.S3ForcePathStyle = aws.Bool(pathStyle)
+		}
+		sess, err := session.NewSession(config)
+		if err != nil {
+			return nil, err
+		}
+		return s3blob.OpenBucket(ctx, sess, u.Host, nil)
+
+	case "gs":
+		// Only service account keys are accepted, as other credential types can read files or URLs of the server.
+		var key struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(secretKey, &key); err != nil || key.Type != "service_account" {
+			return nil, errors.New("the secret key must be the JSON key of a service account")
+		}
+		creds, err := google.CredentialsFromJSON(ctx, secretKey, storage.ScopeReadWrite)
+		if err != nil {
+			return nil, err
+		}
+		client, err := gcp.NewHTTPClient(gcp.DefaultTransport(), creds.TokenSource)
+		if err != nil {
+			return nil, err
+		}
+		return gcsblob.OpenBucket(ctx, client, u.Host, nil)
+
+	case "azblob":
+		if !azureAccountName.MatchString(cfg.AccessKey) {
+			return nil, fmt.Errorf("invalid storage account name: %s", cfg.AccessKey)
+		}
+		cred, err := container.NewSharedKeyCredential(cfg.AccessKey, string(secretKey))
+		if err != nil {
+			return nil, err
+		}
+		containerURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s", cfg.AccessKey, url.PathEscape(u.Host))
+		client, err := container.NewClientWithSharedKeyCredential(containerURL, cred, nil)
+		if err != nil {
+			return nil, err
+		}
+		return azureblob.OpenBucket(ctx, client, nil)
+
+	default:
+		return nil, fmt.Errorf("unsupported bucket url scheme: %s", u.Scheme)
+	}
+}
+
+// prefix returns the prefix of the keys, with a trailing slash unless it is empty.
+func (r *bucketRepository) prefix() string {
+	p := strings.Trim(r.config.Spec.Bucket.Prefix, "/")
+	if p == "" {
+		return ""
+	}
+	return p + "/"
+}
+
+func (r *bucketRepository) key(p string) string {
+	return r.prefix() + p
+}
+
+func (r *bucketRepository) hasObjects(ctx context.Context, b *blob.Bucket, prefix string) (bool, error) {
+	found, _, err := b.ListPage(ctx, blob.FirstPageToken, 1, &blob.ListOptions{Prefix: prefix})
+	if err != nil {
+		return false, fmt.Errorf("list objects: %w", err)
+	}
+	return len(found) > 0, nil
+}
+
+// objectHash returns the hash used to detect changes of an object. Most providers return the MD5 of the content
+// (for S3, it is the ETag of objects uploaded in a single part). Otherwise, the ETag is hashed.
+// It returns an empty string if neither is known.
+func objectHash(md5 []byte, etag string) string {
+	if len(md5) > 0 {
+		return hex.EncodeToString(md5)
+	}
+	if etag == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(etag))
+	return hex.EncodeToString(sum[:])
+}
+
+func isBucketScheme(scheme string) bool {
+	for _, s := range bucketSchemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func isS3Param(param string) bool {
+	for _, p := range s3Params {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+)
+
+func newTestBucketRepository(t *testing.T, prefix string) (*bucketRepository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo := NewBucket(&provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: provisioning.RepositorySpec{
+			Type: provisioning.BucketRepositoryType,
+			Bucket: &provisioning.BucketRepositoryConfig{
+				URL:    "s3://test",
+				Prefix: prefix,
+			},
+		},
+	}, nil)
+	repo.openBucket = func(ctx context.Context) (*blob.Bucket, error) {
+		return fileblob.OpenBucket(dir, nil)
+	}
+	return repo, dir
+}
+
+func TestBucketRepository(t *testing.T) {
+	ctx := context.Background()
+	repo, dir := newTestBucketRepository(t, "grafana/")
+
+	// An object outside of the prefix is not part of the repository
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{}`), 0o600))
+
+	empty, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Create(ctx, "dashboards/a.json", "", []byte(`{"a":1}`), "add a"))
+	require.NoError(t, repo.Create(ctx, "dashboards/nested/b.json", "", []byte(`{"b":1}`), "add b"))
+	require.FileExists(t, filepath.Join(dir, "grafana", "dashboards", "a.json"))
+
+	first, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, empty, first)
+
+	t.Run("should read files", func(t *testing.T) {
+		info, err := repo.Read(ctx, "dashboards/a.json", "")
+		require.NoError(t, err)
+		require.Equal(t, "dashboards/a.json", info.Path)
+		require.Equal(t, []byte(`{"a":1}`), info.Data)
+		require.NotEmpty(t, info.Hash)
+		require.NotNil(t, info.Modified)
+
+		info, err = repo.Read(ctx, "dashboards/", "")
+		require.NoError(t, err)
+		require.Nil(t, info.Data)
+
+		_, err = repo.Read(ctx, "dashboards/missing.json", "")
+		require.ErrorIs(t, err, ErrFileNotFound)
+		_, err = repo.Read(ctx, "other.json", "")
+		require.ErrorIs(t, err, ErrFileNotFound)
+		_, err = repo.Read(ctx, "dashboards/a.json", "main")
+		require.ErrorIs(t, err, errBucketRef)
+	})
+
+	t.Run("should not create existing files or update missing ones", func(t *testing.T) {
+		require.ErrorIs(t, repo.Create(ctx, "dashboards/a.json", "", []byte(`{}`), "add a"), ErrFileAlreadyExists)
+		require.ErrorIs(t, repo.Update(ctx, "dashboards/missing.json", "", []byte(`{}`), "update"), ErrFileNotFound)
+		require.ErrorIs(t, repo.Delete(ctx, "dashboards/missing.json", "", "delete"), ErrFileNotFound)
+	})
+
+	t.Run("should read the tree", func(t *testing.T) {
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		paths := make(map[string]bool, len(tree))
+		for _, entry := range tree {
+			paths[entry.Path] = entry.Blob
+		}
+		require.Equal(t, map[string]bool{
+			"dashboards":               false,
+			"dashboards/a.json":        true,
+			"dashboards/nested":        false,
+			"dashboards/nested/b.json": true,
+		}, paths)
+	})
+
+	t.Run("should detect changes", func(t *testing.T) {
+		before, err := repo.Read(ctx, "dashboards/a.json", "")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Update(ctx, "dashboards/a.json", "", []byte(`{"a":2}`), "update a"))
+		after, err := repo.Read(ctx, "dashboards/a.json", "")
+		require.NoError(t, err)
+		require.NotEqual(t, before.Hash, after.Hash)
+
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Contains(t, tree, FileTreeEntry{Path: "dashboards/a.json", Hash: after.Hash, Size: 7, Blob: true})
+
+		latest, err := repo.LatestRef(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, first, latest)
+
+		unchanged, err := repo.LatestRef(ctx)
+		require.NoError(t, err)
+		require.Equal(t, latest, unchanged)
+	})
+
+	t.Run("should create and delete folders", func(t *testing.T) {
+		require.NoError(t, repo.Create(ctx, "empty/", "", nil, "add folder"))
+		info, err := repo.Read(ctx, "empty/.keep", "")
+		require.NoError(t, err)
+		require.Empty(t, info.Data)
+
+		require.NoError(t, repo.Delete(ctx, "dashboards/", "", "delete dashboards"))
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, tree, 2)
+		require.Equal(t, "empty", tree[0].Path)
+		require.Equal(t, "empty/.keep", tree[1].Path)
+	})
+
+	t.Run("should test the connection", func(t *testing.T) {
+		results, err := repo.Test(ctx)
+		require.NoError(t, err)
+		require.True(t, results.Success)
+
+		missing, _ := newTestBucketRepository(t, "")
+		missing.openBucket = func(ctx context.Context) (*blob.Bucket, error) {
+			return fileblob.OpenBucket(filepath.Join(t.TempDir(), "missing"), nil)
+		}
+		results, err = missing.Test(ctx)
+		require.NoError(t, err)
+		require.False(t, results.Success)
+	})
+}
+
+func TestBucketRepository_Validate(t *testing.T) {
+	validate := func(cfg *provisioning.BucketRepositoryConfig) []string {
+		repo := NewBucket(&provisioning.Repository{Spec: provisioning.RepositorySpec{Bucket: cfg}}, nil)
+		var fields []string
+		for _, err := range repo.Validate() {
+			fields = append(fields, err.Field)
+		}
+		return fields
+	}
+
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "s3://my-bucket?region=us-east-1", Prefix: "dashboards/", AccessKey: "id", SecretKey: "secret"}))
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "s3://my-bucket?endpoint=https://minio.example.com&use_path_style=true", AccessKey: "id", EncryptedSecretKey: []byte("secret")}))
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "gs://my-bucket", SecretKey: `{"type":"service_account"}`}))
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "azblob://my-container", Prefix: "team/dashboards", AccessKey: "myaccount", SecretKey: "key"}))
+
+	require.Equal(t, []string{"spec.bucket"}, validate(nil))
+	require.Equal(t, []string{"spec.bucket.url"}, validate(&provisioning.BucketRepositoryConfig{}))
+	require.Equal(t, []string{"spec.bucket.url"}, validate(&provisioning.BucketRepositoryConfig{URL: "mem://"}))
+	require.Equal(t, []string{"spec.bucket.url"}, validate(&provisioning.BucketRepositoryConfig{URL: "file:///var/lib/grafana"}))
+	require.Equal(t, []string{"spec.bucket.url"}, validate(&provisioning.BucketRepositoryConfig{URL: "s3://my-bucket?profile=admin", AccessKey: "id", SecretKey: "secret"}))
+	require.Equal(t, []string{"spec.bucket.url"}, validate(&provisioning.BucketRepositoryConfig{URL: "gs://my-bucket?access_id=admin", SecretKey: "key"}))
+	require.Equal(t, []string{"spec.bucket.prefix"}, validate(&provisioning.BucketRepositoryConfig{URL: "gs://my-bucket", Prefix: "../dashboards", SecretKey: "key"}))
+	require.Equal(t, []string{"spec.bucket.secretKey", "spec.bucket.accessKey"}, validate(&provisioning.BucketRepositoryConfig{URL: "s3://my-bucket"}))
+	require.Equal(t, []string{"spec.bucket.accessKey"}, validate(&provisioning.BucketRepositoryConfig{URL: "azblob://my-container", AccessKey: "evil.example.com/", SecretKey: "key"}))
+	require.Equal(t, []string{"spec.bucket.accessKey"}, validate(&provisioning.BucketRepositoryConfig{URL: "gs://my-bucket", AccessKey: "id", SecretKey: "key"}))
+}
+
+func TestBucketRepository_OpenWithCredentials(t *testing.T) {
+	open := func(cfg *provisioning.BucketRepositoryConfig) error {
+		_, err := NewBucket(&provisioning.Repository{Spec: provisioning.RepositorySpec{Bucket: cfg}}, nil).open(context.Background())
+		return err
+	}
+
+	require.ErrorContains(t, open(&provisioning.BucketRepositoryConfig{URL: "s3://my-bucket", AccessKey: "id"}), "credentials of the bucket are required")
+	require.ErrorContains(t, open(&provisioning.BucketRepositoryConfig{URL: "file:///etc", SecretKey: "key"}), "unsupported bucket url scheme")
+	require.ErrorContains(t, open(&provisioning.BucketRepositoryConfig{
+		URL:       "gs://my-bucket",
+		SecretKey: `{"type":"external_account","credential_source":{"file":"/etc/passwd"}}`,
+	}), "service account")
+}
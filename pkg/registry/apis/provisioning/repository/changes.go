@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrChangeRequestStale is returned when a change request is merged while its branch has changed since it was approved.
+var ErrChangeRequestStale = errors.New("the branch changed since the change request was approved")
+
+type ChangeRequestState string
+
+const (
+	ChangeRequestStateOpen   ChangeRequestState = "open"
+	ChangeRequestStateMerged ChangeRequestState = "merged"
+	ChangeRequestStateClosed ChangeRequestState = "closed"
+)
+
+type ChangeRequestOptions struct {
+	// The branch with the changes
+	Branch string
+	// The branch the changes are merged into
+	Target      string
+	Title       string
+	Description string
+}
+
+// A change request as known by the server (e.g. a pull request on GitHub)
+type ChangeRequest struct {
+	Number int64
+	URL    string
+	// The branch the changes are merged into
+	Target string
+	State  ChangeRequestState
+	// The commit at the head of the branch
+	Head string
+	// The head commit has been approved by a reviewer
+	Approved bool
+}
+
+// ChangeRequester is a versioned repository where changes on a branch can be reviewed before they are merged into another branch.
+// Each forge (GitHub, GitLab, Gitea, ...) implements it with its own API.
+type ChangeRequester interface {
+	Versioned
+
+	// Open a change request for the branch.
+	// If one is already open for the same branches, it is returned instead.
+	OpenChangeRequest(ctx context.Context, opts ChangeRequestOptions) (*ChangeRequest, error)
+
+	// Get the current state of a change request
+	GetChangeRequest(ctx context.Context, number int64) (*ChangeRequest, error)
+
+	// Merge the change request into its target branch, if the head of its branch is still the approved commit.
+	// It returns ErrChangeRequestStale if the branch changed.
+	MergeChangeRequest(ctx context.Context, number int64, head, message string) error
+}
@@ -0,0 +1,815 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/safepath"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/secrets"
+)
+
+var (
+	_ Repository = (*gitRepository)(nil)
+	_ Reader     = (*gitRepository)(nil)
+	_ Writer     = (*gitRepository)(nil)
+	_ Versioned  = (*gitRepository)(nil)
+)
+
+// ErrFileAlreadyExists indicates that a path already exists in the repository.
+var ErrFileAlreadyExists = errors.New("the file already exists")
+
+const (
+	// emptyTreeHash is the hash of a tree without any entries. It exists in every git repository.
+	emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	// keepFile is written to create an otherwise empty directory, as git only tracks files.
+	keepFile = ".keep"
+
+	defaultGitUsername = "git"
+
+	// gitFetchInterval is how long the branches of a mirror are considered up to date after a fetch.
+	// Reads of a branch within it reuse the fetched commits, reads of a commit hash never fetch it again.
+	gitFetchInterval = 10 * time.Second
+)
+
+// scpLikeURL matches the short SSH syntax, e.g. `git@example.com:org/repo.git`.
+var scpLikeURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/]`)
+
+// gitRepository is a repository on any Git server. It talks to the server with the git command line,
+// and keeps a bare mirror of the remote branches in a local directory to read from.
+// Changes are committed with plumbing commands into a temporary index, and pushed to the remote.
+type gitRepository struct {
+	config  *provisioning.Repository
+	secrets secrets.Service
+	// dir is the path of the bare mirror.
+	dir string
+
+	// mirror serializes the operations on the mirror. It is shared by all the instances using the same mirror.
+	mirror *gitMirror
+}
+
+// gitMirror holds the state of a mirror directory that is shared by all the instances using it.
+type gitMirror struct {
+	sync.Mutex
+	// fetched is when the branches were last fetched from the remote.
+	fetched time.Time
+}
+
+// mirrors holds the state of each mirror directory, as a repository is created for every request.
+var mirrors sync.Map
+
+// NewGit creates a repository backed by a Git server. The mirror of the repository is kept in a subdirectory of dir.
+func NewGit(config *provisioning.Repository, secrets secrets.Service, dir string) *gitRepository {
+	mirror := filepath.Join(dir, config.Namespace, config.Name+".git")
+	state, _ := mirrors.LoadOrStore(mirror, &gitMirror{})
+	return &gitRepository{
+		config:  config,
+		secrets: secrets,
+		dir:     mirror,
+		mirror:  state.(*gitMirror),
+	}
+}
+
+func (r *gitRepository) Config() *provisioning.Repository {
+	return r.config
+}
+
+// Validate implements Repository.
+func (r *gitRepository) Validate() (list field.ErrorList) {
+	git := r.config.Spec.Git
+	if git == nil {
+		list = append(list, field.Required(field.NewPath("spec", "git"), "a git config is required"))
+		return list
+	}
+
+	hasToken := git.Token != "" || len(git.EncryptedToken) > 0
+	hasSSHKey := git.SSHKey != "" || len(git.EncryptedSSHKey) > 0
+	isSSH := isSSHURL(git.URL)
+	if git.URL == "" {
+		list = append(list, field.Required(field.NewPath("spec", "git", "url"), "a git url is required"))
+	} else if !isSSH {
+		u, err := url.Parse(git.URL)
+		switch {
+		case err != nil:
+			list = append(list, field.Invalid(field.NewPath("spec", "git", "url"), git.URL, "unable to parse the url"))
+		case u.Scheme != "https" && u.Scheme != "http":
+			// Local repositories are not allowed, as they would expose the files of the server.
+			list = append(list, field.Invalid(field.NewPath("spec", "git", "url"), git.URL, "the url must use https, http or ssh"))
+		case u.Scheme == "http" && hasToken:
+			list = append(list, field.Forbidden(field.NewPath("spec", "git", "token"), "a token can only be sent over https"))
+		}
+	}
+
+	if !isValidBranchName(git.Branch) {
+		list = append(list, field.Invalid(field.NewPath("spec", "git", "branch"), git.Branch, "invalid branch name"))
+	}
+
+	if isSSH && hasToken {
+		list = append(list, field.Forbidden(field.NewPath("spec", "git", "token"), "a token can only be used over https"))
+	}
+	if isSSH && git.KnownHosts == "" {
+		list = append(list, field.Required(field.NewPath("spec", "git", "knownHosts"), "known hosts are required to verify the ssh server"))
+	}
+	if !isSSH && hasSSHKey {
+		list = append(list, field.Forbidden(field.NewPath("spec", "git", "sshKey"), "an ssh key can only be used over ssh"))
+	}
+	if !isSSH && git.KnownHosts != "" {
+		list = append(list, field.Forbidden(field.NewPath("spec", "git", "knownHosts"), "known hosts can only be used over ssh"))
+	}
+
+	return list
+}
+
+// Test implements Repository.
+func (r *gitRepository) Test(ctx context.Context) (*provisioning.TestResults, error) {
+	if _, err := r.remoteHead(ctx, r.config.Spec.Git.Branch); err != nil {
+		return &provisioning.TestResults{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Errors:  []string{err.Error()},
+		}, nil
+	}
+	return &provisioning.TestResults{
+		Code:    http.StatusOK,
+		Success: true,
+	}, nil
+}
+
+// Read implements Reader.
+func (r *gitRepository) Read(ctx context.Context, filePath, ref string) (*FileInfo, error) {
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror.Lock()
+	defer r.mirror.Unlock()
+
+	commit, err := r.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &FileInfo{
+		Path: p,
+		Ref:  commit,
+	}
+	if p != "" {
+		entry, err := r.lsTree(ctx, commit, p)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Blob {
+			info.Hash = entry.Hash
+			info.Data, err = r.git(ctx, nil, nil, "cat-file", "blob", entry.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("read file: %w", err)
+			}
+		}
+	}
+
+	out, err := r.git(ctx, nil, nil, append([]string{"log", "-1", "--format=%ct", commit}, pathspec(p)...)...)
+	if err == nil {
+		if ts, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+			modified := metav1.NewTime(time.Unix(ts, 0))
+			info.Modified = &modified
+		}
+	}
+	return info, nil
+}
+
+// ReadTree implements Reader.
+func (r *gitRepository) ReadTree(ctx context.Context, ref string) ([]FileTreeEntry, error) {
+	r.mirror.Lock()
+	defer r.mirror.Unlock()
+
+	commit, err := r.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.git(ctx, nil, nil, "ls-tree", "-r", "-t", "-l", "-z", commit)
+	if err != nil {
+		return nil, fmt.Errorf("read tree: %w", err)
+	}
+	return parseLsTree(out)
+}
+
+// Create implements Writer.
+func (r *gitRepository) Create(ctx context.Context, filePath, ref string, data []byte, message string) error {
+	return r.write(ctx, filePath, ref, data, message, func(exists bool) error {
+		if exists {
+			return ErrFileAlreadyExists
+		}
+		return nil
+	})
+}
+
+// Update implements Writer.
+func (r *gitRepository) Update(ctx context.Context, filePath, ref string, data []byte, message string) error {
+	if strings.HasSuffix(filePath, "/") {
+		return fmt.Errorf("cannot update a directory")
+	}
+	return r.write(ctx, filePath, ref, data, message, func(exists bool) error {
+		if !exists {
+			return ErrFileNotFound
+		}
+		return nil
+	})
+}
+
+// Write implements Writer.
+func (r *gitRepository) Write(ctx context.Context, filePath, ref string, data []byte, message string) error {
+	return r.write(ctx, filePath, ref, data, message, func(bool) error { return nil })
+}
+
+func (r *gitRepository) write(ctx context.Context, filePath, ref string, data []byte, message string, check func(exists bool) error) error {
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	if p == "" {
+		return fmt.Errorf("a path is required")
+	}
+	isDir := strings.HasSuffix(filePath, "/")
+	if isDir {
+		// Git does not track directories, so a placeholder file is created instead.
+		p += "/" + keepFile
+		data = nil
+	}
+
+	return r.commit(ctx, ref, message, func(parent string, env []string) error {
+		exists := false
+		if parent != "" {
+			_, err := r.lsTree(ctx, parent, p)
+			switch {
+			case err == nil:
+				exists = true
+			case !errors.Is(err, ErrFileNotFound):
+				return err
+			}
+		}
+		if err := check(exists); err != nil {
+			return err
+		}
+
+		hash, err := r.git(ctx, env, data, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		_, err = r.git(ctx, env, nil, "update-index", "--add", "--cacheinfo", "100644,"+strings.TrimSpace(string(hash))+","+p)
+		return err
+	})
+}
+
+// Delete implements Writer.
+func (r *gitRepository) Delete(ctx context.Context, filePath, ref, message string) error {
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	if p == "" {
+		return fmt.Errorf("a path is required")
+	}
+
+	return r.commit(ctx, ref, message, func(parent string, env []string) error {
+		out, err := r.git(ctx, env, nil, append([]string{"ls-files", "-z"}, pathspec(p)...)...)
+		if err != nil {
+			return err
+		}
+		paths := splitNull(out)
+		if len(paths) == 0 {
+			return ErrFileNotFound
+		}
+		// A zero mode removes the entry. Unlike --force-remove, this does not need a work tree.
+		var removals bytes.Buffer
+		for _, p := range paths {
+			fmt.Fprintf(&removals, "0 %s\t%s\x00", strings.Repeat("0", 40), p)
+		}
+		_, err = r.git(ctx, env, removals.Bytes(), "update-index", "-z", "--index-info")
+		return err
+	})
+}
+
+// History implements Versioned.
+func (r *gitRepository) History(ctx context.Context, filePath, ref string) ([]provisioning.HistoryItem, error) {
+	p, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror.Lock()
+	defer r.mirror.Unlock()
+
+	commit, err := r.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	// Fields are separated with NUL, and commits with the record separator.
+	out, err := r.git(ctx, nil, nil, append([]string{"log", "--format=%H%x00%an%x00%ae%x00%ct%x00%s%x1e", commit}, pathspec(p)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	var items []provisioning.HistoryItem
+	for _, record := range strings.Split(string(out), "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+		createdAt, _ := strconv.ParseInt(fields[3], 10, 64)
+		items = append(items, provisioning.HistoryItem{
+			Ref:     fields[0],
+			Message: fields[4],
+			Authors: []provisioning.Author{{
+				Name:     fields[1],
+				Username: fields[2],
+			}},
+			CreatedAt: createdAt * 1000,
+		})
+	}
+	if len(items) == 0 {
+		return nil, ErrFileNotFound
+	}
+	return items, nil
+}
+
+// LatestRef implements Versioned. It asks the remote for the head of the configured branch, without fetching it.
+func (r *gitRepository) LatestRef(ctx context.Context) (string, error) {
+	return r.remoteHead(ctx, r.config.Spec.Git.Branch)
+}
+
+// CompareFiles implements Versioned. An empty base compares against an empty repository.
+func (r *gitRepository) CompareFiles(ctx context.Context, base, ref string) ([]VersionedFileChange, error) {
+	r.mirror.Lock()
+	defer r.mirror.Unlock()
+
+	target, err := r.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	baseTree := emptyTreeHash
+	if base != "" {
+		base, err = r.resolve(ctx, base)
+		if err != nil {
+			return nil, err
+		}
+		baseTree = base
+	}
+
+	out, err := r.git(ctx, nil, nil, "diff-tree", "-r", "-M", "--name-status", "-z", baseTree, target)
+	if err != nil {
+		return nil, fmt.Errorf("compare files: %w", err)
+	}
+
+	var changes []VersionedFileChange
+	fields := splitNull(out)
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" || i+1 >= len(fields) {
+			continue
+		}
+		i++
+		change := VersionedFileChange{Path: fields[i], Ref: target}
+		switch status[0] {
+		case 'A':
+			change.Action = FileActionCreated
+		case 'M', 'T':
+			change.Action = FileActionUpdated
+			change.PreviousRef = base
+		case 'D':
+			change.Action = FileActionDeleted
+			change.Ref = base
+			change.PreviousRef = base
+		case 'R':
+			if i+1 >= len(fields) {
+				continue
+			}
+			i++
+			change.Action = FileActionRenamed
+			change.PreviousPath = change.Path
+			change.Path = fields[i]
+			change.PreviousRef = base
+		default:
+			change.Action = FileActionIgnored
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// commit applies the changes to a temporary index based on the head of the branch, and pushes a commit with it.
+// The change function receives the parent commit (empty if the branch has no commits yet), and the environment to use the index with.
+func (r *gitRepository) commit(ctx context.Context, ref, message string, change func(parent string, env []string) error) error {
+	branch := ref
+	if branch == "" {
+		branch = r.config.Spec.Git.Branch
+	}
+	if !isValidBranchName(branch) {
+		return fmt.Errorf("invalid branch name: %s", branch)
+	}
+
+	r.mirror.Lock()
+	defer r.mirror.Unlock()
+
+	if err := r.fetch(ctx); err != nil {
+		return err
+	}
+
+	parent, err := r.localHead(ctx, branch)
+	if err != nil && branch != r.config.Spec.Git.Branch {
+		// A new branch starts from the configured one.
+		parent, err = r.localHead(ctx, r.config.Spec.Git.Branch)
+	}
+	if err != nil && !errors.Is(err, ErrFileNotFound) {
+		return err
+	}
+
+	index, err := os.CreateTemp("", "grafana-git-index-*")
+	if err != nil {
+		return fmt.Errorf("create index: %w", err)
+	}
+	_ = index.Close()
+	// git refuses to read an empty index file, so it starts from a missing one.
+	_ = os.Remove(index.Name())
+	defer func() { _ = os.Remove(index.Name()) }()
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	if parent != "" {
+		if _, err := r.git(ctx, env, nil, "read-tree", parent); err != nil {
+			return fmt.Errorf("read tree: %w", err)
+		}
+	}
+	if err := change(parent, env); err != nil {
+		return err
+	}
+
+	tree, err := r.git(ctx, env, nil, "write-tree")
+	if err != nil {
+		return fmt.Errorf("write tree: %w", err)
+	}
+	args := []string{"commit-tree", strings.TrimSpace(string(tree)), "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	commit, err := r.git(ctx, authorEnv(ctx), nil, args...)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	hash := strings.TrimSpace(string(commit))
+
+	if err := r.remote(ctx, "push", "--quiet", r.config.Spec.Git.URL, hash+":refs/heads/"+branch); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	if _, err := r.git(ctx, nil, nil, "update-ref", "refs/heads/"+branch, hash); err != nil {
+		return fmt.Errorf("update branch: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("pushed commit to git repository", "branch", branch, "commit", hash)
+	return nil
+}
+
+// resolve returns the commit hash for a branch or a commit. An empty ref resolves to the configured branch.
+// Branches are fetched if the mirror was not fetched within gitFetchInterval, so that reads see recent changes.
+// Callers reading many files at the same version, like syncs, should resolve the ref once and read at the commit hash.
+func (r *gitRepository) resolve(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		ref = r.config.Spec.Git.Branch
+	}
+	if !isCommitHash(ref) && !isValidBranchName(ref) {
+		return "", fmt.Errorf("invalid ref: %s", ref)
+	}
+	if err := r.init(ctx); err != nil {
+		return "", err
+	}
+	// A known commit does not need to be fetched.
+	if isCommitHash(ref) {
+		if _, err := r.git(ctx, nil, nil, "cat-file", "-e", ref+"^{commit}"); err == nil {
+			return ref, nil
+		}
+	}
+	if isCommitHash(ref) || time.Since(r.mirror.fetched) >= gitFetchInterval {
+		if err := r.fetch(ctx); err != nil {
+			return "", err
+		}
+	}
+	if hash, err := r.localHead(ctx, ref); err == nil {
+		return hash, nil
+	}
+	out, err := r.git(ctx, nil, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %q: %w", ref, ErrFileNotFound)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// localHead returns the commit of a branch in the mirror.
+func (r *gitRepository) localHead(ctx context.Context, branch string) (string, error) {
+	out, err := r.git(ctx, nil, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("unknown branch %q: %w", branch, ErrFileNotFound)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// remoteHead returns the commit of a branch in the remote.
+func (r *gitRepository) remoteHead(ctx context.Context, branch string) (string, error) {
+	auth, cleanup, err := r.authEnv(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	out, err := runGit(ctx, "", auth, nil, "ls-remote", "--heads", r.config.Spec.Git.URL, "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+	hash, _, ok := strings.Cut(strings.TrimSpace(string(out)), "\t")
+	if !ok || hash == "" {
+		return "", fmt.Errorf("branch %q not found in the repository", branch)
+	}
+	return hash, nil
+}
+
+// init creates the mirror if it does not exist yet.
+func (r *gitRepository) init(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(r.dir, "HEAD")); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(r.dir, 0o750); err != nil {
+		return fmt.Errorf("create git directory: %w", err)
+	}
+	if _, err := runGit(ctx, "", nil, nil, "init", "--quiet", "--bare", r.dir); err != nil {
+		return fmt.Errorf("init git directory: %w", err)
+	}
+	return nil
+}
+
+// fetch updates all branches of the mirror from the remote.
+func (r *gitRepository) fetch(ctx context.Context) error {
+	if err := r.init(ctx); err != nil {
+		return err
+	}
+	if err := r.remote(ctx, "fetch", "--quiet", "--prune", "--no-tags", "--update-head-ok", r.config.Spec.Git.URL, "+refs/heads/*:refs/heads/*"); err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	r.mirror.fetched = time.Now()
+	return nil
+}
+
+// remote runs a git command that talks to the remote, with the credentials of the repository.
+func (r *gitRepository) remote(ctx context.Context, args ...string) error {
+	env, cleanup, err := r.authEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	_, err = r.git(ctx, env, nil, args...)
+	return err
+}
+
+// authEnv returns the environment with the credentials for the remote.
+// The returned function removes any temporary file that holds the credentials.
+func (r *gitRepository) authEnv(ctx context.Context) ([]string, func(), error) {
+	git := r.config.Spec.Git
+	noop := func() {}
+
+	token := git.Token
+	if token == "" && len(git.EncryptedToken) > 0 {
+		decrypted, err := r.secrets.Decrypt(ctx, git.EncryptedToken)
+		if err != nil {
+			return nil, noop, fmt.Errorf("decrypt token: %w", err)
+		}
+		token = string(decrypted)
+	}
+	if token != "" {
+		username := git.Username
+		if username == "" {
+			username = defaultGitUsername
+		}
+		// The header is passed through the environment, so that the token does not show up in the process list.
+		header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+token))
+		return []string{
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=" + header,
+		}, noop, nil
+	}
+
+	key := []byte(git.SSHKey)
+	if len(key) == 0 && len(git.EncryptedSSHKey) > 0 {
+		decrypted, err := r.secrets.Decrypt(ctx, git.EncryptedSSHKey)
+		if err != nil {
+			return nil, noop, fmt.Errorf("decrypt ssh key: %w", err)
+		}
+		key = decrypted
+	}
+	if !isSSHURL(git.URL) {
+		return nil, noop, nil
+	}
+	// Without known hosts, anyone in the network path could impersonate the server.
+	if git.KnownHosts == "" {
+		return nil, noop, errors.New("known hosts are required to verify the ssh server")
+	}
+
+	dir, err := os.MkdirTemp("", "grafana-git-ssh-*")
+	if err != nil {
+		return nil, noop, fmt.Errorf("create ssh directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	sshCommand := []string{"ssh", "-o", "BatchMode=yes"}
+	if len(key) > 0 {
+		keyFile := filepath.Join(dir, "id")
+		if !bytes.HasSuffix(key, []byte("\n")) {
+			key = append(key, '\n')
+		}
+		if err := os.WriteFile(keyFile, key, 0o600); err != nil {
+			cleanup()
+			return nil, noop, fmt.Errorf("write ssh key: %w", err)
+		}
+		sshCommand = append(sshCommand, "-i", shellQuote(keyFile), "-o", "IdentitiesOnly=yes")
+	}
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	if err := os.WriteFile(knownHostsFile, []byte(git.KnownHosts), 0o600); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("write known hosts: %w", err)
+	}
+	sshCommand = append(sshCommand, "-o", "UserKnownHostsFile="+shellQuote(knownHostsFile), "-o", "StrictHostKeyChecking=yes")
+
+	return []string{"GIT_SSH_COMMAND=" + strings.Join(sshCommand, " ")}, cleanup, nil
+}
+
+// lsTree returns the entry of a path in a commit.
+func (r *gitRepository) lsTree(ctx context.Context, commit, p string) (FileTreeEntry, error) {
+	out, err := r.git(ctx, nil, nil, "ls-tree", "-l", "-z", commit, "--", p)
+	if err != nil {
+		return FileTreeEntry{}, fmt.Errorf("read tree: %w", err)
+	}
+	entries, err := parseLsTree(out)
+	if err != nil {
+		return FileTreeEntry{}, err
+	}
+	if len(entries) != 1 || entries[0].Path != p {
+		return FileTreeEntry{}, ErrFileNotFound
+	}
+	return entries[0], nil
+}
+
+func (r *gitRepository) git(ctx context.Context, env []string, stdin []byte, args ...string) ([]byte, error) {
+	return runGit(ctx, r.dir, env, stdin, args...)
+}
+
+// runGit runs a git command in the given git directory, and returns its standard output.
+func runGit(ctx context.Context, gitDir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	// #nosec G204 -- the arguments are passed to git directly, without a shell.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"LC_ALL=C",
+	)
+	if gitDir != "" {
+		cmd.Env = append(cmd.Env, "GIT_DIR="+gitDir)
+	}
+	cmd.Env = append(cmd.Env, env...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseLsTree parses the output of `git ls-tree -l -z`, where each entry is `<mode> <type> <hash> <size>\t<path>`.
+func parseLsTree(out []byte) ([]FileTreeEntry, error) {
+	var entries []FileTreeEntry
+	for _, line := range splitNull(out) {
+		meta, p, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected tree entry: %q", line)
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected tree entry: %q", line)
+		}
+		entry := FileTreeEntry{Path: p}
+		if fields[1] == "blob" {
+			entry.Blob = true
+			entry.Hash = fields[2]
+			entry.Size, _ = strconv.ParseInt(fields[3], 10, 64)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// authorEnv sets the author of a commit to the requester, if known.
+func authorEnv(ctx context.Context) []string {
+	name, email := "Grafana", "noreply@grafana.com"
+	if user, err := identity.GetRequester(ctx); err == nil {
+		if n := user.GetName(); n != "" {
+			name = n
+		} else if l := user.GetLogin(); l != "" {
+			name = l
+		}
+		if e := user.GetEmail(); e != "" {
+			email = e
+		}
+	}
+	return []string{
+		"GIT_AUTHOR_NAME=" + name,
+		"GIT_AUTHOR_EMAIL=" + email,
+		"GIT_COMMITTER_NAME=Grafana",
+		"GIT_COMMITTER_EMAIL=noreply@grafana.com",
+	}
+}
+
+// cleanPath returns the path relative to the root of the repository, without leading or trailing slashes.
+func cleanPath(p string) (string, error) {
+	joined, err := safepath.Join("/", p)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(joined, "/"), nil
+}
+
+// pathspec returns the arguments to limit a command to p, matched literally. An empty p is not limited.
+func pathspec(p string) []string {
+	if p == "" {
+		return nil
+	}
+	return []string{"--", ":(literal)" + p}
+}
+
+func splitNull(out []byte) []string {
+	s := strings.TrimSuffix(string(out), "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
+
+// isSSHURL returns true if the url is an ssh url, or uses the short ssh syntax.
+func isSSHURL(rawURL string) bool {
+	if scpLikeURL.MatchString(rawURL) {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "ssh"
+}
+
+func isCommitHash(ref string) bool {
+	if len(ref) != 40 && len(ref) != 64 {
+		return false
+	}
+	for _, c := range ref {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidBranchName is a conservative version of `git check-ref-format --branch`.
+func isValidBranchName(branch string) bool {
+	if branch == "" || strings.HasPrefix(branch, "-") || strings.HasPrefix(branch, "/") || strings.HasSuffix(branch, "/") ||
+		strings.HasSuffix(branch, ".lock") || strings.HasSuffix(branch, ".") ||
+		strings.Contains(branch, "..") || strings.Contains(branch, "//") || strings.Contains(branch, "@{") {
+		return false
+	}
+	for _, c := range branch {
+		if c <= ' ' || c == 0x7f || strings.ContainsRune("~^:?*[\\", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
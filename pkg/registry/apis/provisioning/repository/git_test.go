@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+)
+
+func newTestGitRepository(t *testing.T) *gitRepository {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	_, err := runGit(context.Background(), "", nil, nil, "init", "--quiet", "--bare", remote)
+	require.NoError(t, err)
+
+	return NewGit(&provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: provisioning.RepositorySpec{
+			Type: provisioning.GitRepositoryType,
+			Git: &provisioning.GitRepositoryConfig{
+				URL:    "file://" + remote,
+				Branch: "main",
+			},
+		},
+	}, nil, t.TempDir())
+}
+
+func TestGitRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestGitRepository(t)
+
+	_, err := repo.LatestRef(ctx)
+	require.Error(t, err, "the branch does not exist yet")
+
+	require.NoError(t, repo.Create(ctx, "dashboards/a.json", "", []byte(`{"a":1}`), "add a"))
+	first, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+
+	t.Run("should read files", func(t *testing.T) {
+		info, err := repo.Read(ctx, "dashboards/a.json", "")
+		require.NoError(t, err)
+		require.Equal(t, "dashboards/a.json", info.Path)
+		require.Equal(t, []byte(`{"a":1}`), info.Data)
+		require.Equal(t, first, info.Ref)
+		require.NotEmpty(t, info.Hash)
+		require.NotNil(t, info.Modified)
+
+		_, err = repo.Read(ctx, "dashboards/missing.json", "")
+		require.ErrorIs(t, err, ErrFileNotFound)
+
+		_, err = repo.Read(ctx, "../dashboards/a.json", "")
+		require.NoError(t, err, "path traversal is kept inside the repository")
+	})
+
+	t.Run("should not create existing files or update missing ones", func(t *testing.T) {
+		require.ErrorIs(t, repo.Create(ctx, "dashboards/a.json", "", []byte(`{}`), "add a"), ErrFileAlreadyExists)
+		require.ErrorIs(t, repo.Update(ctx, "dashboards/missing.json", "", []byte(`{}`), "update"), ErrFileNotFound)
+		require.ErrorIs(t, repo.Delete(ctx, "dashboards/missing.json", "", "delete"), ErrFileNotFound)
+	})
+
+	require.NoError(t, repo.Update(ctx, "dashboards/a.json", "", []byte(`{"a":2}`), "update a"))
+	require.NoError(t, repo.Write(ctx, "dashboards/b.json", "", []byte(`{"b":1}`), "add b"))
+	require.NoError(t, repo.Create(ctx, "empty/", "", nil, "add folder"))
+	second, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+
+	t.Run("should read the tree", func(t *testing.T) {
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		paths := make(map[string]bool, len(tree))
+		for _, entry := range tree {
+			paths[entry.Path] = entry.Blob
+		}
+		require.Equal(t, map[string]bool{
+			"dashboards":        false,
+			"dashboards/a.json": true,
+			"dashboards/b.json": true,
+			"empty":             false,
+			"empty/.keep":       true,
+		}, paths)
+
+		old, err := repo.ReadTree(ctx, first)
+		require.NoError(t, err)
+		require.Len(t, old, 2)
+	})
+
+	t.Run("should read files at a ref", func(t *testing.T) {
+		info, err := repo.Read(ctx, "dashboards/a.json", first)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"a":1}`), info.Data)
+	})
+
+	t.Run("should reject invalid refs", func(t *testing.T) {
+		for _, ref := range []string{"--output=/tmp/out", "HEAD@{1}", "main~1", "a..b"} {
+			_, err := repo.Read(ctx, "dashboards/a.json", ref)
+			require.ErrorContains(t, err, "invalid ref")
+		}
+	})
+
+	t.Run("should share the mirror lock between instances", func(t *testing.T) {
+		same := NewGit(repo.config, nil, filepath.Dir(filepath.Dir(repo.dir)))
+		require.Equal(t, repo.dir, same.dir)
+		require.Same(t, repo.mirror, same.mirror)
+	})
+
+	t.Run("should return history of a file", func(t *testing.T) {
+		history, err := repo.History(ctx, "dashboards/a.json", "")
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, "update a", history[0].Message)
+		require.Equal(t, "add a", history[1].Message)
+		require.Equal(t, first, history[1].Ref)
+		require.Equal(t, "Grafana", history[0].Authors[0].Name)
+	})
+
+	t.Run("should compare refs", func(t *testing.T) {
+		changes, err := repo.CompareFiles(ctx, first, second)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []VersionedFileChange{
+			{Action: FileActionUpdated, Path: "dashboards/a.json", Ref: second, PreviousRef: first},
+			{Action: FileActionCreated, Path: "dashboards/b.json", Ref: second},
+			{Action: FileActionCreated, Path: "empty/.keep", Ref: second},
+		}, changes)
+
+		changes, err = repo.CompareFiles(ctx, "", first)
+		require.NoError(t, err)
+		require.Equal(t, []VersionedFileChange{
+			{Action: FileActionCreated, Path: "dashboards/a.json", Ref: first},
+		}, changes)
+	})
+
+	t.Run("should write to a new branch", func(t *testing.T) {
+		require.NoError(t, repo.Write(ctx, "dashboards/c.json", "feature", []byte(`{"c":1}`), "add c"))
+
+		_, err := repo.Read(ctx, "dashboards/c.json", "")
+		require.ErrorIs(t, err, ErrFileNotFound)
+		info, err := repo.Read(ctx, "dashboards/c.json", "feature")
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"c":1}`), info.Data)
+
+		changes, err := repo.CompareFiles(ctx, "main", "feature")
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, FileActionCreated, changes[0].Action)
+	})
+
+	t.Run("should delete folders", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, "dashboards/", "", "delete dashboards"))
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, []FileTreeEntry{
+			{Path: "empty"},
+			{Path: "empty/.keep", Hash: "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391", Blob: true},
+		}, tree)
+	})
+
+	t.Run("should see changes pushed by others", func(t *testing.T) {
+		other := NewGit(repo.config, nil, t.TempDir())
+		require.NoError(t, other.Create(ctx, "other.json", "", []byte(`{}`), "add other"))
+
+		latest, err := repo.LatestRef(ctx)
+		require.NoError(t, err)
+		info, err := repo.Read(ctx, "other.json", latest)
+		require.NoError(t, err)
+		require.Equal(t, latest, info.Ref)
+
+		repo.mirror.fetched = time.Time{}
+		info, err = repo.Read(ctx, "other.json", "")
+		require.NoError(t, err)
+		require.Equal(t, latest, info.Ref)
+	})
+
+	t.Run("should not fetch again to read recently fetched branches", func(t *testing.T) {
+		remote := strings.TrimPrefix(repo.config.Spec.Git.URL, "file://")
+		require.NoError(t, os.Rename(remote, remote+".moved"))
+		t.Cleanup(func() { require.NoError(t, os.Rename(remote+".moved", remote)) })
+
+		_, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		_, err = repo.Read(ctx, "other.json", "")
+		require.NoError(t, err)
+
+		repo.mirror.fetched = time.Time{}
+		_, err = repo.Read(ctx, "other.json", "")
+		require.ErrorContains(t, err, "fetch")
+	})
+
+	t.Run("should test the connection", func(t *testing.T) {
+		results, err := repo.Test(ctx)
+		require.NoError(t, err)
+		require.True(t, results.Success)
+
+		missing := NewGit(&provisioning.Repository{Spec: provisioning.RepositorySpec{Git: &provisioning.GitRepositoryConfig{
+			URL:    "file://" + filepath.Join(t.TempDir(), "missing.git"),
+			Branch: "main",
+		}}}, nil, t.TempDir())
+		results, err = missing.Test(ctx)
+		require.NoError(t, err)
+		require.False(t, results.Success)
+	})
+}
+
+func TestGitRepository_Validate(t *testing.T) {
+	validate := func(cfg *provisioning.GitRepositoryConfig) []string {
+		repo := NewGit(&provisioning.Repository{Spec: provisioning.RepositorySpec{Git: cfg}}, nil, "")
+		var fields []string
+		for _, err := range repo.Validate() {
+			fields = append(fields, err.Field)
+		}
+		return fields
+	}
+
+	require.Empty(t, validate(&provisioning.GitRepositoryConfig{URL: "https://gitea.example.com/org/repo.git", Branch: "main", Token: "token"}))
+	require.Empty(t, validate(&provisioning.GitRepositoryConfig{URL: "http://gitea.internal/org/repo.git", Branch: "main"}))
+	require.Empty(t, validate(&provisioning.GitRepositoryConfig{URL: "git@gitlab.example.com:org/repo.git", Branch: "main", SSHKey: "key", KnownHosts: "hosts"}))
+	require.Empty(t, validate(&provisioning.GitRepositoryConfig{URL: "ssh://git@example.com/repo.git", Branch: "release/v1", KnownHosts: "hosts"}))
+
+	require.Equal(t, []string{"spec.git"}, validate(nil))
+	require.Equal(t, []string{"spec.git.url", "spec.git.branch"}, validate(&provisioning.GitRepositoryConfig{}))
+	require.Equal(t, []string{"spec.git.url"}, validate(&provisioning.GitRepositoryConfig{URL: "ftp://example.com/repo", Branch: "main"}))
+	require.Equal(t, []string{"spec.git.url"}, validate(&provisioning.GitRepositoryConfig{URL: "file:///var/lib/grafana/repo.git", Branch: "main"}))
+	require.Equal(t, []string{"spec.git.branch"}, validate(&provisioning.GitRepositoryConfig{URL: "https://example.com/repo", Branch: "a..b"}))
+	require.Equal(t, []string{"spec.git.token"}, validate(&provisioning.GitRepositoryConfig{URL: "ssh://git@example.com/repo.git", Branch: "main", Token: "token", KnownHosts: "hosts"}))
+	require.Equal(t, []string{"spec.git.token"}, validate(&provisioning.GitRepositoryConfig{URL: "http://gitea.internal/org/repo.git", Branch: "main", Token: "token"}))
+	require.Equal(t, []string{"spec.git.knownHosts"}, validate(&provisioning.GitRepositoryConfig{URL: "git@gitlab.example.com:org/repo.git", Branch: "main", SSHKey: "key"}))
+	require.Equal(t, []string{"spec.git.sshKey", "spec.git.knownHosts"}, validate(&provisioning.GitRepositoryConfig{URL: "https://example.com/repo", Branch: "main", SSHKey: "key", KnownHosts: "hosts"}))
+}
+
+func TestGitRepository_AuthEnv(t *testing.T) {
+	ctx := context.Background()
+	authEnv := func(cfg *provisioning.GitRepositoryConfig) ([]string, error) {
+		repo := NewGit(&provisioning.Repository{Spec: provisioning.RepositorySpec{Git: cfg}}, nil, "")
+		env, cleanup, err := repo.authEnv(ctx)
+		cleanup()
+		return env, err
+	}
+
+	_, err := authEnv(&provisioning.GitRepositoryConfig{URL: "git@example.com:org/repo.git", SSHKey: "key"})
+	require.ErrorContains(t, err, "known hosts are required")
+
+	env, err := authEnv(&provisioning.GitRepositoryConfig{URL: "ssh://git@example.com/repo.git", KnownHosts: "example.com ssh-ed25519 AAAA"})
+	require.NoError(t, err)
+	require.Len(t, env, 1)
+	require.Contains(t, env[0], "StrictHostKeyChecking=yes")
+
+	env, err = authEnv(&provisioning.GitRepositoryConfig{URL: "https://example.com/repo.git"})
+	require.NoError(t, err)
+	require.Empty(t, env)
+}
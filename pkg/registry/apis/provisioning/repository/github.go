@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/secrets"
+)
+
+var (
+	_ Repository      = (*githubRepository)(nil)
+	_ Reader          = (*githubRepository)(nil)
+	_ Writer          = (*githubRepository)(nil)
+	_ ChangeRequester = (*githubRepository)(nil)
+)
+
+// githubTokenUsername is the username GitHub expects with an access token over HTTPS.
+const githubTokenUsername = "x-access-token"
+
+// githubRepository is a repository on GitHub (or GitHub Enterprise Server).
+// The files are read and written with git, and change requests are pull requests created with the REST API.
+type githubRepository struct {
+	*gitRepository
+
+	config *provisioning.Repository
+	// apiURL is the base URL of the REST API, e.g. `https://api.github.com`.
+	apiURL string
+	owner  string
+	repo   string
+	client *http.Client
+}
+
+// NewGitHub creates a repository backed by GitHub. The mirror of the repository is kept in a subdirectory of dir.
+func NewGitHub(config *provisioning.Repository, secrets secrets.Service, dir string) *githubRepository {
+	// The git operations use a git configuration derived from the GitHub one.
+	gitConfig := config.DeepCopy()
+	gitConfig.Spec.Git = nil
+	if gh := config.Spec.GitHub; gh != nil {
+		gitConfig.Spec.Git = &provisioning.GitRepositoryConfig{
+			URL:            gh.URL,
+			Branch:         gh.Branch,
+			Username:       githubTokenUsername,
+			Token:          gh.Token,
+			EncryptedToken: gh.EncryptedToken,
+		}
+	}
+
+	r := &githubRepository{
+		gitRepository: NewGit(gitConfig, secrets, dir),
+		config:        config,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+	if config.Spec.GitHub != nil {
+		r.apiURL, r.owner, r.repo, _ = parseGitHubURL(config.Spec.GitHub.URL)
+	}
+	return r
+}
+
+func (r *githubRepository) Config() *provisioning.Repository {
+	return r.config
+}
+
+// Validate implements Repository.
+func (r *githubRepository) Validate() (list field.ErrorList) {
+	gh := r.config.Spec.GitHub
+	if gh == nil {
+		list = append(list, field.Required(field.NewPath("spec", "github"), "a github config is required"))
+		return list
+	}
+	if gh.URL == "" {
+		list = append(list, field.Required(field.NewPath("spec", "github", "url"), "a github url is required"))
+	} else if _, _, _, err := parseGitHubURL(gh.URL); err != nil {
+		list = append(list, field.Invalid(field.NewPath("spec", "github", "url"), gh.URL, err.Error()))
+	}
+	if !isValidBranchName(gh.Branch) {
+		list = append(list, field.Invalid(field.NewPath("spec", "github", "branch"), gh.Branch, "invalid branch name"))
+	}
+	return list
+}
+
+type githubPullRequest struct {
+	Number  int64  `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Base    struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+type githubReview struct {
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	State string `json:"state"`
+	// The commit that was reviewed
+	CommitID string `json:"commit_id"`
+}
+
+// OpenChangeRequest implements ChangeRequester with a pull request.
+func (r *githubRepository) OpenChangeRequest(ctx context.Context, opts ChangeRequestOptions) (*ChangeRequest, error) {
+	target := opts.Target
+	if target == "" {
+		target = r.config.Spec.GitHub.Branch
+	}
+
+	var pr githubPullRequest
+	err := r.api(ctx, http.MethodPost, "pulls", map[string]string{
+		"title": opts.Title,
+		"body":  opts.Description,
+		"head":  opts.Branch,
+		"base":  target,
+	}, &pr)
+
+	// GitHub refuses a second pull request for the same branches, so the open one is returned instead.
+	var apiErr *githubAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+		var open []githubPullRequest
+		query := url.Values{
+			"state": {"open"},
+			"head":  {r.owner + ":" + opts.Branch},
+			"base":  {target},
+		}
+		if listErr := r.api(ctx, http.MethodGet, "pulls?"+query.Encode(), nil, &open); listErr != nil || len(open) == 0 {
+			return nil, err
+		}
+		pr, err = open[0], nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open pull request: %w", err)
+	}
+
+	return &ChangeRequest{
+		Number: pr.Number,
+		URL:    pr.HTMLURL,
+		Target: target,
+		State:  ChangeRequestStateOpen,
+	}, nil
+}
+
+// GetChangeRequest implements ChangeRequester.
+// A pull request is approved when a reviewer approved its head commit, and no reviewer requests changes.
+// Approvals of previous commits do not count, so that changes pushed after a review are reviewed again.
+func (r *githubRepository) GetChangeRequest(ctx context.Context, number int64) (*ChangeRequest, error) {
+	prPath := "pulls/" + strconv.FormatInt(number, 10)
+
+	var pr githubPullRequest
+	if err := r.api(ctx, http.MethodGet, prPath, nil, &pr); err != nil {
+		return nil, fmt.Errorf("get pull request: %w", err)
+	}
+
+	cr := &ChangeRequest{
+		Number: pr.Number,
+		URL:    pr.HTMLURL,
+		Target: pr.Base.Ref,
+		State:  ChangeRequestStateOpen,
+		Head:   pr.Head.SHA,
+	}
+	switch {
+	case pr.Merged:
+		cr.State = ChangeRequestStateMerged
+	case pr.State == "closed":
+		cr.State = ChangeRequestStateClosed
+	}
+
+	var reviews []githubReview
+	if err := r.api(ctx, http.MethodGet, prPath+"/reviews?per_page=100", nil, &reviews); err != nil {
+		return nil, fmt.Errorf("get pull request reviews: %w", err)
+	}
+	// Reviews are returned in chronological order, so the last one of each reviewer counts.
+	latest := make(map[string]string, len(reviews))
+	for _, review := range reviews {
+		switch review.State {
+		case "APPROVED":
+			if review.CommitID != pr.Head.SHA {
+				// An approval of a previous commit is void.
+				delete(latest, review.User.Login)
+				continue
+			}
+			latest[review.User.Login] = review.State
+		case "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.User.Login] = review.State
+		}
+	}
+	for _, state := range latest {
+		if state == "CHANGES_REQUESTED" {
+			cr.Approved = false
+			break
+		}
+		if state == "APPROVED" {
+			cr.Approved = true
+		}
+	}
+
+	return cr, nil
+}
+
+// MergeChangeRequest implements ChangeRequester.
+// GitHub refuses to merge when the head of the branch is not the given commit.
+func (r *githubRepository) MergeChangeRequest(ctx context.Context, number int64, head, message string) error {
+	err := r.api(ctx, http.MethodPut, "pulls/"+strconv.FormatInt(number, 10)+"/merge", map[string]string{
+		"commit_title": message,
+		"merge_method": "merge",
+		"sha":          head,
+	}, nil)
+	var apiErr *githubAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		return ErrChangeRequestStale
+	}
+	if err != nil {
+		return fmt.Errorf("merge pull request: %w", err)
+	}
+	return nil
+}
+
+type githubAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *githubAPIError) Error() string {
+	return fmt.Sprintf("github api returned %d: %s", e.StatusCode, e.Message)
+}
+
+// api sends a request to the REST API, relative to the repository (e.g. `pulls/1`).
+// A non-nil body is sent as JSON, and the response is decoded into out when it is not nil.
+func (r *githubRepository) api(ctx context.Context, method, repoPath string, body any, out any) error {
+	if r.apiURL == "" {
+		return fmt.Errorf("invalid github url")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.apiURL+"/repos/"+r.owner+"/"+r.repo+"/"+repoPath, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	token, err := r.token(ctx)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		apiErr := &githubAPIError{StatusCode: res.StatusCode}
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&msg); err == nil {
+			apiErr.Message = msg.Message
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// token returns the access token of the repository, decrypting it when needed.
+func (r *githubRepository) token(ctx context.Context) (string, error) {
+	gh := r.config.Spec.GitHub
+	if gh.Token != "" || len(gh.EncryptedToken) == 0 {
+		return gh.Token, nil
+	}
+	decrypted, err := r.secrets.Decrypt(ctx, gh.EncryptedToken)
+	if err != nil {
+		return "", fmt.Errorf("decrypt token: %w", err)
+	}
+	return string(decrypted), nil
+}
+
+// parseGitHubURL returns the REST API URL, owner and name of a repository URL, e.g. `https://github.com/grafana/grafana`.
+// Repositories on GitHub Enterprise Server use the API under `/api/v3` on the same host.
+func parseGitHubURL(repoURL string) (apiURL, owner, repo string, err error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", "", errors.New("unable to parse the url")
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", "", "", errors.New("the url must use https or http")
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", errors.New("the url must point to a repository, e.g. https://github.com/owner/repository")
+	}
+
+	if u.Host == "github.com" {
+		apiURL = "https://api.github.com"
+	} else {
+		apiURL = u.Scheme + "://" + u.Host + "/api/v3"
+	}
+	return apiURL, parts[0], parts[1], nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+)
+
+func newTestGitHubRepository(t *testing.T, handler http.Handler) *githubRepository {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repo := NewGitHub(&provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: provisioning.RepositorySpec{
+			Type: provisioning.GitHubRepositoryType,
+			GitHub: &provisioning.GitHubRepositoryConfig{
+				URL:    "https://github.com/grafana/example",
+				Branch: "main",
+				Token:  "token",
+			},
+		},
+	}, nil, t.TempDir())
+	repo.apiURL = server.URL
+	return repo
+}
+
+func TestGitHubRepository_ChangeRequests(t *testing.T) {
+	ctx := context.Background()
+	reviews := []map[string]any{}
+	head := "c1"
+	var merged map[string]string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/grafana/example/pulls", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["head"] == "existing" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"A pull request already exists for grafana:existing."}`))
+			return
+		}
+		require.Equal(t, "main", body["base"])
+		require.Equal(t, "Update dashboards", body["title"])
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":1,"html_url":"https://github.com/grafana/example/pull/1","state":"open"}`))
+	})
+	mux.HandleFunc("GET /repos/grafana/example/pulls", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "grafana:existing", r.URL.Query().Get("head"))
+		_, _ = w.Write([]byte(`[{"number":2,"html_url":"https://github.com/grafana/example/pull/2","state":"open"}]`))
+	})
+	mux.HandleFunc("GET /repos/grafana/example/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		pr := githubPullRequest{
+			Number:  1,
+			HTMLURL: "https://github.com/grafana/example/pull/1",
+			State:   "open",
+			Merged:  merged != nil,
+		}
+		pr.Base.Ref = "main"
+		pr.Head.SHA = head
+		require.NoError(t, json.NewEncoder(w).Encode(pr))
+	})
+	mux.HandleFunc("GET /repos/grafana/example/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(reviews))
+	})
+	mux.HandleFunc("PUT /repos/grafana/example/pulls/1/merge", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["sha"] != head {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"Head branch was modified. Review and try the merge again."}`))
+			return
+		}
+		merged = body
+		_, _ = w.Write([]byte(`{"merged":true}`))
+	})
+	repo := newTestGitHubRepository(t, mux)
+
+	cr, err := repo.OpenChangeRequest(ctx, ChangeRequestOptions{Branch: "changes", Title: "Update dashboards"})
+	require.NoError(t, err)
+	require.Equal(t, &ChangeRequest{Number: 1, URL: "https://github.com/grafana/example/pull/1", Target: "main", State: ChangeRequestStateOpen}, cr)
+
+	t.Run("should return the open pull request of the branch", func(t *testing.T) {
+		cr, err := repo.OpenChangeRequest(ctx, ChangeRequestOptions{Branch: "existing", Title: "Update dashboards"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), cr.Number)
+	})
+
+	t.Run("should use the latest review of each reviewer", func(t *testing.T) {
+		review := func(login, state string) map[string]any {
+			return map[string]any{"user": map[string]string{"login": login}, "state": state, "commit_id": head}
+		}
+
+		cr, err := repo.GetChangeRequest(ctx, 1)
+		require.NoError(t, err)
+		require.False(t, cr.Approved)
+
+		reviews = []map[string]any{review("a", "CHANGES_REQUESTED"), review("b", "APPROVED")}
+		cr, err = repo.GetChangeRequest(ctx, 1)
+		require.NoError(t, err)
+		require.False(t, cr.Approved)
+
+		reviews = append(reviews, review("a", "COMMENTED"), review("a", "APPROVED"))
+		cr, err = repo.GetChangeRequest(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, cr.Approved)
+		require.Equal(t, ChangeRequestStateOpen, cr.State)
+		require.Equal(t, "main", cr.Target)
+		require.Equal(t, "c1", cr.Head)
+	})
+
+	t.Run("should ignore approvals of previous commits", func(t *testing.T) {
+		head = "c2"
+		defer func() { head = "c1" }()
+
+		cr, err := repo.GetChangeRequest(ctx, 1)
+		require.NoError(t, err)
+		require.False(t, cr.Approved)
+	})
+
+	t.Run("should not merge when the branch changed", func(t *testing.T) {
+		head = "c2"
+		defer func() { head = "c1" }()
+
+		err := repo.MergeChangeRequest(ctx, 1, "c1", "Update dashboards")
+		require.ErrorIs(t, err, ErrChangeRequestStale)
+		require.Nil(t, merged)
+	})
+
+	t.Run("should merge", func(t *testing.T) {
+		require.NoError(t, repo.MergeChangeRequest(ctx, 1, "c1", "Update dashboards"))
+		require.Equal(t, "Update dashboards", merged["commit_title"])
+		require.Equal(t, "c1", merged["sha"])
+
+		cr, err := repo.GetChangeRequest(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, ChangeRequestStateMerged, cr.State)
+	})
+
+	t.Run("should return api errors", func(t *testing.T) {
+		_, err := repo.GetChangeRequest(ctx, 3)
+		var apiErr *githubAPIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	})
+}
+
+func TestGitHubRepository_Validate(t *testing.T) {
+	validate := func(cfg *provisioning.GitHubRepositoryConfig) []string {
+		repo := NewGitHub(&provisioning.Repository{Spec: provisioning.RepositorySpec{GitHub: cfg}}, nil, t.TempDir())
+		var fields []string
+		for _, err := range repo.Validate() {
+			fields = append(fields, err.Field)
+		}
+		return fields
+	}
+
+	require.Empty(t, validate(&provisioning.GitHubRepositoryConfig{URL: "https://github.com/grafana/example", Branch: "main"}))
+	require.Empty(t, validate(&provisioning.GitHubRepositoryConfig{URL: "https://github.example.com/grafana/example.git", Branch: "main"}))
+
+	require.Equal(t, []string{"spec.github"}, validate(nil))
+	require.Equal(t, []string{"spec.github.url"}, validate(&provisioning.GitHubRepositoryConfig{Branch: "main"}))
+	require.Equal(t, []string{"spec.github.url"}, validate(&provisioning.GitHubRepositoryConfig{URL: "https://github.com/grafana", Branch: "main"}))
+	require.Equal(t, []string{"spec.github.branch"}, validate(&provisioning.GitHubRepositoryConfig{URL: "https://github.com/grafana/example", Branch: "a..b"}))
+}
+
+func TestParseGitHubURL(t *testing.T) {
+	apiURL, owner, repo, err := parseGitHubURL("https://github.com/grafana/grafana")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://api.github.com", "grafana", "grafana"}, []string{apiURL, owner, repo})
+
+	apiURL, owner, repo, err = parseGitHubURL("https://github.example.com/team/dashboards.git")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://github.example.com/api/v3", "team", "dashboards"}, []string{apiURL, owner, repo})
+}
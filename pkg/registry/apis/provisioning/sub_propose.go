@@ -0,0 +1,139 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	provisioning "github.com/grafana/grafana/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/repository"
+)
+
+// proposeRequest is the body of a request to the propose subresource of a repository
+type proposeRequest struct {
+	// The branch the files are written to. A new branch is created when it is empty.
+	Branch      string `json:"branch,omitempty"`
+	Target      string `json:"target,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Merge       bool   `json:"merge,omitempty"`
+
+	Files []proposeFile `json:"files"`
+}
+
+type proposeFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+	// Delete the file instead of writing it
+	Delete bool `json:"delete,omitempty"`
+}
+
+// subProposeREST saves files on a branch of a repository, and proposes the changes with a change proposal.
+// The commits are authored by the user that saves the files.
+type subProposeREST struct {
+	builder   *APIBuilder
+	proposals rest.Creater
+}
+
+var (
+	_ rest.Storage         = (*subProposeREST)(nil)
+	_ rest.Connecter       = (*subProposeREST)(nil)
+	_ rest.StorageMetadata = (*subProposeREST)(nil)
+)
+
+func (r *subProposeREST) New() runtime.Object {
+	return &provisioning.ChangeProposal{}
+}
+
+func (r *subProposeREST) Destroy() {}
+
+func (r *subProposeREST) ConnectMethods() []string {
+	return []string{"POST"}
+}
+
+func (r *subProposeREST) ProducesMIMETypes(verb string) []string {
+	return nil
+}
+
+func (r *subProposeREST) ProducesObject(verb string) interface{} {
+	return &provisioning.ChangeProposal{}
+}
+
+func (r *subProposeREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, ""
+}
+
+func (r *subProposeREST) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body proposeRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			responder.Error(apierrors.NewBadRequest(fmt.Sprintf("invalid request body: %s", err)))
+			return
+		}
+		if len(body.Files) == 0 {
+			responder.Error(apierrors.NewBadRequest("no files to propose"))
+			return
+		}
+
+		suffix := rand.String(8)
+		if body.Branch == "" {
+			body.Branch = "grafana/proposal-" + suffix
+		}
+		proposal := &provisioning.ChangeProposal{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "proposal-" + suffix,
+				Namespace: request.NamespaceValue(ctx),
+			},
+			Spec: provisioning.ChangeProposalSpec{
+				Repository:  name,
+				Branch:      body.Branch,
+				Target:      body.Target,
+				Title:       body.Title,
+				Description: body.Description,
+				Merge:       body.Merge,
+			},
+		}
+		// The proposal is validated before the files are written, so that no branch is left behind for an invalid one.
+		if err := r.builder.validateProposal(ctx, proposal); err != nil {
+			responder.Error(err)
+			return
+		}
+
+		repo, err := r.builder.GetRepository(ctx, name)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		writer, ok := repo.(repository.Writer)
+		if !ok {
+			responder.Error(apierrors.NewBadRequest("the repository does not support writing files"))
+			return
+		}
+		for _, file := range body.Files {
+			if file.Delete {
+				err = writer.Delete(ctx, file.Path, body.Branch, body.Title)
+			} else {
+				err = writer.Write(ctx, file.Path, body.Branch, []byte(file.Content), body.Title)
+			}
+			if err != nil {
+				responder.Error(fmt.Errorf("save %s: %w", file.Path, err))
+				return
+			}
+		}
+
+		created, err := r.proposals.Create(ctx, proposal, rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		responder.Object(http.StatusCreated, created)
+	}), nil
+}
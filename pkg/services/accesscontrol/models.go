@@ -438,6 +438,11 @@ const (
 	ActionAlertingSilencesCreate = "alert.silences:create"
 	ActionAlertingSilencesWrite  = "alert.silences:write"
 
+	// Alerting silence templates (+recurring silences) actions
+	ActionAlertingSilenceTemplatesRead   = "alert.silence-templates:read"
+	ActionAlertingSilenceTemplatesWrite  = "alert.silence-templates:write"
+	ActionAlertingSilenceTemplatesDelete = "alert.silence-templates:delete"
+
 	// Alerting Notification actions (legacy)
 	ActionAlertingNotificationsRead  = "alert.notifications:read"
 	ActionAlertingNotificationsWrite = "alert.notifications:write"
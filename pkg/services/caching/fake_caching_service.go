@@ -25,6 +25,11 @@ func (f *FakeOSSCachingService) HandleResourceRequest(ctx context.Context, req *
 	return f.ReturnHit, f.ReturnResourceResponse
 }
 
+func (f *FakeOSSCachingService) HandlePartialQueryRequest(ctx context.Context, req *backend.QueryDataRequest) CachedPartialQueryDataResponse {
+	f.calls["HandlePartialQueryRequest"]++
+	return CachedPartialQueryDataResponse{Request: req}
+}
+
 func (f *FakeOSSCachingService) AssertCalls(t *testing.T, fn string, times int) {
 	assert.Equal(t, times, f.calls[fn])
 }
@@ -0,0 +1,168 @@
+package caching
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	queryKeyPrefix    = "query-cache:"
+	resourceKeyPrefix = "resource-cache:"
+	chunkKeyPrefix    = "query-chunk-cache:"
+
+	// minAlignment is used to align time ranges of queries that have no interval.
+	minAlignment = time.Second
+)
+
+// volatileQueryFields are removed from the query JSON before computing the cache key
+// because they change between otherwise identical requests without affecting the result.
+var volatileQueryFields = []string{"requestId", "queryCachingTTL"}
+
+// normalizedQuery is the representation of a single query that is hashed into the cache key.
+type normalizedQuery struct {
+	RefID         string `json:"refId"`
+	QueryType     string `json:"queryType"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+	Interval      int64  `json:"interval"`
+	From          int64  `json:"from"`
+	To            int64  `json:"to"`
+	Query         any    `json:"query"`
+}
+
+// alignTimeRange truncates both ends of the time range to a multiple of the interval, so that requests
+// for relative ranges like now-6h to now issued within the same interval share a cache entry.
+func alignTimeRange(tr backend.TimeRange, interval time.Duration) backend.TimeRange {
+	if interval < minAlignment {
+		interval = minAlignment
+	}
+	return backend.TimeRange{
+		From: tr.From.Truncate(interval),
+		To:   tr.To.Truncate(interval),
+	}
+}
+
+// normalizeQueryJSON decodes the raw query so that it is re-encoded with sorted keys and
+// without fields that do not affect the result.
+func normalizeQueryJSON(raw json.RawMessage) (any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	if m, ok := v.(map[string]any); ok {
+		for _, f := range volatileQueryFields {
+			delete(m, f)
+		}
+	}
+	return v, nil
+}
+
+// QueryCacheKey returns the cache key of a query request. The key is derived from the organization,
+// the data source UID and last update time, and every query with its JSON model and aligned time range.
+func QueryCacheKey(req *backend.QueryDataRequest) (string, error) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	if ds == nil {
+		return "", fmt.Errorf("request has no data source")
+	}
+
+	queries := make([]normalizedQuery, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		model, err := normalizeQueryJSON(q.JSON)
+		if err != nil {
+			return "", fmt.Errorf("failed to normalize query %s: %w", q.RefID, err)
+		}
+		tr := alignTimeRange(q.TimeRange, q.Interval)
+		queries = append(queries, normalizedQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval.Milliseconds(),
+			From:          tr.From.UnixMilli(),
+			To:            tr.To.UnixMilli(),
+			Query:         model,
+		})
+	}
+
+	b, err := json.Marshal(queries)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(req.PluginContext.OrgID, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(ds.UID))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(ds.Updated.UnixNano(), 10)))
+	h.Write([]byte{0})
+	h.Write(b)
+
+	return queryKeyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// QueryChunkCacheKey returns the cache key of the results of a single query for the chunk of time
+// starting at chunkFrom. Unlike QueryCacheKey the query's own time range is not part of the key.
+func QueryChunkCacheKey(pCtx backend.PluginContext, q backend.DataQuery, chunkFrom time.Time, chunkSize time.Duration) (string, error) {
+	ds := pCtx.DataSourceInstanceSettings
+	if ds == nil {
+		return "", fmt.Errorf("request has no data source")
+	}
+
+	model, err := normalizeQueryJSON(q.JSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to normalize query %s: %w", q.RefID, err)
+	}
+	b, err := json.Marshal(normalizedQuery{
+		RefID:         q.RefID,
+		QueryType:     q.QueryType,
+		MaxDataPoints: q.MaxDataPoints,
+		Interval:      q.Interval.Milliseconds(),
+		From:          chunkFrom.UnixMilli(),
+		To:            chunkFrom.Add(chunkSize).UnixMilli(),
+		Query:         model,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(pCtx.OrgID, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(ds.UID))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(ds.Updated.UnixNano(), 10)))
+	h.Write([]byte{0})
+	h.Write(b)
+
+	return chunkKeyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResourceCacheKey returns the cache key of a resource request.
+func ResourceCacheKey(req *backend.CallResourceRequest) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(req.PluginContext.OrgID, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(req.PluginContext.PluginID))
+	h.Write([]byte{0})
+	if ds := req.PluginContext.DataSourceInstanceSettings; ds != nil {
+		h.Write([]byte(ds.UID))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.FormatInt(ds.Updated.UnixNano(), 10)))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL))
+	h.Write([]byte{0})
+	h.Write(req.Body)
+
+	return resourceKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
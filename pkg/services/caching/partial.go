@@ -0,0 +1,352 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+type skipQueryCacheKey struct{}
+
+// ContextWithoutQueryCache returns a context that makes HandleQueryRequest ignore the request.
+// It is used for requests that only cover the uncached part of a time range, because their
+// results are cached in chunks by HandlePartialQueryRequest instead.
+func ContextWithoutQueryCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipQueryCacheKey{}, true)
+}
+
+func skipQueryCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipQueryCacheKey{}).(bool)
+	return skip
+}
+
+// chunk is an aligned part of the time range of a query whose results are cached on their own.
+type chunk struct {
+	from, to time.Time
+	key      string
+}
+
+// partialQuery tracks which part of the time range of a query is served from the cache.
+type partialQuery struct {
+	query  backend.DataQuery
+	chunks []chunk
+	// cached holds the responses of the leading chunks that were found in the cache.
+	cached []backend.DataResponse
+	// fresh is the time range that still has to be queried. It always includes the end of the
+	// requested time range, as points at the end of the last chunk belong to the next one.
+	fresh backend.TimeRange
+}
+
+func (s *OSSCachingService) HandlePartialQueryRequest(ctx context.Context, req *backend.QueryDataRequest) CachedPartialQueryDataResponse {
+	passthrough := CachedPartialQueryDataResponse{Request: req}
+	if !s.enabled() || s.cfg.PartialChunkSize <= 0 || req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return passthrough
+	}
+	ds := req.PluginContext.DataSourceInstanceSettings
+
+	ttl := s.queryTTL(ds)
+	if ttl <= 0 || skipCache(ctx, req.GetHTTPHeader(XCacheSkipHeader)) || forwardsUserIdentity(ds) {
+		return passthrough
+	}
+
+	cutoff := s.now().Add(-s.cfg.PartialMaxDataDelay)
+	freshReq := &backend.QueryDataRequest{
+		PluginContext: req.PluginContext,
+		Headers:       req.Headers,
+	}
+	pqs := make([]*partialQuery, 0, len(req.Queries))
+	hasChunks, hasCached := false, false
+	for _, q := range req.Queries {
+		pq, err := s.lookupChunks(ctx, req.PluginContext, q, cutoff)
+		if err != nil {
+			s.log.FromContext(ctx).Debug("Failed to look up cached query chunks", "datasource", ds.UID, "error", err)
+			return passthrough
+		}
+		hasChunks = hasChunks || len(pq.chunks) > 0
+		hasCached = hasCached || len(pq.cached) > 0
+		fq := q
+		fq.TimeRange = pq.fresh
+		freshReq.Queries = append(freshReq.Queries, fq)
+		pqs = append(pqs, pq)
+	}
+
+	// Time ranges shorter than a chunk are left to the regular query cache.
+	if !hasChunks {
+		return passthrough
+	}
+
+	if hasCached {
+		setCacheStatus(ctx, StatusPartial)
+	} else {
+		setCacheStatus(ctx, StatusMiss)
+	}
+
+	return CachedPartialQueryDataResponse{
+		Request: freshReq,
+		MergeFn: func(ctx context.Context, resp *backend.QueryDataResponse) (*backend.QueryDataResponse, *backend.QueryDataRequest) {
+			return s.mergeChunks(ctx, req, pqs, resp, ttl)
+		},
+	}
+}
+
+// chunkSize returns the chunk size for a query, rounded up to a multiple of the query interval so
+// that results bucketed by interval line up with the chunk boundaries.
+func (s *OSSCachingService) chunkSize(q backend.DataQuery) time.Duration {
+	size := s.cfg.PartialChunkSize
+	if q.Interval > 0 && size%q.Interval != 0 {
+		size = (size/q.Interval + 1) * q.Interval
+	}
+	return size
+}
+
+// lookupChunks splits the time range of a query into aligned chunks that end before the cutoff and reads
+// the leading chunks from the cache. The remaining time range is queried starting at the first missing chunk.
+func (s *OSSCachingService) lookupChunks(ctx context.Context, pCtx backend.PluginContext, q backend.DataQuery, cutoff time.Time) (*partialQuery, error) {
+	pq := &partialQuery{query: q}
+	size := s.chunkSize(q)
+
+	start := q.TimeRange.From.Truncate(size)
+	end := q.TimeRange.To
+	if cutoff.Before(end) {
+		end = cutoff
+	}
+	for from := start; !from.Add(size).After(end); from = from.Add(size) {
+		key, err := QueryChunkCacheKey(pCtx, q, from, size)
+		if err != nil {
+			return nil, err
+		}
+		pq.chunks = append(pq.chunks, chunk{from: from, to: from.Add(size), key: key})
+	}
+
+	for _, c := range pq.chunks {
+		dr, ok := s.getChunk(ctx, c.key, q.RefID)
+		if !ok {
+			break
+		}
+		pq.cached = append(pq.cached, dr)
+	}
+
+	freshFrom := q.TimeRange.From
+	switch {
+	case len(pq.cached) > 0:
+		freshFrom = pq.chunks[len(pq.cached)-1].to
+	case len(pq.chunks) > 0:
+		// Query the whole first chunk so that it can be cached as well.
+		freshFrom = start
+	}
+	pq.fresh = backend.TimeRange{From: freshFrom, To: q.TimeRange.To}
+
+	return pq, nil
+}
+
+func (s *OSSCachingService) getChunk(ctx context.Context, key, refID string) (backend.DataResponse, bool) {
+	b, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.FromContext(ctx).Error("Failed to read query chunk from cache", "error", err)
+		}
+		return backend.DataResponse{}, false
+	}
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to decode cached query chunk", "error", err)
+		return backend.DataResponse{}, false
+	}
+	dr, ok := resp.Responses[refID]
+	return dr, ok
+}
+
+// mergeChunks caches the chunks covered by the fresh response and stitches the cached and fresh frames
+// of every query together, trimmed to the originally requested time range.
+// Queries with cached chunks whose fresh response is missing or can't be split by time are returned in a
+// request for their whole time range, as the fresh response alone would only cover the end of it.
+func (s *OSSCachingService) mergeChunks(ctx context.Context, req *backend.QueryDataRequest, pqs []*partialQuery, resp *backend.QueryDataResponse, ttl time.Duration) (*backend.QueryDataResponse, *backend.QueryDataRequest) {
+	var retry *backend.QueryDataRequest
+	merged := backend.NewQueryDataResponse()
+	if resp != nil {
+		for refID, dr := range resp.Responses {
+			merged.Responses[refID] = dr
+		}
+	}
+
+	for _, pq := range pqs {
+		refID := pq.query.RefID
+		parts := make([]data.Frames, 0, len(pq.cached)+1)
+		for _, dr := range pq.cached {
+			parts = append(parts, dr.Frames)
+		}
+
+		fresh, hasFresh := merged.Responses[refID]
+		switch {
+		case hasFresh && fresh.Error != nil:
+			// The error is returned as the data source sent it, and nothing is cached.
+			continue
+		case len(pq.cached) > 0 && (!hasFresh || !isTimeSeriesResponse(fresh)):
+			if retry == nil {
+				retry = &backend.QueryDataRequest{
+					PluginContext: req.PluginContext,
+					Headers:       req.Headers,
+				}
+			}
+			retry.Queries = append(retry.Queries, pq.query)
+			delete(merged.Responses, refID)
+			continue
+		case hasFresh && !isTimeSeriesResponse(fresh):
+			// The fresh response covers the whole time range, but it can't be cached in chunks.
+			continue
+		}
+
+		if hasFresh {
+			for _, c := range pq.chunks[len(pq.cached):] {
+				s.setChunk(ctx, c, refID, sliceFrames(fresh.Frames, c.from, c.to), ttl)
+			}
+			parts = append(parts, fresh.Frames)
+		}
+
+		frames := stitchFrames(parts)
+		for i, f := range frames {
+			frames[i] = sliceFrame(f, pq.query.TimeRange.From, pq.query.TimeRange.To, true)
+		}
+		fresh.Frames = frames
+		merged.Responses[refID] = fresh
+	}
+
+	return merged, retry
+}
+
+func (s *OSSCachingService) setChunk(ctx context.Context, c chunk, refID string, frames data.Frames, ttl time.Duration) {
+	b, err := json.Marshal(&backend.QueryDataResponse{Responses: backend.Responses{
+		refID: backend.DataResponse{Frames: frames},
+	}})
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to encode query chunk for cache", "error", err)
+		return
+	}
+	s.set(ctx, c.key, b, ttl)
+}
+
+// isTimeSeriesResponse reports whether every frame in the response can be split by time.
+// Frames without fields, as returned by some data sources when there is no data, are allowed.
+func isTimeSeriesResponse(dr backend.DataResponse) bool {
+	for _, f := range dr.Frames {
+		if len(f.Fields) > 0 && timeFieldIndex(f) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func timeFieldIndex(f *data.Frame) int {
+	idx := f.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(idx) == 0 {
+		return -1
+	}
+	return idx[0]
+}
+
+// sliceFrames returns the rows of the frames with a time in [from, to).
+func sliceFrames(frames data.Frames, from, to time.Time) data.Frames {
+	out := make(data.Frames, 0, len(frames))
+	for _, f := range frames {
+		if len(f.Fields) == 0 {
+			continue
+		}
+		out = append(out, sliceFrame(f, from, to, false))
+	}
+	return out
+}
+
+// sliceFrame returns a copy of the frame with the rows that have a time between from and to.
+// The end of the range is only included if inclusive is set.
+func sliceFrame(f *data.Frame, from, to time.Time, inclusive bool) *data.Frame {
+	timeIdx := timeFieldIndex(f)
+	if timeIdx < 0 {
+		return f
+	}
+	out := emptyCopy(f)
+	timeField := f.Fields[timeIdx]
+	for i := 0; i < timeField.Len(); i++ {
+		t, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		ts := t.(time.Time)
+		if ts.Before(from) || ts.After(to) || (!inclusive && ts.Equal(to)) {
+			continue
+		}
+		for j, field := range f.Fields {
+			out.Fields[j].Append(field.CopyAt(i))
+		}
+	}
+	return out
+}
+
+// stitchFrames concatenates the rows of frames with the same schema and labels across the parts,
+// which must be in time order. Frames that only appear in some of the parts are kept as they are.
+func stitchFrames(parts []data.Frames) data.Frames {
+	var order []string
+	byKey := map[string]*data.Frame{}
+	for _, frames := range parts {
+		for _, f := range frames {
+			if len(f.Fields) == 0 {
+				continue
+			}
+			key := frameKey(f)
+			stitched, ok := byKey[key]
+			if !ok {
+				stitched = emptyCopy(f)
+				byKey[key] = stitched
+				order = append(order, key)
+			}
+			// The latest part carries the most recent metadata, such as the executed query.
+			stitched.Meta = f.Meta
+			for j, field := range f.Fields {
+				for i := 0; i < field.Len(); i++ {
+					stitched.Fields[j].Append(field.CopyAt(i))
+				}
+			}
+		}
+	}
+
+	out := make(data.Frames, 0, len(order))
+	for _, key := range order {
+		out = append(out, byKey[key])
+	}
+	if len(out) == 0 && len(parts) > 0 {
+		return parts[len(parts)-1]
+	}
+	return out
+}
+
+// frameKey identifies a frame across chunks by its name and the name, type and labels of its fields,
+// in the same way series are matched by their labels in server side expressions.
+func frameKey(f *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(f.Name)
+	for _, field := range f.Fields {
+		sb.WriteByte(0)
+		sb.WriteString(field.Name)
+		sb.WriteByte(0)
+		sb.WriteString(field.Type().ItemTypeString())
+		sb.WriteByte(0)
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
+
+// emptyCopy is like data.Frame.EmptyCopy but keeps the frame metadata and field configs.
+func emptyCopy(f *data.Frame) *data.Frame {
+	out := f.EmptyCopy()
+	out.Meta = f.Meta
+	for i, field := range f.Fields {
+		out.Fields[i].Config = field.Config
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package caching
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeDataSource returns one point per minute for the requested time range and records the requested ranges.
+type fakeDataSource struct {
+	requests []backend.TimeRange
+	// table makes the data source return the number of points instead of a time series.
+	table bool
+}
+
+func (f *fakeDataSource) QueryData(req *backend.QueryDataRequest) *backend.QueryDataResponse {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		f.requests = append(f.requests, q.TimeRange)
+		if f.table {
+			count := int64(q.TimeRange.To.Sub(q.TimeRange.From)/time.Minute) + 1
+			resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("count", nil, []int64{count}))}}
+			continue
+		}
+		var times []time.Time
+		var values []float64
+		for ts := q.TimeRange.From.Truncate(time.Minute); !ts.After(q.TimeRange.To); ts = ts.Add(time.Minute) {
+			if ts.Before(q.TimeRange.From) {
+				continue
+			}
+			times = append(times, ts)
+			values = append(values, float64(ts.Unix()))
+		}
+		frame := data.NewFrame("",
+			data.NewField("time", nil, times),
+			data.NewField("value", data.Labels{"host": "a"}, values),
+		)
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp
+}
+
+func queryWithPartialCache(t *testing.T, s *OSSCachingService, ds *fakeDataSource, req *backend.QueryDataRequest) (*backend.QueryDataResponse, string) {
+	t.Helper()
+	ctx, httpResp := newReqContext(t, nil)
+	cr := s.HandlePartialQueryRequest(ctx, req)
+	if cr.MergeFn == nil {
+		return ds.QueryData(cr.Request), httpResp.Header().Get(XCacheHeader)
+	}
+	resp, retry := cr.MergeFn(ctx, ds.QueryData(cr.Request))
+	if retry != nil {
+		for refID, dr := range ds.QueryData(retry).Responses {
+			resp.Responses[refID] = dr
+		}
+	}
+	return resp, httpResp.Header().Get(XCacheHeader)
+}
+
+func requireMinutelyPoints(t *testing.T, resp *backend.QueryDataResponse, from, to time.Time) {
+	t.Helper()
+	require.Contains(t, resp.Responses, "A")
+	require.Len(t, resp.Responses["A"].Frames, 1)
+	frame := resp.Responses["A"].Frames[0]
+
+	expected := []time.Time{}
+	for ts := from.Truncate(time.Minute); !ts.After(to); ts = ts.Add(time.Minute) {
+		if !ts.Before(from) {
+			expected = append(expected, ts)
+		}
+	}
+	actual := make([]time.Time, frame.Fields[0].Len())
+	for i := range actual {
+		actual[i] = frame.Fields[0].At(i).(time.Time)
+	}
+	require.Equal(t, expected, actual)
+	for i, ts := range actual {
+		assert.Equal(t, float64(ts.Unix()), frame.Fields[1].At(i))
+	}
+	assert.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+}
+
+func TestOSSCachingService_HandlePartialQueryRequest(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	newPartialService := func(t *testing.T, mutators ...func(*setting.QueryCachingSettings)) *OSSCachingService {
+		s, _ := newTestService(t, append([]func(*setting.QueryCachingSettings){func(c *setting.QueryCachingSettings) {
+			c.PartialChunkSize = 30 * time.Minute
+			c.PartialMaxDataDelay = time.Minute
+		}}, mutators...)...)
+		s.nowFn = func() time.Time { return now }
+		return s
+	}
+
+	t.Run("disabled partial caching passes the request through", func(t *testing.T) {
+		s, _ := newTestService(t)
+		req := newQueryRequest(now.Add(-6*time.Hour), now)
+		cr := s.HandlePartialQueryRequest(context.Background(), req)
+		assert.Same(t, req, cr.Request)
+		assert.Nil(t, cr.MergeFn)
+	})
+
+	t.Run("time ranges shorter than a chunk pass the request through", func(t *testing.T) {
+		s := newPartialService(t)
+		req := newQueryRequest(now.Add(-5*time.Minute), now)
+		cr := s.HandlePartialQueryRequest(context.Background(), req)
+		assert.Same(t, req, cr.Request)
+		assert.Nil(t, cr.MergeFn)
+	})
+
+	t.Run("refreshing a relative time range only queries the tail", func(t *testing.T) {
+		s := newPartialService(t)
+		ds := &fakeDataSource{}
+
+		from, to := now.Add(-6*time.Hour), now
+		resp, status := queryWithPartialCache(t, s, ds, newQueryRequest(from, to))
+		assert.Equal(t, StatusMiss, status)
+		requireMinutelyPoints(t, resp, from, to)
+		require.Len(t, ds.requests, 1)
+		// The first chunk is queried in full so that it can be cached.
+		assert.Equal(t, from.Truncate(30*time.Minute), ds.requests[0].From)
+
+		now = now.Add(30 * time.Second)
+		from, to = from.Add(30*time.Second), to.Add(30*time.Second)
+		resp, status = queryWithPartialCache(t, s, ds, newQueryRequest(from, to))
+		assert.Equal(t, StatusPartial, status)
+		requireMinutelyPoints(t, resp, from, to)
+		require.Len(t, ds.requests, 2)
+		assert.Equal(t, time.Date(2024, 1, 1, 11, 30, 0, 0, time.UTC), ds.requests[1].From)
+		assert.Equal(t, to, ds.requests[1].To)
+	})
+
+	t.Run("previously queried absolute time ranges only query the end of the range", func(t *testing.T) {
+		s := newPartialService(t)
+		ds := &fakeDataSource{}
+
+		from, to := now.Add(-24*time.Hour).Truncate(time.Hour), now.Add(-12*time.Hour).Truncate(time.Hour)
+		_, _ = queryWithPartialCache(t, s, ds, newQueryRequest(from, to))
+		resp, status := queryWithPartialCache(t, s, ds, newQueryRequest(from, to))
+		assert.Equal(t, StatusPartial, status)
+		require.Len(t, ds.requests, 2)
+		// The point at the end of the range belongs to the next chunk, which is not part of the range.
+		assert.Equal(t, backend.TimeRange{From: to, To: to}, ds.requests[1])
+		requireMinutelyPoints(t, resp, from, to)
+	})
+
+	t.Run("errors are returned as is and not cached", func(t *testing.T) {
+		s := newPartialService(t)
+		req := newQueryRequest(now.Add(-6*time.Hour), now)
+		ctx, _ := newReqContext(t, nil)
+		cr := s.HandlePartialQueryRequest(ctx, req)
+		require.NotNil(t, cr.MergeFn)
+		resp, retry := cr.MergeFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.ErrDataResponse(backend.StatusBadRequest, "boom"),
+		}})
+		require.Error(t, resp.Responses["A"].Error)
+		require.Nil(t, retry)
+
+		key, err := QueryChunkCacheKey(req.PluginContext, req.Queries[0], now.Add(-6*time.Hour).Truncate(30*time.Minute), 30*time.Minute)
+		require.NoError(t, err)
+		_, err = s.cache.Get(ctx, key)
+		require.Error(t, err)
+	})
+
+	t.Run("responses that can't be stitched are queried again for the whole time range", func(t *testing.T) {
+		s := newPartialService(t)
+		ds := &fakeDataSource{}
+
+		from, to := now.Add(-6*time.Hour), now
+		_, _ = queryWithPartialCache(t, s, ds, newQueryRequest(from, to))
+
+		// The data source now returns a table, e.g. because the query changed its format.
+		ds.table = true
+		resp, _ := queryWithPartialCache(t, s, ds, newQueryRequest(from, to))
+		require.Len(t, ds.requests, 3)
+		assert.Equal(t, backend.TimeRange{From: from, To: to}, ds.requests[2])
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		assert.Equal(t, int64(361), resp.Responses["A"].Frames[0].Fields[0].At(0))
+	})
+
+	t.Run("chunks are rounded up to a multiple of the interval", func(t *testing.T) {
+		s := newPartialService(t)
+		assert.Equal(t, 35*time.Minute, s.chunkSize(backend.DataQuery{Interval: 7 * time.Minute}))
+		assert.Equal(t, 30*time.Minute, s.chunkSize(backend.DataQuery{Interval: 10 * time.Minute}))
+	})
+}
+
+func TestStitchFrames(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := func(host string, offsets ...int) *data.Frame {
+		times := make([]time.Time, 0, len(offsets))
+		values := make([]float64, 0, len(offsets))
+		for _, o := range offsets {
+			times = append(times, t0.Add(time.Duration(o)*time.Minute))
+			values = append(values, float64(o))
+		}
+		return data.NewFrame("", data.NewField("time", nil, times), data.NewField("value", data.Labels{"host": host}, values))
+	}
+
+	frames := stitchFrames([]data.Frames{
+		{series("a", 0, 1), series("b", 0)},
+		{series("a", 2), series("c", 2)},
+	})
+	require.Len(t, frames, 3)
+	assert.Equal(t, 3, frames[0].Fields[0].Len())
+	assert.Equal(t, data.Labels{"host": "a"}, frames[0].Fields[1].Labels)
+	assert.Equal(t, 1, frames[1].Fields[0].Len())
+	assert.Equal(t, data.Labels{"host": "c"}, frames[2].Fields[1].Labels)
+}
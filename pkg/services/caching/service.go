@@ -2,17 +2,32 @@ package caching
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
 	XCacheHeader   = "X-Cache"
 	StatusHit      = "HIT"
 	StatusMiss     = "MISS"
+	StatusPartial  = "PARTIAL"
 	StatusBypass   = "BYPASS"
 	StatusError    = "ERROR"
 	StatusDisabled = "DISABLED"
+
+	// XCacheSkipHeader can be set by clients to bypass the cache for a request.
+	XCacheSkipHeader = "X-Cache-Skip"
 )
 
 type CacheQueryResponseFn func(context.Context, *backend.QueryDataResponse)
@@ -35,8 +50,25 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
+type CachedPartialQueryDataResponse struct {
+	// The request that still has to be sent to the data source. Its queries only cover the parts of the original
+	// time ranges that are not cached.
+	Request *backend.QueryDataRequest
+	// A function that stitches the response to Request together with the cached results, caches the newly
+	// queried parts and returns the response to the original request.
+	// Queries whose response can't be stitched to their cached results are left out of the response. They are
+	// returned in a second request, over their original time range, whose responses must be added to the response.
+	// It is nil if the request can't be split, in which case Request is the original request and its response must be used as is.
+	MergeFn func(context.Context, *backend.QueryDataResponse) (*backend.QueryDataResponse, *backend.QueryDataRequest)
+}
+
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage) *OSSCachingService {
+	return &OSSCachingService{
+		cfg:   cfg.QueryCaching,
+		cache: cache,
+		log:   log.New("caching"),
+		nowFn: time.Now,
+	}
 }
 
 type CachingService interface {
@@ -47,18 +79,201 @@ type CachingService interface {
 	// HandleResourceRequest uses a CallResourceRequest to check the cache for any existing results for that request. If none are found, it should return false.
 	// This function may populate any response headers (accessible through the context) with the cache status using the X-Cache header.
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
+	// HandlePartialQueryRequest splits the time range of every query into aligned chunks and looks them up in the cache.
+	// It returns a request for the parts that are not cached, and a function to stitch its response together with the cached chunks.
+	// This function may populate any response headers (accessible through the context) with the cache status using the X-Cache header.
+	HandlePartialQueryRequest(context.Context, *backend.QueryDataRequest) CachedPartialQueryDataResponse
 }
 
-// Implementation of interface - does nothing
+// OSSCachingService caches query and resource responses in the remote cache.
+// The zero value is a valid, disabled service that never returns a hit.
 type OSSCachingService struct {
+	cfg   setting.QueryCachingSettings
+	cache remotecache.CacheStorage
+	log   log.Logger
+	nowFn func() time.Time
+}
+
+func (s *OSSCachingService) enabled() bool {
+	return s.cfg.Enabled && s.cache != nil
+}
+
+func (s *OSSCachingService) now() time.Time {
+	if s.nowFn != nil {
+		return s.nowFn()
+	}
+	return time.Now()
+}
+
+// queryTTL returns the time to live of query responses for a data source.
+func (s *OSSCachingService) queryTTL(ds *backend.DataSourceInstanceSettings) time.Duration {
+	if ttl, ok := s.cfg.DataSourceTTLs[ds.UID]; ok {
+		return ttl
+	}
+	return s.cfg.TTL
 }
 
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	if !s.enabled() || req == nil || req.PluginContext.DataSourceInstanceSettings == nil || skipQueryCache(ctx) {
+		return false, CachedQueryDataResponse{}
+	}
+	ds := req.PluginContext.DataSourceInstanceSettings
+
+	ttl := s.queryTTL(ds)
+	if ttl <= 0 || skipCache(ctx, req.GetHTTPHeader(XCacheSkipHeader)) || forwardsUserIdentity(ds) {
+		setCacheStatus(ctx, StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := QueryCacheKey(req)
+	if err != nil {
+		s.log.FromContext(ctx).Debug("Failed to compute query cache key", "datasource", ds.UID, "error", err)
+		setCacheStatus(ctx, StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+
+	b, err := s.cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		resp := &backend.QueryDataResponse{}
+		if err := json.Unmarshal(b, resp); err == nil {
+			setCacheStatus(ctx, StatusHit)
+			return true, CachedQueryDataResponse{Response: resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode cached query response", "datasource", ds.UID, "error", err)
+	case !errors.Is(err, remotecache.ErrCacheItemNotFound):
+		s.log.FromContext(ctx).Error("Failed to read query response from cache", "datasource", ds.UID, "error", err)
+		setCacheStatus(ctx, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	setCacheStatus(ctx, StatusMiss)
+	return false, CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.QueryDataResponse) {
+			if resp == nil || hasErrors(resp) {
+				return
+			}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				s.log.FromContext(ctx).Warn("Failed to encode query response for cache", "datasource", ds.UID, "error", err)
+				return
+			}
+			s.set(ctx, key, b, ttl)
+		},
+	}
 }
 
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
-	return false, CachedResourceDataResponse{}
+	if !s.enabled() || req == nil {
+		return false, CachedResourceDataResponse{}
+	}
+
+	ttl := s.cfg.ResourcesTTL
+	ds := req.PluginContext.DataSourceInstanceSettings
+	if ttl <= 0 || req.Method != http.MethodGet || skipCache(ctx, req.GetHTTPHeader(XCacheSkipHeader)) || (ds != nil && forwardsUserIdentity(ds)) {
+		setCacheStatus(ctx, StatusBypass)
+		return false, CachedResourceDataResponse{}
+	}
+
+	key := ResourceCacheKey(req)
+	b, err := s.cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		resp := &backend.CallResourceResponse{}
+		if err := json.Unmarshal(b, resp); err == nil {
+			setCacheStatus(ctx, StatusHit)
+			return true, CachedResourceDataResponse{Response: resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode cached resource response", "plugin", req.PluginContext.PluginID, "error", err)
+	case !errors.Is(err, remotecache.ErrCacheItemNotFound):
+		s.log.FromContext(ctx).Error("Failed to read resource response from cache", "plugin", req.PluginContext.PluginID, "error", err)
+		setCacheStatus(ctx, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	setCacheStatus(ctx, StatusMiss)
+
+	// A streamed response can't be represented by a single cached response, so only the first
+	// response is cached and the entry is dropped again if the plugin sends more.
+	var mu sync.Mutex
+	calls := 0
+	return false, CachedResourceDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.CallResourceResponse) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls > 1 {
+				if err := s.cache.Delete(ctx, key); err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+					s.log.FromContext(ctx).Warn("Failed to delete streamed resource response from cache", "error", err)
+				}
+				return
+			}
+			if resp == nil || resp.Status < http.StatusOK || resp.Status >= http.StatusMultipleChoices {
+				return
+			}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				s.log.FromContext(ctx).Warn("Failed to encode resource response for cache", "plugin", req.PluginContext.PluginID, "error", err)
+				return
+			}
+			s.set(ctx, key, b, ttl)
+		},
+	}
+}
+
+func (s *OSSCachingService) set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if s.cfg.MaxValueSize > 0 && len(value) > s.cfg.MaxValueSize {
+		s.log.FromContext(ctx).Debug("Response too large to cache", "size", len(value), "limit", s.cfg.MaxValueSize)
+		return
+	}
+	if err := s.cache.Set(ctx, key, value, ttl); err != nil {
+		s.log.FromContext(ctx).Error("Failed to write response to cache", "error", err)
+	}
+}
+
+// setCacheStatus writes the cache status to the X-Cache header of the HTTP response, if any.
+func setCacheStatus(ctx context.Context, status string) {
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.Resp == nil {
+		return
+	}
+	reqCtx.Resp.Header().Set(XCacheHeader, status)
+}
+
+// skipCache reports whether the client asked to bypass the cache with the X-Cache-Skip header,
+// either on the plugin request or on the HTTP request that triggered it.
+func skipCache(ctx context.Context, header string) bool {
+	if header == "" {
+		if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Req != nil {
+			header = reqCtx.Req.Header.Get(XCacheSkipHeader)
+		}
+	}
+	skip, _ := strconv.ParseBool(header)
+	return skip
+}
+
+// forwardsUserIdentity reports whether the data source queries with the identity of the signed in user,
+// in which case responses must not be shared between users.
+func forwardsUserIdentity(ds *backend.DataSourceInstanceSettings) bool {
+	var jsonData struct {
+		OAuthPassThru bool `json:"oauthPassThru"`
+	}
+	if len(ds.JSONData) == 0 {
+		return false
+	}
+	if err := json.Unmarshal(ds.JSONData, &jsonData); err != nil {
+		return false
+	}
+	return jsonData.OAuthPassThru
+}
+
+func hasErrors(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return true
+		}
+	}
+	return false
 }
 
 var _ CachingService = &OSSCachingService{}
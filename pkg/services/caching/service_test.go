@@ -0,0 +1,249 @@
+package caching
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func newTestService(t *testing.T, mutators ...func(*setting.QueryCachingSettings)) (*OSSCachingService, remotecache.FakeCacheStorage) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.QueryCaching = setting.QueryCachingSettings{
+		Enabled:        true,
+		TTL:            time.Minute,
+		ResourcesTTL:   time.Minute,
+		DataSourceTTLs: map[string]time.Duration{},
+	}
+	for _, m := range mutators {
+		m(&cfg.QueryCaching)
+	}
+	store := remotecache.NewFakeCacheStorage()
+	return ProvideCachingService(cfg, store), store
+}
+
+func newReqContext(t *testing.T, headers map[string]string) (context.Context, http.ResponseWriter) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/ds/query", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp := web.NewResponseWriter(req.Method, httptest.NewRecorder())
+	reqCtx := &contextmodel.ReqContext{Context: &web.Context{Req: req, Resp: resp}}
+	return ctxkey.Set(context.Background(), reqCtx), resp
+}
+
+func newQueryRequest(from, to time.Time) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:  "ds-uid",
+				Type: "postgres",
+			},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			Interval:  time.Minute,
+			TimeRange: backend.TimeRange{From: from, To: to},
+			JSON:      []byte(`{"refId":"A","rawSql":"SELECT 1","requestId":"Q100"}`),
+		}},
+	}
+}
+
+func TestOSSCachingService_HandleQueryRequest(t *testing.T) {
+	to := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	from := to.Add(-6 * time.Hour)
+
+	t.Run("disabled service never hits and sets no header", func(t *testing.T) {
+		s := &OSSCachingService{}
+		ctx, resp := newReqContext(t, nil)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Empty(t, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("miss stores the response and a later request within the interval hits", func(t *testing.T) {
+		s, store := newTestService(t)
+
+		ctx, resp := newReqContext(t, nil)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		require.False(t, hit)
+		require.NotNil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusMiss, resp.Header().Get(XCacheHeader))
+
+		frame := data.NewFrame("", data.NewField("value", nil, []float64{1, 2, 3}))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.DataResponse{Frames: data.Frames{frame}},
+		}})
+		require.Len(t, store.Storage, 1)
+
+		ctx, resp = newReqContext(t, nil)
+		req := newQueryRequest(from.Add(20*time.Second), to.Add(20*time.Second))
+		req.Queries[0].JSON = []byte(`{"rawSql":"SELECT 1","refId":"A","requestId":"Q101"}`)
+		hit, cr = s.HandleQueryRequest(ctx, req)
+		require.True(t, hit)
+		assert.Equal(t, StatusHit, resp.Header().Get(XCacheHeader))
+		require.Contains(t, cr.Response.Responses, "A")
+		require.Len(t, cr.Response.Responses["A"].Frames, 1)
+		v, ok := cr.Response.Responses["A"].Frames[0].Fields[0].ConcreteAt(2)
+		require.True(t, ok)
+		assert.Equal(t, 3.0, v)
+	})
+
+	t.Run("request in the next interval misses", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, _ := newReqContext(t, nil)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{}}})
+
+		ctx, resp := newReqContext(t, nil)
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest(from.Add(time.Minute), to.Add(time.Minute)))
+		assert.False(t, hit)
+		assert.Equal(t, StatusMiss, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		s, store := newTestService(t)
+		ctx, _ := newReqContext(t, nil)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.ErrDataResponse(backend.StatusBadRequest, "boom"),
+		}})
+		assert.Empty(t, store.Storage)
+	})
+
+	t.Run("data source ttl of zero bypasses the cache", func(t *testing.T) {
+		s, _ := newTestService(t, func(c *setting.QueryCachingSettings) {
+			c.DataSourceTTLs["ds-uid"] = 0
+		})
+		ctx, resp := newReqContext(t, nil)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusBypass, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("X-Cache-Skip header bypasses the cache", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, resp := newReqContext(t, map[string]string{XCacheSkipHeader: "true"})
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusBypass, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("data sources forwarding the user identity bypass the cache", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, resp := newReqContext(t, nil)
+		req := newQueryRequest(from, to)
+		req.PluginContext.DataSourceInstanceSettings.JSONData = []byte(`{"oauthPassThru":true}`)
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		assert.False(t, hit)
+		assert.Equal(t, StatusBypass, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("responses larger than the max value size are not cached", func(t *testing.T) {
+		s, store := newTestService(t, func(c *setting.QueryCachingSettings) {
+			c.MaxValueSize = 10
+		})
+		ctx, _ := newReqContext(t, nil)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(from, to))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{}}})
+		assert.Empty(t, store.Storage)
+	})
+}
+
+func TestOSSCachingService_HandleResourceRequest(t *testing.T) {
+	newResourceRequest := func(method string) *backend.CallResourceRequest {
+		return &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{OrgID: 1, PluginID: "elasticsearch"},
+			Method:        method,
+			Path:          "_mapping",
+			URL:           "_mapping?index=logs",
+		}
+	}
+
+	t.Run("only the first response of a GET request is cached", func(t *testing.T) {
+		s, store := newTestService(t)
+		ctx, resp := newReqContext(t, nil)
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		require.False(t, hit)
+		assert.Equal(t, StatusMiss, resp.Header().Get(XCacheHeader))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`{}`)})
+		require.Len(t, store.Storage, 1)
+
+		ctx, resp = newReqContext(t, nil)
+		hit, cr = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		require.True(t, hit)
+		assert.Equal(t, StatusHit, resp.Header().Get(XCacheHeader))
+		assert.Equal(t, []byte(`{}`), cr.Response.Body)
+	})
+
+	t.Run("streamed responses are dropped from the cache", func(t *testing.T) {
+		s, store := newTestService(t)
+		ctx, _ := newReqContext(t, nil)
+		_, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`1`)})
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`2`)})
+		assert.Empty(t, store.Storage)
+	})
+
+	t.Run("non GET requests bypass the cache", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, resp := newReqContext(t, nil)
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodPost))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusBypass, resp.Header().Get(XCacheHeader))
+	})
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	to := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	base, err := QueryCacheKey(newQueryRequest(to.Add(-time.Hour), to))
+	require.NoError(t, err)
+
+	t.Run("differs by org", func(t *testing.T) {
+		req := newQueryRequest(to.Add(-time.Hour), to)
+		req.PluginContext.OrgID = 2
+		key, err := QueryCacheKey(req)
+		require.NoError(t, err)
+		assert.NotEqual(t, base, key)
+	})
+
+	t.Run("differs by query model", func(t *testing.T) {
+		req := newQueryRequest(to.Add(-time.Hour), to)
+		req.Queries[0].JSON = []byte(`{"refId":"A","rawSql":"SELECT 2"}`)
+		key, err := QueryCacheKey(req)
+		require.NoError(t, err)
+		assert.NotEqual(t, base, key)
+	})
+
+	t.Run("differs when the data source is updated", func(t *testing.T) {
+		req := newQueryRequest(to.Add(-time.Hour), to)
+		req.PluginContext.DataSourceInstanceSettings.Updated = to
+		key, err := QueryCacheKey(req)
+		require.NoError(t, err)
+		assert.NotEqual(t, base, key)
+	})
+
+	t.Run("fails without a data source", func(t *testing.T) {
+		_, err := QueryCacheKey(&backend.QueryDataRequest{})
+		require.Error(t, err)
+	})
+}
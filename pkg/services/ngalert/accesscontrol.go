@@ -91,6 +91,9 @@ var (
 					Action: accesscontrol.ActionAlertingInstancesExternalRead,
 					Scope:  datasources.ScopeAll,
 				},
+				{
+					Action: accesscontrol.ActionAlertingSilenceTemplatesRead,
+				},
 			},
 		},
 	}
@@ -112,6 +115,37 @@ var (
 					Action: accesscontrol.ActionAlertingInstancesExternalWrite,
 					Scope:  datasources.ScopeAll,
 				},
+				{
+					Action: accesscontrol.ActionAlertingSilenceTemplatesWrite,
+				},
+				{
+					Action: accesscontrol.ActionAlertingSilenceTemplatesDelete,
+				},
+			}),
+		},
+	}
+
+	silenceTemplatesReaderRole = accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        accesscontrol.FixedRolePrefix + "alerting.silence-templates:reader",
+			DisplayName: "Silence Templates Reader",
+			Description: "Read all silence templates and recurring silences in Grafana alerting",
+			Group:       AlertRolesGroup,
+			Permissions: []accesscontrol.Permission{
+				{Action: accesscontrol.ActionAlertingSilenceTemplatesRead},
+			},
+		},
+	}
+
+	silenceTemplatesWriterRole = accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        accesscontrol.FixedRolePrefix + "alerting.silence-templates:writer",
+			DisplayName: "Silence Templates Writer",
+			Description: "Create, update, and delete all silence templates and recurring silences in Grafana alerting",
+			Group:       AlertRolesGroup,
+			Permissions: accesscontrol.ConcatPermissions(silenceTemplatesReaderRole.Role.Permissions, []accesscontrol.Permission{
+				{Action: accesscontrol.ActionAlertingSilenceTemplatesWrite},
+				{Action: accesscontrol.ActionAlertingSilenceTemplatesDelete},
 			}),
 		},
 	}
@@ -379,6 +413,7 @@ func DeclareFixedRoles(service accesscontrol.Service, features featuremgmt.Featu
 	fixedRoles := []accesscontrol.RoleRegistration{
 		rulesReaderRole, rulesWriterRole,
 		instancesReaderRole, instancesWriterRole,
+		silenceTemplatesReaderRole, silenceTemplatesWriterRole,
 		notificationsReaderRole, notificationsWriterRole,
 		alertingReaderRole, alertingWriterRole, alertingAdminRole, alertingProvisionerRole, alertingProvisioningReaderWithSecretsRole, alertingProvisioningStatus,
 	}
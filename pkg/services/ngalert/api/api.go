@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -58,12 +59,15 @@ type API struct {
 	QuotaService         quota.Service
 	TransactionManager   provisioning.TransactionManager
 	ProvenanceStore      provisioning.ProvisioningStore
+	BacktestStore        backtesting.RunStore
 	RuleStore            RuleStore
 	AlertingStore        store.AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
+	InstanceStore        state.InstanceStore
+	StateRestores        *state.RestoreStore
 	Scheduler            StatusReader
 	AccessControl        ac.AccessControl
 	Policies             *provisioning.NotificationPolicyService
@@ -71,6 +75,7 @@ type API struct {
 	ContactPointService  *provisioning.ContactPointService
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
+	SilenceTemplates     *provisioning.SilenceTemplateService
 	AlertRules           *provisioning.AlertRuleService
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
@@ -80,6 +85,9 @@ type API struct {
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
 	UserService          user.Service
+	HTTPClientProvider   httpclient.Provider
+	// NotificationDeliveryStore is used to query the log of notification delivery attempts.
+	NotificationDeliveryStore NotificationDeliveryStore
 
 	// Hooks can be used to replace API handlers for specific paths.
 	Hooks *Hooks
@@ -93,6 +101,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		ac:        api.AccessControl,
 	}
 	ruleAuthzService := accesscontrol.NewRuleService(api.AccessControl)
+	backtestingEngine := backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Tracer)
 
 	// Register endpoints for proxying to Alertmanager-compatible backends.
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
@@ -138,6 +147,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			amRefresher:        api.MultiOrgAlertmanager,
 			featureManager:     api.FeatureManager,
 			userService:        api.UserService,
+			backtesting:        backtesting.NewRunner(backtestingEngine, api.BacktestStore),
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -148,7 +158,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			authz:           ruleAuthzService,
 			evaluator:       api.EvaluatorFactory,
 			cfg:             &api.Cfg.UnifiedAlerting,
-			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Tracer),
+			backtesting:     backtestingEngine,
 			featureManager:  api.FeatureManager,
 			appUrl:          api.AppUrl,
 			tracer:          api.Tracer,
@@ -162,6 +172,15 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			alertmanagerProvider: api.AlertsRouter,
 			featureManager:       api.FeatureManager,
 		},
+		&AlertStateSnapshotSrv{
+			xactManager:       api.TransactionManager,
+			instanceStore:     api.InstanceStore,
+			ruleStore:         api.RuleStore,
+			restores:          api.StateRestores,
+			alertmanagerState: api.MultiOrgAlertmanager,
+			featureManager:    api.FeatureManager,
+			now:               time.Now,
+		},
 	), m)
 
 	api.RegisterProvisioningApiEndpoints(NewProvisioningApi(&ProvisioningSrv{
@@ -170,6 +189,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		contactPointService: api.ContactPointService,
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
+		silenceTemplates:    api.SilenceTemplates,
 		alertRules:          api.AlertRules,
 		// XXX: Used to flag recording rules, remove when FT is removed
 		featureManager: api.FeatureManager,
@@ -184,11 +204,12 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		logger:            logger,
 		receiverService:   api.ReceiverService,
 		muteTimingService: api.MuteTimings,
+		deliveryStore:     api.NotificationDeliveryStore,
 	}), m)
 
 	if api.FeatureManager.IsEnabledGlobally(featuremgmt.FlagAlertingConversionAPI) {
 		api.RegisterConvertPrometheusApiEndpoints(NewConvertPrometheusApi(
-			NewConvertPrometheusSrv(&api.Cfg.UnifiedAlerting, logger, api.RuleStore, api.DatasourceCache, api.AlertRules),
+			NewConvertPrometheusSrv(&api.Cfg.UnifiedAlerting, logger, api.RuleStore, api.DatasourceCache, api.AlertRules, api.HTTPClientProvider),
 		), m)
 	}
 }
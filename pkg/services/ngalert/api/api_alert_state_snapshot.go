@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// AlertmanagerStateService exports and restores the silences and the notification log of the Alertmanager of an organization.
+type AlertmanagerStateService interface {
+	ExportState(ctx context.Context, orgID int64) (notifier.AlertmanagerState, error)
+	RestoreState(ctx context.Context, orgID int64, st notifier.AlertmanagerState, r notifier.StateRemapping) (notifier.RestoredAlertmanagerState, error)
+}
+
+// AlertStateSnapshotSrv exports the state of the alert instances and the Alertmanager of an organization
+// and restores it in another Grafana instance, for example when migrating to a new database.
+// The restored instances are recorded in the database, and every instance of the cluster loads the state
+// of the restored rules it evaluates at its next scheduler tick.
+type AlertStateSnapshotSrv struct {
+	xactManager       provisioning.TransactionManager
+	instanceStore     state.InstanceStore
+	ruleStore         RuleStore
+	restores          *state.RestoreStore
+	alertmanagerState AlertmanagerStateService
+	featureManager    featuremgmt.FeatureToggles
+	now               func() time.Time
+}
+
+func (srv AlertStateSnapshotSrv) RouteGetAlertStateSnapshot(c *contextmodel.ReqContext) response.Response {
+	if c.SignedInUser.GetOrgRole() != org.RoleAdmin {
+		return accessForbiddenResp()
+	}
+	orgID := c.SignedInUser.GetOrgID()
+
+	instances, err := srv.instanceStore.ListAlertInstances(c.Req.Context(), &ngmodels.ListAlertInstancesQuery{RuleOrgID: orgID})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to list alert instances")
+	}
+	amState, err := srv.alertmanagerState.ExportState(c.Req.Context(), orgID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to export Alertmanager state", err)
+	}
+
+	snapshot := apimodels.AlertStateSnapshot{
+		Version:         apimodels.AlertStateSnapshotVersion,
+		OrgID:           orgID,
+		CreatedAt:       srv.now().UTC(),
+		Instances:       make([]apimodels.AlertInstanceSnapshot, 0, len(instances)),
+		Silences:        amState.Silences,
+		NotificationLog: amState.NotificationLog,
+	}
+	for _, instance := range instances {
+		snapshot.Instances = append(snapshot.Instances, apimodels.AlertInstanceSnapshot{
+			RuleUID:           instance.RuleUID,
+			Labels:            instance.Labels,
+			State:             string(instance.CurrentState),
+			StateReason:       instance.CurrentReason,
+			StateSince:        instance.CurrentStateSince,
+			StateEnd:          instance.CurrentStateEnd,
+			LastEvalTime:      instance.LastEvalTime,
+			LastSentAt:        instance.LastSentAt,
+			ResolvedAt:        instance.ResolvedAt,
+			ResultFingerprint: instance.ResultFingerprint,
+		})
+	}
+	return response.JSON(http.StatusOK, snapshot)
+}
+
+func (srv AlertStateSnapshotSrv) RoutePostAlertStateRestore(c *contextmodel.ReqContext, body apimodels.AlertStateRestoreRequest) response.Response {
+	if c.SignedInUser.GetOrgRole() != org.RoleAdmin {
+		return accessForbiddenResp()
+	}
+	if body.Snapshot.Version != apimodels.AlertStateSnapshotVersion {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unsupported snapshot version %d", body.Snapshot.Version), "")
+	}
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	targetUID := func(uid string) string {
+		if to, ok := body.RuleUIDs[uid]; ok {
+			return to
+		}
+		return uid
+	}
+	ruleUIDs := make([]string, 0, len(body.Snapshot.Instances))
+	for _, instance := range body.Snapshot.Instances {
+		ruleUIDs = append(ruleUIDs, targetUID(instance.RuleUID))
+	}
+	rules, err := srv.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID, RuleUIDs: ruleUIDs})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to fetch alert rules")
+	}
+	rulesByUID := make(map[string]*ngmodels.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByUID[rule.UID] = rule
+	}
+
+	result := apimodels.AlertStateRestoreResult{DryRun: body.DryRun}
+	remapping := notifier.StateRemapping{LabelValues: map[string]map[string]string{
+		alertingModels.RuleUIDLabel:      {},
+		alertingModels.NamespaceUIDLabel: {},
+	}}
+	missing := map[string]struct{}{}
+	instancesByRule := map[string][]ngmodels.AlertInstance{}
+	for _, s := range body.Snapshot.Instances {
+		rule, ok := rulesByUID[targetUID(s.RuleUID)]
+		if !ok {
+			missing[s.RuleUID] = struct{}{}
+			result.SkippedInstances++
+			continue
+		}
+		instance, err := restoredAlertInstance(s, rule, &remapping)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid alert instance of rule %s", s.RuleUID)
+		}
+		instancesByRule[rule.UID] = append(instancesByRule[rule.UID], instance)
+		result.Instances++
+	}
+	for uid := range missing {
+		result.MissingRules = append(result.MissingRules, uid)
+	}
+	sort.Strings(result.MissingRules)
+
+	// The whole snapshot is validated before anything is written.
+	amState := notifier.AlertmanagerState{Silences: body.Snapshot.Silences, NotificationLog: body.Snapshot.NotificationLog}
+	if _, result.Silences, err = notifier.RemapSilences(amState.Silences, remapping); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid silences")
+	}
+	if _, result.NotificationLogEntries, err = notifier.RemapNotificationLog(amState.NotificationLog, remapping); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid notification log")
+	}
+	if body.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	restoredRules := make([]string, 0, len(instancesByRule))
+	for uid := range instancesByRule {
+		restoredRules = append(restoredRules, uid)
+	}
+	sort.Strings(restoredRules)
+	err = srv.xactManager.InTransaction(ctx, func(ctx context.Context) error {
+		for _, uid := range restoredRules {
+			if err := srv.saveInstances(ctx, rulesByUID[uid], instancesByRule[uid]); err != nil {
+				return fmt.Errorf("failed to save alert instances of rule %s: %w", uid, err)
+			}
+		}
+		if err := srv.restores.Record(ctx, orgID, restoredRules); err != nil {
+			return fmt.Errorf("failed to record the restore: %w", err)
+		}
+		// The Alertmanager is restored last, so a failure to save the instances does not leave it with a partial state.
+		restored, err := srv.alertmanagerState.RestoreState(ctx, orgID, amState, remapping)
+		if err != nil {
+			return fmt.Errorf("failed to restore Alertmanager state: %w", err)
+		}
+		result.Silences = restored.Silences
+		result.NotificationLogEntries = restored.NotificationLogEntries
+		return nil
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to restore alert state", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// restoredAlertInstance converts an alert instance from a snapshot to an instance of the rule in this Grafana instance.
+// The built-in labels of the rule are replaced and recorded in the remapping, so the Alertmanager state can be remapped as well.
+func restoredAlertInstance(s apimodels.AlertInstanceSnapshot, rule *ngmodels.AlertRule, remapping *notifier.StateRemapping) (ngmodels.AlertInstance, error) {
+	labels := make(ngmodels.InstanceLabels, len(s.Labels))
+	alert := make(model.LabelSet, len(s.Labels))
+	for k, v := range s.Labels {
+		labels[k] = v
+		alert[model.LabelName(k)] = model.LabelValue(v)
+	}
+	remapping.Alerts = append(remapping.Alerts, alert)
+	if s.RuleUID != rule.UID {
+		remapping.LabelValues[alertingModels.RuleUIDLabel][s.RuleUID] = rule.UID
+	}
+	if ns, ok := labels[alertingModels.NamespaceUIDLabel]; ok && ns != rule.NamespaceUID {
+		remapping.LabelValues[alertingModels.NamespaceUIDLabel][ns] = rule.NamespaceUID
+		labels[alertingModels.NamespaceUIDLabel] = rule.NamespaceUID
+	}
+	labels[alertingModels.RuleUIDLabel] = rule.UID
+
+	_, hash, err := labels.StringAndHash()
+	if err != nil {
+		return ngmodels.AlertInstance{}, err
+	}
+	instance := ngmodels.AlertInstance{
+		AlertInstanceKey: ngmodels.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: hash,
+		},
+		Labels:            labels,
+		CurrentState:      ngmodels.InstanceStateType(s.State),
+		CurrentReason:     s.StateReason,
+		CurrentStateSince: s.StateSince,
+		CurrentStateEnd:   s.StateEnd,
+		LastEvalTime:      s.LastEvalTime,
+		LastSentAt:        s.LastSentAt,
+		ResolvedAt:        s.ResolvedAt,
+		ResultFingerprint: s.ResultFingerprint,
+	}
+	if err := ngmodels.ValidateAlertInstance(instance); err != nil {
+		return ngmodels.AlertInstance{}, err
+	}
+	return instance, nil
+}
+
+// saveInstances stores the restored instances of a rule in addition to its current instances.
+// Restored instances replace current instances with the same labels.
+func (srv AlertStateSnapshotSrv) saveInstances(ctx context.Context, rule *ngmodels.AlertRule, instances []ngmodels.AlertInstance) error {
+	// The compressed instance store only supports replacing all instances of a rule.
+	if !srv.featureManager.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStateCompressed) {
+		for _, instance := range instances {
+			if err := srv.instanceStore.SaveAlertInstance(ctx, instance); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	current, err := srv.instanceStore.ListAlertInstances(ctx, &ngmodels.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+	if err != nil {
+		return err
+	}
+	byHash := make(map[string]ngmodels.AlertInstance, len(current)+len(instances))
+	for _, instance := range current {
+		byHash[instance.LabelsHash] = *instance
+	}
+	for _, instance := range instances {
+		byHash[instance.LabelsHash] = instance
+	}
+	merged := make([]ngmodels.AlertInstance, 0, len(byHash))
+	for _, instance := range byHash {
+		merged = append(merged, instance)
+	}
+	return srv.instanceStore.SaveAlertInstancesForRule(ctx, rule.GetKeyWithGroup(), merged)
+}
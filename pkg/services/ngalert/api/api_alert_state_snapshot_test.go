@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestRestoredAlertInstance(t *testing.T) {
+	rule := &ngmodels.AlertRule{OrgID: 3, UID: "new-uid", NamespaceUID: "new-folder"}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := apimodels.AlertInstanceSnapshot{
+		RuleUID: "old-uid",
+		Labels: map[string]string{
+			model.AlertNameLabel:             "rule",
+			alertingModels.RuleUIDLabel:      "old-uid",
+			alertingModels.NamespaceUIDLabel: "old-folder",
+		},
+		State:        string(ngmodels.InstanceStateFiring),
+		StateSince:   since,
+		LastEvalTime: since.Add(time.Minute),
+	}
+	remapping := notifier.StateRemapping{LabelValues: map[string]map[string]string{
+		alertingModels.RuleUIDLabel:      {},
+		alertingModels.NamespaceUIDLabel: {},
+	}}
+
+	instance, err := restoredAlertInstance(snapshot, rule, &remapping)
+	require.NoError(t, err)
+
+	expectedLabels := ngmodels.InstanceLabels{
+		model.AlertNameLabel:             "rule",
+		alertingModels.RuleUIDLabel:      "new-uid",
+		alertingModels.NamespaceUIDLabel: "new-folder",
+	}
+	_, hash, err := expectedLabels.StringAndHash()
+	require.NoError(t, err)
+	require.Equal(t, ngmodels.AlertInstanceKey{RuleOrgID: 3, RuleUID: "new-uid", LabelsHash: hash}, instance.AlertInstanceKey)
+	require.Equal(t, expectedLabels, instance.Labels)
+	require.Equal(t, ngmodels.InstanceStateFiring, instance.CurrentState)
+	require.Equal(t, since, instance.CurrentStateSince)
+
+	// The snapshot is not modified.
+	require.Equal(t, "old-uid", snapshot.Labels[alertingModels.RuleUIDLabel])
+
+	require.Equal(t, map[string]map[string]string{
+		alertingModels.RuleUIDLabel:      {"old-uid": "new-uid"},
+		alertingModels.NamespaceUIDLabel: {"old-folder": "new-folder"},
+	}, remapping.LabelValues)
+	require.Len(t, remapping.Alerts, 1)
+	require.Equal(t, model.LabelValue("old-uid"), remapping.Alerts[0][alertingModels.RuleUIDLabel])
+
+	t.Run("should fail if the state is invalid", func(t *testing.T) {
+		s := snapshot
+		s.State = "Unknown"
+		_, err := restoredAlertInstance(s, rule, &remapping)
+		require.Error(t, err)
+	})
+}
+
+type fakeAlertmanagerState struct {
+	restored int
+}
+
+func (f *fakeAlertmanagerState) ExportState(context.Context, int64) (notifier.AlertmanagerState, error) {
+	return notifier.AlertmanagerState{}, nil
+}
+
+func (f *fakeAlertmanagerState) RestoreState(context.Context, int64, notifier.AlertmanagerState, notifier.StateRemapping) (notifier.RestoredAlertmanagerState, error) {
+	f.restored++
+	return notifier.RestoredAlertmanagerState{}, nil
+}
+
+func TestRoutePostAlertStateRestore(t *testing.T) {
+	const orgID = 1
+	rule := ngmodels.RuleGen.With(ngmodels.RuleGen.WithOrgID(orgID)).GenerateRef()
+
+	setup := func(t *testing.T) (AlertStateSnapshotSrv, *state.FakeInstanceStore, *fakeAlertmanagerState, *kvstore.FakeKVStore) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.PutRule(context.Background(), rule)
+		instanceStore := &state.FakeInstanceStore{}
+		amState := &fakeAlertmanagerState{}
+		kv := kvstore.NewFakeKVStore()
+		srv := AlertStateSnapshotSrv{
+			xactManager:       ruleStore,
+			instanceStore:     instanceStore,
+			ruleStore:         ruleStore,
+			restores:          state.NewRestoreStore(kv),
+			alertmanagerState: amState,
+			featureManager:    featuremgmt.WithFeatures(),
+			now:               time.Now,
+		}
+		return srv, instanceStore, amState, kv
+	}
+	request := func() apimodels.AlertStateRestoreRequest {
+		return apimodels.AlertStateRestoreRequest{Snapshot: apimodels.AlertStateSnapshot{
+			Version: apimodels.AlertStateSnapshotVersion,
+			Instances: []apimodels.AlertInstanceSnapshot{{
+				RuleUID:    rule.UID,
+				Labels:     map[string]string{model.AlertNameLabel: rule.Title},
+				State:      string(ngmodels.InstanceStateFiring),
+				StateSince: time.Now(),
+			}},
+		}}
+	}
+	reqCtx := func() *contextmodel.ReqContext {
+		c := createRequestCtxInOrg(orgID)
+		c.SignedInUser.OrgRole = org.RoleAdmin
+		return c
+	}
+
+	t.Run("should restore instances and record the restore", func(t *testing.T) {
+		srv, instanceStore, amState, kv := setup(t)
+
+		resp := srv.RoutePostAlertStateRestore(reqCtx(), request())
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Len(t, instanceStore.RecordedOps(), 1)
+		require.Equal(t, 1, amState.restored)
+		_, ok, err := kv.Get(context.Background(), orgID, "alerting.state", "restored_rules")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("should not write anything if the snapshot is invalid", func(t *testing.T) {
+		srv, instanceStore, amState, kv := setup(t)
+		body := request()
+		body.Snapshot.Silences = []byte("invalid")
+
+		resp := srv.RoutePostAlertStateRestore(reqCtx(), body)
+
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		require.Empty(t, instanceStore.RecordedOps())
+		require.Zero(t, amState.restored)
+		_, ok, err := kv.Get(context.Background(), orgID, "alerting.state", "restored_rules")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
@@ -304,6 +304,47 @@ func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, bo
 	return response.JSON(http.StatusOK, newTestTemplateResult(res))
 }
 
+func (srv AlertmanagerSrv) RoutePostRoutingSimulation(c *contextmodel.ReqContext, body apimodels.RoutingSimulationRequest) response.Response {
+	if len(body.Labels) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("labels must not be empty"), "")
+	}
+	if err := body.Labels.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid labels")
+	}
+	at := time.Now()
+	if body.Time != nil {
+		at = *body.Time
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
+	if errResp != nil {
+		return errResp
+	}
+
+	// The configuration is read from the database, which is the source of truth for both the internal and the remote Alertmanager.
+	cfg, err := srv.mam.GetAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID(), true)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the Alertmanager configuration")
+	}
+	silences, err := am.ListSilences(c.Req.Context(), nil)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to list silences")
+	}
+	alerts, err := am.GetAlerts(c.Req.Context(), true, true, true, nil, "")
+	if err != nil {
+		if errors.Is(err, alertingNotify.ErrGetAlertsUnavailable) {
+			return ErrResp(http.StatusServiceUnavailable, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alerts")
+	}
+
+	res, err := notifier.SimulateRouting(cfg.AlertmanagerConfig.Config, silences, alerts, body.Labels, at)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to simulate routing")
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
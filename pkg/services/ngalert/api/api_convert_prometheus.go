@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -59,20 +60,22 @@ func errInvalidHeaderValue(header string) error {
 // When a rule group is converted from Prometheus to Grafana, the original definition is preserved alongside
 // the Grafana rule and used for reading requests here.
 type ConvertPrometheusSrv struct {
-	cfg              *setting.UnifiedAlertingSettings
-	logger           log.Logger
-	ruleStore        RuleStore
-	datasourceCache  datasources.CacheService
-	alertRuleService *provisioning.AlertRuleService
+	cfg                *setting.UnifiedAlertingSettings
+	logger             log.Logger
+	ruleStore          RuleStore
+	datasourceCache    datasources.CacheService
+	alertRuleService   *provisioning.AlertRuleService
+	httpClientProvider httpclient.Provider
 }
 
-func NewConvertPrometheusSrv(cfg *setting.UnifiedAlertingSettings, logger log.Logger, ruleStore RuleStore, datasourceCache datasources.CacheService, alertRuleService *provisioning.AlertRuleService) *ConvertPrometheusSrv {
+func NewConvertPrometheusSrv(cfg *setting.UnifiedAlertingSettings, logger log.Logger, ruleStore RuleStore, datasourceCache datasources.CacheService, alertRuleService *provisioning.AlertRuleService, httpClientProvider httpclient.Provider) *ConvertPrometheusSrv {
 	return &ConvertPrometheusSrv{
-		cfg:              cfg,
-		logger:           logger,
-		ruleStore:        ruleStore,
-		datasourceCache:  datasourceCache,
-		alertRuleService: alertRuleService,
+		cfg:                cfg,
+		logger:             logger,
+		ruleStore:          ruleStore,
+		datasourceCache:    datasourceCache,
+		alertRuleService:   alertRuleService,
+		httpClientProvider: httpClientProvider,
 	}
 }
 
@@ -307,19 +310,7 @@ func (srv *ConvertPrometheusSrv) convertToGrafanaRuleGroup(c *contextmodel.ReqCo
 		return nil, err
 	}
 
-	converter, err := prom.NewConverter(
-		prom.Config{
-			DatasourceUID:   ds.UID,
-			DatasourceType:  ds.Type,
-			DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
-			RecordingRules: prom.RulesConfig{
-				IsPaused: pauseRecordingRules,
-			},
-			AlertRules: prom.RulesConfig{
-				IsPaused: pauseAlertRules,
-			},
-		},
-	)
+	converter, err := srv.newConverter(ds, pauseRecordingRules, pauseAlertRules)
 	if err != nil {
 		logger.Error("Failed to create Prometheus converter", "datasource_uid", ds.UID, "datasource_type", ds.Type, "error", err)
 		return nil, err
@@ -334,6 +325,22 @@ func (srv *ConvertPrometheusSrv) convertToGrafanaRuleGroup(c *contextmodel.ReqCo
 	return grafanaGroup, nil
 }
 
+func (srv *ConvertPrometheusSrv) newConverter(ds *datasources.DataSource, pauseRecordingRules, pauseAlertRules bool) (*prom.Converter, error) {
+	return prom.NewConverter(
+		prom.Config{
+			DatasourceUID:   ds.UID,
+			DatasourceType:  ds.Type,
+			DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+			RecordingRules: prom.RulesConfig{
+				IsPaused: pauseRecordingRules,
+			},
+			AlertRules: prom.RulesConfig{
+				IsPaused: pauseAlertRules,
+			},
+		},
+	)
+}
+
 // parseBooleanHeader parses a boolean header value, returning an error if the header
 // is present but invalid. If the header is not present, returns (false, nil).
 func parseBooleanHeader(header string, headerName string) (bool, error) {
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+)
+
+var (
+	errImportRulerURLMissing = errutil.ValidationFailed(
+		"alerting.importRulerURLMissing",
+		errutil.WithPublicMessage("Missing ruler URL"),
+	).Errorf("missing ruler URL")
+
+	errImportDatasourceUIDMissing = errutil.ValidationFailed(
+		"alerting.importDatasourceUIDMissing",
+		errutil.WithPublicMessage("Missing datasource UID"),
+	).Errorf("missing datasource UID")
+
+	errImportInvalidRulerMsg  = "Invalid ruler: {{.Public.Error}}"
+	errImportInvalidRulerBase = errutil.ValidationFailed("alerting.importInvalidRuler").MustTemplate(errImportInvalidRulerMsg, errutil.WithPublic(errImportInvalidRulerMsg))
+
+	errImportRulerRequestFailedMsg  = "Failed to read rules from the ruler: {{.Public.Error}}"
+	errImportRulerRequestFailedBase = errutil.BadGateway("alerting.importRulerRequestFailed").MustTemplate(errImportRulerRequestFailedMsg, errutil.WithPublic(errImportRulerRequestFailedMsg))
+)
+
+func errImportInvalidRuler(err error) error {
+	return errImportInvalidRulerBase.Build(errutil.TemplateData{Public: map[string]any{"Error": err.Error()}, Error: err})
+}
+
+func errImportRulerRequestFailed(err error) error {
+	return errImportRulerRequestFailedBase.Build(errutil.TemplateData{Public: map[string]any{"Error": err.Error()}, Error: err})
+}
+
+// RouteConvertPrometheusImportRuler reads the rule groups of all namespaces from a Prometheus-compatible ruler
+// and converts them to Grafana rule groups in folders with the titles of the namespaces.
+//
+// The response contains the change of every rule and warnings about what cannot be converted.
+// Unless the request applies the import, nothing is changed. Otherwise, every changed group is replaced in the
+// same way as by RouteConvertPrometheusPostRuleGroup, so importing the same rules again does not change anything.
+// Groups that were imported before but do not exist in the ruler anymore are marked as removed upstream,
+// and are deleted only if the request asks for it.
+func (srv *ConvertPrometheusSrv) RouteConvertPrometheusImportRuler(c *contextmodel.ReqContext, body apimodels.ConvertPrometheusImportRulerRequest) response.Response {
+	logger := srv.logger.FromContext(c.Req.Context()).New("ruler_url", body.RulerURL, "tenant", body.TenantID, "apply", body.Apply)
+
+	if strings.TrimSpace(body.RulerURL) == "" {
+		return response.Err(errImportRulerURLMissing)
+	}
+	datasourceUID := strings.TrimSpace(body.DatasourceUID)
+	if datasourceUID == "" {
+		return response.Err(errImportDatasourceUIDMissing)
+	}
+	ds, err := srv.datasourceCache.GetDatasourceByUID(c.Req.Context(), datasourceUID, c.SignedInUser, c.SkipDSCache)
+	if err != nil {
+		logger.Error("Failed to get datasource", "datasource_uid", datasourceUID, "error", err)
+		return errorToResponse(err)
+	}
+	converter, err := srv.newConverter(ds, body.RecordingRulesPaused, body.AlertRulesPaused)
+	if err != nil {
+		logger.Error("Failed to create Prometheus converter", "datasource_uid", ds.UID, "datasource_type", ds.Type, "error", err)
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	httpClient, err := srv.httpClientProvider.New()
+	if err != nil {
+		logger.Error("Failed to create HTTP client", "error", err)
+		return errorToResponse(err)
+	}
+	client, err := prom.NewRulerClient(prom.RulerClientConfig{
+		URL:               body.RulerURL,
+		RulesPath:         body.RulesPath,
+		TenantID:          body.TenantID,
+		BasicAuthUser:     body.BasicAuthUser,
+		BasicAuthPassword: body.BasicAuthPassword,
+		Headers:           body.Headers,
+	}, httpClient)
+	if err != nil {
+		return response.Err(errImportInvalidRuler(err))
+	}
+
+	upstream, err := client.GetRuleNamespaces(c.Req.Context())
+	if err != nil {
+		logger.Error("Failed to read rules from the ruler", "error", err)
+		return response.Err(errImportRulerRequestFailed(err))
+	}
+	if len(body.Namespaces) > 0 {
+		filtered := make(map[string][]prom.RulerRuleGroup, len(body.Namespaces))
+		for _, ns := range body.Namespaces {
+			// Namespaces that are not in the ruler are kept to find the groups that were removed upstream.
+			filtered[ns] = upstream[ns]
+		}
+		upstream = filtered
+	}
+
+	namespaces := make([]string, 0, len(upstream))
+	for ns := range upstream {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	logger.Info("Importing rules from the ruler", "namespaces", len(namespaces))
+
+	result := apimodels.ConvertPrometheusImportRulerResponse{
+		DryRun: !body.Apply,
+		Rules:  []apimodels.ConvertPrometheusImportRuleChange{},
+	}
+	for _, ns := range namespaces {
+		if errResp := srv.importRulerNamespace(c, converter, ns, upstream[ns], body, &result, logger.New("folder_title", ns)); errResp != nil {
+			return errResp
+		}
+	}
+
+	for _, change := range result.Rules {
+		switch prom.RuleChangeAction(change.Action) {
+		case prom.RuleChangeCreate:
+			result.Summary.Create++
+		case prom.RuleChangeUpdate:
+			result.Summary.Update++
+		case prom.RuleChangeUnchanged:
+			result.Summary.Unchanged++
+		case prom.RuleChangeDelete:
+			result.Summary.Delete++
+		case prom.RuleChangeRemovedUpstream:
+			result.Summary.RemovedUpstream++
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// importRulerNamespace adds the changes of the groups of a namespace to the result, and applies them if requested.
+// Groups that cannot be converted or saved are reported as warnings and do not stop the import of other groups.
+func (srv *ConvertPrometheusSrv) importRulerNamespace(
+	c *contextmodel.ReqContext,
+	converter *prom.Converter,
+	namespaceTitle string,
+	groups []prom.RulerRuleGroup,
+	body apimodels.ConvertPrometheusImportRulerRequest,
+	result *apimodels.ConvertPrometheusImportRulerResponse,
+	logger log.Logger,
+) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	warn := func(group, msg string) {
+		result.Warnings = append(result.Warnings, apimodels.ConvertPrometheusImportWarning{Namespace: namespaceTitle, Group: group, Message: msg})
+	}
+	addChanges := func(group string, changes []prom.RuleChange) {
+		for _, change := range changes {
+			result.Rules = append(result.Rules, apimodels.ConvertPrometheusImportRuleChange{
+				Namespace: namespaceTitle,
+				Group:     group,
+				UID:       change.UID,
+				Title:     change.Title,
+				Action:    string(change.Action),
+				Diff:      change.Diff,
+			})
+		}
+	}
+
+	ns, errResp := srv.importRulerFolder(c, namespaceTitle, body.Apply && len(groups) > 0, logger)
+	if errResp != nil {
+		return errResp
+	}
+
+	// existing contains the stored rules of the folder, grouped by rule group.
+	existing := map[string][]*models.AlertRule{}
+	namespaceUID := ""
+	if ns != nil {
+		namespaceUID = ns.UID
+		rules, err := srv.ruleStore.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID, NamespaceUIDs: []string{ns.UID}})
+		if err != nil {
+			logger.Error("Failed to get alert rules", "folder_uid", ns.UID, "error", err)
+			return errorToResponse(err)
+		}
+		for _, rule := range rules {
+			existing[rule.RuleGroup] = append(existing[rule.RuleGroup], rule)
+		}
+	}
+
+	imported := make(map[string]struct{}, len(groups))
+	for _, promGroup := range groups {
+		imported[promGroup.Name] = struct{}{}
+		for _, msg := range promGroup.UnsupportedSettings() {
+			warn(promGroup.Name, msg)
+		}
+
+		current := existing[promGroup.Name]
+		if slices.ContainsFunc(current, func(r *models.AlertRule) bool { return !r.ImportedFromPrometheus() }) {
+			warn(promGroup.Name, "the group exists and was not imported from a Prometheus-compatible source, it is not replaced")
+			result.Summary.FailedGroups++
+			continue
+		}
+
+		group, err := converter.PrometheusRulesToGrafana(orgID, namespaceUID, promGroup.PrometheusRuleGroup)
+		if err != nil {
+			warn(promGroup.Name, err.Error())
+			result.Summary.FailedGroups++
+			continue
+		}
+
+		changes := prom.DiffRuleGroup(current, group.Rules)
+		addChanges(promGroup.Name, changes)
+		if !body.Apply || !prom.HasChanges(changes) {
+			continue
+		}
+		if err := srv.alertRuleService.ReplaceRuleGroup(ctx, c.SignedInUser, *group, models.ProvenanceConvertedPrometheus); err != nil {
+			logger.Error("Failed to replace rule group", "group", promGroup.Name, "error", err)
+			warn(promGroup.Name, fmt.Sprintf("failed to save the group: %s", err))
+			result.Summary.FailedGroups++
+		}
+	}
+
+	removed := make([]string, 0)
+	for group, rules := range existing {
+		if _, ok := imported[group]; ok {
+			continue
+		}
+		if slices.ContainsFunc(rules, func(r *models.AlertRule) bool { return !r.ImportedFromPrometheus() }) {
+			continue
+		}
+		removed = append(removed, group)
+	}
+	sort.Strings(removed)
+
+	action := prom.RuleChangeRemovedUpstream
+	if body.DeleteRemoved {
+		action = prom.RuleChangeDelete
+	}
+	for _, group := range removed {
+		addChanges(group, prom.RemovedRuleChanges(existing[group], action))
+		if !body.Apply || !body.DeleteRemoved {
+			continue
+		}
+		if err := srv.alertRuleService.DeleteRuleGroup(ctx, c.SignedInUser, ns.UID, group, models.ProvenanceConvertedPrometheus); err != nil {
+			logger.Error("Failed to delete rule group", "group", group, "error", err)
+			warn(group, fmt.Sprintf("failed to delete the group: %s", err))
+			result.Summary.FailedGroups++
+		}
+	}
+	return nil
+}
+
+// importRulerFolder returns the folder of a namespace. It creates the folder if create is true,
+// otherwise it returns nil if the folder does not exist.
+func (srv *ConvertPrometheusSrv) importRulerFolder(c *contextmodel.ReqContext, title string, create bool, logger log.Logger) (*folder.Folder, response.Response) {
+	if create {
+		return srv.getOrCreateNamespace(c, title, logger)
+	}
+	ns, err := srv.ruleStore.GetNamespaceInRootByTitle(c.Req.Context(), title, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		// If there is no such folder, the error is usually ErrFolderAccessDenied.
+		if errors.Is(err, dashboards.ErrFolderAccessDenied) || errors.Is(err, dashboards.ErrFolderNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to get folder", "error", err)
+		return nil, toNamespaceErrorResponse(err)
+	}
+	return ns, nil
+}
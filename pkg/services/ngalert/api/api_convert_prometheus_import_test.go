@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+)
+
+func TestRouteConvertPrometheusImportRuler(t *testing.T) {
+	const rulerRules = `
+test-namespace:
+  - name: test-group
+    interval: 1m
+    query_offset: 1m
+    rules:
+      - alert: TestAlert
+        expr: up == 0
+        for: 5m
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`
+	ruler := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prom.DefaultRulerRulesPath || r.Header.Get("X-Scope-OrgID") != "tenant-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(rulerRules))
+	}))
+	t.Cleanup(ruler.Close)
+
+	setup := func(t *testing.T) (*ConvertPrometheusSrv, *folder.Folder, *models.AlertRule, func() []*models.AlertRule) {
+		srv, _, ruleStore, folderService := createConvertPrometheusSrv(t)
+
+		fldr := randFolder()
+		fldr.ParentUID = ""
+		fldr.Title = "test-namespace"
+		folderService.ExpectedFolder = fldr
+		folderService.ExpectedFolders = []*folder.Folder{fldr}
+		ruleStore.Folders[1] = append(ruleStore.Folders[1], fldr)
+
+		// A group that was imported before and does not exist in the ruler anymore.
+		stale := models.RuleGen.
+			With(models.RuleGen.WithNamespaceUID(fldr.UID)).
+			With(models.RuleGen.WithGroupName("stale-group")).
+			With(models.RuleGen.WithOrgID(1)).
+			With(models.RuleGen.WithPrometheusOriginalRuleDefinition("stale")).
+			GenerateRef()
+		ruleStore.PutRule(context.Background(), stale)
+
+		list := func() []*models.AlertRule {
+			rules, err := ruleStore.ListAlertRules(context.Background(), &models.ListAlertRulesQuery{OrgID: 1})
+			require.NoError(t, err)
+			return rules
+		}
+		return srv, fldr, stale, list
+	}
+
+	importRules := func(t *testing.T, srv *ConvertPrometheusSrv, body apimodels.ConvertPrometheusImportRulerRequest) apimodels.ConvertPrometheusImportRulerResponse {
+		t.Helper()
+		response := srv.RouteConvertPrometheusImportRuler(createRequestCtx(), body)
+		require.Equal(t, http.StatusOK, response.Status(), string(response.Body()))
+		var result apimodels.ConvertPrometheusImportRulerResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		return result
+	}
+
+	request := apimodels.ConvertPrometheusImportRulerRequest{
+		RulerURL:      ruler.URL,
+		TenantID:      "tenant-1",
+		DatasourceUID: existingDSUID,
+	}
+
+	t.Run("should require ruler URL and datasource", func(t *testing.T) {
+		srv, _, _, _ := setup(t)
+
+		response := srv.RouteConvertPrometheusImportRuler(createRequestCtx(), apimodels.ConvertPrometheusImportRulerRequest{DatasourceUID: existingDSUID})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		response = srv.RouteConvertPrometheusImportRuler(createRequestCtx(), apimodels.ConvertPrometheusImportRulerRequest{RulerURL: ruler.URL})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return bad gateway if the ruler fails", func(t *testing.T) {
+		srv, _, _, _ := setup(t)
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(failing.Close)
+
+		body := request
+		body.RulerURL = failing.URL
+		response := srv.RouteConvertPrometheusImportRuler(createRequestCtx(), body)
+		require.Equal(t, http.StatusBadGateway, response.Status())
+	})
+
+	t.Run("dry run should report changes without saving them", func(t *testing.T) {
+		srv, _, stale, list := setup(t)
+
+		result := importRules(t, srv, request)
+
+		require.True(t, result.DryRun)
+		require.Len(t, result.Rules, 3)
+		require.Equal(t, apimodels.ConvertPrometheusImportSummary{Create: 2, RemovedUpstream: 1}, result.Summary)
+		require.Equal(t, "[test-group] TestAlert", result.Rules[0].Title)
+		require.Equal(t, string(prom.RuleChangeCreate), result.Rules[0].Action)
+		require.Equal(t, stale.UID, result.Rules[2].UID)
+		require.Equal(t, string(prom.RuleChangeRemovedUpstream), result.Rules[2].Action)
+		require.Len(t, result.Warnings, 1)
+		require.Contains(t, result.Warnings[0].Message, "query_offset")
+		require.Len(t, list(), 1)
+	})
+
+	t.Run("apply should import rules idempotently and keep groups removed upstream", func(t *testing.T) {
+		srv, fldr, stale, list := setup(t)
+		body := request
+		body.Apply = true
+
+		result := importRules(t, srv, body)
+		require.False(t, result.DryRun)
+		require.Equal(t, 2, result.Summary.Create)
+
+		rules := list()
+		require.Len(t, rules, 3)
+		for _, r := range rules {
+			require.Equal(t, fldr.UID, r.NamespaceUID)
+		}
+
+		result = importRules(t, srv, body)
+		require.Equal(t, apimodels.ConvertPrometheusImportSummary{Unchanged: 2, RemovedUpstream: 1}, result.Summary)
+		require.Len(t, list(), 3)
+
+		body.DeleteRemoved = true
+		result = importRules(t, srv, body)
+		require.Equal(t, apimodels.ConvertPrometheusImportSummary{Unchanged: 2, Delete: 1}, result.Summary)
+		rules = list()
+		require.Len(t, rules, 2)
+		for _, r := range rules {
+			require.NotEqual(t, stale.UID, r.UID)
+		}
+	})
+}
@@ -2,19 +2,32 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
+const (
+	defaultNotificationDeliveriesLimit = 100
+	maxNotificationDeliveriesLimit     = 1000
+)
+
 type NotificationSrv struct {
 	logger            log.Logger
 	receiverService   ReceiverService
 	muteTimingService MuteTimingService // defined in api_provisioning.go
+	deliveryStore     NotificationDeliveryStore
+}
+
+type NotificationDeliveryStore interface {
+	FindNotificationDeliveries(ctx context.Context, query models.NotificationDeliveriesQuery) ([]models.NotificationDelivery, error)
 }
 
 type ReceiverService interface {
@@ -78,3 +91,82 @@ func (srv *NotificationSrv) RouteGetReceivers(c *contextmodel.ReqContext) respon
 
 	return response.JSON(http.StatusOK, gettables)
 }
+
+// RouteGetNotificationDeliveries returns the log of attempts to deliver notifications, most recent first.
+// The deliveries are restricted to the receivers that the user can read.
+func (srv *NotificationSrv) RouteGetNotificationDeliveries(c *contextmodel.ReqContext) response.Response {
+	status := models.NotificationDeliveryStatus(c.Query("status"))
+	if status != "" && status != models.NotificationDeliverySuccess && status != models.NotificationDeliveryFailure {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid status %q, must be %s or %s", status, models.NotificationDeliverySuccess, models.NotificationDeliveryFailure), "")
+	}
+	limit := c.QueryInt("limit")
+	if limit < 0 || limit > maxNotificationDeliveriesLimit {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must be between 0 and %d", maxNotificationDeliveriesLimit), "")
+	}
+	if limit == 0 {
+		limit = defaultNotificationDeliveriesLimit
+	}
+
+	query := models.NotificationDeliveriesQuery{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		Integration: c.Query("integration"),
+		GroupKey:    c.Query("groupKey"),
+		Status:      status,
+		Limit:       limit,
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(from, 0)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(to, 0)
+	}
+
+	// The receiver service returns only the receivers that the user can read.
+	receivers, err := srv.receiverService.ListReceivers(c.Req.Context(), models.ListReceiversQuery{OrgID: query.OrgID}, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get receivers", err)
+	}
+	readable := make(map[string]struct{}, len(receivers))
+	for _, r := range receivers {
+		readable[r.Name] = struct{}{}
+	}
+	query.Receivers = make([]string, 0, len(readable))
+	if requested := c.QueryStrings("receiver"); len(requested) > 0 {
+		for _, name := range requested {
+			if _, ok := readable[name]; ok {
+				query.Receivers = append(query.Receivers, name)
+			}
+		}
+	} else {
+		for name := range readable {
+			query.Receivers = append(query.Receivers, name)
+		}
+	}
+
+	deliveries, err := srv.deliveryStore.FindNotificationDeliveries(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get notification deliveries", err)
+	}
+
+	result := make([]apimodels.GettableNotificationDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, apimodels.GettableNotificationDelivery{
+			Receiver:         d.Receiver,
+			Integration:      d.Integration,
+			IntegrationIndex: d.IntegrationIndex,
+			IntegrationUID:   d.IntegrationUID,
+			GroupKey:         d.GroupKey,
+			Attempt:          d.Attempt,
+			Status:           string(d.Status),
+			StatusCode:       d.StatusCode,
+			Response:         d.Response,
+			Error:            d.Error,
+			Retry:            d.Retry,
+			DurationMs:       d.Duration.Milliseconds(),
+			FiringAlerts:     d.FiringAlerts,
+			ResolvedAlerts:   d.ResolvedAlerts,
+			Timestamp:        d.Timestamp,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -538,6 +538,14 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, sr StatusRe
 			LastEvaluation: status.EvaluationTimestamp,
 			EvaluationTime: status.EvaluationDuration.Seconds(),
 		}
+		if status.QueryCost != (ngmodels.QueryCost{}) {
+			newRule.QueryCost = &apimodels.RuleQueryCost{
+				Duration: status.QueryCost.Duration.Seconds(),
+				Series:   status.QueryCost.Series,
+				Frames:   status.QueryCost.Frames,
+				Bytes:    status.QueryCost.Bytes,
+			}
+		}
 
 		states := manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
@@ -31,6 +32,7 @@ type ProvisioningSrv struct {
 	contactPointService ContactPointService
 	templates           TemplateService
 	muteTimings         MuteTimingService
+	silenceTemplates    SilenceTemplateService
 	alertRules          AlertRuleService
 	folderSvc           folder.Service
 
@@ -66,6 +68,14 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64, provenance definitions.Provenance, version string) error
 }
 
+type SilenceTemplateService interface {
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (definitions.SilenceTemplate, error)
+	CreateSilenceTemplate(ctx context.Context, orgID int64, t definitions.SilenceTemplate) (definitions.SilenceTemplate, error)
+	UpdateSilenceTemplate(ctx context.Context, orgID int64, t definitions.SilenceTemplate) (definitions.SilenceTemplate, error)
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string, provenance definitions.Provenance, version string) error
+}
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, user identity.Requester) ([]*alerting_models.AlertRule, map[string]alerting_models.Provenance, error)
 	GetAlertRule(ctx context.Context, user identity.Requester, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -316,6 +326,51 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, na
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetSilenceTemplates(c *contextmodel.ReqContext) response.Response {
+	templates, err := srv.silenceTemplates.GetSilenceTemplates(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get silence templates", err)
+	}
+	return response.JSON(http.StatusOK, templates)
+}
+
+func (srv *ProvisioningSrv) RouteGetSilenceTemplate(c *contextmodel.ReqContext, uid string) response.Response {
+	t, err := srv.silenceTemplates.GetSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get silence template", err)
+	}
+	return response.JSON(http.StatusOK, t)
+}
+
+func (srv *ProvisioningSrv) RoutePostSilenceTemplate(c *contextmodel.ReqContext, t definitions.SilenceTemplate) response.Response {
+	t.Provenance = determineProvenance(c)
+	t.CreatedBy = c.SignedInUser.GetLogin()
+	created, err := srv.silenceTemplates.CreateSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), t)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create silence template", err)
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutSilenceTemplate(c *contextmodel.ReqContext, t definitions.SilenceTemplate, uid string) response.Response {
+	t.UID = uid
+	t.Provenance = determineProvenance(c)
+	updated, err := srv.silenceTemplates.UpdateSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), t)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update silence template", err)
+	}
+	return response.JSON(http.StatusAccepted, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteSilenceTemplate(c *contextmodel.ReqContext, uid string) response.Response {
+	version := c.Query("version")
+	err := srv.silenceTemplates.DeleteSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), uid, determineProvenance(c), version)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete silence template", err)
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *contextmodel.ReqContext) response.Response {
 	rules, provenances, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.SignedInUser)
 	if err != nil {
@@ -460,6 +515,9 @@ func (srv *ProvisioningSrv) RouteGetAlertRulesExport(c *contextmodel.ReqContext)
 	if len(groupsWithFullpath) == 0 {
 		return response.Empty(http.StatusNotFound)
 	}
+	if extractExportRequest(c).Format == "prometheus" {
+		return exportPrometheusResponse(c, groupsWithFullpath)
+	}
 
 	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath(groupsWithFullpath)
 	if err != nil {
@@ -475,6 +533,9 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleGroupExport(c *contextmodel.ReqCont
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rule group", err)
 	}
+	if extractExportRequest(c).Format == "prometheus" {
+		return exportPrometheusResponse(c, []alerting_models.AlertRuleGroupWithFolderFullpath{g})
+	}
 
 	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath([]alerting_models.AlertRuleGroupWithFolderFullpath{g})
 	if err != nil {
@@ -494,9 +555,14 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleExport(c *contextmodel.ReqContext,
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rules", err)
 	}
 
-	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath([]alerting_models.AlertRuleGroupWithFolderFullpath{
+	groups := []alerting_models.AlertRuleGroupWithFolderFullpath{
 		alerting_models.NewAlertRuleGroupWithFolderFullpathFromRulesGroup(rule.AlertRule.GetGroupKey(), alerting_models.RulesGroup{&rule.AlertRule}, rule.FolderFullpath),
-	})
+	}
+	if extractExportRequest(c).Format == "prometheus" {
+		return exportPrometheusResponse(c, groups)
+	}
+
+	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath(groups)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
 	}
@@ -557,7 +623,7 @@ func extractExportRequest(c *contextmodel.ReqContext) definitions.ExportQueryPar
 	}
 
 	queryFormat := c.Query("format")
-	if queryFormat == "yaml" || queryFormat == "json" || queryFormat == "hcl" {
+	if queryFormat == "yaml" || queryFormat == "json" || queryFormat == "hcl" || queryFormat == "prometheus" {
 		format = queryFormat
 	}
 
@@ -569,11 +635,30 @@ func extractExportRequest(c *contextmodel.ReqContext) definitions.ExportQueryPar
 	return params
 }
 
+// exportPrometheusResponse returns the rule groups as a Prometheus rule file. The rules that cannot be represented
+// in the Prometheus format are listed in a comment at the top of the file.
+func exportPrometheusResponse(c *contextmodel.ReqContext, groups []alerting_models.AlertRuleGroupWithFolderFullpath) response.Response {
+	e, err := prom.GrafanaRuleGroupsToPrometheus(groups)
+	if err != nil {
+		if errors.Is(err, prom.ErrDuplicateExportGroup) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create Prometheus rules export")
+	}
+	if extractExportRequest(c).Download {
+		return response.YAMLDownload(http.StatusOK, e, "rules.yaml")
+	}
+	return response.YAML(http.StatusOK, e)
+}
+
 func exportResponse(c *contextmodel.ReqContext, body definitions.AlertingFileExport) response.Response {
 	params := extractExportRequest(c)
 	if params.Format == "hcl" {
 		return exportHcl(params.Download, body)
 	}
+	if params.Format == "prometheus" {
+		return ErrResp(http.StatusBadRequest, errors.New("the prometheus format is supported only for the export of alert rules"), "")
+	}
 
 	body = escapeAlertingFileExport(body)
 	if params.Download {
@@ -259,6 +259,22 @@ func TestProvisioningApi(t *testing.T) {
 		})
 	})
 
+	t.Run("silence templates", func(t *testing.T) {
+		t.Run("POST sets the creator to the signed in user", func(t *testing.T) {
+			svc := &fakeSilenceTemplateService{}
+			sut := createProvisioningSrvSut(t)
+			sut.silenceTemplates = svc
+			rc := createTestRequestCtx()
+			rc.SignedInUser.Login = "editor"
+			tmpl := definitions.SilenceTemplate{Name: "maintenance", CreatedBy: "someone-else"}
+
+			response := sut.RoutePostSilenceTemplate(&rc, tmpl)
+
+			require.Equal(t, 201, response.Status())
+			require.Equal(t, "editor", svc.created.CreatedBy)
+		})
+	})
+
 	t.Run("alert rules", func(t *testing.T) {
 		t.Run("are invalid", func(t *testing.T) {
 			t.Run("POST returns 400 on wrong body params", func(t *testing.T) {
@@ -2038,6 +2054,16 @@ func createTestRequestCtx() contextmodel.ReqContext {
 	}
 }
 
+type fakeSilenceTemplateService struct {
+	SilenceTemplateService
+	created definitions.SilenceTemplate
+}
+
+func (f *fakeSilenceTemplateService) CreateSilenceTemplate(_ context.Context, _ int64, t definitions.SilenceTemplate) (definitions.SilenceTemplate, error) {
+	f.created = t
+	return t, nil
+}
+
 type fakeNotificationPolicyService struct {
 	tree definitions.Route
 	prov models.Provenance
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
@@ -60,6 +61,7 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+	backtesting    *backtesting.Runner
 }
 
 var (
@@ -569,16 +571,19 @@ func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, prov
 	rules.SortByGroupIndex()
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(rules))
 	var interval time.Duration
+	var sequential bool
 	if len(rules) > 0 {
 		interval = time.Duration(rules[0].IntervalSeconds) * time.Second
+		sequential = rules[0].Metadata.SequentialEvaluation
 	}
 	for _, r := range rules {
 		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, provenanceRecords, userIdToName))
 	}
 	return apimodels.GettableRuleGroupConfig{
-		Name:     groupName,
-		Interval: model.Duration(interval),
-		Rules:    ruleNodes,
+		Name:                 groupName,
+		Interval:             model.Duration(interval),
+		Rules:                ruleNodes,
+		EvaluateSequentially: sequential,
 	}
 }
 
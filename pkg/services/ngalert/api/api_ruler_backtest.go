@@ -0,0 +1,340 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const defaultBacktestRunsLimit = 100
+
+// RoutePostBacktestRun starts an asynchronous backtest run of the rule definition in the request, or of a stored rule.
+func (srv RulerSrv) RoutePostBacktestRun(c *contextmodel.ReqContext, cmd apimodels.PostableBacktestRun) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+	ctx := c.Req.Context()
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(http.StatusBadRequest, nil, "From cannot be greater than To")
+	}
+	keepFiringFor := time.Duration(cmd.KeepFiringFor)
+	if keepFiringFor < 0 {
+		return ErrResp(http.StatusBadRequest, nil, "Bad keep firing for interval")
+	}
+
+	var rule ngmodels.BacktestRule
+	if cmd.RuleUID != "" {
+		stored, err := srv.getAuthorizedRuleByUid(ctx, c, cmd.RuleUID)
+		if err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return response.Empty(http.StatusNotFound)
+			}
+			return errorToResponse(err)
+		}
+		if len(cmd.Data) == 0 {
+			if cmd.RuleVersion != 0 && cmd.RuleVersion != stored.Version {
+				version, err := srv.getRuleVersion(ctx, stored.GetKey(), cmd.RuleVersion)
+				if err != nil {
+					return errorToResponse(err)
+				}
+				if version == nil {
+					return ErrResp(http.StatusNotFound, nil, "version %d of the rule is not found", cmd.RuleVersion)
+				}
+				stored = *version
+			}
+			if stored.Record != nil {
+				return ErrResp(http.StatusBadRequest, nil, "recording rules cannot be backtested")
+			}
+			cmd.RuleVersion = stored.Version
+			rule = backtestRuleFromAlertRule(stored, keepFiringFor)
+		}
+	}
+	if len(cmd.Data) > 0 {
+		var err error
+		rule, err = srv.validateBacktestRule(cmd)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	} else if cmd.RuleUID == "" {
+		return ErrResp(http.StatusBadRequest, nil, "either the rule definition or the rule UID must be specified")
+	}
+
+	if err := srv.authz.AuthorizeDatasourceAccessForRule(ctx, c.SignedInUser, &ngmodels.AlertRule{Data: rule.Data}); err != nil {
+		return errorToResponse(err)
+	}
+
+	run, err := srv.backtesting.Start(ctx, c.SignedInUser, ngmodels.BacktestRun{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		RuleUID:     cmd.RuleUID,
+		RuleVersion: cmd.RuleVersion,
+		Rule:        rule,
+		From:        cmd.From,
+		To:          cmd.To,
+	})
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Failed to start backtest run")
+	}
+	return response.JSON(http.StatusAccepted, toGettableBacktestRun(run, false))
+}
+
+// RouteGetBacktestRuns lists the backtest runs that the user has access to, without their results.
+func (srv RulerSrv) RouteGetBacktestRuns(c *contextmodel.ReqContext) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+	ctx := c.Req.Context()
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultBacktestRunsLimit
+	}
+	runs, err := srv.backtesting.List(ctx, ngmodels.ListBacktestRunsQuery{
+		OrgID:   c.SignedInUser.GetOrgID(),
+		RuleUID: c.Query("rule_uid"),
+		Limit:   limit,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to list backtest runs")
+	}
+
+	ruleAccess := map[string]error{}
+	result := make(apimodels.GettableBacktestRuns, 0, len(runs))
+	for _, run := range runs {
+		if err := srv.authorizeBacktestRun(ctx, c, run, ruleAccess); err != nil {
+			continue
+		}
+		result = append(result, toGettableBacktestRun(run, false))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetBacktestRun returns the backtest run with its result.
+func (srv RulerSrv) RouteGetBacktestRun(c *contextmodel.ReqContext, uid string) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+	run, errResp := srv.getAuthorizedBacktestRun(c, uid)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, toGettableBacktestRun(run, true))
+}
+
+// RouteGetBacktestComparison compares the results of two completed backtest runs.
+func (srv RulerSrv) RouteGetBacktestComparison(c *contextmodel.ReqContext, baseUID, candidateUID string) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+	base, errResp := srv.getAuthorizedBacktestRun(c, baseUID)
+	if errResp != nil {
+		return errResp
+	}
+	candidate, errResp := srv.getAuthorizedBacktestRun(c, candidateUID)
+	if errResp != nil {
+		return errResp
+	}
+	for _, run := range []*ngmodels.BacktestRun{base, candidate} {
+		if run.Status != ngmodels.BacktestRunCompleted {
+			return ErrResp(http.StatusBadRequest, nil, "backtest run %s is %s, only completed runs can be compared", run.UID, run.Status)
+		}
+	}
+	return response.JSON(http.StatusOK, toBacktestComparison(base, candidate, backtesting.Compare(base.Result, candidate.Result)))
+}
+
+func (srv RulerSrv) getAuthorizedBacktestRun(c *contextmodel.ReqContext, uid string) (*ngmodels.BacktestRun, response.Response) {
+	ctx := c.Req.Context()
+	run, err := srv.backtesting.Get(ctx, c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrBacktestRunNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "Failed to get backtest run")
+	}
+	if err := srv.authorizeBacktestRun(ctx, c, run, map[string]error{}); err != nil {
+		return nil, errorToResponse(err)
+	}
+	return run, nil
+}
+
+// authorizeBacktestRun checks that the user can query the data sources of the backtested rule,
+// and can access the stored rule that the run is related to. The result of the check of each rule is cached in ruleAccess.
+func (srv RulerSrv) authorizeBacktestRun(ctx context.Context, c *contextmodel.ReqContext, run *ngmodels.BacktestRun, ruleAccess map[string]error) error {
+	if err := srv.authz.AuthorizeDatasourceAccessForRule(ctx, c.SignedInUser, &ngmodels.AlertRule{Data: run.Rule.Data}); err != nil {
+		return err
+	}
+	if run.RuleUID == "" {
+		return nil
+	}
+	err, ok := ruleAccess[run.RuleUID]
+	if !ok {
+		_, err = srv.getAuthorizedRuleByUid(ctx, c, run.RuleUID)
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			// the rule was deleted, the access to the data sources is enough
+			err = nil
+		}
+		ruleAccess[run.RuleUID] = err
+	}
+	return err
+}
+
+func (srv RulerSrv) getRuleVersion(ctx context.Context, key ngmodels.AlertRuleKey, version int64) (*ngmodels.AlertRule, error) {
+	versions, err := srv.store.GetAlertRuleVersions(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range versions {
+		if rule.Version == version {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+func (srv RulerSrv) validateBacktestRule(cmd apimodels.PostableBacktestRun) (ngmodels.BacktestRule, error) {
+	noDataState, err := ngmodels.NoDataStateFromString(string(cmd.NoDataState))
+	if err != nil {
+		return ngmodels.BacktestRule{}, err
+	}
+	forInterval := time.Duration(cmd.For)
+	if forInterval < 0 {
+		return ngmodels.BacktestRule{}, errors.New("bad for interval")
+	}
+	intervalSeconds, err := validateInterval(time.Duration(cmd.Interval), srv.cfg.BaseInterval)
+	if err != nil {
+		return ngmodels.BacktestRule{}, err
+	}
+	if cmd.Condition == "" {
+		return ngmodels.BacktestRule{}, fmt.Errorf("%w: condition must not be empty", ngmodels.ErrAlertRuleFailedValidation)
+	}
+	return ngmodels.BacktestRule{
+		Title:           cmd.Title,
+		Condition:       cmd.Condition,
+		Data:            AlertQueriesFromApiAlertQueries(cmd.Data),
+		IntervalSeconds: intervalSeconds,
+		For:             forInterval,
+		KeepFiringFor:   time.Duration(cmd.KeepFiringFor),
+		NoDataState:     noDataState,
+		ExecErrState:    ngmodels.ErrorErrState,
+		Labels:          cmd.Labels,
+		Annotations:     cmd.Annotations,
+	}, nil
+}
+
+func backtestRuleFromAlertRule(rule ngmodels.AlertRule, keepFiringFor time.Duration) ngmodels.BacktestRule {
+	return ngmodels.BacktestRule{
+		Title:           rule.Title,
+		Condition:       rule.Condition,
+		Data:            rule.Data,
+		IntervalSeconds: rule.IntervalSeconds,
+		For:             rule.For,
+		KeepFiringFor:   keepFiringFor,
+		NoDataState:     rule.NoDataState,
+		ExecErrState:    rule.ExecErrState,
+		Labels:          rule.Labels,
+		Annotations:     rule.Annotations,
+	}
+}
+
+func toGettableBacktestRun(run *ngmodels.BacktestRun, withResult bool) apimodels.GettableBacktestRun {
+	result := apimodels.GettableBacktestRun{
+		UID:           run.UID,
+		RuleUID:       run.RuleUID,
+		RuleVersion:   run.RuleVersion,
+		Title:         run.Rule.Title,
+		From:          run.From,
+		To:            run.To,
+		Interval:      model.Duration(time.Duration(run.Rule.IntervalSeconds) * time.Second),
+		For:           model.Duration(run.Rule.For),
+		KeepFiringFor: model.Duration(run.Rule.KeepFiringFor),
+		Status:        string(run.Status),
+		Error:         run.Error,
+		Created:       run.Created,
+		Started:       run.Started,
+		Finished:      run.Finished,
+		CreatedBy:     run.CreatedBy,
+	}
+	if !withResult || run.Result == nil {
+		return result
+	}
+	summary := toBacktestSummary(backtesting.Summarize(run.Result))
+	result.Evaluations = run.Result.Evaluations
+	result.Summary = &summary
+	result.Instances = make([]apimodels.BacktestInstance, 0, len(run.Result.Instances))
+	for i := range run.Result.Instances {
+		result.Instances = append(result.Instances, *toBacktestInstance(&run.Result.Instances[i]))
+	}
+	return result
+}
+
+func toBacktestInstance(inst *ngmodels.BacktestInstance) *apimodels.BacktestInstance {
+	if inst == nil {
+		return nil
+	}
+	transitions := make([]apimodels.BacktestTransition, 0, len(inst.Transitions))
+	for _, t := range inst.Transitions {
+		transitions = append(transitions, apimodels.BacktestTransition{
+			Time:   t.Time,
+			State:  t.State,
+			Reason: t.Reason,
+		})
+	}
+	return &apimodels.BacktestInstance{
+		Labels:      inst.Labels,
+		Transitions: transitions,
+		Notifications: apimodels.BacktestNotifications{
+			Firing:   inst.Notifications.Firing,
+			Resolved: inst.Notifications.Resolved,
+		},
+		PendingDuration:    model.Duration(inst.PendingDuration),
+		AlertingDuration:   model.Duration(inst.AlertingDuration),
+		RecoveringDuration: model.Duration(inst.RecoveringDuration),
+	}
+}
+
+func toBacktestSummary(s backtesting.Summary) apimodels.BacktestSummary {
+	return apimodels.BacktestSummary{
+		Instances:   s.Instances,
+		Transitions: s.Transitions,
+		Notifications: apimodels.BacktestNotifications{
+			Firing:   s.Notifications.Firing,
+			Resolved: s.Notifications.Resolved,
+		},
+		PendingDuration:    model.Duration(s.PendingDuration),
+		AlertingDuration:   model.Duration(s.AlertingDuration),
+		RecoveringDuration: model.Duration(s.RecoveringDuration),
+	}
+}
+
+func toBacktestComparison(base, candidate *ngmodels.BacktestRun, c backtesting.Comparison) apimodels.BacktestComparison {
+	result := apimodels.BacktestComparison{
+		Base:      toGettableBacktestRun(base, false),
+		Candidate: toGettableBacktestRun(candidate, false),
+		Instances: make([]apimodels.BacktestInstanceComparison, 0, len(c.Instances)),
+	}
+	baseSummary, candidateSummary := toBacktestSummary(c.Base), toBacktestSummary(c.Candidate)
+	result.Base.Evaluations, result.Base.Summary = base.Result.Evaluations, &baseSummary
+	result.Candidate.Evaluations, result.Candidate.Summary = candidate.Result.Evaluations, &candidateSummary
+	for _, inst := range c.Instances {
+		result.Instances = append(result.Instances, apimodels.BacktestInstanceComparison{
+			Labels:    inst.Labels,
+			Base:      toBacktestInstance(inst.Base),
+			Candidate: toBacktestInstance(inst.Candidate),
+		})
+	}
+	return result
+}
@@ -36,6 +36,9 @@ func (srv RulerSrv) ExportFromPayload(c *contextmodel.ReqContext, ruleGroupConfi
 	}
 
 	groupsWithFullpath := ngmodels.NewAlertRuleGroupWithFolderFullpath(rules[0].GetGroupKey(), rules, namespace.Fullpath)
+	if extractExportRequest(c).Format == "prometheus" {
+		return exportPrometheusResponse(c, []ngmodels.AlertRuleGroupWithFolderFullpath{groupsWithFullpath})
+	}
 
 	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath([]ngmodels.AlertRuleGroupWithFolderFullpath{groupsWithFullpath})
 	if err != nil {
@@ -95,6 +98,10 @@ func (srv RulerSrv) ExportRules(c *contextmodel.ReqContext) response.Response {
 	// sort result so the response is always stable
 	ngmodels.SortAlertRuleGroupWithFolderTitle(groups)
 
+	if extractExportRequest(c).Format == "prometheus" {
+		return exportPrometheusResponse(c, groups)
+	}
+
 	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath(groups)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
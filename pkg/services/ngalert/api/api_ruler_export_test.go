@@ -14,6 +14,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -22,6 +23,7 @@ import (
 	folder2 "github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 )
 
@@ -171,6 +173,19 @@ func TestExportFromPayload(t *testing.T) {
 		require.Equal(t, string(expectedResponse), string(response.Body()))
 	})
 
+	t.Run("prometheus format returns a Prometheus rule file", func(t *testing.T) {
+		rc := createRequest()
+		rc.Context.Req.Form.Set("format", "prometheus")
+
+		response := srv.ExportFromPayload(rc, body, folder.UID)
+		response.WriteTo(rc)
+
+		require.Equal(t, 200, response.Status())
+		require.Equal(t, "text/yaml", rc.Resp.Header().Get("Content-Type"))
+		var file prom.PrometheusRulesFile
+		require.NoError(t, yaml.Unmarshal(response.Body(), &file))
+	})
+
 	t.Run("hcl body content is as expected", func(t *testing.T) {
 		expectedResponse, err := testData.ReadFile(path.Join("test-data", strings.Replace(requestFile, ".json", "-export.hcl", 1)))
 		require.NoError(t, err)
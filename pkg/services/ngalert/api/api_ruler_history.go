@@ -31,6 +31,7 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 	ruleUID := c.Query("ruleUID")
 	dashUID := c.Query("dashboardUID")
 	panelID := c.QueryInt64("panelID")
+	state := c.Query("state")
 
 	labels := make(map[string]string)
 	for k, v := range c.Req.URL.Query() {
@@ -49,6 +50,7 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		To:           time.Unix(to, 0),
 		Limit:        limit,
 		Labels:       labels,
+		State:        state,
 	}
 	frame, err := srv.hist.Query(c.Req.Context(), query)
 	if err != nil {
@@ -329,6 +329,7 @@ func ValidateRuleGroup(
 		ruleWithOptionals := ngmodels.AlertRuleWithOptionals{}
 		rule.IsPaused = isPaused
 		rule.RuleGroupIndex = idx + 1
+		rule.Metadata.SequentialEvaluation = ruleGroupConfig.EvaluateSequentially
 		ruleWithOptionals.AlertRule = *rule
 		ruleWithOptionals.HasPause = hasPause
 		ruleWithOptionals.HasMetadata = hasMetadata
@@ -47,6 +47,12 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingRuleRead),
 			ac.EvalPermission(dashboards.ActionFoldersRead),
 		)
+	case http.MethodPost + "/api/ruler/grafana/api/v1/backtests",
+		http.MethodGet + "/api/ruler/grafana/api/v1/backtests",
+		http.MethodGet + "/api/ruler/grafana/api/v1/backtests/{BacktestUID}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/backtests/{BacktestUID}/compare/{CandidateUID}":
+		// additional authorization of data sources and rules is done in the request handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/export":
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
@@ -79,6 +85,13 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingReceiversRead),
 			ac.EvalPermission(ac.ActionAlertingReceiversReadSecrets),
 		)
+	case http.MethodGet + "/api/v1/notifications/deliveries":
+		// additional authorization is done in the request handler
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingNotificationsRead),
+			ac.EvalPermission(ac.ActionAlertingReceiversRead),
+			ac.EvalPermission(ac.ActionAlertingReceiversReadSecrets),
+		)
 	case http.MethodGet + "/api/v1/notifications/receivers/{Name}":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingReceiversRead),
@@ -147,6 +160,14 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingProvisioningSetStatus),
 		)
 
+	case http.MethodPost + "/api/convert/prometheus/import":
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead),
+			ac.EvalPermission(dashboards.ActionFoldersRead),
+			ac.EvalPermission(ac.ActionAlertingRuleCreate),
+			ac.EvalPermission(ac.ActionAlertingProvisioningSetStatus),
+		)
+
 	case http.MethodDelete + "/api/convert/prometheus/config/v1/rules/{NamespaceTitle}/{Group}",
 		http.MethodDelete + "/api/convert/api/prom/rules/{NamespaceTitle}/{Group}",
 		http.MethodDelete + "/api/convert/prometheus/config/v1/rules/{NamespaceTitle}",
@@ -258,6 +279,15 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingNotificationsWrite),
 			ac.EvalPermission(ac.ActionAlertingReceiversTest),
 		)
+	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/routing/simulate":
+		// the result includes the silences and alerts that mute the alert
+		eval = ac.EvalAll(
+			ac.EvalAny(
+				ac.EvalPermission(ac.ActionAlertingNotificationsRead),
+				ac.EvalPermission(ac.ActionAlertingRoutesRead),
+			),
+			ac.EvalPermission(ac.ActionAlertingInstanceRead),
+		)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/templates/test":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingNotificationsWrite),
@@ -288,7 +318,9 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodDelete + "/api/v1/ngalert/admin_config",
 		http.MethodGet + "/api/v1/ngalert/admin_config",
 		http.MethodPost + "/api/v1/ngalert/admin_config",
-		http.MethodGet + "/api/v1/ngalert/alertmanagers":
+		http.MethodGet + "/api/v1/ngalert/alertmanagers",
+		http.MethodGet + "/api/v1/ngalert/state/export",
+		http.MethodPost + "/api/v1/ngalert/state/restore":
 		return middleware.ReqOrgAdmin
 
 	// Grafana-only Provisioning Export Paths for everything except contact points.
@@ -442,6 +474,27 @@ func (api *API) authorize(method, path string) web.Handler {
 				ac.EvalPermission(ac.ActionAlertingProvisioningSetStatus),
 			),
 		)
+	case http.MethodGet + "/api/v1/provisioning/silence-templates",
+		http.MethodGet + "/api/v1/provisioning/silence-templates/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
+			ac.EvalPermission(ac.ActionAlertingNotificationsProvisioningRead), // organization scope
+			ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets),
+			ac.EvalPermission(ac.ActionAlertingSilenceTemplatesRead),
+		)
+	case http.MethodPost + "/api/v1/provisioning/silence-templates",
+		http.MethodPut + "/api/v1/provisioning/silence-templates/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),              // organization scope,
+			ac.EvalPermission(ac.ActionAlertingNotificationsProvisioningWrite), // organization scope
+			ac.EvalPermission(ac.ActionAlertingSilenceTemplatesWrite),
+		)
+	case http.MethodDelete + "/api/v1/provisioning/silence-templates/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),              // organization scope,
+			ac.EvalPermission(ac.ActionAlertingNotificationsProvisioningWrite), // organization scope
+			ac.EvalPermission(ac.ActionAlertingSilenceTemplatesDelete),
+		)
 	case http.MethodGet + "/api/v1/notifications/time-intervals/{name}",
 		http.MethodGet + "/api/v1/notifications/time-intervals":
 		eval = ac.EvalAny(
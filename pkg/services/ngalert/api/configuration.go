@@ -8,12 +8,14 @@ import (
 
 // ConfigurationApiHandler always forwards requests to grafana backend
 type ConfigurationApiHandler struct {
-	grafana *ConfigSrv
+	grafana   *ConfigSrv
+	snapshots *AlertStateSnapshotSrv
 }
 
-func NewConfiguration(grafana *ConfigSrv) *ConfigurationApiHandler {
+func NewConfiguration(grafana *ConfigSrv, snapshots *AlertStateSnapshotSrv) *ConfigurationApiHandler {
 	return &ConfigurationApiHandler{
-		grafana: grafana,
+		grafana:   grafana,
+		snapshots: snapshots,
 	}
 }
 
@@ -36,3 +38,11 @@ func (f *ConfigurationApiHandler) handleRouteDeleteNGalertConfig(c *contextmodel
 func (f *ConfigurationApiHandler) handleRouteGetStatus(c *contextmodel.ReqContext) response.Response {
 	return f.grafana.RouteGetAlertingStatus(c)
 }
+
+func (f *ConfigurationApiHandler) handleRouteGetAlertStateSnapshot(c *contextmodel.ReqContext) response.Response {
+	return f.snapshots.RouteGetAlertStateSnapshot(c)
+}
+
+func (f *ConfigurationApiHandler) handleRoutePostAlertStateRestore(c *contextmodel.ReqContext, body apimodels.AlertStateRestoreRequest) response.Response {
+	return f.snapshots.RoutePostAlertStateRestore(c, body)
+}
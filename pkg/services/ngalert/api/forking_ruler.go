@@ -128,3 +128,19 @@ func (f *RulerApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexRuler,
 func (f *RulerApiHandler) handleRouteGetRuleVersionsByUID(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
 	return f.GrafanaRuler.RouteGetRuleVersionsByUID(ctx, ruleUID)
 }
+
+func (f *RulerApiHandler) handleRoutePostBacktestRun(ctx *contextmodel.ReqContext, conf apimodels.PostableBacktestRun) response.Response {
+	return f.GrafanaRuler.RoutePostBacktestRun(ctx, conf)
+}
+
+func (f *RulerApiHandler) handleRouteGetBacktestRuns(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaRuler.RouteGetBacktestRuns(ctx)
+}
+
+func (f *RulerApiHandler) handleRouteGetBacktestRun(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaRuler.RouteGetBacktestRun(ctx, uid)
+}
+
+func (f *RulerApiHandler) handleRouteGetBacktestComparison(ctx *contextmodel.ReqContext, baseUID, candidateUID string) response.Response {
+	return f.GrafanaRuler.RouteGetBacktestComparison(ctx, baseUID, candidateUID)
+}
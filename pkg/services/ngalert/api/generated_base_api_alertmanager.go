@@ -44,6 +44,7 @@ type AlertmanagerApi interface {
 	RoutePostAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfigHistoryActivate(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaRoutingSimulation(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaReceivers(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaTemplates(*contextmodel.ReqContext) response.Response
 }
@@ -175,6 +176,14 @@ func (f *AlertmanagerApiHandler) RoutePostGrafanaAlertingConfigHistoryActivate(c
 	idParam := web.Params(ctx.Req)[":id"]
 	return f.handleRoutePostGrafanaAlertingConfigHistoryActivate(ctx, idParam)
 }
+func (f *AlertmanagerApiHandler) RoutePostGrafanaRoutingSimulation(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.RoutingSimulationRequest{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaRoutingSimulation(ctx, conf)
+}
 func (f *AlertmanagerApiHandler) RoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.TestReceiversConfigBodyParams{}
@@ -482,6 +491,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/routing/simulate"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/routing/simulate"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/api/v1/routing/simulate",
+				api.Hooks.Wrap(srv.RoutePostGrafanaRoutingSimulation),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers/test"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -30,6 +31,7 @@ type ConvertPrometheusApi interface {
 	RouteConvertPrometheusGetNamespace(*contextmodel.ReqContext) response.Response
 	RouteConvertPrometheusGetRuleGroup(*contextmodel.ReqContext) response.Response
 	RouteConvertPrometheusGetRules(*contextmodel.ReqContext) response.Response
+	RouteConvertPrometheusImportRuler(*contextmodel.ReqContext) response.Response
 	RouteConvertPrometheusPostRuleGroup(*contextmodel.ReqContext) response.Response
 }
 
@@ -88,6 +90,14 @@ func (f *ConvertPrometheusApiHandler) RouteConvertPrometheusGetRuleGroup(ctx *co
 func (f *ConvertPrometheusApiHandler) RouteConvertPrometheusGetRules(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteConvertPrometheusGetRules(ctx)
 }
+func (f *ConvertPrometheusApiHandler) RouteConvertPrometheusImportRuler(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.ConvertPrometheusImportRulerRequest{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteConvertPrometheusImportRuler(ctx, conf)
+}
 func (f *ConvertPrometheusApiHandler) RouteConvertPrometheusPostRuleGroup(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	namespaceTitleParam := web.Params(ctx.Req)[":NamespaceTitle"]
@@ -228,6 +238,18 @@ func (api *API) RegisterConvertPrometheusApiEndpoints(srv ConvertPrometheusApi,
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/convert/prometheus/import"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/convert/prometheus/import"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/convert/prometheus/import",
+				api.Hooks.Wrap(srv.RouteConvertPrometheusImportRuler),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/convert/prometheus/config/v1/rules/{NamespaceTitle}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
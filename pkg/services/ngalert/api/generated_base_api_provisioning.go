@@ -24,6 +24,7 @@ type ProvisioningApi interface {
 	RouteDeleteAlertRuleGroup(*contextmodel.ReqContext) response.Response
 	RouteDeleteContactpoints(*contextmodel.ReqContext) response.Response
 	RouteDeleteMuteTiming(*contextmodel.ReqContext) response.Response
+	RouteDeleteSilenceTemplate(*contextmodel.ReqContext) response.Response
 	RouteDeleteTemplate(*contextmodel.ReqContext) response.Response
 	RouteExportMuteTiming(*contextmodel.ReqContext) response.Response
 	RouteExportMuteTimings(*contextmodel.ReqContext) response.Response
@@ -39,16 +40,20 @@ type ProvisioningApi interface {
 	RouteGetMuteTimings(*contextmodel.ReqContext) response.Response
 	RouteGetPolicyTree(*contextmodel.ReqContext) response.Response
 	RouteGetPolicyTreeExport(*contextmodel.ReqContext) response.Response
+	RouteGetSilenceTemplate(*contextmodel.ReqContext) response.Response
+	RouteGetSilenceTemplates(*contextmodel.ReqContext) response.Response
 	RouteGetTemplate(*contextmodel.ReqContext) response.Response
 	RouteGetTemplates(*contextmodel.ReqContext) response.Response
 	RoutePostAlertRule(*contextmodel.ReqContext) response.Response
 	RoutePostContactpoints(*contextmodel.ReqContext) response.Response
 	RoutePostMuteTiming(*contextmodel.ReqContext) response.Response
+	RoutePostSilenceTemplate(*contextmodel.ReqContext) response.Response
 	RoutePutAlertRule(*contextmodel.ReqContext) response.Response
 	RoutePutAlertRuleGroup(*contextmodel.ReqContext) response.Response
 	RoutePutContactpoint(*contextmodel.ReqContext) response.Response
 	RoutePutMuteTiming(*contextmodel.ReqContext) response.Response
 	RoutePutPolicyTree(*contextmodel.ReqContext) response.Response
+	RoutePutSilenceTemplate(*contextmodel.ReqContext) response.Response
 	RoutePutTemplate(*contextmodel.ReqContext) response.Response
 	RouteResetPolicyTree(*contextmodel.ReqContext) response.Response
 }
@@ -74,6 +79,11 @@ func (f *ProvisioningApiHandler) RouteDeleteMuteTiming(ctx *contextmodel.ReqCont
 	nameParam := web.Params(ctx.Req)[":name"]
 	return f.handleRouteDeleteMuteTiming(ctx, nameParam)
 }
+func (f *ProvisioningApiHandler) RouteDeleteSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	uIDParam := web.Params(ctx.Req)[":UID"]
+	return f.handleRouteDeleteSilenceTemplate(ctx, uIDParam)
+}
 func (f *ProvisioningApiHandler) RouteDeleteTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -135,6 +145,14 @@ func (f *ProvisioningApiHandler) RouteGetPolicyTree(ctx *contextmodel.ReqContext
 func (f *ProvisioningApiHandler) RouteGetPolicyTreeExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetPolicyTreeExport(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	uIDParam := web.Params(ctx.Req)[":UID"]
+	return f.handleRouteGetSilenceTemplate(ctx, uIDParam)
+}
+func (f *ProvisioningApiHandler) RouteGetSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetSilenceTemplates(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -167,6 +185,14 @@ func (f *ProvisioningApiHandler) RoutePostMuteTiming(ctx *contextmodel.ReqContex
 	}
 	return f.handleRoutePostMuteTiming(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePostSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.SilenceTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostSilenceTemplate(ctx, conf)
+}
 func (f *ProvisioningApiHandler) RoutePutAlertRule(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	uIDParam := web.Params(ctx.Req)[":UID"]
@@ -216,6 +242,16 @@ func (f *ProvisioningApiHandler) RoutePutPolicyTree(ctx *contextmodel.ReqContext
 	}
 	return f.handleRoutePutPolicyTree(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePutSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	uIDParam := web.Params(ctx.Req)[":UID"]
+	// Parse Request Body
+	conf := apimodels.SilenceTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePutSilenceTemplate(ctx, conf, uIDParam)
+}
 func (f *ProvisioningApiHandler) RoutePutTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -280,6 +316,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/silence-templates/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/silence-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/silence-templates/{UID}",
+				api.Hooks.Wrap(srv.RouteDeleteSilenceTemplate),
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -460,6 +508,30 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/silence-templates/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/silence-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/silence-templates/{UID}",
+				api.Hooks.Wrap(srv.RouteGetSilenceTemplate),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/silence-templates"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/silence-templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/silence-templates",
+				api.Hooks.Wrap(srv.RouteGetSilenceTemplates),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -520,6 +592,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/silence-templates"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/silence-templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/silence-templates",
+				api.Hooks.Wrap(srv.RoutePostSilenceTemplate),
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -580,6 +664,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/silence-templates/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/silence-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/silence-templates/{UID}",
+				api.Hooks.Wrap(srv.RoutePutSilenceTemplate),
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -24,6 +24,9 @@ type RulerApi interface {
 	RouteDeleteNamespaceGrafanaRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteNamespaceRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteRuleGroupConfig(*contextmodel.ReqContext) response.Response
+	RouteGetBacktestComparison(*contextmodel.ReqContext) response.Response
+	RouteGetBacktestRun(*contextmodel.ReqContext) response.Response
+	RouteGetBacktestRuns(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaRuleGroupConfig(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteGetNamespaceGrafanaRulesConfig(*contextmodel.ReqContext) response.Response
@@ -33,6 +36,7 @@ type RulerApi interface {
 	RouteGetRulegGroupConfig(*contextmodel.ReqContext) response.Response
 	RouteGetRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteGetRulesForExport(*contextmodel.ReqContext) response.Response
+	RoutePostBacktestRun(*contextmodel.ReqContext) response.Response
 	RoutePostNameGrafanaRulesConfig(*contextmodel.ReqContext) response.Response
 	RoutePostNameRulesConfig(*contextmodel.ReqContext) response.Response
 	RoutePostRulesGroupForExport(*contextmodel.ReqContext) response.Response
@@ -62,6 +66,20 @@ func (f *RulerApiHandler) RouteDeleteRuleGroupConfig(ctx *contextmodel.ReqContex
 	groupnameParam := web.Params(ctx.Req)[":Groupname"]
 	return f.handleRouteDeleteRuleGroupConfig(ctx, datasourceUIDParam, namespaceParam, groupnameParam)
 }
+func (f *RulerApiHandler) RouteGetBacktestComparison(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	backtestUIDParam := web.Params(ctx.Req)[":BacktestUID"]
+	candidateUIDParam := web.Params(ctx.Req)[":CandidateUID"]
+	return f.handleRouteGetBacktestComparison(ctx, backtestUIDParam, candidateUIDParam)
+}
+func (f *RulerApiHandler) RouteGetBacktestRun(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	backtestUIDParam := web.Params(ctx.Req)[":BacktestUID"]
+	return f.handleRouteGetBacktestRun(ctx, backtestUIDParam)
+}
+func (f *RulerApiHandler) RouteGetBacktestRuns(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetBacktestRuns(ctx)
+}
 func (f *RulerApiHandler) RouteGetGrafanaRuleGroupConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	namespaceParam := web.Params(ctx.Req)[":Namespace"]
@@ -107,6 +125,14 @@ func (f *RulerApiHandler) RouteGetRulesConfig(ctx *contextmodel.ReqContext) resp
 func (f *RulerApiHandler) RouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetRulesForExport(ctx)
 }
+func (f *RulerApiHandler) RoutePostBacktestRun(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableBacktestRun{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostBacktestRun(ctx, conf)
+}
 func (f *RulerApiHandler) RoutePostNameGrafanaRulesConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	namespaceParam := web.Params(ctx.Req)[":Namespace"]
@@ -189,6 +215,42 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/backtests/{BacktestUID}/compare/{CandidateUID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/backtests/{BacktestUID}/compare/{CandidateUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/backtests/{BacktestUID}/compare/{CandidateUID}",
+				api.Hooks.Wrap(srv.RouteGetBacktestComparison),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/backtests/{BacktestUID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/backtests/{BacktestUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/backtests/{BacktestUID}",
+				api.Hooks.Wrap(srv.RouteGetBacktestRun),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/backtests"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/backtests"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/backtests",
+				api.Hooks.Wrap(srv.RouteGetBacktestRuns),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -297,6 +359,18 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/backtests"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/backtests"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/backtests",
+				api.Hooks.Wrap(srv.RoutePostBacktestRun),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -30,3 +30,7 @@ func (f *NotificationsApiHandler) handleRouteGetReceiver(ctx *contextmodel.ReqCo
 func (f *NotificationsApiHandler) handleRouteGetReceivers(ctx *contextmodel.ReqContext) response.Response {
 	return f.notificationSrv.RouteGetReceivers(ctx)
 }
+
+func (f *NotificationsApiHandler) handleRouteGetNotificationDeliveries(ctx *contextmodel.ReqContext) response.Response {
+	return f.notificationSrv.RouteGetNotificationDeliveries(ctx)
+}
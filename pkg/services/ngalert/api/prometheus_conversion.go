@@ -56,6 +56,10 @@ func (f *ConvertPrometheusApiHandler) handleRouteConvertPrometheusPostRuleGroup(
 	return f.svc.RouteConvertPrometheusPostRuleGroup(ctx, namespaceTitle, promGroup)
 }
 
+func (f *ConvertPrometheusApiHandler) handleRouteConvertPrometheusImportRuler(ctx *contextmodel.ReqContext, body apimodels.ConvertPrometheusImportRulerRequest) response.Response {
+	return f.svc.RouteConvertPrometheusImportRuler(ctx, body)
+}
+
 // cortextool
 func (f *ConvertPrometheusApiHandler) handleRouteConvertPrometheusCortexGetRules(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteConvertPrometheusGetRules(ctx)
@@ -84,6 +84,26 @@ func (f *ProvisioningApiHandler) handleRouteDeleteMuteTiming(ctx *contextmodel.R
 	return f.svc.RouteDeleteMuteTiming(ctx, name)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetSilenceTemplates(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetSilenceTemplate(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteGetSilenceTemplate(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostSilenceTemplate(ctx *contextmodel.ReqContext, t apimodels.SilenceTemplate) response.Response {
+	return f.svc.RoutePostSilenceTemplate(ctx, t)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutSilenceTemplate(ctx *contextmodel.ReqContext, t apimodels.SilenceTemplate, UID string) response.Response {
+	return f.svc.RoutePutSilenceTemplate(ctx, t, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteSilenceTemplate(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteDeleteSilenceTemplate(ctx, UID)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetAlertRules(ctx *contextmodel.ReqContext) response.Response {
 	return f.svc.RouteGetAlertRules(ctx)
 }
@@ -0,0 +1,93 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /v1/ngalert/state/export configuration RouteGetAlertStateSnapshot
+//
+//  Export the state of the alert instances, the notification log and the silences of the user's organization,
+//  so that it can be restored in another Grafana instance.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertStateSnapshot
+//       404: Failure
+//       500: Failure
+
+// swagger:route POST /v1/ngalert/state/restore configuration RoutePostAlertStateRestore
+//
+// Restore a snapshot of the alert state exported from another Grafana instance into the user's organization.
+// Alert instances of rules that do not exist are skipped. Restored silences and notification log entries
+// take precedence over existing ones.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertStateRestoreResult
+//       400: ValidationError
+//       404: Failure
+//       500: Failure
+
+// AlertStateSnapshotVersion is the version of the format of AlertStateSnapshot.
+const AlertStateSnapshotVersion = 1
+
+// swagger:model
+type AlertStateSnapshot struct {
+	Version   int       `json:"version"`
+	OrgID     int64     `json:"orgId"`
+	CreatedAt time.Time `json:"createdAt"`
+	// State of the alert instances of all alert rules.
+	Instances []AlertInstanceSnapshot `json:"instances"`
+	// Silences of the Alertmanager in its binary format, encoded as base64.
+	Silences []byte `json:"silences,omitempty"`
+	// Notification log of the Alertmanager in its binary format, encoded as base64.
+	NotificationLog []byte `json:"notificationLog,omitempty"`
+}
+
+// swagger:model
+type AlertInstanceSnapshot struct {
+	RuleUID           string            `json:"ruleUid"`
+	Labels            map[string]string `json:"labels"`
+	State             string            `json:"state"`
+	StateReason       string            `json:"stateReason,omitempty"`
+	StateSince        time.Time         `json:"stateSince"`
+	StateEnd          time.Time         `json:"stateEnd"`
+	LastEvalTime      time.Time         `json:"lastEvalTime"`
+	LastSentAt        *time.Time        `json:"lastSentAt,omitempty"`
+	ResolvedAt        *time.Time        `json:"resolvedAt,omitempty"`
+	ResultFingerprint string            `json:"resultFingerprint,omitempty"`
+}
+
+// swagger:parameters RoutePostAlertStateRestore
+type AlertStateRestoreParams struct {
+	// in:body
+	Body AlertStateRestoreRequest
+}
+
+// swagger:model
+type AlertStateRestoreRequest struct {
+	Snapshot AlertStateSnapshot `json:"snapshot"`
+	// UIDs of the alert rules in this instance by the UIDs of the alert rules in the instance the snapshot was exported from.
+	// Rules that are not in the map are expected to have the same UID in both instances.
+	RuleUIDs map[string]string `json:"ruleUids,omitempty"`
+	// If true, the snapshot is validated but nothing is restored.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// swagger:model
+type AlertStateRestoreResult struct {
+	Instances              int `json:"instances"`
+	SkippedInstances       int `json:"skippedInstances"`
+	Silences               int `json:"silences"`
+	NotificationLogEntries int `json:"notificationLogEntries"`
+	// UIDs of the alert rules in the snapshot that do not exist in this instance.
+	MissingRules []string `json:"missingRules,omitempty"`
+	DryRun       bool     `json:"dryRun,omitempty"`
+}
@@ -170,6 +170,24 @@ import (
 //       202: ConvertPrometheusResponse
 //       403: ForbiddenError
 
+// swagger:route POST /convert/prometheus/import convert_prometheus RouteConvertPrometheusImportRuler
+//
+// Reads the rule groups of all namespaces from a Prometheus-compatible ruler, such as Cortex, Mimir or Loki,
+// and converts them to Grafana rule groups in folders named after the namespaces.
+// Unless apply is true, nothing is changed and only the changes that the import would make are returned.
+// Imported groups that do not exist in the ruler anymore are reported, and deleted only if deleteRemoved is true.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ConvertPrometheusImportRulerResponse
+//       400: ValidationError
+//       403: ForbiddenError
+
 // swagger:parameters RouteConvertPrometheusPostRuleGroup RouteConvertPrometheusCortexPostRuleGroup
 type RouteConvertPrometheusPostRuleGroupParams struct {
 	// in: path
@@ -228,3 +246,69 @@ type ConvertPrometheusResponse struct {
 	ErrorType string `json:"errorType"`
 	Error     string `json:"error"`
 }
+
+// swagger:parameters RouteConvertPrometheusImportRuler
+type RouteConvertPrometheusImportRulerParams struct {
+	// in:body
+	Body ConvertPrometheusImportRulerRequest
+}
+
+// swagger:model
+type ConvertPrometheusImportRulerRequest struct {
+	// Base URL of the ruler, for example http://mimir:8080/prometheus.
+	// required: true
+	RulerURL string `json:"rulerUrl"`
+	// Path of the rules endpoint relative to the URL. Defaults to /api/v1/rules, use /loki/api/v1/rules for Loki.
+	RulesPath string `json:"rulesPath,omitempty"`
+	// Tenant whose rules are imported, sent in the X-Scope-OrgID header.
+	TenantID          string            `json:"tenantId,omitempty"`
+	BasicAuthUser     string            `json:"basicAuthUser,omitempty"`
+	BasicAuthPassword string            `json:"basicAuthPassword,omitempty"`
+	Headers           map[string]string `json:"headers,omitempty"`
+	// UID of the data source that the imported rules query.
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// Namespaces to import. All namespaces of the ruler are imported if it is empty.
+	Namespaces           []string `json:"namespaces,omitempty"`
+	RecordingRulesPaused bool     `json:"recordingRulesPaused,omitempty"`
+	AlertRulesPaused     bool     `json:"alertRulesPaused,omitempty"`
+	// Apply the changes. If false, the import is a dry run.
+	Apply bool `json:"apply,omitempty"`
+	// Delete imported groups that do not exist in the ruler anymore.
+	DeleteRemoved bool `json:"deleteRemoved,omitempty"`
+}
+
+// swagger:model
+type ConvertPrometheusImportRulerResponse struct {
+	DryRun   bool                                `json:"dryRun"`
+	Summary  ConvertPrometheusImportSummary      `json:"summary"`
+	Rules    []ConvertPrometheusImportRuleChange `json:"rules"`
+	Warnings []ConvertPrometheusImportWarning    `json:"warnings,omitempty"`
+}
+
+type ConvertPrometheusImportSummary struct {
+	Create          int `json:"create"`
+	Update          int `json:"update"`
+	Unchanged       int `json:"unchanged"`
+	Delete          int `json:"delete"`
+	RemovedUpstream int `json:"removedUpstream"`
+	// Number of groups that could not be converted or saved.
+	FailedGroups int `json:"failedGroups"`
+}
+
+type ConvertPrometheusImportRuleChange struct {
+	Namespace string `json:"namespace"`
+	Group     string `json:"group"`
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	// enum: create,update,unchanged,delete,removed_upstream
+	Action string `json:"action"`
+	// Paths of the fields that an update changes.
+	Diff []string `json:"diff,omitempty"`
+}
+
+type ConvertPrometheusImportWarning struct {
+	Namespace string `json:"namespace"`
+	Group     string `json:"group,omitempty"`
+	Message   string `json:"message"`
+}
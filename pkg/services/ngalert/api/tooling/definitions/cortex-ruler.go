@@ -251,6 +251,10 @@ type PostableRuleGroupConfig struct {
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
 
+	// EvaluateSequentially makes Grafana evaluate the rules of the group one after another, so that
+	// alert rules see the values written by the recording rules they depend on in the same evaluation.
+	EvaluateSequentially bool `yaml:"evaluate_sequentially,omitempty" json:"evaluate_sequentially,omitempty"`
+
 	// fields below are used by Mimir/Loki rulers
 
 	SourceTenants                 []string        `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
@@ -300,6 +304,10 @@ func (c *PostableRuleGroupConfig) validate() error {
 	if hasGrafRules && (len(c.SourceTenants) > 0 || c.EvaluationDelay != nil || c.QueryOffset != nil || c.AlignEvaluationTimeOnInterval || c.Limit > 0) {
 		return fmt.Errorf("fields source_tenants, evaluation_delay, query_offset, align_evaluation_time_on_interval and limit are not supported for Grafana rules")
 	}
+
+	if hasLotexRules && c.EvaluateSequentially {
+		return fmt.Errorf("field evaluate_sequentially is supported only for Grafana rules")
+	}
 	return nil
 }
 
@@ -312,6 +320,9 @@ type GettableRuleGroupConfig struct {
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
 
+	// EvaluateSequentially is true if Grafana evaluates the rules of the group one after another.
+	EvaluateSequentially bool `yaml:"evaluate_sequentially,omitempty" json:"evaluate_sequentially,omitempty"`
+
 	// fields below are used by Mimir/Loki rulers
 
 	SourceTenants                 []string        `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
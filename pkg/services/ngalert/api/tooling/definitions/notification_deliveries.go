@@ -0,0 +1,76 @@
+package definitions
+
+import "time"
+
+// swagger:route GET /v1/notifications/deliveries notifications RouteGetNotificationDeliveries
+//
+// Get the log of attempts to deliver notifications to the integrations of contact points, most recent first.
+// Only deliveries to contact points that the user can read are returned.
+//
+//    Responses:
+//      200: GetNotificationDeliveriesResponse
+//      400: ValidationError
+//      403: PermissionDenied
+
+// swagger:parameters RouteGetNotificationDeliveries
+type GetNotificationDeliveriesParams struct {
+	// Names of the contact points.
+	// in:query
+	// required: false
+	Receiver []string `json:"receiver"`
+	// Type of the integration, e.g. slack or email.
+	// in:query
+	// required: false
+	Integration string `json:"integration"`
+	// Key of the alert group.
+	// in:query
+	// required: false
+	GroupKey string `json:"groupKey"`
+	// in:query
+	// required: false
+	// enum: success,failure
+	Status string `json:"status"`
+	// Unix timestamp in seconds of the start of the time range.
+	// in:query
+	// required: false
+	From int64 `json:"from"`
+	// Unix timestamp in seconds of the end of the time range.
+	// in:query
+	// required: false
+	To int64 `json:"to"`
+	// in:query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:response GetNotificationDeliveriesResponse
+type GetNotificationDeliveriesResponse struct {
+	// in:body
+	Body []GettableNotificationDelivery
+}
+
+// GettableNotificationDelivery is a single attempt to deliver a notification of an alert group to an integration.
+// swagger:model
+type GettableNotificationDelivery struct {
+	Receiver         string `json:"receiver"`
+	Integration      string `json:"integration"`
+	IntegrationIndex int    `json:"integrationIndex"`
+	IntegrationUID   string `json:"integrationUid,omitempty"`
+	GroupKey         string `json:"groupKey"`
+	// Number of the attempt, starting at 1 and increased on every retry of the same notification.
+	Attempt int `json:"attempt"`
+	// enum: success,failure
+	Status string `json:"status"`
+	// HTTP status code returned by the contact point, if it is known.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Beginning of the body of the HTTP response of the contact point.
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// True if the failed attempt is going to be retried.
+	Retry          bool      `json:"retry"`
+	DurationMs     int64     `json:"durationMs"`
+	FiringAlerts   int       `json:"firingAlerts"`
+	ResolvedAlerts int       `json:"resolvedAlerts"`
+	Timestamp      time.Time `json:"timestamp"`
+}
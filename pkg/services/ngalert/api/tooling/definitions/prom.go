@@ -183,6 +183,19 @@ type Rule struct {
 	Type           string    `json:"type"`
 	LastEvaluation time.Time `json:"lastEvaluation"`
 	EvaluationTime float64   `json:"evaluationTime"`
+	// QueryCost is the cost of the data source queries made by the latest evaluation.
+	QueryCost *RuleQueryCost `json:"queryCost,omitempty"`
+}
+
+// RuleQueryCost describes the cost of the data source queries made by a rule evaluation.
+// swagger:model
+type RuleQueryCost struct {
+	// Duration of the queries in seconds.
+	Duration float64 `json:"duration"`
+	Series   int     `json:"series"`
+	Frames   int     `json:"frames"`
+	// Estimated size of the returned data in bytes.
+	Bytes int64 `json:"bytes"`
 }
 
 // Alert has info for an alert.
@@ -19,10 +19,11 @@ type ExportQueryParams struct {
 	Download bool `json:"download"`
 
 	// Format of the downloaded file. Supported yaml, json or hcl. Accept header can also be used, but the query parameter will take precedence.
+	// Alert rules can also be exported as a Prometheus rule file with the format prometheus. Rules that cannot be represented in the Prometheus format are listed in a comment and are not exported.
 	// in: query
 	// required: false
 	// default: yaml
-	// enum: yaml,json,hcl
+	// enum: yaml,json,hcl,prometheus
 	Format string `json:"format"`
 }
 
@@ -0,0 +1,127 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /v1/provisioning/silence-templates provisioning stable RouteGetSilenceTemplates
+//
+// Get all the silence templates and recurring silences.
+//
+//     Responses:
+//       200: SilenceTemplates
+
+// swagger:route GET /v1/provisioning/silence-templates/{UID} provisioning stable RouteGetSilenceTemplate
+//
+// Get a silence template.
+//
+//     Responses:
+//       200: SilenceTemplate
+//       404: description: Not found.
+
+// swagger:route POST /v1/provisioning/silence-templates provisioning stable RoutePostSilenceTemplate
+//
+// Create a new silence template. A template with a schedule creates silences in the Alertmanager at every occurrence of the schedule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: SilenceTemplate
+//       400: ValidationError
+
+// swagger:route PUT /v1/provisioning/silence-templates/{UID} provisioning stable RoutePutSilenceTemplate
+//
+// Replace an existing silence template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: SilenceTemplate
+//       400: ValidationError
+//       404: description: Not found.
+//       409: PublicError
+
+// swagger:route DELETE /v1/provisioning/silence-templates/{UID} provisioning stable RouteDeleteSilenceTemplate
+//
+// Delete a silence template. The silence created for the current occurrence of the schedule is expired.
+//
+//     Responses:
+//       204: description: The silence template was deleted successfully.
+//       409: PublicError
+
+// swagger:model
+type SilenceTemplates []SilenceTemplate
+
+// swagger:parameters RouteGetSilenceTemplate RoutePutSilenceTemplate
+type RouteGetSilenceTemplateParam struct {
+	// Silence template UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RouteDeleteSilenceTemplate
+type RouteDeleteSilenceTemplateParam struct {
+	// Silence template UID
+	// in:path
+	UID string
+
+	// Version of silence template to use for optimistic concurrency. Leave empty to disable validation
+	// in:query
+	Version string `json:"version"`
+}
+
+// swagger:parameters RoutePostSilenceTemplate RoutePutSilenceTemplate
+type SilenceTemplatePayload struct {
+	// in:body
+	Body SilenceTemplate
+}
+
+// swagger:parameters RoutePostSilenceTemplate RoutePutSilenceTemplate RouteDeleteSilenceTemplate
+type SilenceTemplateHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// swagger:model
+type SilenceTemplate struct {
+	UID      string        `json:"uid"`
+	Name     string        `json:"name"`
+	Matchers amv2.Matchers `json:"matchers"`
+	Comment  string        `json:"comment,omitempty"`
+	// Duration of the silences created from the template.
+	// example: 2h
+	Duration model.Duration `json:"duration"`
+	// Cron expression or RRULE that defines when silences are created. Leave empty for a template that is not recurring.
+	// example: 0 22 * * 6
+	Schedule string `json:"schedule,omitempty"`
+	// IANA name of the location the schedule is evaluated in. Defaults to UTC.
+	// example: Europe/Berlin
+	Timezone string `json:"timezone,omitempty"`
+	// Login of the user that created the template.
+	// readonly: true
+	CreatedBy string `json:"createdBy,omitempty"`
+	// Start of the next occurrence of the schedule.
+	// readonly: true
+	NextOccurrence *time.Time `json:"nextOccurrence,omitempty"`
+	// Start of the latest occurrence of the schedule a silence was created for.
+	// readonly: true
+	LastOccurrence *time.Time `json:"lastOccurrence,omitempty"`
+	// ID of the silence created for the latest occurrence of the schedule.
+	// readonly: true
+	SilenceID  string     `json:"silenceId,omitempty"`
+	Version    string     `json:"version,omitempty"`
+	Provenance Provenance `json:"provenance,omitempty"`
+}
+
+func (t *SilenceTemplate) ResourceType() string {
+	return "silenceTemplate"
+}
+
+func (t *SilenceTemplate) ResourceID() string {
+	return t.UID
+}
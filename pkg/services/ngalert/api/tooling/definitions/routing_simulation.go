@@ -0,0 +1,97 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /alertmanager/grafana/config/api/v1/routing/simulate alertmanager RoutePostGrafanaRoutingSimulation
+//
+// Simulate how an alert with the given labels is handled by the notification policies, silences and inhibition rules
+// of the Grafana Alertmanager at the given time, without sending any notification.
+//
+//     Responses:
+//       200: RoutingSimulationResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+//       409: AlertManagerNotReady
+
+// swagger:parameters RoutePostGrafanaRoutingSimulation
+type RoutingSimulationParams struct {
+	// in:body
+	Body RoutingSimulationRequest
+}
+
+// swagger:model
+type RoutingSimulationRequest struct {
+	// Labels of the alert.
+	// required: true
+	Labels model.LabelSet `json:"labels"`
+	// Time at which the alert is routed. Defaults to the current time.
+	// Time intervals and silences are evaluated at this time, while inhibitions are always evaluated against the current alerts.
+	Time *time.Time `json:"time,omitempty"`
+}
+
+// swagger:model
+type RoutingSimulationResult struct {
+	Time time.Time `json:"time"`
+	// Routes of the notification policy tree that match the labels, in the order they are evaluated.
+	Routes []SimulatedRoute `json:"routes"`
+	// Silences that mute the alert at the given time.
+	Silences []SimulatedSilence `json:"silences"`
+	// Inhibition rules that mute the alert because of the current alerts.
+	Inhibitions []SimulatedInhibition `json:"inhibitions"`
+	Silenced    bool                  `json:"silenced"`
+	Inhibited   bool                  `json:"inhibited"`
+	// Receivers that would be notified.
+	Receivers []string `json:"receivers"`
+}
+
+// SimulatedRoute is a route of the notification policy tree with the settings it inherits from its parents.
+type SimulatedRoute struct {
+	// Positions of the route and its parents among their siblings, starting from the child of the root route.
+	// The path of the root route is empty.
+	Path     []int    `json:"path"`
+	Matchers []string `json:"matchers,omitempty"`
+	Receiver string   `json:"receiver"`
+	Continue bool     `json:"continue"`
+	// Labels the alert is grouped by. The alert is grouped by all its labels if groupByAll is true.
+	GroupBy    []string `json:"groupBy,omitempty"`
+	GroupByAll bool     `json:"groupByAll"`
+	// Labels of the alert group the alert belongs to.
+	GroupLabels         model.LabelSet          `json:"groupLabels"`
+	GroupWait           model.Duration          `json:"groupWait"`
+	GroupInterval       model.Duration          `json:"groupInterval"`
+	RepeatInterval      model.Duration          `json:"repeatInterval"`
+	MuteTimeIntervals   []SimulatedTimeInterval `json:"muteTimeIntervals,omitempty"`
+	ActiveTimeIntervals []SimulatedTimeInterval `json:"activeTimeIntervals,omitempty"`
+	// True if notifications of the route are muted by its time intervals at the given time.
+	Muted bool `json:"muted"`
+}
+
+type SimulatedTimeInterval struct {
+	Name string `json:"name"`
+	// True if the given time is within the time interval.
+	Active bool `json:"active"`
+}
+
+type SimulatedSilence struct {
+	ID        string    `json:"id"`
+	Matchers  []string  `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+type SimulatedInhibition struct {
+	// Position of the rule in the inhibition rules of the configuration.
+	Index          int      `json:"index"`
+	SourceMatchers []string `json:"sourceMatchers"`
+	TargetMatchers []string `json:"targetMatchers"`
+	Equal          []string `json:"equal,omitempty"`
+	// Labels of the alert that inhibits the alert.
+	SourceAlert model.LabelSet `json:"sourceAlert"`
+}
@@ -0,0 +1,179 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route Post /ruler/grafana/api/v1/backtests ruler RoutePostBacktestRun
+//
+// Start a backtest run that evaluates a rule over a past time range in the background
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: GettableBacktestRun
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/backtests ruler RouteGetBacktestRuns
+//
+// List backtest runs, most recent first. The results of the runs are not included.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableBacktestRuns
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/backtests/{BacktestUID} ruler RouteGetBacktestRun
+//
+// Get a backtest run with its result
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableBacktestRun
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/backtests/{BacktestUID}/compare/{CandidateUID} ruler RouteGetBacktestComparison
+//
+// Compare the results of two completed backtest runs, for example of two versions of a rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestComparison
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RoutePostBacktestRun
+type PostableBacktestRunRequest struct {
+	// in:body
+	Body PostableBacktestRun
+}
+
+// swagger:parameters RouteGetBacktestRuns
+type GetBacktestRunsParams struct {
+	// in: query
+	// required: false
+	RuleUID string `json:"rule_uid"`
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RouteGetBacktestRun RouteGetBacktestComparison
+type BacktestRunParams struct {
+	// in: path
+	BacktestUID string
+}
+
+// swagger:parameters RouteGetBacktestComparison
+type BacktestComparisonParams struct {
+	// in: path
+	CandidateUID string
+}
+
+// PostableBacktestRun is the configuration of a backtest run.
+// If the rule definition is empty and RuleUID is set, the stored rule is backtested,
+// or its version RuleVersion if that is set.
+// swagger:model
+type PostableBacktestRun struct {
+	BacktestConfig
+	// KeepFiringFor is how long an alert instance keeps firing after its condition is no longer met.
+	KeepFiringFor model.Duration `json:"keep_firing_for,omitempty"`
+	// RuleUID is the UID of the stored rule that the definition is a version of. Runs of the same rule can be listed together.
+	RuleUID     string `json:"rule_uid,omitempty"`
+	RuleVersion int64  `json:"rule_version,omitempty"`
+}
+
+// swagger:model
+type GettableBacktestRuns []GettableBacktestRun
+
+// swagger:model
+type GettableBacktestRun struct {
+	UID           string         `json:"uid"`
+	RuleUID       string         `json:"rule_uid,omitempty"`
+	RuleVersion   int64          `json:"rule_version,omitempty"`
+	Title         string         `json:"title"`
+	From          time.Time      `json:"from"`
+	To            time.Time      `json:"to"`
+	Interval      model.Duration `json:"interval"`
+	For           model.Duration `json:"for"`
+	KeepFiringFor model.Duration `json:"keep_firing_for"`
+
+	// Status is one of pending, running, completed or failed.
+	Status    string     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	Created   time.Time  `json:"created"`
+	Started   *time.Time `json:"started,omitempty"`
+	Finished  *time.Time `json:"finished,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+
+	// Summary and Instances are set only for completed runs that are requested individually.
+	Evaluations int                `json:"evaluations,omitempty"`
+	Summary     *BacktestSummary   `json:"summary,omitempty"`
+	Instances   []BacktestInstance `json:"instances,omitempty"`
+}
+
+// BacktestInstance is the history of an alert instance during a backtest run.
+type BacktestInstance struct {
+	Labels             map[string]string     `json:"labels"`
+	Transitions        []BacktestTransition  `json:"transitions"`
+	Notifications      BacktestNotifications `json:"notifications"`
+	PendingDuration    model.Duration        `json:"pending_duration"`
+	AlertingDuration   model.Duration        `json:"alerting_duration"`
+	RecoveringDuration model.Duration        `json:"recovering_duration"`
+}
+
+// BacktestTransition is a change of the state of an alert instance. The state is one of
+// Normal, Pending, Alerting, Recovering, NoData or Error.
+type BacktestTransition struct {
+	Time   time.Time `json:"time"`
+	State  string    `json:"state"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// BacktestNotifications counts the notifications that would have been sent.
+type BacktestNotifications struct {
+	Firing   int `json:"firing"`
+	Resolved int `json:"resolved"`
+}
+
+// BacktestSummary aggregates the histories of all alert instances of a backtest run.
+type BacktestSummary struct {
+	Instances          int                   `json:"instances"`
+	Transitions        int                   `json:"transitions"`
+	Notifications      BacktestNotifications `json:"notifications"`
+	PendingDuration    model.Duration        `json:"pending_duration"`
+	AlertingDuration   model.Duration        `json:"alerting_duration"`
+	RecoveringDuration model.Duration        `json:"recovering_duration"`
+}
+
+// swagger:model
+type BacktestComparison struct {
+	Base      GettableBacktestRun          `json:"base"`
+	Candidate GettableBacktestRun          `json:"candidate"`
+	Instances []BacktestInstanceComparison `json:"instances"`
+}
+
+// BacktestInstanceComparison is the history of an alert instance in both runs.
+// Either of them is missing if the instance does not exist in that run.
+type BacktestInstanceComparison struct {
+	Labels    map[string]string `json:"labels"`
+	Base      *BacktestInstance `json:"base,omitempty"`
+	Candidate *BacktestInstance `json:"candidate,omitempty"`
+}
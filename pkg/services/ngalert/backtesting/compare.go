@@ -0,0 +1,80 @@
+package backtesting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Summary aggregates the histories of all alert instances of a backtest result.
+type Summary struct {
+	Instances          int
+	Transitions        int
+	Notifications      models.BacktestNotifications
+	PendingDuration    time.Duration
+	AlertingDuration   time.Duration
+	RecoveringDuration time.Duration
+}
+
+// Summarize returns the summary of the result.
+func Summarize(result *models.BacktestResult) Summary {
+	s := Summary{}
+	if result == nil {
+		return s
+	}
+	s.Instances = len(result.Instances)
+	for _, inst := range result.Instances {
+		s.Transitions += len(inst.Transitions)
+		s.Notifications.Firing += inst.Notifications.Firing
+		s.Notifications.Resolved += inst.Notifications.Resolved
+		s.PendingDuration += inst.PendingDuration
+		s.AlertingDuration += inst.AlertingDuration
+		s.RecoveringDuration += inst.RecoveringDuration
+	}
+	return s
+}
+
+// InstanceComparison is the history of an alert instance in the base and the candidate results.
+// Either of them is nil if the instance does not exist in that result.
+type InstanceComparison struct {
+	Labels    data.Labels
+	Base      *models.BacktestInstance
+	Candidate *models.BacktestInstance
+}
+
+// Comparison compares the results of two backtest runs, for example of two versions of the same rule.
+type Comparison struct {
+	Base      Summary
+	Candidate Summary
+	Instances []InstanceComparison
+}
+
+// Compare matches the alert instances of both results by their labels. Instances are ordered as they appear
+// in the base result followed by the instances that exist only in the candidate result.
+func Compare(base, candidate *models.BacktestResult) Comparison {
+	c := Comparison{
+		Base:      Summarize(base),
+		Candidate: Summarize(candidate),
+	}
+	byFingerprint := map[data.Fingerprint]int{}
+	if base != nil {
+		for i := range base.Instances {
+			inst := &base.Instances[i]
+			byFingerprint[inst.Labels.Fingerprint()] = len(c.Instances)
+			c.Instances = append(c.Instances, InstanceComparison{Labels: inst.Labels, Base: inst})
+		}
+	}
+	if candidate != nil {
+		for i := range candidate.Instances {
+			inst := &candidate.Instances[i]
+			if idx, ok := byFingerprint[inst.Labels.Fingerprint()]; ok {
+				c.Instances[idx].Candidate = inst
+				continue
+			}
+			c.Instances = append(c.Instances, InstanceComparison{Labels: inst.Labels, Candidate: inst})
+		}
+	}
+	return c
+}
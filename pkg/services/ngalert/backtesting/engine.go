@@ -64,40 +64,19 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 }
 
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
-	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
-	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
-	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
-
-	stateManager := e.createStateManager()
-
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition().WithSource("backtesting"), &schedule.AlertingResultsFromRuleState{
-		Manager: stateManager,
-		Rule:    rule,
-	})
+	length, err := evaluationsCount(rule, from, to)
 	if err != nil {
-		return nil, errors.Join(ErrInvalidInputData, err)
+		return nil, err
 	}
 
-	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
-
 	start := time.Now()
 
 	tsField := data.NewField("Time", nil, make([]time.Time, length))
 	valueFields := make(map[data.Fingerprint]*data.Field)
 
-	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
-		if idx >= length {
-			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
-			return nil
-		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil, nil)
+	err = e.evaluate(ctx, user, rule, from, length, func(idx int, currentTime time.Time, states state.StateTransitions) {
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -114,7 +93,6 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 				continue
 			}
 		}
-		return nil
 	})
 	fields := make([]*data.Field, 0, len(valueFields)+1)
 	fields = append(fields, tsField)
@@ -130,6 +108,69 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	return result, nil
 }
 
+// Timeline evaluates the rule over the range and returns the history of the state of every alert instance.
+// Instances that stop evaluating to Alerting keep firing in Recovering state for keepFiringFor.
+func (e *Engine) Timeline(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, keepFiringFor time.Duration) (*models.BacktestResult, error) {
+	logger := logger.FromContext(ctx)
+
+	length, err := evaluationsCount(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if keepFiringFor < 0 {
+		return nil, fmt.Errorf("%w: keep firing for must not be negative", ErrInvalidInputData)
+	}
+
+	start := time.Now()
+
+	timeline := newTimelineBuilder(time.Duration(rule.IntervalSeconds)*time.Second, keepFiringFor)
+	err = e.evaluate(ctx, user, rule, from, length, timeline.add)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Rule timeline testing finished successfully", "duration", time.Since(start))
+	return timeline.result(), nil
+}
+
+// evaluationsCount returns the number of evaluations of the rule in the range.
+func evaluationsCount(rule *models.AlertRule, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds), nil
+}
+
+// evaluate evaluates the rule length times starting at from, and calls the callback with the states of the rule after each evaluation.
+func (e *Engine) evaluate(ctx context.Context, user identity.Requester, rule *models.AlertRule, from time.Time, length int, callback func(idx int, now time.Time, states state.StateTransitions)) error {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ctx)
+
+	stateManager := e.createStateManager()
+
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition().WithSource("backtesting"), &schedule.AlertingResultsFromRuleState{
+		Manager: stateManager,
+		Rule:    rule,
+	})
+	if err != nil {
+		return errors.Join(ErrInvalidInputData, err)
+	}
+
+	logger.Info("Start testing alert rule", "from", from, "interval", rule.IntervalSeconds, "evaluations", length)
+
+	return evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
+		if idx >= length {
+			logger.Info("Unexpected evaluation. Skipping", "from", from, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
+			return nil
+		}
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil, nil)
+		callback(idx, currentTime, states)
+		return nil
+	})
+}
+
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
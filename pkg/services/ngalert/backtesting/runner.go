@@ -0,0 +1,176 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// runTimeout is the maximum duration of a backtest run. Runs that are not finished after that
+	// are considered failed, for example because the instance that executed them was restarted.
+	runTimeout = time.Hour
+
+	defaultMaxConcurrentRuns = 4
+)
+
+var errRunInterrupted = errors.New("backtest run did not finish in time and was interrupted")
+
+// RunStore persists backtest runs.
+type RunStore interface {
+	InsertBacktestRun(ctx context.Context, run *models.BacktestRun) error
+	UpdateBacktestRun(ctx context.Context, run *models.BacktestRun) error
+	// GetBacktestRun returns models.ErrBacktestRunNotFound if the run does not exist.
+	GetBacktestRun(ctx context.Context, orgID int64, uid string) (*models.BacktestRun, error)
+	ListBacktestRuns(ctx context.Context, query models.ListBacktestRunsQuery) ([]*models.BacktestRun, error)
+}
+
+type timelineTester interface {
+	Timeline(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, keepFiringFor time.Duration) (*models.BacktestResult, error)
+}
+
+// Runner executes backtest runs asynchronously and persists their results.
+type Runner struct {
+	engine timelineTester
+	store  RunStore
+	clock  clock.Clock
+	// slots limits the number of runs that are executed at the same time. Runs wait in pending status for a free slot.
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewRunner(engine *Engine, store RunStore) *Runner {
+	return &Runner{
+		engine: engine,
+		store:  store,
+		clock:  clock.New(),
+		slots:  make(chan struct{}, defaultMaxConcurrentRuns),
+	}
+}
+
+// Start persists the run in pending status and executes it in the background.
+// The returned run contains the UID that can be used to get its status and result.
+func (r *Runner) Start(ctx context.Context, user identity.Requester, run models.BacktestRun) (*models.BacktestRun, error) {
+	if !run.From.Before(run.To) {
+		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, run.From.Unix(), run.To.Unix())
+	}
+	if run.Rule.KeepFiringFor < 0 {
+		return nil, fmt.Errorf("%w: keep firing for must not be negative", ErrInvalidInputData)
+	}
+	run.UID = util.GenerateShortUID()
+	run.Status = models.BacktestRunPending
+	run.Error = ""
+	run.Created = r.clock.Now()
+	run.Started = nil
+	run.Finished = nil
+	run.Result = nil
+	if user != nil {
+		run.CreatedBy = user.GetIdentifier()
+	}
+	if err := r.store.InsertBacktestRun(ctx, &run); err != nil {
+		return nil, fmt.Errorf("failed to save backtest run: %w", err)
+	}
+
+	// The run outlives the request that started it.
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), runTimeout)
+	r.wg.Add(1)
+	go func(run models.BacktestRun) {
+		defer r.wg.Done()
+		defer cancel()
+		r.execute(runCtx, user, &run)
+	}(run)
+	return &run, nil
+}
+
+func (r *Runner) execute(ctx context.Context, user identity.Requester, run *models.BacktestRun) {
+	logger := logger.FromContext(ctx).New("backtest_uid", run.UID, "rule_uid", run.RuleUID)
+
+	select {
+	case r.slots <- struct{}{}:
+		defer func() { <-r.slots }()
+	case <-ctx.Done():
+		r.finish(ctx, logger, run, nil, errRunInterrupted)
+		return
+	}
+
+	started := r.clock.Now()
+	run.Status = models.BacktestRunRunning
+	run.Started = &started
+	if err := r.store.UpdateBacktestRun(ctx, run); err != nil {
+		logger.Error("Failed to update backtest run", "error", err)
+	}
+
+	// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs
+	rule := run.Rule.AlertRule(run.OrgID, "backtesting-"+run.UID)
+	result, err := r.engine.Timeline(ctx, user, rule, run.From, run.To, run.Rule.KeepFiringFor)
+	if err != nil && ctx.Err() != nil {
+		err = errRunInterrupted
+	}
+	r.finish(ctx, logger, run, result, err)
+}
+
+func (r *Runner) finish(ctx context.Context, logger log.Logger, run *models.BacktestRun, result *models.BacktestResult, err error) {
+	finished := r.clock.Now()
+	run.Finished = &finished
+	if err != nil {
+		run.Status = models.BacktestRunFailed
+		run.Error = err.Error()
+		logger.Info("Backtest run failed", "error", err)
+	} else {
+		run.Status = models.BacktestRunCompleted
+		run.Result = result
+		logger.Info("Backtest run completed", "evaluations", result.Evaluations, "instances", len(result.Instances))
+	}
+	// Use a fresh context because the run context can be already expired.
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := r.store.UpdateBacktestRun(storeCtx, run); err != nil {
+		logger.Error("Failed to save the result of the backtest run", "error", err)
+	}
+}
+
+// Get returns the run with its result.
+func (r *Runner) Get(ctx context.Context, orgID int64, uid string) (*models.BacktestRun, error) {
+	run, err := r.store.GetBacktestRun(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	r.markInterrupted(run)
+	return run, nil
+}
+
+// List returns the runs without their results, most recent first.
+func (r *Runner) List(ctx context.Context, query models.ListBacktestRunsQuery) ([]*models.BacktestRun, error) {
+	runs, err := r.store.ListBacktestRuns(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		r.markInterrupted(run)
+	}
+	return runs, nil
+}
+
+// markInterrupted reports runs that should have finished a long time ago as failed.
+// This happens when the Grafana instance that executed them stopped before the run finished.
+func (r *Runner) markInterrupted(run *models.BacktestRun) {
+	if run.Status.IsFinished() || r.clock.Since(run.Created) <= runTimeout+time.Minute {
+		return
+	}
+	run.Status = models.BacktestRunFailed
+	run.Error = errRunInterrupted.Error()
+}
+
+// Wait blocks until all runs that were started are finished.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
@@ -0,0 +1,174 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRunner(t *testing.T) {
+	from := time.Unix(0, 0).UTC()
+	to := from.Add(time.Hour)
+	rule := models.BacktestRule{
+		Title:           "test",
+		Condition:       "A",
+		IntervalSeconds: 60,
+		KeepFiringFor:   time.Minute,
+	}
+
+	newRunner := func(tester timelineTester) (*Runner, *fakeRunStore, *clock.Mock) {
+		store := &fakeRunStore{runs: map[string]models.BacktestRun{}}
+		clk := clock.NewMock()
+		return &Runner{
+			engine: tester,
+			store:  store,
+			clock:  clk,
+			slots:  make(chan struct{}, 1),
+		}, store, clk
+	}
+
+	t.Run("should persist result of completed run", func(t *testing.T) {
+		expected := &models.BacktestResult{Evaluations: 60}
+		tester := fakeTimelineTester(func(_ context.Context, rule *models.AlertRule, f, tt time.Time, keepFiringFor time.Duration) (*models.BacktestResult, error) {
+			require.Equal(t, "test", rule.Title)
+			require.Equal(t, int64(1), rule.OrgID)
+			require.Equal(t, from, f)
+			require.Equal(t, to, tt)
+			require.Equal(t, time.Minute, keepFiringFor)
+			return expected, nil
+		})
+		runner, store, _ := newRunner(tester)
+
+		run, err := runner.Start(context.Background(), nil, models.BacktestRun{OrgID: 1, RuleUID: "rule", Rule: rule, From: from, To: to})
+		require.NoError(t, err)
+		require.NotEmpty(t, run.UID)
+		require.Equal(t, models.BacktestRunPending, run.Status)
+
+		runner.Wait()
+
+		stored, err := runner.Get(context.Background(), 1, run.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.BacktestRunCompleted, stored.Status)
+		require.Equal(t, expected, stored.Result)
+		require.NotNil(t, stored.Started)
+		require.NotNil(t, stored.Finished)
+		require.Equal(t, 3, store.updates) // pending, running, completed
+
+		runs, err := runner.List(context.Background(), models.ListBacktestRunsQuery{OrgID: 1, RuleUID: "rule"})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+	})
+
+	t.Run("should persist error of failed run", func(t *testing.T) {
+		runner, _, _ := newRunner(fakeTimelineTester(func(context.Context, *models.AlertRule, time.Time, time.Time, time.Duration) (*models.BacktestResult, error) {
+			return nil, errors.New("test error")
+		}))
+
+		run, err := runner.Start(context.Background(), nil, models.BacktestRun{OrgID: 1, Rule: rule, From: from, To: to})
+		require.NoError(t, err)
+		runner.Wait()
+
+		stored, err := runner.Get(context.Background(), 1, run.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.BacktestRunFailed, stored.Status)
+		require.Equal(t, "test error", stored.Error)
+		require.Nil(t, stored.Result)
+	})
+
+	t.Run("should reject invalid runs", func(t *testing.T) {
+		runner, store, _ := newRunner(nil)
+
+		_, err := runner.Start(context.Background(), nil, models.BacktestRun{OrgID: 1, Rule: rule, From: to, To: from})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+
+		invalid := rule
+		invalid.KeepFiringFor = -time.Second
+		_, err = runner.Start(context.Background(), nil, models.BacktestRun{OrgID: 1, Rule: invalid, From: from, To: to})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+		require.Empty(t, store.runs)
+	})
+
+	t.Run("should report unfinished runs as failed after timeout", func(t *testing.T) {
+		runner, store, clk := newRunner(nil)
+		store.runs["stale"] = models.BacktestRun{UID: "stale", OrgID: 1, Status: models.BacktestRunRunning, Created: clk.Now()}
+
+		run, err := runner.Get(context.Background(), 1, "stale")
+		require.NoError(t, err)
+		require.Equal(t, models.BacktestRunRunning, run.Status)
+
+		clk.Add(2 * runTimeout)
+		run, err = runner.Get(context.Background(), 1, "stale")
+		require.NoError(t, err)
+		require.Equal(t, models.BacktestRunFailed, run.Status)
+		require.Equal(t, errRunInterrupted.Error(), run.Error)
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		runner, _, _ := newRunner(nil)
+		_, err := runner.Get(context.Background(), 1, "unknown")
+		require.ErrorIs(t, err, models.ErrBacktestRunNotFound)
+	})
+}
+
+type fakeTimelineTester func(ctx context.Context, rule *models.AlertRule, from, to time.Time, keepFiringFor time.Duration) (*models.BacktestResult, error)
+
+func (f fakeTimelineTester) Timeline(ctx context.Context, _ identity.Requester, rule *models.AlertRule, from, to time.Time, keepFiringFor time.Duration) (*models.BacktestResult, error) {
+	return f(ctx, rule, from, to, keepFiringFor)
+}
+
+type fakeRunStore struct {
+	mtx     sync.Mutex
+	runs    map[string]models.BacktestRun
+	updates int
+}
+
+func (f *fakeRunStore) InsertBacktestRun(_ context.Context, run *models.BacktestRun) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.runs[run.UID] = *run
+	f.updates++
+	return nil
+}
+
+func (f *fakeRunStore) UpdateBacktestRun(_ context.Context, run *models.BacktestRun) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, ok := f.runs[run.UID]; !ok {
+		return models.ErrBacktestRunNotFound
+	}
+	f.runs[run.UID] = *run
+	f.updates++
+	return nil
+}
+
+func (f *fakeRunStore) GetBacktestRun(_ context.Context, orgID int64, uid string) (*models.BacktestRun, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	run, ok := f.runs[uid]
+	if !ok || run.OrgID != orgID {
+		return nil, models.ErrBacktestRunNotFound
+	}
+	return &run, nil
+}
+
+func (f *fakeRunStore) ListBacktestRuns(_ context.Context, query models.ListBacktestRunsQuery) ([]*models.BacktestRun, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []*models.BacktestRun
+	for _, run := range f.runs {
+		if run.OrgID != query.OrgID || (query.RuleUID != "" && run.RuleUID != query.RuleUID) {
+			continue
+		}
+		run.Result = nil
+		result = append(result, &run)
+	}
+	return result, nil
+}
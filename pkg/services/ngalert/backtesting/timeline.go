@@ -0,0 +1,117 @@
+package backtesting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// timelineBuilder builds the history of alert instances from the states that the state manager
+// returns after each evaluation of the backtested rule.
+//
+// The state manager already takes care of the pending period of the rule. The builder simulates keep_firing_for on top of it:
+// when an Alerting instance returns to Normal it is Recovering, and it keeps firing until keepFiringFor
+// has elapsed since the first evaluation that was not Alerting. If the condition is met again while the instance
+// is Recovering, the instance is Alerting again right away, without waiting for the pending period.
+type timelineBuilder struct {
+	interval      time.Duration
+	keepFiringFor time.Duration
+	evaluations   int
+
+	instances []*instanceTimeline
+	byID      map[data.Fingerprint]*instanceTimeline
+}
+
+type instanceTimeline struct {
+	models.BacktestInstance
+
+	firing          bool
+	recoveringSince time.Time
+}
+
+func newTimelineBuilder(interval, keepFiringFor time.Duration) *timelineBuilder {
+	return &timelineBuilder{
+		interval:      interval,
+		keepFiringFor: keepFiringFor,
+		byID:          map[data.Fingerprint]*instanceTimeline{},
+	}
+}
+
+func (b *timelineBuilder) add(_ int, now time.Time, states state.StateTransitions) {
+	b.evaluations++
+	for _, s := range states {
+		inst, ok := b.byID[s.CacheID]
+		if !ok {
+			inst = &instanceTimeline{BacktestInstance: models.BacktestInstance{Labels: s.Labels}}
+			b.byID[s.CacheID] = inst
+			b.instances = append(b.instances, inst)
+		}
+		b.addState(inst, now, s.State.State, s.StateReason)
+	}
+}
+
+func (b *timelineBuilder) addState(inst *instanceTimeline, now time.Time, st eval.State, reason string) {
+	current := st.String()
+	wasFiring := inst.firing
+	switch {
+	case st == eval.Alerting:
+		inst.firing = true
+	case wasFiring && st == eval.Pending:
+		// the condition is met again while the instance keeps firing.
+		current, reason = eval.Alerting.String(), ""
+	case wasFiring && st == eval.Normal && b.keepFiringFor > 0:
+		if inst.recoveringSince.IsZero() {
+			inst.recoveringSince = now
+		}
+		if now.Sub(inst.recoveringSince) < b.keepFiringFor {
+			current, reason = models.BacktestStateRecovering, ""
+		} else {
+			inst.firing = false
+		}
+	default:
+		inst.firing = false
+	}
+	if current != models.BacktestStateRecovering {
+		inst.recoveringSince = time.Time{}
+	}
+
+	if inst.firing && !wasFiring {
+		inst.Notifications.Firing++
+	}
+	if !inst.firing && wasFiring {
+		inst.Notifications.Resolved++
+	}
+
+	switch current {
+	case eval.Pending.String():
+		inst.PendingDuration += b.interval
+	case eval.Alerting.String():
+		inst.AlertingDuration += b.interval
+	case models.BacktestStateRecovering:
+		inst.RecoveringDuration += b.interval
+	}
+
+	if n := len(inst.Transitions); n > 0 && inst.Transitions[n-1].State == current && inst.Transitions[n-1].Reason == reason {
+		return
+	}
+	inst.Transitions = append(inst.Transitions, models.BacktestTransition{
+		Time:   now,
+		State:  current,
+		Reason: reason,
+	})
+}
+
+func (b *timelineBuilder) result() *models.BacktestResult {
+	result := &models.BacktestResult{
+		Evaluations: b.evaluations,
+		Instances:   make([]models.BacktestInstance, 0, len(b.instances)),
+	}
+	for _, inst := range b.instances {
+		result.Instances = append(result.Instances, inst.BacktestInstance)
+	}
+	return result
+}
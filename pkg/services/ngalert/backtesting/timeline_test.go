@@ -0,0 +1,122 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestTimelineBuilder(t *testing.T) {
+	interval := time.Minute
+	from := time.Unix(0, 0).UTC()
+	labels := data.Labels{"instance": "a"}
+
+	build := func(keepFiringFor time.Duration, states ...eval.State) models.BacktestInstance {
+		b := newTimelineBuilder(interval, keepFiringFor)
+		for i, s := range states {
+			b.add(i, from.Add(time.Duration(i)*interval), state.StateTransitions{
+				{State: &state.State{CacheID: labels.Fingerprint(), Labels: labels, State: s}},
+			})
+		}
+		result := b.result()
+		require.Equal(t, len(states), result.Evaluations)
+		require.Len(t, result.Instances, 1)
+		return result.Instances[0]
+	}
+
+	transitions := func(inst models.BacktestInstance) []string {
+		res := make([]string, 0, len(inst.Transitions))
+		for _, tr := range inst.Transitions {
+			res = append(res, tr.State)
+		}
+		return res
+	}
+
+	t.Run("should record only changes of state", func(t *testing.T) {
+		inst := build(0, eval.Normal, eval.Pending, eval.Alerting, eval.Alerting, eval.Normal)
+		require.Equal(t, labels, inst.Labels)
+		require.Equal(t, []string{"Normal", "Pending", "Alerting", "Normal"}, transitions(inst))
+		require.Equal(t, from.Add(2*interval), inst.Transitions[2].Time)
+		require.Equal(t, models.BacktestNotifications{Firing: 1, Resolved: 1}, inst.Notifications)
+		require.Equal(t, interval, inst.PendingDuration)
+		require.Equal(t, 2*interval, inst.AlertingDuration)
+		require.Zero(t, inst.RecoveringDuration)
+	})
+
+	t.Run("should keep firing in Recovering state for keep firing for", func(t *testing.T) {
+		inst := build(2*interval, eval.Alerting, eval.Normal, eval.Normal, eval.Normal)
+		require.Equal(t, []string{"Alerting", models.BacktestStateRecovering, "Normal"}, transitions(inst))
+		require.Equal(t, from.Add(3*interval), inst.Transitions[2].Time)
+		require.Equal(t, models.BacktestNotifications{Firing: 1, Resolved: 1}, inst.Notifications)
+		require.Equal(t, 2*interval, inst.RecoveringDuration)
+	})
+
+	t.Run("should return to Alerting without notification if condition is met while recovering", func(t *testing.T) {
+		inst := build(2*interval, eval.Alerting, eval.Normal, eval.Pending, eval.Alerting, eval.Normal, eval.Normal, eval.Normal)
+		require.Equal(t, []string{"Alerting", models.BacktestStateRecovering, "Alerting", models.BacktestStateRecovering, "Normal"}, transitions(inst))
+		require.Equal(t, models.BacktestNotifications{Firing: 1, Resolved: 1}, inst.Notifications)
+		require.Equal(t, 3*interval, inst.AlertingDuration)
+		require.Equal(t, 3*interval, inst.RecoveringDuration)
+	})
+
+	t.Run("should resolve if instance goes to NoData or Error", func(t *testing.T) {
+		inst := build(2*interval, eval.Alerting, eval.NoData, eval.Alerting, eval.Error)
+		require.Equal(t, []string{"Alerting", "NoData", "Alerting", "Error"}, transitions(inst))
+		require.Equal(t, models.BacktestNotifications{Firing: 2, Resolved: 2}, inst.Notifications)
+	})
+
+	t.Run("should keep instances in order of appearance", func(t *testing.T) {
+		b := newTimelineBuilder(interval, 0)
+		other := data.Labels{"instance": "b"}
+		b.add(0, from, state.StateTransitions{
+			{State: &state.State{CacheID: other.Fingerprint(), Labels: other, State: eval.Normal}},
+		})
+		b.add(1, from.Add(interval), state.StateTransitions{
+			{State: &state.State{CacheID: other.Fingerprint(), Labels: other, State: eval.Normal}},
+			{State: &state.State{CacheID: labels.Fingerprint(), Labels: labels, State: eval.Alerting, StateReason: "test"}},
+		})
+		result := b.result()
+		require.Len(t, result.Instances, 2)
+		require.Equal(t, other, result.Instances[0].Labels)
+		require.Equal(t, []models.BacktestTransition{{Time: from.Add(interval), State: "Alerting", Reason: "test"}}, result.Instances[1].Transitions)
+	})
+}
+
+func TestCompare(t *testing.T) {
+	a := data.Labels{"instance": "a"}
+	b := data.Labels{"instance": "b"}
+	c := data.Labels{"instance": "c"}
+	base := &models.BacktestResult{
+		Evaluations: 10,
+		Instances: []models.BacktestInstance{
+			{Labels: a, Notifications: models.BacktestNotifications{Firing: 2, Resolved: 2}, AlertingDuration: time.Minute},
+			{Labels: b, Notifications: models.BacktestNotifications{Firing: 1}, AlertingDuration: 2 * time.Minute},
+		},
+	}
+	candidate := &models.BacktestResult{
+		Evaluations: 10,
+		Instances: []models.BacktestInstance{
+			{Labels: c, Notifications: models.BacktestNotifications{Firing: 1}},
+			{Labels: a, Notifications: models.BacktestNotifications{Firing: 1, Resolved: 1}, AlertingDuration: 3 * time.Minute},
+		},
+	}
+
+	result := Compare(base, candidate)
+
+	require.Equal(t, Summary{Instances: 2, Notifications: models.BacktestNotifications{Firing: 3, Resolved: 2}, AlertingDuration: 3 * time.Minute}, result.Base)
+	require.Equal(t, Summary{Instances: 2, Notifications: models.BacktestNotifications{Firing: 2, Resolved: 1}, AlertingDuration: 3 * time.Minute}, result.Candidate)
+	require.Len(t, result.Instances, 3)
+	require.Equal(t, a, result.Instances[0].Labels)
+	require.Equal(t, &base.Instances[0], result.Instances[0].Base)
+	require.Equal(t, &candidate.Instances[1], result.Instances[0].Candidate)
+	require.Equal(t, b, result.Instances[1].Labels)
+	require.Nil(t, result.Instances[1].Candidate)
+	require.Equal(t, c, result.Instances[2].Labels)
+	require.Nil(t, result.Instances[2].Base)
+}
@@ -12,6 +12,9 @@ import (
 // It is used during the evaluation of queries.
 type AlertingResultsReader interface {
 	Read() map[data.Fingerprint]struct{}
+	// ReadLevels returns the severity level of the results that are in alerting state, for conditions
+	// that are threshold expressions with levels. Levels start at 1 for the least severe level.
+	ReadLevels() map[data.Fingerprint]int
 }
 
 // EvaluationContext represents the context in which a condition is evaluated.
@@ -397,6 +397,25 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 					}
 				}
 			}
+
+			levels, err := q.GetThresholdLevels()
+			if err != nil {
+				return nil, fmt.Errorf("failed to build query '%s': %w", q.RefID, err)
+			}
+			if len(levels) > 0 {
+				// like hysteresis, the levels of the previous results can only be applied to the alert condition.
+				if q.RefID != condition.Condition {
+					return nil, fmt.Errorf("threshold with levels '%s' is only allowed to be the alert condition", q.RefID)
+				}
+				if reader != nil {
+					loaded := reader.ReadLevels()
+					logger.FromContext(ctx.Ctx).Debug("Detected threshold command with levels. Populating with the results", "items", len(loaded))
+					err = q.PatchThresholdLevelsExpression(loaded)
+					if err != nil {
+						return nil, fmt.Errorf("failed to amend threshold command with levels '%s': %w", q.RefID, err)
+					}
+				}
+			}
 		}
 
 		model, err := q.GetModel()
@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// QueryCostCollector sums up the cost of the data source queries made by an evaluation.
+// The duration is the time the data source requests took, and the frames are the ones returned by the data sources.
+// Expressions are not included because they do not hit any data source.
+type QueryCostCollector struct {
+	mtx  sync.Mutex
+	cost models.QueryCost
+}
+
+// WithQueryCostCollector returns a context that makes the evaluations run with it report the cost of their data source queries to the returned collector.
+func WithQueryCostCollector(ctx context.Context) (context.Context, *QueryCostCollector) {
+	c := &QueryCostCollector{}
+	return expr.WithDatasourceQueryObserver(ctx, c.observe), c
+}
+
+// Cost returns the cost of the data source queries made so far.
+func (c *QueryCostCollector) Cost() models.QueryCost {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.cost
+}
+
+func (c *QueryCostCollector) observe(resp *backend.QueryDataResponse, _ error, dur time.Duration) {
+	cost := models.QueryCost{Duration: dur}
+	if resp != nil {
+		for _, r := range resp.Responses {
+			for _, frame := range r.Frames {
+				if frame == nil {
+					continue
+				}
+				cost.Frames++
+				for _, field := range frame.Fields {
+					if field.Type().Numeric() {
+						cost.Series++
+					}
+					cost.Bytes += fieldSize(field)
+				}
+			}
+		}
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.cost = c.cost.Add(cost)
+}
+
+// fieldSize estimates the size of the values of the field. Strings are counted by their length, all other values as 8 bytes.
+func fieldSize(field *data.Field) int64 {
+	switch field.Type() {
+	case data.FieldTypeString:
+		var size int64
+		for i := 0; i < field.Len(); i++ {
+			size += int64(len(field.At(i).(string)))
+		}
+		return size
+	case data.FieldTypeNullableString:
+		var size int64
+		for i := 0; i < field.Len(); i++ {
+			if v := field.At(i).(*string); v != nil {
+				size += int64(len(*v))
+			}
+		}
+		return size
+	default:
+		return int64(field.Len()) * 8
+	}
+}
@@ -0,0 +1,49 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestQueryCostCollector(t *testing.T) {
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{{}, {}}),
+				data.NewField("value", data.Labels{"instance": "a"}, []float64{1, 2}),
+				data.NewField("other", data.Labels{"instance": "b"}, []*float64{util.Pointer(1.0), nil}),
+			),
+			data.NewFrame("",
+				data.NewField("name", nil, []string{"abc", "de"}),
+				data.NewField("nullable", nil, []*string{util.Pointer("f"), nil}),
+			),
+		}},
+	}}
+
+	_, collector := WithQueryCostCollector(context.Background())
+	collector.observe(resp, nil, time.Second)
+	require.Equal(t, models.QueryCost{
+		Duration: time.Second,
+		Series:   2,
+		Frames:   2,
+		Bytes:    3*2*8 + 5 + 1,
+	}, collector.Cost())
+
+	t.Run("should add up the requests of an evaluation", func(t *testing.T) {
+		_, collector := WithQueryCostCollector(context.Background())
+		collector.observe(nil, errors.New("failed"), time.Second)
+		collector.observe(&backend.QueryDataResponse{Responses: backend.Responses{
+			"B": {Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}},
+		}}, nil, time.Second)
+		require.Equal(t, models.QueryCost{Duration: 2 * time.Second, Series: 1, Frames: 1, Bytes: 8}, collector.Cost())
+	})
+}
@@ -88,8 +88,13 @@ func WithValues(values map[string]NumberValueCapture) ResultMutator {
 
 type FakeLoadedMetricsReader struct {
 	fingerprints map[data.Fingerprint]struct{}
+	levels       map[data.Fingerprint]int
 }
 
 func (f FakeLoadedMetricsReader) Read() map[data.Fingerprint]struct{} {
 	return f.fingerprints
 }
+
+func (f FakeLoadedMetricsReader) ReadLevels() map[data.Fingerprint]int {
+	return f.levels
+}
@@ -6,8 +6,11 @@ import (
 )
 
 type RemoteWriter struct {
-	WritesTotal   *prometheus.CounterVec
-	WriteDuration *prometheus.HistogramVec
+	WritesTotal        *prometheus.CounterVec
+	WriteDuration      *prometheus.HistogramVec
+	RetriesTotal       *prometheus.CounterVec
+	DroppedPointsTotal *prometheus.CounterVec
+	BatchSize          *prometheus.HistogramVec
 }
 
 func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
@@ -26,5 +29,25 @@ func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
 				Help:      "Histogram of remote write durations.",
 				Buckets:   prometheus.DefBuckets,
 			}, []string{"org", "backend"}),
+		RetriesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_retries_total",
+			Help:      "The total number of retried remote writes.",
+		}, []string{"org", "backend"}),
+		DroppedPointsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_dropped_points_total",
+			Help:      "The total number of points that were dropped because they could not be written.",
+		}, []string{"org", "backend"}),
+		BatchSize: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "remote_writer_batch_size",
+				Help:      "Histogram of the number of points in remote write batches.",
+				Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+			}, []string{"backend"}),
 	}
 }
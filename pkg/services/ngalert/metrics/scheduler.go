@@ -32,6 +32,11 @@ type Scheduler struct {
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
 	SimplifiedEditorRules               *prometheus.GaugeVec
+	QueryDuration                       *prometheus.CounterVec
+	QuerySeries                         *prometheus.CounterVec
+	QueryFrames                         *prometheus.CounterVec
+	QueryBytes                          *prometheus.CounterVec
+	QueryBudgetExceeded                 *prometheus.CounterVec
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -192,5 +197,50 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "setting"},
 		),
+		QueryDuration: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_query_duration_seconds_total",
+				Help:      "The total time spent executing data source queries of rules.",
+			},
+			[]string{"org", "folder"},
+		),
+		QuerySeries: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_query_series_total",
+				Help:      "The total number of series returned by data source queries of rules.",
+			},
+			[]string{"org", "folder"},
+		),
+		QueryFrames: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_query_frames_total",
+				Help:      "The total number of data frames returned by data source queries of rules.",
+			},
+			[]string{"org", "folder"},
+		),
+		QueryBytes: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_query_bytes_total",
+				Help:      "The estimated total size of the data returned by data source queries of rules.",
+			},
+			[]string{"org", "folder"},
+		),
+		QueryBudgetExceeded: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_query_budget_exceeded_total",
+				Help:      "The total number of rule evaluations that exceeded the query budget.",
+			},
+			[]string{"org", "folder", "action"},
+		),
 	}
 }
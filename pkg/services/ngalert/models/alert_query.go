@@ -140,6 +140,30 @@ func (aq *AlertQuery) PatchHysteresisExpression(loadedMetrics map[data.Fingerpri
 	return expr.SetLoadedDimensionsToHysteresisCommand(aq.modelProps, loadedMetrics)
 }
 
+// GetThresholdLevels returns the names of the levels if the model describes a threshold expression with levels,
+// ordered from the least to the most severe level. Returns error if the Model is not a valid JSON
+func (aq *AlertQuery) GetThresholdLevels() ([]string, error) {
+	if aq.modelProps == nil {
+		err := aq.setModelProps()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expr.GetThresholdLevels(aq.modelProps), nil
+}
+
+// PatchThresholdLevelsExpression updates the AlertQuery to include the levels of the results of the previous evaluation
+// into a threshold expression with levels
+func (aq *AlertQuery) PatchThresholdLevelsExpression(loadedLevels map[data.Fingerprint]int) error {
+	if aq.modelProps == nil {
+		err := aq.setModelProps()
+		if err != nil {
+			return err
+		}
+	}
+	return expr.SetLoadedLevelsToThresholdLevelsCommand(aq.modelProps, loadedLevels)
+}
+
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
 func (aq *AlertQuery) setMaxDatapoints() error {
 	if aq.modelProps == nil {
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -138,6 +139,9 @@ const (
 	// StateReasonAnnotation is the name of the annotation that explains the difference between evaluation state and alert state (i.e. changing state when NoData or Error).
 	StateReasonAnnotation = GrafanaReservedLabelPrefix + "state_reason"
 
+	// ThresholdLevelAnnotation is the name of the annotation that contains the severity level of an alert whose condition is a threshold expression with levels.
+	ThresholdLevelAnnotation = GrafanaReservedLabelPrefix + "level"
+
 	// MigratedLabelPrefix is a label prefix for all labels created during legacy migration.
 	MigratedLabelPrefix = "__legacy_"
 	// MigratedUseLegacyChannelsLabel is created during legacy migration to route to separate nested policies for migrated channels.
@@ -170,6 +174,8 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	// StateReasonQueryBudgetExceeded is the reason of the states of a rule that is not evaluated because it exceeded its query budget.
+	StateReasonQueryBudgetExceeded = "QueryBudgetExceeded"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -297,6 +303,9 @@ type AlertRule struct {
 type AlertRuleMetadata struct {
 	EditorSettings      EditorSettings       `json:"editor_settings"`
 	PrometheusStyleRule *PrometheusStyleRule `json:"prometheus_style_rule,omitempty"`
+	// SequentialEvaluation is set on all rules of a group whose rules are evaluated one after another
+	// in dependency order instead of independently.
+	SequentialEvaluation bool `json:"sequential_evaluation,omitempty"`
 }
 
 type EditorSettings struct {
@@ -442,6 +451,23 @@ func (alertRule *AlertRule) GetEvalCondition() Condition {
 	}
 }
 
+// GetThresholdLevels returns the names of the levels of the condition of the rule, ordered from the least to the most
+// severe level, if the condition is a threshold expression with levels. Otherwise, it returns nil.
+func (alertRule *AlertRule) GetThresholdLevels() []string {
+	for _, q := range alertRule.Data {
+		// skip parsing the models of queries that cannot have levels
+		if q.RefID != alertRule.Condition || !bytes.Contains(q.Model, []byte(`"level"`)) {
+			continue
+		}
+		levels, err := q.GetThresholdLevels()
+		if err != nil {
+			return nil
+		}
+		return levels
+	}
+	return nil
+}
+
 // Diff calculates diff between two alert rules. Returns nil if two rules are equal. Otherwise, returns cmputil.DiffReport
 func (alertRule *AlertRule) Diff(rule *AlertRule, ignore ...string) cmputil.DiffReport {
 	var reporter cmputil.DiffReporter
@@ -937,11 +963,12 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
 
-	// Currently metadata contains only editor settings, so we can just copy it.
-	// If we add more fields to metadata, we might need to handle them separately,
-	// and/or merge or update their values.
+	// Metadata of the rule contains editor settings, which we can just copy. Sequential evaluation is a
+	// setting of the rule group, which is always specified together with the group.
 	if !ruleToPatch.HasMetadata {
+		sequential := ruleToPatch.Metadata.SequentialEvaluation
 		ruleToPatch.Metadata = existingRule.Metadata
+		ruleToPatch.Metadata.SequentialEvaluation = sequential
 	}
 }
 
@@ -1034,4 +1061,27 @@ type RuleStatus struct {
 	LastError           error
 	EvaluationTimestamp time.Time
 	EvaluationDuration  time.Duration
+	// QueryCost is the cost of the data source queries made by the latest evaluation.
+	QueryCost QueryCost
+}
+
+// QueryCost describes the cost of the data source queries made by a single rule evaluation.
+type QueryCost struct {
+	// Duration is the time the data source requests took. Expressions are not included.
+	Duration time.Duration
+	// Series is the number of numeric fields in all frames returned by the queries.
+	Series int
+	Frames int
+	// Bytes is an estimate of the size of the data returned by the queries.
+	Bytes int64
+}
+
+// Add returns the sum of both costs.
+func (c QueryCost) Add(other QueryCost) QueryCost {
+	return QueryCost{
+		Duration: c.Duration + other.Duration,
+		Series:   c.Series + other.Series,
+		Frames:   c.Frames + other.Frames,
+		Bytes:    c.Bytes + other.Bytes,
+	}
 }
@@ -356,6 +356,12 @@ func TestPatchPartialAlertRule(t *testing.T) {
 					r.Labels = nil
 				},
 			},
+			{
+				name: "Metadata.SequentialEvaluation",
+				mutator: func(r *AlertRule) {
+					r.Metadata.SequentialEvaluation = !r.Metadata.SequentialEvaluation
+				},
+			},
 		}
 
 		gen := RuleGen.With(
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+var (
+	ErrBacktestRunNotFound = errors.New("backtest run not found")
+)
+
+// BacktestRunStatus is the status of an asynchronous backtest run.
+type BacktestRunStatus string
+
+const (
+	BacktestRunPending   BacktestRunStatus = "pending"
+	BacktestRunRunning   BacktestRunStatus = "running"
+	BacktestRunCompleted BacktestRunStatus = "completed"
+	BacktestRunFailed    BacktestRunStatus = "failed"
+)
+
+// IsFinished returns true if the run has completed or failed.
+func (s BacktestRunStatus) IsFinished() bool {
+	return s == BacktestRunCompleted || s == BacktestRunFailed
+}
+
+// BacktestStateRecovering is the state of an instance whose condition is no longer met
+// but that keeps firing because of the keep_firing_for of the backtested rule.
+const BacktestStateRecovering = "Recovering"
+
+// BacktestRule is the definition of the alert rule that is backtested.
+type BacktestRule struct {
+	Title           string              `json:"title"`
+	Condition       string              `json:"condition"`
+	Data            []AlertQuery        `json:"data"`
+	IntervalSeconds int64               `json:"intervalSeconds"`
+	For             time.Duration       `json:"for"`
+	KeepFiringFor   time.Duration       `json:"keepFiringFor"`
+	NoDataState     NoDataState         `json:"noDataState"`
+	ExecErrState    ExecutionErrorState `json:"execErrState"`
+	Labels          map[string]string   `json:"labels,omitempty"`
+	Annotations     map[string]string   `json:"annotations,omitempty"`
+}
+
+// AlertRule returns the alert rule that is evaluated by the backtest run.
+func (r BacktestRule) AlertRule(orgID int64, uid string) *AlertRule {
+	return &AlertRule{
+		Title:           r.Title,
+		UID:             uid,
+		OrgID:           orgID,
+		Condition:       r.Condition,
+		Data:            r.Data,
+		IntervalSeconds: r.IntervalSeconds,
+		For:             r.For,
+		NoDataState:     r.NoDataState,
+		ExecErrState:    r.ExecErrState,
+		Labels:          r.Labels,
+		Annotations:     r.Annotations,
+	}
+}
+
+// BacktestRun is a backtest of an alert rule that is evaluated asynchronously and whose results are persisted.
+type BacktestRun struct {
+	UID   string
+	OrgID int64
+	// RuleUID is the UID of the stored alert rule that the backtested definition is a version of. It is empty
+	// if the definition is not related to a stored rule. RuleVersion is set when a stored version is backtested.
+	RuleUID     string
+	RuleVersion int64
+	Rule        BacktestRule
+	From        time.Time
+	To          time.Time
+
+	Status   BacktestRunStatus
+	Error    string
+	Created  time.Time
+	Started  *time.Time
+	Finished *time.Time
+	// CreatedBy is the UID of the user that started the run.
+	CreatedBy string
+
+	// Result is nil until the run completes. It is not loaded when runs are listed.
+	Result *BacktestResult
+}
+
+// ListBacktestRunsQuery is the query for listing backtest runs of an organization, most recent first.
+type ListBacktestRunsQuery struct {
+	OrgID int64
+	// RuleUID filters runs that are related to the stored rule.
+	RuleUID string
+	Limit   int
+}
+
+// BacktestResult is the result of a backtest run.
+type BacktestResult struct {
+	// Evaluations is the number of evaluations of the rule over the backtested range.
+	Evaluations int                `json:"evaluations"`
+	Instances   []BacktestInstance `json:"instances"`
+}
+
+// BacktestInstance is the history of an alert instance during a backtest run.
+type BacktestInstance struct {
+	Labels data.Labels `json:"labels"`
+	// Transitions contains the changes of the state of the instance, starting with the state in which it first appeared.
+	Transitions   []BacktestTransition  `json:"transitions"`
+	Notifications BacktestNotifications `json:"notifications"`
+	// Durations are the total time the instance spent in Pending, Alerting and Recovering states.
+	PendingDuration    time.Duration `json:"pendingDuration"`
+	AlertingDuration   time.Duration `json:"alertingDuration"`
+	RecoveringDuration time.Duration `json:"recoveringDuration"`
+}
+
+// BacktestTransition is a change of the state of an instance at the time of an evaluation.
+type BacktestTransition struct {
+	Time   time.Time `json:"time"`
+	State  string    `json:"state"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// BacktestNotifications counts the notifications that the instance would have sent.
+type BacktestNotifications struct {
+	Firing   int `json:"firing"`
+	Resolved int `json:"resolved"`
+}
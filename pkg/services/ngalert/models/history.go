@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 )
 
@@ -13,8 +15,40 @@ type HistoryQuery struct {
 	DashboardUID string
 	PanelID      int64
 	Labels       map[string]string
+	// State filters transitions by the state the alert instance transitioned to, e.g. "Alerting".
+	// Formatted states with a reason, such as "Alerting (Error)", match their base state.
+	State        string
 	From         time.Time
 	To           time.Time
 	Limit        int
 	SignedInUser identity.Requester
 }
+
+// StateHistoryEntry is a single state transition of an alert instance stored in the database.
+type StateHistoryEntry struct {
+	ID           int64
+	OrgID        int64
+	RuleUID      string
+	RuleID       int64
+	RuleTitle    string
+	RuleGroup    string
+	NamespaceUID string
+	DashboardUID string
+	PanelID      int64
+	Condition    string
+	Fingerprint  string
+	Labels       data.Labels
+	Previous     string
+	Current      string
+	Error        string
+	// Values is the JSON encoded map of values of the evaluation that caused the transition.
+	Values    string
+	Timestamp time.Time
+}
+
+// StateHistoryEntriesQuery is used to find state history entries in the database.
+type StateHistoryEntriesQuery struct {
+	HistoryQuery
+	// NamespaceUIDs, if not empty, restricts the result to entries of rules in the given folders.
+	NamespaceUIDs []string
+}
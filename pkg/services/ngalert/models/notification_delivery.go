@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationDeliveryStatus is the outcome of an attempt to deliver a notification.
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliverySuccess NotificationDeliveryStatus = "success"
+	NotificationDeliveryFailure NotificationDeliveryStatus = "failure"
+)
+
+// NotificationDelivery is a single attempt to deliver a notification of an alert group to an integration of a receiver.
+type NotificationDelivery struct {
+	ID    int64
+	OrgID int64
+	// Receiver is the name of the contact point.
+	Receiver string
+	// Integration is the type of the integration, e.g. slack or email.
+	Integration      string
+	IntegrationIndex int
+	IntegrationUID   string
+	GroupKey         string
+	// Attempt is the number of the attempt to deliver the notification, starting at 1 and increased on every retry.
+	Attempt int
+	Status  NotificationDeliveryStatus
+	// StatusCode is the HTTP status code returned by the receiver, or 0 if it is unknown.
+	StatusCode int
+	// Response is the beginning of the body of the HTTP response of the receiver.
+	Response string
+	Error    string
+	// Retry is true if a failed attempt will be retried.
+	Retry          bool
+	Duration       time.Duration
+	FiringAlerts   int
+	ResolvedAlerts int
+	Timestamp      time.Time
+}
+
+// NotificationDeliveriesQuery is used to find notification delivery records.
+type NotificationDeliveriesQuery struct {
+	OrgID int64
+	// Receivers, if not nil, restricts the result to deliveries to the given receivers.
+	Receivers   []string
+	Integration string
+	GroupKey    string
+	Status      NotificationDeliveryStatus
+	From        time.Time
+	To          time.Time
+	Limit       int
+}
@@ -0,0 +1,394 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// rruleSearchDays limits how many days are searched for the next occurrence of an RRULE.
+const rruleSearchDays = 366 * 10
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// SilenceSchedule defines the occurrences of a recurring silence.
+type SilenceSchedule interface {
+	// Next returns the first occurrence after the given time, or the zero time if there are no more occurrences.
+	Next(after time.Time) time.Time
+}
+
+// ParseSilenceSchedule parses a schedule of a recurring silence, which is evaluated in the given location.
+// The schedule is either a standard cron expression with five fields, e.g. "0 22 * * 6", or a recurrence rule
+// as defined in RFC 5545 with an optional start, e.g. "DTSTART:20240106T220000\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SA".
+// Recurrence rules support the DAILY, WEEKLY and MONTHLY frequencies.
+func ParseSilenceSchedule(schedule string, loc *time.Location) (SilenceSchedule, error) {
+	schedule = strings.TrimSpace(schedule)
+	if schedule == "" {
+		return nil, errors.New("schedule must not be empty")
+	}
+	if strings.Contains(strings.ToUpper(schedule), "FREQ=") {
+		return parseRRule(schedule, loc)
+	}
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		return nil, errors.New("the timezone of a cron expression must be set in the timezone field")
+	}
+	s, err := cronParser.Parse(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := s.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+	return s, nil
+}
+
+type rruleFrequency string
+
+const (
+	rruleDaily   rruleFrequency = "DAILY"
+	rruleWeekly  rruleFrequency = "WEEKLY"
+	rruleMonthly rruleFrequency = "MONTHLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// rruleWeekday is a day of the week of the BYDAY part. For monthly rules, n is the position
+// of the day in the month, e.g. 1 for the first or -1 for the last one. It is 0 for every such day.
+type rruleWeekday struct {
+	weekday time.Weekday
+	n       int
+}
+
+type rruleSchedule struct {
+	loc        *time.Location
+	dtstart    time.Time
+	freq       rruleFrequency
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []rruleWeekday
+	byMonthDay []int
+	byMonth    []time.Month
+	byHour     []int
+	byMinute   []int
+}
+
+func parseRRule(schedule string, loc *time.Location) (*rruleSchedule, error) {
+	r := &rruleSchedule{loc: loc, interval: 1}
+	var rule string
+	hasStart := false
+	for _, line := range strings.Split(schedule, "\n") {
+		line = strings.TrimSpace(line)
+		upper := strings.ToUpper(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(upper, "DTSTART"):
+			start, err := parseRRuleStart(line, loc)
+			if err != nil {
+				return nil, err
+			}
+			r.dtstart = start
+			r.loc = start.Location()
+			hasStart = true
+		case strings.HasPrefix(upper, "RRULE:"):
+			rule = upper[len("RRULE:"):]
+		case strings.HasPrefix(upper, "FREQ="):
+			rule = upper
+		default:
+			return nil, fmt.Errorf("unsupported line %q", line)
+		}
+	}
+	if rule == "" {
+		return nil, errors.New("RRULE is missing")
+	}
+	if !hasStart {
+		r.dtstart = time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			r.freq = rruleFrequency(value)
+			if r.freq != rruleDaily && r.freq != rruleWeekly && r.freq != rruleMonthly {
+				return nil, fmt.Errorf("unsupported frequency %q, must be one of DAILY, WEEKLY or MONTHLY", value)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count < 1 {
+				err = errors.New("must be positive")
+			}
+		case "UNTIL":
+			r.until, err = parseRRuleTime(value, r.loc, true)
+		case "BYDAY":
+			r.byDay, err = parseRRuleWeekdays(value)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseRRuleInts(value, -31, 31, false)
+		case "BYMONTH":
+			var months []int
+			months, err = parseRRuleInts(value, 1, 12, true)
+			for _, m := range months {
+				r.byMonth = append(r.byMonth, time.Month(m))
+			}
+		case "BYHOUR":
+			r.byHour, err = parseRRuleInts(value, 0, 23, true)
+		case "BYMINUTE":
+			r.byMinute, err = parseRRuleInts(value, 0, 59, true)
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("only MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL cannot be used together")
+	}
+	if !hasStart && (r.interval > 1 || r.count > 0) {
+		return nil, errors.New("DTSTART is required if INTERVAL or COUNT are set")
+	}
+	if !hasStart && r.freq == rruleWeekly && len(r.byDay) == 0 {
+		return nil, errors.New("DTSTART or BYDAY is required for a weekly rule")
+	}
+	if !hasStart && r.freq == rruleMonthly && len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+		return nil, errors.New("DTSTART, BYDAY or BYMONTHDAY is required for a monthly rule")
+	}
+	if r.freq == rruleWeekly && len(r.byMonthDay) > 0 {
+		return nil, errors.New("BYMONTHDAY cannot be used with a weekly rule")
+	}
+	for _, d := range r.byDay {
+		if d.n != 0 && r.freq != rruleMonthly {
+			return nil, errors.New("positions of days in BYDAY are only supported by monthly rules")
+		}
+	}
+	slices.Sort(r.byHour)
+	slices.Sort(r.byMinute)
+	return r, nil
+}
+
+func parseRRuleStart(line string, loc *time.Location) (time.Time, error) {
+	params, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid DTSTART %q", line)
+	}
+	for _, param := range strings.Split(params, ";")[1:] {
+		key, tz, _ := strings.Cut(param, "=")
+		if strings.ToUpper(key) != "TZID" {
+			return time.Time{}, fmt.Errorf("unsupported DTSTART parameter %s", key)
+		}
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid DTSTART timezone: %w", err)
+		}
+		loc = l
+	}
+	t, err := parseRRuleTime(strings.ToUpper(value), loc, false)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+	// Times in UTC are evaluated in the location of the schedule.
+	return t.In(loc), nil
+}
+
+func parseRRuleTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	if len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err == nil && endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return t, err
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+func parseRRuleWeekdays(value string) ([]rruleWeekday, error) {
+	var result []rruleWeekday
+	for _, s := range strings.Split(value, ",") {
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid day %q", s)
+		}
+		wd, ok := rruleWeekdays[s[len(s)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", s)
+		}
+		d := rruleWeekday{weekday: wd}
+		if pos := s[:len(s)-2]; pos != "" {
+			n, err := strconv.Atoi(pos)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid day %q", s)
+			}
+			d.n = n
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func parseRRuleInts(value string, minValue, maxValue int, allowZero bool) ([]int, error) {
+	var result []int
+	for _, s := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < minValue || n > maxValue || (n == 0 && !allowZero) {
+			return nil, fmt.Errorf("invalid value %q", s)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func (r *rruleSchedule) Next(after time.Time) time.Time {
+	after = after.In(r.loc)
+	day := dateOf(r.dtstart, r.loc)
+	// Occurrences must be counted from the start if the number of occurrences is limited.
+	if r.count == 0 && after.After(r.dtstart) {
+		day = dateOf(after, r.loc)
+	}
+	n := 0
+	for i := 0; i < rruleSearchDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if !r.until.IsZero() && day.After(r.until) {
+			return time.Time{}
+		}
+		if !r.matchesDay(day) {
+			continue
+		}
+		for _, t := range r.times(day) {
+			if t.Before(r.dtstart) {
+				continue
+			}
+			if !r.until.IsZero() && t.After(r.until) {
+				return time.Time{}
+			}
+			n++
+			if r.count > 0 && n > r.count {
+				return time.Time{}
+			}
+			if t.After(after) {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (r *rruleSchedule) matchesDay(day time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, day.Month()) {
+		return false
+	}
+	start := dateOf(r.dtstart, r.loc)
+	switch r.freq {
+	case rruleDaily:
+		if daysBetween(start, day)%r.interval != 0 {
+			return false
+		}
+		return (len(r.byDay) == 0 || r.matchesWeekday(day)) && (len(r.byMonthDay) == 0 || r.matchesMonthDay(day))
+	case rruleWeekly:
+		if (daysBetween(weekStart(start), weekStart(day))/7)%r.interval != 0 {
+			return false
+		}
+		if len(r.byDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+		return r.matchesWeekday(day)
+	case rruleMonthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%r.interval != 0 {
+			return false
+		}
+		if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+			return day.Day() == start.Day()
+		}
+		return (len(r.byDay) == 0 || r.matchesWeekday(day)) && (len(r.byMonthDay) == 0 || r.matchesMonthDay(day))
+	}
+	return false
+}
+
+func (r *rruleSchedule) matchesWeekday(day time.Time) bool {
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.byDay {
+		if d.weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case d.n == 0:
+			return true
+		case d.n > 0 && (day.Day()-1)/7+1 == d.n:
+			return true
+		case d.n < 0 && (daysInMonth-day.Day())/7+1 == -d.n:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rruleSchedule) matchesMonthDay(day time.Time) bool {
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.byMonthDay {
+		if d == day.Day() || (d < 0 && daysInMonth+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// times returns the occurrences in the given day in chronological order.
+func (r *rruleSchedule) times(day time.Time) []time.Time {
+	hours, minutes := r.byHour, r.byMinute
+	if len(hours) == 0 {
+		hours = []int{r.dtstart.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{r.dtstart.Minute()}
+	}
+	result := make([]time.Time, 0, len(hours)*len(minutes))
+	for _, h := range hours {
+		for _, m := range minutes {
+			result = append(result, time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, r.loc))
+		}
+	}
+	return result
+}
+
+// dateOf returns the midnight of the day of the time in the given location.
+func dateOf(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// daysBetween returns the number of calendar days between two dates, regardless of daylight saving time.
+func daysBetween(from, to time.Time) int {
+	f := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	t := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(t.Sub(f).Hours() / 24)
+}
+
+// weekStart returns the Monday of the week of the date.
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestParseSilenceSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Monday
+	from := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		schedule string
+		loc      *time.Location
+		expected []time.Time
+	}{
+		{
+			name:     "cron expression",
+			schedule: "0 22 * * 6",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 6, 22, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 13, 22, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "cron expression in a location",
+			schedule: "30 2 * * *",
+			loc:      berlin,
+			expected: []time.Time{
+				time.Date(2024, 1, 2, 2, 30, 0, 0, berlin),
+				time.Date(2024, 1, 3, 2, 30, 0, 0, berlin),
+			},
+		},
+		{
+			name:     "weekly rule without start",
+			schedule: "RRULE:FREQ=WEEKLY;BYDAY=SA,SU;BYHOUR=22",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 6, 22, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 7, 22, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 13, 22, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "bi-weekly rule with start in a location",
+			schedule: "DTSTART;TZID=Europe/Berlin:20231230T220000\nRRULE:FREQ=WEEKLY;INTERVAL=2",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 13, 22, 0, 0, 0, berlin),
+				time.Date(2024, 1, 27, 22, 0, 0, 0, berlin),
+			},
+		},
+		{
+			name:     "daily rule with several times",
+			schedule: "FREQ=DAILY;BYHOUR=6,18;BYMINUTE=15",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 1, 18, 15, 0, 0, time.UTC),
+				time.Date(2024, 1, 2, 6, 15, 0, 0, time.UTC),
+				time.Date(2024, 1, 2, 18, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "monthly rule on the last friday",
+			schedule: "RRULE:FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=20",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 26, 20, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 23, 20, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "monthly rule on the last day",
+			schedule: "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "rule with count",
+			schedule: "DTSTART:20231231T100000Z\nRRULE:FREQ=DAILY;COUNT=3",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+				{},
+			},
+		},
+		{
+			name:     "rule with until",
+			schedule: "RRULE:FREQ=DAILY;BYHOUR=10;UNTIL=20240102",
+			loc:      time.UTC,
+			expected: []time.Time{
+				time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+				{},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseSilenceSchedule(tc.schedule, tc.loc)
+			require.NoError(t, err)
+			next := from
+			for _, expected := range tc.expected {
+				next = s.Next(next)
+				require.Truef(t, expected.Equal(next), "expected %s, got %s", expected, next)
+			}
+		})
+	}
+
+	t.Run("should fail for invalid schedules", func(t *testing.T) {
+		for _, schedule := range []string{
+			"",
+			"0 22 * *",
+			"CRON_TZ=Europe/Berlin 0 22 * * 6",
+			"RRULE:FREQ=YEARLY",
+			"RRULE:FREQ=WEEKLY",
+			"RRULE:FREQ=DAILY;INTERVAL=2",
+			"RRULE:FREQ=DAILY;BYHOUR=24",
+			"RRULE:FREQ=WEEKLY;BYDAY=1MO",
+			"RRULE:FREQ=MONTHLY;BYDAY=XX",
+			"DTSTART:20240101T000000Z\nRRULE:FREQ=DAILY;COUNT=1;UNTIL=20240201",
+			"RRULE:FREQ=DAILY;BYSETPOS=1",
+		} {
+			_, err := ParseSilenceSchedule(schedule, time.UTC)
+			require.Errorf(t, err, "schedule %q", schedule)
+		}
+	})
+}
+
+func TestSilenceTemplateValidate(t *testing.T) {
+	valid := SilenceTemplate{
+		Name:     "weekend maintenance",
+		Matchers: amv2.Matchers{{Name: util.Pointer("cluster"), Value: util.Pointer("eu-.*"), IsRegex: util.Pointer(true), IsEqual: util.Pointer(true)}},
+		Duration: time.Hour,
+		Schedule: "0 22 * * 6",
+		Timezone: "Europe/Berlin",
+	}
+	require.NoError(t, valid.Validate())
+
+	testCases := map[string]func(t *SilenceTemplate){
+		"without name":       func(t *SilenceTemplate) { t.Name = "" },
+		"without matchers":   func(t *SilenceTemplate) { t.Matchers = nil },
+		"with invalid regex": func(t *SilenceTemplate) { t.Matchers[0].Value = util.Pointer("(") },
+		"without duration":   func(t *SilenceTemplate) { t.Duration = 0 },
+		"with invalid tz":    func(t *SilenceTemplate) { t.Timezone = "Mars/Olympus" },
+		"with bad schedule":  func(t *SilenceTemplate) { t.Schedule = "every saturday" },
+	}
+	for name, mutate := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tmpl := valid
+			tmpl.Matchers = amv2.Matchers{{Name: util.Pointer("cluster"), Value: util.Pointer("eu-.*"), IsRegex: util.Pointer(true), IsEqual: util.Pointer(true)}}
+			mutate(&tmpl)
+			require.Error(t, tmpl.Validate())
+		})
+	}
+}
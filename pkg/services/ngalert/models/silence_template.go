@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrSilenceTemplateNotFound = errors.New("silence template not found")
+	ErrSilenceTemplateExists   = errors.New("silence template with the same name already exists")
+)
+
+// SilenceTemplate is a saved set of matchers, comment and duration of a silence.
+// A template with a schedule is a recurring silence: a silence is created from the template at every occurrence of the schedule
+// in the Alertmanager of the organization, and it expires after the duration of the template.
+type SilenceTemplate struct {
+	ID       int64
+	UID      string
+	OrgID    int64
+	Name     string
+	Matchers amv2.Matchers
+	Comment  string
+	// Duration of the silences created from the template.
+	Duration time.Duration
+	// Schedule is a cron expression or an RRULE that defines when silences are created. Empty for templates that are not recurring.
+	Schedule string
+	// Timezone is the IANA name of the location the schedule is evaluated in. Defaults to UTC.
+	Timezone  string
+	CreatedBy string
+	// LastOccurrence is the start of the latest occurrence of the schedule a silence was created for.
+	LastOccurrence time.Time
+	// SilenceID is the ID of the silence created for the latest occurrence of the schedule.
+	SilenceID string
+	Updated   time.Time
+}
+
+// IsRecurring returns true if silences are created from the template on a schedule.
+func (t SilenceTemplate) IsRecurring() bool {
+	return t.Schedule != ""
+}
+
+// Location returns the location the schedule of the template is evaluated in.
+func (t SilenceTemplate) Location() (*time.Location, error) {
+	if t.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", t.Timezone, err)
+	}
+	return loc, nil
+}
+
+// ParsedSchedule returns the schedule of a recurring silence template.
+func (t SilenceTemplate) ParsedSchedule() (SilenceSchedule, error) {
+	loc, err := t.Location()
+	if err != nil {
+		return nil, err
+	}
+	return ParseSilenceSchedule(t.Schedule, loc)
+}
+
+// Validate checks that the template can be used to create silences.
+func (t SilenceTemplate) Validate() error {
+	if t.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if len(t.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if _, err := SilenceTemplateMatchers(t.Matchers); err != nil {
+		return err
+	}
+	if t.Duration <= 0 {
+		return errors.New("duration must be greater than zero")
+	}
+	if _, err := t.Location(); err != nil {
+		return err
+	}
+	if t.IsRecurring() {
+		if _, err := t.ParsedSchedule(); err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+	return nil
+}
+
+// SilenceTemplateMatchers converts the matchers of a silence template to label matchers.
+func SilenceTemplateMatchers(matchers amv2.Matchers) (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		if m == nil || m.Name == nil || *m.Name == "" {
+			return nil, errors.New("matcher name must not be empty")
+		}
+		value := ""
+		if m.Value != nil {
+			value = *m.Value
+		}
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		t := labels.MatchEqual
+		switch {
+		case isRegex && isEqual:
+			t = labels.MatchRegexp
+		case isRegex:
+			t = labels.MatchNotRegexp
+		case !isEqual:
+			t = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(t, *m.Name, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %s: %w", *m.Name, err)
+		}
+		result = append(result, matcher)
+	}
+	return result, nil
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	sdklive "github.com/grafana/grafana-plugin-sdk-go/live"
 	"github.com/prometheus/alertmanager/featurecontrol"
 	"github.com/prometheus/alertmanager/matchers/compat"
 	"golang.org/x/sync/errgroup"
@@ -27,6 +29,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	ac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -80,6 +84,7 @@ func ProvideService(
 	httpClientProvider httpclient.Provider,
 	resourcePermissions accesscontrol.ReceiverPermissionsService,
 	userService user.Service,
+	liveService *live.GrafanaLive,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
@@ -109,6 +114,7 @@ func ProvideService(
 		httpClientProvider:   httpClientProvider,
 		ResourcePermissions:  resourcePermissions,
 		userService:          userService,
+		liveService:          liveService,
 	}
 
 	if ng.IsDisabled() {
@@ -148,6 +154,9 @@ type AlertNG struct {
 	Api                 *api.API
 	httpClientProvider  httpclient.Provider
 	InstanceStore       state.InstanceStore
+	historian           Historian
+	deliveryLog         *notifier.DeliveryLog
+	recurringSilences   *notifier.RecurringSilences
 	// StartupInstanceReader is used to fetch the state of alerts on startup.
 	StartupInstanceReader state.InstanceReader
 
@@ -160,6 +169,7 @@ type AlertNG struct {
 	annotationsRepo      annotations.Repository
 	store                *store.DBstore
 	userService          user.Service
+	liveService          *live.GrafanaLive
 
 	bus          bus.Bus
 	pluginsStore pluginstore.Store
@@ -316,6 +326,11 @@ func (ng *AlertNG) init() error {
 		}
 	}
 
+	if ng.Cfg.UnifiedAlerting.NotificationDeliveryLogEnabled {
+		ng.deliveryLog = notifier.NewDeliveryLog(ng.store, ng.Cfg.UnifiedAlerting.NotificationDeliveryLogRetention, log.New("ngalert.notifier.delivery-log"))
+		overrides = append(overrides, notifier.WithDeliveryLog(ng.deliveryLog))
+	}
+
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 	moa, err := notifier.NewMultiOrgAlertmanager(
@@ -374,7 +389,7 @@ func (ng *AlertNG) init() error {
 		// Force-disable the feature if the feature toggle is not on - sets us up for feature toggle removal.
 		ng.Cfg.UnifiedAlerting.RecordingRules.Enabled = false
 	}
-	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, clk, ng.Metrics.GetRemoteWriterMetrics())
+	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, ng.liveStreamPublisher(), clk, ng.Metrics.GetRemoteWriterMetrics())
 	if err != nil {
 		return fmt.Errorf("failed to initialize recording writer: %w", err)
 	}
@@ -391,22 +406,28 @@ func (ng *AlertNG) init() error {
 		EvaluatorFactory:     evalFactory,
 		RuleStore:            ng.store,
 		RecordingRulesCfg:    ng.Cfg.UnifiedAlerting.RecordingRules,
+		QueryBudgetCfg:       ng.Cfg.UnifiedAlerting.QueryBudget,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
 		Tracer:               ng.tracer,
 		Log:                  log.New("ngalert.scheduler"),
 		RecordingWriter:      ng.RecordingWriter,
 	}
+	stateRestores := state.NewRestoreStore(ng.KVStore)
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
 	if err != nil {
 		return err
 	}
 
+	ng.historian = history
+
 	ng.InstanceStore, ng.StartupInstanceReader = initInstanceStore(ng.store.SQLStore, ng.Log, ng.FeatureToggles)
+	schedCfg.InstanceReader = ng.StartupInstanceReader
+	schedCfg.Restores = stateRestores
 
 	stateManagerCfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
@@ -424,6 +445,15 @@ func (ng *AlertNG) init() error {
 		Log:                            log.New("ngalert.state.manager"),
 		ResolvedRetention:              ng.Cfg.UnifiedAlerting.ResolvedAlertRetention,
 	}
+	if ng.Cfg.UnifiedAlerting.HAShardRuleEvaluation {
+		// The periodic state persister overwrites the state of all rules with the state cached by this instance,
+		// which contains only the rules evaluated by this instance.
+		if !ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStateCompressed) && ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
+			return fmt.Errorf("sharding of rule evaluation is not compatible with the %s feature toggle", featuremgmt.FlagAlertingSaveStatePeriodic)
+		}
+		ng.Log.Info("Sharding of rule evaluation across HA instances is enabled")
+		schedCfg.ClusterMembership = moa
+	}
 	statePersister := initStatePersister(ng.Cfg.UnifiedAlerting, stateManagerCfg, ng.FeatureToggles)
 	stateManager := state.NewManager(stateManagerCfg, statePersister)
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
@@ -465,6 +495,8 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(configStore, ng.SecretsService, ng.store, ng.store, provisioningReceiverService, ng.Log, ng.store, ng.ResourcePermissions)
 	templateService := provisioning.NewTemplateService(configStore, ng.store, ng.store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(configStore, ng.store, ng.store, ng.Log, ng.store)
+	silenceTemplateService := provisioning.NewSilenceTemplateService(ng.store, ng.store, ng.store, ng.MultiOrgAlertmanager, ng.Log)
+	ng.recurringSilences = notifier.NewRecurringSilences(ng.store, ng.MultiOrgAlertmanager, log.New("ngalert.notifier.recurring-silences"))
 	alertRuleService := provisioning.NewAlertRuleService(ng.store, ng.store, ng.folderService, ng.QuotaService, ng.store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
@@ -483,8 +515,11 @@ func (ng *AlertNG) init() error {
 		AlertingStore:        ng.store,
 		AdminConfigStore:     ng.store,
 		ProvenanceStore:      ng.store,
+		BacktestStore:        ng.store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		InstanceStore:        ng.InstanceStore,
+		StateRestores:        stateRestores,
 		Scheduler:            scheduler,
 		AccessControl:        ng.accesscontrol,
 		Policies:             policyService,
@@ -492,6 +527,7 @@ func (ng *AlertNG) init() error {
 		ContactPointService:  contactPointService,
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
+		SilenceTemplates:     silenceTemplateService,
 		AlertRules:           alertRuleService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
@@ -502,6 +538,9 @@ func (ng *AlertNG) init() error {
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 		UserService:          ng.userService,
+		HTTPClientProvider:   ng.httpClientProvider,
+
+		NotificationDeliveryStore: ng.store,
 	}
 	ng.Api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -603,6 +642,26 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	if r, ok := ng.historian.(historian.Runner); ok {
+		children.Go(func() error {
+			return r.Run(subCtx)
+		})
+	}
+	if ng.deliveryLog != nil {
+		children.Go(func() error {
+			return ng.deliveryLog.Run(subCtx)
+		})
+	}
+	if ng.recurringSilences != nil {
+		children.Go(func() error {
+			return ng.recurringSilences.Run(subCtx)
+		})
+	}
+	if w, ok := ng.RecordingWriter.(*writer.BatchWriter); ok {
+		children.Go(func() error {
+			return w.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -646,7 +705,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, hs historian.StateHistoryStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -661,7 +720,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer, ac)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, hs, met, l, tracer, ac)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -670,7 +729,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer, ac)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, hs, met, l, tracer, ac)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -701,6 +760,14 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypeSQL {
+		scfg, err := historian.NewSQLConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sql state history configuration: %w", err)
+		}
+		sqlBackendLogger := log.New("ngalert.state.historian", "backend", "sql")
+		return historian.NewSQLBackend(sqlBackendLogger, scfg, hs, met, rs, ac), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
@@ -754,12 +821,44 @@ func createRemoteAlertmanager(cfg remote.AlertmanagerConfig, kvstore kvstore.KVS
 	return remote.NewAlertmanager(cfg, notifier.NewFileStore(cfg.OrgID, kvstore), decryptFn, autogenFn, m, tracer)
 }
 
-func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
+func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, livePublisher writer.LiveStreamPublisher, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
 	logger := log.New("ngalert.writer")
 
-	if settings.Enabled {
+	if !settings.Enabled {
+		return writer.NoopWriter{}, nil
+	}
+
+	switch settings.Target {
+	case "", "prometheus":
 		return writer.NewPrometheusWriter(settings, httpClientProvider, clock, logger, m)
+	case "sql":
+		return writer.NewSQLWriter(settings, clock, logger, m)
+	case "influxdb":
+		return writer.NewInfluxWriter(settings, httpClientProvider, clock, logger, m)
+	case "live":
+		return writer.NewLiveWriter(settings, livePublisher, clock, logger, m)
+	default:
+		return nil, fmt.Errorf("unknown recording rules target %q", settings.Target)
+	}
+}
+
+// liveStreamPublisher returns the publisher of Grafana Live managed streams, or nil if Grafana Live is not available.
+func (ng *AlertNG) liveStreamPublisher() writer.LiveStreamPublisher {
+	if ng.liveService == nil || ng.liveService.ManagedStreamRunner == nil {
+		return nil
 	}
+	return managedStreamPublisher{runner: ng.liveService.ManagedStreamRunner}
+}
+
+// managedStreamPublisher pushes frames to the managed streams of Grafana Live.
+type managedStreamPublisher struct {
+	runner *managedstream.Runner
+}
 
-	return writer.NoopWriter{}, nil
+func (p managedStreamPublisher) PushFrame(ctx context.Context, orgID int64, namespace, path string, frame *data.Frame) error {
+	stream, err := p.runner.GetOrCreateStream(orgID, sdklive.ScopeStream, namespace)
+	if err != nil {
+		return err
+	}
+	return stream.Push(ctx, path, frame)
 }
@@ -63,6 +63,9 @@ type alertmanager struct {
 	orgID     int64
 
 	withAutogen bool
+
+	// deliveryLog, if set, records every attempt to deliver a notification.
+	deliveryLog *DeliveryLog
 }
 
 // maintenanceOptions represent the options for components that need maintenance on a frequency within the Alertmanager.
@@ -404,7 +407,11 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	}
 	s := &sender{am.NotificationService}
 	img := newImageProvider(am.Store, log.New("ngalert.notifier.image-provider"))
-	integrations, err := alertingNotify.BuildReceiverIntegrations(
+	build := alertingNotify.BuildReceiverIntegrations
+	if am.deliveryLog != nil {
+		build = buildDeliveryIntegrations
+	}
+	integrations, err := build(
 		receiverCfg,
 		tmpl,
 		img,
@@ -419,6 +426,9 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
+	if am.deliveryLog != nil {
+		integrations = am.deliveryLog.wrapIntegrations(am.orgID, receiver, integrations)
+	}
 	return integrations, nil
 }
 
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"fmt"
+
+	alertingHttp "github.com/grafana/alerting/http"
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertmanagerReceiver "github.com/grafana/alerting/receivers/alertmanager"
+	"github.com/grafana/alerting/receivers/dinding"
+	"github.com/grafana/alerting/receivers/discord"
+	"github.com/grafana/alerting/receivers/email"
+	"github.com/grafana/alerting/receivers/googlechat"
+	"github.com/grafana/alerting/receivers/jira"
+	"github.com/grafana/alerting/receivers/kafka"
+	"github.com/grafana/alerting/receivers/line"
+	"github.com/grafana/alerting/receivers/mqtt"
+	"github.com/grafana/alerting/receivers/oncall"
+	"github.com/grafana/alerting/receivers/opsgenie"
+	"github.com/grafana/alerting/receivers/pagerduty"
+	"github.com/grafana/alerting/receivers/pushover"
+	"github.com/grafana/alerting/receivers/sensugo"
+	"github.com/grafana/alerting/receivers/slack"
+	"github.com/grafana/alerting/receivers/sns"
+	"github.com/grafana/alerting/receivers/teams"
+	"github.com/grafana/alerting/receivers/telegram"
+	"github.com/grafana/alerting/receivers/threema"
+	"github.com/grafana/alerting/receivers/victorops"
+	"github.com/grafana/alerting/receivers/webex"
+	"github.com/grafana/alerting/receivers/webhook"
+	"github.com/grafana/alerting/receivers/wecom"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// buildDeliveryIntegrations builds the integrations of a receiver like alertingNotify.BuildReceiverIntegrations,
+// except that the webhook clients are wrapped by a deliverySender so that the delivery log can record the HTTP
+// responses of the contact points. The alerting package does not let callers replace the webhook client,
+// and TestBuildDeliveryIntegrations fails if the integrations built here diverge from the ones it builds.
+func buildDeliveryIntegrations(
+	receiver alertingNotify.GrafanaReceiverConfig,
+	tmpl *alertingTemplates.Template,
+	img images.Provider,
+	logger logging.LoggerFactory,
+	httpClientConfiguration alertingHttp.ClientConfiguration,
+	newEmailSender func(n receivers.Metadata) (receivers.EmailSender, error),
+	orgID int64,
+	version string,
+) ([]*alertingNotify.Integration, error) {
+	type notificationChannel interface {
+		notify.Notifier
+		notify.ResolvedSender
+	}
+	var (
+		integrations []*alertingNotify.Integration
+		errors       types.MultiError
+		nl           = func(meta receivers.Metadata) logging.Logger {
+			return logger("ngalert.notifier."+meta.Type, "notifierUID", meta.UID)
+		}
+		ci = func(idx int, cfg receivers.Metadata, n notificationChannel) {
+			integrations = append(integrations, alertingNotify.NewIntegration(n, n, cfg.Type, idx, cfg.Name))
+		}
+		nw = func(cfg receivers.Metadata) receivers.WebhookSender {
+			return deliverySender{alertingHttp.NewClient(logger("ngalert.notifier."+cfg.Type+".client", "notifierUID", cfg.UID), httpClientConfiguration)}
+		}
+	)
+	for i, cfg := range receiver.AlertmanagerConfigs {
+		ci(i, cfg.Metadata, alertmanagerReceiver.New(cfg.Settings, cfg.Metadata, img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.DingdingConfigs {
+		ci(i, cfg.Metadata, dinding.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.DiscordConfigs {
+		ci(i, cfg.Metadata, discord.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), version))
+	}
+	for i, cfg := range receiver.EmailConfigs {
+		mailCli, e := newEmailSender(cfg.Metadata)
+		if e != nil {
+			errors.Add(fmt.Errorf("unable to build email client for %s notifier %s (UID: %s): %w ", cfg.Type, cfg.Name, cfg.UID, e))
+			continue
+		}
+		ci(i, cfg.Metadata, email.New(cfg.Settings, cfg.Metadata, tmpl, mailCli, img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.GooglechatConfigs {
+		ci(i, cfg.Metadata, googlechat.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), version))
+	}
+	for i, cfg := range receiver.JiraConfigs {
+		ci(i, cfg.Metadata, jira.New(cfg.Settings, cfg.Metadata, tmpl, alertingHttp.NewForkedSender(nw(cfg.Metadata)), nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.KafkaConfigs {
+		ci(i, cfg.Metadata, kafka.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.LineConfigs {
+		ci(i, cfg.Metadata, line.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.MqttConfigs {
+		ci(i, cfg.Metadata, mqtt.New(cfg.Settings, cfg.Metadata, tmpl, nl(cfg.Metadata), nil))
+	}
+	for i, cfg := range receiver.OnCallConfigs {
+		ci(i, cfg.Metadata, oncall.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), orgID))
+	}
+	for i, cfg := range receiver.OpsgenieConfigs {
+		ci(i, cfg.Metadata, opsgenie.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.PagerdutyConfigs {
+		ci(i, cfg.Metadata, pagerduty.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.PushoverConfigs {
+		ci(i, cfg.Metadata, pushover.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.SensugoConfigs {
+		ci(i, cfg.Metadata, sensugo.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.SNSConfigs {
+		ci(i, cfg.Metadata, sns.New(cfg.Settings, cfg.Metadata, tmpl, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.SlackConfigs {
+		ci(i, cfg.Metadata, slack.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), version))
+	}
+	for i, cfg := range receiver.TeamsConfigs {
+		ci(i, cfg.Metadata, teams.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.TelegramConfigs {
+		ci(i, cfg.Metadata, telegram.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.ThreemaConfigs {
+		ci(i, cfg.Metadata, threema.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.VictoropsConfigs {
+		ci(i, cfg.Metadata, victorops.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), version))
+	}
+	for i, cfg := range receiver.WebhookConfigs {
+		ci(i, cfg.Metadata, webhook.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), orgID))
+	}
+	for i, cfg := range receiver.WecomConfigs {
+		ci(i, cfg.Metadata, wecom.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.WebexConfigs {
+		ci(i, cfg.Metadata, webex.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata), orgID))
+	}
+	if errors.Len() > 0 {
+		return nil, &errors
+	}
+	return integrations, nil
+}
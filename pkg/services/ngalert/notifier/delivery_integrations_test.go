@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"reflect"
+	"testing"
+
+	alertingHttp "github.com/grafana/alerting/http"
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDeliveryIntegrations(t *testing.T) {
+	// Add one integration of every type that the receiver configuration supports.
+	var cfg alertingNotify.GrafanaReceiverConfig
+	v := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Slice {
+			continue
+		}
+		notifier := reflect.New(field.Type().Elem().Elem())
+		notifier.Elem().FieldByName("Metadata").Set(reflect.ValueOf(receivers.Metadata{
+			Type: v.Type().Field(i).Name,
+			UID:  v.Type().Field(i).Name,
+			Name: "receiver",
+		}))
+		field.Set(reflect.Append(field, notifier))
+	}
+
+	build := func(b func(alertingNotify.GrafanaReceiverConfig, *alertingTemplates.Template, images.Provider, logging.LoggerFactory, alertingHttp.ClientConfiguration, func(receivers.Metadata) (receivers.EmailSender, error), int64, string) ([]*alertingNotify.Integration, error)) []string {
+		integrations, err := b(cfg, nil, nil, LoggerFactory, alertingHttp.DefaultClientConfiguration, func(receivers.Metadata) (receivers.EmailSender, error) {
+			return &sender{}, nil
+		}, 1, "test")
+		require.NoError(t, err)
+		names := make([]string, 0, len(integrations))
+		for _, integration := range integrations {
+			names = append(names, integration.Name())
+		}
+		return names
+	}
+
+	expected := build(alertingNotify.BuildReceiverIntegrations)
+	require.Len(t, expected, v.NumField()-1, "every type of integration should be built")
+	require.ElementsMatch(t, expected, build(buildDeliveryIntegrations))
+}
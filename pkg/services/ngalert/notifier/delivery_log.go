@@ -0,0 +1,240 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	deliveryLogBufferSize          = 10000
+	deliveryLogFlushInterval       = 5 * time.Second
+	deliveryLogMaintenanceInterval = time.Hour
+	// deliveryAttemptsTTL is how long the number of attempts of a notification is tracked.
+	// It is longer than the time the retry stage retries a notification, which is limited by the group interval.
+	deliveryAttemptsTTL = 24 * time.Hour
+)
+
+// NotificationDeliveryStore stores the outcome of attempts to deliver notifications.
+type NotificationDeliveryStore interface {
+	InsertNotificationDeliveries(ctx context.Context, deliveries []models.NotificationDelivery) error
+	DeleteNotificationDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DeliveryLog records every attempt to deliver a notification to an integration of a receiver.
+// Records are written to the store in batches in the background, so that the database does not delay notifications.
+// If the buffer of records that are not yet written is full, new records are dropped.
+type DeliveryLog struct {
+	store     NotificationDeliveryStore
+	retention time.Duration
+	clock     clock.Clock
+	logger    log.Logger
+	buffer    chan models.NotificationDelivery
+}
+
+func NewDeliveryLog(store NotificationDeliveryStore, retention time.Duration, logger log.Logger) *DeliveryLog {
+	return &DeliveryLog{
+		store:     store,
+		retention: retention,
+		clock:     clock.New(),
+		logger:    logger,
+		buffer:    make(chan models.NotificationDelivery, deliveryLogBufferSize),
+	}
+}
+
+// Record adds the delivery to the log. It never blocks.
+func (l *DeliveryLog) Record(d models.NotificationDelivery) {
+	select {
+	case l.buffer <- d:
+	default:
+		l.logger.Warn("Notification delivery log buffer is full, dropping record", "receiver", d.Receiver, "integration", d.Integration, "group_key", d.GroupKey)
+	}
+}
+
+// Run writes the recorded deliveries to the store and periodically deletes the records older than the retention.
+func (l *DeliveryLog) Run(ctx context.Context) error {
+	flush := l.clock.Ticker(deliveryLogFlushInterval)
+	defer flush.Stop()
+	maintenance := l.clock.Ticker(deliveryLogMaintenanceInterval)
+	defer maintenance.Stop()
+
+	l.deleteExpired(ctx)
+	batch := make([]models.NotificationDelivery, 0, 100)
+	for {
+		select {
+		case <-ctx.Done():
+			// Detached context here is to make sure that the records of notifications sent before the shutdown are saved.
+			l.flush(context.Background(), l.drain(batch))
+			return nil
+		case d := <-l.buffer:
+			batch = append(batch, d)
+			if len(batch) >= cap(batch) {
+				l.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-flush.C:
+			l.flush(ctx, batch)
+			batch = batch[:0]
+		case <-maintenance.C:
+			l.deleteExpired(ctx)
+		}
+	}
+}
+
+func (l *DeliveryLog) drain(batch []models.NotificationDelivery) []models.NotificationDelivery {
+	for {
+		select {
+		case d := <-l.buffer:
+			batch = append(batch, d)
+		default:
+			return batch
+		}
+	}
+}
+
+func (l *DeliveryLog) flush(ctx context.Context, batch []models.NotificationDelivery) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := l.store.InsertNotificationDeliveries(ctx, batch); err != nil {
+		l.logger.Error("Failed to save notification deliveries", "count", len(batch), "error", err)
+	}
+}
+
+func (l *DeliveryLog) deleteExpired(ctx context.Context) {
+	if l.retention <= 0 {
+		return
+	}
+	deleted, err := l.store.DeleteNotificationDeliveriesBefore(ctx, l.clock.Now().Add(-l.retention))
+	if err != nil && !errors.Is(err, context.Canceled) {
+		l.logger.Error("Failed to delete expired notification deliveries", "error", err)
+	}
+	if deleted > 0 {
+		l.logger.Debug("Deleted expired notification deliveries", "count", deleted)
+	}
+}
+
+// wrapIntegrations returns integrations that record every notification attempt of the given integrations of a receiver.
+func (l *DeliveryLog) wrapIntegrations(orgID int64, receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	// Integrations are built per type in the order of the receiver configuration,
+	// and their index is the position among the integrations of the same type.
+	uids := make(map[string][]string, len(receiver.Integrations))
+	for _, cfg := range receiver.Integrations {
+		t := strings.ToLower(cfg.Type)
+		uids[t] = append(uids[t], cfg.UID)
+	}
+
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		n := &deliveryNotifier{
+			upstream:    integration,
+			log:         l,
+			orgID:       orgID,
+			receiver:    receiver.Name,
+			integration: integration.Name(),
+			index:       integration.Index(),
+			attempts:    map[deliveryAttemptKey]int{},
+		}
+		if typeUIDs := uids[strings.ToLower(integration.Name())]; integration.Index() < len(typeUIDs) {
+			n.uid = typeUIDs[integration.Index()]
+		}
+		result = append(result, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiver.Name))
+	}
+	return result
+}
+
+// deliveryAttemptKey identifies a notification of an alert group. Retries of a notification are executed
+// by the notification pipeline with the same time as the first attempt.
+type deliveryAttemptKey struct {
+	groupKey string
+	now      time.Time
+}
+
+// deliveryNotifier records the outcome of every notification sent by an integration.
+type deliveryNotifier struct {
+	upstream    *alertingNotify.Integration
+	log         *DeliveryLog
+	orgID       int64
+	receiver    string
+	integration string
+	index       int
+	uid         string
+
+	mtx      sync.Mutex
+	attempts map[deliveryAttemptKey]int
+}
+
+func (n *deliveryNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	ctx, resp := withDeliveryResponse(ctx)
+	start := n.log.clock.Now()
+	retry, err := n.upstream.Notify(ctx, alerts...)
+	duration := n.log.clock.Since(start)
+
+	now, ok := notify.Now(ctx)
+	if !ok {
+		// Test notifications are not sent by the notification pipeline and are not recorded.
+		return retry, err
+	}
+	groupKey, _ := notify.GroupKey(ctx)
+	statusCode, body := resp.get()
+
+	d := models.NotificationDelivery{
+		OrgID:            n.orgID,
+		Receiver:         n.receiver,
+		Integration:      n.integration,
+		IntegrationIndex: n.index,
+		IntegrationUID:   n.uid,
+		GroupKey:         groupKey,
+		Attempt:          n.nextAttempt(deliveryAttemptKey{groupKey: groupKey, now: now}, err != nil && retry),
+		Status:           models.NotificationDeliverySuccess,
+		StatusCode:       statusCode,
+		Response:         body,
+		Duration:         duration,
+		Timestamp:        start,
+	}
+	if err != nil {
+		d.Status = models.NotificationDeliveryFailure
+		d.Error = err.Error()
+		d.Retry = retry
+	}
+	for _, a := range alerts {
+		if a.ResolvedAt(now) {
+			d.ResolvedAlerts++
+		} else {
+			d.FiringAlerts++
+		}
+	}
+	n.log.Record(d)
+	return retry, err
+}
+
+// nextAttempt returns the number of the current attempt to send the notification.
+// The number is forgotten if the notification is not going to be retried.
+func (n *deliveryNotifier) nextAttempt(key deliveryAttemptKey, willRetry bool) int {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	attempt := n.attempts[key] + 1
+	if !willRetry {
+		delete(n.attempts, key)
+		return attempt
+	}
+	n.attempts[key] = attempt
+	// Retries stop if the pipeline is canceled, remove the attempts of notifications that are not retried anymore.
+	for k := range n.attempts {
+		if key.now.Sub(k.now) > deliveryAttemptsTTL {
+			delete(n.attempts, k)
+		}
+	}
+	return attempt
+}
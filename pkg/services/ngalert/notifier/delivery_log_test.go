@@ -0,0 +1,173 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeDeliveryStore struct {
+	mtx        sync.Mutex
+	deliveries []models.NotificationDelivery
+	deleted    []time.Time
+}
+
+func (f *fakeDeliveryStore) InsertNotificationDeliveries(_ context.Context, deliveries []models.NotificationDelivery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deliveries = append(f.deliveries, deliveries...)
+	return nil
+}
+
+func (f *fakeDeliveryStore) DeleteNotificationDeliveriesBefore(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deleted = append(f.deleted, before)
+	return 0, nil
+}
+
+type fakeDeliveryNotifier struct {
+	results []error
+	// statusCodes are the status codes of the responses received by the notifier, 0 if there was no response.
+	statusCodes []int
+	retry       bool
+	calls       int
+}
+
+func (f *fakeDeliveryNotifier) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	err := f.results[f.calls]
+	if f.calls < len(f.statusCodes) && f.statusCodes[f.calls] != 0 {
+		recordDeliveryResponse(ctx, f.statusCodes[f.calls], []byte(http.StatusText(f.statusCodes[f.calls])))
+	}
+	f.calls++
+	return err != nil && f.retry, err
+}
+
+func (f *fakeDeliveryNotifier) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryLog(t *testing.T) {
+	receiver := &alertingNotify.APIReceiver{
+		ConfigReceiver: alertingNotify.ConfigReceiver{Name: "on-call"},
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{UID: "email-uid", Type: "email"},
+				{UID: "webhook-1", Type: "webhook"},
+				{UID: "webhook-2", Type: "webhook"},
+			},
+		},
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, EndsAt: now.Add(-time.Minute)}},
+	}
+	pipelineCtx := func() context.Context {
+		ctx := notify.WithNow(context.Background(), now)
+		return notify.WithGroupKey(ctx, `{}:{alertname="a"}`)
+	}
+
+	t.Run("should record every attempt", func(t *testing.T) {
+		store := &fakeDeliveryStore{}
+		l := NewDeliveryLog(store, 0, log.NewNopLogger())
+		n := &fakeDeliveryNotifier{
+			results:     []error{errors.New("webhook response status 503 Service Unavailable"), errors.New("dial tcp: i/o timeout"), nil},
+			statusCodes: []int{503, 0, 200},
+			retry:       true,
+		}
+		integrations := l.wrapIntegrations(1, receiver, []*alertingNotify.Integration{
+			alertingNotify.NewIntegration(n, n, "webhook", 1, "on-call"),
+		})
+		require.Len(t, integrations, 1)
+		require.Equal(t, "webhook", integrations[0].Name())
+		require.Equal(t, 1, integrations[0].Index())
+
+		ctx := pipelineCtx()
+		for range n.results {
+			_, _ = integrations[0].Notify(ctx, alerts...)
+		}
+
+		deliveries := l.drain(nil)
+		require.Len(t, deliveries, 3)
+		for i, d := range deliveries {
+			require.Equal(t, int64(1), d.OrgID)
+			require.Equal(t, "on-call", d.Receiver)
+			require.Equal(t, "webhook", d.Integration)
+			require.Equal(t, 1, d.IntegrationIndex)
+			require.Equal(t, "webhook-2", d.IntegrationUID)
+			require.Equal(t, `{}:{alertname="a"}`, d.GroupKey)
+			require.Equal(t, i+1, d.Attempt)
+			require.Equal(t, 1, d.FiringAlerts)
+			require.Equal(t, 1, d.ResolvedAlerts)
+		}
+		require.Equal(t, models.NotificationDeliveryFailure, deliveries[0].Status)
+		require.Equal(t, 503, deliveries[0].StatusCode)
+		require.Equal(t, "Service Unavailable", deliveries[0].Response)
+		require.True(t, deliveries[0].Retry)
+		require.Equal(t, models.NotificationDeliveryFailure, deliveries[1].Status)
+		require.Equal(t, 0, deliveries[1].StatusCode)
+		require.Empty(t, deliveries[1].Response)
+		require.Equal(t, "dial tcp: i/o timeout", deliveries[1].Error)
+		require.Equal(t, models.NotificationDeliverySuccess, deliveries[2].Status)
+		require.Empty(t, deliveries[2].Error)
+		require.Equal(t, 200, deliveries[2].StatusCode)
+		require.Equal(t, "OK", deliveries[2].Response)
+
+		t.Run("and start counting again for the next notification", func(t *testing.T) {
+			n.results = append(n.results, nil)
+			_, _ = integrations[0].Notify(pipelineCtx(), alerts...)
+			deliveries := l.drain(nil)
+			require.Len(t, deliveries, 1)
+			require.Equal(t, 1, deliveries[0].Attempt)
+		})
+	})
+
+	t.Run("should not count attempts of errors that are not retried", func(t *testing.T) {
+		l := NewDeliveryLog(&fakeDeliveryStore{}, 0, log.NewNopLogger())
+		n := &fakeDeliveryNotifier{results: []error{errors.New("failed"), errors.New("failed")}}
+		integration := l.wrapIntegrations(1, receiver, []*alertingNotify.Integration{alertingNotify.NewIntegration(n, n, "email", 0, "on-call")})[0]
+
+		_, _ = integration.Notify(pipelineCtx(), alerts...)
+		_, _ = integration.Notify(pipelineCtx(), alerts...)
+		deliveries := l.drain(nil)
+		require.Len(t, deliveries, 2)
+		require.Equal(t, 1, deliveries[1].Attempt)
+		require.False(t, deliveries[1].Retry)
+		require.Equal(t, "email-uid", deliveries[1].IntegrationUID)
+	})
+
+	t.Run("should not record test notifications", func(t *testing.T) {
+		l := NewDeliveryLog(&fakeDeliveryStore{}, 0, log.NewNopLogger())
+		n := &fakeDeliveryNotifier{results: []error{nil}}
+		integration := l.wrapIntegrations(1, receiver, []*alertingNotify.Integration{alertingNotify.NewIntegration(n, n, "email", 0, "on-call")})[0]
+
+		_, err := integration.Notify(notify.WithGroupKey(context.Background(), "test"), alerts...)
+		require.NoError(t, err)
+		require.Empty(t, l.drain(nil))
+	})
+
+	t.Run("should save the records and delete expired ones", func(t *testing.T) {
+		store := &fakeDeliveryStore{}
+		l := NewDeliveryLog(store, time.Hour, log.NewNopLogger())
+		l.Record(models.NotificationDelivery{Receiver: "on-call"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, l.Run(ctx))
+		require.Len(t, store.deliveries, 1)
+		require.Len(t, store.deleted, 1)
+	})
+}
@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+// maxDeliveryResponseSize is the maximum number of bytes of a response body that are recorded in the delivery log.
+const maxDeliveryResponseSize = 1024
+
+type deliveryResponseKey struct{}
+
+// deliveryResponse holds the last HTTP response received by an integration while sending a notification.
+type deliveryResponse struct {
+	mtx        sync.Mutex
+	statusCode int
+	body       string
+}
+
+// withDeliveryResponse returns a context in which the webhook senders of the integrations record their responses.
+func withDeliveryResponse(ctx context.Context) (context.Context, *deliveryResponse) {
+	r := &deliveryResponse{}
+	return context.WithValue(ctx, deliveryResponseKey{}, r), r
+}
+
+// recordDeliveryResponse stores the status code and the beginning of the body of a response in the context, if it has a recorder.
+func recordDeliveryResponse(ctx context.Context, statusCode int, body []byte) {
+	r, ok := ctx.Value(deliveryResponseKey{}).(*deliveryResponse)
+	if !ok {
+		return
+	}
+	if len(body) > maxDeliveryResponseSize {
+		body = body[:maxDeliveryResponseSize]
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.statusCode = statusCode
+	r.body = strings.ToValidUTF8(string(body), "")
+}
+
+func (r *deliveryResponse) get() (int, string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.statusCode, r.body
+}
+
+// deliverySender records the responses received by the wrapped sender in the context of the notification.
+// The sender passes every response it reads to the validation of the webhook, before it checks the status code.
+type deliverySender struct {
+	receivers.WebhookSender
+}
+
+func (s deliverySender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
+	webhook := *cmd
+	webhook.Validation = func(body []byte, statusCode int) error {
+		recordDeliveryResponse(ctx, statusCode, body)
+		if cmd.Validation == nil {
+			return nil
+		}
+		return cmd.Validation(body, statusCode)
+	}
+	return s.WebhookSender.SendWebhook(ctx, &webhook)
+}
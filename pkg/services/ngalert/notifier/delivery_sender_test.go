@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	alertingHttp "github.com/grafana/alerting/http"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliverySender(t *testing.T) {
+	status := http.StatusOK
+	body := "ok"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	sender := deliverySender{alertingHttp.NewClient(&logging.FakeLogger{}, alertingHttp.DefaultClientConfiguration)}
+
+	t.Run("should record the response of a successful request", func(t *testing.T) {
+		ctx, resp := withDeliveryResponse(context.Background())
+		require.NoError(t, sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: server.URL, Body: "{}"}))
+
+		statusCode, response := resp.get()
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, "ok", response)
+	})
+
+	t.Run("should record the truncated response of a failed request", func(t *testing.T) {
+		status = http.StatusBadRequest
+		body = strings.Repeat("a", 2*maxDeliveryResponseSize)
+		ctx, resp := withDeliveryResponse(context.Background())
+		err := sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: server.URL, Body: "{}"})
+		require.EqualError(t, err, "webhook response status 400 Bad Request")
+
+		statusCode, response := resp.get()
+		require.Equal(t, http.StatusBadRequest, statusCode)
+		require.Equal(t, body[:maxDeliveryResponseSize], response)
+	})
+
+	t.Run("should keep the validation of the webhook", func(t *testing.T) {
+		status = http.StatusOK
+		body = `{"ok":false}`
+		ctx, resp := withDeliveryResponse(context.Background())
+		err := sender.SendWebhook(ctx, &receivers.SendWebhookSettings{
+			URL: server.URL,
+			Validation: func(b []byte, statusCode int) error {
+				return errors.New(string(b))
+			},
+		})
+		require.EqualError(t, err, `webhook failed validation: {"ok":false}`)
+
+		statusCode, response := resp.get()
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Equal(t, body, response)
+	})
+
+	t.Run("should not record anything without a recorder", func(t *testing.T) {
+		require.NoError(t, sender.SendWebhook(context.Background(), &receivers.SendWebhookSettings{URL: server.URL}))
+	})
+}
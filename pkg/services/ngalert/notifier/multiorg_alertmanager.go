@@ -103,6 +103,8 @@ type MultiOrgAlertmanager struct {
 	ns      notifications.Service
 
 	receiverResourcePermissions ac.ReceiverPermissionsService
+
+	deliveryLog *DeliveryLog
 }
 
 type OrgAlertmanagerFactory func(ctx context.Context, orgID int64) (Alertmanager, error)
@@ -115,6 +117,13 @@ func WithAlertmanagerOverride(f func(OrgAlertmanagerFactory) OrgAlertmanagerFact
 	}
 }
 
+// WithDeliveryLog makes the Alertmanagers created by the default factory record every notification attempt in the log.
+func WithDeliveryLog(l *DeliveryLog) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.deliveryLog = l
+	}
+}
+
 func NewMultiOrgAlertmanager(
 	cfg *setting.Cfg,
 	configStore AlertingStore,
@@ -160,7 +169,12 @@ func NewMultiOrgAlertmanager(
 	moa.factory = func(ctx context.Context, orgID int64) (Alertmanager, error) {
 		m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID), l)
 		stateStore := NewFileStore(orgID, kvStore)
-		return NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, m, featureManager)
+		am, err := NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, m, featureManager)
+		if err != nil {
+			return nil, err
+		}
+		am.deliveryLog = moa.deliveryLog
+		return am, nil
 	}
 
 	for _, opt := range opts {
@@ -406,6 +420,24 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembers returns the name of this instance and the names of all live instances in the HA cluster, including this one.
+// It returns an empty name if high availability is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	switch p := moa.peer.(type) {
+	case *alertingCluster.Peer:
+		peers := p.Peers()
+		members := make([]string, 0, len(peers))
+		for _, m := range peers {
+			members = append(members, m.Name())
+		}
+		return p.Name(), members
+	case *redisPeer:
+		return p.Name(), p.Members()
+	default:
+		return "", nil
+	}
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
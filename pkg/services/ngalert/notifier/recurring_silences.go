@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	recurringSilencesInterval = time.Minute
+	// recurringSilenceLookahead is how long before an occurrence of a schedule its silence is created,
+	// so that the silence is in place when the occurrence starts even if an iteration is delayed.
+	recurringSilenceLookahead = 5 * time.Minute
+)
+
+// RecurringSilenceStore stores the silence templates with a schedule and the latest occurrence a silence was created for.
+type RecurringSilenceStore interface {
+	ListRecurringSilenceTemplates(ctx context.Context) ([]models.SilenceTemplate, error)
+	ClaimSilenceTemplateOccurrence(ctx context.Context, orgID int64, uid string, previous, occurrence time.Time) (bool, error)
+	SetSilenceTemplateSilenceID(ctx context.Context, orgID int64, uid string, occurrence time.Time, silenceID string) error
+}
+
+// SilenceCreator creates silences in the Alertmanager of an organization.
+type SilenceCreator interface {
+	CreateSilence(ctx context.Context, orgID int64, ps models.Silence) (string, error)
+}
+
+// RecurringSilences creates a silence in the Alertmanager of the organization at every occurrence of the schedule of a silence template.
+// The silence starts at the occurrence and ends after the duration of the template, so the Alertmanager expires it.
+// Occurrences are claimed in the database before the silence is created, so only one instance of a high availability setup creates it.
+type RecurringSilences struct {
+	store    RecurringSilenceStore
+	silences SilenceCreator
+	clock    clock.Clock
+	logger   log.Logger
+}
+
+func NewRecurringSilences(store RecurringSilenceStore, silences SilenceCreator, logger log.Logger) *RecurringSilences {
+	return &RecurringSilences{
+		store:    store,
+		silences: silences,
+		clock:    clock.New(),
+		logger:   logger,
+	}
+}
+
+// Run creates the silences of the recurring silence templates until the context is cancelled.
+func (r *RecurringSilences) Run(ctx context.Context) error {
+	ticker := r.clock.Ticker(recurringSilencesInterval)
+	defer ticker.Stop()
+
+	r.sync(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.sync(ctx)
+		}
+	}
+}
+
+func (r *RecurringSilences) sync(ctx context.Context) {
+	templates, err := r.store.ListRecurringSilenceTemplates(ctx)
+	if err != nil {
+		r.logger.Error("Failed to list recurring silences", "error", err)
+		return
+	}
+	now := r.clock.Now()
+	for _, t := range templates {
+		if err := r.syncTemplate(ctx, t, now); err != nil {
+			r.logger.Error("Failed to create recurring silence", "org", t.OrgID, "uid", t.UID, "name", t.Name, "error", err)
+		}
+	}
+}
+
+func (r *RecurringSilences) syncTemplate(ctx context.Context, t models.SilenceTemplate, now time.Time) error {
+	sched, err := t.ParsedSchedule()
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	// The first occurrence that has not ended yet and that no silence was created for.
+	after := now.Add(-t.Duration)
+	if t.LastOccurrence.After(after) {
+		after = t.LastOccurrence
+	}
+	occurrence := sched.Next(after)
+	if occurrence.IsZero() || occurrence.After(now.Add(recurringSilenceLookahead)) {
+		return nil
+	}
+
+	claimed, err := r.store.ClaimSilenceTemplateOccurrence(ctx, t.OrgID, t.UID, t.LastOccurrence, occurrence)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		r.logger.Debug("Occurrence of recurring silence is claimed by another instance", "org", t.OrgID, "uid", t.UID, "occurrence", occurrence)
+		return nil
+	}
+
+	silenceID, err := r.silences.CreateSilence(ctx, t.OrgID, recurringSilence(t, occurrence))
+	if err != nil {
+		// Release the occurrence so that the silence is created in the next iteration.
+		if _, rerr := r.store.ClaimSilenceTemplateOccurrence(ctx, t.OrgID, t.UID, occurrence, t.LastOccurrence); rerr != nil {
+			r.logger.Warn("Failed to release occurrence of recurring silence", "org", t.OrgID, "uid", t.UID, "occurrence", occurrence, "error", rerr)
+		}
+		return err
+	}
+	r.logger.Info("Created recurring silence", "org", t.OrgID, "uid", t.UID, "silenceID", silenceID, "startsAt", occurrence, "endsAt", occurrence.Add(t.Duration))
+
+	return r.store.SetSilenceTemplateSilenceID(ctx, t.OrgID, t.UID, occurrence, silenceID)
+}
+
+func recurringSilence(t models.SilenceTemplate, occurrence time.Time) models.Silence {
+	comment := t.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Recurring silence %q", t.Name)
+	}
+	createdBy := t.CreatedBy
+	if createdBy == "" {
+		createdBy = "grafana"
+	}
+	return models.Silence{
+		Silence: amv2.Silence{
+			Matchers:  t.Matchers,
+			StartsAt:  util.Pointer(strfmt.DateTime(occurrence)),
+			EndsAt:    util.Pointer(strfmt.DateTime(occurrence.Add(t.Duration))),
+			Comment:   util.Pointer(comment),
+			CreatedBy: util.Pointer(createdBy),
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type fakeRecurringSilenceStore struct {
+	templates map[string]*models.SilenceTemplate
+}
+
+func (f *fakeRecurringSilenceStore) ListRecurringSilenceTemplates(_ context.Context) ([]models.SilenceTemplate, error) {
+	result := make([]models.SilenceTemplate, 0, len(f.templates))
+	for _, t := range f.templates {
+		result = append(result, *t)
+	}
+	return result, nil
+}
+
+func (f *fakeRecurringSilenceStore) ClaimSilenceTemplateOccurrence(_ context.Context, _ int64, uid string, previous, occurrence time.Time) (bool, error) {
+	t := f.templates[uid]
+	if !t.LastOccurrence.Equal(previous) {
+		return false, nil
+	}
+	t.LastOccurrence = occurrence
+	t.SilenceID = ""
+	return true, nil
+}
+
+func (f *fakeRecurringSilenceStore) SetSilenceTemplateSilenceID(_ context.Context, _ int64, uid string, occurrence time.Time, silenceID string) error {
+	t := f.templates[uid]
+	if t.LastOccurrence.Equal(occurrence) {
+		t.SilenceID = silenceID
+	}
+	return nil
+}
+
+type fakeSilenceCreator struct {
+	created []models.Silence
+	err     error
+}
+
+func (f *fakeSilenceCreator) CreateSilence(_ context.Context, _ int64, ps models.Silence) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.created = append(f.created, ps)
+	return util.GenerateShortUID(), nil
+}
+
+func TestRecurringSilences(t *testing.T) {
+	// Saturday, the schedule starts at 22:00 every Saturday.
+	saturday := time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)
+	occurrence := saturday.Add(22 * time.Hour)
+
+	newTemplate := func() *models.SilenceTemplate {
+		return &models.SilenceTemplate{
+			UID:       "weekend",
+			OrgID:     1,
+			Name:      "weekend maintenance",
+			Matchers:  amv2.Matchers{{Name: util.Pointer("cluster"), Value: util.Pointer("eu"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)}},
+			Duration:  2 * time.Hour,
+			Schedule:  "0 22 * * 6",
+			CreatedBy: "admin",
+		}
+	}
+	setup := func() (*RecurringSilences, *fakeRecurringSilenceStore, *fakeSilenceCreator) {
+		store := &fakeRecurringSilenceStore{templates: map[string]*models.SilenceTemplate{"weekend": newTemplate()}}
+		silences := &fakeSilenceCreator{}
+		return NewRecurringSilences(store, silences, log.NewNopLogger()), store, silences
+	}
+
+	t.Run("should not create a silence before the occurrence", func(t *testing.T) {
+		r, _, silences := setup()
+		require.NoError(t, r.syncTemplate(context.Background(), *newTemplate(), occurrence.Add(-time.Hour)))
+		require.Empty(t, silences.created)
+	})
+
+	t.Run("should create a silence once per occurrence", func(t *testing.T) {
+		r, store, silences := setup()
+		now := occurrence.Add(-time.Minute)
+		require.NoError(t, r.syncTemplate(context.Background(), *store.templates["weekend"], now))
+		require.Len(t, silences.created, 1)
+		s := silences.created[0]
+		require.Equal(t, strfmt.DateTime(occurrence), *s.StartsAt)
+		require.Equal(t, strfmt.DateTime(occurrence.Add(2*time.Hour)), *s.EndsAt)
+		require.Equal(t, `Recurring silence "weekend maintenance"`, *s.Comment)
+		require.Equal(t, "admin", *s.CreatedBy)
+		require.Equal(t, newTemplate().Matchers, s.Matchers)
+
+		tmpl := store.templates["weekend"]
+		require.Equal(t, occurrence, tmpl.LastOccurrence)
+		require.NotEmpty(t, tmpl.SilenceID)
+
+		// The same occurrence is skipped in the next iterations.
+		require.NoError(t, r.syncTemplate(context.Background(), *tmpl, now.Add(time.Hour)))
+		require.Len(t, silences.created, 1)
+
+		// The next occurrence gets a new silence.
+		require.NoError(t, r.syncTemplate(context.Background(), *tmpl, occurrence.Add(7*24*time.Hour)))
+		require.Len(t, silences.created, 2)
+		require.Equal(t, strfmt.DateTime(occurrence.Add(7*24*time.Hour)), *silences.created[1].StartsAt)
+	})
+
+	t.Run("should create a silence for an occurrence that has not ended", func(t *testing.T) {
+		r, store, silences := setup()
+		require.NoError(t, r.syncTemplate(context.Background(), *store.templates["weekend"], occurrence.Add(90*time.Minute)))
+		require.Len(t, silences.created, 1)
+		require.Equal(t, strfmt.DateTime(occurrence), *silences.created[0].StartsAt)
+	})
+
+	t.Run("should not create a silence if another instance claimed the occurrence", func(t *testing.T) {
+		r, store, silences := setup()
+		stale := *store.templates["weekend"]
+		store.templates["weekend"].LastOccurrence = occurrence
+		require.NoError(t, r.syncTemplate(context.Background(), stale, occurrence))
+		require.Empty(t, silences.created)
+	})
+
+	t.Run("should release the occurrence if the silence cannot be created", func(t *testing.T) {
+		r, store, silences := setup()
+		silences.err = errors.New("alertmanager is not ready")
+		require.Error(t, r.syncTemplate(context.Background(), *store.templates["weekend"], occurrence))
+		require.True(t, store.templates["weekend"].LastOccurrence.IsZero())
+
+		silences.err = nil
+		require.NoError(t, r.syncTemplate(context.Background(), *store.templates["weekend"], occurrence))
+		require.Len(t, silences.created, 1)
+	})
+}
@@ -0,0 +1,256 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var ErrNoRoutingTree = errors.New("the configuration has no notification policy tree")
+
+// SimulateRouting returns how an alert with the given labels is handled at the given time by the configuration,
+// the silences and the current alerts of an Alertmanager: the routes it matches, whether it is silenced or inhibited,
+// and the receivers that would be notified.
+// It does not depend on the implementation of the Alertmanager, so it works the same for the internal and the remote Alertmanager.
+func SimulateRouting(cfg definitions.Config, silences amv2.GettableSilences, alerts amv2.GettableAlerts, lset model.LabelSet, at time.Time) (definitions.RoutingSimulationResult, error) {
+	if cfg.Route == nil {
+		return definitions.RoutingSimulationResult{}, ErrNoRoutingTree
+	}
+
+	result := definitions.RoutingSimulationResult{
+		Time:        at,
+		Routes:      []definitions.SimulatedRoute{},
+		Silences:    []definitions.SimulatedSilence{},
+		Inhibitions: []definitions.SimulatedInhibition{},
+		Receivers:   []string{},
+	}
+
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	paths := map[*dispatch.Route][]int{}
+	var walk func(r *dispatch.Route, path []int)
+	walk = func(r *dispatch.Route, path []int) {
+		paths[r] = path
+		for i, child := range r.Routes {
+			walk(child, append(append(make([]int, 0, len(path)+1), path...), i))
+		}
+	}
+	walk(root, []int{})
+
+	for _, r := range root.Match(lset) {
+		result.Routes = append(result.Routes, simulateRoute(r, paths[r], intervals, lset, at))
+	}
+
+	for _, s := range silences {
+		matches, err := silenceMatches(s, lset, at)
+		if err != nil {
+			return definitions.RoutingSimulationResult{}, err
+		}
+		if matches {
+			result.Silences = append(result.Silences, simulatedSilence(s))
+		}
+	}
+	result.Silenced = len(result.Silences) > 0
+
+	for i, cr := range cfg.InhibitRules {
+		rule := inhibit.NewInhibitRule(cr)
+		if source, ok := inhibitingAlert(rule, alerts, lset); ok {
+			result.Inhibitions = append(result.Inhibitions, definitions.SimulatedInhibition{
+				Index:          i,
+				SourceMatchers: matcherStrings(rule.SourceMatchers),
+				TargetMatchers: matcherStrings(rule.TargetMatchers),
+				Equal:          sortedLabelNames(rule.Equal),
+				SourceAlert:    source,
+			})
+		}
+	}
+	result.Inhibited = len(result.Inhibitions) > 0
+
+	if result.Silenced || result.Inhibited {
+		return result, nil
+	}
+	seen := map[string]struct{}{}
+	for _, r := range result.Routes {
+		if _, ok := seen[r.Receiver]; ok || r.Muted {
+			continue
+		}
+		seen[r.Receiver] = struct{}{}
+		result.Receivers = append(result.Receivers, r.Receiver)
+	}
+	return result, nil
+}
+
+func simulateRoute(r *dispatch.Route, path []int, intervals map[string][]timeinterval.TimeInterval, lset model.LabelSet, at time.Time) definitions.SimulatedRoute {
+	route := definitions.SimulatedRoute{
+		Path:           path,
+		Matchers:       matcherStrings(r.Matchers),
+		Receiver:       r.RouteOpts.Receiver,
+		Continue:       r.Continue,
+		GroupByAll:     r.RouteOpts.GroupByAll,
+		GroupBy:        sortedLabelNames(r.RouteOpts.GroupBy),
+		GroupLabels:    model.LabelSet{},
+		GroupWait:      model.Duration(r.RouteOpts.GroupWait),
+		GroupInterval:  model.Duration(r.RouteOpts.GroupInterval),
+		RepeatInterval: model.Duration(r.RouteOpts.RepeatInterval),
+	}
+
+	// The alert is grouped in the same way as by the dispatcher.
+	for ln, lv := range lset {
+		if _, ok := r.RouteOpts.GroupBy[ln]; ok || r.RouteOpts.GroupByAll {
+			route.GroupLabels[ln] = lv
+		}
+	}
+
+	// The route is muted if any of its mute time intervals contains the time,
+	// or if it has active time intervals and none of them contains the time.
+	for _, name := range r.RouteOpts.MuteTimeIntervals {
+		ti := simulatedTimeInterval(name, intervals, at)
+		route.Muted = route.Muted || ti.Active
+		route.MuteTimeIntervals = append(route.MuteTimeIntervals, ti)
+	}
+	activeIntervalsContainTime := len(r.RouteOpts.ActiveTimeIntervals) == 0
+	for _, name := range r.RouteOpts.ActiveTimeIntervals {
+		ti := simulatedTimeInterval(name, intervals, at)
+		activeIntervalsContainTime = activeIntervalsContainTime || ti.Active
+		route.ActiveTimeIntervals = append(route.ActiveTimeIntervals, ti)
+	}
+	route.Muted = route.Muted || !activeIntervalsContainTime
+	return route
+}
+
+func simulatedTimeInterval(name string, intervals map[string][]timeinterval.TimeInterval, at time.Time) definitions.SimulatedTimeInterval {
+	ti := definitions.SimulatedTimeInterval{Name: name}
+	for _, interval := range intervals[name] {
+		if interval.ContainsTime(at.UTC()) {
+			ti.Active = true
+			break
+		}
+	}
+	return ti
+}
+
+// silenceMatches returns true if the silence is active at the given time and its matchers match the labels.
+func silenceMatches(s *amv2.GettableSilence, lset model.LabelSet, at time.Time) (bool, error) {
+	if s.StartsAt == nil || s.EndsAt == nil || at.Before(time.Time(*s.StartsAt)) || !at.Before(time.Time(*s.EndsAt)) {
+		return false, nil
+	}
+	matchers, err := silenceMatchers(s)
+	if err != nil {
+		return false, err
+	}
+	return matchers.Matches(lset), nil
+}
+
+func silenceMatchers(s *amv2.GettableSilence) (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		if m.Name == nil || m.Value == nil {
+			continue
+		}
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		t := labels.MatchEqual
+		switch {
+		case isRegex && isEqual:
+			t = labels.MatchRegexp
+		case isRegex:
+			t = labels.MatchNotRegexp
+		case !isEqual:
+			t = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(t, *m.Name, *m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher of silence %s: %w", silenceID(s), err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+func simulatedSilence(s *amv2.GettableSilence) definitions.SimulatedSilence {
+	matchers, _ := silenceMatchers(s)
+	silence := definitions.SimulatedSilence{
+		ID:       silenceID(s),
+		Matchers: matcherStrings(matchers),
+		StartsAt: time.Time(*s.StartsAt),
+		EndsAt:   time.Time(*s.EndsAt),
+	}
+	if s.CreatedBy != nil {
+		silence.CreatedBy = *s.CreatedBy
+	}
+	if s.Comment != nil {
+		silence.Comment = *s.Comment
+	}
+	return silence
+}
+
+func silenceID(s *amv2.GettableSilence) string {
+	if s.ID == nil {
+		return ""
+	}
+	return *s.ID
+}
+
+// inhibitingAlert returns the labels of an alert that makes the rule inhibit an alert with the given labels.
+// It follows the semantics of the inhibitor of the Alertmanager: if the labels match both sides of the rule,
+// alerts that also match both sides do not inhibit it.
+func inhibitingAlert(rule *inhibit.InhibitRule, alerts amv2.GettableAlerts, lset model.LabelSet) (model.LabelSet, bool) {
+	if !rule.TargetMatchers.Matches(lset) {
+		return nil, false
+	}
+	excludeTwoSidedMatch := rule.SourceMatchers.Matches(lset)
+Outer:
+	for _, a := range alerts {
+		source := make(model.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			source[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if !rule.SourceMatchers.Matches(source) {
+			continue
+		}
+		for ln := range rule.Equal {
+			if source[ln] != lset[ln] {
+				continue Outer
+			}
+		}
+		if excludeTwoSidedMatch && rule.TargetMatchers.Matches(source) {
+			continue
+		}
+		return source, true
+	}
+	return nil, false
+}
+
+func matcherStrings(matchers labels.Matchers) []string {
+	result := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		result = append(result, m.String())
+	}
+	return result
+}
+
+func sortedLabelNames(names map[model.LabelName]struct{}) []string {
+	result := make([]string, 0, len(names))
+	for ln := range names {
+		result = append(result, string(ln))
+	}
+	sort.Strings(result)
+	return result
+}
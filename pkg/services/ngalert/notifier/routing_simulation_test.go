@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const routingSimulationConfig = `
+route:
+  receiver: default
+  group_by: [alertname]
+  routes:
+    - receiver: team-a
+      object_matchers: [["team", "=", "a"]]
+      group_by: [alertname, cluster]
+      group_wait: 10s
+      continue: true
+      mute_time_intervals: [weekends]
+    - receiver: team-a-oncall
+      object_matchers: [["team", "=", "a"], ["severity", "=", "critical"]]
+      active_time_intervals: [business-hours]
+    - receiver: team-b
+      object_matchers: [["team", "=", "b"]]
+inhibit_rules:
+  - source_matchers: [severity="critical"]
+    target_matchers: [severity="warning"]
+    equal: [cluster]
+time_intervals:
+  - name: weekends
+    time_intervals:
+      - weekdays: [saturday, sunday]
+  - name: business-hours
+    time_intervals:
+      - times:
+          - start_time: "09:00"
+            end_time: "17:00"
+`
+
+func TestSimulateRouting(t *testing.T) {
+	var cfg definitions.Config
+	require.NoError(t, yaml.Unmarshal([]byte(routingSimulationConfig), &cfg))
+
+	monday := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 1, 6, 20, 0, 0, 0, time.UTC)
+
+	t.Run("should return the matching routes with the inherited settings", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "HighLatency", "team": "a", "severity": "critical", "cluster": "eu"}
+		res, err := SimulateRouting(cfg, nil, nil, lset, monday)
+		require.NoError(t, err)
+
+		require.Len(t, res.Routes, 2)
+		teamA := res.Routes[0]
+		require.Equal(t, []int{0}, teamA.Path)
+		require.Equal(t, []string{`team="a"`}, teamA.Matchers)
+		require.Equal(t, "team-a", teamA.Receiver)
+		require.True(t, teamA.Continue)
+		require.Equal(t, []string{"alertname", "cluster"}, teamA.GroupBy)
+		require.Equal(t, model.LabelSet{"alertname": "HighLatency", "cluster": "eu"}, teamA.GroupLabels)
+		require.Equal(t, model.Duration(10*time.Second), teamA.GroupWait)
+		require.Equal(t, model.Duration(5*time.Minute), teamA.GroupInterval)
+		require.Equal(t, []definitions.SimulatedTimeInterval{{Name: "weekends", Active: false}}, teamA.MuteTimeIntervals)
+		require.False(t, teamA.Muted)
+
+		oncall := res.Routes[1]
+		require.Equal(t, []int{1}, oncall.Path)
+		require.Equal(t, model.LabelSet{"alertname": "HighLatency"}, oncall.GroupLabels)
+		require.Equal(t, model.Duration(30*time.Second), oncall.GroupWait)
+		require.Equal(t, []definitions.SimulatedTimeInterval{{Name: "business-hours", Active: true}}, oncall.ActiveTimeIntervals)
+		require.False(t, oncall.Muted)
+
+		require.Equal(t, []string{"team-a", "team-a-oncall"}, res.Receivers)
+		require.False(t, res.Silenced)
+		require.False(t, res.Inhibited)
+	})
+
+	t.Run("should not notify receivers of routes muted by time intervals", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "HighLatency", "team": "a", "severity": "critical"}
+		res, err := SimulateRouting(cfg, nil, nil, lset, saturday)
+		require.NoError(t, err)
+		require.Len(t, res.Routes, 2)
+		require.True(t, res.Routes[0].Muted)
+		require.True(t, res.Routes[1].Muted)
+		require.Empty(t, res.Receivers)
+	})
+
+	t.Run("should fall back to the root route", func(t *testing.T) {
+		res, err := SimulateRouting(cfg, nil, nil, model.LabelSet{"alertname": "Other"}, monday)
+		require.NoError(t, err)
+		require.Len(t, res.Routes, 1)
+		require.Empty(t, res.Routes[0].Path)
+		require.Equal(t, []string{"default"}, res.Receivers)
+	})
+
+	t.Run("should return silences active at the given time", func(t *testing.T) {
+		silence := func(id string, startsAt, endsAt time.Time, matchers ...*amv2.Matcher) *amv2.GettableSilence {
+			s := &amv2.GettableSilence{ID: util.Pointer(id)}
+			s.Matchers = matchers
+			s.StartsAt = util.Pointer(strfmt.DateTime(startsAt))
+			s.EndsAt = util.Pointer(strfmt.DateTime(endsAt))
+			s.CreatedBy = util.Pointer("admin")
+			s.Comment = util.Pointer("maintenance")
+			return s
+		}
+		matcher := func(name, value string, isEqual, isRegex bool) *amv2.Matcher {
+			return &amv2.Matcher{Name: util.Pointer(name), Value: util.Pointer(value), IsEqual: util.Pointer(isEqual), IsRegex: util.Pointer(isRegex)}
+		}
+		silences := amv2.GettableSilences{
+			silence("active", monday.Add(-time.Hour), monday.Add(time.Hour), matcher("team", "a|b", true, true)),
+			silence("expired", monday.Add(-2*time.Hour), monday.Add(-time.Hour), matcher("team", "b", true, false)),
+			silence("pending", monday.Add(time.Hour), monday.Add(2*time.Hour), matcher("team", "b", true, false)),
+			silence("other", monday.Add(-time.Hour), monday.Add(time.Hour), matcher("team", "b", false, false)),
+		}
+
+		res, err := SimulateRouting(cfg, silences, nil, model.LabelSet{"alertname": "HighLatency", "team": "b"}, monday)
+		require.NoError(t, err)
+		require.True(t, res.Silenced)
+		require.Len(t, res.Silences, 1)
+		require.Equal(t, "active", res.Silences[0].ID)
+		require.Equal(t, []string{`team=~"a|b"`}, res.Silences[0].Matchers)
+		require.Equal(t, "admin", res.Silences[0].CreatedBy)
+		require.Len(t, res.Routes, 1)
+		require.Empty(t, res.Receivers)
+
+		res, err = SimulateRouting(cfg, silences, nil, model.LabelSet{"alertname": "HighLatency", "team": "b"}, monday.Add(90*time.Minute))
+		require.NoError(t, err)
+		require.Len(t, res.Silences, 1)
+		require.Equal(t, "pending", res.Silences[0].ID)
+	})
+
+	t.Run("should return inhibition rules that mute the alert", func(t *testing.T) {
+		alert := func(lbls amv2.LabelSet) *amv2.GettableAlert {
+			a := &amv2.GettableAlert{}
+			a.Labels = lbls
+			return a
+		}
+		alerts := amv2.GettableAlerts{
+			alert(amv2.LabelSet{"alertname": "Down", "severity": "critical", "cluster": "us"}),
+			alert(amv2.LabelSet{"alertname": "Down", "severity": "critical", "cluster": "eu"}),
+		}
+
+		res, err := SimulateRouting(cfg, nil, alerts, model.LabelSet{"alertname": "HighLatency", "team": "b", "severity": "warning", "cluster": "eu"}, monday)
+		require.NoError(t, err)
+		require.True(t, res.Inhibited)
+		require.Equal(t, []definitions.SimulatedInhibition{{
+			Index:          0,
+			SourceMatchers: []string{`severity="critical"`},
+			TargetMatchers: []string{`severity="warning"`},
+			Equal:          []string{"cluster"},
+			SourceAlert:    model.LabelSet{"alertname": "Down", "severity": "critical", "cluster": "eu"},
+		}}, res.Inhibitions)
+		require.Empty(t, res.Receivers)
+
+		res, err = SimulateRouting(cfg, nil, alerts, model.LabelSet{"alertname": "HighLatency", "team": "b", "severity": "warning", "cluster": "ap"}, monday)
+		require.NoError(t, err)
+		require.False(t, res.Inhibited)
+		require.Equal(t, []string{"team-b"}, res.Receivers)
+	})
+}
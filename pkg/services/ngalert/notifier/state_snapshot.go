@@ -0,0 +1,278 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// AlertmanagerState is the internal state of the Alertmanager of an organization in the format it is persisted in the kvstore.
+type AlertmanagerState struct {
+	Silences        []byte
+	NotificationLog []byte
+}
+
+// RestoredAlertmanagerState is the result of restoring the state of the Alertmanager of an organization.
+type RestoredAlertmanagerState struct {
+	Silences               int
+	NotificationLogEntries int
+}
+
+// StateRemapping describes how the labels of alerts change when the state of an Alertmanager is restored in another
+// instance, for example because the alert rules have different UIDs in the target instance.
+type StateRemapping struct {
+	// LabelValues maps the name of a label to the replacements of its values.
+	LabelValues map[string]map[string]string
+	// Alerts are the label sets of the alerts in the source instance. The notification log refers to alerts by
+	// the hash of their labels, so it can only be remapped for known alerts.
+	Alerts []model.LabelSet
+}
+
+func (r StateRemapping) empty() bool {
+	return len(r.LabelValues) == 0
+}
+
+func (r StateRemapping) labels(ls model.LabelSet) model.LabelSet {
+	result := make(model.LabelSet, len(ls))
+	for name, value := range ls {
+		if v, ok := r.LabelValues[string(name)][string(value)]; ok {
+			value = model.LabelValue(v)
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// groupKey replaces the values of the remapped labels in the matchers and group labels of an aggregation group key.
+func (r StateRemapping) groupKey(key string) string {
+	for name, values := range r.LabelValues {
+		for from, to := range values {
+			key = strings.ReplaceAll(key, fmt.Sprintf("%s=%q", name, from), fmt.Sprintf("%s=%q", name, to))
+		}
+	}
+	return key
+}
+
+func (r StateRemapping) alertHashes() map[uint64]uint64 {
+	result := make(map[uint64]uint64, len(r.Alerts))
+	for _, ls := range r.Alerts {
+		from, to := alertHash(ls), alertHash(r.labels(ls))
+		if from != to {
+			result[from] = to
+		}
+	}
+	return result
+}
+
+// alertHash is the hash the Alertmanager stores in the notification log for an alert, see hashAlert in prometheus-alertmanager/notify/notify.go.
+func alertHash(ls model.LabelSet) uint64 {
+	const sep = '\xff'
+	names := make(model.LabelNames, 0, len(ls))
+	for name := range ls {
+		names = append(names, name)
+	}
+	sort.Sort(names)
+
+	var b []byte
+	for _, name := range names {
+		b = append(b, string(name)...)
+		b = append(b, sep)
+		b = append(b, string(ls[name])...)
+		b = append(b, sep)
+	}
+	return xxhash.Sum64(b)
+}
+
+// RemapNotificationLog replaces the group keys and alert hashes of the entries of a notification log.
+// It returns the remapped log and the number of entries in it.
+func RemapNotificationLog(b []byte, r StateRemapping) ([]byte, int, error) {
+	hashes := r.alertHashes()
+	remapHashes := func(hs []uint64) {
+		for i, h := range hs {
+			if to, ok := hashes[h]; ok {
+				hs[i] = to
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	count := 0
+	reader := bytes.NewReader(b)
+	for {
+		var e nflogpb.MeshEntry
+		if _, err := pbutil.ReadDelimited(reader, &e); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, 0, fmt.Errorf("failed to decode notification log: %w", err)
+		}
+		if e.Entry == nil || e.Entry.Receiver == nil {
+			return nil, 0, errors.New("failed to decode notification log: entry without receiver")
+		}
+		if !r.empty() {
+			e.Entry.GroupKey = []byte(r.groupKey(string(e.Entry.GroupKey)))
+			remapHashes(e.Entry.FiringAlerts)
+			remapHashes(e.Entry.ResolvedAlerts)
+		}
+		if _, err := pbutil.WriteDelimited(&buf, &e); err != nil {
+			return nil, 0, err
+		}
+		count++
+	}
+	return buf.Bytes(), count, nil
+}
+
+// RemapSilences replaces the values of the remapped labels in the equality matchers of silences.
+// It returns the remapped silences and their number.
+func RemapSilences(b []byte, r StateRemapping) ([]byte, int, error) {
+	var buf bytes.Buffer
+	count := 0
+	reader := bytes.NewReader(b)
+	for {
+		var s silencepb.MeshSilence
+		if _, err := pbutil.ReadDelimited(reader, &s); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, 0, fmt.Errorf("failed to decode silences: %w", err)
+		}
+		if s.Silence == nil {
+			return nil, 0, errors.New("failed to decode silences: entry without silence")
+		}
+		for _, m := range s.Silence.Matchers {
+			if m.Type != silencepb.Matcher_EQUAL {
+				continue
+			}
+			if v, ok := r.LabelValues[m.Name][m.Pattern]; ok {
+				m.Pattern = v
+			}
+		}
+		if _, err := pbutil.WriteDelimited(&buf, &s); err != nil {
+			return nil, 0, err
+		}
+		count++
+	}
+	return buf.Bytes(), count, nil
+}
+
+// rawState is a state that is already in the binary format the Alertmanager persists.
+type rawState []byte
+
+func (s rawState) MarshalBinary() ([]byte, error) {
+	return s, nil
+}
+
+var _ alertingNotify.State = rawState(nil)
+
+// ExportState returns the silences and the notification log of the Alertmanager of an organization.
+// The notification log is the one persisted by the latest maintenance of the Alertmanager.
+func (moa *MultiOrgAlertmanager) ExportState(ctx context.Context, orgID int64) (AlertmanagerState, error) {
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	orgAM, err := moa.alertmanagerForOrg(orgID)
+	if err != nil {
+		return AlertmanagerState{}, err
+	}
+
+	silences, err := orgAM.SilenceState(ctx)
+	if err != nil {
+		return AlertmanagerState{}, fmt.Errorf("failed to get silences: %w", err)
+	}
+	silencesBytes, err := silences.MarshalBinary()
+	if err != nil {
+		return AlertmanagerState{}, fmt.Errorf("failed to encode silences: %w", err)
+	}
+
+	nflog, err := NewFileStore(orgID, moa.kvStore).GetNotificationLog(ctx)
+	if err != nil {
+		return AlertmanagerState{}, err
+	}
+
+	return AlertmanagerState{
+		Silences:        silencesBytes,
+		NotificationLog: []byte(nflog),
+	}, nil
+}
+
+// RestoreState merges the silences and the notification log exported from another instance into the state of the
+// Alertmanager of an organization. The Alertmanager is stopped, so that it persists its current state, and a new one
+// is started from the merged state. Restored entries take precedence over existing entries with the same key.
+func (moa *MultiOrgAlertmanager) RestoreState(ctx context.Context, orgID int64, st AlertmanagerState, r StateRemapping) (RestoredAlertmanagerState, error) {
+	silences, silencesCount, err := RemapSilences(st.Silences, r)
+	if err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+	nflog, nflogCount, err := RemapNotificationLog(st.NotificationLog, r)
+	if err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+
+	moa.alertmanagersMtx.Lock()
+	defer moa.alertmanagersMtx.Unlock()
+
+	orgAM, err := moa.alertmanagerForOrg(orgID)
+	if err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+
+	moa.logger.Info("Stopping Alertmanager to restore its state", "org", orgID)
+	orgAM.StopAndWait()
+
+	fs := NewFileStore(orgID, moa.kvStore)
+	currentSilences, err := fs.GetSilences(ctx)
+	if err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+	currentNflog, err := fs.GetNotificationLog(ctx)
+	if err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+	// Later entries win when the Alertmanager decodes its state, so appending the restored state merges both.
+	if _, err := fs.SaveSilences(ctx, rawState(append([]byte(currentSilences), silences...))); err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+	if _, err := fs.SaveNotificationLog(ctx, rawState(append([]byte(currentNflog), nflog...))); err != nil {
+		return RestoredAlertmanagerState{}, err
+	}
+
+	moa.metrics.RemoveOrgRegistry(orgID)
+	am, err := moa.factory(ctx, orgID)
+	if err != nil {
+		delete(moa.alertmanagers, orgID)
+		return RestoredAlertmanagerState{}, fmt.Errorf("failed to create Alertmanager for org %d: %w", orgID, err)
+	}
+	moa.alertmanagers[orgID] = am
+
+	cfg, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return RestoredAlertmanagerState{}, err
+	}
+	if cfg == nil {
+		err = am.SaveAndApplyDefaultConfig(ctx)
+	} else {
+		err = am.ApplyConfig(ctx, cfg)
+	}
+	if err != nil {
+		return RestoredAlertmanagerState{}, fmt.Errorf("failed to apply Alertmanager configuration: %w", err)
+	}
+
+	moa.logger.Info("Restored Alertmanager state", "org", orgID, "silences", silencesCount, "notificationLogEntries", nflogCount)
+	return RestoredAlertmanagerState{
+		Silences:               silencesCount,
+		NotificationLogEntries: nflogCount,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRemapSilences(t *testing.T) {
+	now := time.Now()
+	s := createSilence("silence-1", now, now.Add(time.Hour))
+	s.Silence.Matchers = append(s.Silence.Matchers,
+		&silencepb.Matcher{Type: silencepb.Matcher_EQUAL, Name: alertingModels.RuleUIDLabel, Pattern: "old-uid"},
+		&silencepb.Matcher{Type: silencepb.Matcher_REGEXP, Name: alertingModels.RuleUIDLabel, Pattern: "old-uid"},
+	)
+	b, err := silenceState{"silence-1": s}.MarshalBinary()
+	require.NoError(t, err)
+
+	remapped, count, err := RemapSilences(b, StateRemapping{
+		LabelValues: map[string]map[string]string{alertingModels.RuleUIDLabel: {"old-uid": "new-uid"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	st, err := decodeSilenceState(bytes.NewReader(remapped))
+	require.NoError(t, err)
+	matchers := st["silence-1"].Silence.Matchers
+	require.Equal(t, "test_alert", matchers[0].Pattern)
+	require.Equal(t, "new-uid", matchers[2].Pattern)
+	// Only equality matchers are remapped.
+	require.Equal(t, "old-uid", matchers[3].Pattern)
+}
+
+func TestRemapNotificationLog(t *testing.T) {
+	alert := model.LabelSet{
+		model.AlertNameLabel:        "test_alert",
+		alertingModels.RuleUIDLabel: "old-uid",
+		model.LabelName("severity"): "critical",
+	}
+	other := model.LabelSet{model.AlertNameLabel: "other_alert"}
+	groupKey := `{}/{__grafana_autogenerated__="true"}:{__alert_rule_uid__="old-uid", alertname="test_alert"}`
+
+	_, e := createNotificationLog(groupKey, "receiver", time.Now(), time.Now().Add(time.Hour))
+	e.Entry.FiringAlerts = []uint64{alertHash(alert), alertHash(other)}
+	b, err := nflogState{"entry": e}.MarshalBinary()
+	require.NoError(t, err)
+
+	r := StateRemapping{
+		LabelValues: map[string]map[string]string{alertingModels.RuleUIDLabel: {"old-uid": "new-uid"}},
+		Alerts:      []model.LabelSet{alert, other},
+	}
+	remapped, count, err := RemapNotificationLog(b, r)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	st, err := decodeNflogState(bytes.NewReader(remapped))
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	for _, entry := range st {
+		require.Equal(t, `{}/{__grafana_autogenerated__="true"}:{__alert_rule_uid__="new-uid", alertname="test_alert"}`, string(entry.Entry.GroupKey))
+		newAlert := alert.Clone()
+		newAlert[alertingModels.RuleUIDLabel] = "new-uid"
+		require.Equal(t, []uint64{alertHash(newAlert), alertHash(other)}, entry.Entry.FiringAlerts)
+	}
+}
+
+func TestMultiOrgAlertmanager_ExportAndRestoreState(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	gen := models.SilenceGen(models.SilenceMuts.WithEmptyId())
+	_, err := mam.CreateSilence(ctx, 1, gen())
+	require.NoError(t, err)
+	_, err = mam.CreateSilence(ctx, 1, gen())
+	require.NoError(t, err)
+
+	exported, err := mam.ExportState(ctx, 1)
+	require.NoError(t, err)
+
+	// Stopping an Alertmanager right after it started can block because its dispatcher is not running yet.
+	time.Sleep(200 * time.Millisecond)
+	restored, err := mam.RestoreState(ctx, 2, exported, StateRemapping{})
+	require.NoError(t, err)
+	require.Equal(t, 2, restored.Silences)
+	require.Equal(t, 0, restored.NotificationLogEntries)
+
+	// The Alertmanager of the organization is replaced by one that loaded the restored state.
+	am, err := mam.alertmanagerForOrg(2)
+	require.NoError(t, err)
+	silences, err := am.ListSilences(ctx, []string{})
+	require.NoError(t, err)
+	require.Len(t, silences, 2)
+
+	state, err := am.SilenceState(ctx)
+	require.NoError(t, err)
+	b, err := state.MarshalBinary()
+	require.NoError(t, err)
+	decoded, err := alertingNotify.DecodeState(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+
+	_, err = mam.RestoreState(ctx, 10, exported, StateRemapping{})
+	require.ErrorIs(t, err, ErrAlertmanagerNotFound)
+}
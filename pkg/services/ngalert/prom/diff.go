@@ -0,0 +1,98 @@
+package prom
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleChangeAction is the change of a single rule when rule groups of a ruler are imported.
+type RuleChangeAction string
+
+const (
+	RuleChangeCreate    RuleChangeAction = "create"
+	RuleChangeUpdate    RuleChangeAction = "update"
+	RuleChangeUnchanged RuleChangeAction = "unchanged"
+	RuleChangeDelete    RuleChangeAction = "delete"
+	// RuleChangeRemovedUpstream marks an imported rule whose group does not exist in the ruler anymore
+	// but that is kept in Grafana.
+	RuleChangeRemovedUpstream RuleChangeAction = "removed_upstream"
+)
+
+// ruleFieldsToIgnoreInDiff contains the fields that are set by the store and never by the conversion.
+// RuleGroupIndex is assigned again when the group is saved.
+var ruleFieldsToIgnoreInDiff = []string{"ID", "Version", "Updated", "UpdatedBy", "RuleGroupIndex"}
+
+// RuleChange describes how importing a rule changes the rules stored in Grafana.
+type RuleChange struct {
+	UID    string
+	Title  string
+	Action RuleChangeAction
+	// Diff contains the paths of the fields that an update changes.
+	Diff []string
+}
+
+// DiffRuleGroup matches the converted rules of a group with the rules of the group stored in Grafana by UID
+// and returns the change of every rule. Stored rules that are not part of the converted group are deleted
+// when the group is replaced, and are returned after the converted rules, ordered by their index in the group.
+func DiffRuleGroup(existing []*models.AlertRule, converted []models.AlertRule) []RuleChange {
+	byUID := make(map[string]*models.AlertRule, len(existing))
+	for _, r := range existing {
+		byUID[r.UID] = r
+	}
+
+	changes := make([]RuleChange, 0, len(converted)+len(existing))
+	for i := range converted {
+		rule := &converted[i]
+		change := RuleChange{UID: rule.UID, Title: rule.Title, Action: RuleChangeCreate}
+		if current, ok := byUID[rule.UID]; ok {
+			delete(byUID, rule.UID)
+			change.Diff = diffPaths(current, rule)
+			change.Action = RuleChangeUnchanged
+			if len(change.Diff) > 0 {
+				change.Action = RuleChangeUpdate
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	deleted := make([]*models.AlertRule, 0, len(byUID))
+	for _, r := range existing {
+		if _, ok := byUID[r.UID]; ok {
+			deleted = append(deleted, r)
+		}
+	}
+	return append(changes, RemovedRuleChanges(deleted, RuleChangeDelete)...)
+}
+
+// RemovedRuleChanges returns the changes of the stored rules of a group that does not exist in the ruler anymore.
+// The action is either RuleChangeDelete or RuleChangeRemovedUpstream, depending on whether the group is deleted.
+func RemovedRuleChanges(existing []*models.AlertRule, action RuleChangeAction) []RuleChange {
+	rules := slices.Clone(existing)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].RuleGroupIndex < rules[j].RuleGroupIndex
+	})
+	changes := make([]RuleChange, 0, len(rules))
+	for _, r := range rules {
+		changes = append(changes, RuleChange{UID: r.UID, Title: r.Title, Action: action})
+	}
+	return changes
+}
+
+// HasChanges returns true if any of the changes modifies the stored rules.
+func HasChanges(changes []RuleChange) bool {
+	return slices.ContainsFunc(changes, func(c RuleChange) bool {
+		return c.Action != RuleChangeUnchanged
+	})
+}
+
+func diffPaths(current, converted *models.AlertRule) []string {
+	report := current.Diff(converted, ruleFieldsToIgnoreInDiff...)
+	if len(report) == 0 {
+		return nil
+	}
+	paths := report.Paths()
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
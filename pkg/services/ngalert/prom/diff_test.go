@@ -0,0 +1,93 @@
+package prom
+
+import (
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDiffRuleGroup(t *testing.T) {
+	converter, err := NewConverter(Config{DatasourceUID: "ds", DatasourceType: "prometheus", DefaultInterval: time.Minute})
+	require.NoError(t, err)
+
+	convert := func(rules ...PrometheusRule) []models.AlertRule {
+		group, err := converter.PrometheusRulesToGrafana(1, "folder", PrometheusRuleGroup{Name: "group", Interval: prommodel.Duration(time.Minute), Rules: rules})
+		require.NoError(t, err)
+		return group.Rules
+	}
+	stored := func(rules []models.AlertRule) []*models.AlertRule {
+		result := make([]*models.AlertRule, 0, len(rules))
+		for i := range rules {
+			r := rules[i].Copy()
+			r.ID = int64(i + 1)
+			r.Version = 3
+			r.Updated = time.Now()
+			result = append(result, r)
+		}
+		return result
+	}
+
+	alert := PrometheusRule{Alert: "HighErrorRate", Expr: "rate(errors_total[5m]) > 1"}
+	record := PrometheusRule{Record: "job:up:sum", Expr: "sum by (job) (up)"}
+
+	t.Run("should create all rules of a new group", func(t *testing.T) {
+		changes := DiffRuleGroup(nil, convert(alert, record))
+
+		require.Len(t, changes, 2)
+		require.Equal(t, RuleChangeCreate, changes[0].Action)
+		require.Equal(t, "[group] HighErrorRate", changes[0].Title)
+		require.Equal(t, RuleChangeCreate, changes[1].Action)
+		require.True(t, HasChanges(changes))
+	})
+
+	t.Run("should not change rules that are converted again", func(t *testing.T) {
+		changes := DiffRuleGroup(stored(convert(alert, record)), convert(alert, record))
+
+		require.Len(t, changes, 2)
+		for _, c := range changes {
+			require.Equal(t, RuleChangeUnchanged, c.Action)
+			require.Empty(t, c.Diff)
+		}
+		require.False(t, HasChanges(changes))
+	})
+
+	t.Run("should report changed fields", func(t *testing.T) {
+		changed := alert
+		changed.Labels = map[string]string{"severity": "critical"}
+
+		changes := DiffRuleGroup(stored(convert(alert, record)), convert(changed, record))
+
+		require.Equal(t, RuleChangeUpdate, changes[0].Action)
+		require.Contains(t, changes[0].Diff, "Labels[severity]")
+		require.Equal(t, RuleChangeUnchanged, changes[1].Action)
+	})
+
+	t.Run("should delete rules that are not in the group anymore", func(t *testing.T) {
+		existing := stored(convert(alert, record))
+
+		changes := DiffRuleGroup(existing, convert(alert))
+
+		require.Len(t, changes, 2)
+		require.Equal(t, RuleChangeUnchanged, changes[0].Action)
+		require.Equal(t, RuleChange{UID: existing[1].UID, Title: existing[1].Title, Action: RuleChangeDelete}, changes[1])
+	})
+}
+
+func TestRemovedRuleChanges(t *testing.T) {
+	rules := []*models.AlertRule{
+		{UID: "b", Title: "B", RuleGroupIndex: 2},
+		{UID: "a", Title: "A", RuleGroupIndex: 1},
+	}
+
+	changes := RemovedRuleChanges(rules, RuleChangeRemovedUpstream)
+
+	require.Equal(t, []RuleChange{
+		{UID: "a", Title: "A", Action: RuleChangeRemovedUpstream},
+		{UID: "b", Title: "B", Action: RuleChangeRemovedUpstream},
+	}, changes)
+	require.Equal(t, "b", rules[0].UID)
+}
@@ -0,0 +1,414 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrDuplicateExportGroup is returned when rule groups of different folders with the same name are exported
+// to a single Prometheus rule file, in which group names must be unique.
+var ErrDuplicateExportGroup = errors.New("rule group names must be unique in a Prometheus rule file, export the folders separately")
+
+// instantReducers are the reducers that return the value of the only point of the series returned by instant queries.
+var instantReducers = map[string]struct{}{"last": {}, "mean": {}, "min": {}, "max": {}, "sum": {}}
+
+// UnsupportedRule is a Grafana rule that cannot be represented in the Prometheus format.
+type UnsupportedRule struct {
+	Folder string
+	Group  string
+	UID    string
+	Title  string
+	Reason string
+}
+
+// ExportedRuleGroup is a Prometheus rule group and the folder of the Grafana rule group it was exported from.
+type ExportedRuleGroup struct {
+	Folder string
+	Group  PrometheusRuleGroup
+}
+
+// PrometheusRulesExport is the result of the export of Grafana rule groups to the Prometheus format.
+type PrometheusRulesExport struct {
+	Groups      []ExportedRuleGroup
+	Unsupported []UnsupportedRule
+}
+
+// GrafanaRuleGroupsToPrometheus exports Grafana rule groups to Prometheus rule groups.
+// Rules that cannot be represented in the Prometheus format are skipped and returned as unsupported,
+// and groups without any supported rule are omitted.
+func GrafanaRuleGroupsToPrometheus(groups []models.AlertRuleGroupWithFolderFullpath) (PrometheusRulesExport, error) {
+	result := PrometheusRulesExport{}
+	folders := make(map[string]string, len(groups))
+	for _, group := range groups {
+		promGroup := PrometheusRuleGroup{
+			Name:     group.Title,
+			Interval: prommodel.Duration(time.Duration(group.Interval) * time.Second),
+			Rules:    make([]PrometheusRule, 0, len(group.Rules)),
+		}
+		for i := range group.Rules {
+			rule := &group.Rules[i]
+			promRule, err := GrafanaRuleToPrometheus(rule)
+			if err != nil {
+				result.Unsupported = append(result.Unsupported, UnsupportedRule{
+					Folder: group.FolderFullpath,
+					Group:  group.Title,
+					UID:    rule.UID,
+					Title:  rule.Title,
+					Reason: err.Error(),
+				})
+				continue
+			}
+			promGroup.Rules = append(promGroup.Rules, promRule)
+		}
+		if len(promGroup.Rules) == 0 {
+			continue
+		}
+		if folder, ok := folders[promGroup.Name]; ok {
+			return PrometheusRulesExport{}, fmt.Errorf("%w: group %q exists in folders %q and %q", ErrDuplicateExportGroup, promGroup.Name, folder, group.FolderFullpath)
+		}
+		folders[promGroup.Name] = group.FolderFullpath
+		result.Groups = append(result.Groups, ExportedRuleGroup{Folder: group.FolderFullpath, Group: promGroup})
+	}
+	return result, nil
+}
+
+// MarshalYAML returns a Prometheus rule file with the exported groups. The folder of every group and
+// the rules that are not exported, with the reason why, are written as comments.
+func (e PrometheusRulesExport) MarshalYAML() (any, error) {
+	file := PrometheusRulesFile{Groups: make([]PrometheusRuleGroup, 0, len(e.Groups))}
+	for _, g := range e.Groups {
+		file.Groups = append(file.Groups, g.Group)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(file); err != nil {
+		return nil, err
+	}
+	// The node is a mapping with the key "groups" and the sequence of the groups as value.
+	if len(node.Content) == 2 {
+		for i, g := range node.Content[1].Content {
+			g.HeadComment = "Folder: " + e.Groups[i].Folder
+		}
+	}
+
+	if len(e.Unsupported) > 0 {
+		sb := strings.Builder{}
+		sb.WriteString("The following rules cannot be represented in the Prometheus format and are not exported:")
+		for _, r := range e.Unsupported {
+			sb.WriteString(fmt.Sprintf("\n- %s/%s: %q (%s): %s", r.Folder, r.Group, r.Title, r.UID, r.Reason))
+		}
+		node.HeadComment = sb.String()
+	}
+	return &node, nil
+}
+
+// GrafanaRuleToPrometheus converts a Grafana rule to a Prometheus rule. Rules that were imported from
+// a Prometheus-compatible source are exported with their original definition. Other rules can be exported
+// if they query a single Prometheus or Loki query, optionally reduce it, and compare the result to a threshold.
+// Otherwise, the returned error describes why the rule cannot be represented in the Prometheus format.
+func GrafanaRuleToPrometheus(rule *models.AlertRule) (PrometheusRule, error) {
+	if rule.IsPaused {
+		return PrometheusRule{}, errors.New("the rule is paused")
+	}
+	if definition := rule.PrometheusRuleDefinition(); definition != "" {
+		var promRule PrometheusRule
+		if err := yaml.Unmarshal([]byte(definition), &promRule); err != nil {
+			return PrometheusRule{}, fmt.Errorf("failed to parse the original Prometheus definition: %w", err)
+		}
+		return promRule, nil
+	}
+	if len(rule.NotificationSettings) > 0 {
+		return PrometheusRule{}, errors.New("the rule uses simplified routing")
+	}
+
+	nodes, err := parseExportNodes(rule.Data)
+	if err != nil {
+		return PrometheusRule{}, err
+	}
+	annotations, err := exportAnnotations(rule.Annotations)
+	if err != nil {
+		return PrometheusRule{}, err
+	}
+
+	if rule.Type() == models.RuleTypeRecording {
+		query, err := nodes.query(rule.Record.From)
+		if err != nil {
+			return PrometheusRule{}, err
+		}
+		return PrometheusRule{
+			Record:      rule.Record.Metric,
+			Expr:        query.expr,
+			Labels:      exportLabels(rule.Labels),
+			Annotations: annotations,
+		}, nil
+	}
+
+	if rule.NoDataState == models.Alerting {
+		return PrometheusRule{}, errors.New("the rule fires when the query returns no data")
+	}
+	if rule.ExecErrState == models.AlertingErrState {
+		return PrometheusRule{}, errors.New("the rule fires when the query fails")
+	}
+	promExpr, err := nodes.alertExpr(rule.Condition)
+	if err != nil {
+		return PrometheusRule{}, err
+	}
+	promRule := PrometheusRule{
+		Alert:       rule.Title,
+		Expr:        promExpr,
+		Labels:      exportLabels(rule.Labels),
+		Annotations: annotations,
+	}
+	if rule.For > 0 {
+		promRule.For = new(prommodel.Duration)
+		*promRule.For = prommodel.Duration(rule.For)
+	}
+	return promRule, nil
+}
+
+// exportNode is a query or an expression of a rule.
+type exportNode struct {
+	isExpr bool
+	model  map[string]any
+	// expr is the query of a data source query.
+	expr    string
+	instant bool
+}
+
+type exportNodes map[string]exportNode
+
+func parseExportNodes(data []models.AlertQuery) (exportNodes, error) {
+	nodes := make(exportNodes, len(data))
+	for _, q := range data {
+		var model map[string]any
+		if err := json.Unmarshal(q.Model, &model); err != nil {
+			return nil, fmt.Errorf("failed to parse the model of query %s: %w", q.RefID, err)
+		}
+		node := exportNode{model: model}
+		if q.DatasourceUID == expr.DatasourceUID || expr.IsDataSource(q.DatasourceUID) {
+			node.isExpr = true
+			nodes[q.RefID] = node
+			continue
+		}
+		if q.RelativeTimeRange.To != 0 {
+			return nil, fmt.Errorf("query %s is evaluated with an offset", q.RefID)
+		}
+		if ds, ok := model["datasource"].(map[string]any); ok {
+			if t, _ := ds["type"].(string); t != "" && t != datasources.DS_PROMETHEUS && t != datasources.DS_LOKI {
+				return nil, fmt.Errorf("query %s uses a data source of type %s, only Prometheus and Loki queries are supported", q.RefID, t)
+			}
+		}
+		node.expr, _ = model["expr"].(string)
+		if strings.TrimSpace(node.expr) == "" {
+			return nil, fmt.Errorf("query %s is not a Prometheus or Loki query", q.RefID)
+		}
+		instant, _ := model["instant"].(bool)
+		queryType, _ := model["queryType"].(string)
+		node.instant = instant || queryType == "instant"
+		nodes[q.RefID] = node
+	}
+	return nodes, nil
+}
+
+// query returns the data source query with the ref ID.
+func (n exportNodes) query(refID string) (exportNode, error) {
+	node, ok := n[refID]
+	if !ok {
+		return exportNode{}, fmt.Errorf("query %s does not exist", refID)
+	}
+	if node.isExpr {
+		return exportNode{}, fmt.Errorf("%s must be a Prometheus or Loki query, but it is an expression", refID)
+	}
+	return node, nil
+}
+
+// alertExpr returns the PromQL or LogQL expression that returns the series for which the condition of the rule is firing.
+func (n exportNodes) alertExpr(condition string) (string, error) {
+	node, ok := n[condition]
+	if !ok {
+		return "", fmt.Errorf("condition %s does not exist", condition)
+	}
+	if !node.isExpr {
+		return "", errors.New("the condition must be a threshold expression")
+	}
+	exprType, _ := node.model["type"].(string)
+	switch exprType {
+	case string(expr.QueryTypeThreshold):
+		var cfg expr.ThresholdCommandConfig
+		if err := remarshal(node.model, &cfg); err != nil {
+			return "", fmt.Errorf("failed to parse threshold %s: %w", condition, err)
+		}
+		if len(cfg.Conditions) != 1 {
+			return "", fmt.Errorf("threshold %s must have exactly one condition", condition)
+		}
+		if cfg.Conditions[0].UnloadEvaluator != nil {
+			return "", fmt.Errorf("threshold %s uses a recovery threshold", condition)
+		}
+		input := strings.TrimPrefix(cfg.Expression, "$")
+		if query, ok := n.everySeriesQuery(input); ok && cfg.Conditions[0].Evaluator.Type == expr.ThresholdIsAbove &&
+			len(cfg.Conditions[0].Evaluator.Params) > 0 && cfg.Conditions[0].Evaluator.Params[0] == 0 {
+			return query.expr, nil
+		}
+		query, err := n.thresholdInput(input)
+		if err != nil {
+			return "", err
+		}
+		return thresholdExpr(query, cfg.Conditions[0].Evaluator)
+	default:
+		return "", fmt.Errorf("the condition must be a threshold expression, but %s is a %s expression", condition, exprType)
+	}
+}
+
+// everySeriesQuery returns the query of a math expression that returns 1 for every series of an instant query,
+// which is how rules converted from Prometheus fire for every series returned by their query.
+func (n exportNodes) everySeriesQuery(refID string) (exportNode, bool) {
+	node, ok := n[refID]
+	if !ok || !node.isExpr {
+		return exportNode{}, false
+	}
+	if exprType, _ := node.model["type"].(string); exprType != string(expr.QueryTypeMath) {
+		return exportNode{}, false
+	}
+	mathExpr, _ := node.model["expression"].(string)
+	for queryRefID, q := range n {
+		if !q.isExpr && q.instant && mathExpr == fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", queryRefID) {
+			return q, true
+		}
+	}
+	return exportNode{}, false
+}
+
+// thresholdInput returns the query whose result is compared by the threshold. The query can be reduced to
+// its value if the reduction returns the same value as an instant query.
+func (n exportNodes) thresholdInput(refID string) (exportNode, error) {
+	node, ok := n[refID]
+	if !ok {
+		return exportNode{}, fmt.Errorf("query %s does not exist", refID)
+	}
+	if !node.isExpr {
+		if !node.instant {
+			return exportNode{}, fmt.Errorf("query %s must be an instant query or be reduced", refID)
+		}
+		return node, nil
+	}
+
+	if exprType, _ := node.model["type"].(string); exprType != string(expr.QueryTypeReduce) {
+		return exportNode{}, fmt.Errorf("the threshold must compare a reduced query, but %s is a %s expression", refID, exprType)
+	}
+	reducer, _ := node.model["reducer"].(string)
+	input, _ := node.model["expression"].(string)
+	query, err := n.query(strings.TrimPrefix(input, "$"))
+	if err != nil {
+		return exportNode{}, err
+	}
+	if _, ok := instantReducers[reducer]; ok && query.instant || reducer == "last" {
+		return query, nil
+	}
+	return exportNode{}, fmt.Errorf("reducer %s of %s cannot be represented in PromQL", reducer, refID)
+}
+
+func thresholdExpr(query exportNode, evaluator expr.ConditionEvalJSON) (string, error) {
+	p := func(i int) string {
+		return strconv.FormatFloat(evaluator.Params[i], 'f', -1, 64)
+	}
+	params := 1
+	switch evaluator.Type {
+	case expr.ThresholdIsWithinRange, expr.ThresholdIsOutsideRange, expr.ThresholdIsWithinRangeIncluded, expr.ThresholdIsOutsideRangeIncluded:
+		params = 2
+	}
+	if len(evaluator.Params) < params {
+		return "", fmt.Errorf("threshold of type %s must have %d parameters", evaluator.Type, params)
+	}
+
+	q := wrapExpr(query.expr)
+	switch evaluator.Type {
+	case expr.ThresholdIsAbove:
+		return fmt.Sprintf("%s > %s", q, p(0)), nil
+	case expr.ThresholdIsBelow:
+		return fmt.Sprintf("%s < %s", q, p(0)), nil
+	case expr.ThresholdIsEqual:
+		return fmt.Sprintf("%s == %s", q, p(0)), nil
+	case expr.ThresholdIsNotEqual:
+		return fmt.Sprintf("%s != %s", q, p(0)), nil
+	case expr.ThresholdIsGreaterThanEqual:
+		return fmt.Sprintf("%s >= %s", q, p(0)), nil
+	case expr.ThresholdIsLessThanEqual:
+		return fmt.Sprintf("%s <= %s", q, p(0)), nil
+	case expr.ThresholdIsWithinRange:
+		return fmt.Sprintf("%s > %s < %s", q, p(0), p(1)), nil
+	case expr.ThresholdIsWithinRangeIncluded:
+		return fmt.Sprintf("%s >= %s <= %s", q, p(0), p(1)), nil
+	case expr.ThresholdIsOutsideRange:
+		return fmt.Sprintf("%s < %s or %s > %s", q, p(0), q, p(1)), nil
+	case expr.ThresholdIsOutsideRangeIncluded:
+		return fmt.Sprintf("%s <= %s or %s >= %s", q, p(0), q, p(1)), nil
+	default:
+		return "", fmt.Errorf("threshold of type %s cannot be represented in PromQL", evaluator.Type)
+	}
+}
+
+// wrapExpr puts the query in parentheses unless it is a PromQL query that does not need them to be compared.
+func wrapExpr(query string) string {
+	query = strings.TrimSpace(query)
+	parsed, err := parser.ParseExpr(query)
+	if err != nil {
+		return "(" + query + ")"
+	}
+	switch parsed.(type) {
+	case *parser.BinaryExpr, *parser.UnaryExpr:
+		return "(" + query + ")"
+	}
+	return query
+}
+
+func exportLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if _, ok := models.InternalLabelNameSet[k]; ok {
+			continue
+		}
+		result[k] = v
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// exportAnnotations returns the annotations without the annotations that are used internally by Grafana.
+func exportAnnotations(annotations map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "__") && strings.HasSuffix(k, "__") {
+			continue
+		}
+		if strings.Contains(v, "$values") {
+			return nil, fmt.Errorf("annotation %s uses $values, which does not exist in Prometheus templates", k)
+		}
+		result[k] = v
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func remarshal(in any, out any) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
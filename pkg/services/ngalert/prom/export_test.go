@@ -0,0 +1,311 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func exportQuery(refID, dsType, promExpr string, instant bool) models.AlertQuery {
+	model, _ := json.Marshal(map[string]any{
+		"refId":      refID,
+		"datasource": map[string]any{"type": dsType, "uid": "ds-uid"},
+		"expr":       promExpr,
+		"instant":    instant,
+		"range":      !instant,
+	})
+	return models.AlertQuery{
+		RefID:             refID,
+		DatasourceUID:     "ds-uid",
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(10 * time.Minute)},
+		Model:             model,
+	}
+}
+
+func exportExpression(refID string, model map[string]any) models.AlertQuery {
+	model["refId"] = refID
+	model["datasource"] = map[string]any{"type": "__expr__", "uid": expr.DatasourceUID}
+	b, _ := json.Marshal(model)
+	return models.AlertQuery{RefID: refID, DatasourceUID: expr.DatasourceUID, Model: b}
+}
+
+func exportThreshold(refID, input, evalType string, params ...float64) models.AlertQuery {
+	return exportExpression(refID, map[string]any{
+		"type":       "threshold",
+		"expression": input,
+		"conditions": []any{map[string]any{"evaluator": map[string]any{"type": evalType, "params": params}}},
+	})
+}
+
+func exportReduce(refID, input, reducer string) models.AlertQuery {
+	return exportExpression(refID, map[string]any{"type": "reduce", "expression": input, "reducer": reducer})
+}
+
+func exportAlertRule(data ...models.AlertQuery) *models.AlertRule {
+	return &models.AlertRule{
+		UID:          "rule-uid",
+		Title:        "High CPU",
+		Condition:    data[len(data)-1].RefID,
+		Data:         data,
+		For:          5 * time.Minute,
+		NoDataState:  models.NoData,
+		ExecErrState: models.ErrorErrState,
+		Labels:       map[string]string{"severity": "critical"},
+		Annotations: map[string]string{
+			"summary":                     "CPU usage is {{ $value }}",
+			models.DashboardUIDAnnotation: "dashboard",
+		},
+	}
+}
+
+func TestGrafanaRuleToPrometheus(t *testing.T) {
+	t.Run("should export a reduced query compared to a threshold", func(t *testing.T) {
+		rule := exportAlertRule(
+			exportQuery("A", "prometheus", "cpu_usage", false),
+			exportReduce("B", "A", "last"),
+			exportThreshold("C", "B", "gt", 80),
+		)
+		promRule, err := GrafanaRuleToPrometheus(rule)
+		require.NoError(t, err)
+		require.Equal(t, PrometheusRule{
+			Alert:       "High CPU",
+			Expr:        "cpu_usage > 80",
+			For:         util.Pointer(prommodel.Duration(5 * time.Minute)),
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: map[string]string{"summary": "CPU usage is {{ $value }}"},
+		}, promRule)
+	})
+
+	t.Run("should export threshold types", func(t *testing.T) {
+		testCases := []struct {
+			evalType string
+			params   []float64
+			expected string
+		}{
+			{"lt", []float64{0.5}, "up < 0.5"},
+			{"eq", []float64{1}, "up == 1"},
+			{"ne", []float64{1}, "up != 1"},
+			{"gte", []float64{1}, "up >= 1"},
+			{"lte", []float64{1}, "up <= 1"},
+			{"within_range", []float64{1, 5}, "up > 1 < 5"},
+			{"within_range_included", []float64{1, 5}, "up >= 1 <= 5"},
+			{"outside_range", []float64{1, 5}, "up < 1 or up > 5"},
+			{"outside_range_included", []float64{1, 5}, "up <= 1 or up >= 5"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.evalType, func(t *testing.T) {
+				rule := exportAlertRule(
+					exportQuery("A", "prometheus", "up", true),
+					exportThreshold("B", "A", tc.evalType, tc.params...),
+				)
+				promRule, err := GrafanaRuleToPrometheus(rule)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, promRule.Expr)
+			})
+		}
+	})
+
+	t.Run("should wrap binary expressions in parentheses", func(t *testing.T) {
+		rule := exportAlertRule(
+			exportQuery("A", "prometheus", "sum(rate(errors[5m])) / sum(rate(requests[5m]))", true),
+			exportReduce("B", "A", "mean"),
+			exportThreshold("C", "B", "gt", 0.05),
+		)
+		promRule, err := GrafanaRuleToPrometheus(rule)
+		require.NoError(t, err)
+		require.Equal(t, "(sum(rate(errors[5m])) / sum(rate(requests[5m]))) > 0.05", promRule.Expr)
+	})
+
+	t.Run("should export LogQL queries", func(t *testing.T) {
+		rule := exportAlertRule(
+			exportQuery("A", "loki", `count_over_time({job="app"} |= "error" [5m])`, true),
+			exportThreshold("B", "A", "gt", 10),
+		)
+		promRule, err := GrafanaRuleToPrometheus(rule)
+		require.NoError(t, err)
+		require.Equal(t, `(count_over_time({job="app"} |= "error" [5m])) > 10`, promRule.Expr)
+	})
+
+	t.Run("should export rules converted from Prometheus without the original definition", func(t *testing.T) {
+		rule := exportAlertRule(
+			exportQuery("query", "prometheus", "up == 0", true),
+			exportExpression("prometheus_math", map[string]any{"type": "math", "expression": "is_number($query) || is_nan($query) || is_inf($query)"}),
+			exportThreshold("threshold", "prometheus_math", "gt", 0),
+		)
+		promRule, err := GrafanaRuleToPrometheus(rule)
+		require.NoError(t, err)
+		require.Equal(t, "up == 0", promRule.Expr)
+	})
+
+	t.Run("should export the original definition of imported rules", func(t *testing.T) {
+		rule := exportAlertRule(exportQuery("A", "prometheus", "up", true))
+		rule.Metadata.PrometheusStyleRule = &models.PrometheusStyleRule{
+			OriginalRuleDefinition: "alert: InstanceDown\nexpr: up == 0\nfor: 1m\nlabels:\n  severity: page\n",
+		}
+		promRule, err := GrafanaRuleToPrometheus(rule)
+		require.NoError(t, err)
+		require.Equal(t, PrometheusRule{
+			Alert:  "InstanceDown",
+			Expr:   "up == 0",
+			For:    util.Pointer(prommodel.Duration(time.Minute)),
+			Labels: map[string]string{"severity": "page"},
+		}, promRule)
+	})
+
+	t.Run("should export recording rules", func(t *testing.T) {
+		rule := exportAlertRule(exportQuery("A", "prometheus", "sum(rate(requests[5m]))", true))
+		rule.Record = &models.Record{Metric: "job:requests:rate5m", From: "A"}
+		rule.Annotations = nil
+		promRule, err := GrafanaRuleToPrometheus(rule)
+		require.NoError(t, err)
+		require.Equal(t, PrometheusRule{
+			Record: "job:requests:rate5m",
+			Expr:   "sum(rate(requests[5m]))",
+			Labels: map[string]string{"severity": "critical"},
+		}, promRule)
+	})
+
+	t.Run("should report rules that cannot be represented", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			rule     func() *models.AlertRule
+			expected string
+		}{
+			{
+				name: "range query without reduce",
+				rule: func() *models.AlertRule {
+					return exportAlertRule(exportQuery("A", "prometheus", "up", false), exportThreshold("B", "A", "gt", 1))
+				},
+				expected: "query A must be an instant query or be reduced",
+			},
+			{
+				name: "reducer of range query",
+				rule: func() *models.AlertRule {
+					return exportAlertRule(exportQuery("A", "prometheus", "up", false), exportReduce("B", "A", "mean"), exportThreshold("C", "B", "gt", 1))
+				},
+				expected: "reducer mean of B cannot be represented in PromQL",
+			},
+			{
+				name: "other data source",
+				rule: func() *models.AlertRule {
+					return exportAlertRule(exportQuery("A", "mysql", "SELECT 1", true), exportThreshold("B", "A", "gt", 1))
+				},
+				expected: "query A uses a data source of type mysql, only Prometheus and Loki queries are supported",
+			},
+			{
+				name: "classic condition",
+				rule: func() *models.AlertRule {
+					return exportAlertRule(exportQuery("A", "prometheus", "up", true), exportExpression("B", map[string]any{"type": "classic_conditions"}))
+				},
+				expected: "the condition must be a threshold expression, but B is a classic_conditions expression",
+			},
+			{
+				name: "recovery threshold",
+				rule: func() *models.AlertRule {
+					return exportAlertRule(exportQuery("A", "prometheus", "up", true), exportExpression("B", map[string]any{
+						"type":       "threshold",
+						"expression": "A",
+						"conditions": []any{map[string]any{
+							"evaluator":       map[string]any{"type": "gt", "params": []float64{10}},
+							"unloadEvaluator": map[string]any{"type": "lt", "params": []float64{5}},
+						}},
+					}))
+				},
+				expected: "threshold B uses a recovery threshold",
+			},
+			{
+				name: "alerting on no data",
+				rule: func() *models.AlertRule {
+					r := exportAlertRule(exportQuery("A", "prometheus", "up", true), exportThreshold("B", "A", "gt", 1))
+					r.NoDataState = models.Alerting
+					return r
+				},
+				expected: "the rule fires when the query returns no data",
+			},
+			{
+				name: "paused rule",
+				rule: func() *models.AlertRule {
+					r := exportAlertRule(exportQuery("A", "prometheus", "up", true), exportThreshold("B", "A", "gt", 1))
+					r.IsPaused = true
+					return r
+				},
+				expected: "the rule is paused",
+			},
+			{
+				name: "annotation with $values",
+				rule: func() *models.AlertRule {
+					r := exportAlertRule(exportQuery("A", "prometheus", "up", true), exportThreshold("B", "A", "gt", 1))
+					r.Annotations["description"] = "{{ $values.A }}"
+					return r
+				},
+				expected: "annotation description uses $values, which does not exist in Prometheus templates",
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := GrafanaRuleToPrometheus(tc.rule())
+				require.EqualError(t, err, tc.expected)
+			})
+		}
+	})
+}
+
+func TestGrafanaRuleGroupsToPrometheus(t *testing.T) {
+	supported := exportAlertRule(exportQuery("A", "prometheus", "up", true), exportThreshold("B", "A", "lt", 1))
+	unsupported := exportAlertRule(exportQuery("A", "mysql", "SELECT 1", true), exportThreshold("B", "A", "gt", 1))
+	unsupported.UID = "other-uid"
+	unsupported.Title = "SQL rule"
+
+	groups := []models.AlertRuleGroupWithFolderFullpath{
+		{
+			AlertRuleGroup: &models.AlertRuleGroup{Title: "group-1", Interval: 60, Rules: []models.AlertRule{*supported, *unsupported}},
+			FolderFullpath: "parent/folder",
+		},
+		{
+			AlertRuleGroup: &models.AlertRuleGroup{Title: "group-2", Interval: 60, Rules: []models.AlertRule{*unsupported}},
+			FolderFullpath: "other",
+		},
+	}
+
+	t.Run("should export supported rules and report the others", func(t *testing.T) {
+		result, err := GrafanaRuleGroupsToPrometheus(groups)
+		require.NoError(t, err)
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, "parent/folder", result.Groups[0].Folder)
+		require.Equal(t, "group-1", result.Groups[0].Group.Name)
+		require.Equal(t, prommodel.Duration(time.Minute), result.Groups[0].Group.Interval)
+		require.Len(t, result.Groups[0].Group.Rules, 1)
+		require.Equal(t, []UnsupportedRule{
+			{Folder: "parent/folder", Group: "group-1", UID: "other-uid", Title: "SQL rule", Reason: "query A uses a data source of type mysql, only Prometheus and Loki queries are supported"},
+			{Folder: "other", Group: "group-2", UID: "other-uid", Title: "SQL rule", Reason: "query A uses a data source of type mysql, only Prometheus and Loki queries are supported"},
+		}, result.Unsupported)
+
+		out, err := yaml.Marshal(result)
+		require.NoError(t, err)
+		require.Contains(t, string(out), "# Folder: parent/folder")
+		require.Contains(t, string(out), `# - parent/folder/group-1: "SQL rule" (other-uid)`)
+
+		var file PrometheusRulesFile
+		require.NoError(t, yaml.Unmarshal(out, &file))
+		require.Len(t, file.Groups, 1)
+		require.Equal(t, "up < 1", file.Groups[0].Rules[0].Expr)
+		require.NoError(t, file.Groups[0].Rules[0].Validate())
+	})
+
+	t.Run("should fail if groups of different folders have the same name", func(t *testing.T) {
+		duplicate := append(groups, models.AlertRuleGroupWithFolderFullpath{
+			AlertRuleGroup: &models.AlertRuleGroup{Title: "group-1", Interval: 60, Rules: []models.AlertRule{*supported}},
+			FolderFullpath: "another",
+		})
+		_, err := GrafanaRuleGroupsToPrometheus(duplicate)
+		require.ErrorIs(t, err, ErrDuplicateExportGroup)
+	})
+}
@@ -0,0 +1,134 @@
+package prom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRulerRulesPath is the path of the endpoint of Cortex and Mimir rulers that returns the rule groups of all namespaces.
+	// Loki serves the same endpoint at /loki/api/v1/rules.
+	DefaultRulerRulesPath = "/api/v1/rules"
+
+	tenantIDHeader = "X-Scope-OrgID"
+
+	// maxRulerErrorBodySize limits the size of the response body that is added to errors.
+	maxRulerErrorBodySize = 512
+)
+
+// RulerClientConfig defines how to connect to a Prometheus-compatible ruler.
+type RulerClientConfig struct {
+	// URL is the base URL of the ruler, for example http://mimir:8080 or http://mimir:8080/prometheus.
+	URL string
+	// RulesPath is the path of the rules endpoint relative to URL. DefaultRulerRulesPath is used if it is empty.
+	RulesPath string
+	// TenantID is sent in the X-Scope-OrgID header if it is set.
+	TenantID          string
+	BasicAuthUser     string
+	BasicAuthPassword string
+	Headers           map[string]string
+}
+
+// RulerRuleGroup is a rule group as returned by a Cortex, Mimir or Loki ruler.
+// It contains the settings of these rulers that cannot be converted to Grafana rule groups.
+type RulerRuleGroup struct {
+	PrometheusRuleGroup `yaml:",inline"`
+	QueryOffset         *prommodel.Duration `yaml:"query_offset,omitempty"`
+	EvaluationDelay     *prommodel.Duration `yaml:"evaluation_delay,omitempty"`
+	Limit               int                 `yaml:"limit,omitempty"`
+	SourceTenants       []string            `yaml:"source_tenants,omitempty"`
+}
+
+// UnsupportedSettings returns warnings for the settings of the group that are ignored by the conversion.
+func (g RulerRuleGroup) UnsupportedSettings() []string {
+	var warnings []string
+	if g.QueryOffset != nil && *g.QueryOffset != 0 {
+		warnings = append(warnings, fmt.Sprintf("query_offset %s is not supported and is ignored", g.QueryOffset))
+	}
+	if g.EvaluationDelay != nil && *g.EvaluationDelay != 0 {
+		warnings = append(warnings, fmt.Sprintf("evaluation_delay %s is not supported and is ignored", g.EvaluationDelay))
+	}
+	if g.Limit > 0 {
+		warnings = append(warnings, fmt.Sprintf("limit %d is not supported and is ignored", g.Limit))
+	}
+	if len(g.SourceTenants) > 0 {
+		warnings = append(warnings, fmt.Sprintf("source_tenants %s are not supported, rules query only the configured data source", strings.Join(g.SourceTenants, ", ")))
+	}
+	return warnings
+}
+
+// RulerClient reads rule groups from the configuration API of a Prometheus-compatible ruler, such as Cortex, Mimir or Loki.
+type RulerClient struct {
+	cfg      RulerClientConfig
+	rulesURL string
+	client   *http.Client
+}
+
+// NewRulerClient creates a RulerClient. It returns an error if the URL of the ruler is not a valid HTTP(S) URL.
+func NewRulerClient(cfg RulerClientConfig, client *http.Client) (*RulerClient, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ruler URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid ruler URL %q: must be an absolute http or https URL", cfg.URL)
+	}
+	rulesPath := cfg.RulesPath
+	if rulesPath == "" {
+		rulesPath = DefaultRulerRulesPath
+	}
+	u = u.JoinPath(rulesPath)
+
+	return &RulerClient{
+		cfg:      cfg,
+		rulesURL: u.String(),
+		client:   client,
+	}, nil
+}
+
+// GetRuleNamespaces returns the rule groups of all namespaces of the ruler, keyed by namespace.
+// A ruler without rules responds with 404 Not Found, in which case the result is empty.
+func (c *RulerClient) GetRuleNamespaces(ctx context.Context) (map[string][]RulerRuleGroup, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.rulesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/yaml")
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.cfg.TenantID != "" {
+		req.Header.Set(tenantIDHeader, c.cfg.TenantID)
+	}
+	if c.cfg.BasicAuthUser != "" {
+		req.SetBasicAuth(c.cfg.BasicAuthUser, c.cfg.BasicAuthPassword)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request rules from the ruler: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string][]RulerRuleGroup{}, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxRulerErrorBodySize))
+		return nil, fmt.Errorf("ruler responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	namespaces := map[string][]RulerRuleGroup{}
+	if err := yaml.NewDecoder(resp.Body).Decode(&namespaces); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse rules of the ruler: %w", err)
+	}
+	return namespaces, nil
+}
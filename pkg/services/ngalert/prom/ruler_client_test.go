@@ -0,0 +1,135 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulerClient_GetRuleNamespaces(t *testing.T) {
+	const rules = `
+ns1:
+  - name: group1
+    interval: 30s
+    source_tenants: [a, b]
+    rules:
+      - alert: HighErrorRate
+        expr: rate(errors_total[5m]) > 1
+        for: 5m
+        labels:
+          severity: critical
+ns2:
+  - name: group2
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`
+
+	t.Run("should read rules of all namespaces", func(t *testing.T) {
+		var req *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req = r
+			_, _ = w.Write([]byte(rules))
+		}))
+		t.Cleanup(srv.Close)
+
+		client, err := NewRulerClient(RulerClientConfig{
+			URL:               srv.URL + "/prometheus",
+			TenantID:          "tenant-1",
+			BasicAuthUser:     "user",
+			BasicAuthPassword: "password",
+			Headers:           map[string]string{"X-Custom": "value"},
+		}, srv.Client())
+		require.NoError(t, err)
+
+		namespaces, err := client.GetRuleNamespaces(context.Background())
+		require.NoError(t, err)
+
+		require.Equal(t, "/prometheus/api/v1/rules", req.URL.Path)
+		require.Equal(t, "tenant-1", req.Header.Get("X-Scope-OrgID"))
+		require.Equal(t, "value", req.Header.Get("X-Custom"))
+		user, password, ok := req.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+
+		require.Len(t, namespaces, 2)
+		group := namespaces["ns1"][0]
+		require.Equal(t, "group1", group.Name)
+		require.Equal(t, prommodel.Duration(30*time.Second), group.Interval)
+		require.Equal(t, []string{"a", "b"}, group.SourceTenants)
+		require.Len(t, group.Rules, 1)
+		require.Equal(t, "HighErrorRate", group.Rules[0].Alert)
+		require.Equal(t, map[string]string{"severity": "critical"}, group.Rules[0].Labels)
+		require.Equal(t, "job:up:sum", namespaces["ns2"][0].Rules[0].Record)
+	})
+
+	t.Run("should use the configured rules path", func(t *testing.T) {
+		var path string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+		}))
+		t.Cleanup(srv.Close)
+
+		client, err := NewRulerClient(RulerClientConfig{URL: srv.URL, RulesPath: "/loki/api/v1/rules"}, srv.Client())
+		require.NoError(t, err)
+
+		namespaces, err := client.GetRuleNamespaces(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, namespaces)
+		require.Equal(t, "/loki/api/v1/rules", path)
+	})
+
+	t.Run("should return no rules if the ruler responds with not found", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "no rule groups found", http.StatusNotFound)
+		}))
+		t.Cleanup(srv.Close)
+
+		client, err := NewRulerClient(RulerClientConfig{URL: srv.URL}, srv.Client())
+		require.NoError(t, err)
+
+		namespaces, err := client.GetRuleNamespaces(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, namespaces)
+	})
+
+	t.Run("should return error if the ruler fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "ruler is unavailable", http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+
+		client, err := NewRulerClient(RulerClientConfig{URL: srv.URL}, srv.Client())
+		require.NoError(t, err)
+
+		_, err = client.GetRuleNamespaces(context.Background())
+		require.ErrorContains(t, err, "503")
+		require.ErrorContains(t, err, "ruler is unavailable")
+	})
+
+	t.Run("should return error for invalid URLs", func(t *testing.T) {
+		for _, u := range []string{"", "mimir:8080", "ftp://mimir", "http://"} {
+			_, err := NewRulerClient(RulerClientConfig{URL: u}, http.DefaultClient)
+			require.Error(t, err, u)
+		}
+	})
+}
+
+func TestRulerRuleGroup_UnsupportedSettings(t *testing.T) {
+	offset := prommodel.Duration(time.Minute)
+	group := RulerRuleGroup{QueryOffset: &offset, Limit: 10, SourceTenants: []string{"a"}}
+
+	warnings := group.UnsupportedSettings()
+
+	require.Len(t, warnings, 3)
+	require.Contains(t, warnings[0], "query_offset 1m")
+	require.Contains(t, warnings[1], "limit 10")
+	require.Contains(t, warnings[2], "source_tenants a")
+	require.Empty(t, RulerRuleGroup{}.UnsupportedSettings())
+}
@@ -26,6 +26,13 @@ var (
 	ErrTemplateInvalid  = errutil.BadRequest("alerting.notifications.templates.invalidFormat").MustTemplate("Invalid format of the submitted template", errutil.WithPublic("Template is in invalid format. Correct the payload and try again."))
 	ErrTemplateExists   = errutil.BadRequest("alerting.notifications.templates.nameExists", errutil.WithPublicMessage("Template file with this name already exists. Use a different name or update existing one."))
 
+	ErrSilenceTemplateNotFound = errutil.NotFound("alerting.silence-templates.notFound", errutil.WithPublicMessage("Silence template not found"))
+	ErrSilenceTemplateExists   = errutil.BadRequest("alerting.silence-templates.nameExists", errutil.WithPublicMessage("Silence template with this name already exists. Use a different name or update existing one."))
+	ErrSilenceTemplateInvalid  = errutil.BadRequest("alerting.silence-templates.invalidFormat").MustTemplate(
+		"Invalid format of the submitted silence template",
+		errutil.WithPublic("Silence template is in invalid format: {{.Public.Error}}. Correct the payload and try again."),
+	)
+
 	ErrContactPointReferenced = errutil.Conflict("alerting.notifications.contact-points.referenced", errutil.WithPublicMessage("Contact point is currently referenced by a notification policy."))
 	ErrContactPointUsedInRule = errutil.Conflict("alerting.notifications.contact-points.used-by-rule", errutil.WithPublicMessage("Contact point is currently used in the notification settings of one or many alert rules."))
 	contactPointUidExists     = "Receiver configuration with UID '{{ .Public.UID }}' already exists in contact point '{{ .Public.Name }}'. Please use unique identifiers for receivers across all contact points."
@@ -100,6 +107,16 @@ func MakeErrTimeIntervalDependentResourcesProvenance(usedByRoutes bool, rules []
 	})
 }
 
+// MakeErrSilenceTemplateInvalid creates an error with the ErrSilenceTemplateInvalid template
+func MakeErrSilenceTemplateInvalid(err error) error {
+	return ErrSilenceTemplateInvalid.Build(errutil.TemplateData{
+		Public: map[string]any{
+			"Error": err.Error(),
+		},
+		Error: err,
+	})
+}
+
 func MakeErrRouteInvalidFormat(err error) error {
 	return ErrRouteInvalidFormat.Build(errutil.TemplateData{
 		Public: map[string]any{
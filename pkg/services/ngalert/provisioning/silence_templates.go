@@ -0,0 +1,334 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
+)
+
+type SilenceTemplateStore interface {
+	ListSilenceTemplates(ctx context.Context, orgID int64) ([]models.SilenceTemplate, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (models.SilenceTemplate, error)
+	InsertSilenceTemplate(ctx context.Context, t models.SilenceTemplate) (models.SilenceTemplate, error)
+	UpdateSilenceTemplate(ctx context.Context, t models.SilenceTemplate) error
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error
+}
+
+// SilenceExpirer expires silences in the Alertmanager of an organization.
+type SilenceExpirer interface {
+	DeleteSilence(ctx context.Context, orgID int64, silenceID string) error
+}
+
+type SilenceTemplateService struct {
+	store           SilenceTemplateStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	silences        SilenceExpirer
+	log             log.Logger
+	validator       validation.ProvenanceStatusTransitionValidator
+	now             func() time.Time
+}
+
+func NewSilenceTemplateService(store SilenceTemplateStore, prov ProvisioningStore, xact TransactionManager, silences SilenceExpirer, log log.Logger) *SilenceTemplateService {
+	return &SilenceTemplateService{
+		store:           store,
+		provenanceStore: prov,
+		xact:            xact,
+		silences:        silences,
+		log:             log,
+		validator:       validation.ValidateProvenanceRelaxed,
+		now:             time.Now,
+	}
+}
+
+// GetSilenceTemplates returns all silence templates of the organization sorted by name.
+func (svc *SilenceTemplateService) GetSilenceTemplates(ctx context.Context, orgID int64) ([]definitions.SilenceTemplate, error) {
+	templates, err := svc.store.ListSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return []definitions.SilenceTemplate{}, nil
+	}
+
+	provenances, err := svc.provenanceStore.GetProvenances(ctx, orgID, (&definitions.SilenceTemplate{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.SilenceTemplate, 0, len(templates))
+	for _, t := range templates {
+		def := svc.toDefinition(t)
+		if prov, ok := provenances[def.ResourceID()]; ok {
+			def.Provenance = definitions.Provenance(prov)
+		}
+		result = append(result, def)
+	}
+	return result, nil
+}
+
+// GetSilenceTemplate returns a silence template by UID.
+func (svc *SilenceTemplateService) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (definitions.SilenceTemplate, error) {
+	t, err := svc.getSilenceTemplate(ctx, orgID, uid)
+	if err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+
+	result := svc.toDefinition(t)
+	prov, err := svc.provenanceStore.GetProvenance(ctx, &result, orgID)
+	if err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+	result.Provenance = definitions.Provenance(prov)
+	return result, nil
+}
+
+// CreateSilenceTemplate creates a new silence template. The first silence of a recurring template is created by the scheduler.
+func (svc *SilenceTemplateService) CreateSilenceTemplate(ctx context.Context, orgID int64, t definitions.SilenceTemplate) (definitions.SilenceTemplate, error) {
+	tmpl := fromSilenceTemplateDefinition(orgID, t)
+	if err := tmpl.Validate(); err != nil {
+		return definitions.SilenceTemplate{}, MakeErrSilenceTemplateInvalid(err)
+	}
+
+	var created models.SilenceTemplate
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = svc.store.InsertSilenceTemplate(ctx, tmpl)
+		if err != nil {
+			if errors.Is(err, models.ErrSilenceTemplateExists) {
+				return ErrSilenceTemplateExists.Errorf("")
+			}
+			return err
+		}
+		result := definitions.SilenceTemplate{UID: created.UID}
+		return svc.provenanceStore.SetProvenance(ctx, &result, orgID, models.Provenance(t.Provenance))
+	})
+	if err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+
+	result := svc.toDefinition(created)
+	result.Provenance = t.Provenance
+	return result, nil
+}
+
+// UpdateSilenceTemplate replaces the silence template with the same UID.
+// If the silences created from the template change, the silence created for the current occurrence is expired, and the scheduler creates a new one.
+func (svc *SilenceTemplateService) UpdateSilenceTemplate(ctx context.Context, orgID int64, t definitions.SilenceTemplate) (definitions.SilenceTemplate, error) {
+	existing, err := svc.getSilenceTemplate(ctx, orgID, t.UID)
+	if err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+
+	tmpl := fromSilenceTemplateDefinition(orgID, t)
+	tmpl.ID = existing.ID
+	tmpl.CreatedBy = existing.CreatedBy
+	if err := tmpl.Validate(); err != nil {
+		return definitions.SilenceTemplate{}, MakeErrSilenceTemplateInvalid(err)
+	}
+
+	if err := svc.checkProvenance(ctx, orgID, existing, models.Provenance(t.Provenance)); err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+	if err := svc.checkOptimisticConcurrency(existing, models.Provenance(t.Provenance), t.Version, "update"); err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+
+	changed := !sameSilences(existing, tmpl)
+	if !changed {
+		tmpl.LastOccurrence = existing.LastOccurrence
+		tmpl.SilenceID = existing.SilenceID
+	}
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateSilenceTemplate(ctx, tmpl); err != nil {
+			if errors.Is(err, models.ErrSilenceTemplateExists) {
+				return ErrSilenceTemplateExists.Errorf("")
+			}
+			if errors.Is(err, models.ErrSilenceTemplateNotFound) {
+				return ErrSilenceTemplateNotFound.Errorf("")
+			}
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &t, orgID, models.Provenance(t.Provenance))
+	})
+	if err != nil {
+		return definitions.SilenceTemplate{}, err
+	}
+
+	if changed && existing.SilenceID != "" {
+		svc.expireSilence(ctx, existing)
+	}
+
+	result := svc.toDefinition(tmpl)
+	result.Provenance = t.Provenance
+	return result, nil
+}
+
+// DeleteSilenceTemplate deletes the silence template with the given UID and expires the silence created for its current occurrence.
+func (svc *SilenceTemplateService) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string, provenance definitions.Provenance, version string) error {
+	existing, err := svc.store.GetSilenceTemplate(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrSilenceTemplateNotFound) {
+			svc.log.FromContext(ctx).Debug("Silence template was not found. Skip deleting", "uid", uid)
+			return nil
+		}
+		return err
+	}
+
+	if err := svc.checkProvenance(ctx, orgID, existing, models.Provenance(provenance)); err != nil {
+		return err
+	}
+	if err := svc.checkOptimisticConcurrency(existing, models.Provenance(provenance), version, "delete"); err != nil {
+		return err
+	}
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteSilenceTemplate(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.provenanceStore.DeleteProvenance(ctx, &definitions.SilenceTemplate{UID: uid}, orgID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if existing.SilenceID != "" {
+		svc.expireSilence(ctx, existing)
+	}
+	return nil
+}
+
+func (svc *SilenceTemplateService) getSilenceTemplate(ctx context.Context, orgID int64, uid string) (models.SilenceTemplate, error) {
+	t, err := svc.store.GetSilenceTemplate(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrSilenceTemplateNotFound) {
+			return models.SilenceTemplate{}, ErrSilenceTemplateNotFound.Errorf("")
+		}
+		return models.SilenceTemplate{}, err
+	}
+	return t, nil
+}
+
+// checkProvenance checks that the provenance of the template is not changed in an invalid way.
+func (svc *SilenceTemplateService) checkProvenance(ctx context.Context, orgID int64, existing models.SilenceTemplate, provenance models.Provenance) error {
+	storedProvenance, err := svc.provenanceStore.GetProvenance(ctx, &definitions.SilenceTemplate{UID: existing.UID}, orgID)
+	if err != nil {
+		return err
+	}
+	return svc.validator(storedProvenance, provenance)
+}
+
+func (svc *SilenceTemplateService) checkOptimisticConcurrency(current models.SilenceTemplate, provenance models.Provenance, desiredVersion string, action string) error {
+	if desiredVersion == "" {
+		if provenance != models.ProvenanceFile {
+			// if version is not specified and it's not a file provisioning, emit a log message to reflect that optimistic concurrency is disabled for this request
+			svc.log.Debug("ignoring optimistic concurrency check because version was not provided", "silenceTemplate", current.UID, "operation", action)
+		}
+		return nil
+	}
+	currentVersion := calculateSilenceTemplateFingerprint(current)
+	if currentVersion != desiredVersion {
+		return ErrVersionConflict.Errorf("provided version %s of silence template %s does not match current version %s", desiredVersion, current.UID, currentVersion)
+	}
+	return nil
+}
+
+// expireSilence expires the silence created for the latest occurrence of the template.
+// Failures are only logged because the silence expires on its own at the end of the occurrence.
+func (svc *SilenceTemplateService) expireSilence(ctx context.Context, t models.SilenceTemplate) {
+	if t.LastOccurrence.IsZero() || !t.LastOccurrence.Add(t.Duration).After(svc.now()) {
+		return
+	}
+	if err := svc.silences.DeleteSilence(ctx, t.OrgID, t.SilenceID); err != nil {
+		svc.log.FromContext(ctx).Warn("Failed to expire the silence of a silence template", "uid", t.UID, "silenceID", t.SilenceID, "error", err)
+	}
+}
+
+func (svc *SilenceTemplateService) toDefinition(t models.SilenceTemplate) definitions.SilenceTemplate {
+	result := definitions.SilenceTemplate{
+		UID:       t.UID,
+		Name:      t.Name,
+		Matchers:  t.Matchers,
+		Comment:   t.Comment,
+		Duration:  model.Duration(t.Duration),
+		Schedule:  t.Schedule,
+		Timezone:  t.Timezone,
+		CreatedBy: t.CreatedBy,
+		SilenceID: t.SilenceID,
+		Version:   calculateSilenceTemplateFingerprint(t),
+	}
+	if !t.LastOccurrence.IsZero() {
+		last := t.LastOccurrence
+		result.LastOccurrence = &last
+	}
+	if t.IsRecurring() {
+		if sched, err := t.ParsedSchedule(); err == nil {
+			if next := sched.Next(svc.now()); !next.IsZero() {
+				result.NextOccurrence = &next
+			}
+		}
+	}
+	return result
+}
+
+func fromSilenceTemplateDefinition(orgID int64, t definitions.SilenceTemplate) models.SilenceTemplate {
+	return models.SilenceTemplate{
+		UID:       t.UID,
+		OrgID:     orgID,
+		Name:      t.Name,
+		Matchers:  t.Matchers,
+		Comment:   t.Comment,
+		Duration:  time.Duration(t.Duration),
+		Schedule:  t.Schedule,
+		Timezone:  t.Timezone,
+		CreatedBy: t.CreatedBy,
+	}
+}
+
+// sameSilences returns true if both templates create the same silences.
+func sameSilences(a, b models.SilenceTemplate) bool {
+	return a.Schedule == b.Schedule && a.Timezone == b.Timezone && a.Duration == b.Duration && matchersKey(a) == matchersKey(b)
+}
+
+func matchersKey(t models.SilenceTemplate) string {
+	matchers, err := models.SilenceTemplateMatchers(t.Matchers)
+	if err != nil {
+		return ""
+	}
+	return matchers.String()
+}
+
+func calculateSilenceTemplateFingerprint(t models.SilenceTemplate) string {
+	sum := fnv.New64()
+
+	writeString := func(s string) {
+		_, _ = sum.Write([]byte(s))
+		// add a byte sequence that cannot happen in UTF-8 strings.
+		_, _ = sum.Write([]byte{255})
+	}
+	tmp := make([]byte, 8)
+	writeInt := func(i int64) {
+		binary.LittleEndian.PutUint64(tmp, uint64(i))
+		_, _ = sum.Write(tmp)
+	}
+
+	writeString(t.Name)
+	writeString(matchersKey(t))
+	writeString(t.Comment)
+	writeInt(int64(t.Duration))
+	writeString(t.Schedule)
+	writeString(t.Timezone)
+	writeString(t.CreatedBy)
+	return fmt.Sprintf("%016x", sum.Sum64())
+}
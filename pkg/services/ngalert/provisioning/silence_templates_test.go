@@ -0,0 +1,246 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type fakeSilenceTemplateStore struct {
+	templates map[string]models.SilenceTemplate
+}
+
+func (f *fakeSilenceTemplateStore) ListSilenceTemplates(_ context.Context, _ int64) ([]models.SilenceTemplate, error) {
+	result := make([]models.SilenceTemplate, 0, len(f.templates))
+	for _, t := range f.templates {
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+func (f *fakeSilenceTemplateStore) GetSilenceTemplate(_ context.Context, _ int64, uid string) (models.SilenceTemplate, error) {
+	t, ok := f.templates[uid]
+	if !ok {
+		return models.SilenceTemplate{}, models.ErrSilenceTemplateNotFound
+	}
+	return t, nil
+}
+
+func (f *fakeSilenceTemplateStore) InsertSilenceTemplate(_ context.Context, t models.SilenceTemplate) (models.SilenceTemplate, error) {
+	for _, existing := range f.templates {
+		if existing.Name == t.Name {
+			return models.SilenceTemplate{}, models.ErrSilenceTemplateExists
+		}
+	}
+	if t.UID == "" {
+		t.UID = util.GenerateShortUID()
+	}
+	f.templates[t.UID] = t
+	return t, nil
+}
+
+func (f *fakeSilenceTemplateStore) UpdateSilenceTemplate(_ context.Context, t models.SilenceTemplate) error {
+	if _, ok := f.templates[t.UID]; !ok {
+		return models.ErrSilenceTemplateNotFound
+	}
+	f.templates[t.UID] = t
+	return nil
+}
+
+func (f *fakeSilenceTemplateStore) DeleteSilenceTemplate(_ context.Context, _ int64, uid string) error {
+	delete(f.templates, uid)
+	return nil
+}
+
+type fakeSilenceExpirer struct {
+	expired []string
+}
+
+func (f *fakeSilenceExpirer) DeleteSilence(_ context.Context, _ int64, silenceID string) error {
+	f.expired = append(f.expired, silenceID)
+	return nil
+}
+
+func TestSilenceTemplateService(t *testing.T) {
+	orgID := int64(1)
+	now := time.Date(2024, 1, 6, 23, 0, 0, 0, time.UTC)
+	occurrence := time.Date(2024, 1, 6, 22, 0, 0, 0, time.UTC)
+
+	matchers := amv2.Matchers{{Name: util.Pointer("cluster"), Value: util.Pointer("eu"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)}}
+	active := models.SilenceTemplate{
+		UID:            "weekend",
+		OrgID:          orgID,
+		Name:           "weekend maintenance",
+		Matchers:       matchers,
+		Duration:       2 * time.Hour,
+		Schedule:       "0 22 * * 6",
+		CreatedBy:      "admin",
+		LastOccurrence: occurrence,
+		SilenceID:      "silence-1",
+	}
+
+	createSut := func() (*SilenceTemplateService, *fakeSilenceTemplateStore, *MockProvisioningStore, *fakeSilenceExpirer) {
+		store := &fakeSilenceTemplateStore{templates: map[string]models.SilenceTemplate{active.UID: active}}
+		prov := &MockProvisioningStore{}
+		silences := &fakeSilenceExpirer{}
+		return &SilenceTemplateService{
+			store:           store,
+			provenanceStore: prov,
+			xact:            newNopTransactionManager(),
+			silences:        silences,
+			log:             log.NewNopLogger(),
+			validator: func(from, to models.Provenance) error {
+				return nil
+			},
+			now: func() time.Time { return now },
+		}, store, prov, silences
+	}
+
+	t.Run("GetSilenceTemplate returns the next occurrence", func(t *testing.T) {
+		sut, _, prov, _ := createSut()
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(models.ProvenanceAPI, nil)
+
+		result, err := sut.GetSilenceTemplate(context.Background(), orgID, active.UID)
+		require.NoError(t, err)
+		require.Equal(t, definitions.Provenance(models.ProvenanceAPI), result.Provenance)
+		require.Equal(t, calculateSilenceTemplateFingerprint(active), result.Version)
+		require.Equal(t, occurrence, *result.LastOccurrence)
+		require.Equal(t, occurrence.Add(7*24*time.Hour), *result.NextOccurrence)
+		require.Equal(t, "silence-1", result.SilenceID)
+	})
+
+	t.Run("GetSilenceTemplate returns ErrSilenceTemplateNotFound", func(t *testing.T) {
+		sut, _, _, _ := createSut()
+		_, err := sut.GetSilenceTemplate(context.Background(), orgID, "unknown")
+		require.ErrorIs(t, err, ErrSilenceTemplateNotFound)
+	})
+
+	t.Run("CreateSilenceTemplate", func(t *testing.T) {
+		t.Run("rejects invalid templates", func(t *testing.T) {
+			sut, _, _, _ := createSut()
+			_, err := sut.CreateSilenceTemplate(context.Background(), orgID, definitions.SilenceTemplate{
+				Name:     "nightly",
+				Matchers: matchers,
+				Duration: model.Duration(time.Hour),
+				Schedule: "every night",
+			})
+			require.ErrorIs(t, err, ErrSilenceTemplateInvalid)
+		})
+
+		t.Run("rejects duplicate names", func(t *testing.T) {
+			sut, _, _, _ := createSut()
+			_, err := sut.CreateSilenceTemplate(context.Background(), orgID, definitions.SilenceTemplate{
+				Name:     active.Name,
+				Matchers: matchers,
+				Duration: model.Duration(time.Hour),
+			})
+			require.ErrorIs(t, err, ErrSilenceTemplateExists)
+		})
+
+		t.Run("saves the template and its provenance", func(t *testing.T) {
+			sut, store, prov, _ := createSut()
+			prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceFile).Return(nil)
+
+			result, err := sut.CreateSilenceTemplate(context.Background(), orgID, definitions.SilenceTemplate{
+				Name:       "nightly",
+				Matchers:   matchers,
+				Duration:   model.Duration(time.Hour),
+				Schedule:   "RRULE:FREQ=DAILY;BYHOUR=2",
+				Timezone:   "Europe/Berlin",
+				Provenance: definitions.Provenance(models.ProvenanceFile),
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, result.UID)
+			require.Contains(t, store.templates, result.UID)
+			require.Equal(t, definitions.Provenance(models.ProvenanceFile), result.Provenance)
+			prov.AssertExpectations(t)
+		})
+	})
+
+	t.Run("UpdateSilenceTemplate", func(t *testing.T) {
+		t.Run("returns ErrVersionConflict if version does not match", func(t *testing.T) {
+			sut, _, prov, _ := createSut()
+			prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(models.ProvenanceNone, nil)
+
+			_, err := sut.UpdateSilenceTemplate(context.Background(), orgID, definitions.SilenceTemplate{
+				UID:      active.UID,
+				Name:     active.Name,
+				Matchers: matchers,
+				Duration: model.Duration(time.Hour),
+				Version:  "wrong",
+			})
+			require.ErrorIs(t, err, ErrVersionConflict)
+		})
+
+		t.Run("keeps the active silence if silences do not change", func(t *testing.T) {
+			sut, store, prov, silences := createSut()
+			prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(models.ProvenanceNone, nil)
+			prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceNone).Return(nil)
+
+			_, err := sut.UpdateSilenceTemplate(context.Background(), orgID, definitions.SilenceTemplate{
+				UID:       active.UID,
+				Name:      "renamed",
+				Matchers:  matchers,
+				Comment:   "new comment",
+				Duration:  model.Duration(active.Duration),
+				Schedule:  active.Schedule,
+				CreatedBy: "someone-else",
+				Version:   calculateSilenceTemplateFingerprint(active),
+			})
+			require.NoError(t, err)
+			require.Empty(t, silences.expired)
+			updated := store.templates[active.UID]
+			require.Equal(t, "renamed", updated.Name)
+			require.Equal(t, "admin", updated.CreatedBy)
+			require.Equal(t, occurrence, updated.LastOccurrence)
+			require.Equal(t, "silence-1", updated.SilenceID)
+		})
+
+		t.Run("expires the active silence if the schedule changes", func(t *testing.T) {
+			sut, store, prov, silences := createSut()
+			prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(models.ProvenanceNone, nil)
+			prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceNone).Return(nil)
+
+			_, err := sut.UpdateSilenceTemplate(context.Background(), orgID, definitions.SilenceTemplate{
+				UID:      active.UID,
+				Name:     active.Name,
+				Matchers: matchers,
+				Duration: model.Duration(active.Duration),
+				Schedule: "0 21 * * 6",
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{"silence-1"}, silences.expired)
+			updated := store.templates[active.UID]
+			require.True(t, updated.LastOccurrence.IsZero())
+			require.Empty(t, updated.SilenceID)
+		})
+	})
+
+	t.Run("DeleteSilenceTemplate", func(t *testing.T) {
+		t.Run("deletes the template and expires the active silence", func(t *testing.T) {
+			sut, store, prov, silences := createSut()
+			prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(models.ProvenanceNone, nil)
+			prov.EXPECT().DeleteProvenance(mock.Anything, mock.Anything, orgID).Return(nil)
+
+			err := sut.DeleteSilenceTemplate(context.Background(), orgID, active.UID, definitions.Provenance(models.ProvenanceNone), "")
+			require.NoError(t, err)
+			require.NotContains(t, store.templates, active.UID)
+			require.Equal(t, []string{"silence-1"}, silences.expired)
+		})
+
+		t.Run("does not fail if template does not exist", func(t *testing.T) {
+			sut, _, _, _ := createSut()
+			require.NoError(t, sut.DeleteSilenceTemplate(context.Background(), orgID, "unknown", definitions.Provenance(models.ProvenanceNone), ""))
+		})
+	})
+}
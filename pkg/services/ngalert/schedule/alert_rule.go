@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/prometheus/alertmanager/api/v2/models"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -61,6 +62,7 @@ func newRuleFactory(
 	evalFactory eval.EvaluatorFactory,
 	clock clock.Clock,
 	rrCfg setting.RecordingRuleSettings,
+	budgetCfg setting.UnifiedAlertingQueryBudgetSettings,
 	met *metrics.Scheduler,
 	logger log.Logger,
 	tracer tracing.Tracer,
@@ -77,6 +79,7 @@ func newRuleFactory(
 				clock,
 				evalFactory,
 				rrCfg,
+				budgetCfg,
 				logger,
 				met,
 				tracer,
@@ -95,6 +98,7 @@ func newRuleFactory(
 			stateManager,
 			evalFactory,
 			clock,
+			budgetCfg,
 			met,
 			logger,
 			tracer,
@@ -123,6 +127,7 @@ type alertRule struct {
 	sender       AlertsSender
 	stateManager *state.Manager
 	evalFactory  eval.EvaluatorFactory
+	budget       *queryBudget
 
 	// Event hooks that are only used in tests.
 	evalAppliedHook evalAppliedFunc
@@ -143,6 +148,7 @@ func newAlertRule(
 	stateManager *state.Manager,
 	evalFactory eval.EvaluatorFactory,
 	clock clock.Clock,
+	budgetCfg setting.UnifiedAlertingQueryBudgetSettings,
 	met *metrics.Scheduler,
 	logger log.Logger,
 	tracer tracing.Tracer,
@@ -163,6 +169,7 @@ func newAlertRule(
 		sender:               sender,
 		stateManager:         stateManager,
 		evalFactory:          evalFactory,
+		budget:               newQueryBudget(budgetCfg),
 		evalAppliedHook:      evalAppliedHook,
 		stopAppliedHook:      stopAppliedHook,
 		metrics:              met,
@@ -180,7 +187,7 @@ func (a *alertRule) Type() ngmodels.RuleType {
 }
 
 func (a *alertRule) Status() ngmodels.RuleStatus {
-	return a.stateManager.GetStatusForRuleUID(a.key.OrgID, a.key.UID)
+	return a.budget.applyTo(a.stateManager.GetStatusForRuleUID(a.key.OrgID, a.key.UID))
 }
 
 // eval signals the rule evaluation routine to perform the evaluation of the rule. Does nothing if the loop is stopped.
@@ -274,6 +281,9 @@ func (a *alertRule) Run() error {
 				defer func() {
 					evalDuration.Observe(a.clock.Now().Sub(evalStart).Seconds())
 					a.evalApplied(ctx.scheduledAt)
+					if ctx.afterEval != nil {
+						ctx.afterEval()
+					}
 				}()
 
 				for attempt := int64(1); attempt <= a.maxAttempts; attempt++ {
@@ -297,6 +307,14 @@ func (a *alertRule) Run() error {
 						logger.Debug("Skip rule evaluation because it is paused")
 						return
 					}
+					if !a.budget.allow(f, a.clock.Now()) {
+						logger.Debug("Skip rule evaluation because it exceeded the query budget")
+						// Firing alerts are sent again, as the Alertmanager resolves them when they are not.
+						a.stateManager.KeepStatesForRule(ngmodels.WithRuleKey(grafanaCtx, a.key.AlertRuleKey), ctx.scheduledAt, ctx.rule, ngmodels.StateReasonQueryBudgetExceeded, func(ctx context.Context, statesToSend state.StateTransitions) {
+							a.send(ctx, logger, statesToSend)
+						})
+						return
+					}
 
 					// Only increment evaluation counter once, not per-retry.
 					if attempt == 1 {
@@ -378,17 +396,23 @@ func (a *alertRule) evaluate(ctx context.Context, e *Evaluation, span trace.Span
 	start := a.clock.Now()
 
 	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule))
-	ruleEval, err := a.evalFactory.Create(evalCtx, e.rule.GetEvalCondition().WithSource("scheduler").WithFolder(e.folderTitle))
+	condition := e.rule.GetEvalCondition().WithSource("scheduler").WithFolder(e.folderTitle)
+	ruleEval, err := a.evalFactory.Create(evalCtx, condition)
 	var results eval.Results
 	var dur time.Duration
 	if err != nil {
 		dur = a.clock.Now().Sub(start)
 		logger.Error("Failed to build rule evaluator", "error", err)
 	} else {
-		results, err = ruleEval.Evaluate(ctx, e.scheduledAt)
+		var resp *backend.QueryDataResponse
+		costCtx, costs := eval.WithQueryCostCollector(ctx)
+		resp, err = ruleEval.EvaluateRaw(costCtx, e.scheduledAt)
 		dur = a.clock.Now().Sub(start)
+		recordQueryCost(a.budget, a.metrics, e, costs.Cost(), a.clock.Now(), logger)
 		if err != nil {
 			logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
+		} else {
+			results = eval.EvaluateAlert(resp, condition, e.scheduledAt)
 		}
 	}
 
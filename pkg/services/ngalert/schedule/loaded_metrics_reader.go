@@ -42,3 +42,28 @@ func (n AlertingResultsFromRuleState) Read() map[data.Fingerprint]struct{} {
 	}
 	return active
 }
+
+// ReadLevels returns the level of the results that are in Alerting and Pending states that have empty StateReason.
+// The level is the value of the condition in the latest evaluation. If it is not known, for example because the state
+// was restored after a restart, the least severe level is assumed.
+func (n AlertingResultsFromRuleState) ReadLevels() map[data.Fingerprint]int {
+	states := n.Manager.GetStatesForRuleUID(n.Rule.OrgID, n.Rule.UID)
+
+	levels := map[data.Fingerprint]int{}
+	for _, st := range states {
+		if st.StateReason != "" {
+			continue
+		}
+		if st.State != eval.Alerting && st.State != eval.Pending {
+			continue
+		}
+		level := 1
+		if st.LatestResult != nil {
+			if v, ok := st.LatestResult.Values[n.Rule.Condition]; ok && v >= 1 {
+				level = int(v)
+			}
+		}
+		levels[st.ResultFingerprint] = level
+	}
+	return levels
+}
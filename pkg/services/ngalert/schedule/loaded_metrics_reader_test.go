@@ -53,6 +53,33 @@ func TestLoadedResultsFromRuleState(t *testing.T) {
 	})
 }
 
+func TestLoadedLevelsFromRuleState(t *testing.T) {
+	rule := ngmodels.RuleGen.GenerateRef()
+	p := &FakeRuleStateProvider{
+		map[ngmodels.AlertRuleKey][]*state.State{
+			rule.GetKey(): {
+				{State: eval.Alerting, ResultFingerprint: data.Fingerprint(1), LatestResult: &state.Evaluation{Values: map[string]float64{rule.Condition: 2}}},
+				{State: eval.Pending, ResultFingerprint: data.Fingerprint(2), LatestResult: &state.Evaluation{Values: map[string]float64{rule.Condition: 1}}},
+				{State: eval.Alerting, ResultFingerprint: data.Fingerprint(3)},
+				{State: eval.Normal, ResultFingerprint: data.Fingerprint(4), LatestResult: &state.Evaluation{Values: map[string]float64{rule.Condition: 0}}},
+				{State: eval.Alerting, ResultFingerprint: data.Fingerprint(5), StateReason: uuid.NewString()},
+			},
+		},
+	}
+
+	reader := AlertingResultsFromRuleState{
+		Manager: p,
+		Rule:    rule,
+	}
+
+	loaded := reader.ReadLevels()
+	require.Equal(t, map[data.Fingerprint]int{
+		1: 2,
+		2: 1,
+		3: 1, // level is unknown, the least severe level is assumed.
+	}, loaded)
+}
+
 type FakeRuleStateProvider struct {
 	states map[ngmodels.AlertRuleKey][]*state.State
 }
@@ -0,0 +1,146 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var errQueryBudgetExceeded = errors.New("query budget exceeded")
+
+// queryBudget keeps track of the query cost of a rule and decides whether the rule can be evaluated.
+// It is kept in memory with the routine of the rule, so the accounting starts over when the routine is restarted,
+// e.g. when Grafana restarts or the rule is assigned to another instance.
+type queryBudget struct {
+	cfg setting.UnifiedAlertingQueryBudgetSettings
+
+	mtx        sync.Mutex
+	cost       ngmodels.QueryCost
+	violations int
+	// throttledUntil is the time before which the rule is not evaluated.
+	throttledUntil time.Time
+	// pausedFingerprint is the fingerprint of the rule version that is paused. Zero if the rule is not paused.
+	pausedFingerprint fingerprint
+	lastErr           error
+}
+
+func newQueryBudget(cfg setting.UnifiedAlertingQueryBudgetSettings) *queryBudget {
+	return &queryBudget{cfg: cfg}
+}
+
+// allow returns false if the evaluation of the rule with the given fingerprint must be skipped.
+// A paused rule is evaluated again when its fingerprint changes, i.e. the rule is updated.
+func (b *queryBudget) allow(fp fingerprint, now time.Time) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.pausedFingerprint != 0 {
+		if b.pausedFingerprint == fp {
+			return false
+		}
+		b.reset()
+	}
+	return !now.Before(b.throttledUntil)
+}
+
+// record stores the cost of the latest evaluation and checks it against the budget.
+// It returns true if the cost exceeds the budget, and the action that was taken, if any.
+func (b *queryBudget) record(cost ngmodels.QueryCost, fp fingerprint, now time.Time) (bool, string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.cost = cost
+
+	reasons := b.violationReasons(cost)
+	if len(reasons) == 0 {
+		b.reset()
+		return false, ""
+	}
+
+	b.violations++
+	if b.violations < b.cfg.ConsecutiveViolations {
+		return true, ""
+	}
+	b.violations = 0
+
+	reason := strings.Join(reasons, ", ")
+	switch b.cfg.Action {
+	case setting.QueryBudgetActionPause:
+		b.pausedFingerprint = fp
+		b.lastErr = fmt.Errorf("%w: %s; evaluation is paused until the rule is updated", errQueryBudgetExceeded, reason)
+	default:
+		b.throttledUntil = now.Add(b.cfg.ThrottleInterval)
+		b.lastErr = fmt.Errorf("%w: %s; evaluation is throttled until %s", errQueryBudgetExceeded, reason, b.throttledUntil.UTC().Format(time.RFC3339))
+	}
+	return true, b.cfg.Action
+}
+
+// status returns the cost of the latest evaluation, and the error if the rule is throttled or paused.
+func (b *queryBudget) status() (ngmodels.QueryCost, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.cost, b.lastErr
+}
+
+// applyTo adds the cost of the latest evaluation to the status of the rule.
+// If the rule is throttled or paused, the status reports an error with the reason.
+func (b *queryBudget) applyTo(status ngmodels.RuleStatus) ngmodels.RuleStatus {
+	cost, err := b.status()
+	status.QueryCost = cost
+	if err != nil {
+		status.Health = "error"
+		status.LastError = err
+	}
+	return status
+}
+
+func (b *queryBudget) reset() {
+	b.violations = 0
+	b.throttledUntil = time.Time{}
+	b.pausedFingerprint = 0
+	b.lastErr = nil
+}
+
+func (b *queryBudget) violationReasons(cost ngmodels.QueryCost) []string {
+	var reasons []string
+	if b.cfg.MaxQueryDuration > 0 && cost.Duration > b.cfg.MaxQueryDuration {
+		reasons = append(reasons, fmt.Sprintf("queries took %s (limit %s)", cost.Duration, b.cfg.MaxQueryDuration))
+	}
+	if b.cfg.MaxSeries > 0 && cost.Series > b.cfg.MaxSeries {
+		reasons = append(reasons, fmt.Sprintf("queries returned %d series (limit %d)", cost.Series, b.cfg.MaxSeries))
+	}
+	if b.cfg.MaxFrames > 0 && cost.Frames > b.cfg.MaxFrames {
+		reasons = append(reasons, fmt.Sprintf("queries returned %d frames (limit %d)", cost.Frames, b.cfg.MaxFrames))
+	}
+	if b.cfg.MaxBytes > 0 && cost.Bytes > b.cfg.MaxBytes {
+		reasons = append(reasons, fmt.Sprintf("queries returned %d bytes (limit %d)", cost.Bytes, b.cfg.MaxBytes))
+	}
+	return reasons
+}
+
+// recordQueryCost checks the cost of the evaluation against the budget of the rule and updates the metrics of the folder.
+func recordQueryCost(b *queryBudget, met *metrics.Scheduler, ev *Evaluation, cost ngmodels.QueryCost, now time.Time, logger log.Logger) {
+	orgID := fmt.Sprint(ev.rule.OrgID)
+	met.QueryDuration.WithLabelValues(orgID, ev.rule.NamespaceUID).Add(cost.Duration.Seconds())
+	met.QuerySeries.WithLabelValues(orgID, ev.rule.NamespaceUID).Add(float64(cost.Series))
+	met.QueryFrames.WithLabelValues(orgID, ev.rule.NamespaceUID).Add(float64(cost.Frames))
+	met.QueryBytes.WithLabelValues(orgID, ev.rule.NamespaceUID).Add(float64(cost.Bytes))
+
+	exceeded, action := b.record(cost, ev.Fingerprint(), now)
+	if !exceeded {
+		return
+	}
+	if action == "" {
+		met.QueryBudgetExceeded.WithLabelValues(orgID, ev.rule.NamespaceUID, "none").Inc()
+		logger.Debug("Rule evaluation exceeded the query budget", "duration", cost.Duration, "series", cost.Series, "frames", cost.Frames, "bytes", cost.Bytes)
+		return
+	}
+	met.QueryBudgetExceeded.WithLabelValues(orgID, ev.rule.NamespaceUID, action).Inc()
+	_, err := b.status()
+	logger.Warn("Rule exceeded the query budget", "action", action, "error", err)
+}
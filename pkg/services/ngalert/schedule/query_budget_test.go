@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryBudget(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	withinBudget := models.QueryCost{Duration: time.Second, Series: 10, Frames: 1, Bytes: 100}
+	overBudget := models.QueryCost{Duration: time.Second, Series: 20, Frames: 1, Bytes: 100}
+	const fp = fingerprint(1)
+
+	t.Run("should not enforce anything if limits are not set", func(t *testing.T) {
+		b := newQueryBudget(setting.UnifiedAlertingQueryBudgetSettings{Action: setting.QueryBudgetActionPause, ConsecutiveViolations: 1})
+		exceeded, action := b.record(overBudget, fp, now)
+		require.False(t, exceeded)
+		require.Empty(t, action)
+		require.True(t, b.allow(fp, now))
+
+		cost, err := b.status()
+		require.NoError(t, err)
+		require.Equal(t, overBudget, cost)
+	})
+
+	t.Run("should throttle rule", func(t *testing.T) {
+		b := newQueryBudget(setting.UnifiedAlertingQueryBudgetSettings{
+			MaxSeries:             10,
+			Action:                setting.QueryBudgetActionThrottle,
+			ConsecutiveViolations: 2,
+			ThrottleInterval:      time.Minute,
+		})
+
+		exceeded, action := b.record(overBudget, fp, now)
+		require.True(t, exceeded)
+		require.Empty(t, action)
+		require.True(t, b.allow(fp, now))
+
+		exceeded, action = b.record(overBudget, fp, now)
+		require.True(t, exceeded)
+		require.Equal(t, setting.QueryBudgetActionThrottle, action)
+		require.False(t, b.allow(fp, now))
+		require.False(t, b.allow(fp+1, now.Add(30*time.Second)))
+		require.True(t, b.allow(fp, now.Add(time.Minute)))
+
+		status := b.applyTo(models.RuleStatus{Health: "ok"})
+		require.Equal(t, "error", status.Health)
+		require.ErrorIs(t, status.LastError, errQueryBudgetExceeded)
+		require.ErrorContains(t, status.LastError, "queries returned 20 series (limit 10)")
+		require.Equal(t, overBudget, status.QueryCost)
+
+		exceeded, _ = b.record(withinBudget, fp, now.Add(time.Minute))
+		require.False(t, exceeded)
+		require.Equal(t, "ok", b.applyTo(models.RuleStatus{Health: "ok"}).Health)
+	})
+
+	t.Run("should pause rule until it is updated", func(t *testing.T) {
+		b := newQueryBudget(setting.UnifiedAlertingQueryBudgetSettings{
+			MaxQueryDuration:      500 * time.Millisecond,
+			Action:                setting.QueryBudgetActionPause,
+			ConsecutiveViolations: 1,
+		})
+
+		exceeded, action := b.record(withinBudget, fp, now)
+		require.True(t, exceeded)
+		require.Equal(t, setting.QueryBudgetActionPause, action)
+		require.False(t, b.allow(fp, now.Add(24*time.Hour)))
+		_, err := b.status()
+		require.ErrorContains(t, err, "paused until the rule is updated")
+
+		require.True(t, b.allow(fp+1, now))
+		_, err = b.status()
+		require.NoError(t, err)
+	})
+}
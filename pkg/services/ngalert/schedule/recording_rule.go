@@ -45,6 +45,7 @@ type recordingRule struct {
 	clock       clock.Clock
 	evalFactory eval.EvaluatorFactory
 	cfg         setting.RecordingRuleSettings
+	budget      *queryBudget
 	writer      RecordingWriter
 
 	// Event hooks that are only used in tests.
@@ -56,7 +57,7 @@ type recordingRule struct {
 	tracer  tracing.Tracer
 }
 
-func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKeyWithGroup, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, cfg setting.RecordingRuleSettings, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, writer RecordingWriter, evalAppliedHook evalAppliedFunc, stopAppliedHook stopAppliedFunc) *recordingRule {
+func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKeyWithGroup, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, cfg setting.RecordingRuleSettings, budgetCfg setting.UnifiedAlertingQueryBudgetSettings, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, writer RecordingWriter, evalAppliedHook evalAppliedFunc, stopAppliedHook stopAppliedFunc) *recordingRule {
 	ctx, stop := util.WithCancelCause(ngmodels.WithRuleKey(parent, key.AlertRuleKey))
 	return &recordingRule{
 		key:                 key,
@@ -70,6 +71,7 @@ func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKeyWithGroup
 		clock:               clock,
 		evalFactory:         evalFactory,
 		cfg:                 cfg,
+		budget:              newQueryBudget(budgetCfg),
 		maxAttempts:         maxAttempts,
 		evalAppliedHook:     evalAppliedHook,
 		stopAppliedHook:     stopAppliedHook,
@@ -89,12 +91,12 @@ func (r *recordingRule) Type() ngmodels.RuleType {
 }
 
 func (r *recordingRule) Status() ngmodels.RuleStatus {
-	return ngmodels.RuleStatus{
+	return r.budget.applyTo(ngmodels.RuleStatus{
 		Health:              r.health.Load(),
 		LastError:           r.lastError.Load(),
 		EvaluationTimestamp: r.evaluationTimestamp.Load(),
 		EvaluationDuration:  r.evaluationDuration.Load(),
-	}
+	})
 }
 
 func (r *recordingRule) Eval(eval *Evaluation) (bool, *Evaluation) {
@@ -138,6 +140,9 @@ func (r *recordingRule) Run() error {
 			}
 			if !r.cfg.Enabled {
 				r.logger.Warn("Recording rule scheduled but subsystem is not enabled. Skipping")
+				if eval.afterEval != nil {
+					eval.afterEval()
+				}
 				return nil
 			}
 			// TODO: Skipping the "evalRunning" guard that the alert rule routine does, because it seems to be dead code and impossible to hit.
@@ -170,12 +175,19 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 		r.evaluationDuration.Store(dur)
 
 		r.evaluationDoneTestHook(ev)
+		if ev.afterEval != nil {
+			ev.afterEval()
+		}
 	}()
 
 	if ev.rule.IsPaused {
 		logger.Debug("Skip recording rule evaluation because it is paused")
 		return
 	}
+	if !r.budget.allow(ev.Fingerprint(), r.clock.Now()) {
+		logger.Debug("Skip recording rule evaluation because it exceeded the query budget")
+		return
+	}
 
 	ctx, span := r.tracer.Start(ctx, "recording rule execution", trace.WithAttributes(
 		attribute.String("rule_uid", ev.rule.UID),
@@ -278,6 +290,16 @@ func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logge
 		return fmt.Errorf("remote write failed: %w", err)
 	}
 
+	// Rules that are evaluated after this one in a sequence must see the written metrics, so do not leave them in a buffer.
+	if f, ok := r.writer.(flusher); ok && ev.afterEval != nil {
+		if err := f.Flush(ctx); err != nil {
+			span.SetStatus(codes.Error, "failed to write metrics")
+			span.RecordError(err)
+			return fmt.Errorf("remote write failed: %w", err)
+		}
+		writeDur = r.clock.Now().Sub(writeStart)
+	}
+
 	logger.Debug("Metrics written", "duration", writeDur)
 	span.AddEvent("metrics written", trace.WithAttributes(
 		attribute.Int64("frames", int64(len(frames))),
@@ -293,9 +315,12 @@ func (r *recordingRule) buildAndExecutePipeline(ctx context.Context, evalCtx eva
 		logger.Error("Failed to build rule evaluator", "error", err)
 		return nil, err
 	}
-	results, err := evaluator.EvaluateRaw(ctx, ev.scheduledAt)
+	costCtx, costs := eval.WithQueryCostCollector(ctx)
+	results, err := evaluator.EvaluateRaw(costCtx, ev.scheduledAt)
+	dur := r.clock.Now().Sub(start)
+	recordQueryCost(r.budget, r.metrics, ev, costs.Cost(), r.clock.Now(), logger)
 	if err != nil {
-		logger.Error("Failed to evaluate rule", "error", err, "duration", r.clock.Now().Sub(start))
+		logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
 	}
 	return results, err
 }
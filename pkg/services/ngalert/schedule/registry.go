@@ -91,6 +91,9 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called by the rule routine when it completes the evaluation.
+	// It is used to start the next evaluation of rule groups that are evaluated sequentially.
+	afterEval func()
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
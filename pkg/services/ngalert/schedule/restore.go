@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// RestoreReader provides the latest restore of the alert state of every organization.
+type RestoreReader interface {
+	Latest(ctx context.Context) (map[int64]state.Restore, error)
+}
+
+// loadRestoredState loads the state of the rules that was restored from a snapshot since the previous tick.
+// The snapshot is restored by one instance, so every instance loads the state of the restored rules it evaluates.
+func (sch *schedule) loadRestoredState(ctx context.Context, rules []*ngmodels.AlertRule) {
+	if sch.restores == nil || sch.instanceReader == nil {
+		return
+	}
+	restores, err := sch.restores.Latest(ctx)
+	if err != nil {
+		sch.log.FromContext(ctx).Error("Failed to get the restored alert state", "error", err)
+		return
+	}
+	if sch.appliedRestores == nil {
+		// The state of all rules is loaded on startup, including the restored ones.
+		sch.appliedRestores = make(map[int64]string, len(restores))
+		for orgID, r := range restores {
+			sch.appliedRestores[orgID] = r.ID
+		}
+		return
+	}
+
+	restored := make(map[ngmodels.AlertRuleKey]struct{})
+	for orgID, r := range restores {
+		if sch.appliedRestores[orgID] == r.ID {
+			continue
+		}
+		sch.log.FromContext(ctx).Info("Loading the restored alert state", "org_id", orgID, "restore", r.ID)
+		for _, uid := range r.RuleUIDs {
+			restored[ngmodels.AlertRuleKey{OrgID: orgID, UID: uid}] = struct{}{}
+		}
+		sch.appliedRestores[orgID] = r.ID
+	}
+	if len(restored) == 0 {
+		return
+	}
+	for _, rule := range rules {
+		key := rule.GetKey()
+		if _, ok := restored[key]; !ok {
+			continue
+		}
+		if err := sch.stateManager.WarmRule(ctx, rule, sch.instanceReader); err != nil {
+			sch.log.FromContext(ctx).Error("Failed to load the restored state of the rule", append(key.LogContext(), "error", err)...)
+		}
+	}
+}
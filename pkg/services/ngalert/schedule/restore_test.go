@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type fakeRestoreReader struct {
+	restores map[int64]state.Restore
+}
+
+func (f *fakeRestoreReader) Latest(context.Context) (map[int64]state.Restore, error) {
+	return f.restores, nil
+}
+
+func TestSchedule_loadRestoredState(t *testing.T) {
+	ctx := context.Background()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, newFakeRulesStore(), instanceStore, nil, nil, nil, nil)
+	sch.instanceReader = instanceStore
+	rules := models.RuleGen.With(models.RuleGen.WithOrgID(1)).GenerateManyRef(3)
+	restores := &fakeRestoreReader{restores: map[int64]state.Restore{
+		1: {ID: "first", RuleUIDs: []string{rules[0].UID}},
+	}}
+	sch.restores = restores
+
+	sch.loadRestoredState(ctx, rules)
+	require.Empty(t, instanceStore.RecordedOps(), "the state restored before startup should not be loaded again")
+
+	sch.loadRestoredState(ctx, rules)
+	require.Empty(t, instanceStore.RecordedOps())
+
+	t.Run("should load state of the rules restored by any instance", func(t *testing.T) {
+		restores.restores = map[int64]state.Restore{
+			1: {ID: "second", RuleUIDs: []string{rules[1].UID, rules[2].UID, "not-evaluated"}},
+		}
+
+		sch.loadRestoredState(ctx, rules)
+
+		ops := instanceStore.RecordedOps()
+		require.Len(t, ops, 2)
+		for i, op := range ops {
+			q, ok := op.(models.ListAlertInstancesQuery)
+			require.True(t, ok)
+			require.Equal(t, rules[i+1].UID, q.RuleUID)
+		}
+
+		sch.loadRestoredState(ctx, rules)
+		require.Len(t, instanceStore.RecordedOps(), 2)
+	})
+}
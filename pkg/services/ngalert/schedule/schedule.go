@@ -2,7 +2,6 @@ package schedule
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"slices"
 	"strings"
@@ -52,6 +51,11 @@ type RecordingWriter interface {
 	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
 }
 
+// flusher is implemented by recording writers that buffer writes.
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
 // AlertRuleStopReasonProvider is an interface for determining the reason why an alert rule was stopped.
 type AlertRuleStopReasonProvider interface {
 	// FindReason returns two values:
@@ -96,6 +100,7 @@ type schedule struct {
 	disableGrafanaFolder bool
 	jitterEvaluations    JitterStrategy
 	rrCfg                setting.RecordingRuleSettings
+	budgetCfg            setting.UnifiedAlertingQueryBudgetSettings
 
 	metrics *metrics.Scheduler
 
@@ -111,6 +116,17 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// sharder is used to split the evaluation of rules across the instances of the cluster.
+	// If nil, all rules are evaluated by this instance.
+	sharder *ruleSharder
+	// notOwnedRules contains the rules that were evaluated by other instances at the previous tick.
+	notOwnedRules  map[ngmodels.AlertRuleKey]struct{}
+	instanceReader state.InstanceReader
+
+	restores RestoreReader
+	// appliedRestores contains the ID of the latest restore of each organization whose state was loaded.
+	appliedRestores map[int64]string
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -121,6 +137,7 @@ type SchedulerCfg struct {
 	MinRuleInterval        time.Duration
 	DisableGrafanaFolder   bool
 	RecordingRulesCfg      setting.RecordingRuleSettings
+	QueryBudgetCfg         setting.UnifiedAlertingQueryBudgetSettings
 	AppURL                 *url.URL
 	JitterEvaluations      JitterStrategy
 	EvaluatorFactory       eval.EvaluatorFactory
@@ -131,6 +148,13 @@ type SchedulerCfg struct {
 	Log                    log.Logger
 	RecordingWriter        RecordingWriter
 	RuleStopReasonProvider AlertRuleStopReasonProvider
+	// ClusterMembership, if set, enables sharding of rule evaluation across the members of the cluster.
+	ClusterMembership ClusterMembership
+	// InstanceReader is used to load the state of rules taken over from other members of the cluster,
+	// or restored from a snapshot.
+	InstanceReader state.InstanceReader
+	// Restores, if set, is polled for the rules whose state was restored from a snapshot by any member of the cluster.
+	Restores RestoreReader
 }
 
 // NewScheduler returns a new scheduler.
@@ -154,6 +178,7 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		disableGrafanaFolder:   cfg.DisableGrafanaFolder,
 		jitterEvaluations:      cfg.JitterEvaluations,
 		rrCfg:                  cfg.RecordingRulesCfg,
+		budgetCfg:              cfg.QueryBudgetCfg,
 		stateManager:           stateManager,
 		minRuleInterval:        cfg.MinRuleInterval,
 		schedulableAlertRules:  alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
@@ -161,6 +186,11 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		tracer:                 cfg.Tracer,
 		recordingWriter:        cfg.RecordingWriter,
 		ruleStopReasonProvider: cfg.RuleStopReasonProvider,
+		instanceReader:         cfg.InstanceReader,
+		restores:               cfg.Restores,
+	}
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership)
 	}
 
 	return &sch
@@ -282,6 +312,8 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	// this is the new current state. rulesDiff contains the previously existing rules that were different between this state and the previous state.
 	alertRules, folderTitles := sch.schedulableAlertRules.all()
+	alertRules = sch.shardRules(ctx, alertRules)
+	sch.loadRestoredState(ctx, alertRules)
 
 	// registeredDefinitions is a map used for finding deleted alert rules
 	// initially it is assigned to all known alert rules from the previous cycle
@@ -304,6 +336,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.evaluatorFactory,
 		sch.clock,
 		sch.rrCfg,
+		sch.budgetCfg,
 		sch.metrics,
 		sch.log,
 		sch.tracer,
@@ -346,7 +379,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-		offset := jitterOffsetInTicks(item, sch.baseInterval, sch.jitterEvaluations)
+		jitterStrategy := sch.jitterEvaluations
+		if item.Metadata.SequentialEvaluation && jitterStrategy == JitterByRule {
+			// All rules of a sequentially evaluated group must be ready to run on the same tick.
+			jitterStrategy = JitterByGroup
+		}
+		offset := jitterOffsetInTicks(item, sch.baseInterval, jitterStrategy)
 		isReadyToRun := item.IntervalSeconds != 0 && (tickNum%itemFrequency)-offset == 0
 
 		var folderTitle string
@@ -390,29 +428,26 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
-	var step int64 = 0
-	if len(readyToRun) > 0 {
-		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
-	}
-
 	slices.SortFunc(readyToRun, func(a, b readyToRunItem) int {
 		return strings.Compare(a.rule.UID, b.rule.UID)
 	})
-	for i := range readyToRun {
-		item := readyToRun[i]
+
+	// Rules of sequentially evaluated groups are sent one after another, starting from the first rule of each group.
+	toSend := sch.sequenceEvaluations(readyToRun)
+	slices.SortFunc(toSend, func(a, b readyToRunItem) int {
+		return strings.Compare(a.rule.UID, b.rule.UID)
+	})
+
+	var step int64 = 0
+	if len(toSend) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(toSend))
+	}
+
+	for i := range toSend {
+		item := toSend[i]
 
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
-				return
-			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick, "droppedTick", dropped.scheduledAt)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
-			}
+			sch.sendEvaluation(item)
 		})
 	}
 
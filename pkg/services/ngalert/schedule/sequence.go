@@ -0,0 +1,170 @@
+package schedule
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// metricNameRegex matches everything that looks like a metric name in queries that are not valid PromQL.
+var metricNameRegex = regexp.MustCompile(`[a-zA-Z_:][a-zA-Z0-9_:]*`)
+
+// sequenceEvaluations chains the evaluations of rule groups that evaluate their rules sequentially.
+// It returns the evaluations of the rules that are evaluated independently and the first evaluation of every chain.
+// The next evaluation of a chain is sent to its rule routine when the rule routine completes the previous one.
+func (sch *schedule) sequenceEvaluations(items []readyToRunItem) []readyToRunItem {
+	result := make([]readyToRunItem, 0, len(items))
+	groups := make(map[ngmodels.AlertRuleGroupKey][]readyToRunItem)
+	for _, item := range items {
+		if !item.rule.Metadata.SequentialEvaluation {
+			result = append(result, item)
+			continue
+		}
+		key := item.rule.GetGroupKey()
+		groups[key] = append(groups[key], item)
+	}
+
+	for key, group := range groups {
+		rules := make([]*ngmodels.AlertRule, 0, len(group))
+		byUID := make(map[string]readyToRunItem, len(group))
+		for _, item := range group {
+			rules = append(rules, item.rule)
+			byUID[item.rule.UID] = item
+		}
+		ordered, cyclic := sequenceOrder(rules)
+		if cyclic {
+			sch.log.Warn("Rules of the group depend on each other in a cycle, rules in the cycle are evaluated in the order of the group", "org_id", key.OrgID, "folder_uid", key.NamespaceUID, "group", key.RuleGroup)
+		}
+
+		chain := make([]readyToRunItem, len(ordered))
+		for i, rule := range ordered {
+			chain[i] = byUID[rule.UID]
+		}
+		// Link the chain from its end so that every evaluation sends the already linked next one.
+		for i := len(chain) - 2; i >= 0; i-- {
+			next := chain[i+1]
+			chain[i].afterEval = func() {
+				sch.sendEvaluation(next)
+			}
+		}
+		result = append(result, chain[0])
+	}
+	return result
+}
+
+// sendEvaluation sends the evaluation to the rule routine. If the routine is stopped,
+// the evaluation is skipped and the next evaluation of the chain, if any, is sent instead.
+func (sch *schedule) sendEvaluation(item readyToRunItem) {
+	key := item.rule.GetKey()
+	success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
+	if !success {
+		sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", item.scheduledAt)...)
+		if item.afterEval != nil {
+			item.afterEval()
+		}
+		return
+	}
+	if dropped != nil {
+		sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", item.scheduledAt, "droppedTick", dropped.scheduledAt)...)
+		orgID := fmt.Sprint(key.OrgID)
+		sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+	}
+}
+
+// sequenceOrder orders the rules of a group so that recording rules are evaluated before the rules that query
+// the metrics they record. Rules that do not depend on each other keep the order of the group.
+// If rules depend on each other in a cycle, the rules in the cycle are ordered as in the group and cyclic is true.
+func sequenceOrder(rules []*ngmodels.AlertRule) (ordered []*ngmodels.AlertRule, cyclic bool) {
+	remaining := slices.Clone(rules)
+	slices.SortStableFunc(remaining, func(a, b *ngmodels.AlertRule) int {
+		if c := cmp.Compare(a.RuleGroupIndex, b.RuleGroupIndex); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UID, b.UID)
+	})
+
+	// recordedBy contains the number of remaining rules that record every metric.
+	recordedBy := make(map[string]int)
+	for _, rule := range remaining {
+		if rule.Record != nil {
+			recordedBy[rule.Record.Metric]++
+		}
+	}
+	dependencies := make(map[string][]string, len(remaining))
+	for _, rule := range remaining {
+		for metric := range referencedMetrics(rule) {
+			if _, ok := recordedBy[metric]; ok && (rule.Record == nil || rule.Record.Metric != metric) {
+				dependencies[rule.UID] = append(dependencies[rule.UID], metric)
+			}
+		}
+	}
+
+	ordered = make([]*ngmodels.AlertRule, 0, len(remaining))
+	for len(remaining) > 0 {
+		// Take the first rule in group order whose dependencies are all recorded. If there is no such rule, take the first rule.
+		idx := slices.IndexFunc(remaining, func(rule *ngmodels.AlertRule) bool {
+			return !slices.ContainsFunc(dependencies[rule.UID], func(metric string) bool {
+				return recordedBy[metric] > 0
+			})
+		})
+		if idx < 0 {
+			idx = 0
+			cyclic = true
+		}
+		rule := remaining[idx]
+		remaining = slices.Delete(remaining, idx, idx+1)
+		if rule.Record != nil {
+			recordedBy[rule.Record.Metric]--
+		}
+		ordered = append(ordered, rule)
+	}
+	return ordered, cyclic
+}
+
+// referencedMetrics returns the names of the metrics that the queries of the rule select.
+// Queries that are not valid PromQL, such as queries of other data sources, reference every word that looks like a metric name.
+func referencedMetrics(rule *ngmodels.AlertRule) map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, q := range rule.Data {
+		// GetQuery caches the parsed model, so use a copy to not modify the rule that is shared with the rule routines.
+		query := q
+		if isExpr, _ := query.IsExpression(); isExpr {
+			continue
+		}
+		expr, err := query.GetQuery()
+		if err != nil || expr == "" {
+			continue
+		}
+
+		parsed, err := parser.ParseExpr(expr)
+		if err != nil {
+			for _, name := range metricNameRegex.FindAllString(expr, -1) {
+				result[name] = struct{}{}
+			}
+			continue
+		}
+		parser.Inspect(parsed, func(node parser.Node, _ []parser.Node) error {
+			vs, ok := node.(*parser.VectorSelector)
+			if !ok {
+				return nil
+			}
+			if vs.Name != "" {
+				result[vs.Name] = struct{}{}
+			}
+			for _, m := range vs.LabelMatchers {
+				if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+					result[m.Value] = struct{}{}
+				}
+			}
+			return nil
+		})
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package schedule
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestReferencedMetrics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		queries  []string
+		expected []string
+	}{
+		{
+			name:     "selectors of PromQL queries",
+			queries:  []string{`sum(rate(http_requests_total{job="api"}[5m])) / on() group_left job:errors:rate5m`},
+			expected: []string{"http_requests_total", "job:errors:rate5m"},
+		},
+		{
+			name:     "metric name matchers",
+			queries:  []string{`{__name__="up", job="api"}`},
+			expected: []string{"up"},
+		},
+		{
+			name:     "words of queries that are not PromQL",
+			queries:  []string{`SELECT mean(value) FROM cpu_usage`},
+			expected: []string{"SELECT", "mean", "value", "FROM", "cpu_usage"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &models.AlertRule{}
+			for _, q := range tc.queries {
+				rule.Data = append(rule.Data, promQuery(t, "A", q))
+			}
+			rule.Data = append(rule.Data, models.AlertQuery{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"expression":"A","type":"reduce"}`)})
+
+			metrics := referencedMetrics(rule)
+
+			require.Len(t, metrics, len(tc.expected))
+			for _, m := range tc.expected {
+				require.Contains(t, metrics, m)
+			}
+		})
+	}
+}
+
+func TestSequenceOrder(t *testing.T) {
+	t.Run("should evaluate recording rules before the rules that use their metrics", func(t *testing.T) {
+		alert := sequenceTestRule(t, "alert", 1, "", `job:errors:ratio > 0.1`)
+		ratio := sequenceTestRule(t, "ratio", 2, "job:errors:ratio", `job:errors:rate5m / job:requests:rate5m`)
+		errors := sequenceTestRule(t, "errors", 3, "job:errors:rate5m", `sum by (job) (rate(errors_total[5m]))`)
+		other := sequenceTestRule(t, "other", 4, "", `up == 0`)
+		requests := sequenceTestRule(t, "requests", 5, "job:requests:rate5m", `sum by (job) (rate(requests_total[5m]))`)
+
+		ordered, cyclic := sequenceOrder([]*models.AlertRule{requests, other, alert, errors, ratio})
+
+		require.False(t, cyclic)
+		require.Equal(t, []string{"errors", "other", "requests", "ratio", "alert"}, ruleUIDs(ordered))
+	})
+
+	t.Run("should keep the order of the group if rules do not depend on each other", func(t *testing.T) {
+		a := sequenceTestRule(t, "a", 1, "a:sum", `sum(a)`)
+		b := sequenceTestRule(t, "b", 2, "", `up`)
+		c := sequenceTestRule(t, "c", 3, "c:sum", `sum(c)`)
+
+		ordered, cyclic := sequenceOrder([]*models.AlertRule{c, b, a})
+
+		require.False(t, cyclic)
+		require.Equal(t, []string{"a", "b", "c"}, ruleUIDs(ordered))
+	})
+
+	t.Run("should ignore recording rules that use their own metric", func(t *testing.T) {
+		a := sequenceTestRule(t, "a", 1, "a:total", `a:total offset 1m + 1`)
+		b := sequenceTestRule(t, "b", 2, "", `a:total > 10`)
+
+		ordered, cyclic := sequenceOrder([]*models.AlertRule{b, a})
+
+		require.False(t, cyclic)
+		require.Equal(t, []string{"a", "b"}, ruleUIDs(ordered))
+	})
+
+	t.Run("should fall back to the order of the group for cycles", func(t *testing.T) {
+		a := sequenceTestRule(t, "a", 1, "a:sum", `b:sum`)
+		b := sequenceTestRule(t, "b", 2, "b:sum", `a:sum`)
+		c := sequenceTestRule(t, "c", 3, "", `a:sum > 1`)
+
+		ordered, cyclic := sequenceOrder([]*models.AlertRule{c, b, a})
+
+		require.True(t, cyclic)
+		require.Equal(t, []string{"a", "b", "c"}, ruleUIDs(ordered))
+	})
+}
+
+func TestSchedule_sequenceEvaluations(t *testing.T) {
+	sch := setupScheduler(t, newFakeRulesStore(), nil, nil, nil, nil, nil)
+	tick := time.Now()
+
+	alert := sequenceTestRule(t, "alert", 1, "", `job:errors:rate5m > 1`)
+	recording := sequenceTestRule(t, "recording", 2, "job:errors:rate5m", `sum(rate(errors_total[5m]))`)
+	independent := sequenceTestRule(t, "independent", 1, "", `up == 0`)
+	independent.Metadata.SequentialEvaluation = false
+	independent.RuleGroup = "other"
+
+	var mtx sync.Mutex
+	var evaluated []string
+	items := make([]readyToRunItem, 0, 3)
+	for _, rule := range []*models.AlertRule{alert, recording, independent} {
+		items = append(items, readyToRunItem{
+			ruleRoutine: &chainedRule{evaluated: func(uid string) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				evaluated = append(evaluated, uid)
+			}},
+			Evaluation: Evaluation{scheduledAt: tick, rule: rule},
+		})
+	}
+
+	toSend := sch.sequenceEvaluations(items)
+
+	require.Len(t, toSend, 2)
+	for _, item := range toSend {
+		sch.sendEvaluation(item)
+	}
+	require.ElementsMatch(t, []string{"independent", "recording", "alert"}, evaluated)
+	require.Less(t, slices.Index(evaluated, "recording"), slices.Index(evaluated, "alert"))
+
+	t.Run("should continue the chain if a rule routine is stopped", func(t *testing.T) {
+		evaluated = nil
+		items[1].ruleRoutine = &chainedRule{stopped: true}
+
+		for _, item := range sch.sequenceEvaluations(items[:2]) {
+			sch.sendEvaluation(item)
+		}
+
+		require.Equal(t, []string{"alert"}, evaluated)
+	})
+}
+
+// chainedRule is a Rule that evaluates synchronously.
+type chainedRule struct {
+	Rule
+	stopped   bool
+	evaluated func(uid string)
+}
+
+func (r *chainedRule) Eval(e *Evaluation) (bool, *Evaluation) {
+	if r.stopped {
+		return false, nil
+	}
+	r.evaluated(e.rule.UID)
+	if e.afterEval != nil {
+		e.afterEval()
+	}
+	return true, nil
+}
+
+func sequenceTestRule(t *testing.T, uid string, idx int, record string, query string) *models.AlertRule {
+	t.Helper()
+	rule := &models.AlertRule{
+		OrgID:          1,
+		UID:            uid,
+		Title:          uid,
+		NamespaceUID:   "folder",
+		RuleGroup:      "group",
+		RuleGroupIndex: idx,
+		Data:           []models.AlertQuery{promQuery(t, "A", query)},
+		Metadata:       models.AlertRuleMetadata{SequentialEvaluation: true},
+	}
+	if record != "" {
+		rule.Record = &models.Record{Metric: record, From: "A"}
+	}
+	return rule
+}
+
+func promQuery(t *testing.T, refID, query string) models.AlertQuery {
+	t.Helper()
+	model, err := json.Marshal(map[string]string{"expr": query})
+	require.NoError(t, err)
+	return models.AlertQuery{RefID: refID, DatasourceUID: "prometheus", Model: model}
+}
+
+func ruleUIDs(rules []*models.AlertRule) []string {
+	result := make([]string, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, r.UID)
+	}
+	return result
+}
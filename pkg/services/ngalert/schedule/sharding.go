@@ -0,0 +1,166 @@
+package schedule
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringVirtualNodes is the number of positions each member takes on the hash ring.
+// More positions give a more even distribution of rules across members.
+const ringVirtualNodes = 128
+
+var errRuleHandedOff = errors.New("rule handed off to another instance")
+
+// ClusterMembership provides the members of the cluster of Grafana instances that share the evaluation of alert rules.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this instance and the names of all live instances, including this one.
+	ClusterMembers() (string, []string)
+}
+
+// ruleSharder assigns alert rules to the members of the cluster using consistent hashing,
+// so that every rule is evaluated by exactly one member. When a member joins or leaves the cluster,
+// only the rules assigned to that member are moved.
+type ruleSharder struct {
+	membership ClusterMembership
+
+	mtx     sync.Mutex
+	self    string
+	members []string
+	ring    *hashRing
+}
+
+func newRuleSharder(membership ClusterMembership) *ruleSharder {
+	return &ruleSharder{membership: membership}
+}
+
+// currentRing returns the hash ring for the current members of the cluster.
+func (s *ruleSharder) currentRing() *hashRing {
+	self, members := s.membership.ClusterMembers()
+	members = slices.Clone(members)
+	if self != "" && !slices.Contains(members, self) {
+		// The instance may not see itself until it has joined the cluster.
+		members = append(members, self)
+	}
+	sort.Strings(members)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.ring == nil || s.self != self || !slices.Equal(s.members, members) {
+		s.self = self
+		s.members = members
+		s.ring = newHashRing(self, members)
+	}
+	return s.ring
+}
+
+type ringToken struct {
+	hash   uint64
+	member string
+}
+
+// hashRing is an immutable consistent hash ring.
+type hashRing struct {
+	self   string
+	tokens []ringToken
+}
+
+func newHashRing(self string, members []string) *hashRing {
+	tokens := make([]ringToken, 0, len(members)*ringVirtualNodes)
+	for _, m := range members {
+		for i := 0; i < ringVirtualNodes; i++ {
+			tokens = append(tokens, ringToken{hash: xxhash.Sum64String(m + "/" + strconv.Itoa(i)), member: m})
+		}
+	}
+	slices.SortFunc(tokens, func(a, b ringToken) int {
+		if c := cmp.Compare(a.hash, b.hash); c != 0 {
+			return c
+		}
+		// Make the order deterministic on all members in the unlikely case of collisions.
+		return strings.Compare(a.member, b.member)
+	})
+	return &hashRing{self: self, tokens: tokens}
+}
+
+// shardKey returns the key that assigns the rule to a member of the cluster. All rules of a group that is evaluated
+// sequentially share the key of the group, so that a single member evaluates the whole group in dependency order.
+func shardKey(rule *ngmodels.AlertRule) string {
+	if rule.Metadata.SequentialEvaluation {
+		return strconv.FormatInt(rule.OrgID, 10) + "/" + rule.NamespaceUID + "/" + rule.RuleGroup
+	}
+	return strconv.FormatInt(rule.OrgID, 10) + "/" + rule.UID
+}
+
+// owner returns the member that evaluates the rules with the shard key, or an empty string if the ring has no members.
+func (r *hashRing) owner(key string) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := xxhash.Sum64String(key)
+	idx := sort.Search(len(r.tokens), func(i int) bool {
+		return r.tokens[i].hash >= h
+	})
+	if idx == len(r.tokens) {
+		idx = 0
+	}
+	return r.tokens[idx].member
+}
+
+// owns returns true if this instance evaluates the rules with the shard key. An instance that does not know its
+// name, e.g. because clustering is not configured, evaluates all rules.
+func (r *hashRing) owns(key string) bool {
+	if r.self == "" {
+		return true
+	}
+	return r.owner(key) == r.self
+}
+
+// shardRules returns the rules that this instance should evaluate. Routines of rules that were handed off to another
+// instance are stopped without resolving their alerts, and the state of rules that were taken over from another instance
+// is loaded from the database so that the evaluation continues where the previous owner left off.
+func (sch *schedule) shardRules(ctx context.Context, rules []*ngmodels.AlertRule) []*ngmodels.AlertRule {
+	if sch.sharder == nil {
+		return rules
+	}
+	ring := sch.sharder.currentRing()
+	owned := make([]*ngmodels.AlertRule, 0, len(rules))
+	notOwned := make(map[ngmodels.AlertRuleKey]struct{}, len(rules))
+	for _, rule := range rules {
+		key := rule.GetKey()
+		_, wasNotOwned := sch.notOwnedRules[key]
+		if ring.owns(shardKey(rule)) {
+			if wasNotOwned {
+				sch.log.FromContext(ctx).Info("Rule was taken over from another instance", key.LogContext()...)
+				if err := sch.stateManager.WarmRule(ctx, rule, sch.instanceReader); err != nil {
+					sch.log.FromContext(ctx).Error("Failed to load the state of the rule taken over from another instance", append(key.LogContext(), "error", err)...)
+				}
+			}
+			owned = append(owned, rule)
+			continue
+		}
+
+		notOwned[key] = struct{}{}
+		if wasNotOwned {
+			continue
+		}
+		if routine, ok := sch.registry.del(key); ok {
+			sch.log.FromContext(ctx).Info("Rule was handed off to another instance", append(key.LogContext(), "owner", ring.owner(shardKey(rule)))...)
+			// The routine removes the state of the rule from the cache when it stops.
+			routine.Stop(errRuleHandedOff)
+		} else {
+			// The state of all rules is loaded on startup. Forget the rules that are evaluated by other instances.
+			sch.stateManager.ForgetStateByRuleUID(ctx, rule.GetKeyWithGroup())
+		}
+	}
+	sch.notOwnedRules = notOwned
+	return owned
+}
@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]string, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, shardKey(&models.AlertRule{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)}))
+	}
+
+	t.Run("should distribute rules across all members", func(t *testing.T) {
+		ring := newHashRing("a", []string{"a", "b", "c"})
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[ring.owner(key)]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.Greaterf(t, count, len(keys)/6, "member %s owns too few rules", member)
+			require.Lessf(t, count, len(keys)/2, "member %s owns too many rules", member)
+		}
+	})
+
+	t.Run("should move only rules of the member that joined or left", func(t *testing.T) {
+		before := newHashRing("a", []string{"a", "b", "c"})
+		after := newHashRing("a", []string{"a", "b", "c", "d"})
+		for _, key := range keys {
+			if before.owner(key) != after.owner(key) {
+				require.Equal(t, "d", after.owner(key))
+			}
+		}
+
+		after = newHashRing("a", []string{"a", "c"})
+		for _, key := range keys {
+			if before.owner(key) != after.owner(key) {
+				require.Equal(t, "b", before.owner(key))
+			}
+		}
+	})
+
+	t.Run("should own all rules if instance name is unknown", func(t *testing.T) {
+		ring := newHashRing("", []string{"a", "b"})
+		for _, key := range keys {
+			require.True(t, ring.owns(key))
+		}
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	t.Run("should include the instance itself if it is not yet a member", func(t *testing.T) {
+		sharder := newRuleSharder(&fakeClusterMembership{self: "a", members: []string{"b"}})
+		ring := sharder.currentRing()
+		owners := map[string]struct{}{}
+		for i := 0; i < 100; i++ {
+			owners[ring.owner(shardKey(&models.AlertRule{OrgID: 1, UID: fmt.Sprint(i)}))] = struct{}{}
+		}
+		require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, owners)
+	})
+
+	t.Run("should rebuild the ring only when membership changes", func(t *testing.T) {
+		membership := &fakeClusterMembership{self: "a", members: []string{"b", "a"}}
+		sharder := newRuleSharder(membership)
+		ring := sharder.currentRing()
+		membership.set("a", []string{"a", "b"})
+		require.Same(t, ring, sharder.currentRing())
+		membership.set("a", []string{"a", "b", "c"})
+		require.NotSame(t, ring, sharder.currentRing())
+	})
+}
+
+func TestSchedule_shardRules(t *testing.T) {
+	ctx := context.Background()
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil, nil)
+	membership := &fakeClusterMembership{self: "a", members: []string{"a", "b"}}
+	sch.sharder = newRuleSharder(membership)
+	sch.instanceReader = instanceStore
+
+	rules := models.RuleGen.GenerateManyRef(30)
+	ruleFactory := ruleFactoryFromScheduler(sch)
+	routines := make(map[models.AlertRuleKey]Rule, len(rules))
+	for _, rule := range rules {
+		routines[rule.GetKey()], _ = sch.registry.getOrCreate(ctx, rule, ruleFactory)
+	}
+
+	owned := sch.shardRules(ctx, rules)
+
+	ring := newHashRing("a", []string{"a", "b"})
+	handedOff := map[models.AlertRuleKey]struct{}{}
+	for _, rule := range rules {
+		key := rule.GetKey()
+		if ring.owns(shardKey(rule)) {
+			require.Contains(t, owned, rule)
+			require.True(t, sch.registry.exists(key))
+			continue
+		}
+		handedOff[key] = struct{}{}
+		require.NotContains(t, owned, rule)
+		require.False(t, sch.registry.exists(key))
+		require.ErrorIs(t, routines[key].(*alertRule).ctx.Err(), errRuleHandedOff)
+	}
+	require.NotEmpty(t, handedOff)
+	require.NotEmpty(t, owned)
+	require.Empty(t, instanceStore.RecordedOps())
+
+	t.Run("should load state of rules taken over from other instances", func(t *testing.T) {
+		membership.set("a", []string{"a"})
+
+		owned := sch.shardRules(ctx, rules)
+
+		require.Len(t, owned, len(rules))
+		ops := instanceStore.RecordedOps()
+		require.Len(t, ops, len(handedOff))
+		for _, op := range ops {
+			q, ok := op.(models.ListAlertInstancesQuery)
+			require.True(t, ok)
+			require.Contains(t, handedOff, models.AlertRuleKey{OrgID: q.RuleOrgID, UID: q.RuleUID})
+		}
+	})
+}
+
+func TestSchedule_shardRules_SequentialGroups(t *testing.T) {
+	ctx := context.Background()
+	var rules []*models.AlertRule
+	for i := 0; i < 20; i++ {
+		group := models.RuleGen.With(
+			models.RuleMuts.WithGroupKey(models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: fmt.Sprintf("group-%d", i)}),
+			models.RuleMuts.WithMetadata(models.AlertRuleMetadata{SequentialEvaluation: true}),
+		).GenerateManyRef(3)
+		rules = append(rules, group...)
+	}
+	rules = append(rules, models.RuleGen.With(models.RuleMuts.WithOrgID(1)).GenerateManyRef(20)...)
+
+	// Two instances of the same cluster shard the same rules.
+	owners := make(map[models.AlertRuleKey]string, len(rules))
+	for _, self := range []string{"a", "b"} {
+		sch := setupScheduler(t, newFakeRulesStore(), &state.FakeInstanceStore{}, nil, nil, nil, nil)
+		sch.sharder = newRuleSharder(&fakeClusterMembership{self: self, members: []string{"a", "b"}})
+		for _, rule := range sch.shardRules(ctx, rules) {
+			require.NotContains(t, owners, rule.GetKey(), "rule is evaluated by both instances")
+			owners[rule.GetKey()] = self
+		}
+	}
+	require.Len(t, owners, len(rules), "every rule should be evaluated by one instance")
+
+	groupOwners := map[models.AlertRuleGroupKey]map[string]struct{}{}
+	for _, rule := range rules {
+		if !rule.Metadata.SequentialEvaluation {
+			continue
+		}
+		if groupOwners[rule.GetGroupKey()] == nil {
+			groupOwners[rule.GetGroupKey()] = map[string]struct{}{}
+		}
+		groupOwners[rule.GetGroupKey()][owners[rule.GetKey()]] = struct{}{}
+	}
+	for key, members := range groupOwners {
+		require.Lenf(t, members, 1, "rules of sequential group %s are evaluated by several instances", key.RuleGroup)
+	}
+	require.Len(t, groupOwners, 20)
+}
+
+type fakeClusterMembership struct {
+	mtx     sync.Mutex
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) set(self string, members []string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.self = self
+	f.members = members
+}
+
+func (f *fakeClusterMembership) ClusterMembers() (string, []string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.self, f.members
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -107,6 +108,9 @@ func expandAnnotationsAndLabels(ctx context.Context, log log.Logger, alertRule *
 	// In the future, we want to show these errors to the user somehow.
 	labels, _ := expand(ctx, log, alertRule.Title, alertRule.Labels, templateData, externalURL, result.EvaluatedAt)
 	annotations, _ := expand(ctx, log, alertRule.Title, alertRule.Annotations, templateData, externalURL, result.EvaluatedAt)
+	if level, ok := thresholdLevel(alertRule, result); ok {
+		annotations[ngModels.ThresholdLevelAnnotation] = level
+	}
 
 	lbs := make(data.Labels, len(extraLabels)+len(labels)+len(resultLabels))
 	dupes := make(data.Labels)
@@ -143,6 +147,19 @@ func expandAnnotationsAndLabels(ctx context.Context, log log.Logger, alertRule *
 	return lbs, annotations
 }
 
+// thresholdLevel returns the name of the level of the result if the condition of the rule is a threshold expression with levels.
+func thresholdLevel(alertRule *ngModels.AlertRule, result eval.Result) (string, bool) {
+	v, ok := result.Values[alertRule.Condition]
+	if !ok || v.Value == nil {
+		return "", false
+	}
+	levels := alertRule.GetThresholdLevels()
+	if len(levels) == 0 {
+		return "", false
+	}
+	return expr.ThresholdLevelName(levels, *v.Value)
+}
+
 // expand returns the expanded templates of all annotations or labels for the template data.
 // If a template cannot be expanded due to an error in the template the original template is
 // maintained and an error is added to the multierror. All errors in the multierror are
@@ -463,6 +463,14 @@ func buildQueryTail(query models.HistoryQuery) (string, error) {
 		b.WriteString(" | panelID=")
 		b.WriteString(strconv.FormatInt(query.PanelID, 10))
 	}
+	if query.State != "" {
+		// Match formatted states with a reason, e.g. "Alerting (Error)".
+		b.WriteString(" | current=~")
+		_, err := fmt.Fprintf(&b, "%q", regexp.QuoteMeta(query.State)+".*")
+		if err != nil {
+			return "", err
+		}
+	}
 
 	requiredSize := 0
 	labelKeys := make([]string, 0, len(query.Labels))
@@ -489,11 +497,17 @@ func queryHasLogFilters(query models.HistoryQuery) bool {
 	return query.RuleUID != "" ||
 		query.DashboardUID != "" ||
 		query.PanelID != 0 ||
+		query.State != "" ||
 		len(query.Labels) > 0
 }
 
 func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query models.HistoryQuery) ([]string, error) {
-	bypass, err := h.ac.CanReadAllRules(ctx, query.SignedInUser)
+	return readableFolderUIDs(ctx, h.ac, h.ruleStore, query)
+}
+
+// readableFolderUIDs returns UIDs of folders in which the user can read rules, or nil if the user can read all rules.
+func readableFolderUIDs(ctx context.Context, ac AccessControl, ruleStore RuleStore, query models.HistoryQuery) ([]string, error) {
+	bypass, err := ac.CanReadAllRules(ctx, query.SignedInUser)
 	if err != nil {
 		return nil, err
 	}
@@ -502,7 +516,7 @@ func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query mo
 	}
 	// if there is a filter by rule UID, find that rule UID and make sure that user has access to it.
 	if query.RuleUID != "" {
-		rule, err := h.ruleStore.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{
+		rule, err := ruleStore.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{
 			UID:   query.RuleUID,
 			OrgID: query.OrgID,
 		})
@@ -512,17 +526,17 @@ func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query mo
 		if rule == nil {
 			return nil, models.ErrAlertRuleNotFound
 		}
-		return nil, h.ac.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
+		return nil, ac.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
 	}
 	// if no filter, then we need to get all namespaces user has access to
-	folders, err := h.ruleStore.GetUserVisibleNamespaces(ctx, query.OrgID, query.SignedInUser)
+	folders, err := ruleStore.GetUserVisibleNamespaces(ctx, query.OrgID, query.SignedInUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch folders that user can access: %w", err)
 	}
 	uids := make([]string, 0, len(folders))
 	// now keep only UIDs of folder in which user can read rules.
 	for _, f := range folders {
-		hasAccess, err := h.ac.HasAccessInFolder(ctx, query.SignedInUser, models.Namespace(*f))
+		hasAccess, err := ac.HasAccessInFolder(ctx, query.SignedInUser, models.Namespace(*f))
 		if err != nil {
 			return nil, err
 		}
@@ -249,6 +249,14 @@ func TestBuildLogQuery(t *testing.T) {
 			},
 			exp: []string{`{orgID="123",from="state-history"} | json | panelID=456`},
 		},
+		{
+			name: "filters current state in log line",
+			query: models.HistoryQuery{
+				OrgID: 123,
+				State: "Alerting",
+			},
+			exp: []string{`{orgID="123",from="state-history"} | json | current=~"Alerting.*"`},
+		},
 		{
 			name: "filters instance labels in log line",
 			query: models.HistoryQuery{
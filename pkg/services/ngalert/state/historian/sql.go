@@ -0,0 +1,288 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// sqlMaintenanceInterval is how often the SQL backend applies retention and downsampling.
+const sqlMaintenanceInterval = 10 * time.Minute
+
+// StateHistoryStore persists state history entries in the database.
+type StateHistoryStore interface {
+	InsertStateHistoryEntries(ctx context.Context, entries []models.StateHistoryEntry) error
+	FindStateHistoryEntries(ctx context.Context, query models.StateHistoryEntriesQuery) ([]models.StateHistoryEntry, error)
+	DeleteStateHistoryEntriesBefore(ctx context.Context, before time.Time) (int64, error)
+	DownsampleStateHistoryEntries(ctx context.Context, before time.Time, interval time.Duration) (int64, error)
+}
+
+type SQLConfig struct {
+	// Retention is how long entries are kept. Zero keeps them forever.
+	Retention time.Duration
+	// DownsampleAfter is the age after which entries are downsampled. Zero disables downsampling.
+	DownsampleAfter time.Duration
+	// DownsampleInterval is the size of the time buckets used for downsampling.
+	DownsampleInterval time.Duration
+}
+
+func NewSQLConfig(cfg setting.UnifiedAlertingStateHistorySettings) (SQLConfig, error) {
+	if cfg.SQLRetention < 0 {
+		return SQLConfig{}, errors.New("retention must not be negative")
+	}
+	if cfg.SQLDownsampleAfter < 0 {
+		return SQLConfig{}, errors.New("downsample after must not be negative")
+	}
+	if cfg.SQLDownsampleAfter > 0 && cfg.SQLDownsampleInterval <= 0 {
+		return SQLConfig{}, errors.New("downsample interval must be positive when downsampling is enabled")
+	}
+	return SQLConfig{
+		Retention:          cfg.SQLRetention,
+		DownsampleAfter:    cfg.SQLDownsampleAfter,
+		DownsampleInterval: cfg.SQLDownsampleInterval,
+	}, nil
+}
+
+// SQLBackend is a state.Historian that records state history to a dedicated table in the Grafana database.
+type SQLBackend struct {
+	store     StateHistoryStore
+	cfg       SQLConfig
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+	ac        AccessControl
+	ruleStore RuleStore
+}
+
+func NewSQLBackend(logger log.Logger, cfg SQLConfig, store StateHistoryStore, metrics *metrics.Historian, ruleStore RuleStore, ac AccessControl) *SQLBackend {
+	return &SQLBackend{
+		store:     store,
+		cfg:       cfg,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+		ac:        ac,
+		ruleStore: ruleStore,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	entries := statesToEntries(rule, states)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+		logger.Debug("Saving state history batch", "samples", len(entries))
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.store.InsertStateHistoryEntries(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch", "samples", len(entries))
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe
+// of the same shape as the one returned by the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := readableFolderUIDs(ctx, h.ac, h.ruleStore, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maximumPageSize {
+		query.Limit = maximumPageSize
+	}
+
+	entries, err := h.store.FindStateHistoryEntries(ctx, models.StateHistoryEntriesQuery{
+		HistoryQuery:  query,
+		NamespaceUIDs: uids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Entries are returned most recent first, so the limit applies to the latest transitions.
+	slices.Reverse(entries)
+	return entriesToFrame(entries)
+}
+
+// Run periodically deletes entries that are older than the configured retention and downsamples old entries.
+func (h *SQLBackend) Run(ctx context.Context) error {
+	if h.cfg.Retention <= 0 && h.cfg.DownsampleAfter <= 0 {
+		return nil
+	}
+	ticker := h.clock.Ticker(sqlMaintenanceInterval)
+	defer ticker.Stop()
+	for {
+		h.maintain(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *SQLBackend) maintain(ctx context.Context) {
+	now := h.clock.Now()
+	if h.cfg.Retention > 0 {
+		deleted, err := h.store.DeleteStateHistoryEntriesBefore(ctx, now.Add(-h.cfg.Retention))
+		if err != nil && !errors.Is(err, context.Canceled) {
+			h.log.Error("Failed to delete expired state history entries", "error", err)
+		}
+		if deleted > 0 {
+			h.log.Debug("Deleted expired state history entries", "count", deleted)
+		}
+	}
+	if h.cfg.DownsampleAfter > 0 {
+		deleted, err := h.store.DownsampleStateHistoryEntries(ctx, now.Add(-h.cfg.DownsampleAfter), h.cfg.DownsampleInterval)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			h.log.Error("Failed to downsample state history entries", "error", err)
+		}
+		if deleted > 0 {
+			h.log.Debug("Downsampled state history entries", "deleted", deleted)
+		}
+	}
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		entry := models.StateHistoryEntry{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			RuleID:       rule.ID,
+			RuleTitle:    rule.Title,
+			RuleGroup:    rule.Group,
+			NamespaceUID: rule.NamespaceUID,
+			DashboardUID: rule.DashboardUID,
+			PanelID:      rule.PanelID,
+			Condition:    rule.Condition,
+			Fingerprint:  labelFingerprint(sanitizedLabels),
+			Labels:       sanitizedLabels,
+			Previous:     state.PreviousFormatted(),
+			Current:      state.Formatted(),
+			Timestamp:    state.State.LastEvaluationTime,
+		}
+		if values := valuesAsDataBlob(state.State); values != nil {
+			if b, err := values.MarshalJSON(); err == nil {
+				entry.Values = string(b)
+			}
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.Error = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entriesToFrame converts entries sorted by time to a dataframe with the time, line and labels fields.
+// The line contains the entry in the same format that is written to Loki, and the labels are the equivalent of Loki stream labels.
+func entriesToFrame(entries []models.StateHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		entry := LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.Previous,
+			Current:        e.Current,
+			Error:          e.Error,
+			Condition:      e.Condition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: e.Labels,
+		}
+		if e.Values != "" {
+			values, err := simplejson.NewJson([]byte(e.Values))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse values of state history entry: %w", err)
+			}
+			entry.Values = values
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry: %w", err)
+		}
+		streamLbls, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history labels: %w", err)
+		}
+		times = append(times, e.Timestamp)
+		lines = append(lines, line)
+		labels = append(labels, streamLbls)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
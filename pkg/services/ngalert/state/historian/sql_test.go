@@ -0,0 +1,194 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSQLBackend(t *testing.T) {
+	t.Run("Record writes state transitions to the store", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		backend := createTestSQLBackend(t, store, &acfakes.FakeRuleService{})
+		rule := createTestRule()
+		now := time.Unix(100, 0).UTC()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b", "__private__": "c"},
+			Values:             map[string]float64{"A": 1},
+			LastEvaluationTime: now,
+		})
+
+		err := <-backend.Record(context.Background(), rule, states)
+
+		require.NoError(t, err)
+		require.Len(t, store.entries, 1)
+		entry := store.entries[0]
+		require.Equal(t, rule.UID, entry.RuleUID)
+		require.Equal(t, rule.NamespaceUID, entry.NamespaceUID)
+		require.Equal(t, data.Labels{"a": "b"}, entry.Labels)
+		require.Equal(t, "Normal", entry.Previous)
+		require.Equal(t, "Alerting", entry.Current)
+		require.JSONEq(t, `{"A": 1}`, entry.Values)
+		require.Equal(t, now, entry.Timestamp)
+	})
+
+	t.Run("Record elides write if nothing to record", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		backend := createTestSQLBackend(t, store, &acfakes.FakeRuleService{})
+
+		err := <-backend.Record(context.Background(), createTestRule(), []state.StateTransition{})
+
+		require.NoError(t, err)
+		require.Zero(t, store.writes)
+	})
+
+	t.Run("Record returns store errors", func(t *testing.T) {
+		store := &fakeStateHistoryStore{err: errors.New("test error")}
+		backend := createTestSQLBackend(t, store, &acfakes.FakeRuleService{})
+		states := singleFromNormal(&state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}})
+
+		err := <-backend.Record(context.Background(), createTestRule(), states)
+
+		require.ErrorContains(t, err, "test error")
+	})
+
+	t.Run("Query returns entries in the same format as Loki", func(t *testing.T) {
+		ts := time.Unix(100, 0).UTC()
+		store := &fakeStateHistoryStore{
+			found: []models.StateHistoryEntry{
+				{OrgID: 1, RuleUID: "rule-uid", RuleGroup: "group", NamespaceUID: "folder", Labels: data.Labels{"a": "b"}, Previous: "Pending", Current: "Alerting", Values: `{"A":1}`, Timestamp: ts.Add(time.Minute)},
+				{OrgID: 1, RuleUID: "rule-uid", RuleGroup: "group", NamespaceUID: "folder", Labels: data.Labels{"a": "b"}, Previous: "Normal", Current: "Pending", Timestamp: ts},
+			},
+		}
+		ac := &acfakes.FakeRuleService{}
+		ac.CanReadAllRulesFunc = func(context.Context, identity.Requester) (bool, error) {
+			return true, nil
+		}
+		backend := createTestSQLBackend(t, store, ac)
+
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "rule-uid", State: "Alerting"})
+
+		require.NoError(t, err)
+		require.Equal(t, "Alerting", store.lastQuery.State)
+		require.Equal(t, defaultPageSize, store.lastQuery.Limit)
+		require.False(t, store.lastQuery.From.IsZero())
+		require.Nil(t, store.lastQuery.NamespaceUIDs)
+
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, ts, frame.Fields[0].At(0))
+		var entry LokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(1).(json.RawMessage), &entry))
+		require.Equal(t, "Pending", entry.Previous)
+		require.Equal(t, "Alerting", entry.Current)
+		require.Equal(t, map[string]string{"a": "b"}, entry.InstanceLabels)
+		require.Equal(t, 1.0, entry.Values.Get("A").MustFloat64())
+		var lbls map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+		require.Equal(t, map[string]string{OrgIDLabel: "1", GroupLabel: "group", FolderUIDLabel: "folder", StateHistoryLabelKey: StateHistoryLabelValue}, lbls)
+	})
+
+	t.Run("Query fails if user cannot access the rule", func(t *testing.T) {
+		ac := &acfakes.FakeRuleService{}
+		backend := createTestSQLBackend(t, &fakeStateHistoryStore{}, ac)
+
+		_, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "not-found"})
+
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+
+	t.Run("maintain applies retention and downsampling", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		backend := createTestSQLBackend(t, store, &acfakes.FakeRuleService{})
+		clk := clock.NewMock()
+		clk.Set(time.Unix(1000000, 0))
+		backend.clock = clk
+		backend.cfg = SQLConfig{Retention: 24 * time.Hour, DownsampleAfter: time.Hour, DownsampleInterval: 5 * time.Minute}
+
+		backend.maintain(context.Background())
+
+		require.Equal(t, clk.Now().Add(-24*time.Hour), store.deletedBefore)
+		require.Equal(t, clk.Now().Add(-time.Hour), store.downsampledBefore)
+		require.Equal(t, 5*time.Minute, store.downsampleInterval)
+	})
+}
+
+func TestNewSQLConfig(t *testing.T) {
+	_, err := NewSQLConfig(setting.UnifiedAlertingStateHistorySettings{SQLRetention: -time.Hour})
+	require.Error(t, err)
+
+	_, err = NewSQLConfig(setting.UnifiedAlertingStateHistorySettings{SQLDownsampleAfter: time.Hour})
+	require.Error(t, err)
+
+	cfg, err := NewSQLConfig(setting.UnifiedAlertingStateHistorySettings{SQLRetention: time.Hour, SQLDownsampleAfter: time.Minute, SQLDownsampleInterval: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, SQLConfig{Retention: time.Hour, DownsampleAfter: time.Minute, DownsampleInterval: time.Second}, cfg)
+}
+
+func createTestSQLBackend(t *testing.T, store StateHistoryStore, ac AccessControl) *SQLBackend {
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	return NewSQLBackend(log.NewNopLogger(), SQLConfig{}, store, met, fakes.NewRuleStore(t), ac)
+}
+
+type fakeStateHistoryStore struct {
+	mtx                sync.Mutex
+	err                error
+	writes             int
+	entries            []models.StateHistoryEntry
+	found              []models.StateHistoryEntry
+	lastQuery          models.StateHistoryEntriesQuery
+	deletedBefore      time.Time
+	downsampledBefore  time.Time
+	downsampleInterval time.Duration
+}
+
+func (f *fakeStateHistoryStore) InsertStateHistoryEntries(_ context.Context, entries []models.StateHistoryEntry) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.writes++
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) FindStateHistoryEntries(_ context.Context, query models.StateHistoryEntriesQuery) ([]models.StateHistoryEntry, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.lastQuery = query
+	return append([]models.StateHistoryEntry(nil), f.found...), f.err
+}
+
+func (f *fakeStateHistoryStore) DeleteStateHistoryEntriesBefore(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deletedBefore = before
+	return 0, f.err
+}
+
+func (f *fakeStateHistoryStore) DownsampleStateHistoryEntries(_ context.Context, before time.Time, interval time.Duration) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.downsampledBefore = before
+	f.downsampleInterval = interval
+	return 0, f.err
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -188,38 +189,7 @@ func (st *Manager) Warm(ctx context.Context, orgReader OrgReader, rulesReader Ru
 				continue
 			}
 
-			// nil safety.
-			annotations := ruleForEntry.Annotations
-			if annotations == nil {
-				annotations = make(map[string]string)
-			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheID := entry.Labels.Fingerprint()
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					logger.Error("Failed to parse result fingerprint of alert instance", "error", err, "rule_uid", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			state := &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          annotations,
-				ResultFingerprint:    resultFp,
-				ResolvedAt:           entry.ResolvedAt,
-				LastSentAt:           entry.LastSentAt,
-			}
+			state := stateFromInstance(entry, ruleForEntry, logger)
 			st.cache.set(state)
 			statesCount++
 		}
@@ -228,6 +198,63 @@ func (st *Manager) Warm(ctx context.Context, orgReader OrgReader, rulesReader Ru
 	logger.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule replaces the cached state of the rule with the state stored in the database.
+// It is used when this instance starts evaluating a rule that was previously evaluated by another instance.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule, instanceReader InstanceReader) error {
+	alertInstances, err := instanceReader.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch state of the rule: %w", err)
+	}
+
+	logger := st.log.FromContext(ctx).New(rule.GetKey().LogContext()...)
+	states := ruleStates{states: make(map[data.Fingerprint]*State, len(alertInstances))}
+	for _, entry := range alertInstances {
+		s := stateFromInstance(entry, rule, logger)
+		states.states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.GetKey(), states)
+	logger.Debug("State of the rule has been loaded", "states", len(states.states))
+	return nil
+}
+
+func stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule, logger log.Logger) *State {
+	// nil safety.
+	annotations := rule.Annotations
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	lbs := map[string]string(entry.Labels)
+	cacheID := entry.Labels.Fingerprint()
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			logger.Error("Failed to parse result fingerprint of alert instance", "error", err, "rule_uid", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               lbs,
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          annotations,
+		ResultFingerprint:    resultFp,
+		ResolvedAt:           entry.ResolvedAt,
+		LastSentAt:           entry.LastSentAt,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID string, stateId data.Fingerprint) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -389,6 +416,43 @@ func (st *Manager) ProcessEvalResults(
 	return allChanges
 }
 
+// KeepStatesForRule keeps the current states of a rule whose evaluation is skipped, e.g. because it exceeded its query budget.
+// The states do not change, but they are marked as evaluated at evaluatedAt with the given reason, so that firing alerts
+// do not expire in the Alertmanager and the states are not considered stale once the rule is evaluated again.
+// The states that need it are sent again with the send function.
+func (st *Manager) KeepStatesForRule(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, reason string, send Sender) StateTransitions {
+	ctx, span := st.tracer.Start(ctx, "alert rule state keep", trace.WithAttributes(
+		attribute.String("rule_uid", alertRule.UID),
+		attribute.Int64("org_id", alertRule.OrgID),
+		attribute.String("reason", reason)))
+	defer span.End()
+
+	states := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID)
+	transitions := make(StateTransitions, 0, len(states))
+	for _, s := range states {
+		transitions = append(transitions, StateTransition{
+			State:               s,
+			PreviousState:       s.State,
+			PreviousStateReason: s.StateReason,
+		})
+		s.StateReason = reason
+		s.LastEvaluationTime = evaluatedAt
+		if s.State != eval.Normal {
+			s.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+		}
+	}
+
+	var statesToSend StateTransitions
+	if send != nil {
+		statesToSend = st.updateLastSentAt(transitions, evaluatedAt)
+	}
+	st.persister.Sync(ctx, span, alertRule.GetKeyWithGroup(), transitions)
+	if send != nil {
+		send(ctx, statesToSend)
+	}
+	return transitions
+}
+
 // updateLastSentAt returns the subset StateTransitions that need sending and updates their LastSentAt field.
 // Note: This is not idempotent, running this twice can (and usually will) return different results.
 func (st *Manager) updateLastSentAt(states StateTransitions, evaluatedAt time.Time) StateTransitions {
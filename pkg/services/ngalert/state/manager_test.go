@@ -1986,6 +1986,59 @@ func TestStaleResults(t *testing.T) {
 	})
 }
 
+func TestKeepStatesForRule(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithFor(0)).GenerateRef()
+	results := eval.Results{
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()))(),
+		eval.ResultGen(eval.WithState(eval.Normal), eval.WithEvaluatedAt(clk.Now()))(),
+	}
+	st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil, nil)
+
+	// The rule is not evaluated for longer than it takes for states to become stale and alerts to expire.
+	clk.Add(10 * time.Duration(rule.IntervalSeconds) * time.Second)
+	var sent state.StateTransitions
+	kept := st.KeepStatesForRule(ctx, clk.Now(), rule, models.StateReasonQueryBudgetExceeded, func(_ context.Context, states state.StateTransitions) {
+		sent = states
+	})
+	require.Len(t, kept, 2)
+	require.Len(t, sent, 1, "only the firing alert is sent again")
+	require.Equal(t, eval.Alerting, sent[0].State.State)
+	require.True(t, sent[0].EndsAt.After(clk.Now()))
+
+	for _, s := range st.GetStatesForRuleUID(rule.OrgID, rule.UID) {
+		require.Equal(t, models.StateReasonQueryBudgetExceeded, s.StateReason)
+		require.Equal(t, clk.Now(), s.LastEvaluationTime)
+	}
+
+	t.Run("states are not stale when the rule is evaluated again", func(t *testing.T) {
+		clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+		for i := range results {
+			results[i].EvaluatedAt = clk.Now()
+		}
+		processed := st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil, nil)
+		require.Len(t, processed, 2)
+		for _, s := range processed {
+			require.NotEqual(t, models.StateReasonMissingSeries, s.StateReason)
+		}
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 2)
+	})
+}
+
 func TestDeleteStateByRuleUID(t *testing.T) {
 	interval := time.Minute
 	ctx := context.Background()
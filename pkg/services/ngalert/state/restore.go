@@ -0,0 +1,63 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+const (
+	restoreNamespace = "alerting.state"
+	restoreKey       = "restored_rules"
+)
+
+// Restore is the latest restore of the alert state of an organization from a snapshot.
+type Restore struct {
+	// ID identifies the restore. It changes with every restore.
+	ID       string   `json:"id"`
+	RuleUIDs []string `json:"ruleUids"`
+}
+
+// RestoreStore records the restores of alert state snapshots in the database. A snapshot is restored by one instance,
+// but every instance of the cluster must load the restored state of the rules that it evaluates.
+type RestoreStore struct {
+	kv kvstore.KVStore
+}
+
+func NewRestoreStore(kv kvstore.KVStore) *RestoreStore {
+	return &RestoreStore{kv: kv}
+}
+
+// Record records that the state of the rules was restored. It replaces the previous restore of the organization.
+func (s *RestoreStore) Record(ctx context.Context, orgID int64, ruleUIDs []string) error {
+	b, err := json.Marshal(Restore{ID: uuid.NewString(), RuleUIDs: ruleUIDs})
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, restoreNamespace, restoreKey, string(b))
+}
+
+// Latest returns the latest restore of every organization that restored a snapshot.
+func (s *RestoreStore) Latest(ctx context.Context) (map[int64]Restore, error) {
+	all, err := s.kv.GetAll(ctx, kvstore.AllOrganizations, restoreNamespace)
+	if err != nil {
+		return nil, err
+	}
+	restores := make(map[int64]Restore, len(all))
+	for orgID, values := range all {
+		value, ok := values[restoreKey]
+		if !ok {
+			continue
+		}
+		var r Restore
+		if err := json.Unmarshal([]byte(value), &r); err != nil {
+			return nil, fmt.Errorf("invalid restore of organization %d: %w", orgID, err)
+		}
+		restores[orgID] = r
+	}
+	return restores, nil
+}
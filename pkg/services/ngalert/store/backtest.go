@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// alertRuleBacktest represents a record in alert_rule_backtest table
+type alertRuleBacktest struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	UID         string `xorm:"uid"`
+	RuleUID     string `xorm:"rule_uid"`
+	RuleVersion int64  `xorm:"rule_version"`
+	Rule        string `xorm:"rule"`
+	FromTime    time.Time
+	ToTime      time.Time
+	Status      string
+	Error       string
+	Created     time.Time
+	Started     *time.Time
+	Finished    *time.Time
+	CreatedBy   string `xorm:"created_by"`
+	Result      string
+}
+
+func (a alertRuleBacktest) TableName() string {
+	return "alert_rule_backtest"
+}
+
+func (st DBstore) InsertBacktestRun(ctx context.Context, run *models.BacktestRun) error {
+	row, err := backtestRunToRecord(run)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(row); err != nil {
+			return fmt.Errorf("failed to insert backtest run: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateBacktestRun updates the status, the timestamps and the result of the run.
+func (st DBstore) UpdateBacktestRun(ctx context.Context, run *models.BacktestRun) error {
+	row, err := backtestRunToRecord(run)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", run.OrgID, run.UID).
+			Cols("status", "error", "started", "finished", "result").
+			Update(row)
+		if err != nil {
+			return fmt.Errorf("failed to update backtest run: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrBacktestRunNotFound
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetBacktestRun(ctx context.Context, orgID int64, uid string) (*models.BacktestRun, error) {
+	var result *models.BacktestRun
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		row := alertRuleBacktest{}
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&row)
+		if err != nil {
+			return fmt.Errorf("failed to get backtest run: %w", err)
+		}
+		if !exists {
+			return models.ErrBacktestRunNotFound
+		}
+		result, err = backtestRunFromRecord(row)
+		return err
+	})
+	return result, err
+}
+
+// ListBacktestRuns returns the runs that match the query, most recent first. Results of the runs are not loaded.
+func (st DBstore) ListBacktestRuns(ctx context.Context, query models.ListBacktestRunsQuery) ([]*models.BacktestRun, error) {
+	var result []*models.BacktestRun
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(alertRuleBacktest{}).Omit("result").Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		q = q.Desc("created", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		var rows []alertRuleBacktest
+		if err := q.Find(&rows); err != nil {
+			return fmt.Errorf("failed to list backtest runs: %w", err)
+		}
+		result = make([]*models.BacktestRun, 0, len(rows))
+		for _, row := range rows {
+			run, err := backtestRunFromRecord(row)
+			if err != nil {
+				return err
+			}
+			result = append(result, run)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func backtestRunToRecord(run *models.BacktestRun) (*alertRuleBacktest, error) {
+	rule, err := json.Marshal(run.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal backtested rule: %w", err)
+	}
+	row := &alertRuleBacktest{
+		OrgID:       run.OrgID,
+		UID:         run.UID,
+		RuleUID:     run.RuleUID,
+		RuleVersion: run.RuleVersion,
+		Rule:        string(rule),
+		FromTime:    run.From,
+		ToTime:      run.To,
+		Status:      string(run.Status),
+		Error:       run.Error,
+		Created:     run.Created,
+		Started:     run.Started,
+		Finished:    run.Finished,
+		CreatedBy:   run.CreatedBy,
+	}
+	if run.Result != nil {
+		result, err := json.Marshal(run.Result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal backtest result: %w", err)
+		}
+		row.Result = string(result)
+	}
+	return row, nil
+}
+
+func backtestRunFromRecord(row alertRuleBacktest) (*models.BacktestRun, error) {
+	run := &models.BacktestRun{
+		UID:         row.UID,
+		OrgID:       row.OrgID,
+		RuleUID:     row.RuleUID,
+		RuleVersion: row.RuleVersion,
+		From:        row.FromTime,
+		To:          row.ToTime,
+		Status:      models.BacktestRunStatus(row.Status),
+		Error:       row.Error,
+		Created:     row.Created,
+		Started:     row.Started,
+		Finished:    row.Finished,
+		CreatedBy:   row.CreatedBy,
+	}
+	if err := json.Unmarshal([]byte(row.Rule), &run.Rule); err != nil {
+		return nil, fmt.Errorf("failed to parse backtested rule of run %s: %w", row.UID, err)
+	}
+	if row.Result != "" {
+		run.Result = &models.BacktestResult{}
+		if err := json.Unmarshal([]byte(row.Result), run.Result); err != nil {
+			return nil, fmt.Errorf("failed to parse result of backtest run %s: %w", row.UID, err)
+		}
+	}
+	return run, nil
+}
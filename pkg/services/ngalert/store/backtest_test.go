@@ -0,0 +1,100 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationBacktestRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	// our database schema uses second precision for timestamps
+	now := time.Now().UTC().Truncate(time.Second)
+	newRun := func(orgID int64, ruleUID string, created time.Time) *models.BacktestRun {
+		return &models.BacktestRun{
+			UID:     util.GenerateShortUID(),
+			OrgID:   orgID,
+			RuleUID: ruleUID,
+			Rule: models.BacktestRule{
+				Title:           "test",
+				Condition:       "A",
+				Data:            []models.AlertQuery{models.GenerateAlertQuery()},
+				IntervalSeconds: 60,
+				KeepFiringFor:   time.Minute,
+			},
+			From:    now.Add(-time.Hour),
+			To:      now,
+			Status:  models.BacktestRunPending,
+			Created: created,
+		}
+	}
+
+	run := newRun(1, "rule-1", now)
+	require.NoError(t, dbstore.InsertBacktestRun(ctx, run))
+	require.NoError(t, dbstore.InsertBacktestRun(ctx, newRun(1, "rule-2", now.Add(time.Second))))
+	require.NoError(t, dbstore.InsertBacktestRun(ctx, newRun(2, "rule-1", now)))
+
+	t.Run("should get run", func(t *testing.T) {
+		stored, err := dbstore.GetBacktestRun(ctx, 1, run.UID)
+		require.NoError(t, err)
+		require.Equal(t, run.UID, stored.UID)
+		require.Equal(t, models.BacktestRunPending, stored.Status)
+		require.Equal(t, run.Rule.Title, stored.Rule.Title)
+		require.Equal(t, run.Rule.KeepFiringFor, stored.Rule.KeepFiringFor)
+		require.Nil(t, stored.Result)
+
+		_, err = dbstore.GetBacktestRun(ctx, 2, run.UID)
+		require.ErrorIs(t, err, models.ErrBacktestRunNotFound)
+	})
+
+	t.Run("should update status and result", func(t *testing.T) {
+		finished := now.Add(time.Minute)
+		run.Status = models.BacktestRunCompleted
+		run.Finished = &finished
+		run.Result = &models.BacktestResult{
+			Evaluations: 60,
+			Instances: []models.BacktestInstance{
+				{
+					Labels:        data.Labels{"test": "1"},
+					Transitions:   []models.BacktestTransition{{Time: now, State: "Alerting"}},
+					Notifications: models.BacktestNotifications{Firing: 1},
+				},
+			},
+		}
+		require.NoError(t, dbstore.UpdateBacktestRun(ctx, run))
+
+		stored, err := dbstore.GetBacktestRun(ctx, 1, run.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.BacktestRunCompleted, stored.Status)
+		require.Equal(t, run.Result, stored.Result)
+		require.NotNil(t, stored.Finished)
+	})
+
+	t.Run("should list runs without results", func(t *testing.T) {
+		runs, err := dbstore.ListBacktestRuns(ctx, models.ListBacktestRunsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.Equal(t, "rule-2", runs[0].RuleUID) // most recent first
+		for _, r := range runs {
+			require.Nil(t, r.Result)
+		}
+
+		runs, err = dbstore.ListBacktestRuns(ctx, models.ListBacktestRunsQuery{OrgID: 1, RuleUID: "rule-1"})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, run.UID, runs[0].UID)
+	})
+}
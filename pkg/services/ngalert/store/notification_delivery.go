@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// notificationDeliveryBatchSize limits the number of rows inserted or deleted by a single statement.
+const notificationDeliveryBatchSize = 500
+
+// alertNotificationDelivery represents a record in alert_notification_delivery table
+type alertNotificationDelivery struct {
+	ID               int64  `xorm:"pk autoincr 'id'"`
+	OrgID            int64  `xorm:"org_id"`
+	Receiver         string `xorm:"receiver"`
+	Integration      string `xorm:"integration"`
+	IntegrationIndex int    `xorm:"integration_index"`
+	IntegrationUID   string `xorm:"integration_uid"`
+	GroupKey         string `xorm:"group_key"`
+	GroupKeyHash     string `xorm:"group_key_hash"`
+	Attempt          int    `xorm:"attempt"`
+	Status           string `xorm:"status"`
+	StatusCode       int    `xorm:"status_code"`
+	Response         string `xorm:"response"`
+	Error            string `xorm:"error"`
+	Retry            bool   `xorm:"retry"`
+	DurationMs       int64  `xorm:"duration_ms"`
+	FiringAlerts     int    `xorm:"firing_alerts"`
+	ResolvedAlerts   int    `xorm:"resolved_alerts"`
+	EpochNano        int64  `xorm:"epoch_nano"`
+}
+
+func (a alertNotificationDelivery) TableName() string {
+	return "alert_notification_delivery"
+}
+
+// groupKeyHash returns the hash of the group key that is used to find deliveries of a group,
+// because group keys can be longer than the indexed columns allow.
+func groupKeyHash(groupKey string) string {
+	h := sha256.Sum256([]byte(groupKey))
+	return hex.EncodeToString(h[:])
+}
+
+// InsertNotificationDeliveries saves the notification delivery records to alert_notification_delivery table.
+func (st DBstore) InsertNotificationDeliveries(ctx context.Context, deliveries []models.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	rows := make([]alertNotificationDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		rows = append(rows, alertNotificationDelivery{
+			OrgID:            d.OrgID,
+			Receiver:         d.Receiver,
+			Integration:      d.Integration,
+			IntegrationIndex: d.IntegrationIndex,
+			IntegrationUID:   d.IntegrationUID,
+			GroupKey:         d.GroupKey,
+			GroupKeyHash:     groupKeyHash(d.GroupKey),
+			Attempt:          d.Attempt,
+			Status:           string(d.Status),
+			StatusCode:       d.StatusCode,
+			Response:         d.Response,
+			Error:            d.Error,
+			Retry:            d.Retry,
+			DurationMs:       d.Duration.Milliseconds(),
+			FiringAlerts:     d.FiringAlerts,
+			ResolvedAlerts:   d.ResolvedAlerts,
+			EpochNano:        d.Timestamp.UnixNano(),
+		})
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(rows); start += notificationDeliveryBatchSize {
+			end := min(start+notificationDeliveryBatchSize, len(rows))
+			batch := rows[start:end]
+			if _, err := sess.InsertMulti(&batch); err != nil {
+				return fmt.Errorf("failed to insert notification deliveries: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindNotificationDeliveries returns the notification delivery records that match the query, most recent first.
+func (st DBstore) FindNotificationDeliveries(ctx context.Context, query models.NotificationDeliveriesQuery) ([]models.NotificationDelivery, error) {
+	if query.Receivers != nil && len(query.Receivers) == 0 {
+		return []models.NotificationDelivery{}, nil
+	}
+	var rows []alertNotificationDelivery
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(alertNotificationDelivery{}).Where("org_id = ?", query.OrgID)
+		if !query.From.IsZero() {
+			q = q.And("epoch_nano >= ?", query.From.UnixNano())
+		}
+		if !query.To.IsZero() {
+			q = q.And("epoch_nano <= ?", query.To.UnixNano())
+		}
+		if len(query.Receivers) > 0 {
+			q = q.In("receiver", query.Receivers)
+		}
+		if query.Integration != "" {
+			q = q.And("integration = ?", query.Integration)
+		}
+		if query.GroupKey != "" {
+			q = q.And("group_key_hash = ?", groupKeyHash(query.GroupKey))
+		}
+		if query.Status != "" {
+			q = q.And("status = ?", string(query.Status))
+		}
+		q = q.OrderBy("epoch_nano DESC, id DESC")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		if err := q.Find(&rows); err != nil {
+			return fmt.Errorf("failed to find notification deliveries: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.NotificationDelivery, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, models.NotificationDelivery{
+			ID:               row.ID,
+			OrgID:            row.OrgID,
+			Receiver:         row.Receiver,
+			Integration:      row.Integration,
+			IntegrationIndex: row.IntegrationIndex,
+			IntegrationUID:   row.IntegrationUID,
+			GroupKey:         row.GroupKey,
+			Attempt:          row.Attempt,
+			Status:           models.NotificationDeliveryStatus(row.Status),
+			StatusCode:       row.StatusCode,
+			Response:         row.Response,
+			Error:            row.Error,
+			Retry:            row.Retry,
+			Duration:         time.Duration(row.DurationMs) * time.Millisecond,
+			FiringAlerts:     row.FiringAlerts,
+			ResolvedAlerts:   row.ResolvedAlerts,
+			Timestamp:        time.Unix(0, row.EpochNano).UTC(),
+		})
+	}
+	return result, nil
+}
+
+// DeleteNotificationDeliveriesBefore deletes notification delivery records that are older than the given time.
+// It returns the number of deleted records.
+func (st DBstore) DeleteNotificationDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var ids []int64
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(alertNotificationDelivery{}).Cols("id").Where("epoch_nano < ?", before.UnixNano()).
+				OrderBy("id").Limit(notificationDeliveryBatchSize).Find(&ids)
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to find expired notification deliveries: %w", err)
+		}
+		if len(ids) > 0 {
+			err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+				n, err := sess.Table(alertNotificationDelivery{}).In("id", ids).Delete(alertNotificationDelivery{})
+				total += n
+				return err
+			})
+			if err != nil {
+				return total, fmt.Errorf("failed to delete notification deliveries: %w", err)
+			}
+		}
+		if len(ids) < notificationDeliveryBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationNotificationDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	delivery := func(receiver, integration, groupKey string, attempt int, status models.NotificationDeliveryStatus, ts time.Time) models.NotificationDelivery {
+		return models.NotificationDelivery{
+			OrgID:        1,
+			Receiver:     receiver,
+			Integration:  integration,
+			GroupKey:     groupKey,
+			Attempt:      attempt,
+			Status:       status,
+			Duration:     150 * time.Millisecond,
+			FiringAlerts: 1,
+			Timestamp:    ts,
+		}
+	}
+
+	failed := delivery("on-call", "slack", "{}:{alertname=\"a\"}", 1, models.NotificationDeliveryFailure, base)
+	failed.StatusCode = 500
+	failed.Response = "upstream unavailable"
+	failed.Error = "webhook response status 500 Internal Server Error"
+	failed.Retry = true
+	require.NoError(t, dbstore.InsertNotificationDeliveries(ctx, []models.NotificationDelivery{
+		failed,
+		delivery("on-call", "slack", "{}:{alertname=\"a\"}", 2, models.NotificationDeliverySuccess, base.Add(time.Second)),
+		delivery("on-call", "email", "{}:{alertname=\"a\"}", 1, models.NotificationDeliverySuccess, base.Add(2*time.Second)),
+		delivery("team", "webhook", "{}:{alertname=\"b\"}", 1, models.NotificationDeliverySuccess, base.Add(3*time.Second)),
+	}))
+
+	find := func(q models.NotificationDeliveriesQuery) []models.NotificationDelivery {
+		q.OrgID = 1
+		res, err := dbstore.FindNotificationDeliveries(ctx, q)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("should filter deliveries", func(t *testing.T) {
+		res := find(models.NotificationDeliveriesQuery{})
+		require.Len(t, res, 4)
+		require.Equal(t, "team", res[0].Receiver) // most recent first
+
+		res = find(models.NotificationDeliveriesQuery{GroupKey: "{}:{alertname=\"a\"}", Integration: "slack"})
+		require.Len(t, res, 2)
+		require.Equal(t, 2, res[0].Attempt)
+		require.Equal(t, models.NotificationDeliveryFailure, res[1].Status)
+		require.Equal(t, 500, res[1].StatusCode)
+		require.Equal(t, "upstream unavailable", res[1].Response)
+		require.Equal(t, "webhook response status 500 Internal Server Error", res[1].Error)
+		require.True(t, res[1].Retry)
+		require.Equal(t, 150*time.Millisecond, res[1].Duration)
+		require.Equal(t, base, res[1].Timestamp)
+
+		require.Len(t, find(models.NotificationDeliveriesQuery{Status: models.NotificationDeliveryFailure}), 1)
+		require.Len(t, find(models.NotificationDeliveriesQuery{Receivers: []string{"team"}}), 1)
+		require.Empty(t, find(models.NotificationDeliveriesQuery{Receivers: []string{}}))
+		require.Len(t, find(models.NotificationDeliveriesQuery{From: base.Add(time.Second), To: base.Add(2 * time.Second)}), 2)
+		require.Len(t, find(models.NotificationDeliveriesQuery{Limit: 3}), 3)
+	})
+
+	t.Run("should delete deliveries older than the given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteNotificationDeliveriesBefore(ctx, base.Add(2*time.Second))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+		require.Len(t, find(models.NotificationDeliveriesQuery{}), 2)
+	})
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// alertSilenceTemplate represents a record in alert_silence_template table
+type alertSilenceTemplate struct {
+	ID              int64     `xorm:"pk autoincr 'id'"`
+	OrgID           int64     `xorm:"org_id"`
+	UID             string    `xorm:"uid"`
+	Name            string    `xorm:"name"`
+	Matchers        string    `xorm:"matchers"`
+	Comment         string    `xorm:"comment"`
+	DurationSeconds int64     `xorm:"duration_seconds"`
+	Schedule        string    `xorm:"schedule"`
+	Timezone        string    `xorm:"timezone"`
+	CreatedBy       string    `xorm:"created_by"`
+	LastOccurrence  int64     `xorm:"last_occurrence"`
+	SilenceID       string    `xorm:"silence_id"`
+	Updated         time.Time `xorm:"updated"`
+}
+
+func (a alertSilenceTemplate) TableName() string {
+	return "alert_silence_template"
+}
+
+// ListSilenceTemplates returns the silence templates of the organization sorted by name.
+func (st DBstore) ListSilenceTemplates(ctx context.Context, orgID int64) ([]models.SilenceTemplate, error) {
+	var rows []alertSilenceTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).OrderBy("name").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list silence templates: %w", err)
+	}
+	return silenceTemplatesFromRows(rows)
+}
+
+// ListRecurringSilenceTemplates returns the silence templates with a schedule in all organizations.
+func (st DBstore) ListRecurringSilenceTemplates(ctx context.Context) ([]models.SilenceTemplate, error) {
+	var rows []alertSilenceTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("schedule IS NOT NULL AND schedule <> ''").OrderBy("org_id, id").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring silence templates: %w", err)
+	}
+	return silenceTemplatesFromRows(rows)
+}
+
+// GetSilenceTemplate returns the silence template with the given UID, or models.ErrSilenceTemplateNotFound.
+func (st DBstore) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (models.SilenceTemplate, error) {
+	var row alertSilenceTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrSilenceTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return models.SilenceTemplate{}, err
+	}
+	return silenceTemplateFromRow(row)
+}
+
+// InsertSilenceTemplate saves a new silence template and returns it. A UID is generated if the template does not have one.
+func (st DBstore) InsertSilenceTemplate(ctx context.Context, t models.SilenceTemplate) (models.SilenceTemplate, error) {
+	if t.UID == "" {
+		t.UID = util.GenerateShortUID()
+	}
+	t.Updated = TimeNow()
+	row, err := silenceTemplateToRow(t)
+	if err != nil {
+		return models.SilenceTemplate{}, err
+	}
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(&row); err != nil {
+			if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+				return models.ErrSilenceTemplateExists
+			}
+			return fmt.Errorf("failed to insert silence template: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.SilenceTemplate{}, err
+	}
+	t.ID = row.ID
+	return t, nil
+}
+
+// UpdateSilenceTemplate replaces the silence template with the same UID.
+func (st DBstore) UpdateSilenceTemplate(ctx context.Context, t models.SilenceTemplate) error {
+	t.Updated = TimeNow()
+	row, err := silenceTemplateToRow(t)
+	if err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		n, err := sess.Table(alertSilenceTemplate{}).Where("org_id = ? AND uid = ?", t.OrgID, t.UID).
+			Cols("name", "matchers", "comment", "duration_seconds", "schedule", "timezone", "created_by", "last_occurrence", "silence_id", "updated").
+			Update(&row)
+		if err != nil {
+			if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+				return models.ErrSilenceTemplateExists
+			}
+			return fmt.Errorf("failed to update silence template: %w", err)
+		}
+		if n == 0 {
+			return models.ErrSilenceTemplateNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteSilenceTemplate deletes the silence template with the given UID. It does not fail if the template does not exist.
+func (st DBstore) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(alertSilenceTemplate{})
+		return err
+	})
+}
+
+// ClaimSilenceTemplateOccurrence sets the latest occurrence of a recurring silence template if it is still the previous one,
+// and clears the ID of its silence. It returns false if another instance has already claimed the occurrence.
+func (st DBstore) ClaimSilenceTemplateOccurrence(ctx context.Context, orgID int64, uid string, previous, occurrence time.Time) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		n, err := sess.Table(alertSilenceTemplate{}).
+			Where("org_id = ? AND uid = ? AND last_occurrence = ?", orgID, uid, unixOrZero(previous)).
+			Cols("last_occurrence", "silence_id").
+			Update(&alertSilenceTemplate{LastOccurrence: unixOrZero(occurrence), SilenceID: ""})
+		claimed = n > 0
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim occurrence of silence template: %w", err)
+	}
+	return claimed, nil
+}
+
+// SetSilenceTemplateSilenceID saves the ID of the silence created for the given occurrence of a recurring silence template.
+func (st DBstore) SetSilenceTemplateSilenceID(ctx context.Context, orgID int64, uid string, occurrence time.Time, silenceID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table(alertSilenceTemplate{}).
+			Where("org_id = ? AND uid = ? AND last_occurrence = ?", orgID, uid, unixOrZero(occurrence)).
+			Cols("silence_id").
+			Update(&alertSilenceTemplate{SilenceID: silenceID})
+		if err != nil {
+			return fmt.Errorf("failed to save silence ID of silence template: %w", err)
+		}
+		return nil
+	})
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func silenceTemplateToRow(t models.SilenceTemplate) (alertSilenceTemplate, error) {
+	matchers, err := json.Marshal(t.Matchers)
+	if err != nil {
+		return alertSilenceTemplate{}, fmt.Errorf("failed to marshal matchers of silence template: %w", err)
+	}
+	return alertSilenceTemplate{
+		ID:              t.ID,
+		OrgID:           t.OrgID,
+		UID:             t.UID,
+		Name:            t.Name,
+		Matchers:        string(matchers),
+		Comment:         t.Comment,
+		DurationSeconds: int64(t.Duration / time.Second),
+		Schedule:        t.Schedule,
+		Timezone:        t.Timezone,
+		CreatedBy:       t.CreatedBy,
+		LastOccurrence:  unixOrZero(t.LastOccurrence),
+		SilenceID:       t.SilenceID,
+		Updated:         t.Updated,
+	}, nil
+}
+
+func silenceTemplateFromRow(row alertSilenceTemplate) (models.SilenceTemplate, error) {
+	var matchers amv2.Matchers
+	if err := json.Unmarshal([]byte(row.Matchers), &matchers); err != nil {
+		return models.SilenceTemplate{}, fmt.Errorf("failed to unmarshal matchers of silence template %s: %w", row.UID, err)
+	}
+	t := models.SilenceTemplate{
+		ID:        row.ID,
+		UID:       row.UID,
+		OrgID:     row.OrgID,
+		Name:      row.Name,
+		Matchers:  matchers,
+		Comment:   row.Comment,
+		Duration:  time.Duration(row.DurationSeconds) * time.Second,
+		Schedule:  row.Schedule,
+		Timezone:  row.Timezone,
+		CreatedBy: row.CreatedBy,
+		SilenceID: row.SilenceID,
+		Updated:   row.Updated,
+	}
+	if row.LastOccurrence > 0 {
+		t.LastOccurrence = time.Unix(row.LastOccurrence, 0).UTC()
+	}
+	return t, nil
+}
+
+func silenceTemplatesFromRows(rows []alertSilenceTemplate) ([]models.SilenceTemplate, error) {
+	result := make([]models.SilenceTemplate, 0, len(rows))
+	for _, row := range rows {
+		t, err := silenceTemplateFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
@@ -0,0 +1,324 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// stateHistoryBatchSize limits the number of rows read or deleted by a single statement.
+// It is kept below the SQLite limit of 999 parameters per statement.
+const stateHistoryBatchSize = 500
+
+// alertStateHistory represents a record in alert_state_history table
+type alertStateHistory struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	RuleID        int64  `xorm:"rule_id"`
+	RuleTitle     string `xorm:"rule_title"`
+	RuleGroup     string `xorm:"rule_group"`
+	NamespaceUID  string `xorm:"namespace_uid"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       int64  `xorm:"panel_id"`
+	RuleCondition string `xorm:"rule_condition"`
+	Fingerprint   string `xorm:"fingerprint"`
+	Labels        string `xorm:"labels"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	Error         string `xorm:"error"`
+	StateValues   string `xorm:"state_values"`
+	EpochNano     int64  `xorm:"epoch_nano"`
+	Downsampled   bool   `xorm:"downsampled"`
+}
+
+func (a alertStateHistory) TableName() string {
+	return "alert_state_history"
+}
+
+// InsertStateHistoryEntries saves the state transitions to alert_state_history table.
+func (st DBstore) InsertStateHistoryEntries(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	rows := make([]alertStateHistory, 0, len(entries))
+	for _, e := range entries {
+		lbls, err := json.Marshal(e.Labels)
+		if err != nil {
+			return fmt.Errorf("failed to serialize labels: %w", err)
+		}
+		rows = append(rows, alertStateHistory{
+			OrgID:         e.OrgID,
+			RuleUID:       e.RuleUID,
+			RuleID:        e.RuleID,
+			RuleTitle:     e.RuleTitle,
+			RuleGroup:     e.RuleGroup,
+			NamespaceUID:  e.NamespaceUID,
+			DashboardUID:  e.DashboardUID,
+			PanelID:       e.PanelID,
+			RuleCondition: e.Condition,
+			Fingerprint:   e.Fingerprint,
+			Labels:        string(lbls),
+			PreviousState: e.Previous,
+			CurrentState:  e.Current,
+			Error:         e.Error,
+			StateValues:   e.Values,
+			EpochNano:     e.Timestamp.UnixNano(),
+		})
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(rows); start += stateHistoryBatchSize {
+			end := min(start+stateHistoryBatchSize, len(rows))
+			batch := rows[start:end]
+			if _, err := sess.InsertMulti(&batch); err != nil {
+				return fmt.Errorf("failed to insert state history entries: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindStateHistoryEntries returns state history entries that match the query, most recent first.
+// Labels are matched exactly, and the state matches the formatted current state by prefix.
+func (st DBstore) FindStateHistoryEntries(ctx context.Context, query models.StateHistoryEntriesQuery) ([]models.StateHistoryEntry, error) {
+	var result []models.StateHistoryEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		// Labels are stored as a serialized map and cannot be filtered by the database.
+		// Read the matching rows page by page and filter them until the limit is reached.
+		for offset := 0; ; offset += stateHistoryBatchSize {
+			q := sess.Table(alertStateHistory{}).Where("org_id = ?", query.OrgID)
+			if !query.From.IsZero() {
+				q = q.And("epoch_nano >= ?", query.From.UnixNano())
+			}
+			if !query.To.IsZero() {
+				q = q.And("epoch_nano <= ?", query.To.UnixNano())
+			}
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.DashboardUID != "" {
+				q = q.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if query.PanelID != 0 {
+				q = q.And("panel_id = ?", query.PanelID)
+			}
+			if query.State != "" {
+				q = q.And("current_state "+st.SQLStore.GetDialect().LikeStr()+" ?", query.State+"%")
+			}
+			if len(query.NamespaceUIDs) > 0 {
+				q = q.In("namespace_uid", query.NamespaceUIDs)
+			}
+
+			var rows []alertStateHistory
+			if err := q.OrderBy("epoch_nano DESC, id DESC").Limit(stateHistoryBatchSize, offset).Find(&rows); err != nil {
+				return fmt.Errorf("failed to find state history entries: %w", err)
+			}
+			for _, row := range rows {
+				entry, err := stateHistoryEntryFromRecord(row)
+				if err != nil {
+					return err
+				}
+				if !matchesLabels(entry.Labels, query.Labels) {
+					continue
+				}
+				result = append(result, entry)
+				if query.Limit > 0 && len(result) >= query.Limit {
+					return nil
+				}
+			}
+			if len(rows) < stateHistoryBatchSize {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteStateHistoryEntriesBefore deletes state history entries that are older than the given time.
+// It returns the number of deleted entries.
+func (st DBstore) DeleteStateHistoryEntriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var ids []int64
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(alertStateHistory{}).Cols("id").Where("epoch_nano < ?", before.UnixNano()).
+				OrderBy("id").Limit(stateHistoryBatchSize).Find(&ids)
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to find expired state history entries: %w", err)
+		}
+		affected, err := st.deleteStateHistoryEntries(ctx, ids)
+		total += affected
+		if err != nil {
+			return total, err
+		}
+		if len(ids) < stateHistoryBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// DownsampleStateHistoryEntries reduces the entries older than the given time to at most one transition
+// per alert instance in each time bucket of the given interval. The most recent transition in a bucket is kept,
+// and its previous state is replaced by the previous state of the first transition in the bucket, so the history
+// remains continuous. Buckets that were downsampled are marked and not processed again.
+// It returns the number of deleted entries.
+func (st DBstore) DownsampleStateHistoryEntries(ctx context.Context, before time.Time, interval time.Duration) (int64, error) {
+	if interval <= 0 {
+		return 0, fmt.Errorf("downsample interval must be positive")
+	}
+	// Only downsample complete buckets.
+	before = before.Truncate(interval)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var oldest []int64
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(alertStateHistory{}).Cols("epoch_nano").
+				Where("downsampled = ? AND epoch_nano < ?", false, before.UnixNano()).
+				OrderBy("epoch_nano").Limit(1).Find(&oldest)
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to find state history entries to downsample: %w", err)
+		}
+		if len(oldest) == 0 {
+			return total, nil
+		}
+		from := time.Unix(0, oldest[0]).Truncate(interval)
+		affected, err := st.downsampleStateHistoryBucket(ctx, from, from.Add(interval))
+		total += affected
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+func (st DBstore) downsampleStateHistoryBucket(ctx context.Context, from, to time.Time) (int64, error) {
+	var affected int64
+	err := st.InTransaction(ctx, func(ctx context.Context) error {
+		return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			var rows []alertStateHistory
+			err := sess.Cols("id", "org_id", "rule_uid", "fingerprint", "previous_state", "epoch_nano").
+				Where("epoch_nano >= ? AND epoch_nano < ?", from.UnixNano(), to.UnixNano()).
+				OrderBy("epoch_nano, id").Find(&rows)
+			if err != nil {
+				return fmt.Errorf("failed to read state history entries: %w", err)
+			}
+
+			type instanceKey struct {
+				orgID       int64
+				ruleUID     string
+				fingerprint string
+			}
+			type bucket struct {
+				first, last alertStateHistory
+			}
+			buckets := make(map[instanceKey]*bucket)
+			var toDelete []int64
+			for _, row := range rows {
+				key := instanceKey{orgID: row.OrgID, ruleUID: row.RuleUID, fingerprint: row.Fingerprint}
+				b, ok := buckets[key]
+				if !ok {
+					buckets[key] = &bucket{first: row, last: row}
+					continue
+				}
+				toDelete = append(toDelete, b.last.ID)
+				b.last = row
+			}
+
+			for _, b := range buckets {
+				if b.first.ID == b.last.ID || b.first.PreviousState == b.last.PreviousState {
+					continue
+				}
+				_, err := sess.Table(alertStateHistory{}).ID(b.last.ID).Cols("previous_state").
+					Update(&alertStateHistory{PreviousState: b.first.PreviousState})
+				if err != nil {
+					return fmt.Errorf("failed to update state history entry: %w", err)
+				}
+			}
+
+			for start := 0; start < len(toDelete); start += stateHistoryBatchSize {
+				end := min(start+stateHistoryBatchSize, len(toDelete))
+				n, err := sess.Table(alertStateHistory{}).In("id", toDelete[start:end]).Delete(alertStateHistory{})
+				if err != nil {
+					return fmt.Errorf("failed to delete state history entries: %w", err)
+				}
+				affected += n
+			}
+
+			_, err = sess.Table(alertStateHistory{}).Where("epoch_nano >= ? AND epoch_nano < ?", from.UnixNano(), to.UnixNano()).
+				Cols("downsampled").Update(&alertStateHistory{Downsampled: true})
+			if err != nil {
+				return fmt.Errorf("failed to mark state history entries as downsampled: %w", err)
+			}
+			return nil
+		})
+	})
+	return affected, err
+}
+
+func (st DBstore) deleteStateHistoryEntries(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var affected int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		n, err := sess.Table(alertStateHistory{}).In("id", ids).Delete(alertStateHistory{})
+		if err != nil {
+			return fmt.Errorf("failed to delete state history entries: %w", err)
+		}
+		affected = n
+		return nil
+	})
+	return affected, err
+}
+
+func stateHistoryEntryFromRecord(row alertStateHistory) (models.StateHistoryEntry, error) {
+	var lbls data.Labels
+	if err := json.Unmarshal([]byte(row.Labels), &lbls); err != nil {
+		return models.StateHistoryEntry{}, fmt.Errorf("failed to parse labels of state history entry %d: %w", row.ID, err)
+	}
+	return models.StateHistoryEntry{
+		ID:           row.ID,
+		OrgID:        row.OrgID,
+		RuleUID:      row.RuleUID,
+		RuleID:       row.RuleID,
+		RuleTitle:    row.RuleTitle,
+		RuleGroup:    row.RuleGroup,
+		NamespaceUID: row.NamespaceUID,
+		DashboardUID: row.DashboardUID,
+		PanelID:      row.PanelID,
+		Condition:    row.RuleCondition,
+		Fingerprint:  row.Fingerprint,
+		Labels:       lbls,
+		Previous:     row.PreviousState,
+		Current:      row.CurrentState,
+		Error:        row.Error,
+		Values:       row.StateValues,
+		Timestamp:    time.Unix(0, row.EpochNano).UTC(),
+	}, nil
+}
+
+func matchesLabels(lbls data.Labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if lbls[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationStateHistoryEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(ruleUID, fingerprint string, lbls data.Labels, previous, current string, ts time.Time) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:        1,
+			RuleUID:      ruleUID,
+			RuleTitle:    "test",
+			RuleGroup:    "group",
+			NamespaceUID: "folder-" + ruleUID,
+			Condition:    "A",
+			Fingerprint:  fingerprint,
+			Labels:       lbls,
+			Previous:     previous,
+			Current:      current,
+			Timestamp:    ts,
+		}
+	}
+
+	a := data.Labels{"instance": "a"}
+	b := data.Labels{"instance": "b"}
+	require.NoError(t, dbstore.InsertStateHistoryEntries(ctx, []models.StateHistoryEntry{
+		entry("rule-1", "a", a, "Normal", "Pending", base),
+		entry("rule-1", "a", a, "Pending", "Alerting", base.Add(time.Minute)),
+		entry("rule-1", "a", a, "Alerting", "Normal", base.Add(2*time.Minute)),
+		entry("rule-1", "b", b, "Normal", "Alerting (Error)", base.Add(3*time.Minute)),
+		entry("rule-2", "a", a, "Normal", "Alerting", base.Add(4*time.Minute)),
+	}))
+
+	t.Run("should filter entries", func(t *testing.T) {
+		find := func(q models.HistoryQuery) []models.StateHistoryEntry {
+			q.OrgID = 1
+			res, err := dbstore.FindStateHistoryEntries(ctx, models.StateHistoryEntriesQuery{HistoryQuery: q})
+			require.NoError(t, err)
+			return res
+		}
+
+		res := find(models.HistoryQuery{})
+		require.Len(t, res, 5)
+		require.Equal(t, "rule-2", res[0].RuleUID) // most recent first
+		require.Equal(t, base.Add(4*time.Minute), res[0].Timestamp)
+
+		require.Len(t, find(models.HistoryQuery{RuleUID: "rule-1"}), 4)
+		require.Len(t, find(models.HistoryQuery{RuleUID: "rule-1", Labels: map[string]string{"instance": "a"}}), 3)
+		require.Len(t, find(models.HistoryQuery{State: "Alerting"}), 3)
+		require.Len(t, find(models.HistoryQuery{From: base.Add(time.Minute), To: base.Add(2 * time.Minute)}), 2)
+		require.Len(t, find(models.HistoryQuery{Limit: 2}), 2)
+
+		res, err := dbstore.FindStateHistoryEntries(ctx, models.StateHistoryEntriesQuery{
+			HistoryQuery:  models.HistoryQuery{OrgID: 1},
+			NamespaceUIDs: []string{"folder-rule-2"},
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, a, res[0].Labels)
+	})
+
+	t.Run("should downsample entries", func(t *testing.T) {
+		deleted, err := dbstore.DownsampleStateHistoryEntries(ctx, base.Add(10*time.Minute), 10*time.Minute)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		res, err := dbstore.FindStateHistoryEntries(ctx, models.StateHistoryEntriesQuery{HistoryQuery: models.HistoryQuery{OrgID: 1, RuleUID: "rule-1", Labels: map[string]string{"instance": "a"}}})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "Normal", res[0].Previous)
+		require.Equal(t, "Normal", res[0].Current)
+
+		// downsampled buckets are not processed again
+		deleted, err = dbstore.DownsampleStateHistoryEntries(ctx, base.Add(10*time.Minute), time.Minute)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+
+	t.Run("should delete entries older than the given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteStateHistoryEntriesBefore(ctx, base.Add(4*time.Minute))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		res, err := dbstore.FindStateHistoryEntries(ctx, models.StateHistoryEntriesQuery{HistoryQuery: models.HistoryQuery{OrgID: 1}})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "rule-2", res[0].RuleUID)
+	})
+}
@@ -0,0 +1,199 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+// BatchSettings configures how a BatchWriter buffers points and retries failed writes.
+type BatchSettings struct {
+	// Size is the number of buffered points that triggers a write.
+	Size int
+	// FlushInterval is the maximum time points are buffered before they are written.
+	FlushInterval time.Duration
+	// MaxRetries is the number of times a failed write is retried before the points are dropped.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry. It doubles with every retry.
+	RetryBackoff time.Duration
+}
+
+func NewBatchSettings(settings setting.RecordingRuleSettings) (BatchSettings, error) {
+	if settings.BatchSize <= 0 {
+		return BatchSettings{}, fmt.Errorf("batch size must be greater than 0")
+	}
+	if settings.FlushInterval <= 0 {
+		return BatchSettings{}, fmt.Errorf("flush interval must be greater than 0")
+	}
+	if settings.MaxRetries < 0 {
+		return BatchSettings{}, fmt.Errorf("max retries must not be negative")
+	}
+	if settings.MaxRetries > 0 && settings.RetryBackoff <= 0 {
+		return BatchSettings{}, fmt.Errorf("retry backoff must be greater than 0")
+	}
+	return BatchSettings{
+		Size:          settings.BatchSize,
+		FlushInterval: settings.FlushInterval,
+		MaxRetries:    settings.MaxRetries,
+		RetryBackoff:  settings.RetryBackoff,
+	}, nil
+}
+
+// pointSink writes points of a single organization to a target.
+type pointSink interface {
+	// writePoints returns the status of the write, which is used as the status_code label of the write metrics.
+	// Writes that fail with ErrRejectedWrite are not retried.
+	writePoints(ctx context.Context, orgID int64, points []Point) (string, error)
+}
+
+// BatchWriter buffers the points of recording rules and writes them to a target in batches.
+// Failed writes are retried with exponential backoff.
+type BatchWriter struct {
+	target  string
+	sink    pointSink
+	cfg     BatchSettings
+	clock   clock.Clock
+	logger  log.Logger
+	metrics *metrics.RemoteWriter
+
+	mtx     sync.Mutex
+	pending map[int64][]Point
+	size    int
+
+	// flushMtx serializes flushes so that batches are written in the order they were buffered.
+	flushMtx sync.Mutex
+}
+
+func newBatchWriter(target string, sink pointSink, cfg BatchSettings, clock clock.Clock, l log.Logger, m *metrics.RemoteWriter) *BatchWriter {
+	return &BatchWriter{
+		target:  target,
+		sink:    sink,
+		cfg:     cfg,
+		clock:   clock,
+		logger:  l,
+		metrics: m,
+		pending: make(map[int64][]Point),
+	}
+}
+
+// Write buffers the points of the given frames. When the buffer is full, all buffered points are written
+// and the error of the write is returned.
+func (w *BatchWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	w.mtx.Lock()
+	w.pending[orgID] = append(w.pending[orgID], points...)
+	w.size += len(points)
+	full := w.size >= w.cfg.Size
+	w.mtx.Unlock()
+
+	if !full {
+		return nil
+	}
+	return w.Flush(ctx)
+}
+
+// Flush writes all buffered points.
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	w.flushMtx.Lock()
+	defer w.flushMtx.Unlock()
+
+	w.mtx.Lock()
+	pending := w.pending
+	w.pending = make(map[int64][]Point)
+	w.size = 0
+	w.mtx.Unlock()
+
+	var errs []error
+	for orgID, points := range pending {
+		for len(points) > 0 {
+			n := min(len(points), w.cfg.Size)
+			if err := w.send(ctx, orgID, points[:n]); err != nil {
+				errs = append(errs, err)
+			}
+			points = points[n:]
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *BatchWriter) send(ctx context.Context, orgID int64, points []Point) error {
+	l := w.logger.FromContext(ctx)
+	org := fmt.Sprint(orgID)
+	w.metrics.BatchSize.WithLabelValues(w.target).Observe(float64(len(points)))
+
+	backoff := w.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		writeStart := w.clock.Now()
+		status, err := w.sink.writePoints(ctx, orgID, points)
+		w.metrics.WriteDuration.WithLabelValues(org, w.target).Observe(w.clock.Now().Sub(writeStart).Seconds())
+		w.metrics.WritesTotal.WithLabelValues(org, w.target, status).Inc()
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, ErrRejectedWrite) || attempt >= w.cfg.MaxRetries || ctx.Err() != nil {
+			w.metrics.DroppedPointsTotal.WithLabelValues(org, w.target).Add(float64(len(points)))
+			return err
+		}
+
+		l.Warn("Failed to write points, retrying", "target", w.target, "org", orgID, "attempt", attempt+1, "backoff", backoff, "error", err)
+		w.metrics.RetriesTotal.WithLabelValues(org, w.target).Inc()
+		select {
+		case <-ctx.Done():
+			w.metrics.DroppedPointsTotal.WithLabelValues(org, w.target).Add(float64(len(points)))
+			return errors.Join(err, ctx.Err())
+		case <-w.clock.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Run writes the buffered points every flush interval until the context is cancelled.
+// The remaining points are written before it returns.
+func (w *BatchWriter) Run(ctx context.Context) error {
+	ticker := w.clock.Ticker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Flush(ctx); err != nil {
+				w.logger.Error("Failed to write recording rule points", "target", w.target, "error", err)
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.cfg.FlushInterval)
+			defer cancel()
+			if err := w.Flush(flushCtx); err != nil {
+				w.logger.Error("Failed to write recording rule points on shutdown", "target", w.target, "error", err)
+			}
+			if c, ok := w.sink.(io.Closer); ok {
+				if err := c.Close(); err != nil {
+					w.logger.Warn("Failed to close recording rule target", "target", w.target, "error", err)
+				}
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewBatchSettings(t *testing.T) {
+	valid := setting.RecordingRuleSettings{BatchSize: 10, FlushInterval: time.Second, MaxRetries: 2, RetryBackoff: time.Millisecond}
+	cfg, err := NewBatchSettings(valid)
+	require.NoError(t, err)
+	require.Equal(t, BatchSettings{Size: 10, FlushInterval: time.Second, MaxRetries: 2, RetryBackoff: time.Millisecond}, cfg)
+
+	for name, mutate := range map[string]func(*setting.RecordingRuleSettings){
+		"zero batch size":       func(s *setting.RecordingRuleSettings) { s.BatchSize = 0 },
+		"zero flush interval":   func(s *setting.RecordingRuleSettings) { s.FlushInterval = 0 },
+		"negative retries":      func(s *setting.RecordingRuleSettings) { s.MaxRetries = -1 },
+		"retries without delay": func(s *setting.RecordingRuleSettings) { s.RetryBackoff = 0 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := valid
+			mutate(&s)
+			_, err := NewBatchSettings(s)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBatchWriter(t *testing.T) {
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "1"}, {"foo": "2"}})
+	single := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "1"}})
+	now := time.Now()
+
+	t.Run("buffers points until the batch is full", func(t *testing.T) {
+		sink := &fakeSink{}
+		w, _ := newTestBatchWriter(t, sink, BatchSettings{Size: 3, FlushInterval: time.Minute})
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, frames, 1, nil))
+		require.Empty(t, sink.batches())
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, frames, 2, nil))
+		batches := sink.batches()
+		require.Len(t, batches, 2)
+		for _, b := range batches {
+			require.Len(t, b.points, 2)
+		}
+		require.ElementsMatch(t, []int64{1, 2}, []int64{batches[0].orgID, batches[1].orgID})
+	})
+
+	t.Run("splits buffered points into batches of the configured size", func(t *testing.T) {
+		sink := &fakeSink{}
+		w, _ := newTestBatchWriter(t, sink, BatchSettings{Size: 3, FlushInterval: time.Minute})
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, frames, 1, nil))
+		require.NoError(t, w.Write(context.Background(), "metric", now, frames, 1, nil))
+
+		batches := sink.batches()
+		require.Len(t, batches, 2)
+		require.Len(t, batches[0].points, 3)
+		require.Len(t, batches[1].points, 1)
+	})
+
+	t.Run("retries unexpected failures", func(t *testing.T) {
+		sink := &fakeSink{errs: []error{ErrUnexpectedWriteFailure, ErrUnexpectedWriteFailure}}
+		w, m := newTestBatchWriter(t, sink, BatchSettings{Size: 1, FlushInterval: time.Minute, MaxRetries: 2, RetryBackoff: time.Millisecond})
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, single, 1, nil))
+
+		require.Len(t, sink.batches(), 3)
+		require.Equal(t, 2.0, testutil.ToFloat64(m.RetriesTotal.WithLabelValues("1", "test")))
+		require.Equal(t, 2.0, testutil.ToFloat64(m.WritesTotal.WithLabelValues("1", "test", statusError)))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.WritesTotal.WithLabelValues("1", "test", statusOK)))
+		require.Zero(t, testutil.ToFloat64(m.DroppedPointsTotal.WithLabelValues("1", "test")))
+	})
+
+	t.Run("drops points when retries are exhausted", func(t *testing.T) {
+		sink := &fakeSink{errs: []error{ErrUnexpectedWriteFailure, ErrUnexpectedWriteFailure}}
+		w, m := newTestBatchWriter(t, sink, BatchSettings{Size: 1, FlushInterval: time.Minute, MaxRetries: 1, RetryBackoff: time.Millisecond})
+
+		err := w.Write(context.Background(), "metric", now, single, 1, nil)
+
+		require.ErrorIs(t, err, ErrUnexpectedWriteFailure)
+		require.Len(t, sink.batches(), 2)
+		require.Equal(t, 1.0, testutil.ToFloat64(m.DroppedPointsTotal.WithLabelValues("1", "test")))
+	})
+
+	t.Run("does not retry rejected writes", func(t *testing.T) {
+		sink := &fakeSink{errs: []error{ErrRejectedWrite}}
+		w, m := newTestBatchWriter(t, sink, BatchSettings{Size: 1, FlushInterval: time.Minute, MaxRetries: 3, RetryBackoff: time.Millisecond})
+
+		err := w.Write(context.Background(), "metric", now, single, 1, nil)
+
+		require.ErrorIs(t, err, ErrRejectedWrite)
+		require.Len(t, sink.batches(), 1)
+		require.Zero(t, testutil.ToFloat64(m.RetriesTotal.WithLabelValues("1", "test")))
+	})
+
+	t.Run("returns bad frame error without buffering", func(t *testing.T) {
+		sink := &fakeSink{}
+		w, _ := newTestBatchWriter(t, sink, BatchSettings{Size: 1, FlushInterval: time.Minute})
+
+		err := w.Write(context.Background(), "metric", now, data.Frames{data.NewFrame("")}, 1, nil)
+
+		require.ErrorIs(t, err, ErrBadFrame)
+		require.NoError(t, w.Flush(context.Background()))
+		require.Empty(t, sink.batches())
+	})
+
+	t.Run("Run writes buffered points periodically and on shutdown", func(t *testing.T) {
+		sink := &fakeSink{}
+		clk := clock.NewMock()
+		w, _ := newTestBatchWriter(t, sink, BatchSettings{Size: 100, FlushInterval: time.Minute})
+		w.clock = clk
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			require.NoError(t, w.Run(ctx))
+		}()
+
+		require.NoError(t, w.Write(ctx, "metric", now, frames, 1, nil))
+		require.Eventually(t, func() bool {
+			clk.Add(time.Minute)
+			return len(sink.batches()) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, w.Write(ctx, "metric", now, frames, 1, nil))
+		cancel()
+		<-done
+		require.Len(t, sink.batches(), 2)
+		require.True(t, sink.isClosed())
+	})
+}
+
+func newTestBatchWriter(t *testing.T, sink pointSink, cfg BatchSettings) (*BatchWriter, *metrics.RemoteWriter) {
+	t.Helper()
+	m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+	return newBatchWriter("test", sink, cfg, clock.New(), log.NewNopLogger(), m), m
+}
+
+type sinkBatch struct {
+	orgID  int64
+	points []Point
+}
+
+type fakeSink struct {
+	mtx     sync.Mutex
+	errs    []error
+	written []sinkBatch
+	closed  bool
+}
+
+func (s *fakeSink) writePoints(_ context.Context, orgID int64, points []Point) (string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.written = append(s.written, sinkBatch{orgID: orgID, points: points})
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return statusError, errors.Join(err, errors.New("test error"))
+	}
+	return statusOK, nil
+}
+
+func (s *fakeSink) batches() []sinkBatch {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]sinkBatch(nil), s.written...)
+}
+
+func (s *fakeSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) isClosed() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.closed
+}
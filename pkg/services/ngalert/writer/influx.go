@@ -0,0 +1,144 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const influxTarget = "influxdb"
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	influxTagEscaper         = strings.NewReplacer(`,`, `\,`, ` `, `\ `, `=`, `\=`, "\n", `\n`)
+)
+
+// influxSink writes points to an InfluxDB compatible write endpoint using the line protocol.
+// The settings URL must contain the full write path and parameters, such as the database or bucket.
+type influxSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewInfluxWriter creates a writer that writes the points of recording rules to InfluxDB in batches.
+func NewInfluxWriter(
+	settings setting.RecordingRuleSettings,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*BatchWriter, error) {
+	if err := validateSettings(settings); err != nil {
+		return nil, err
+	}
+	if settings.URL == "" {
+		return nil, fmt.Errorf("URL is required")
+	}
+	cfg, err := NewBatchSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(http.Header)
+	for k, v := range settings.CustomHeaders {
+		headers.Add(k, v)
+	}
+	timeouts := httpclient.DefaultTimeoutOptions
+	timeouts.Timeout = settings.Timeout
+	cl, err := httpClientProvider.New(httpclient.Options{
+		BasicAuth: createAuthOpts(settings.BasicAuthUsername, settings.BasicAuthPassword),
+		Header:    headers,
+		Timeouts:  &timeouts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &influxSink{client: cl, url: settings.URL}
+	return newBatchWriter(influxTarget, sink, cfg, clock, l, metrics), nil
+}
+
+func (s *influxSink) writePoints(ctx context.Context, _ int64, points []Point) (string, error) {
+	body := encodeLineProtocol(points)
+	if body.Len() == 0 {
+		return statusOK, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, body)
+	if err != nil {
+		return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "grafana-recording-rule")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	status := strconv.Itoa(res.StatusCode)
+	if res.StatusCode/100 == 2 {
+		return status, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	err = fmt.Errorf("unexpected status code %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	// Other client errors, such as an invalid line or a field type conflict, are the fault of the data.
+	if res.StatusCode/100 == 4 && res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusRequestTimeout {
+		return status, errors.Join(ErrRejectedWrite, err)
+	}
+	return status, errors.Join(ErrUnexpectedWriteFailure, err)
+}
+
+// encodeLineProtocol encodes points as InfluxDB line protocol with nanosecond precision.
+// The name of the point is used as the measurement, the labels as tags and the value as the "value" field.
+// Points with NaN or infinite values are skipped because InfluxDB cannot store them.
+func encodeLineProtocol(points []Point) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	for _, p := range points {
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			continue
+		}
+
+		buf.WriteString(influxMeasurementEscaper.Replace(p.Name))
+
+		keys := make([]string, 0, len(p.Labels))
+		for k, v := range p.Labels {
+			// Tags with empty values are not allowed.
+			if v != "" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			buf.WriteByte(',')
+			buf.WriteString(influxTagEscaper.Replace(k))
+			buf.WriteByte('=')
+			buf.WriteString(influxTagEscaper.Replace(p.Labels[k]))
+		}
+
+		buf.WriteString(" value=")
+		buf.WriteString(strconv.FormatFloat(p.Metric.V, 'g', -1, 64))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(p.Metric.T.UnixNano(), 10))
+		buf.WriteByte('\n')
+	}
+	return buf
+}
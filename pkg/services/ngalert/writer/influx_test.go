@@ -0,0 +1,84 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestEncodeLineProtocol(t *testing.T) {
+	ts := time.Unix(10, 5)
+	points := []Point{
+		{Name: "cpu usage", Labels: map[string]string{"host": "a,b", "z": "1=2", "empty": ""}, Metric: Metric{T: ts, V: 1.5}},
+		{Name: "nan", Labels: map[string]string{}, Metric: Metric{T: ts, V: math.NaN()}},
+		{Name: "mem", Labels: map[string]string{}, Metric: Metric{T: ts, V: 2}},
+	}
+
+	require.Equal(t,
+		"cpu\\ usage,host=a\\,b,z=1\\=2 value=1.5 10000000005\nmem value=2 10000000005\n",
+		encodeLineProtocol(points).String(),
+	)
+}
+
+func TestInfluxWriter(t *testing.T) {
+	var status int
+	var body string
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(b)
+		header = r.Header
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	settings := setting.RecordingRuleSettings{
+		URL:           srv.URL + "/api/v2/write?bucket=metrics&precision=ns",
+		Timeout:       time.Second,
+		CustomHeaders: map[string]string{"Authorization": "Token secret"},
+		BatchSize:     1,
+		FlushInterval: time.Minute,
+	}
+	w, err := NewInfluxWriter(settings, httpclient.NewProvider(), clock.New(), log.NewNopLogger(), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "bar"}})
+
+	t.Run("writes line protocol", func(t *testing.T) {
+		status = http.StatusNoContent
+		require.NoError(t, w.Write(context.Background(), "metric", time.Unix(1, 0), frames, 1, map[string]string{"extra": "label"}))
+		require.Regexp(t, `^metric,extra=label,foo=bar value=\S+ 1000000000\n$`, body)
+		require.Equal(t, "Token secret", header.Get("Authorization"))
+	})
+
+	t.Run("rejects writes on client errors", func(t *testing.T) {
+		status = http.StatusBadRequest
+		require.ErrorIs(t, w.Write(context.Background(), "metric", time.Unix(1, 0), frames, 1, nil), ErrRejectedWrite)
+	})
+
+	t.Run("fails on server errors", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		require.ErrorIs(t, w.Write(context.Background(), "metric", time.Unix(1, 0), frames, 1, nil), ErrUnexpectedWriteFailure)
+	})
+
+	t.Run("requires URL", func(t *testing.T) {
+		s := settings
+		s.URL = ""
+		_, err := NewInfluxWriter(s, httpclient.NewProvider(), clock.New(), log.NewNopLogger(), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,110 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const liveTarget = "live"
+
+var (
+	liveNamespaceRegex    = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+	liveInvalidPathChars  = regexp.MustCompile(`[^A-Za-z0-9_\-=.]`)
+	errLiveNotInitialized = errors.New("grafana live is not initialized")
+)
+
+// LiveStreamPublisher pushes frames to Grafana Live managed streams.
+type LiveStreamPublisher interface {
+	PushFrame(ctx context.Context, orgID int64, namespace, path string, frame *data.Frame) error
+}
+
+// liveSink pushes points to the stream/<namespace>/<metric> channels of Grafana Live.
+// Every push contains a frame with a single row that holds the values of all series of a metric at the same time.
+type liveSink struct {
+	publisher LiveStreamPublisher
+	namespace string
+}
+
+// NewLiveWriter creates a writer that pushes the points of recording rules to Grafana Live managed streams in batches.
+func NewLiveWriter(
+	settings setting.RecordingRuleSettings,
+	publisher LiveStreamPublisher,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*BatchWriter, error) {
+	if publisher == nil {
+		return nil, errLiveNotInitialized
+	}
+	if !liveNamespaceRegex.MatchString(settings.LiveNamespace) {
+		return nil, fmt.Errorf("invalid live namespace %q", settings.LiveNamespace)
+	}
+	cfg, err := NewBatchSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &liveSink{publisher: publisher, namespace: settings.LiveNamespace}
+	return newBatchWriter(liveTarget, sink, cfg, clock, l, metrics), nil
+}
+
+func (s *liveSink) writePoints(ctx context.Context, orgID int64, points []Point) (string, error) {
+	for _, frame := range pointsToLiveFrames(points) {
+		if err := s.publisher.PushFrame(ctx, orgID, s.namespace, liveChannelPath(frame.Name), frame); err != nil {
+			return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+		}
+	}
+	return statusOK, nil
+}
+
+// liveChannelPath replaces the characters of a metric name that are not allowed in the path of a channel.
+func liveChannelPath(name string) string {
+	return liveInvalidPathChars.ReplaceAllString(name, "_")
+}
+
+// pointsToLiveFrames groups points by metric name and time, and returns a frame for every group
+// in the order of the time of the points.
+func pointsToLiveFrames(points []Point) []*data.Frame {
+	type groupKey struct {
+		name string
+		t    int64
+	}
+	groups := make(map[groupKey][]Point)
+	keys := make([]groupKey, 0)
+	for _, p := range points {
+		key := groupKey{name: p.Name, t: p.Metric.T.UnixNano()}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].t < keys[j].t
+	})
+
+	frames := make([]*data.Frame, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		sort.Slice(group, func(i, j int) bool {
+			return data.Labels(group[i].Labels).String() < data.Labels(group[j].Labels).String()
+		})
+		fields := make([]*data.Field, 0, len(group)+1)
+		fields = append(fields, data.NewField("time", nil, []time.Time{group[0].Metric.T}))
+		for _, p := range group {
+			fields = append(fields, data.NewField("value", data.Labels(p.Labels), []float64{p.Metric.V}))
+		}
+		frames = append(frames, data.NewFrame(key.name, fields...))
+	}
+	return frames
+}
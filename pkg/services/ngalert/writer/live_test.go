@@ -0,0 +1,79 @@
+package writer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLiveWriter(t *testing.T) {
+	settings := setting.RecordingRuleSettings{LiveNamespace: "recording_rules", BatchSize: 100, FlushInterval: time.Minute}
+	m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+
+	t.Run("pushes a frame per metric and time", func(t *testing.T) {
+		publisher := &fakeLivePublisher{}
+		w, err := NewLiveWriter(settings, publisher, clock.New(), log.NewNopLogger(), m)
+		require.NoError(t, err)
+		frames := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "2"}, {"foo": "1"}})
+
+		require.NoError(t, w.Write(context.Background(), "job:requests:rate5m", time.Unix(2, 0), frames, 1, nil))
+		require.NoError(t, w.Write(context.Background(), "job:requests:rate5m", time.Unix(1, 0), frames, 1, nil))
+		require.NoError(t, w.Write(context.Background(), "other", time.Unix(1, 0), frames, 1, nil))
+		require.NoError(t, w.Flush(context.Background()))
+
+		require.Len(t, publisher.pushes, 3)
+		for _, p := range publisher.pushes {
+			require.EqualValues(t, 1, p.orgID)
+			require.Equal(t, "recording_rules", p.namespace)
+		}
+		require.Equal(t, "job_requests_rate5m", publisher.pushes[0].path)
+		require.Equal(t, time.Unix(1, 0), publisher.pushes[0].frame.Fields[0].At(0))
+		require.Equal(t, time.Unix(2, 0), publisher.pushes[2].frame.Fields[0].At(0))
+
+		frame := publisher.pushes[0].frame
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, data.Labels{"foo": "1"}, frame.Fields[1].Labels)
+		require.Equal(t, data.Labels{"foo": "2"}, frame.Fields[2].Labels)
+	})
+
+	t.Run("requires Grafana Live", func(t *testing.T) {
+		_, err := NewLiveWriter(settings, nil, clock.New(), log.NewNopLogger(), m)
+		require.ErrorIs(t, err, errLiveNotInitialized)
+	})
+
+	t.Run("validates namespace", func(t *testing.T) {
+		s := settings
+		s.LiveNamespace = "invalid/namespace"
+		_, err := NewLiveWriter(s, &fakeLivePublisher{}, clock.New(), log.NewNopLogger(), m)
+		require.Error(t, err)
+	})
+}
+
+type livePush struct {
+	orgID     int64
+	namespace string
+	path      string
+	frame     *data.Frame
+}
+
+type fakeLivePublisher struct {
+	mtx    sync.Mutex
+	pushes []livePush
+}
+
+func (p *fakeLivePublisher) PushFrame(_ context.Context, orgID int64, namespace, path string, frame *data.Frame) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.pushes = append(p.pushes, livePush{orgID: orgID, namespace: namespace, path: path, frame: frame})
+	return nil
+}
@@ -0,0 +1,172 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/benbjohnson/clock"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	sqlTarget = "sql"
+
+	SQLDriverPostgres = "postgres"
+	SQLDriverMySQL    = "mysql"
+
+	// sqlMaxRowsPerStatement keeps the number of parameters of a single insert statement
+	// well below the limits of the databases.
+	sqlMaxRowsPerStatement = 1000
+)
+
+var sqlTableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// sqlSink writes points as rows of a PostgreSQL or MySQL table. The table is created on the first write if it does not exist.
+// Labels are stored as a JSON object, and NaN or infinite values are stored as NULL.
+type sqlSink struct {
+	db     *sql.DB
+	driver string
+	table  string
+
+	mtx          sync.Mutex
+	tableCreated bool
+}
+
+// NewSQLWriter creates a writer that writes the points of recording rules to a PostgreSQL or MySQL table in batches.
+func NewSQLWriter(
+	settings setting.RecordingRuleSettings,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*BatchWriter, error) {
+	if settings.SQLDriver != SQLDriverPostgres && settings.SQLDriver != SQLDriverMySQL {
+		return nil, fmt.Errorf("unsupported SQL driver %q, must be one of: %s, %s", settings.SQLDriver, SQLDriverPostgres, SQLDriverMySQL)
+	}
+	if settings.SQLConnectionString == "" {
+		return nil, fmt.Errorf("SQL connection string is required")
+	}
+	if !sqlTableNameRegex.MatchString(settings.SQLTable) {
+		return nil, fmt.Errorf("invalid SQL table name %q", settings.SQLTable)
+	}
+	cfg, err := NewBatchSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Opening the database does not connect to it, so Grafana can start while the database is unavailable.
+	db, err := sql.Open(settings.SQLDriver, settings.SQLConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQL database: %w", err)
+	}
+
+	sink := newSQLSink(db, settings.SQLDriver, settings.SQLTable)
+	return newBatchWriter(sqlTarget, sink, cfg, clock, l, metrics), nil
+}
+
+func newSQLSink(db *sql.DB, driver, table string) *sqlSink {
+	return &sqlSink{db: db, driver: driver, table: table}
+}
+
+func (s *sqlSink) writePoints(ctx context.Context, orgID int64, points []Point) (string, error) {
+	if err := s.ensureTable(ctx); err != nil {
+		return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	for len(points) > 0 {
+		n := min(len(points), sqlMaxRowsPerStatement)
+		query, args, err := s.insertStatement(orgID, points[:n])
+		if err != nil {
+			_ = tx.Rollback()
+			return statusError, errors.Join(ErrBadFrame, err)
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			_ = tx.Rollback()
+			return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+		}
+		points = points[n:]
+	}
+	if err := tx.Commit(); err != nil {
+		return statusError, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	return statusOK, nil
+}
+
+func (s *sqlSink) ensureTable(ctx context.Context) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.tableCreated {
+		return nil
+	}
+
+	valueType, tsType := "DOUBLE PRECISION", "TIMESTAMPTZ"
+	if s.driver == SQLDriverMySQL {
+		valueType, tsType = "DOUBLE", "DATETIME(6)"
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (org_id BIGINT NOT NULL, name VARCHAR(190) NOT NULL, labels TEXT NOT NULL, value %s NULL, ts %s NOT NULL)", s.table, valueType, tsType)
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.table, err)
+	}
+	s.tableCreated = true
+	return nil
+}
+
+func (s *sqlSink) insertStatement(orgID int64, points []Point) (string, []any, error) {
+	const columns = 5
+	sb := strings.Builder{}
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(s.table)
+	sb.WriteString(" (org_id, name, labels, value, ts) VALUES ")
+
+	args := make([]any, 0, len(points)*columns)
+	for i, p := range points {
+		lbls, err := json.Marshal(p.Labels)
+		if err != nil {
+			return "", nil, err
+		}
+		var value sql.NullFloat64
+		if !math.IsNaN(p.Metric.V) && !math.IsInf(p.Metric.V, 0) {
+			value = sql.NullFloat64{Float64: p.Metric.V, Valid: true}
+		}
+
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j := 0; j < columns; j++ {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(s.placeholder(i*columns + j + 1))
+		}
+		sb.WriteByte(')')
+		args = append(args, orgID, p.Name, string(lbls), value, p.Metric.T.UTC())
+	}
+	return sb.String(), args, nil
+}
+
+func (s *sqlSink) placeholder(n int) string {
+	if s.driver == SQLDriverPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (s *sqlSink) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,114 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSQLSink(t *testing.T) {
+	ts := time.Unix(100, 0)
+	points := []Point{
+		{Name: "a", Labels: map[string]string{"x": "1"}, Metric: Metric{T: ts, V: 1}},
+		{Name: "b", Labels: map[string]string{}, Metric: Metric{T: ts, V: math.Inf(1)}},
+	}
+
+	t.Run("postgres", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		sink := newSQLSink(db, SQLDriverPostgres, "metrics")
+
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS metrics (org_id BIGINT NOT NULL, name VARCHAR(190) NOT NULL, labels TEXT NOT NULL, value DOUBLE PRECISION NULL, ts TIMESTAMPTZ NOT NULL)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO metrics (org_id, name, labels, value, ts) VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)")).
+			WithArgs(int64(1), "a", `{"x":"1"}`, sql.NullFloat64{Float64: 1, Valid: true}, ts.UTC(), int64(1), "b", `{}`, sql.NullFloat64{}, ts.UTC()).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+		// The table is created only once.
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO metrics").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		status, err := sink.writePoints(context.Background(), 1, points)
+		require.NoError(t, err)
+		require.Equal(t, statusOK, status)
+		_, err = sink.writePoints(context.Background(), 1, points[:1])
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		sink := newSQLSink(db, SQLDriverMySQL, "metrics")
+
+		mock.ExpectExec(regexp.QuoteMeta("value DOUBLE NULL, ts DATETIME(6) NOT NULL)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("VALUES (?, ?, ?, ?, ?)")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err = sink.writePoints(context.Background(), 1, points[:1])
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back on failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		sink := newSQLSink(db, SQLDriverPostgres, "metrics")
+		sink.tableCreated = true
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO metrics").WillReturnError(driver.ErrBadConn)
+		mock.ExpectRollback()
+
+		status, err := sink.writePoints(context.Background(), 1, points)
+		require.ErrorIs(t, err, ErrUnexpectedWriteFailure)
+		require.True(t, errors.Is(err, driver.ErrBadConn))
+		require.Equal(t, statusError, status)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewSQLWriter(t *testing.T) {
+	valid := setting.RecordingRuleSettings{
+		SQLDriver:           SQLDriverPostgres,
+		SQLConnectionString: "postgres://localhost/metrics",
+		SQLTable:            "public.recording_rules",
+		BatchSize:           10,
+		FlushInterval:       time.Second,
+	}
+	m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+
+	w, err := NewSQLWriter(valid, clock.New(), log.NewNopLogger(), m)
+	require.NoError(t, err)
+	require.NotNil(t, w)
+
+	for name, mutate := range map[string]func(*setting.RecordingRuleSettings){
+		"unknown driver":     func(s *setting.RecordingRuleSettings) { s.SQLDriver = "sqlite3" },
+		"missing connection": func(s *setting.RecordingRuleSettings) { s.SQLConnectionString = "" },
+		"invalid table":      func(s *setting.RecordingRuleSettings) { s.SQLTable = "metrics; DROP TABLE users" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := valid
+			mutate(&s)
+			_, err := NewSQLWriter(s, clock.New(), log.NewNopLogger(), m)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/promotion"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type PromotionAPI struct {
+	promotionService promotion.Service
+	ac               accesscontrol.AccessControl
+}
+
+func New(promotionService promotion.Service, ac accesscontrol.AccessControl) *PromotionAPI {
+	return &PromotionAPI{
+		promotionService: promotionService,
+		ac:               ac,
+	}
+}
+
+func (api *PromotionAPI) RegisterAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := accesscontrol.Middleware(api.ac)
+	routeRegister.Group("/api/promotion", func(route routing.RouteRegister) {
+		route.Post(
+			"/export",
+			authorize(accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(dashboards.ActionFoldersRead),
+				accesscontrol.EvalPermission(dashboards.ActionDashboardsRead),
+			)),
+			routing.Wrap(api.Export),
+		)
+		route.Post(
+			"/import",
+			authorize(accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(dashboards.ActionFoldersCreate),
+				accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate),
+			)),
+			routing.Wrap(api.Import),
+		)
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /promotion/export promotion exportBundle
+//
+// Export a folder tree with its dependencies as a bundle.
+//
+// Responses:
+// 200: exportBundleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *PromotionAPI) Export(c *contextmodel.ReqContext) response.Response {
+	req := promotion.ExportRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	req.User = c.SignedInUser
+	bundle, err := api.promotionService.Export(c.Req.Context(), &req)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to export bundle", err)
+	}
+
+	return response.JSON(http.StatusOK, bundle)
+}
+
+// swagger:route POST /promotion/import promotion importBundle
+//
+// Import a bundle, or return the plan of the import with dryRun.
+//
+// Responses:
+// 200: importBundleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *PromotionAPI) Import(c *contextmodel.ReqContext) response.Response {
+	req := promotion.ImportRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if req.Bundle != nil && (len(req.Bundle.AlertRuleGroups) > 0 || len(req.Bundle.ContactPoints) > 0) {
+		// the alerting provisioning services do not check the permissions of every resource they write
+		hasAccess, err := api.ac.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(accesscontrol.ActionAlertingProvisioningWrite))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !hasAccess {
+			return response.Error(http.StatusForbidden, "Importing alert rules and contact points requires the alerting provisioning permission", nil)
+		}
+	}
+
+	req.User = c.SignedInUser
+	resp, err := api.promotionService.Import(c.Req.Context(), &req)
+	if err != nil {
+		if errors.Is(err, utils.ErrDashboardInputMissing) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to import bundle", err)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
+// swagger:parameters exportBundle
+type ExportBundleParams struct {
+	// in:body
+	// required:true
+	Body promotion.ExportRequest
+}
+
+// swagger:response exportBundleResponse
+type ExportBundleResponse struct {
+	// in: body
+	Body promotion.Bundle `json:"body"`
+}
+
+// swagger:parameters importBundle
+type ImportBundleParams struct {
+	// in:body
+	// required:true
+	Body promotion.ImportRequest
+}
+
+// swagger:response importBundleResponse
+type ImportBundleResponse struct {
+	// in: body
+	Body promotion.ImportResponse `json:"body"`
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/promotion"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestPromotionAPI(t *testing.T) {
+	var importRequest *promotion.ImportRequest
+	service := &serviceMock{
+		exportFunc: func(ctx context.Context, req *promotion.ExportRequest) (*promotion.Bundle, error) {
+			return &promotion.Bundle{Version: promotion.BundleVersion, Folders: []promotion.Folder{{UID: req.FolderUID}}}, nil
+		},
+		importFunc: func(ctx context.Context, req *promotion.ImportRequest) (*promotion.ImportResponse, error) {
+			importRequest = req
+			if len(req.Inputs) == 0 {
+				return nil, utils.ErrDashboardInputMissing
+			}
+			return &promotion.ImportResponse{DryRun: req.DryRun, Plan: []promotion.PlanItem{}}, nil
+		},
+	}
+
+	promotionAPI := New(service, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()))
+	routeRegister := routing.NewRouteRegister()
+	promotionAPI.RegisterAPIEndpoints(routeRegister)
+	s := webtest.NewServer(t, routeRegister)
+
+	send := func(t *testing.T, url string, body any, permissions map[string][]string) *http.Response {
+		t.Helper()
+		jsonBytes, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := s.NewPostRequest(url, bytes.NewReader(jsonBytes))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+			UserID:      1,
+			OrgID:       1,
+			Permissions: map[int64]map[string][]string{1: permissions},
+		})
+		resp, err := s.SendJSON(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	importPermissions := map[string][]string{
+		dashboards.ActionFoldersCreate:    {},
+		dashboards.ActionDashboardsCreate: {},
+	}
+
+	t.Run("Export should return the bundle", func(t *testing.T) {
+		resp := send(t, "/api/promotion/export", promotion.ExportRequest{FolderUID: "team"}, map[string][]string{
+			dashboards.ActionFoldersRead:    {dashboards.ScopeFoldersAll},
+			dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsAll},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var bundle promotion.Bundle
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&bundle))
+		require.Equal(t, []promotion.Folder{{UID: "team"}}, bundle.Folders)
+	})
+
+	t.Run("Export without permissions should return 403", func(t *testing.T) {
+		resp := send(t, "/api/promotion/export", promotion.ExportRequest{FolderUID: "team"}, map[string][]string{})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Import with missing inputs should return 400", func(t *testing.T) {
+		resp := send(t, "/api/promotion/import", promotion.ImportRequest{Bundle: &promotion.Bundle{}}, importPermissions)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Import should return the plan", func(t *testing.T) {
+		resp := send(t, "/api/promotion/import", promotion.ImportRequest{
+			Bundle: &promotion.Bundle{},
+			Inputs: []dashboardimport.ImportDashboardInput{{Name: "ENV", Type: promotion.InputTypeConstant, Value: "prod"}},
+			DryRun: true,
+		}, importPermissions)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.True(t, importRequest.DryRun)
+		require.Equal(t, int64(1), importRequest.User.GetOrgID())
+	})
+
+	t.Run("Import of alert rules should require the alerting provisioning permission", func(t *testing.T) {
+		importRequest = nil
+		body := promotion.ImportRequest{
+			Bundle: &promotion.Bundle{AlertRuleGroups: []definitions.AlertRuleGroup{{Title: "group", FolderUID: "team"}}},
+			Inputs: []dashboardimport.ImportDashboardInput{{Name: "ENV", Type: promotion.InputTypeConstant, Value: "prod"}},
+		}
+		resp := send(t, "/api/promotion/import", body, importPermissions)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Nil(t, importRequest)
+
+		resp = send(t, "/api/promotion/import", body, map[string][]string{
+			dashboards.ActionFoldersCreate:                {},
+			dashboards.ActionDashboardsCreate:             {},
+			accesscontrol.ActionAlertingProvisioningWrite: {},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotNil(t, importRequest)
+	})
+}
+
+type serviceMock struct {
+	exportFunc func(ctx context.Context, req *promotion.ExportRequest) (*promotion.Bundle, error)
+	importFunc func(ctx context.Context, req *promotion.ImportRequest) (*promotion.ImportResponse, error)
+}
+
+func (s *serviceMock) Export(ctx context.Context, req *promotion.ExportRequest) (*promotion.Bundle, error) {
+	if s.exportFunc != nil {
+		return s.exportFunc(ctx, req)
+	}
+
+	return nil, nil
+}
+
+func (s *serviceMock) Import(ctx context.Context, req *promotion.ImportRequest) (*promotion.ImportResponse, error) {
+	if s.importFunc != nil {
+		return s.importFunc(ctx, req)
+	}
+
+	return nil, nil
+}
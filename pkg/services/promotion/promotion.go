@@ -0,0 +1,135 @@
+package promotion
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// BundleVersion is the version of the bundle format written by Export.
+const BundleVersion = 1
+
+// Input types of a bundle. They are the same as the input types of the dashboard import.
+const (
+	InputTypeDatasource = "datasource"
+	InputTypeConstant   = "constant"
+)
+
+var (
+	ErrInvalidBundle          = errutil.BadRequest("promotion.invalidBundle")
+	ErrInvalidExportVariable  = errutil.BadRequest("promotion.invalidExportVariable")
+	ErrAlertingNotAvailable   = errutil.BadRequest("promotion.alertingNotAvailable", errutil.WithPublicMessage("Alerting is disabled, the bundle alert rules and contact points cannot be imported"))
+	ErrExportFolderIsRequired = errutil.BadRequest("promotion.folderRequired", errutil.WithPublicMessage("The folder to export is required"))
+)
+
+// Input declares an environment-specific value of a bundle.
+// It is referenced as ${NAME} in the resources of the bundle, and its value is given when importing the bundle.
+type Input struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	PluginID    string `json:"pluginId,omitempty"`
+}
+
+type Folder struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ParentUID   string `json:"parentUid,omitempty"`
+}
+
+type Dashboard struct {
+	FolderUID string           `json:"folderUid"`
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+type LibraryElement struct {
+	UID   string          `json:"uid"`
+	Name  string          `json:"name"`
+	Kind  int64           `json:"kind"`
+	Model json.RawMessage `json:"model"`
+}
+
+// Bundle is a folder tree with the resources it depends on, which can be imported in another Grafana instance.
+// Folders are sorted so that parents come before their children.
+type Bundle struct {
+	Version         int                                `json:"version"`
+	Inputs          []Input                            `json:"inputs"`
+	Folders         []Folder                           `json:"folders"`
+	Dashboards      []Dashboard                        `json:"dashboards"`
+	LibraryElements []LibraryElement                   `json:"libraryElements"`
+	AlertRuleGroups []definitions.AlertRuleGroup       `json:"alertRuleGroups"`
+	ContactPoints   []definitions.EmbeddedContactPoint `json:"contactPoints"`
+}
+
+// ExportVariable turns every occurrence of a value in the exported resources into a constant input.
+type ExportVariable struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+// ExportRequest request object for exporting a folder tree.
+type ExportRequest struct {
+	FolderUID string           `json:"folderUid"`
+	Variables []ExportVariable `json:"variables"`
+
+	User identity.Requester `json:"-"`
+}
+
+// ImportRequest request object for importing a bundle.
+// Inputs give the values of the bundle inputs, and DryRun only returns the plan of the import.
+type ImportRequest struct {
+	Bundle *Bundle                                `json:"bundle"`
+	Inputs []dashboardimport.ImportDashboardInput `json:"inputs"`
+	DryRun bool                                   `json:"dryRun"`
+
+	User identity.Requester `json:"-"`
+}
+
+type Kind string
+
+const (
+	KindFolder         Kind = "folder"
+	KindDashboard      Kind = "dashboard"
+	KindLibraryElement Kind = "libraryElement"
+	KindAlertRuleGroup Kind = "alertRuleGroup"
+	KindContactPoint   Kind = "contactPoint"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	// ActionSkip is used for library elements which already exist, as the dashboard import does not update them.
+	ActionSkip Action = "skip"
+)
+
+// PlanItem describes what the import does with a resource of the bundle.
+// Alert rule groups are identified by their folder and title, the other resources by their UID.
+type PlanItem struct {
+	Kind      Kind   `json:"kind"`
+	UID       string `json:"uid,omitempty"`
+	FolderUID string `json:"folderUid,omitempty"`
+	Title     string `json:"title"`
+	Action    Action `json:"action"`
+}
+
+// ImportResponse response object returned when importing a bundle.
+type ImportResponse struct {
+	DryRun bool       `json:"dryRun"`
+	Plan   []PlanItem `json:"plan"`
+}
+
+// Service service interface for exporting and importing bundles.
+type Service interface {
+	Export(ctx context.Context, req *ExportRequest) (*Bundle, error)
+	Import(ctx context.Context, req *ImportRequest) (*ImportResponse, error)
+}
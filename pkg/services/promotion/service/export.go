@@ -0,0 +1,432 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	ngalertapi "github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/promotion"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+const dashboardsPerPage = 1000
+
+var inputNameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Export collects the folder tree of the request with the resources it depends on.
+// The datasources are turned into datasource inputs, the secure settings of the contact points into constant inputs,
+// and the values of the request variables into constant inputs.
+func (s *PromotionService) Export(ctx context.Context, req *promotion.ExportRequest) (*promotion.Bundle, error) {
+	if req.FolderUID == "" {
+		return nil, promotion.ErrExportFolderIsRequired.Errorf("folder uid is empty")
+	}
+	for _, variable := range req.Variables {
+		if variable.Name == "" || variable.Value == "" {
+			return nil, promotion.ErrInvalidExportVariable.Errorf("export variables must have a name and a value")
+		}
+	}
+
+	e := &exporter{
+		dataSourceService: s.dataSourceService,
+		orgID:             req.User.GetOrgID(),
+		variables:         req.Variables,
+		inputs:            map[string]promotion.Input{},
+		datasourceRefs:    map[string]string{},
+		datasourceInputs:  map[string]string{},
+	}
+	for _, variable := range req.Variables {
+		e.inputs[variable.Name] = promotion.Input{
+			Name:        variable.Name,
+			Label:       variable.Name,
+			Description: variable.Description,
+			Type:        promotion.InputTypeConstant,
+		}
+	}
+
+	bundle := &promotion.Bundle{Version: promotion.BundleVersion}
+	var err error
+	if bundle.Folders, err = s.exportFolders(ctx, req.User, req.FolderUID); err != nil {
+		return nil, err
+	}
+	folderUIDs := make([]string, 0, len(bundle.Folders))
+	for _, f := range bundle.Folders {
+		folderUIDs = append(folderUIDs, f.UID)
+	}
+
+	if bundle.Dashboards, err = s.exportDashboards(ctx, req.User, folderUIDs); err != nil {
+		return nil, err
+	}
+	if bundle.LibraryElements, err = s.exportLibraryElements(ctx, req.User, bundle.Dashboards); err != nil {
+		return nil, err
+	}
+	if s.ruleService != nil {
+		if bundle.AlertRuleGroups, err = s.exportAlertRuleGroups(ctx, req.User, folderUIDs); err != nil {
+			return nil, err
+		}
+		if bundle.ContactPoints, err = s.exportContactPoints(ctx, req.User, bundle.AlertRuleGroups, e); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := e.substitute(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+	result.Inputs = make([]promotion.Input, 0, len(e.inputs))
+	for _, name := range slices.Sorted(maps.Keys(e.inputs)) {
+		result.Inputs = append(result.Inputs, e.inputs[name])
+	}
+	return result, nil
+}
+
+// exportFolders returns the folder and its descendants, parents first.
+// The exported folder is moved to the root, as its parents are not exported.
+func (s *PromotionService) exportFolders(ctx context.Context, user identity.Requester, folderUID string) ([]promotion.Folder, error) {
+	root, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
+		UID:          &folderUID,
+		OrgID:        user.GetOrgID(),
+		SignedInUser: user,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folders := []promotion.Folder{{UID: root.UID, Title: root.Title, Description: root.Description}}
+	for i := 0; i < len(folders); i++ {
+		children, err := s.folderService.GetChildren(ctx, &folder.GetChildrenQuery{
+			UID:          folders[i].UID,
+			OrgID:        user.GetOrgID(),
+			SignedInUser: user,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			folders = append(folders, promotion.Folder{
+				UID:         child.UID,
+				Title:       child.Title,
+				Description: child.Description,
+				ParentUID:   folders[i].UID,
+			})
+		}
+	}
+	return folders, nil
+}
+
+func (s *PromotionService) exportDashboards(ctx context.Context, user identity.Requester, folderUIDs []string) ([]promotion.Dashboard, error) {
+	var uids []string
+	for page := int64(1); ; page++ {
+		hits, err := s.dashboardService.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+			OrgId:        user.GetOrgID(),
+			SignedInUser: user,
+			FolderUIDs:   folderUIDs,
+			Type:         searchstore.TypeDashboard,
+			Limit:        dashboardsPerPage,
+			Page:         page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			uids = append(uids, hit.UID)
+		}
+		if len(hits) < dashboardsPerPage {
+			break
+		}
+	}
+	if len(uids) == 0 {
+		return []promotion.Dashboard{}, nil
+	}
+
+	dashs, err := s.dashboardService.GetDashboards(ctx, &dashboards.GetDashboardsQuery{
+		DashboardUIDs: uids,
+		OrgID:         user.GetOrgID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(dashs, func(i, j int) bool {
+		return dashs[i].UID < dashs[j].UID
+	})
+
+	result := make([]promotion.Dashboard, 0, len(dashs))
+	for _, dash := range dashs {
+		// the id is specific to this instance, the dashboard is imported by its uid
+		dash.Data.Del("id")
+		dash.Data.Set("uid", dash.UID)
+		result = append(result, promotion.Dashboard{FolderUID: dash.FolderUID, Dashboard: dash.Data})
+	}
+	return result, nil
+}
+
+// exportLibraryElements returns the library panels used by the dashboards, wherever they are stored.
+func (s *PromotionService) exportLibraryElements(ctx context.Context, user identity.Requester, dashs []promotion.Dashboard) ([]promotion.LibraryElement, error) {
+	uids := map[string]bool{}
+	for _, dash := range dashs {
+		collectLibraryPanelUIDs(dash.Dashboard.Get("panels").MustArray(), uids)
+	}
+
+	result := make([]promotion.LibraryElement, 0, len(uids))
+	for _, uid := range slices.Sorted(maps.Keys(uids)) {
+		element, err := s.libraryElementService.GetElement(ctx, user, model.GetLibraryElementCommand{
+			UID:        uid,
+			FolderName: dashboards.RootFolderName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get library element %s: %w", uid, err)
+		}
+		result = append(result, promotion.LibraryElement{
+			UID:   element.UID,
+			Name:  element.Name,
+			Kind:  element.Kind,
+			Model: element.Model,
+		})
+	}
+	return result, nil
+}
+
+func collectLibraryPanelUIDs(panels []any, uids map[string]bool) {
+	for _, panel := range panels {
+		panelAsJSON := simplejson.NewFromAny(panel)
+		if panelAsJSON.Get("type").MustString() == "row" {
+			collectLibraryPanelUIDs(panelAsJSON.Get("panels").MustArray(), uids)
+			continue
+		}
+		if uid := panelAsJSON.Get("libraryPanel").Get("uid").MustString(); uid != "" {
+			uids[uid] = true
+		}
+	}
+}
+
+func (s *PromotionService) exportAlertRuleGroups(ctx context.Context, user identity.Requester, folderUIDs []string) ([]definitions.AlertRuleGroup, error) {
+	groups, err := s.ruleService.GetAlertGroupsWithFolderFullpath(ctx, user, &provisioning.FilterOptions{
+		NamespaceUIDs: folderUIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]definitions.AlertRuleGroup, 0, len(groups))
+	for _, group := range groups {
+		apiGroup := ngalertapi.ApiAlertRuleGroupFromAlertRuleGroup(*group.AlertRuleGroup)
+		for i := range apiGroup.Rules {
+			// the rules are imported by their uid in the organization of the importing user
+			apiGroup.Rules[i].ID = 0
+			apiGroup.Rules[i].OrgID = 0
+			apiGroup.Rules[i].Provenance = ""
+			apiGroup.Rules[i].Updated = time.Time{}
+		}
+		result = append(result, apiGroup)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FolderUID != result[j].FolderUID {
+			return result[i].FolderUID < result[j].FolderUID
+		}
+		return result[i].Title < result[j].Title
+	})
+	return result, nil
+}
+
+// exportContactPoints returns the contact points the alert rules send their notifications to.
+// Their secure settings are redacted, so they are replaced with constant inputs.
+func (s *PromotionService) exportContactPoints(ctx context.Context, user identity.Requester, groups []definitions.AlertRuleGroup, e *exporter) ([]definitions.EmbeddedContactPoint, error) {
+	receivers := map[string]bool{}
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if rule.NotificationSettings != nil && rule.NotificationSettings.Receiver != "" {
+				receivers[rule.NotificationSettings.Receiver] = true
+			}
+		}
+	}
+
+	result := make([]definitions.EmbeddedContactPoint, 0, len(receivers))
+	for _, receiver := range slices.Sorted(maps.Keys(receivers)) {
+		contactPoints, err := s.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{
+			Name:  receiver,
+			OrgID: user.GetOrgID(),
+		}, user)
+		if err != nil {
+			return nil, err
+		}
+		for _, contactPoint := range contactPoints {
+			contactPoint.Provenance = ""
+			secureSettings, err := provisioning.RemoveSecretsForContactPoint(&contactPoint)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range slices.Sorted(maps.Keys(secureSettings)) {
+				name := inputName("CP", contactPoint.UID, key)
+				e.inputs[name] = promotion.Input{
+					Name:        name,
+					Label:       fmt.Sprintf("%s %s", contactPoint.Name, key),
+					Description: fmt.Sprintf("Secure setting %s of the contact point %s", key, contactPoint.Name),
+					Type:        promotion.InputTypeConstant,
+				}
+				contactPoint.Settings.Set(key, "${"+name+"}")
+			}
+			result = append(result, contactPoint)
+		}
+	}
+	return result, nil
+}
+
+// exporter replaces the environment-specific values of the exported resources with inputs.
+type exporter struct {
+	dataSourceService datasources.DataSourceService
+	orgID             int64
+	variables         []promotion.ExportVariable
+	inputs            map[string]promotion.Input
+	// datasourceRefs maps the datasource references found in the resources to input names,
+	// or to an empty string when the reference is not a datasource of the organization.
+	datasourceRefs map[string]string
+	// datasourceInputs maps the datasource uids to input names.
+	datasourceInputs map[string]string
+}
+
+// substitute walks through the bundle as plain JSON, so that the datasource references of dashboards, library panels
+// and alert queries are all found the same way.
+func (e *exporter) substitute(ctx context.Context, bundle *promotion.Bundle) (*promotion.Bundle, error) {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	if tree, err = e.walk(ctx, tree); err != nil {
+		return nil, err
+	}
+
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, err
+	}
+	result := &promotion.Bundle{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (e *exporter) walk(ctx context.Context, value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			var datasourceInput string
+			var err error
+			switch ref := item.(type) {
+			case string:
+				// legacy panels reference their datasource by name, alert queries use datasourceUid
+				if key == "datasource" || key == "datasourceUid" {
+					if datasourceInput, err = e.datasourceInput(ctx, ref); err != nil {
+						return nil, err
+					}
+				}
+			case map[string]any:
+				if uid, ok := ref["uid"].(string); ok && key == "datasource" {
+					if datasourceInput, err = e.datasourceInput(ctx, uid); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			if v[key], err = e.walk(ctx, item); err != nil {
+				return nil, err
+			}
+			if datasourceInput == "" {
+				continue
+			}
+			if ref, ok := v[key].(map[string]any); ok {
+				ref["uid"] = "${" + datasourceInput + "}"
+			} else {
+				v[key] = "${" + datasourceInput + "}"
+			}
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			var err error
+			if v[i], err = e.walk(ctx, item); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	case string:
+		for _, variable := range e.variables {
+			v = strings.ReplaceAll(v, variable.Value, "${"+variable.Name+"}")
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// datasourceInput returns the name of the input which replaces a datasource reference.
+// An empty name is returned for references which are not datasources of the organization,
+// such as template variables or the built-in datasources.
+func (e *exporter) datasourceInput(ctx context.Context, ref string) (string, error) {
+	if name, ok := e.datasourceRefs[ref]; ok {
+		return name, nil
+	}
+	if ref == "" || strings.HasPrefix(ref, "$") {
+		return "", nil
+	}
+
+	ds, err := e.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: ref, OrgID: e.orgID})
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		ds, err = e.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{Name: ref, OrgID: e.orgID})
+	}
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		e.datasourceRefs[ref] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	name, ok := e.datasourceInputs[ds.UID]
+	if !ok {
+		name = inputName("DS", ds.Name)
+		for i := 2; ; i++ {
+			if _, exists := e.inputs[name]; !exists {
+				break
+			}
+			name = fmt.Sprintf("%s_%d", inputName("DS", ds.Name), i)
+		}
+		e.inputs[name] = promotion.Input{
+			Name:     name,
+			Label:    ds.Name,
+			Type:     promotion.InputTypeDatasource,
+			PluginID: ds.Type,
+		}
+		e.datasourceInputs[ds.UID] = name
+	}
+	e.datasourceRefs[ref] = name
+	return name, nil
+}
+
+func inputName(parts ...string) string {
+	for i, part := range parts {
+		parts[i] = strings.Trim(inputNameInvalidChars.ReplaceAllString(part, "_"), "_")
+	}
+	return strings.ToUpper(strings.Join(parts, "_"))
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	ngalertapi "github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/promotion"
+)
+
+// importPlan is the plan of an import, with the existing resources which are updated instead of created.
+type importPlan struct {
+	items                 []promotion.PlanItem
+	existingFolders       map[string]bool
+	existingContactPoints map[string]bool
+}
+
+// Import replaces the inputs of the bundle with the values of the request, and imports the bundle resources
+// in a single database transaction, unless the request is a dry run.
+// Dashboards and library panels are imported like with the dashboard import, alert rule groups and contact points
+// like with the alerting provisioning API, without provenance so that they can still be edited.
+func (s *PromotionService) Import(ctx context.Context, req *promotion.ImportRequest) (*promotion.ImportResponse, error) {
+	if req.Bundle == nil {
+		return nil, promotion.ErrInvalidBundle.Errorf("bundle is required")
+	}
+	if req.Bundle.Version != promotion.BundleVersion {
+		return nil, promotion.ErrInvalidBundle.Errorf("unsupported bundle version %d", req.Bundle.Version)
+	}
+	if s.ruleService == nil && (len(req.Bundle.AlertRuleGroups) > 0 || len(req.Bundle.ContactPoints) > 0) {
+		return nil, promotion.ErrAlertingNotAvailable.Errorf("alerting is disabled")
+	}
+
+	bundle, err := evaluateBundle(req.Bundle, req.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.plan(ctx, req.User, bundle)
+	if err != nil {
+		return nil, err
+	}
+	resp := &promotion.ImportResponse{DryRun: req.DryRun, Plan: plan.items}
+	if req.DryRun {
+		return resp, nil
+	}
+
+	err = s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.apply(ctx, req.User, bundle, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// evaluateBundle replaces the inputs of the bundle with their values, the same way the dashboard import does.
+func evaluateBundle(bundle *promotion.Bundle, inputs []dashboardimport.ImportDashboardInput) (*promotion.Bundle, error) {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	template, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, err
+	}
+	template.Set("__inputs", template.Get("inputs").Interface())
+	template.Del("inputs")
+
+	evaluated, err := utils.NewDashTemplateEvaluator(template, inputs).Eval()
+	if err != nil {
+		return nil, err
+	}
+	if data, err = evaluated.MarshalJSON(); err != nil {
+		return nil, err
+	}
+	result := &promotion.Bundle{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	result.Inputs = bundle.Inputs
+	return result, nil
+}
+
+func (s *PromotionService) plan(ctx context.Context, user identity.Requester, bundle *promotion.Bundle) (*importPlan, error) {
+	plan := &importPlan{
+		items:                 []promotion.PlanItem{},
+		existingFolders:       map[string]bool{},
+		existingContactPoints: map[string]bool{},
+	}
+	add := func(item promotion.PlanItem, exists bool) {
+		item.Action = promotion.ActionCreate
+		if exists {
+			item.Action = promotion.ActionUpdate
+		}
+		plan.items = append(plan.items, item)
+	}
+
+	for _, f := range bundle.Folders {
+		_, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
+			UID:          &f.UID,
+			OrgID:        user.GetOrgID(),
+			SignedInUser: user,
+		})
+		if err != nil && !errors.Is(err, dashboards.ErrFolderNotFound) && !errors.Is(err, folder.ErrFolderNotFound) {
+			return nil, err
+		}
+		plan.existingFolders[f.UID] = err == nil
+		add(promotion.PlanItem{Kind: promotion.KindFolder, UID: f.UID, Title: f.Title}, err == nil)
+	}
+
+	for _, element := range bundle.LibraryElements {
+		_, err := s.libraryElementService.GetElement(ctx, user, model.GetLibraryElementCommand{
+			UID:        element.UID,
+			FolderName: dashboards.RootFolderName,
+		})
+		if err != nil && !errors.Is(err, model.ErrLibraryElementNotFound) {
+			return nil, err
+		}
+		item := promotion.PlanItem{Kind: promotion.KindLibraryElement, UID: element.UID, Title: element.Name, Action: promotion.ActionCreate}
+		if err == nil {
+			item.Action = promotion.ActionSkip
+		}
+		plan.items = append(plan.items, item)
+	}
+
+	for _, dash := range bundle.Dashboards {
+		uid := dash.Dashboard.Get("uid").MustString()
+		_, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()})
+		if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, err
+		}
+		add(promotion.PlanItem{
+			Kind:      promotion.KindDashboard,
+			UID:       uid,
+			FolderUID: dash.FolderUID,
+			Title:     dash.Dashboard.Get("title").MustString(),
+		}, err == nil)
+	}
+
+	existingContactPoints := map[string][]definitions.EmbeddedContactPoint{}
+	for _, contactPoint := range bundle.ContactPoints {
+		existing, ok := existingContactPoints[contactPoint.Name]
+		if !ok {
+			var err error
+			existing, err = s.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{
+				Name:  contactPoint.Name,
+				OrgID: user.GetOrgID(),
+			}, user)
+			if err != nil {
+				return nil, err
+			}
+			existingContactPoints[contactPoint.Name] = existing
+		}
+		exists := false
+		for _, e := range existing {
+			exists = exists || e.UID == contactPoint.UID
+		}
+		plan.existingContactPoints[contactPoint.UID] = exists
+		add(promotion.PlanItem{Kind: promotion.KindContactPoint, UID: contactPoint.UID, Title: contactPoint.Name}, exists)
+	}
+
+	for _, group := range bundle.AlertRuleGroups {
+		_, err := s.ruleService.GetRuleGroup(ctx, user, group.FolderUID, group.Title)
+		if err != nil && !errors.Is(err, models.ErrAlertRuleGroupNotFound) {
+			return nil, err
+		}
+		add(promotion.PlanItem{Kind: promotion.KindAlertRuleGroup, FolderUID: group.FolderUID, Title: group.Title}, err == nil)
+	}
+	return plan, nil
+}
+
+// apply imports the resources in the order of their dependencies: the folders first, and the contact points before
+// the alert rules which send notifications to them.
+func (s *PromotionService) apply(ctx context.Context, user identity.Requester, bundle *promotion.Bundle, plan *importPlan) error {
+	for _, f := range bundle.Folders {
+		var err error
+		if plan.existingFolders[f.UID] {
+			_, err = s.folderService.Update(ctx, &folder.UpdateFolderCommand{
+				UID:            f.UID,
+				OrgID:          user.GetOrgID(),
+				NewTitle:       &f.Title,
+				NewDescription: &f.Description,
+				Overwrite:      true,
+				SignedInUser:   user,
+			})
+		} else {
+			_, err = s.folderService.Create(ctx, &folder.CreateFolderCommand{
+				UID:          f.UID,
+				OrgID:        user.GetOrgID(),
+				Title:        f.Title,
+				Description:  f.Description,
+				ParentUID:    f.ParentUID,
+				SignedInUser: user,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// the dashboard import creates the missing library panels of each dashboard
+	elements := make(map[string]any, len(bundle.LibraryElements))
+	for _, element := range bundle.LibraryElements {
+		var elementModel any
+		if err := json.Unmarshal(element.Model, &elementModel); err != nil {
+			return err
+		}
+		elements[element.UID] = map[string]any{
+			"uid":   element.UID,
+			"name":  element.Name,
+			"kind":  element.Kind,
+			"model": elementModel,
+		}
+	}
+	for _, dash := range bundle.Dashboards {
+		if len(elements) > 0 {
+			dash.Dashboard.Set("__elements", elements)
+		}
+		_, err := s.dashboardImportService.ImportDashboard(ctx, &dashboardimport.ImportDashboardRequest{
+			Dashboard: dash.Dashboard,
+			Overwrite: true,
+			FolderUid: dash.FolderUID,
+			User:      user,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, contactPoint := range bundle.ContactPoints {
+		var err error
+		if plan.existingContactPoints[contactPoint.UID] {
+			err = s.contactPointService.UpdateContactPoint(ctx, user.GetOrgID(), contactPoint, models.ProvenanceNone)
+		} else {
+			_, err = s.contactPointService.CreateContactPoint(ctx, user.GetOrgID(), user, contactPoint, models.ProvenanceNone)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, group := range bundle.AlertRuleGroups {
+		groupModel, err := ngalertapi.AlertRuleGroupFromApiAlertRuleGroup(group)
+		if err != nil {
+			return err
+		}
+		if err := s.ruleService.ReplaceRuleGroup(ctx, user, groupModel, models.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
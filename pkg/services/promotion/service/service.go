@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/promotion/api"
+)
+
+// ruleService is the part of the alerting provisioning service used to export and import alert rule groups.
+type ruleService interface {
+	GetAlertGroupsWithFolderFullpath(ctx context.Context, user identity.Requester, filterOpts *provisioning.FilterOptions) ([]models.AlertRuleGroupWithFolderFullpath, error)
+	GetRuleGroup(ctx context.Context, user identity.Requester, namespaceUID, group string) (models.AlertRuleGroup, error)
+	ReplaceRuleGroup(ctx context.Context, user identity.Requester, group models.AlertRuleGroup, provenance models.Provenance) error
+}
+
+// contactPointService is the part of the alerting provisioning service used to export and import contact points.
+type contactPointService interface {
+	GetContactPoints(ctx context.Context, q provisioning.ContactPointQuery, u identity.Requester) ([]definitions.EmbeddedContactPoint, error)
+	CreateContactPoint(ctx context.Context, orgID int64, user identity.Requester, contactPoint definitions.EmbeddedContactPoint, provenance models.Provenance) (definitions.EmbeddedContactPoint, error)
+	UpdateContactPoint(ctx context.Context, orgID int64, contactPoint definitions.EmbeddedContactPoint, provenance models.Provenance) error
+}
+
+func ProvideService(routeRegister routing.RouteRegister,
+	sqlStore db.DB, ac accesscontrol.AccessControl,
+	folderService folder.Service, dashboardService dashboards.DashboardService,
+	libraryElementService libraryelements.Service, dataSourceService datasources.DataSourceService,
+	dashboardImportService dashboardimport.Service, ng *ngalert.AlertNG,
+) *PromotionService {
+	s := &PromotionService{
+		db:                     sqlStore,
+		folderService:          folderService,
+		dashboardService:       dashboardService,
+		libraryElementService:  libraryElementService,
+		dataSourceService:      dataSourceService,
+		dashboardImportService: dashboardImportService,
+	}
+	// the alerting services are only available when alerting is enabled
+	if !ng.IsDisabled() {
+		s.ruleService = ng.Api.AlertRules
+		s.contactPointService = ng.Api.ContactPointService
+	}
+
+	promotionAPI := api.New(s, ac)
+	promotionAPI.RegisterAPIEndpoints(routeRegister)
+
+	return s
+}
+
+type PromotionService struct {
+	db                     db.DB
+	folderService          folder.Service
+	dashboardService       dashboards.DashboardService
+	libraryElementService  libraryelements.Service
+	dataSourceService      datasources.DataSourceService
+	dashboardImportService dashboardimport.Service
+	ruleService            ruleService
+	contactPointService    contactPointService
+}
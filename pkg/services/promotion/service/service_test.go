@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db/dbtest"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	libraryelementsfake "github.com/grafana/grafana/pkg/services/libraryelements/fake"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/promotion"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestExport(t *testing.T) {
+	s, deps := setupService(t)
+	deps.folders.folders = map[string]*folder.Folder{
+		"team":       {UID: "team", Title: "Team", ParentUID: "parent"},
+		"team-child": {UID: "team-child", Title: "Child", ParentUID: "team"},
+	}
+	deps.dashboards.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{{UID: "dash"}}, nil)
+	deps.dashboards.On("GetDashboards", mock.Anything, mock.Anything).Return([]*dashboards.Dashboard{{
+		ID:        4,
+		UID:       "dash",
+		FolderUID: "team-child",
+		Data: simplejson.NewFromAny(map[string]any{
+			"id":    4,
+			"title": "Service",
+			"panels": []any{
+				map[string]any{
+					"datasource": map[string]any{"type": "prometheus", "uid": "prom-staging"},
+					"targets": []any{
+						map[string]any{"datasource": map[string]any{"uid": "${datasource}"}, "expr": `up{env="staging"}`},
+					},
+				},
+				map[string]any{
+					"type": "row",
+					"panels": []any{
+						map[string]any{"libraryPanel": map[string]any{"uid": "lib", "name": "Library panel"}},
+					},
+				},
+			},
+		}),
+	}}, nil)
+	_, err := deps.libraryElements.CreateElement(context.Background(), testUser, model.CreateLibraryElementCommand{
+		UID:   "lib",
+		Name:  "Library panel",
+		Kind:  int64(model.PanelElement),
+		Model: []byte(`{"datasource":"Prometheus staging"}`),
+	})
+	require.NoError(t, err)
+	deps.rules.groups = []models.AlertRuleGroup{{
+		Title:     "group",
+		FolderUID: "team",
+		Interval:  60,
+		Rules: []models.AlertRule{{
+			ID:           1,
+			UID:          "rule",
+			OrgID:        1,
+			Title:        "Rule",
+			NamespaceUID: "team",
+			RuleGroup:    "group",
+			Condition:    "B",
+			Data: []models.AlertQuery{
+				{RefID: "A", DatasourceUID: "prom-staging", Model: json.RawMessage(`{"expr":"up"}`)},
+				{RefID: "B", DatasourceUID: "__expr__", Model: json.RawMessage(`{"type":"threshold"}`)},
+			},
+			NotificationSettings: []models.NotificationSettings{{Receiver: "slack"}},
+		}},
+	}}
+	deps.contactPoints.contactPoints = []definitions.EmbeddedContactPoint{{
+		UID:        "slack-uid",
+		Name:       "slack",
+		Type:       "slack",
+		Settings:   simplejson.NewFromAny(map[string]any{"recipient": "#staging", "url": definitions.RedactedValue}),
+		Provenance: "api",
+	}}
+
+	bundle, err := s.Export(context.Background(), &promotion.ExportRequest{
+		FolderUID: "team",
+		Variables: []promotion.ExportVariable{{Name: "ENV", Value: "staging"}},
+		User:      testUser,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []promotion.Input{
+		{Name: "CP_SLACK_UID_URL", Label: "slack url", Description: "Secure setting url of the contact point slack", Type: promotion.InputTypeConstant},
+		{Name: "DS_PROMETHEUS_STAGING", Label: "Prometheus staging", Type: promotion.InputTypeDatasource, PluginID: "prometheus"},
+		{Name: "ENV", Label: "ENV", Type: promotion.InputTypeConstant},
+	}, bundle.Inputs)
+	require.Equal(t, []promotion.Folder{
+		{UID: "team", Title: "Team"},
+		{UID: "team-child", Title: "Child", ParentUID: "team"},
+	}, bundle.Folders)
+
+	require.Len(t, bundle.Dashboards, 1)
+	dash := bundle.Dashboards[0].Dashboard
+	require.Equal(t, "team-child", bundle.Dashboards[0].FolderUID)
+	require.Equal(t, "dash", dash.Get("uid").MustString())
+	_, hasID := dash.CheckGet("id")
+	require.False(t, hasID)
+	panel := dash.Get("panels").GetIndex(0)
+	require.Equal(t, "${DS_PROMETHEUS_STAGING}", panel.GetPath("datasource", "uid").MustString())
+	require.Equal(t, "${datasource}", panel.Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+	require.Equal(t, `up{env="${ENV}"}`, panel.Get("targets").GetIndex(0).Get("expr").MustString())
+
+	require.Len(t, bundle.LibraryElements, 1)
+	require.Equal(t, "lib", bundle.LibraryElements[0].UID)
+	require.JSONEq(t, `{"datasource":"${DS_PROMETHEUS_STAGING}"}`, string(bundle.LibraryElements[0].Model))
+
+	require.Len(t, bundle.AlertRuleGroups, 1)
+	rule := bundle.AlertRuleGroups[0].Rules[0]
+	require.Zero(t, rule.ID)
+	require.Zero(t, rule.OrgID)
+	require.Equal(t, "${DS_PROMETHEUS_STAGING}", rule.Data[0].DatasourceUID)
+	require.Equal(t, "__expr__", rule.Data[1].DatasourceUID)
+
+	require.Len(t, bundle.ContactPoints, 1)
+	require.Empty(t, bundle.ContactPoints[0].Provenance)
+	require.Equal(t, "${CP_SLACK_UID_URL}", bundle.ContactPoints[0].Settings.Get("url").MustString())
+	require.Equal(t, "#${ENV}", bundle.ContactPoints[0].Settings.Get("recipient").MustString())
+
+	t.Run("should require a folder", func(t *testing.T) {
+		_, err := s.Export(context.Background(), &promotion.ExportRequest{User: testUser})
+		require.ErrorIs(t, err, promotion.ErrExportFolderIsRequired)
+	})
+}
+
+func TestImport(t *testing.T) {
+	bundle := &promotion.Bundle{
+		Version: promotion.BundleVersion,
+		Inputs: []promotion.Input{
+			{Name: "DS_PROMETHEUS", Type: promotion.InputTypeDatasource, PluginID: "prometheus"},
+			{Name: "ENV", Type: promotion.InputTypeConstant},
+		},
+		Folders: []promotion.Folder{
+			{UID: "team", Title: "Team"},
+			{UID: "team-child", Title: "Child ${ENV}", ParentUID: "team"},
+		},
+		Dashboards: []promotion.Dashboard{{
+			FolderUID: "team-child",
+			Dashboard: simplejson.NewFromAny(map[string]any{
+				"uid":   "dash",
+				"title": "Service",
+				"panels": []any{
+					map[string]any{"datasource": map[string]any{"uid": "${DS_PROMETHEUS}"}, "libraryPanel": map[string]any{"uid": "lib", "name": "Library panel"}},
+				},
+			}),
+		}},
+		LibraryElements: []promotion.LibraryElement{{UID: "lib", Name: "Library panel", Kind: int64(model.PanelElement), Model: json.RawMessage(`{"type":"graph"}`)}},
+		AlertRuleGroups: []definitions.AlertRuleGroup{{
+			Title:     "group",
+			FolderUID: "team",
+			Interval:  60,
+			Rules: []definitions.ProvisionedAlertRule{{
+				UID:       "rule",
+				Title:     "Rule",
+				Condition: "A",
+				Data:      []definitions.AlertQuery{{RefID: "A", DatasourceUID: "${DS_PROMETHEUS}", Model: json.RawMessage(`{"expr":"up"}`)}},
+			}},
+		}},
+		ContactPoints: []definitions.EmbeddedContactPoint{{
+			UID:      "slack-uid",
+			Name:     "slack",
+			Type:     "slack",
+			Settings: simplejson.NewFromAny(map[string]any{"recipient": "#${ENV}"}),
+		}},
+	}
+	inputs := []dashboardimport.ImportDashboardInput{
+		{Name: "DS_PROMETHEUS", Type: promotion.InputTypeDatasource, Value: "prom-prod"},
+		{Name: "ENV", Type: promotion.InputTypeConstant, Value: "prod"},
+	}
+
+	t.Run("should return the plan of a dry run", func(t *testing.T) {
+		s, deps := setupService(t)
+		deps.folders.folders = map[string]*folder.Folder{"team": {UID: "team", Title: "Team"}}
+		deps.dashboards.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+		deps.contactPoints.contactPoints = []definitions.EmbeddedContactPoint{{UID: "slack-uid", Name: "slack"}}
+		deps.rules.groups = []models.AlertRuleGroup{{Title: "group", FolderUID: "team"}}
+
+		resp, err := s.Import(context.Background(), &promotion.ImportRequest{Bundle: bundle, Inputs: inputs, DryRun: true, User: testUser})
+		require.NoError(t, err)
+		require.Equal(t, &promotion.ImportResponse{
+			DryRun: true,
+			Plan: []promotion.PlanItem{
+				{Kind: promotion.KindFolder, UID: "team", Title: "Team", Action: promotion.ActionUpdate},
+				{Kind: promotion.KindFolder, UID: "team-child", Title: "Child prod", Action: promotion.ActionCreate},
+				{Kind: promotion.KindLibraryElement, UID: "lib", Title: "Library panel", Action: promotion.ActionCreate},
+				{Kind: promotion.KindDashboard, UID: "dash", FolderUID: "team-child", Title: "Service", Action: promotion.ActionCreate},
+				{Kind: promotion.KindContactPoint, UID: "slack-uid", Title: "slack", Action: promotion.ActionUpdate},
+				{Kind: promotion.KindAlertRuleGroup, FolderUID: "team", Title: "group", Action: promotion.ActionUpdate},
+			},
+		}, resp)
+		require.Empty(t, deps.folders.created)
+		require.Empty(t, deps.dashboardImport.requests)
+		require.Empty(t, deps.rules.replaced)
+	})
+
+	t.Run("should import the resources with the input values", func(t *testing.T) {
+		s, deps := setupService(t)
+		deps.dashboards.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+
+		_, err := s.Import(context.Background(), &promotion.ImportRequest{Bundle: bundle, Inputs: inputs, User: testUser})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"team", "team-child"}, deps.folders.created)
+		require.Len(t, deps.dashboardImport.requests, 1)
+		dash := deps.dashboardImport.requests[0]
+		require.Equal(t, "team-child", dash.FolderUid)
+		require.True(t, dash.Overwrite)
+		require.Equal(t, "prom-prod", dash.Dashboard.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		require.Equal(t, "Library panel", dash.Dashboard.GetPath("__elements", "lib", "name").MustString())
+
+		require.Len(t, deps.contactPoints.created, 1)
+		require.Equal(t, "#prod", deps.contactPoints.created[0].Settings.Get("recipient").MustString())
+		require.Len(t, deps.rules.replaced, 1)
+		require.Equal(t, "prom-prod", deps.rules.replaced[0].Rules[0].Data[0].DatasourceUID)
+	})
+
+	t.Run("should require the inputs", func(t *testing.T) {
+		s, _ := setupService(t)
+		_, err := s.Import(context.Background(), &promotion.ImportRequest{Bundle: bundle, Inputs: inputs[:1], User: testUser})
+		require.ErrorIs(t, err, utils.ErrDashboardInputMissing)
+	})
+
+	t.Run("should reject unknown bundle versions", func(t *testing.T) {
+		s, _ := setupService(t)
+		_, err := s.Import(context.Background(), &promotion.ImportRequest{Bundle: &promotion.Bundle{Version: 2}, User: testUser})
+		require.ErrorIs(t, err, promotion.ErrInvalidBundle)
+	})
+}
+
+var testUser = &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin}
+
+type testDependencies struct {
+	folders         *folderServiceMock
+	dashboards      *dashboards.FakeDashboardService
+	libraryElements *libraryelementsfake.LibraryElementService
+	dashboardImport *dashboardImportServiceMock
+	rules           *ruleServiceMock
+	contactPoints   *contactPointServiceMock
+}
+
+func setupService(t *testing.T) (*PromotionService, *testDependencies) {
+	deps := &testDependencies{
+		folders:         &folderServiceMock{FakeService: foldertest.NewFakeService(), folders: map[string]*folder.Folder{}},
+		dashboards:      dashboards.NewFakeDashboardService(t),
+		libraryElements: &libraryelementsfake.LibraryElementService{},
+		dashboardImport: &dashboardImportServiceMock{},
+		rules:           &ruleServiceMock{},
+		contactPoints:   &contactPointServiceMock{},
+	}
+	s := &PromotionService{
+		db:                     &transactionDB{FakeDB: dbtest.NewFakeDB()},
+		folderService:          deps.folders,
+		dashboardService:       deps.dashboards,
+		libraryElementService:  deps.libraryElements,
+		dataSourceService:      &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{{UID: "prom-staging", Name: "Prometheus staging", Type: "prometheus"}}},
+		dashboardImportService: deps.dashboardImport,
+		ruleService:            deps.rules,
+		contactPointService:    deps.contactPoints,
+	}
+	return s, deps
+}
+
+type transactionDB struct {
+	*dbtest.FakeDB
+}
+
+func (db *transactionDB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type folderServiceMock struct {
+	*foldertest.FakeService
+	folders map[string]*folder.Folder
+	created []string
+}
+
+func (s *folderServiceMock) Get(_ context.Context, q *folder.GetFolderQuery) (*folder.Folder, error) {
+	if f, ok := s.folders[*q.UID]; ok {
+		return f, nil
+	}
+	return nil, folder.ErrFolderNotFound.Errorf("folder not found")
+}
+
+func (s *folderServiceMock) GetChildren(_ context.Context, q *folder.GetChildrenQuery) ([]*folder.Folder, error) {
+	var children []*folder.Folder
+	for _, f := range s.folders {
+		if f.ParentUID == q.UID {
+			children = append(children, f)
+		}
+	}
+	return children, nil
+}
+
+func (s *folderServiceMock) Create(_ context.Context, cmd *folder.CreateFolderCommand) (*folder.Folder, error) {
+	s.created = append(s.created, cmd.UID)
+	return &folder.Folder{UID: cmd.UID, Title: cmd.Title, ParentUID: cmd.ParentUID}, nil
+}
+
+type dashboardImportServiceMock struct {
+	requests []*dashboardimport.ImportDashboardRequest
+}
+
+func (s *dashboardImportServiceMock) ImportDashboard(_ context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
+	s.requests = append(s.requests, req)
+	return &dashboardimport.ImportDashboardResponse{UID: req.Dashboard.Get("uid").MustString()}, nil
+}
+
+type ruleServiceMock struct {
+	groups   []models.AlertRuleGroup
+	replaced []models.AlertRuleGroup
+}
+
+func (s *ruleServiceMock) GetAlertGroupsWithFolderFullpath(_ context.Context, _ identity.Requester, filterOpts *provisioning.FilterOptions) ([]models.AlertRuleGroupWithFolderFullpath, error) {
+	var result []models.AlertRuleGroupWithFolderFullpath
+	for i, group := range s.groups {
+		for _, uid := range filterOpts.NamespaceUIDs {
+			if group.FolderUID == uid {
+				result = append(result, models.AlertRuleGroupWithFolderFullpath{AlertRuleGroup: &s.groups[i], OrgID: 1})
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *ruleServiceMock) GetRuleGroup(_ context.Context, _ identity.Requester, namespaceUID, group string) (models.AlertRuleGroup, error) {
+	for _, g := range s.groups {
+		if g.FolderUID == namespaceUID && g.Title == group {
+			return g, nil
+		}
+	}
+	return models.AlertRuleGroup{}, models.ErrAlertRuleGroupNotFound.Errorf("")
+}
+
+func (s *ruleServiceMock) ReplaceRuleGroup(_ context.Context, _ identity.Requester, group models.AlertRuleGroup, _ models.Provenance) error {
+	s.replaced = append(s.replaced, group)
+	return nil
+}
+
+type contactPointServiceMock struct {
+	contactPoints []definitions.EmbeddedContactPoint
+	created       []definitions.EmbeddedContactPoint
+	updated       []definitions.EmbeddedContactPoint
+}
+
+func (s *contactPointServiceMock) GetContactPoints(_ context.Context, q provisioning.ContactPointQuery, _ identity.Requester) ([]definitions.EmbeddedContactPoint, error) {
+	var result []definitions.EmbeddedContactPoint
+	for _, contactPoint := range s.contactPoints {
+		if contactPoint.Name == q.Name {
+			result = append(result, contactPoint)
+		}
+	}
+	return result, nil
+}
+
+func (s *contactPointServiceMock) CreateContactPoint(_ context.Context, _ int64, _ identity.Requester, contactPoint definitions.EmbeddedContactPoint, _ models.Provenance) (definitions.EmbeddedContactPoint, error) {
+	s.created = append(s.created, contactPoint)
+	return contactPoint, nil
+}
+
+func (s *contactPointServiceMock) UpdateContactPoint(_ context.Context, _ int64, contactPoint definitions.EmbeddedContactPoint, _ models.Provenance) error {
+	s.updated = append(s.updated, contactPoint)
+	return nil
+}
@@ -0,0 +1,411 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alert_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+type alertRuleLister interface {
+	GetAlertRules(ctx context.Context, user identity.Requester) ([]*alert_models.AlertRule, map[string]alert_models.Provenance, error)
+}
+
+type contactPointLister interface {
+	GetContactPoints(ctx context.Context, q provisioning.ContactPointQuery, u identity.Requester) ([]definitions.EmbeddedContactPoint, error)
+}
+
+type policyTreeGetter interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, string, error)
+}
+
+type muteTimingLister interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error)
+}
+
+type templateLister interface {
+	GetTemplates(ctx context.Context, orgID int64) ([]definitions.NotificationTemplate, error)
+}
+
+// policyTreeName is the name reported for the notification policy tree, which has neither a name nor a UID
+const policyTreeName = "policies"
+
+// DetectDrift compares the alerting resources of the provisioning files with the resources in the database.
+func DetectDrift(ctx context.Context, cfg ProvisionerConfig) ([]drift.Item, error) {
+	cfgReader := newRulesConfigReader(log.New("provisioning.alerting"))
+	files, err := cfgReader.readConfig(ctx, cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleItems, err := detectRuleDrift(ctx, cfg.Path, files, &cfg.RuleService)
+	if err != nil {
+		return nil, fmt.Errorf("alert rules: %w", err)
+	}
+	contactPointItems, err := detectContactPointDrift(ctx, cfg.Path, files, &cfg.ContactPointService)
+	if err != nil {
+		return nil, fmt.Errorf("contact points: %w", err)
+	}
+	policyItems, err := detectPolicyDrift(ctx, cfg.Path, files, &cfg.NotificiationPolicyService)
+	if err != nil {
+		return nil, fmt.Errorf("notification policies: %w", err)
+	}
+	muteTimingItems, err := detectMuteTimingDrift(ctx, cfg.Path, files, &cfg.MuteTimingService)
+	if err != nil {
+		return nil, fmt.Errorf("mute times: %w", err)
+	}
+	templateItems, err := detectTemplateDrift(ctx, cfg.Path, files, &cfg.TemplateService)
+	if err != nil {
+		return nil, fmt.Errorf("text templates: %w", err)
+	}
+	return slices.Concat(ruleItems, contactPointItems, policyItems, muteTimingItems, templateItems), nil
+}
+
+// fileResource is a resource defined in a provisioning file
+type fileResource[T any] struct {
+	resource T
+	path     string
+}
+
+// storedResource is a resource in the database
+type storedResource[T any] struct {
+	name     string
+	resource T
+	// Whether the resource was provisioned from a file
+	fromFile bool
+}
+
+// fileResources collects the resources of the files by organization and name.
+// Organizations only referenced by deletions get an empty set, so the resources provisioned in them are reported as orphaned.
+type fileResources[T any] map[int64]map[string]fileResource[T]
+
+func (r fileResources[T]) addOrg(orgID int64) {
+	if _, ok := r[orgID]; !ok {
+		r[orgID] = map[string]fileResource[T]{}
+	}
+}
+
+func (r fileResources[T]) add(orgID int64, name string, resource T, path string) {
+	r.addOrg(orgID)
+	r[orgID][name] = fileResource[T]{resource: resource, path: path}
+}
+
+// detectResourceDrift compares the resources of the files with the resources of the organizations the files refer to.
+// Resources provisioned from files in other organizations are not known here, so they are not reported as orphaned.
+func detectResourceDrift[T any](
+	kind drift.Kind,
+	expected fileResources[T],
+	list func(orgID int64) ([]storedResource[T], error),
+	compare func(expected, actual T) []string,
+) ([]drift.Item, error) {
+	var items []drift.Item
+	for _, orgID := range slices.Sorted(maps.Keys(expected)) {
+		stored, err := list(orgID)
+		if err != nil {
+			return nil, err
+		}
+
+		var orgItems []drift.Item
+		current := make(map[string]T, len(stored))
+		for _, resource := range stored {
+			current[resource.name] = resource.resource
+			if _, ok := expected[orgID][resource.name]; !ok && resource.fromFile {
+				orgItems = append(orgItems, drift.Item{Kind: kind, OrgID: orgID, Name: resource.name, State: drift.StateOrphaned})
+			}
+		}
+		for name, fileResource := range expected[orgID] {
+			item := drift.Item{Kind: kind, OrgID: orgID, Name: name, Path: fileResource.path, State: drift.StateMissing}
+			if resource, ok := current[name]; ok {
+				item.State = drift.StateModified
+				item.Fields = compare(fileResource.resource, resource)
+				if len(item.Fields) == 0 {
+					continue
+				}
+			}
+			orgItems = append(orgItems, item)
+		}
+
+		sort.Slice(orgItems, func(i, j int) bool {
+			return orgItems[i].Name < orgItems[j].Name
+		})
+		items = append(items, orgItems...)
+	}
+	return items, nil
+}
+
+// detectRuleDrift compares the rules of the files with the rules of the organizations the files refer to.
+func detectRuleDrift(ctx context.Context, path string, files []*AlertingFile, ruleService alertRuleLister) ([]drift.Item, error) {
+	expected := fileResources[alert_models.AlertRule]{}
+	for _, file := range files {
+		for _, group := range file.Groups {
+			expected.addOrg(group.OrgID)
+			for _, rule := range group.Rules {
+				rule.RuleGroup = group.Title
+				rule.IntervalSeconds = group.Interval
+				expected.add(group.OrgID, rule.UID, rule, filepath.Join(path, file.Filename))
+			}
+		}
+		for _, deleteRule := range file.DeleteRules {
+			expected.addOrg(deleteRule.OrgID)
+		}
+	}
+
+	return detectResourceDrift(drift.KindAlertRule, expected, func(orgID int64) ([]storedResource[alert_models.AlertRule], error) {
+		ctx, u := identity.WithServiceIdentity(ctx, orgID)
+		rules, provenances, err := ruleService.GetAlertRules(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		stored := make([]storedResource[alert_models.AlertRule], 0, len(rules))
+		for _, rule := range rules {
+			stored = append(stored, storedResource[alert_models.AlertRule]{
+				name:     rule.UID,
+				resource: *rule,
+				fromFile: provenances[rule.ResourceID()] == alert_models.ProvenanceFile,
+			})
+		}
+		return stored, nil
+	}, compareAlertRule)
+}
+
+// detectContactPointDrift compares the integrations of the contact points in the files by their UID.
+// The secure settings are decrypted, so that a changed secret is reported as well.
+func detectContactPointDrift(ctx context.Context, path string, files []*AlertingFile, contactPointService contactPointLister) ([]drift.Item, error) {
+	expected := fileResources[definitions.EmbeddedContactPoint]{}
+	for _, file := range files {
+		for _, contactPoint := range file.ContactPoints {
+			expected.addOrg(contactPoint.OrgID)
+			for _, integration := range contactPoint.ContactPoints {
+				expected.add(contactPoint.OrgID, integration.UID, integration, filepath.Join(path, file.Filename))
+			}
+		}
+		for _, deleteContactPoint := range file.DeleteContactPoints {
+			expected.addOrg(deleteContactPoint.OrgID)
+		}
+	}
+
+	return detectResourceDrift(drift.KindContactPoint, expected, func(orgID int64) ([]storedResource[definitions.EmbeddedContactPoint], error) {
+		contactPoints, err := contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: orgID, Decrypt: true}, provisionerUser(orgID))
+		if err != nil {
+			return nil, err
+		}
+		stored := make([]storedResource[definitions.EmbeddedContactPoint], 0, len(contactPoints))
+		for _, contactPoint := range contactPoints {
+			stored = append(stored, storedResource[definitions.EmbeddedContactPoint]{
+				name:     contactPoint.UID,
+				resource: contactPoint,
+				fromFile: contactPoint.Provenance == string(alert_models.ProvenanceFile),
+			})
+		}
+		return stored, nil
+	}, compareContactPoint)
+}
+
+// detectPolicyDrift compares the notification policy trees of the files.
+// Every organization has a policy tree, so it is never missing, and a provisioned tree no longer defined in a file is orphaned.
+func detectPolicyDrift(ctx context.Context, path string, files []*AlertingFile, policyService policyTreeGetter) ([]drift.Item, error) {
+	expected := fileResources[definitions.Route]{}
+	for _, file := range files {
+		for _, policy := range file.Policies {
+			expected.add(policy.OrgID, policyTreeName, policy.Policy, filepath.Join(path, file.Filename))
+		}
+		for _, orgID := range file.ResetPolicies {
+			expected.addOrg(int64(orgID))
+		}
+	}
+
+	return detectResourceDrift(drift.KindNotificationPolicy, expected, func(orgID int64) ([]storedResource[definitions.Route], error) {
+		tree, _, err := policyService.GetPolicyTree(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		return []storedResource[definitions.Route]{{
+			name:     policyTreeName,
+			resource: tree,
+			fromFile: tree.Provenance == definitions.Provenance(alert_models.ProvenanceFile),
+		}}, nil
+	}, func(expected, actual definitions.Route) []string {
+		return drift.CompareJSON(toMap(expected), toMap(actual), "provenance")
+	})
+}
+
+// detectMuteTimingDrift compares the mute timings of the files by their name.
+func detectMuteTimingDrift(ctx context.Context, path string, files []*AlertingFile, muteTimingService muteTimingLister) ([]drift.Item, error) {
+	expected := fileResources[definitions.MuteTimeInterval]{}
+	for _, file := range files {
+		for _, muteTime := range file.MuteTimes {
+			expected.add(muteTime.OrgID, muteTime.MuteTime.Name, muteTime.MuteTime, filepath.Join(path, file.Filename))
+		}
+		for _, deleteMuteTime := range file.DeleteMuteTimes {
+			expected.addOrg(deleteMuteTime.OrgID)
+		}
+	}
+
+	return detectResourceDrift(drift.KindMuteTiming, expected, func(orgID int64) ([]storedResource[definitions.MuteTimeInterval], error) {
+		intervals, err := muteTimingService.GetMuteTimings(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		stored := make([]storedResource[definitions.MuteTimeInterval], 0, len(intervals))
+		for _, interval := range intervals {
+			stored = append(stored, storedResource[definitions.MuteTimeInterval]{
+				name:     interval.Name,
+				resource: interval,
+				fromFile: interval.Provenance == definitions.Provenance(alert_models.ProvenanceFile),
+			})
+		}
+		return stored, nil
+	}, func(expected, actual definitions.MuteTimeInterval) []string {
+		return drift.CompareJSON(toMap(expected.MuteTimeInterval), toMap(actual.MuteTimeInterval))
+	})
+}
+
+// detectTemplateDrift compares the notification templates of the files by their name.
+func detectTemplateDrift(ctx context.Context, path string, files []*AlertingFile, templateService templateLister) ([]drift.Item, error) {
+	expected := fileResources[definitions.NotificationTemplate]{}
+	for _, file := range files {
+		for _, template := range file.Templates {
+			expected.add(template.OrgID, template.Data.Name, template.Data, filepath.Join(path, file.Filename))
+		}
+		for _, deleteTemplate := range file.DeleteTemplates {
+			expected.addOrg(deleteTemplate.OrgID)
+		}
+	}
+
+	return detectResourceDrift(drift.KindTemplate, expected, func(orgID int64) ([]storedResource[definitions.NotificationTemplate], error) {
+		templates, err := templateService.GetTemplates(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		stored := make([]storedResource[definitions.NotificationTemplate], 0, len(templates))
+		for _, template := range templates {
+			stored = append(stored, storedResource[definitions.NotificationTemplate]{
+				name:     template.Name,
+				resource: template,
+				fromFile: template.Provenance == definitions.Provenance(alert_models.ProvenanceFile),
+			})
+		}
+		return stored, nil
+	}, compareTemplate)
+}
+
+// compareAlertRule returns the fields of the rule which differ from its definition in the file, with the names used in the files.
+// The folder is not compared, as it is looked up by its title when provisioning.
+func compareAlertRule(expected, actual alert_models.AlertRule) []string {
+	var fields []string
+	check := func(field string, equal bool) {
+		if !equal {
+			fields = append(fields, field)
+		}
+	}
+
+	check("title", expected.Title == actual.Title)
+	check("condition", expected.Condition == actual.Condition)
+	check("data", equalQueries(expected.Data, actual.Data))
+	check("dashboardUid", valueOrZero(expected.DashboardUID) == valueOrZero(actual.DashboardUID))
+	check("panelId", valueOrZero(expected.PanelID) == valueOrZero(actual.PanelID))
+	check("noDataState", expected.NoDataState == actual.NoDataState)
+	check("execErrState", expected.ExecErrState == actual.ExecErrState)
+	check("for", expected.For == actual.For)
+	check("annotations", maps.Equal(expected.Annotations, actual.Annotations))
+	check("labels", maps.Equal(expected.Labels, actual.Labels))
+	check("isPaused", expected.IsPaused == actual.IsPaused)
+	check("notification_settings", (len(expected.NotificationSettings) == 0 && len(actual.NotificationSettings) == 0) ||
+		reflect.DeepEqual(expected.NotificationSettings, actual.NotificationSettings))
+	check("record", reflect.DeepEqual(expected.Record, actual.Record))
+	check("group", expected.RuleGroup == actual.RuleGroup)
+	check("interval", expected.IntervalSeconds == actual.IntervalSeconds)
+	return fields
+}
+
+// equalQueries compares the queries of a file with the stored queries.
+// The queries of the file get the defaults set before a rule is saved.
+func equalQueries(expected, actual []alert_models.AlertQuery) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		query := expected[i]
+		if err := query.PreSave(); err != nil {
+			return false
+		}
+		if query.RefID != actual[i].RefID ||
+			query.QueryType != actual[i].QueryType ||
+			query.DatasourceUID != actual[i].DatasourceUID ||
+			query.RelativeTimeRange != actual[i].RelativeTimeRange {
+			return false
+		}
+
+		var expectedModel, actualModel any
+		if json.Unmarshal(query.Model, &expectedModel) != nil || json.Unmarshal(actual[i].Model, &actualModel) != nil {
+			return false
+		}
+		if !reflect.DeepEqual(expectedModel, actualModel) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareContactPoint returns the fields of the integration which differ from its definition in the file.
+func compareContactPoint(expected, actual definitions.EmbeddedContactPoint) []string {
+	var fields []string
+	check := func(field string, equal bool) {
+		if !equal {
+			fields = append(fields, field)
+		}
+	}
+
+	check("name", expected.Name == actual.Name)
+	check("type", expected.Type == actual.Type)
+	check("settings", len(drift.CompareJSON(settingsMap(expected), settingsMap(actual))) == 0)
+	check("disableResolveMessage", expected.DisableResolveMessage == actual.DisableResolveMessage)
+	return fields
+}
+
+func settingsMap(contactPoint definitions.EmbeddedContactPoint) map[string]any {
+	if contactPoint.Settings == nil {
+		return nil
+	}
+	return contactPoint.Settings.MustMap()
+}
+
+// compareTemplate compares the content of the template after the normalization applied when it is saved.
+func compareTemplate(expected, actual definitions.NotificationTemplate) []string {
+	if err := expected.Validate(); err != nil || expected.Template != actual.Template {
+		return []string{"template"}
+	}
+	return nil
+}
+
+// toMap converts a resource to the map of its JSON representation, to compare it with drift.CompareJSON.
+func toMap(v any) map[string]any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	values := map[string]any{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+func valueOrZero[T any](value *T) T {
+	var zero T
+	if value == nil {
+		return zero
+	}
+	return *value
+}
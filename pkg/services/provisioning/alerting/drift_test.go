@@ -0,0 +1,268 @@
+package alerting
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+type fakeRuleLister struct {
+	rules       []*models.AlertRule
+	provenances map[string]models.Provenance
+}
+
+func (f *fakeRuleLister) GetAlertRules(_ context.Context, user identity.Requester) ([]*models.AlertRule, map[string]models.Provenance, error) {
+	var rules []*models.AlertRule
+	for _, rule := range f.rules {
+		if rule.OrgID == user.GetOrgID() {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, f.provenances, nil
+}
+
+func TestAlertRuleDrift(t *testing.T) {
+	ctx := context.Background()
+	configReader := newRulesConfigReader(log.NewNopLogger())
+	files, err := configReader.readConfig(ctx, testFileCorrectProperties)
+	require.NoError(t, err)
+	path := filepath.Join(testFileCorrectProperties, "rules.yml")
+
+	// the rule as it is stored after provisioning
+	stored := files[0].Groups[0].Rules[0]
+	stored.Data = append([]models.AlertQuery{}, stored.Data...)
+	stored.RuleGroup = "my_group"
+	stored.IntervalSeconds = 10
+	require.NoError(t, stored.PreSave(time.Now, nil))
+
+	lister := &fakeRuleLister{
+		rules: []*models.AlertRule{
+			&stored,
+			{UID: "orphaned", OrgID: 1},
+			{UID: "created_in_the_ui", OrgID: 1},
+			{UID: "other_org", OrgID: 2},
+		},
+		provenances: map[string]models.Provenance{
+			"my_first_rule": models.ProvenanceFile,
+			"orphaned":      models.ProvenanceFile,
+			"other_org":     models.ProvenanceFile,
+		},
+	}
+
+	items, err := detectRuleDrift(ctx, testFileCorrectProperties, files, lister)
+	require.NoError(t, err)
+	require.Equal(t, []drift.Item{
+		{Kind: drift.KindAlertRule, OrgID: 1, Name: "orphaned", State: drift.StateOrphaned},
+	}, items)
+
+	t.Run("should report modified rules", func(t *testing.T) {
+		stored.Labels = map[string]string{"team": "infra"}
+		stored.For = time.Hour
+		items, err := detectRuleDrift(ctx, testFileCorrectProperties, files, lister)
+		require.NoError(t, err)
+		require.Equal(t, drift.Item{
+			Kind:   drift.KindAlertRule,
+			OrgID:  1,
+			Name:   "my_first_rule",
+			Path:   path,
+			State:  drift.StateModified,
+			Fields: []string{"for", "labels"},
+		}, items[0])
+	})
+
+	t.Run("should report missing rules", func(t *testing.T) {
+		lister.rules = lister.rules[1:]
+		items, err := detectRuleDrift(ctx, testFileCorrectProperties, files, lister)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{
+			{Kind: drift.KindAlertRule, OrgID: 1, Name: "my_first_rule", Path: path, State: drift.StateMissing},
+			{Kind: drift.KindAlertRule, OrgID: 1, Name: "orphaned", State: drift.StateOrphaned},
+		}, items)
+	})
+}
+
+type fakeNotificationResources struct {
+	contactPoints map[int64][]definitions.EmbeddedContactPoint
+	policies      map[int64]definitions.Route
+	muteTimings   map[int64][]definitions.MuteTimeInterval
+	templates     map[int64][]definitions.NotificationTemplate
+}
+
+func (f *fakeNotificationResources) GetContactPoints(_ context.Context, q provisioning.ContactPointQuery, _ identity.Requester) ([]definitions.EmbeddedContactPoint, error) {
+	return f.contactPoints[q.OrgID], nil
+}
+
+func (f *fakeNotificationResources) GetPolicyTree(_ context.Context, orgID int64) (definitions.Route, string, error) {
+	return f.policies[orgID], "", nil
+}
+
+func (f *fakeNotificationResources) GetMuteTimings(_ context.Context, orgID int64) ([]definitions.MuteTimeInterval, error) {
+	return f.muteTimings[orgID], nil
+}
+
+func (f *fakeNotificationResources) GetTemplates(_ context.Context, orgID int64) ([]definitions.NotificationTemplate, error) {
+	return f.templates[orgID], nil
+}
+
+func TestNotificationResourceDrift(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join("provisioning", "alerting.yaml")
+	fromFile := string(models.ProvenanceFile)
+
+	slack := definitions.EmbeddedContactPoint{
+		UID:      "slack",
+		Name:     "team",
+		Type:     "slack",
+		Settings: simplejson.NewFromAny(map[string]any{"recipient": "#alerts", "token": "secret"}),
+	}
+	weekends := definitions.MuteTimeInterval{MuteTimeInterval: config.MuteTimeInterval{
+		Name:          "weekends",
+		TimeIntervals: []timeinterval.TimeInterval{{Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}}}},
+	}}
+	files := []*AlertingFile{{
+		Filename:      "alerting.yaml",
+		ContactPoints: []ContactPoint{{OrgID: 1, ContactPoints: []definitions.EmbeddedContactPoint{slack}}},
+		Policies:      []NotificiationPolicy{{OrgID: 1, Policy: definitions.Route{Receiver: "team", GroupByStr: []string{"alertname"}}}},
+		MuteTimes:     []MuteTime{{OrgID: 1, MuteTime: weekends}},
+		Templates:     []Template{{OrgID: 1, Data: definitions.NotificationTemplate{Name: "title", Template: "{{ .CommonLabels.alertname }}"}}},
+	}}
+
+	stored := func() *fakeNotificationResources {
+		storedSlack := slack
+		storedSlack.Settings = simplejson.NewFromAny(map[string]any{"recipient": "#alerts", "token": "secret"})
+		storedSlack.Provenance = fromFile
+		storedWeekends := weekends
+		storedWeekends.Provenance = definitions.Provenance(fromFile)
+		return &fakeNotificationResources{
+			contactPoints: map[int64][]definitions.EmbeddedContactPoint{1: {
+				storedSlack,
+				{UID: "orphaned", Name: "old", Type: "email", Provenance: fromFile},
+				{UID: "created_in_the_ui", Name: "ui", Type: "email"},
+			}},
+			policies:    map[int64]definitions.Route{1: {Receiver: "team", GroupByStr: []string{"alertname"}, Provenance: definitions.Provenance(fromFile)}},
+			muteTimings: map[int64][]definitions.MuteTimeInterval{1: {storedWeekends}},
+			templates: map[int64][]definitions.NotificationTemplate{1: {{
+				Name:       "title",
+				Template:   "{{ define \"title\" }}\n  {{ .CommonLabels.alertname }}\n{{ end }}",
+				Provenance: definitions.Provenance(fromFile),
+			}}},
+		}
+	}
+
+	t.Run("should report orphaned contact points", func(t *testing.T) {
+		items, err := detectContactPointDrift(ctx, "provisioning", files, stored())
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{
+			{Kind: drift.KindContactPoint, OrgID: 1, Name: "orphaned", State: drift.StateOrphaned},
+		}, items)
+	})
+
+	t.Run("should report modified contact points", func(t *testing.T) {
+		resources := stored()
+		resources.contactPoints[1][0].Settings.Set("token", "changed")
+		resources.contactPoints[1][0].DisableResolveMessage = true
+		items, err := detectContactPointDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Equal(t, drift.Item{
+			Kind:   drift.KindContactPoint,
+			OrgID:  1,
+			Name:   "slack",
+			Path:   path,
+			State:  drift.StateModified,
+			Fields: []string{"settings", "disableResolveMessage"},
+		}, items[1])
+	})
+
+	t.Run("should report modified notification policies", func(t *testing.T) {
+		resources := stored()
+		items, err := detectPolicyDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Empty(t, items)
+
+		resources.policies[1] = definitions.Route{Receiver: "other", Provenance: definitions.Provenance(fromFile)}
+		items, err = detectPolicyDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{{
+			Kind:   drift.KindNotificationPolicy,
+			OrgID:  1,
+			Name:   "policies",
+			Path:   path,
+			State:  drift.StateModified,
+			Fields: []string{"group_by", "receiver"},
+		}}, items)
+	})
+
+	t.Run("should report policies which were reset in the files as orphaned", func(t *testing.T) {
+		reset := []*AlertingFile{{Filename: "reset.yaml", ResetPolicies: []OrgID{1}}}
+		items, err := detectPolicyDrift(ctx, "provisioning", reset, stored())
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{
+			{Kind: drift.KindNotificationPolicy, OrgID: 1, Name: "policies", State: drift.StateOrphaned},
+		}, items)
+	})
+
+	t.Run("should report modified mute timings", func(t *testing.T) {
+		resources := stored()
+		items, err := detectMuteTimingDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Empty(t, items)
+
+		resources.muteTimings[1][0].TimeIntervals = nil
+		items, err = detectMuteTimingDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{{
+			Kind:   drift.KindMuteTiming,
+			OrgID:  1,
+			Name:   "weekends",
+			Path:   path,
+			State:  drift.StateModified,
+			Fields: []string{"time_intervals"},
+		}}, items)
+	})
+
+	t.Run("should compare templates after they are normalized", func(t *testing.T) {
+		resources := stored()
+		items, err := detectTemplateDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Empty(t, items)
+
+		resources.templates[1][0].Template = "{{ define \"title\" }}changed{{ end }}"
+		items, err = detectTemplateDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{{
+			Kind:   drift.KindTemplate,
+			OrgID:  1,
+			Name:   "title",
+			Path:   path,
+			State:  drift.StateModified,
+			Fields: []string{"template"},
+		}}, items)
+	})
+
+	t.Run("should report missing resources", func(t *testing.T) {
+		resources := &fakeNotificationResources{}
+		items, err := detectMuteTimingDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{
+			{Kind: drift.KindMuteTiming, OrgID: 1, Name: "weekends", Path: path, State: drift.StateMissing},
+		}, items)
+		items, err = detectTemplateDrift(ctx, "provisioning", files, resources)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{
+			{Kind: drift.KindTemplate, OrgID: 1, Name: "title", Path: path, State: drift.StateMissing},
+		}, items)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/storage/legacysql/dualwrite"
 )
@@ -24,6 +25,8 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	DetectDrift(ctx context.Context) ([]drift.Item, error)
+	RevertDrift(ctx context.Context, items []drift.Item) error
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -117,6 +120,34 @@ func (provider *Provisioner) CleanUpOrphanedDashboards(ctx context.Context) {
 	}
 }
 
+// DetectDrift compares the dashboard files of all the configs with the dashboards in the database.
+func (provider *Provisioner) DetectDrift(ctx context.Context) ([]drift.Item, error) {
+	var items []drift.Item
+	for _, reader := range provider.fileReaders {
+		readerItems, err := reader.detectDrift(ctx)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// the folder can appear later, the same as when provisioning
+				provider.log.Warn("Failed to detect drift of config", "name", reader.Cfg.Name, "error", err)
+				continue
+			}
+			return nil, fmt.Errorf("failed to detect drift of config %v: %w", reader.Cfg.Name, err)
+		}
+		items = append(items, readerItems...)
+	}
+	return items, nil
+}
+
+// RevertDrift saves the drifted dashboards from their files again.
+func (provider *Provisioner) RevertDrift(ctx context.Context, items []drift.Item) error {
+	for _, reader := range provider.fileReaders {
+		if err := reader.revertDrift(ctx, items); err != nil {
+			return fmt.Errorf("failed to revert drift of config %v: %w", reader.Cfg.Name, err)
+		}
+	}
+	return nil
+}
+
 // PollChanges starts polling for changes in dashboard definition files. It creates a goroutine for each provider
 // defined in the config.
 func (provider *Provisioner) PollChanges(ctx context.Context) {
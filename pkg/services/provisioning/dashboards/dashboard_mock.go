@@ -1,6 +1,10 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -8,6 +12,8 @@ type calls struct {
 	PollChanges                 []any
 	GetProvisionerResolvedPath  []any
 	GetAllowUIUpdatesFromConfig []any
+	DetectDrift                 []any
+	RevertDrift                 []any
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +23,8 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	DetectDriftFunc                 func(ctx context.Context) ([]drift.Item, error)
+	RevertDriftFunc                 func(ctx context.Context, items []drift.Item) error
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -67,3 +75,21 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// DetectDrift is a mock implementation of `Provisioner.DetectDrift`
+func (dpm *ProvisionerMock) DetectDrift(ctx context.Context) ([]drift.Item, error) {
+	dpm.Calls.DetectDrift = append(dpm.Calls.DetectDrift, nil)
+	if dpm.DetectDriftFunc != nil {
+		return dpm.DetectDriftFunc(ctx)
+	}
+	return nil, nil
+}
+
+// RevertDrift is a mock implementation of `Provisioner.RevertDrift`
+func (dpm *ProvisionerMock) RevertDrift(ctx context.Context, items []drift.Item) error {
+	dpm.Calls.RevertDrift = append(dpm.Calls.RevertDrift, items)
+	if dpm.RevertDriftFunc != nil {
+		return dpm.RevertDriftFunc(ctx, items)
+	}
+	return nil
+}
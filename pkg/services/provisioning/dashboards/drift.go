@@ -0,0 +1,154 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+// detectDrift compares the dashboard files with the dashboards in the database.
+// Unlike walkDisk, it does not rely on the checksums of the files, so changes made to the dashboards in the database are found too.
+func (fr *FileReader) detectDrift(ctx context.Context) ([]drift.Item, error) {
+	resolvedPath := fr.resolvedPath()
+	if _, err := os.Stat(resolvedPath); err != nil {
+		return nil, err
+	}
+
+	provisionedDashboardRefs, err := getProvisionedDashboardsByPath(ctx, fr.dashboardProvisioningService, fr.Cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	filesFoundOnDisk := map[string]os.FileInfo{}
+	if err := filepath.Walk(resolvedPath, createWalkFn(filesFoundOnDisk)); err != nil {
+		return nil, err
+	}
+
+	ctx, _ = identity.WithServiceIdentity(ctx, fr.Cfg.OrgID)
+	var items []drift.Item
+	for path, provisioningData := range provisionedDashboardRefs {
+		if _, existsOnDisk := filesFoundOnDisk[path]; existsOnDisk {
+			continue
+		}
+		dash, err := fr.getDashboard(ctx, provisioningData.DashboardID)
+		if err != nil {
+			return nil, err
+		}
+		if dash == nil {
+			continue
+		}
+		items = append(items, drift.Item{
+			Kind:  drift.KindDashboard,
+			OrgID: fr.Cfg.OrgID,
+			Name:  dash.UID,
+			Path:  path,
+			State: drift.StateOrphaned,
+		})
+	}
+
+	for path, fileInfo := range filesFoundOnDisk {
+		resolvedFileInfo, err := resolveSymlink(fileInfo, path)
+		if err != nil {
+			return nil, err
+		}
+		jsonFile, err := fr.readDashboardFromFile(path, resolvedFileInfo.ModTime(), 0, "")
+		if err != nil {
+			// the file is not provisioned either, and walkDisk already logs the error
+			continue
+		}
+
+		item := drift.Item{
+			Kind:  drift.KindDashboard,
+			OrgID: fr.Cfg.OrgID,
+			Name:  jsonFile.dashboard.Dashboard.UID,
+			Path:  path,
+			State: drift.StateMissing,
+		}
+
+		var dash *dashboards.Dashboard
+		if provisioningData, ok := provisionedDashboardRefs[path]; ok {
+			dash, err = fr.getDashboard(ctx, provisioningData.DashboardID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if dash != nil {
+			// the id and the version are managed by the database, and the uid is generated when the file does not set one
+			ignored := []string{"id", "version"}
+			if item.Name == "" {
+				ignored = append(ignored, "uid")
+			}
+			item.Name = dash.UID
+			item.State = drift.StateModified
+			item.Fields = drift.CompareJSON(jsonFile.dashboard.Dashboard.Data.MustMap(), dash.Data.MustMap(), ignored...)
+			if len(item.Fields) == 0 {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+	return items, nil
+}
+
+// revertDrift saves the dashboards of the files again, so that the drifted dashboards match their files.
+// Orphaned dashboards are deleted or unprovisioned like when their files are removed.
+func (fr *FileReader) revertDrift(ctx context.Context, items []drift.Item) error {
+	forced := map[string]bool{}
+	for _, item := range items {
+		if item.Kind == drift.KindDashboard && item.OrgID == fr.Cfg.OrgID {
+			forced[item.Path] = true
+		}
+	}
+	if len(forced) == 0 {
+		return nil
+	}
+	return fr.walkDiskForced(ctx, forced)
+}
+
+// forgetChecksums makes the dashboards of the forced files look outdated, so they are saved again.
+// Dashboards which were deleted from the database are unprovisioned, so they are created again.
+func (fr *FileReader) forgetChecksums(ctx context.Context, provisionedDashboardRefs map[string]*dashboards.DashboardProvisioning, forced map[string]bool) error {
+	ctx, _ = identity.WithServiceIdentity(ctx, fr.Cfg.OrgID)
+	for path := range forced {
+		provisioningData, ok := provisionedDashboardRefs[path]
+		if !ok {
+			continue
+		}
+
+		dash, err := fr.getDashboard(ctx, provisioningData.DashboardID)
+		if err != nil {
+			return err
+		}
+		if dash == nil {
+			if err := fr.dashboardProvisioningService.UnprovisionDashboard(ctx, provisioningData.DashboardID); err != nil {
+				return err
+			}
+			delete(provisionedDashboardRefs, path)
+			continue
+		}
+
+		outdated := *provisioningData
+		outdated.CheckSum = ""
+		provisionedDashboardRefs[path] = &outdated
+	}
+	return nil
+}
+
+// getDashboard returns the dashboard with the given id, or nil if it does not exist
+func (fr *FileReader) getDashboard(ctx context.Context, dashboardID int64) (*dashboards.Dashboard, error) {
+	dash, err := fr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dashboardID, OrgID: fr.Cfg.OrgID})
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return nil, nil
+	}
+	return dash, err
+}
@@ -0,0 +1,84 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type driftDashboardStore map[int64]*dashboards.Dashboard
+
+func (s driftDashboardStore) GetDashboard(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+	if dash, ok := s[query.ID]; ok {
+		return dash, nil
+	}
+	return nil, dashboards.ErrDashboardNotFound
+}
+
+func TestDashboardDrift(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"modified.json":  `{"uid": "modified", "title": "Modified", "tags": ["a"]}`,
+		"missing.json":   `{"title": "Missing"}`,
+		"unchanged.json": `{"title": "Unchanged", "refresh": "1m"}`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	cfg := &config{Name: configName, Type: "file", OrgID: 1, Options: map[string]any{"path": dir}}
+	store := driftDashboardStore{
+		1: {ID: 1, UID: "modified", Data: simplejson.NewFromAny(map[string]any{"id": 1, "uid": "modified", "title": "Changed in the UI", "tags": []any{"a"}, "version": 2})},
+		2: {ID: 2, UID: "generated", Data: simplejson.NewFromAny(map[string]any{"id": 2, "uid": "generated", "title": "Unchanged", "refresh": "1m", "version": 1})},
+		3: {ID: 3, UID: "orphaned", Data: simplejson.NewFromAny(map[string]any{"title": "Orphaned"})},
+	}
+	reader, err := NewDashboardFileReader(cfg, log.New("test-logger"), nil, store, nil)
+	require.NoError(t, err)
+
+	path := func(name string) string {
+		return filepath.Join(reader.resolvedPath(), name)
+	}
+	checksum, err := util.Md5SumString(files["unchanged.json"])
+	require.NoError(t, err)
+	provisioned := []*dashboards.DashboardProvisioning{
+		{DashboardID: 1, Name: configName, ExternalID: path("modified.json"), CheckSum: "checksum"},
+		{DashboardID: 2, Name: configName, ExternalID: path("unchanged.json"), CheckSum: checksum},
+		{DashboardID: 3, Name: configName, ExternalID: path("orphaned.json"), CheckSum: "checksum"},
+	}
+
+	fakeService := &dashboards.FakeDashboardProvisioning{}
+	defer fakeService.AssertExpectations(t)
+	fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(provisioned, nil)
+	reader.dashboardProvisioningService = fakeService
+
+	items, err := reader.detectDrift(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []drift.Item{
+		{Kind: drift.KindDashboard, OrgID: 1, Path: path("missing.json"), State: drift.StateMissing},
+		{Kind: drift.KindDashboard, OrgID: 1, Name: "modified", Path: path("modified.json"), State: drift.StateModified, Fields: []string{"title"}},
+		{Kind: drift.KindDashboard, OrgID: 1, Name: "orphaned", Path: path("orphaned.json"), State: drift.StateOrphaned},
+	}, items)
+
+	t.Run("should save the drifted dashboards again", func(t *testing.T) {
+		var saved []string
+		fakeService.On("DeleteProvisionedDashboard", mock.Anything, int64(3), int64(1)).Return(nil).Once()
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).
+			Return(&dashboards.Dashboard{}, nil).
+			Run(func(args mock.Arguments) {
+				saved = append(saved, args.Get(2).(*dashboards.DashboardProvisioning).ExternalID)
+			})
+
+		require.NoError(t, reader.revertDrift(context.Background(), items))
+		require.ElementsMatch(t, []string{path("missing.json"), path("modified.json")}, saved)
+	})
+}
@@ -95,6 +95,11 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	return fr.walkDiskForced(ctx, nil)
+}
+
+// walkDiskForced is walkDisk, but the dashboards of the files in forced are saved again even when the files did not change.
+func (fr *FileReader) walkDiskForced(ctx context.Context, forced map[string]bool) error {
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -106,6 +111,10 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 		return err
 	}
 
+	if err := fr.forgetChecksums(ctx, provisionedDashboardRefs, forced); err != nil {
+		return err
+	}
+
 	// Find relevant files
 	filesFoundOnDisk := map[string]os.FileInfo{}
 	if err := filepath.Walk(resolvedPath, createWalkFn(filesFoundOnDisk)); err != nil {
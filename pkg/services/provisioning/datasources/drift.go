@@ -0,0 +1,93 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+// DetectDrift compares the datasources of the config files with the datasources in the database.
+// The secure json data is encrypted in the database, so it is not compared.
+func DetectDrift(ctx context.Context, configDirectory string, dsService BaseDataSourceService, orgService org.Service) ([]drift.Item, error) {
+	dc := newDatasourceProvisioner(log.New("provisioning.datasources"), dsService, nil, orgService)
+	return dc.detectDrift(ctx, configDirectory)
+}
+
+func (dc *DatasourceProvisioner) detectDrift(ctx context.Context, configPath string) ([]drift.Item, error) {
+	configs, err := dc.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []drift.Item
+	defined := map[DataSourceMapKey]bool{}
+	for _, cfg := range configs {
+		for _, ds := range cfg.Datasources {
+			defined[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] = true
+
+			item := drift.Item{Kind: drift.KindDatasource, OrgID: ds.OrgID, Name: ds.Name, State: drift.StateMissing}
+			dataSource, err := dc.dsService.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: ds.OrgID, Name: ds.Name})
+			if err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return nil, err
+			}
+			if dataSource != nil {
+				item.State = drift.StateModified
+				item.Fields = compareDataSource(ds, dataSource)
+				if len(item.Fields) == 0 {
+					continue
+				}
+			}
+			items = append(items, item)
+		}
+	}
+
+	// Like when provisioning, only the prunable datasources are known to come from files that were removed
+	prunableProvisionedDataSources, err := dc.dsService.GetPrunableProvisionedDataSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range prunableProvisionedDataSources {
+		if defined[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] {
+			continue
+		}
+		items = append(items, drift.Item{Kind: drift.KindDatasource, OrgID: ds.OrgID, Name: ds.Name, State: drift.StateOrphaned})
+	}
+
+	return items, nil
+}
+
+// compareDataSource returns the fields of the datasource which differ from its config, with the names used in the config files
+func compareDataSource(expected *upsertDataSourceFromConfig, actual *datasources.DataSource) []string {
+	var fields []string
+	check := func(field string, equal bool) {
+		if !equal {
+			fields = append(fields, field)
+		}
+	}
+
+	check("type", expected.Type == actual.Type)
+	check("access", datasources.DsAccess(expected.Access) == actual.Access)
+	check("url", expected.URL == actual.URL)
+	check("user", expected.User == actual.User)
+	check("database", expected.Database == actual.Database)
+	check("basicAuth", expected.BasicAuth == actual.BasicAuth)
+	check("basicAuthUser", expected.BasicAuthUser == actual.BasicAuthUser)
+	check("withCredentials", expected.WithCredentials == actual.WithCredentials)
+	check("isDefault", expected.IsDefault == actual.IsDefault)
+	check("editable", expected.Editable == !actual.ReadOnly)
+	check("uid", expected.UID == "" || expected.UID == actual.UID)
+
+	var jsonData map[string]any
+	if actual.JsonData != nil {
+		jsonData = actual.JsonData.MustMap()
+	}
+	for _, field := range drift.CompareJSON(expected.JSONData, jsonData) {
+		fields = append(fields, "jsonData."+field)
+	}
+
+	return fields
+}
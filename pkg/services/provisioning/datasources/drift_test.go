@@ -0,0 +1,45 @@
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+func TestDatasourceDrift(t *testing.T) {
+	store := &spyStore{
+		items: []*datasources.DataSource{
+			{Name: "Graphite", OrgID: 1, Type: "graphite", Access: datasources.DS_ACCESS_PROXY, URL: "http://localhost:8081", JsonData: simplejson.NewFromAny(map[string]any{"graphiteVersion": "1.1"})},
+			{Name: "Old", OrgID: 1, IsPrunable: true},
+			{Name: "Manual", OrgID: 1},
+		},
+	}
+	orgFake := &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1}}
+	dc := newDatasourceProvisioner(logger, store, nil, orgFake)
+
+	items, err := dc.detectDrift(context.Background(), twoDatasourcesConfig)
+	require.NoError(t, err)
+	require.Equal(t, []drift.Item{
+		{Kind: drift.KindDatasource, OrgID: 1, Name: "Graphite", State: drift.StateModified, Fields: []string{"url", "editable", "jsonData.graphiteVersion"}},
+		{Kind: drift.KindDatasource, OrgID: 1, Name: "Prometheus", State: drift.StateMissing},
+		{Kind: drift.KindDatasource, OrgID: 1, Name: "Old", State: drift.StateOrphaned},
+	}, items)
+
+	t.Run("should not report datasources matching their config", func(t *testing.T) {
+		store.items[0].URL = "http://localhost:8080"
+		store.items[0].ReadOnly = true
+		store.items[0].JsonData = simplejson.New()
+		store.items = append(store.items, &datasources.DataSource{Name: "Prometheus", OrgID: 1, Type: "prometheus", Access: datasources.DS_ACCESS_PROXY, URL: "http://localhost:9090", ReadOnly: true})
+
+		items, err := dc.detectDrift(context.Background(), twoDatasourcesConfig)
+		require.NoError(t, err)
+		require.Equal(t, []drift.Item{{Kind: drift.KindDatasource, OrgID: 1, Name: "Old", State: drift.StateOrphaned}}, items)
+	})
+}
@@ -0,0 +1,117 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+// ScanDrift compares the file-provisioned resources with the database, and keeps the report for GetDriftReport.
+// When the auto revert is enabled, the provisioning files of the drifted resources are applied again.
+func (ps *ProvisioningServiceImpl) ScanDrift(ctx context.Context) *drift.Report {
+	report := &drift.Report{Started: time.Now(), Items: []drift.Item{}}
+
+	ps.mutex.Lock()
+	dashboardProvisioner := ps.dashboardProvisioner
+	ps.mutex.Unlock()
+
+	detectors := []struct {
+		kind   drift.Kind
+		detect func(ctx context.Context) ([]drift.Item, error)
+	}{
+		{drift.KindDashboard, dashboardProvisioner.DetectDrift},
+		{drift.KindDatasource, func(ctx context.Context) ([]drift.Item, error) {
+			datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
+			return ps.detectDatasourceDrift(ctx, datasourcePath, ps.datasourceService, ps.orgService)
+		}},
+		{drift.KindPlugin, func(ctx context.Context) ([]drift.Item, error) {
+			appPath := filepath.Join(ps.Cfg.ProvisioningPath, "plugins")
+			return ps.detectPluginDrift(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService)
+		}},
+		{drift.KindAlertRule, func(ctx context.Context) ([]drift.Item, error) {
+			return ps.detectAlertingDrift(ctx, ps.alertingProvisionerConfig())
+		}},
+	}
+	for _, detector := range detectors {
+		items, err := detector.detect(ctx)
+		if err != nil {
+			ps.log.Error("Failed to detect drift", "kind", detector.kind, "error", err)
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", detector.kind, err))
+			continue
+		}
+		report.Items = append(report.Items, items...)
+	}
+
+	if len(report.Items) > 0 {
+		ps.log.Warn("Provisioned resources drifted from their files", "count", len(report.Items))
+		if ps.Cfg.Provisioning.DriftAutoRevert {
+			if err := ps.revertDrift(ctx, dashboardProvisioner, report.Items); err != nil {
+				ps.log.Error("Failed to revert drift", "error", err)
+				report.Errors = append(report.Errors, fmt.Sprintf("revert: %s", err))
+			} else {
+				report.Reverted = true
+			}
+		}
+	}
+
+	report.Finished = time.Now()
+	ps.driftMutex.Lock()
+	ps.driftReport = report
+	ps.driftMutex.Unlock()
+	return report
+}
+
+// GetDriftReport returns the report of the last drift scan, or nil if no scan ran yet
+func (ps *ProvisioningServiceImpl) GetDriftReport() *drift.Report {
+	ps.driftMutex.Lock()
+	defer ps.driftMutex.Unlock()
+	return ps.driftReport
+}
+
+// revertDrift applies the provisioning files again for each kind of drifted resources.
+// Orphaned plugin settings and alert rules are kept, as their files no longer say what to do with them.
+func (ps *ProvisioningServiceImpl) revertDrift(ctx context.Context, dashboardProvisioner dashboards.DashboardProvisioner, items []drift.Item) error {
+	byKind := map[drift.Kind][]drift.Item{}
+	for _, item := range items {
+		byKind[item.Kind] = append(byKind[item.Kind], item)
+	}
+
+	if len(byKind[drift.KindDatasource]) > 0 {
+		if err := ps.ProvisionDatasources(ctx); err != nil {
+			return err
+		}
+	}
+	if len(byKind[drift.KindPlugin]) > 0 {
+		if err := ps.ProvisionPlugins(ctx); err != nil {
+			return err
+		}
+	}
+	if len(byKind[drift.KindAlertRule]) > 0 {
+		if err := ps.ProvisionAlerting(ctx); err != nil {
+			return err
+		}
+	}
+	if len(byKind[drift.KindDashboard]) > 0 {
+		if err := dashboardProvisioner.RevertDrift(ctx, byKind[drift.KindDashboard]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ps *ProvisioningServiceImpl) scanDriftPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(ps.Cfg.Provisioning.DriftScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ps.ScanDrift(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package drift describes how the resources in the database differ from the provisioning files
+package drift
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Kind is the type of a provisioned resource
+type Kind string
+
+const (
+	KindDashboard          Kind = "dashboard"
+	KindDatasource         Kind = "datasource"
+	KindPlugin             Kind = "plugin"
+	KindAlertRule          Kind = "alert-rule"
+	KindContactPoint       Kind = "contact-point"
+	KindNotificationPolicy Kind = "notification-policy"
+	KindMuteTiming         Kind = "mute-timing"
+	KindTemplate           Kind = "notification-template"
+)
+
+// State describes how a resource drifted from its provisioning file
+type State string
+
+const (
+	// The resource is defined in a file, but does not exist in the database
+	StateMissing State = "missing"
+	// The resource in the database differs from its definition in the file
+	StateModified State = "modified"
+	// The resource was provisioned, but it is no longer defined in any file
+	StateOrphaned State = "orphaned"
+)
+
+// Item is a resource which drifted from its provisioning file
+type Item struct {
+	Kind  Kind  `json:"kind"`
+	OrgID int64 `json:"orgId"`
+	// The UID of the resource, or its name for resources without one
+	Name string `json:"name"`
+	// The file defining the resource, when it is known
+	Path  string `json:"path,omitempty"`
+	State State  `json:"state"`
+	// The fields which differ from the file, for modified resources
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Report is the result of a drift scan
+type Report struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Items    []Item    `json:"items"`
+	// The errors of the provisioners which could not be scanned
+	Errors []string `json:"errors,omitempty"`
+	// Whether the provisioning files were applied again to revert the drift
+	Reverted bool `json:"reverted"`
+}
+
+// CompareJSON returns the sorted keys whose values differ between the two objects.
+// The values are compared after a JSON round trip, so that e.g. an int and a float64 holding the same number are equal.
+func CompareJSON(expected, actual map[string]any, ignore ...string) []string {
+	expected, actual = normalize(expected), normalize(actual)
+
+	var fields []string
+	check := func(key string) {
+		if slices.Contains(ignore, key) || slices.Contains(fields, key) {
+			return
+		}
+		if !reflect.DeepEqual(expected[key], actual[key]) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range expected {
+		check(key)
+	}
+	for key := range actual {
+		check(key)
+	}
+
+	slices.Sort(fields)
+	return fields
+}
+
+func normalize(values map[string]any) map[string]any {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return values
+	}
+	normalized := map[string]any{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return values
+	}
+	return normalized
+}
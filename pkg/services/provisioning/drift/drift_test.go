@@ -0,0 +1,40 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareJSON(t *testing.T) {
+	expected := map[string]any{
+		"title":   "Dashboard",
+		"refresh": 10,
+		"panels":  []any{map[string]any{"id": 1}},
+		"id":      nil,
+	}
+
+	t.Run("should ignore the type of numbers", func(t *testing.T) {
+		actual := map[string]any{
+			"title":   "Dashboard",
+			"refresh": 10.0,
+			"panels":  []any{map[string]any{"id": float64(1)}},
+			"id":      1,
+		}
+		require.Empty(t, CompareJSON(expected, actual, "id"))
+	})
+
+	t.Run("should return changed, added and removed keys", func(t *testing.T) {
+		actual := map[string]any{
+			"title":  "Changed",
+			"panels": []any{map[string]any{"id": 1}},
+			"tags":   []any{"new"},
+		}
+		require.Equal(t, []string{"refresh", "tags", "title"}, CompareJSON(expected, actual, "id"))
+	})
+
+	t.Run("should handle empty objects", func(t *testing.T) {
+		require.Empty(t, CompareJSON(nil, map[string]any{}))
+		require.Equal(t, []string{"a"}, CompareJSON(nil, map[string]any{"a": true}))
+	})
+}
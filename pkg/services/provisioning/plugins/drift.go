@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+// DetectDrift compares the apps of the config files with the plugin settings in the database.
+// The plugin settings do not record whether they were provisioned, so orphaned settings are not reported.
+func DetectDrift(ctx context.Context, configDirectory string, pluginStore pluginstore.Store, pluginSettings pluginsettings.Service, orgService org.Service) ([]drift.Item, error) {
+	logger := log.New("provisioning.plugins")
+	ap := PluginProvisioner{
+		log:            logger,
+		cfgProvider:    newConfigReader(logger, pluginStore),
+		pluginSettings: pluginSettings,
+		orgService:     orgService,
+		pluginStore:    pluginStore,
+	}
+	return ap.detectDrift(ctx, configDirectory)
+}
+
+func (ap *PluginProvisioner) detectDrift(ctx context.Context, configPath string) ([]drift.Item, error) {
+	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []drift.Item
+	for _, cfg := range configs {
+		for _, app := range cfg.Apps {
+			if err := ap.setOrgID(ctx, app); err != nil {
+				return nil, err
+			}
+
+			item := drift.Item{Kind: drift.KindPlugin, OrgID: app.OrgID, Name: app.PluginID, State: drift.StateMissing}
+			ps, err := ap.pluginSettings.GetPluginSettingByPluginID(ctx, &pluginsettings.GetByPluginIDArgs{
+				OrgID:    app.OrgID,
+				PluginID: app.PluginID,
+			})
+			if err != nil && !errors.Is(err, pluginsettings.ErrPluginSettingNotFound) {
+				return nil, err
+			}
+			if ps != nil {
+				item.State = drift.StateModified
+				item.Fields = comparePluginSetting(app, ps)
+				if len(item.Fields) == 0 {
+					continue
+				}
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// comparePluginSetting returns the fields of the plugin setting which differ from its config
+func comparePluginSetting(expected *appFromConfig, actual *pluginsettings.DTO) []string {
+	var fields []string
+	if expected.Enabled != actual.Enabled {
+		fields = append(fields, "enabled")
+	}
+	if expected.Pinned != actual.Pinned {
+		fields = append(fields, "pinned")
+	}
+	for _, field := range drift.CompareJSON(expected.JSONData, actual.JSONData) {
+		fields = append(fields, "jsonData."+field)
+	}
+	return fields
+}
@@ -0,0 +1,39 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
+
+func TestPluginDrift(t *testing.T) {
+	cfg := []*pluginsAsConfig{
+		{
+			Apps: []*appFromConfig{
+				{PluginID: "test-plugin", OrgID: 2, Enabled: true, Pinned: true},
+				{PluginID: "test-plugin-2", OrgName: "Org 4", Enabled: true},
+			},
+		},
+	}
+	orgMock := orgtest.NewOrgServiceFake()
+	orgMock.ExpectedOrg = &org.Org{ID: 4}
+	ap := PluginProvisioner{
+		log:            log.New("test"),
+		cfgProvider:    &testConfigReader{result: cfg},
+		pluginSettings: &mockStore{},
+		orgService:     orgMock,
+	}
+
+	items, err := ap.detectDrift(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, []drift.Item{
+		{Kind: drift.KindPlugin, OrgID: 2, Name: "test-plugin", State: drift.StateModified, Fields: []string{"enabled", "pinned"}},
+		{Kind: drift.KindPlugin, OrgID: 4, Name: "test-plugin-2", State: drift.StateMissing},
+	}, items)
+}
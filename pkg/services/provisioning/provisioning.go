@@ -30,6 +30,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
@@ -72,6 +73,9 @@ func ProvideService(
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		detectDatasourceDrift:        datasources.DetectDrift,
+		detectPluginDrift:            plugins.DetectDrift,
+		detectAlertingDrift:          prov_alerting.DetectDrift,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -113,6 +117,8 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	ScanDrift(ctx context.Context) *drift.Report
+	GetDriftReport() *drift.Report
 }
 
 // Used for testing purposes
@@ -154,6 +160,11 @@ type ProvisioningServiceImpl struct {
 	provisionDatasources         func(context.Context, string, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service) error
 	provisionPlugins             func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	detectDatasourceDrift        func(context.Context, string, datasources.BaseDataSourceService, org.Service) ([]drift.Item, error)
+	detectPluginDrift            func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) ([]drift.Item, error)
+	detectAlertingDrift          func(context.Context, prov_alerting.ProvisionerConfig) ([]drift.Item, error)
+	driftMutex                   sync.Mutex
+	driftReport                  *drift.Report
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
@@ -218,6 +229,10 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		ps.searchService.TriggerReIndex()
 	}
 
+	if ps.Cfg.Provisioning.DriftScanInterval > 0 {
+		go ps.scanDriftPeriodically(ctx)
+	}
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -282,6 +297,10 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	return ps.provisionAlerting(ctx, ps.alertingProvisionerConfig())
+}
+
+func (ps *ProvisioningServiceImpl) alertingProvisionerConfig() prov_alerting.ProvisionerConfig {
 	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
 	ruleService := provisioning.NewAlertRuleService(
 		ps.alertingStore,
@@ -315,7 +334,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		ps.alertingStore, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(configStore, ps.alertingStore, ps.alertingStore, ps.log, ps.alertingStore)
 	templateService := provisioning.NewTemplateService(configStore, ps.alertingStore, ps.alertingStore, ps.log)
-	cfg := prov_alerting.ProvisionerConfig{
+	return prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
 		FolderService:              ps.folderService,
@@ -325,7 +344,6 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
 	}
-	return ps.provisionAlerting(ctx, cfg)
 }
 
 func (ps *ProvisioningServiceImpl) GetDashboardProvisionerResolvedPath(name string) string {
@@ -1,6 +1,10 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -10,6 +14,8 @@ type Calls struct {
 	ProvisionAlerting                   []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	ScanDrift                           []any
+	GetDriftReport                      []any
 	Run                                 []any
 }
 
@@ -21,6 +27,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	ScanDriftFunc                           func(ctx context.Context) *drift.Report
+	GetDriftReportFunc                      func() *drift.Report
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -83,6 +91,22 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) ScanDrift(ctx context.Context) *drift.Report {
+	mock.Calls.ScanDrift = append(mock.Calls.ScanDrift, nil)
+	if mock.ScanDriftFunc != nil {
+		return mock.ScanDriftFunc(ctx)
+	}
+	return &drift.Report{}
+}
+
+func (mock *ProvisioningServiceMock) GetDriftReport() *drift.Report {
+	mock.Calls.GetDriftReport = append(mock.Calls.GetDriftReport, nil)
+	if mock.GetDriftReportFunc != nil {
+		return mock.GetDriftReportFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
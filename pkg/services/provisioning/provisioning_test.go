@@ -18,6 +18,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/drift"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/storage/legacysql/dualwrite"
@@ -126,6 +127,44 @@ func TestProvisioningServiceImpl(t *testing.T) {
 
 		assert.Equal(t, 2, serviceTest.dashboardProvisionerInstantiations)
 	})
+
+	t.Run("Should scan drift and revert it", func(t *testing.T) {
+		serviceTest := setup(t)
+		service := serviceTest.service
+		dashboardItem := drift.Item{Kind: drift.KindDashboard, OrgID: 1, Name: "dash", State: drift.StateModified, Fields: []string{"title"}}
+		datasourceItem := drift.Item{Kind: drift.KindDatasource, OrgID: 1, Name: "Prometheus", State: drift.StateMissing}
+		serviceTest.mock.DetectDriftFunc = func(ctx context.Context) ([]drift.Item, error) {
+			return []drift.Item{dashboardItem}, nil
+		}
+		service.detectDatasourceDrift = func(context.Context, string, datasources.BaseDataSourceService, org.Service) ([]drift.Item, error) {
+			return []drift.Item{datasourceItem}, nil
+		}
+		service.detectPluginDrift = func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) ([]drift.Item, error) {
+			return nil, errors.New("plugin error")
+		}
+		service.detectAlertingDrift = func(context.Context, prov_alerting.ProvisionerConfig) ([]drift.Item, error) {
+			return nil, nil
+		}
+		datasourceProvisions := 0
+		service.provisionDatasources = func(context.Context, string, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service) error {
+			datasourceProvisions++
+			return nil
+		}
+
+		assert.Nil(t, service.GetDriftReport())
+		report := service.ScanDrift(context.Background())
+		assert.Equal(t, []drift.Item{dashboardItem, datasourceItem}, report.Items)
+		assert.Equal(t, []string{"plugin: plugin error"}, report.Errors)
+		assert.False(t, report.Reverted)
+		assert.Same(t, report, service.GetDriftReport())
+		assert.Empty(t, serviceTest.mock.Calls.RevertDrift)
+
+		service.Cfg.Provisioning.DriftAutoRevert = true
+		report = service.ScanDrift(context.Background())
+		assert.True(t, report.Reverted)
+		assert.Equal(t, 1, datasourceProvisions)
+		assert.Equal(t, []any{[]drift.Item{dashboardItem}}, serviceTest.mock.Calls.RevertDrift)
+	})
 }
 
 type serviceTestStruct struct {
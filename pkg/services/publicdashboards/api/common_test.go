@@ -155,6 +155,7 @@ func buildQueryDataService(t *testing.T, cs datasources.CacheService, fpc *fakeP
 		&fakeDataSourceRequestValidator{},
 		fpc,
 		pCtxProvider,
+		nil,
 	)
 }
 
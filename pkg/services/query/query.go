@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/caching"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
@@ -45,6 +46,7 @@ func ProvideService(
 	dataSourceRequestValidator validations.DataSourceRequestValidator,
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
+	cachingService caching.CachingService,
 ) *ServiceImpl {
 	g := &ServiceImpl{
 		cfg:                        cfg,
@@ -53,6 +55,7 @@ func ProvideService(
 		dataSourceRequestValidator: dataSourceRequestValidator,
 		pluginClient:               pluginClient,
 		pCtxProvider:               pCtxProvider,
+		cachingService:             cachingService,
 		log:                        log.New("query_data"),
 		concurrentQueryLimit:       cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
 	}
@@ -76,6 +79,7 @@ type ServiceImpl struct {
 	dataSourceRequestValidator validations.DataSourceRequestValidator
 	pluginClient               plugins.Client
 	pCtxProvider               *plugincontext.Provider
+	cachingService             caching.CachingService
 	log                        log.Logger
 	concurrentQueryLimit       int
 }
@@ -269,7 +273,39 @@ func (s *ServiceImpl) handleQuerySingleDatasource(ctx context.Context, user iden
 		req.Queries = append(req.Queries, q.query)
 	}
 
-	return s.pluginClient.QueryData(ctx, req)
+	return s.queryDataWithPartialCache(ctx, req)
+}
+
+// queryDataWithPartialCache serves the parts of the time range that are already cached from the cache,
+// and only sends the remaining parts of the queries to the data source.
+func (s *ServiceImpl) queryDataWithPartialCache(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if s.cachingService == nil {
+		return s.pluginClient.QueryData(ctx, req)
+	}
+
+	cr := s.cachingService.HandlePartialQueryRequest(ctx, req)
+	if cr.MergeFn == nil {
+		return s.pluginClient.QueryData(ctx, req)
+	}
+
+	// The results are cached in chunks, so the request for the remaining time range is not cached as a whole.
+	resp, err := s.pluginClient.QueryData(caching.ContextWithoutQueryCache(ctx), cr.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, retry := cr.MergeFn(ctx, resp)
+	if retry != nil {
+		// These queries could not be stitched to their cached results, so they are sent again for their whole time range.
+		full, err := s.pluginClient.QueryData(ctx, retry)
+		if err != nil {
+			return nil, err
+		}
+		for refID, dr := range full.Responses {
+			merged.Responses[refID] = dr
+		}
+	}
+	return merged, nil
 }
 
 // parseRequest parses a request into parsed queries grouped by datasource uid
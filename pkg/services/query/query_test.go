@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/caching"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -451,6 +452,85 @@ func TestQueryDataMultipleSources(t *testing.T) {
 	})
 }
 
+func TestQueryDataPartialCache(t *testing.T) {
+	t.Run("only the uncached part of the time range is sent to the data source", func(t *testing.T) {
+		tc := setup(t)
+		cs := &fakePartialCachingService{}
+		tc.queryService.cachingService = cs
+
+		reqDTO := metricRequestWithQueries(t, `{
+			"refId": "A",
+			"datasource": {
+				"uid": "gIEkMvIVz",
+				"type": "postgres"
+			}
+		}`)
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.NoError(t, err)
+
+		require.NotNil(t, cs.req)
+		require.Same(t, cs.fresh, tc.pluginContext.req)
+		require.Contains(t, res.Responses, "cached")
+	})
+
+	t.Run("queries that can't be stitched are sent again for the whole time range", func(t *testing.T) {
+		tc := setup(t)
+		cs := &fakePartialCachingService{}
+		tc.queryService.cachingService = cs
+		cs.retry = &backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A"}}}
+
+		reqDTO := metricRequestWithQueries(t, `{
+			"refId": "A",
+			"datasource": {
+				"uid": "gIEkMvIVz",
+				"type": "postgres"
+			}
+		}`)
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.NoError(t, err)
+
+		require.Same(t, cs.retry, tc.pluginContext.req)
+		require.Contains(t, res.Responses, "cached")
+	})
+
+	t.Run("requests that can't be split are sent as they are", func(t *testing.T) {
+		tc := setup(t)
+		cs := caching.NewFakeOSSCachingService()
+		tc.queryService.cachingService = cs
+
+		reqDTO := metricRequestWithQueries(t, `{
+			"refId": "A",
+			"datasource": {
+				"uid": "gIEkMvIVz",
+				"type": "postgres"
+			}
+		}`)
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.NoError(t, err)
+		cs.AssertCalls(t, "HandlePartialQueryRequest", 1)
+		require.Len(t, tc.pluginContext.req.Queries, 1)
+	})
+}
+
+type fakePartialCachingService struct {
+	caching.CachingService
+	req   *backend.QueryDataRequest
+	fresh *backend.QueryDataRequest
+	retry *backend.QueryDataRequest
+}
+
+func (f *fakePartialCachingService) HandlePartialQueryRequest(ctx context.Context, req *backend.QueryDataRequest) caching.CachedPartialQueryDataResponse {
+	f.req = req
+	f.fresh = &backend.QueryDataRequest{PluginContext: req.PluginContext, Queries: req.Queries}
+	return caching.CachedPartialQueryDataResponse{
+		Request: f.fresh,
+		MergeFn: func(ctx context.Context, resp *backend.QueryDataResponse) (*backend.QueryDataResponse, *backend.QueryDataRequest) {
+			resp.Responses["cached"] = backend.DataResponse{}
+			return resp, f.retry
+		},
+	}
+}
+
 func setup(t *testing.T) *testContext {
 	dss := []*datasources.DataSource{
 		{UID: "gIEkMvIVz", Type: "postgres"},
@@ -484,7 +564,7 @@ func setup(t *testing.T) *testContext {
 	)
 	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, pc, pCtxProvider,
 		featuremgmt.WithFeatures(), nil, tracing.InitializeTracerForTest())
-	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, pc, pCtxProvider) // provider belonging to this package
+	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, pc, pCtxProvider, nil) // provider belonging to this package
 	return &testContext{
 		pluginContext:          pc,
 		secretStore:            ss,
@@ -145,4 +145,12 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.AddAlertRuleUpdatedByMigration(mg)
 
 	ualert.AddAlertRuleStateTable(mg)
+
+	ualert.AddAlertRuleBacktestTable(mg)
+
+	ualert.AddAlertStateHistoryTable(mg)
+
+	ualert.AddAlertNotificationDeliveryTable(mg)
+
+	ualert.AddAlertSilenceTemplateTable(mg)
 }
@@ -0,0 +1,44 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddAlertRuleBacktestTable adds table to store the status and results of backtest runs.
+func AddAlertRuleBacktestTable(mg *migrator.Migrator) {
+	backtestTable := migrator.Table{
+		Name: "alert_rule_backtest",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false, Default: "''"},
+			{Name: "rule_version", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "rule", Type: migrator.DB_LongText, Nullable: false},
+			{Name: "from_time", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "to_time", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "started", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "finished", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "result", Type: migrator.DB_LongText, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration(
+		"add alert_rule_backtest table",
+		migrator.NewAddTableMigration(backtestTable),
+	)
+	mg.AddMigration(
+		"add unique index to alert_rule_backtest on org_id and uid columns",
+		migrator.NewAddIndexMigration(backtestTable, backtestTable.Indices[0]),
+	)
+	mg.AddMigration(
+		"add index to alert_rule_backtest on org_id and rule_uid columns",
+		migrator.NewAddIndexMigration(backtestTable, backtestTable.Indices[1]),
+	)
+}
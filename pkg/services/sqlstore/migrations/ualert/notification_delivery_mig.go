@@ -0,0 +1,57 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddAlertNotificationDeliveryTable adds table to store the outcome of attempts to deliver notifications to integrations.
+func AddAlertNotificationDeliveryTable(mg *migrator.Migrator) {
+	deliveryTable := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "group_key_hash", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "attempt", Type: migrator.DB_Int, Nullable: false, Default: "1"},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "response", Type: migrator.DB_Text, Nullable: true},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "retry", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "firing_alerts", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "resolved_alerts", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "epoch_nano", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "epoch_nano"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "receiver", "epoch_nano"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "group_key_hash"}, Type: migrator.IndexType},
+			{Cols: []string{"epoch_nano"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration(
+		"add alert_notification_delivery table",
+		migrator.NewAddTableMigration(deliveryTable),
+	)
+	mg.AddMigration(
+		"add index to alert_notification_delivery on org_id and epoch_nano columns",
+		migrator.NewAddIndexMigration(deliveryTable, deliveryTable.Indices[0]),
+	)
+	mg.AddMigration(
+		"add index to alert_notification_delivery on org_id, receiver and epoch_nano columns",
+		migrator.NewAddIndexMigration(deliveryTable, deliveryTable.Indices[1]),
+	)
+	mg.AddMigration(
+		"add index to alert_notification_delivery on org_id and group_key_hash columns",
+		migrator.NewAddIndexMigration(deliveryTable, deliveryTable.Indices[2]),
+	)
+	mg.AddMigration(
+		"add index to alert_notification_delivery on epoch_nano column",
+		migrator.NewAddIndexMigration(deliveryTable, deliveryTable.Indices[3]),
+	)
+}
@@ -0,0 +1,42 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddAlertSilenceTemplateTable adds table to store silence templates and recurring silences.
+func AddAlertSilenceTemplateTable(mg *migrator.Migrator) {
+	templateTable := migrator.Table{
+		Name: "alert_silence_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "duration_seconds", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "schedule", Type: migrator.DB_Text, Nullable: true},
+			{Name: "timezone", Type: migrator.DB_NVarchar, Length: 64, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "last_occurrence", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "name"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration(
+		"add alert_silence_template table",
+		migrator.NewAddTableMigration(templateTable),
+	)
+	mg.AddMigration(
+		"add unique index to alert_silence_template on org_id and uid columns",
+		migrator.NewAddIndexMigration(templateTable, templateTable.Indices[0]),
+	)
+	mg.AddMigration(
+		"add unique index to alert_silence_template on org_id and name columns",
+		migrator.NewAddIndexMigration(templateTable, templateTable.Indices[1]),
+	)
+}
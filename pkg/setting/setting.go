@@ -464,6 +464,10 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	Provisioning ProvisioningSettings
+
+	QueryCaching QueryCachingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -1291,8 +1295,14 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.Provisioning = readProvisioningSettings(iniFile)
 
 	var err error
+	cfg.QueryCaching, err = readQueryCachingSettings(iniFile)
+	if err != nil {
+		return err
+	}
+
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
 		// if the proxy is misconfigured, disable it rather than crashing
@@ -0,0 +1,23 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type ProvisioningSettings struct {
+	// How often the file-provisioned resources are compared with the database, 0 disables the scan
+	DriftScanInterval time.Duration
+	// Apply the provisioning files again when a scan finds drift
+	DriftAutoRevert bool
+}
+
+func readProvisioningSettings(iniFile *ini.File) ProvisioningSettings {
+	s := ProvisioningSettings{}
+
+	provisioningSection := iniFile.Section("provisioning")
+	s.DriftScanInterval = provisioningSection.Key("drift_scan_interval").MustDuration(10 * time.Minute)
+	s.DriftAutoRevert = provisioningSection.Key("drift_auto_revert").MustBool(false)
+	return s
+}
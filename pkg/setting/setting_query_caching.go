@@ -0,0 +1,55 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	// Enabled turns on caching of data source query and resource responses in the remote cache.
+	Enabled bool
+	// TTL is the default time to live of cached query responses.
+	TTL time.Duration
+	// ResourcesTTL is the time to live of cached resource responses. Zero disables resource caching.
+	ResourcesTTL time.Duration
+	// MaxValueSize is the maximum size in bytes of a single cached response. Zero means no limit.
+	MaxValueSize int
+	// PartialChunkSize is the size of the aligned chunks in which time series results are cached, so that
+	// requests for overlapping time ranges only query the part that is not cached yet. Zero disables it.
+	PartialChunkSize time.Duration
+	// PartialMaxDataDelay is how long data may take to arrive in a data source. Chunks newer than that are not cached.
+	PartialMaxDataDelay time.Duration
+	// DataSourceTTLs overrides TTL for individual data sources, keyed by data source UID.
+	// A TTL of zero bypasses the cache for that data source.
+	DataSourceTTLs map[string]time.Duration
+}
+
+func readQueryCachingSettings(iniFile *ini.File) (QueryCachingSettings, error) {
+	s := QueryCachingSettings{}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(5 * time.Minute)
+	s.ResourcesTTL = section.Key("resources_ttl").MustDuration(5 * time.Minute)
+	s.MaxValueSize = section.Key("max_value_size").MustInt(10 * 1024 * 1024)
+	s.PartialChunkSize = section.Key("partial_chunk_size").MustDuration(0)
+	s.PartialMaxDataDelay = section.Key("partial_max_data_delay").MustDuration(time.Minute)
+
+	dsSection := iniFile.Section("query_caching.datasources")
+	keys := dsSection.Keys()
+	s.DataSourceTTLs = make(map[string]time.Duration, len(keys))
+	for _, key := range keys {
+		ttl, err := time.ParseDuration(key.Value())
+		if err != nil {
+			return s, fmt.Errorf("data source %q in [query_caching.datasources] configuration: invalid ttl: %w", key.Name(), err)
+		}
+		if ttl < 0 {
+			return s, fmt.Errorf("data source %q in [query_caching.datasources] configuration: ttl must not be negative", key.Name())
+		}
+		s.DataSourceTTLs[key.Name()] = ttl
+	}
+
+	return s, nil
+}
@@ -0,0 +1,47 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadQueryCachingSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		s, err := readQueryCachingSettings(ini.Empty())
+		require.NoError(t, err)
+
+		assert.False(t, s.Enabled)
+		assert.Equal(t, 5*time.Minute, s.TTL)
+		assert.Equal(t, 5*time.Minute, s.ResourcesTTL)
+		assert.Empty(t, s.DataSourceTTLs)
+	})
+
+	t.Run("will load data source ttls", func(t *testing.T) {
+		f := ini.Empty()
+		s, err := f.NewSection("query_caching.datasources")
+		require.NoError(t, err)
+		_, err = s.NewKey("postgres-uid", "30s")
+		require.NoError(t, err)
+		_, err = s.NewKey("loki-uid", "0s")
+		require.NoError(t, err)
+
+		qc, err := readQueryCachingSettings(f)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]time.Duration{"postgres-uid": 30 * time.Second, "loki-uid": 0}, qc.DataSourceTTLs)
+	})
+
+	t.Run("will fail on invalid ttl", func(t *testing.T) {
+		f := ini.Empty()
+		s, err := f.NewSection("query_caching.datasources")
+		require.NoError(t, err)
+		_, err = s.NewKey("postgres-uid", "soon")
+		require.NoError(t, err)
+
+		_, err = readQueryCachingSettings(f)
+		require.Error(t, err)
+	})
+}
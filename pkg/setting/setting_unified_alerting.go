@@ -65,7 +65,15 @@ const (
 	stateHistoryDefaultEnabled     = true
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
+	defaultRecordingTarget         = "prometheus"
+	defaultRecordingBatchSize      = 100
+	defaultRecordingFlushInterval  = 10 * time.Second
+	defaultRecordingMaxRetries     = 3
+	defaultRecordingRetryBackoff   = time.Second
+	defaultRecordingSQLTable       = "grafana_recording_rules"
+	defaultRecordingLiveNamespace  = "recording_rules"
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	sqlHistoryDefaultRetention     = 30 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -91,6 +99,7 @@ type UnifiedAlertingSettings struct {
 	HARedisMaxConns                 int
 	HARedisTLSEnabled               bool
 	HARedisTLSConfig                dstls.ClientConfig
+	HAShardRuleEvaluation           bool
 	InitializationTimeout           time.Duration
 	MaxAttempts                     int64
 	MinInterval                     time.Duration
@@ -112,6 +121,7 @@ type UnifiedAlertingSettings struct {
 	StateHistory                  UnifiedAlertingStateHistorySettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
+	QueryBudget                   UnifiedAlertingQueryBudgetSettings
 
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency    int
@@ -122,6 +132,11 @@ type UnifiedAlertingSettings struct {
 	// Retention period for Alertmanager notification log entries.
 	NotificationLogRetention time.Duration
 
+	// NotificationDeliveryLogEnabled enables recording of every attempt to deliver a notification to an integration.
+	NotificationDeliveryLogEnabled bool
+	// Retention period for notification delivery records. Zero keeps them forever.
+	NotificationDeliveryLogRetention time.Duration
+
 	// Duration for which a resolved alert state transition will continue to be sent to the Alertmanager.
 	ResolvedAlertRetention time.Duration
 
@@ -138,6 +153,50 @@ type RecordingRuleSettings struct {
 	BasicAuthPassword string
 	CustomHeaders     map[string]string
 	Timeout           time.Duration
+
+	// Target is the type of the storage that recording rules write to: prometheus, sql, influxdb or live.
+	Target string
+
+	// Batching and retries of writes to targets other than Prometheus.
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+
+	// SQL target.
+	SQLDriver           string
+	SQLConnectionString string
+	SQLTable            string
+
+	// Grafana Live target.
+	LiveNamespace string
+}
+
+const (
+	// QueryBudgetActionThrottle delays the evaluations of a rule that exceeds its query budget.
+	QueryBudgetActionThrottle = "throttle"
+	// QueryBudgetActionPause stops evaluating a rule that exceeds its query budget until the rule is updated.
+	QueryBudgetActionPause = "pause"
+)
+
+// UnifiedAlertingQueryBudgetSettings limits the cost of data source queries made by a single rule evaluation.
+// A zero limit is not enforced.
+type UnifiedAlertingQueryBudgetSettings struct {
+	MaxQueryDuration time.Duration
+	MaxSeries        int
+	MaxFrames        int
+	MaxBytes         int64
+
+	// Action is taken when a rule exceeds any of the limits for ConsecutiveViolations evaluations in a row.
+	Action                string
+	ConsecutiveViolations int
+	// ThrottleInterval is the time a throttled rule is not evaluated for.
+	ThrottleInterval time.Duration
+}
+
+// Enabled returns true if at least one limit is set.
+func (s UnifiedAlertingQueryBudgetSettings) Enabled() bool {
+	return s.MaxQueryDuration > 0 || s.MaxSeries > 0 || s.MaxFrames > 0 || s.MaxBytes > 0
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -177,6 +236,12 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLRetention is how long state history entries written by the SQL backend are kept. Zero keeps them forever.
+	SQLRetention time.Duration
+	// SQLDownsampleAfter and SQLDownsampleInterval configure downsampling of the SQL backend. Entries older than
+	// SQLDownsampleAfter are reduced to at most one transition per alert instance in each SQLDownsampleInterval.
+	SQLDownsampleAfter    time.Duration
+	SQLDownsampleInterval time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -282,6 +347,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HAShardRuleEvaluation = ua.Key("ha_shard_rule_evaluation").MustBool(false)
 	uaCfg.HARedisTLSEnabled = ua.Key("ha_redis_tls_enabled").MustBool(false)
 	uaCfg.HARedisTLSConfig.CertPath = ua.Key("ha_redis_tls_cert_path").MustString("")
 	uaCfg.HARedisTLSConfig.KeyPath = ua.Key("ha_redis_tls_key_path").MustString("")
@@ -430,16 +496,28 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiPrimary:          stateHistory.Key("primary").MustString(""),
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
+		SQLRetention:          stateHistory.Key("sql_retention").MustDuration(sqlHistoryDefaultRetention),
+		SQLDownsampleAfter:    stateHistory.Key("sql_downsample_after").MustDuration(0),
+		SQLDownsampleInterval: stateHistory.Key("sql_downsample_interval").MustDuration(0),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
 	rr := iniFile.Section("recording_rules")
 	uaCfgRecordingRules := RecordingRuleSettings{
-		Enabled:           rr.Key("enabled").MustBool(false),
-		URL:               rr.Key("url").MustString(""),
-		BasicAuthUsername: rr.Key("basic_auth_username").MustString(""),
-		BasicAuthPassword: rr.Key("basic_auth_password").MustString(""),
-		Timeout:           rr.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+		Enabled:             rr.Key("enabled").MustBool(false),
+		URL:                 rr.Key("url").MustString(""),
+		BasicAuthUsername:   rr.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword:   rr.Key("basic_auth_password").MustString(""),
+		Timeout:             rr.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+		Target:              rr.Key("target").MustString(defaultRecordingTarget),
+		BatchSize:           rr.Key("batch_size").MustInt(defaultRecordingBatchSize),
+		FlushInterval:       rr.Key("flush_interval").MustDuration(defaultRecordingFlushInterval),
+		MaxRetries:          rr.Key("max_retries").MustInt(defaultRecordingMaxRetries),
+		RetryBackoff:        rr.Key("retry_backoff").MustDuration(defaultRecordingRetryBackoff),
+		SQLDriver:           rr.Key("sql_driver").MustString(""),
+		SQLConnectionString: rr.Key("sql_connection_string").MustString(""),
+		SQLTable:            rr.Key("sql_table").MustString(defaultRecordingSQLTable),
+		LiveNamespace:       rr.Key("live_namespace").MustString(defaultRecordingLiveNamespace),
 	}
 
 	rrHeaders := iniFile.Section("recording_rules.custom_headers")
@@ -451,6 +529,27 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
+	qb := iniFile.Section("unified_alerting.query_budget")
+	uaCfgQueryBudget := UnifiedAlertingQueryBudgetSettings{
+		MaxQueryDuration:      qb.Key("max_query_duration").MustDuration(0),
+		MaxSeries:             qb.Key("max_series").MustInt(0),
+		MaxFrames:             qb.Key("max_frames").MustInt(0),
+		MaxBytes:              qb.Key("max_bytes").MustInt64(0),
+		Action:                qb.Key("action").MustString(QueryBudgetActionThrottle),
+		ConsecutiveViolations: qb.Key("consecutive_violations").MustInt(1),
+		ThrottleInterval:      qb.Key("throttle_interval").MustDuration(10 * time.Minute),
+	}
+	if uaCfgQueryBudget.Action != QueryBudgetActionThrottle && uaCfgQueryBudget.Action != QueryBudgetActionPause {
+		return fmt.Errorf("setting 'action' in section 'unified_alerting.query_budget' is invalid, only %q and %q are allowed", QueryBudgetActionThrottle, QueryBudgetActionPause)
+	}
+	if uaCfgQueryBudget.ConsecutiveViolations < 1 {
+		return fmt.Errorf("setting 'consecutive_violations' in section 'unified_alerting.query_budget' is invalid, only positive integers are allowed")
+	}
+	if uaCfgQueryBudget.MaxSeries < 0 || uaCfgQueryBudget.MaxFrames < 0 || uaCfgQueryBudget.MaxBytes < 0 || uaCfgQueryBudget.MaxQueryDuration < 0 {
+		return fmt.Errorf("limits in section 'unified_alerting.query_budget' must not be negative")
+	}
+	uaCfg.QueryBudget = uaCfgQueryBudget
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	uaCfg.StatePeriodicSaveInterval, err = gtime.ParseDuration(valueAsString(ua, "state_periodic_save_interval", (time.Minute * 5).String()))
@@ -465,6 +564,12 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		return err
 	}
 
+	uaCfg.NotificationDeliveryLogEnabled = ua.Key("notification_delivery_log_enabled").MustBool(true)
+	uaCfg.NotificationDeliveryLogRetention, err = gtime.ParseDuration(valueAsString(ua, "notification_delivery_log_retention", (7 * 24 * time.Hour).String()))
+	if err != nil {
+		return err
+	}
+
 	uaCfg.ResolvedAlertRetention, err = gtime.ParseDuration(valueAsString(ua, "resolved_alert_retention", (15 * time.Minute).String()))
 	if err != nil {
 		return err
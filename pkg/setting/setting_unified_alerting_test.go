@@ -350,3 +350,55 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestQueryBudgetSettings(t *testing.T) {
+	t.Run("should not enforce any limits by default", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(ini.Empty()))
+		require.False(t, cfg.UnifiedAlerting.QueryBudget.Enabled())
+		require.Equal(t, QueryBudgetActionThrottle, cfg.UnifiedAlerting.QueryBudget.Action)
+		require.Equal(t, 1, cfg.UnifiedAlerting.QueryBudget.ConsecutiveViolations)
+	})
+
+	t.Run("should read the limits", func(t *testing.T) {
+		f := ini.Empty()
+		section, err := f.NewSection("unified_alerting.query_budget")
+		require.NoError(t, err)
+		for k, v := range map[string]string{
+			"max_query_duration":     "30s",
+			"max_series":             "1000",
+			"max_frames":             "10",
+			"max_bytes":              "1048576",
+			"action":                 "pause",
+			"consecutive_violations": "3",
+			"throttle_interval":      "1h",
+		} {
+			_, err = section.NewKey(k, v)
+			require.NoError(t, err)
+		}
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+		require.True(t, cfg.UnifiedAlerting.QueryBudget.Enabled())
+		require.Equal(t, UnifiedAlertingQueryBudgetSettings{
+			MaxQueryDuration:      30 * time.Second,
+			MaxSeries:             1000,
+			MaxFrames:             10,
+			MaxBytes:              1048576,
+			Action:                QueryBudgetActionPause,
+			ConsecutiveViolations: 3,
+			ThrottleInterval:      time.Hour,
+		}, cfg.UnifiedAlerting.QueryBudget)
+	})
+
+	t.Run("should fail if action is unknown", func(t *testing.T) {
+		f := ini.Empty()
+		section, err := f.NewSection("unified_alerting.query_budget")
+		require.NoError(t, err)
+		_, err = section.NewKey("action", "drop")
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.Error(t, cfg.ReadUnifiedAlertingSettings(f))
+	})
+}
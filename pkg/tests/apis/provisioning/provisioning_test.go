@@ -99,6 +99,8 @@ func TestIntegrationProvisioning(t *testing.T) {
 		for _, inputFilePath := range []string{
 			"testdata/local-devenv.json",
 			"testdata/github-example.json",
+			"testdata/git-example.json",
+			"testdata/bucket-example.json",
 		} {
 			t.Run(inputFilePath, func(t *testing.T) {
 				input := helper.LoadYAMLOrJSONFile(inputFilePath)